@@ -120,10 +120,24 @@ func AllowAnonymousRequest(req *http.Request) bool {
 		return true
 	}
 
+	if strings.HasPrefix(req.URL.Path, "/.api/gitlab-webhooks") {
+		return true
+	}
+
+	if strings.HasPrefix(req.URL.Path, "/.api/bitbucket-cloud-webhooks") {
+		return true
+	}
+
 	if strings.HasPrefix(req.URL.Path, "/.api/bitbucket-server-webhooks") {
 		return true
 	}
 
+	// Authentication is performed in the SCIM handler itself, since SCIM clients send access
+	// tokens as bearer tokens.
+	if strings.HasPrefix(req.URL.Path, "/.api/scim/") {
+		return true
+	}
+
 	apiRouteName := matchedRouteName(req, router.Router())
 	if apiRouteName == router.UI {
 		// Test against UI router. (Some of its handlers inject private data into the title or meta tags.)
@@ -220,3 +220,43 @@ func (p *UserPendingPermissions) TracingFields() []otlog.Field {
 
 	return fs
 }
+
+// AccessGrant is a time-limited grant of read access to a repository for a user or
+// for all members of an organization. It is honoured in addition to all other
+// permissions until it expires or is revoked.
+type AccessGrant struct {
+	// The auto-generated internal database ID.
+	ID int64
+	// The repository the access is granted to.
+	RepoID int32
+	// The user who is granted the access, zero when it is granted to an organization.
+	UserID int32
+	// The organization whose members are granted the access, zero when it is granted to a user.
+	OrgID int32
+	// The site admin who granted the access, zero when the user no longer exists.
+	CreatorUserID int32
+	// The reason why the access is granted.
+	Reason string
+	// The time when the access expires.
+	ExpiresAt time.Time
+	// The time when the grant was revoked, zero when it hasn't been revoked.
+	RevokedAt time.Time
+	// The time when the grant was created.
+	CreatedAt time.Time
+}
+
+// Active returns true if the grant is neither revoked nor expired at the given time.
+func (g *AccessGrant) Active(now time.Time) bool {
+	return g.RevokedAt.IsZero() && now.Before(g.ExpiresAt)
+}
+
+// TracingFields returns tracing fields for the opentracing log.
+func (g *AccessGrant) TracingFields() []otlog.Field {
+	return []otlog.Field{
+		otlog.Int64("AccessGrant.ID", g.ID),
+		otlog.Int32("AccessGrant.RepoID", g.RepoID),
+		otlog.Int32("AccessGrant.UserID", g.UserID),
+		otlog.Int32("AccessGrant.OrgID", g.OrgID),
+		otlog.String("AccessGrant.ExpiresAt", g.ExpiresAt.String()),
+	}
+}
@@ -4,10 +4,34 @@ const (
 	// Access token scopes.
 	ScopeUserAll       = "user:all"        // Full control of all resources accessible to the user account.
 	ScopeSiteAdminSudo = "site-admin:sudo" // Ability to perform any action as any other user.
+
+	// Access token scopes that restrict the token to some actions of the user account. A token
+	// with any of these scopes (and without ScopeUserAll) can only perform the actions granted by
+	// its scopes.
+	ScopeSearchRead      = "search:read"       // Ability to run searches.
+	ScopeRepoRead        = "repo:read"         // Ability to read repositories and their files.
+	ScopeCampaignsWrite  = "campaigns:write"   // Ability to read, create and update campaigns.
+	ScopeCodeIntelUpload = "code-intel:upload" // Ability to upload LSIF data.
+	ScopeSiteAdminUsers  = "site-admin:users"  // Ability to manage users (including via SCIM), if the user is a site admin.
 )
 
 // AllScopes is a list of all known access token scopes.
 var AllScopes = []string{
 	ScopeUserAll,
 	ScopeSiteAdminSudo,
+	ScopeSearchRead,
+	ScopeRepoRead,
+	ScopeCampaignsWrite,
+	ScopeCodeIntelUpload,
+	ScopeSiteAdminUsers,
+}
+
+// RestrictedScopes is a list of the access token scopes that restrict a token to some actions of
+// the user account.
+var RestrictedScopes = []string{
+	ScopeSearchRead,
+	ScopeRepoRead,
+	ScopeCampaignsWrite,
+	ScopeCodeIntelUpload,
+	ScopeSiteAdminUsers,
 }
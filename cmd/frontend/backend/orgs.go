@@ -19,6 +19,10 @@ func CheckOrgAccess(ctx context.Context, orgID int32) error {
 	if hasAuthzBypass(ctx) {
 		return nil
 	}
+	// 🚨 SECURITY: Access tokens with restricted scopes may only perform the actions of their scopes.
+	if err := checkAccessTokenUnrestricted(ctx); err != nil {
+		return err
+	}
 	currentUser, err := CurrentUser(ctx)
 	if err != nil {
 		return err
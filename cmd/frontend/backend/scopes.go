@@ -0,0 +1,54 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+// ScopeError is returned when the current actor was authenticated with an access token that doesn't
+// have the scope required for an action.
+type ScopeError struct {
+	Scope string
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("access token does not have the required scope %q (or %q)", e.Scope, authz.ScopeUserAll)
+}
+
+func (e *ScopeError) HTTPStatusCode() int { return http.StatusForbidden }
+
+// CheckAccessTokenScope returns an error if the current actor was authenticated with an access token
+// that has neither the scope nor the "user:all" scope. Actors that were not authenticated with an
+// access token always pass the check.
+func CheckAccessTokenScope(ctx context.Context, scope string) error {
+	if !actor.FromContext(ctx).HasScope(scope) {
+		return &ScopeError{Scope: scope}
+	}
+	return nil
+}
+
+// WithAccessTokenScope checks that the current actor may perform the actions granted by the scope
+// (see CheckAccessTokenScope), and returns a context in which the backend.CheckXyz funcs don't
+// reject the actor because of the scopes of its access token. The backend.CheckXyz funcs still check
+// the actor's user as usual.
+//
+// 🚨 SECURITY: The caller MUST only use the returned context for the actions granted by the scope.
+func WithAccessTokenScope(ctx context.Context, scope string) (context.Context, error) {
+	if err := CheckAccessTokenScope(ctx, scope); err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, accessTokenScopeGranted, scope), nil
+}
+
+// checkAccessTokenUnrestricted returns an error if the current actor is restricted by the scopes of
+// its access token, unless the context was returned by WithAccessTokenScope.
+func checkAccessTokenUnrestricted(ctx context.Context) error {
+	if ctx.Value(accessTokenScopeGranted) != nil {
+		return nil
+	}
+	return CheckAccessTokenScope(ctx, authz.ScopeUserAll)
+}
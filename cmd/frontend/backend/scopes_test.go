@@ -0,0 +1,63 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestCheckAccessTokenScope(t *testing.T) {
+	tests := map[string]struct {
+		actor   *actor.Actor
+		scope   string
+		wantErr bool
+	}{
+		"unrestricted":          {actor: &actor.Actor{UID: 1}, scope: authz.ScopeSearchRead},
+		"restricted with scope": {actor: &actor.Actor{UID: 1, Scopes: []string{authz.ScopeSearchRead}}, scope: authz.ScopeSearchRead},
+		"restricted without scope": {
+			actor:   &actor.Actor{UID: 1, Scopes: []string{authz.ScopeRepoRead}},
+			scope:   authz.ScopeSearchRead,
+			wantErr: true,
+		},
+		"restricted with no scopes": {actor: &actor.Actor{UID: 1, Scopes: []string{}}, scope: authz.ScopeSearchRead, wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := CheckAccessTokenScope(actor.WithActor(context.Background(), test.actor), test.scope)
+			if (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %v", err, test.wantErr)
+			}
+			if _, ok := err.(*ScopeError); err != nil && !ok {
+				t.Errorf("got error %T, want *ScopeError", err)
+			}
+		})
+	}
+}
+
+func TestCheckCurrentUserIsSiteAdmin_restrictedActor(t *testing.T) {
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	defer func() { db.Mocks.Users = db.MockUsers{} }()
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1, Scopes: []string{authz.ScopeSiteAdminUsers}})
+	if err := CheckCurrentUserIsSiteAdmin(ctx); err == nil {
+		t.Fatal("got nil error, want restricted actor to be rejected")
+	}
+
+	if _, err := WithAccessTokenScope(ctx, authz.ScopeCampaignsWrite); err == nil {
+		t.Fatal("got nil error, want scope to be rejected")
+	}
+
+	grantedCtx, err := WithAccessTokenScope(ctx, authz.ScopeSiteAdminUsers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckCurrentUserIsSiteAdmin(grantedCtx); err != nil {
+		t.Fatalf("got error %v, want granted scope to be accepted", err)
+	}
+}
@@ -18,6 +18,10 @@ func CheckCurrentUserIsSiteAdmin(ctx context.Context) error {
 	if hasAuthzBypass(ctx) {
 		return nil
 	}
+	// 🚨 SECURITY: Access tokens with restricted scopes may only perform the actions of their scopes.
+	if err := checkAccessTokenUnrestricted(ctx); err != nil {
+		return err
+	}
 	user, err := CurrentUser(ctx)
 	if err != nil {
 		return err
@@ -69,6 +73,10 @@ func CheckSiteAdminOrSameUser(ctx context.Context, subjectUserID int32) error {
 	if hasAuthzBypass(ctx) {
 		return nil
 	}
+	// 🚨 SECURITY: Access tokens with restricted scopes may only perform the actions of their scopes.
+	if err := checkAccessTokenUnrestricted(ctx); err != nil {
+		return err
+	}
 	actor := actor.FromContext(ctx)
 	if actor.IsAuthenticated() && actor.UID == subjectUserID {
 		return nil
@@ -112,4 +120,7 @@ func hasAuthzBypass(ctx context.Context) bool {
 
 type contextKey int
 
-const authzBypass contextKey = iota
+const (
+	authzBypass contextKey = iota
+	accessTokenScopeGranted
+)
@@ -0,0 +1,154 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/totp"
+)
+
+// numRecoveryCodes is the number of recovery codes a user gets when enabling two-factor
+// authentication.
+const numRecoveryCodes = 10
+
+// Checking the two-factor authentication codes of a user is refused after maxTwoFactorAttempts
+// attempts without a valid code in twoFactorAttemptWindow, so that codes can't be guessed.
+const (
+	maxTwoFactorAttempts   = 5
+	twoFactorAttemptWindow = 15 * time.Minute
+)
+
+// ErrInvalidTwoFactorCode is returned when a two-factor authentication code is invalid.
+var ErrInvalidTwoFactorCode = errors.New("invalid two-factor authentication code")
+
+// ErrTwoFactorRateLimit is returned when a user has made too many attempts to provide a two-factor
+// authentication code.
+var ErrTwoFactorRateLimit = errors.New("too many two-factor authentication attempts, try again later")
+
+// timeNow is the mockable function to return the current time.
+var timeNow = time.Now
+
+// StartTwoFactorEnrollment starts (or restarts) the two-factor authentication enrollment of the user
+// with a new secret. It returns the secret and its otpauth:// URI for the user's authenticator app.
+// The enrollment must be confirmed with ConfirmTwoFactorEnrollment.
+//
+// 🚨 SECURITY: Callers must ensure that the user is the current user, or is being signed in.
+func StartTwoFactorEnrollment(ctx context.Context, user *types.User) (secret, keyURI string, err error) {
+	secret, err = totp.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	if err := db.Users.SetTOTPSecret(ctx, user.ID, secret); err != nil {
+		return "", "", err
+	}
+	return secret, totp.KeyURI(twoFactorIssuer(), user.Username, secret), nil
+}
+
+// ConfirmTwoFactorEnrollment enables two-factor authentication for the user if the code is valid
+// for the secret of the enrollment in progress. It returns the new recovery codes, which are not
+// stored and must be shown to the user.
+//
+// 🚨 SECURITY: Callers must ensure that the user is the current user, or is being signed in.
+func ConfirmTwoFactorEnrollment(ctx context.Context, userID int32, code string) ([]string, error) {
+	t, err := db.Users.GetTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if t.Enabled {
+		return nil, db.ErrTOTPAlreadyEnabled
+	}
+	if t.Secret == "" {
+		return nil, errors.New("no two-factor authentication enrollment in progress")
+	}
+
+	step, ok, err := totp.Validate(t.Secret, code, timeNow())
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	codes, hashes := newRecoveryCodes()
+	if ok, err := db.Users.EnableTOTP(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	} else if !ok {
+		// Another request has confirmed or restarted the enrollment concurrently.
+		return nil, errors.New("two-factor authentication enrollment changed, try again")
+	}
+	return codes, nil
+}
+
+// CheckTwoFactorCode reports whether the code is a valid TOTP code or an unused recovery code of the
+// user, who must have two-factor authentication enabled. Each code can only be used once. It returns
+// ErrTwoFactorRateLimit if the user has made too many attempts without a valid code recently.
+func CheckTwoFactorCode(ctx context.Context, userID int32, code string) (bool, error) {
+	t, err := db.Users.GetTOTP(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !t.Enabled {
+		return false, errors.New("two-factor authentication is not enabled")
+	}
+
+	// 🚨 SECURITY: Throttle attempts, so that codes can't be guessed. The attempt is recorded
+	// before the code is checked, so that concurrent attempts are counted too.
+	attempts, err := db.Users.AddTOTPAttempt(ctx, userID, twoFactorAttemptWindow)
+	if err != nil {
+		return false, err
+	}
+	if attempts > maxTwoFactorAttempts {
+		return false, ErrTwoFactorRateLimit
+	}
+
+	step, ok, err := totp.Validate(t.Secret, code, timeNow())
+	if err != nil {
+		return false, err
+	}
+	if ok {
+		// 🚨 SECURITY: Reject replayed codes.
+		ok, err = db.Users.UseTOTPStep(ctx, userID, step)
+	} else {
+		ok, err = db.Users.UseTOTPRecoveryCode(ctx, userID, totp.HashRecoveryCode(code))
+	}
+	if err != nil || !ok {
+		return false, err
+	}
+	if err := db.Users.ResetTOTPAttempts(ctx, userID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// RegenerateTwoFactorRecoveryCodes replaces the recovery codes of the user with new ones, which are
+// returned.
+//
+// 🚨 SECURITY: Callers must ensure that the user is the current user, and has provided a valid code.
+func RegenerateTwoFactorRecoveryCodes(ctx context.Context, userID int32) ([]string, error) {
+	codes, hashes := newRecoveryCodes()
+	if err := db.Users.SetTOTPRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func newRecoveryCodes() (codes, hashes []string) {
+	codes = totp.GenerateRecoveryCodes(numRecoveryCodes)
+	hashes = make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = totp.HashRecoveryCode(c)
+	}
+	return codes, hashes
+}
+
+// twoFactorIssuer returns the name that identifies the site in authenticator apps.
+func twoFactorIssuer() string {
+	if u, err := url.Parse(conf.Get().ExternalURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return "Sourcegraph"
+}
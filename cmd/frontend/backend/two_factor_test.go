@@ -0,0 +1,131 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/totp"
+)
+
+// testTOTPSecret is the secret of the RFC 6238 test vectors, whose code at testTOTPTime is
+// "081804" in time step 37037036.
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+var testTOTPTime = time.Unix(1111111109, 0)
+
+func TestConfirmTwoFactorEnrollment(t *testing.T) {
+	timeNow = func() time.Time { return testTOTPTime }
+	defer func() { timeNow = time.Now }()
+
+	var enabled bool
+	db.Mocks.Users.GetTOTP = func(context.Context, int32) (*db.UserTOTP, error) {
+		return &db.UserTOTP{Secret: testTOTPSecret, Enabled: enabled}, nil
+	}
+	var hashes []string
+	db.Mocks.Users.EnableTOTP = func(_ context.Context, _ int32, step int64, recoveryCodeHashes []string) (bool, error) {
+		if step != 37037036 {
+			t.Errorf("have step %d, want 37037036", step)
+		}
+		enabled, hashes = true, recoveryCodeHashes
+		return true, nil
+	}
+	defer func() { db.Mocks.Users = db.MockUsers{} }()
+
+	ctx := context.Background()
+	if _, err := ConfirmTwoFactorEnrollment(ctx, 1, "123456"); err != ErrInvalidTwoFactorCode {
+		t.Fatalf("have error %v, want %v", err, ErrInvalidTwoFactorCode)
+	}
+
+	codes, err := ConfirmTwoFactorEnrollment(ctx, 1, "081804")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != numRecoveryCodes || len(hashes) != numRecoveryCodes {
+		t.Fatalf("have %d recovery codes and %d hashes, want %d", len(codes), len(hashes), numRecoveryCodes)
+	}
+	for i, c := range codes {
+		if hashes[i] != totp.HashRecoveryCode(c) {
+			t.Errorf("recovery code %d: stored hash doesn't match", i)
+		}
+	}
+
+	if _, err := ConfirmTwoFactorEnrollment(ctx, 1, "081804"); err != db.ErrTOTPAlreadyEnabled {
+		t.Fatalf("have error %v, want %v", err, db.ErrTOTPAlreadyEnabled)
+	}
+}
+
+func TestCheckTwoFactorCode(t *testing.T) {
+	timeNow = func() time.Time { return testTOTPTime }
+	defer func() { timeNow = time.Now }()
+
+	db.Mocks.Users.GetTOTP = func(context.Context, int32) (*db.UserTOTP, error) {
+		return &db.UserTOTP{Secret: testTOTPSecret, Enabled: true}, nil
+	}
+	usedSteps := map[int64]bool{}
+	db.Mocks.Users.UseTOTPStep = func(_ context.Context, _ int32, step int64) (bool, error) {
+		if usedSteps[step] {
+			return false, nil
+		}
+		usedSteps[step] = true
+		return true, nil
+	}
+	recoveryCodes := map[string]bool{totp.HashRecoveryCode("abcde-fghjk"): true}
+	db.Mocks.Users.UseTOTPRecoveryCode = func(_ context.Context, _ int32, hash string) (bool, error) {
+		ok := recoveryCodes[hash]
+		delete(recoveryCodes, hash)
+		return ok, nil
+	}
+	attempts := 0
+	db.Mocks.Users.AddTOTPAttempt = func(_ context.Context, _ int32, window time.Duration) (int, error) {
+		if window != twoFactorAttemptWindow {
+			t.Errorf("have window %s, want %s", window, twoFactorAttemptWindow)
+		}
+		attempts++
+		return attempts, nil
+	}
+	db.Mocks.Users.ResetTOTPAttempts = func(context.Context, int32) error {
+		attempts = 0
+		return nil
+	}
+	defer func() { db.Mocks.Users = db.MockUsers{} }()
+
+	for _, tc := range []struct {
+		name string
+		code string
+		want bool
+	}{
+		{name: "valid code", code: "081804", want: true},
+		{name: "replayed code", code: "081804", want: false},
+		{name: "invalid code", code: "123456", want: false},
+		{name: "recovery code", code: "ABCDE-FGHJK", want: true},
+		{name: "used recovery code", code: "abcde-fghjk", want: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ok, err := CheckTwoFactorCode(context.Background(), 1, tc.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.want {
+				t.Errorf("have %v, want %v", ok, tc.want)
+			}
+		})
+	}
+
+	t.Run("too many attempts", func(t *testing.T) {
+		attempts = 0
+		for i := 0; i < maxTwoFactorAttempts; i++ {
+			if ok, err := CheckTwoFactorCode(context.Background(), 1, "123456"); ok || err != nil {
+				t.Fatalf("attempt %d: have %v, %v, want false, nil", i+1, ok, err)
+			}
+		}
+		recoveryCodes[totp.HashRecoveryCode("klmno-pqrst")] = true
+		if ok, err := CheckTwoFactorCode(context.Background(), 1, "klmno-pqrst"); ok || err != ErrTwoFactorRateLimit {
+			t.Errorf("have %v, %v, want false, %v", ok, err, ErrTwoFactorRateLimit)
+		}
+		if !recoveryCodes[totp.HashRecoveryCode("klmno-pqrst")] {
+			t.Error("recovery code was used although the attempt was refused")
+		}
+	})
+}
@@ -24,6 +24,7 @@ type AccessToken struct {
 	CreatorUserID int32
 	CreatedAt     time.Time
 	LastUsedAt    *time.Time
+	ExpiresAt     *time.Time // the time after which the access token is invalid, or nil if it never expires
 }
 
 // ErrAccessTokenNotFound occurs when a database operation expects a specific access token to exist
@@ -46,11 +47,13 @@ type accessTokens struct{}
 // space; also bcrypt is slow and would add noticeable latency to each request that supplied a
 // token.
 //
+// If expiresAt is not nil, the access token is invalid after that time.
+//
 // 🚨 SECURITY: The caller must ensure that the actor is permitted to create tokens for the
 // specified user (i.e., that the actor is either the user or a site admin).
-func (s *accessTokens) Create(ctx context.Context, subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error) {
+func (s *accessTokens) Create(ctx context.Context, subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time) (id int64, token string, err error) {
 	if Mocks.AccessTokens.Create != nil {
-		return Mocks.AccessTokens.Create(subjectUserID, scopes, note, creatorUserID)
+		return Mocks.AccessTokens.Create(subjectUserID, scopes, note, creatorUserID, expiresAt)
 	}
 
 	var b [20]byte
@@ -76,37 +79,38 @@ creator_user AS (
   SELECT id FROM users WHERE id=$5 AND deleted_at IS NULL FOR UPDATE
 ),
 insert_values AS (
-  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id
+  SELECT subject_user.id AS subject_user_id, $2::text[] AS scopes, $3::bytea AS value_sha256, $4::text AS note, creator_user.id AS creator_user_id, $6::timestamp with time zone AS expires_at
   FROM subject_user, creator_user
 )
-INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id) SELECT * FROM insert_values RETURNING id
+INSERT INTO access_tokens(subject_user_id, scopes, value_sha256, note, creator_user_id, expires_at) SELECT * FROM insert_values RETURNING id
 `,
-		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID,
+		subjectUserID, pq.Array(scopes), toSHA256Bytes(b[:]), note, creatorUserID, expiresAt,
 	).Scan(&id); err != nil {
 		return 0, "", err
 	}
 	return id, token, nil
 }
 
-// Lookup looks up the access token. If it's valid and contains the required scope, it returns the
-// subject's user ID. Otherwise ErrAccessTokenNotFound is returned.
+// Lookup looks up the access token. If it's valid and contains at least one of the required scopes,
+// it returns the subject's user ID and the access token's scopes. Otherwise ErrAccessTokenNotFound is
+// returned.
 //
 // Calling Lookup also updates the access token's last-used-at date.
 //
 // 🚨 SECURITY: This returns a user ID if and only if the tokenHexEncoded corresponds to a valid,
-// non-deleted access token.
-func (s *accessTokens) Lookup(ctx context.Context, tokenHexEncoded string, requiredScope string) (subjectUserID int32, err error) {
+// non-deleted, non-expired access token.
+func (s *accessTokens) Lookup(ctx context.Context, tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 	if Mocks.AccessTokens.Lookup != nil {
-		return Mocks.AccessTokens.Lookup(tokenHexEncoded, requiredScope)
+		return Mocks.AccessTokens.Lookup(tokenHexEncoded, requiredScopes)
 	}
 
-	if requiredScope == "" {
-		return 0, errors.New("no scope provided in access token lookup")
+	if len(requiredScopes) == 0 {
+		return 0, nil, errors.New("no scope provided in access token lookup")
 	}
 
 	token, err := hex.DecodeString(tokenHexEncoded)
 	if err != nil {
-		return 0, errors.Wrap(err, "AccessTokens.Lookup")
+		return 0, nil, errors.Wrap(err, "AccessTokens.Lookup")
 	}
 
 	if err := dbconn.Global.QueryRowContext(ctx,
@@ -117,18 +121,19 @@ FROM access_tokens t2
 JOIN users subject_user ON t2.subject_user_id=subject_user.id
 JOIN users creator_user ON t2.creator_user_id=creator_user.id
 WHERE t.value_sha256=$1 AND t.deleted_at IS NULL AND
-  subject_user.deleted_at IS NULL AND creator_user.deleted_at IS NULL AND
-  $2 = ANY (t.scopes)
-RETURNING t.subject_user_id
+  (t.expires_at IS NULL OR t.expires_at > now()) AND
+  subject_user.deleted_at IS NULL AND subject_user.deactivated_at IS NULL AND creator_user.deleted_at IS NULL AND
+  $2 && t.scopes
+RETURNING t.subject_user_id, t.scopes
 `,
-		toSHA256Bytes(token), requiredScope,
-	).Scan(&subjectUserID); err != nil {
+		toSHA256Bytes(token), pq.Array(requiredScopes),
+	).Scan(&subjectUserID, pq.Array(&scopes)); err != nil {
 		if err == sql.ErrNoRows {
-			return 0, ErrAccessTokenNotFound
+			return 0, nil, ErrAccessTokenNotFound
 		}
-		return 0, err
+		return 0, nil, err
 	}
-	return subjectUserID, nil
+	return subjectUserID, scopes, nil
 }
 
 // GetByID retrieves the access token (if any) given its ID.
@@ -181,7 +186,7 @@ func (s *accessTokens) List(ctx context.Context, opt AccessTokensListOptions) ([
 
 func (s *accessTokens) list(ctx context.Context, conds []*sqlf.Query, limitOffset *LimitOffset) ([]*AccessToken, error) {
 	q := sqlf.Sprintf(`
-SELECT id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at FROM access_tokens
+SELECT id, subject_user_id, scopes, note, creator_user_id, created_at, last_used_at, expires_at FROM access_tokens
 WHERE (%s)
 ORDER BY now() - created_at < interval '5 minutes' DESC, -- show recently created tokens first
 last_used_at DESC NULLS FIRST, -- ensure newly created tokens show first
@@ -200,7 +205,7 @@ created_at DESC
 	var results []*AccessToken
 	for rows.Next() {
 		var t AccessToken
-		if err := rows.Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt); err != nil {
+		if err := rows.Scan(&t.ID, &t.SubjectUserID, pq.Array(&t.Scopes), &t.Note, &t.CreatorUserID, &t.CreatedAt, &t.LastUsedAt, &t.ExpiresAt); err != nil {
 			return nil, err
 		}
 		results = append(results, &t)
@@ -264,8 +269,8 @@ func toSHA256Bytes(input []byte) []byte {
 }
 
 type MockAccessTokens struct {
-	Create     func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (id int64, token string, err error)
+	Create     func(subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time) (id int64, token string, err error)
 	DeleteByID func(id int64, subjectUserID int32) error
-	Lookup     func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error)
+	Lookup     func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error)
 	GetByID    func(id int64) (*AccessToken, error)
 }
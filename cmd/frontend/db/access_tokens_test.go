@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
 )
@@ -34,7 +35,7 @@ func TestAccessTokens_Create(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -53,13 +54,16 @@ func TestAccessTokens_Create(t *testing.T) {
 		t.Errorf("got %q, want %q", got.Note, want)
 	}
 
-	gotSubjectUserID, err := AccessTokens.Lookup(ctx, tv0, "a")
+	gotSubjectUserID, gotScopes, err := AccessTokens.Lookup(ctx, tv0, []string{"a"})
 	if err != nil {
 		t.Fatal(err)
 	}
 	if want := subject.ID; gotSubjectUserID != want {
 		t.Errorf("got %v, want %v", gotSubjectUserID, want)
 	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(gotScopes, want) {
+		t.Errorf("got token scopes %q, want %q", gotScopes, want)
+	}
 
 	ts, err := AccessTokens.List(ctx, AccessTokensListOptions{SubjectUserID: subject.ID})
 	if err != nil {
@@ -108,11 +112,11 @@ func TestAccessTokens_List(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n0", subject1.ID)
+	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n0", subject1.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
-	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n1", subject1.ID)
+	_, _, err = AccessTokens.Create(ctx, subject1.ID, []string{"a", "b"}, "n1", subject1.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -187,13 +191,13 @@ func TestAccessTokens_Lookup(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID)
+	tid0, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a", "b"}, "n0", creator.ID, nil)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	for _, scope := range []string{"a", "b"} {
-		gotSubjectUserID, err := AccessTokens.Lookup(ctx, tv0, scope)
+	for _, scopes := range [][]string{{"a"}, {"b"}, {"x", "b"}} {
+		gotSubjectUserID, _, err := AccessTokens.Lookup(ctx, tv0, scopes)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -203,12 +207,12 @@ func TestAccessTokens_Lookup(t *testing.T) {
 	}
 
 	// Lookup with a nonexistent scope and ensure it fails.
-	if _, err := AccessTokens.Lookup(ctx, tv0, "x"); err == nil {
+	if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"x"}); err == nil {
 		t.Fatal(err)
 	}
 
-	// Lookup with an empty scope and ensure it fails.
-	if _, err := AccessTokens.Lookup(ctx, tv0, ""); err == nil {
+	// Lookup without scopes and ensure it fails.
+	if _, _, err := AccessTokens.Lookup(ctx, tv0, nil); err == nil {
 		t.Fatal(err)
 	}
 
@@ -216,16 +220,62 @@ func TestAccessTokens_Lookup(t *testing.T) {
 	if err := AccessTokens.DeleteByID(ctx, tid0, subject.ID); err != nil {
 		t.Fatal(err)
 	}
-	if _, err := AccessTokens.Lookup(ctx, tv0, "a"); err == nil {
+	if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err == nil {
 		t.Fatal(err)
 	}
 
 	// Try to Lookup a token that was never created.
-	if _, err := AccessTokens.Lookup(ctx, "abcdefg" /* this token value was never created */, "a"); err == nil {
+	if _, _, err := AccessTokens.Lookup(ctx, "abcdefg" /* this token value was never created */, []string{"a"}); err == nil {
 		t.Fatal(err)
 	}
 }
 
+// 🚨 SECURITY: This tests that expired access tokens are invalid.
+func TestAccessTokens_Lookup_expired(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	subject, err := Users.Create(ctx, NewUser{
+		Email:                 "a@example.com",
+		Username:              "u1",
+		Password:              "p1",
+		EmailVerificationCode: "c1",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	future := time.Now().Add(time.Hour)
+	_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", subject.ID, &future)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err != nil {
+		t.Fatalf("Lookup: want no error looking up unexpired token, got %v", err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	tid1, tv1, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n1", subject.ID, &past)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := AccessTokens.Lookup(ctx, tv1, []string{"a"}); err != ErrAccessTokenNotFound {
+		t.Fatalf("Lookup: want ErrAccessTokenNotFound looking up expired token, got %v", err)
+	}
+
+	// Expired tokens are still listed, so that users can see why they are invalid.
+	got, err := AccessTokens.GetByID(ctx, tid1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ExpiresAt == nil || !got.ExpiresAt.Equal(past.Truncate(time.Microsecond)) {
+		t.Errorf("got ExpiresAt %v, want %v", got.ExpiresAt, past)
+	}
+}
+
 // 🚨 SECURITY: This tests that deleting the subject or creator user of an access token invalidates
 // the token, and that no new access tokens may be created for deleted users.
 func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
@@ -255,18 +305,18 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
 		if err := Users.Delete(ctx, subject.ID); err != nil {
 			t.Fatal(err)
 		}
-		if _, err := AccessTokens.Lookup(ctx, tv0, "a"); err == nil {
+		if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err == nil {
 			t.Fatal("Lookup: want error looking up token for deleted subject user")
 		}
 
-		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID, nil); err == nil {
 			t.Fatal("Create: want error creating token for deleted subject user")
 		}
 	})
@@ -291,18 +341,18 @@ func TestAccessTokens_Lookup_deletedUser(t *testing.T) {
 			t.Fatal(err)
 		}
 
-		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID)
+		_, tv0, err := AccessTokens.Create(ctx, subject.ID, []string{"a"}, "n0", creator.ID, nil)
 		if err != nil {
 			t.Fatal(err)
 		}
 		if err := Users.Delete(ctx, creator.ID); err != nil {
 			t.Fatal(err)
 		}
-		if _, err := AccessTokens.Lookup(ctx, tv0, "a"); err == nil {
+		if _, _, err := AccessTokens.Lookup(ctx, tv0, []string{"a"}); err == nil {
 			t.Fatal("Lookup: want error looking up token for deleted creator user")
 		}
 
-		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID); err == nil {
+		if _, _, err := AccessTokens.Create(ctx, subject.ID, nil, "n0", creator.ID, nil); err == nil {
 			t.Fatal("Create: want error creating token for deleted creator user")
 		}
 	})
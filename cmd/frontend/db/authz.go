@@ -50,6 +50,9 @@ type AuthzStore interface {
 	// The returned list must be a list of repositories that are authorized to the given user.
 	// It is a no-op in the OSS version.
 	AuthorizedRepos(ctx context.Context, args *AuthorizedReposArgs) ([]*types.Repo, error)
+	// AccessGrantedRepos returns the repositories in the candidate list that the user has active
+	// time-limited access grants for. It is a no-op in the OSS version.
+	AccessGrantedRepos(ctx context.Context, args *AuthorizedReposArgs) ([]*types.Repo, error)
 	// RevokeUserPermissions deletes both effective and pending permissions that could be related to a user.
 	// It is a no-op in the OSS version.
 	RevokeUserPermissions(ctx context.Context, args *RevokeUserPermissionsArgs) error
@@ -72,6 +75,13 @@ func (*authzStore) AuthorizedRepos(ctx context.Context, args *AuthorizedReposArg
 	return []*types.Repo{}, nil
 }
 
+func (*authzStore) AccessGrantedRepos(ctx context.Context, args *AuthorizedReposArgs) ([]*types.Repo, error) {
+	if Mocks.Authz.AccessGrantedRepos != nil {
+		return Mocks.Authz.AccessGrantedRepos(ctx, args)
+	}
+	return []*types.Repo{}, nil
+}
+
 func (*authzStore) RevokeUserPermissions(ctx context.Context, args *RevokeUserPermissionsArgs) error {
 	if Mocks.Authz.RevokeUserPermissions != nil {
 		return Mocks.Authz.RevokeUserPermissions(ctx, args)
@@ -9,5 +9,6 @@ import (
 type MockAuthz struct {
 	GrantPendingPermissions func(ctx context.Context, args *GrantPendingPermissionsArgs) error
 	AuthorizedRepos         func(ctx context.Context, args *AuthorizedReposArgs) ([]*types.Repo, error)
+	AccessGrantedRepos      func(ctx context.Context, args *AuthorizedReposArgs) ([]*types.Repo, error)
 	RevokeUserPermissions   func(ctx context.Context, args *RevokeUserPermissionsArgs) error
 }
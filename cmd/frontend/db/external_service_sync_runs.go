@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// externalServiceSyncRuns provides read access to the history of external
+// service syncs, which repo-updater records.
+type externalServiceSyncRuns struct{}
+
+// ExternalServiceSyncRunsListOptions contains options for listing the sync runs
+// of an external service.
+type ExternalServiceSyncRunsListOptions struct {
+	ExternalServiceID int64
+	*LimitOffset
+}
+
+// List lists the sync runs of an external service, most recent first.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*externalServiceSyncRuns) List(ctx context.Context, opt ExternalServiceSyncRunsListOptions) ([]*types.ExternalServiceSyncRun, error) {
+	q := sqlf.Sprintf(`
+		SELECT id, external_service_id, started_at, finished_at, added, deleted, modified, unmodified, rate_limit_wait_ms, error
+		FROM external_service_sync_runs
+		WHERE external_service_id = %d
+		ORDER BY started_at DESC, id DESC
+		%s`,
+		opt.ExternalServiceID,
+		opt.LimitOffset.SQL(),
+	)
+
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*types.ExternalServiceSyncRun
+	for rows.Next() {
+		var (
+			r      types.ExternalServiceSyncRun
+			waitMS int64
+		)
+		if err := rows.Scan(&r.ID, &r.ExternalServiceID, &r.StartedAt, &r.FinishedAt, &r.Added, &r.Deleted, &r.Modified, &r.Unmodified, &waitMS, &r.Error); err != nil {
+			return nil, err
+		}
+		r.RateLimitWait = time.Duration(waitMS) * time.Millisecond
+		results = append(results, &r)
+	}
+	return results, rows.Err()
+}
+
+// Count counts the sync runs of an external service (ignoring limit and offset).
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin.
+func (*externalServiceSyncRuns) Count(ctx context.Context, opt ExternalServiceSyncRunsListOptions) (int, error) {
+	q := sqlf.Sprintf("SELECT COUNT(*) FROM external_service_sync_runs WHERE external_service_id = %d", opt.ExternalServiceID)
+	var count int
+	if err := dbconn.Global.QueryRowContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...).Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
@@ -14,9 +14,11 @@ type MockStores struct {
 	Orgs          MockOrgs
 	OrgMembers    MockOrgMembers
 	SavedSearches MockSavedSearches
+	RepoGroups    MockRepoGroups
 	Settings      MockSettings
 	Users         MockUsers
 	UserEmails    MockUserEmails
+	UserSessions  MockUserSessions
 
 	Phabricator MockPhabricator
 
@@ -17,6 +17,10 @@ import (
 type orgMembers struct{}
 
 func (*orgMembers) Create(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+	if Mocks.OrgMembers.Create != nil {
+		return Mocks.OrgMembers.Create(ctx, orgID, userID)
+	}
+
 	m := types.OrgMembership{
 		OrgID:  orgID,
 		UserID: userID,
@@ -37,6 +41,9 @@ func (*orgMembers) Create(ctx context.Context, orgID, userID int32) (*types.OrgM
 }
 
 func (m *orgMembers) GetByUserID(ctx context.Context, userID int32) ([]*types.OrgMembership, error) {
+	if Mocks.OrgMembers.GetByUserID != nil {
+		return Mocks.OrgMembers.GetByUserID(ctx, userID)
+	}
 	return m.getBySQL(ctx, "INNER JOIN users ON org_members.user_id=users.id WHERE org_members.user_id=$1 AND users.deleted_at IS NULL", userID)
 }
 
@@ -48,12 +55,19 @@ func (m *orgMembers) GetByOrgIDAndUserID(ctx context.Context, orgID, userID int3
 }
 
 func (*orgMembers) Remove(ctx context.Context, orgID, userID int32) error {
+	if Mocks.OrgMembers.Remove != nil {
+		return Mocks.OrgMembers.Remove(ctx, orgID, userID)
+	}
 	_, err := dbconn.Global.ExecContext(ctx, "DELETE FROM org_members WHERE (org_id=$1 AND user_id=$2)", orgID, userID)
 	return err
 }
 
 // GetByOrgID returns a list of all members of a given organization.
 func (*orgMembers) GetByOrgID(ctx context.Context, orgID int32) ([]*types.OrgMembership, error) {
+	if Mocks.OrgMembers.GetByOrgID != nil {
+		return Mocks.OrgMembers.GetByOrgID(ctx, orgID)
+	}
+
 	org, err := Orgs.GetByID(ctx, orgID)
 	if err != nil {
 		return nil, err
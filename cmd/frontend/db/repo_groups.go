@@ -0,0 +1,227 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// repoGroups provides access to the rule based repository groups.
+type repoGroups struct{}
+
+type repoGroupNotFoundError struct {
+	args []interface{}
+}
+
+func (err repoGroupNotFoundError) Error() string {
+	return fmt.Sprintf("repo group not found: %v", err.args)
+}
+
+func (repoGroupNotFoundError) NotFound() bool {
+	return true
+}
+
+// errRepoGroupNameExists is returned when a repo group is created or renamed
+// with a name that is already in use.
+var errRepoGroupNameExists = errors.New("a repo group with this name already exists")
+
+// RepoGroupsListOptions contains options for listing repo groups.
+type RepoGroupsListOptions struct {
+	// OrgID, if set, only lists the repo groups owned by this organization.
+	OrgID int32
+	*LimitOffset
+}
+
+func (o RepoGroupsListOptions) sqlConditions() []*sqlf.Query {
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if o.OrgID != 0 {
+		conds = append(conds, sqlf.Sprintf("org_id=%d", o.OrgID))
+	}
+	return conds
+}
+
+// List lists repo groups, ordered by name.
+//
+// 🚨 SECURITY: Repo group definitions are visible to all users, since any user
+// can search them with repogroup:. Callers must ensure that only authorized
+// users can modify them.
+func (s *repoGroups) List(ctx context.Context, opt RepoGroupsListOptions) (groups []*types.RepoGroup, err error) {
+	if Mocks.RepoGroups.List != nil {
+		return Mocks.RepoGroups.List(ctx, opt)
+	}
+
+	tr, ctx := trace.New(ctx, "db.RepoGroups.List", "")
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	q := sqlf.Sprintf("WHERE %s ORDER BY name ASC %s", sqlf.Join(opt.sqlConditions(), "AND"), opt.LimitOffset.SQL())
+	return s.getBySQL(ctx, q)
+}
+
+// GetByID returns the repo group with the given ID.
+func (s *repoGroups) GetByID(ctx context.Context, id int32) (*types.RepoGroup, error) {
+	if Mocks.RepoGroups.GetByID != nil {
+		return Mocks.RepoGroups.GetByID(ctx, id)
+	}
+
+	groups, err := s.getBySQL(ctx, sqlf.Sprintf("WHERE id=%d", id))
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, repoGroupNotFoundError{[]interface{}{id}}
+	}
+	return groups[0], nil
+}
+
+// Create creates a new repo group. The ID field must be zero, or an error
+// will be returned.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin or, for
+// groups owned by an organization, a member of that organization.
+func (s *repoGroups) Create(ctx context.Context, group *types.RepoGroup) (err error) {
+	if Mocks.RepoGroups.Create != nil {
+		return Mocks.RepoGroups.Create(ctx, group)
+	}
+
+	if group.ID != 0 {
+		return errors.New("newRepoGroup.ID must be zero")
+	}
+
+	tr, ctx := trace.New(ctx, "db.RepoGroups.Create", "")
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	rules, err := json.Marshal(group.Rules)
+	if err != nil {
+		return err
+	}
+
+	err = dbconn.Global.QueryRowContext(ctx,
+		"INSERT INTO repo_groups(name, description, rules, org_id) VALUES($1, $2, $3, $4) RETURNING id, created_at, updated_at",
+		group.Name, group.Description, rules, group.OrgID,
+	).Scan(&group.ID, &group.CreatedAt, &group.UpdatedAt)
+	return repoGroupError(err)
+}
+
+// Update updates the name, description and rules of an existing repo group.
+// The owner of a repo group can't be changed.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin or, for
+// groups owned by an organization, a member of that organization.
+func (s *repoGroups) Update(ctx context.Context, group *types.RepoGroup) (err error) {
+	if Mocks.RepoGroups.Update != nil {
+		return Mocks.RepoGroups.Update(ctx, group)
+	}
+
+	tr, ctx := trace.New(ctx, "db.RepoGroups.Update", "")
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	rules, err := json.Marshal(group.Rules)
+	if err != nil {
+		return err
+	}
+
+	err = dbconn.Global.QueryRowContext(ctx,
+		"UPDATE repo_groups SET name=$1, description=$2, rules=$3, updated_at=now() WHERE id=$4 RETURNING created_at, updated_at",
+		group.Name, group.Description, rules, group.ID,
+	).Scan(&group.CreatedAt, &group.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return repoGroupNotFoundError{[]interface{}{group.ID}}
+	}
+	return repoGroupError(err)
+}
+
+// Delete hard-deletes an existing repo group.
+//
+// 🚨 SECURITY: The caller must ensure that the actor is a site admin or, for
+// groups owned by an organization, a member of that organization.
+func (s *repoGroups) Delete(ctx context.Context, id int32) error {
+	if Mocks.RepoGroups.Delete != nil {
+		return Mocks.RepoGroups.Delete(ctx, id)
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx, "DELETE FROM repo_groups WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return repoGroupNotFoundError{[]interface{}{id}}
+	}
+	return nil
+}
+
+func (*repoGroups) getBySQL(ctx context.Context, querySuffix *sqlf.Query) ([]*types.RepoGroup, error) {
+	q := sqlf.Sprintf("SELECT id, name, description, rules, org_id, created_at, updated_at FROM repo_groups %s", querySuffix)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []*types.RepoGroup
+	for rows.Next() {
+		var (
+			g     types.RepoGroup
+			rules []byte
+		)
+		if err := rows.Scan(&g.ID, &g.Name, &g.Description, &rules, &g.OrgID, &g.CreatedAt, &g.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(rules, &g.Rules); err != nil {
+			return nil, errors.Wrapf(err, "repo group %d: invalid rules", g.ID)
+		}
+		groups = append(groups, &g)
+	}
+	return groups, rows.Err()
+}
+
+func repoGroupError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Constraint {
+		case "repo_groups_name_unique":
+			return errRepoGroupNameExists
+		case "repo_groups_name_valid_chars":
+			return errors.New("repo group names may only contain letters, digits, '_', '.' and '-'")
+		}
+	}
+	return err
+}
+
+// RepoGroupReposListOptions returns the options to list the repositories that
+// match the given repo group rules with Repos.List.
+func RepoGroupReposListOptions(rules types.RepoGroupRules) ReposListOptions {
+	opt := ReposListOptions{
+		Topics:             rules.Topics,
+		Languages:          rules.Languages,
+		Visibilities:       rules.Visibilities,
+		ExternalServiceIDs: rules.ExternalServiceIDs,
+	}
+	if rules.NamePattern != "" {
+		opt.IncludePatterns = []string{rules.NamePattern}
+	}
+	if rules.Archived != nil {
+		opt.OnlyArchived = *rules.Archived
+		opt.NoArchived = !*rules.Archived
+	}
+	return opt
+}
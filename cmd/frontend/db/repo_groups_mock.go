@@ -0,0 +1,15 @@
+package db
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+type MockRepoGroups struct {
+	List    func(ctx context.Context, opt RepoGroupsListOptions) ([]*types.RepoGroup, error)
+	GetByID func(ctx context.Context, id int32) (*types.RepoGroup, error)
+	Create  func(ctx context.Context, group *types.RepoGroup) error
+	Update  func(ctx context.Context, group *types.RepoGroup) error
+	Delete  func(ctx context.Context, id int32) error
+}
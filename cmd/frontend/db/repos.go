@@ -3,11 +3,13 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	regexpsyntax "regexp/syntax"
 	"strings"
 
 	"github.com/keegancsmith/sqlf"
+	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db/query"
@@ -152,6 +154,10 @@ var getBySQLColumns = []string{
 	"uri",
 	"description",
 	"language",
+	"topics",
+	"stars",
+	"default_branch",
+	"visibility",
 }
 
 func (s *repos) getBySQL(ctx context.Context, querySuffix *sqlf.Query) ([]*types.Repo, error) {
@@ -208,7 +214,8 @@ func scanRepo(rows *sql.Rows, r *types.Repo) (err error) {
 		)
 	}
 
-	return rows.Scan(
+	var topics []byte
+	err = rows.Scan(
 		&r.ID,
 		&r.Name,
 		&r.Private,
@@ -217,8 +224,25 @@ func scanRepo(rows *sql.Rows, r *types.Repo) (err error) {
 		&dbutil.NullString{S: &r.ExternalRepo.ServiceID},
 		&dbutil.NullString{S: &r.URI},
 		&r.Description,
-		&r.Language,
+		&dbutil.NullString{S: &r.Language},
+		&topics,
+		&r.Stars,
+		&r.DefaultBranch,
+		&r.Visibility,
 	)
+	if err != nil {
+		return err
+	}
+
+	if err = json.Unmarshal(topics, &r.Topics); err != nil {
+		return err
+	}
+
+	if len(r.Topics) == 0 {
+		r.Topics = nil
+	}
+
+	return nil
 }
 
 // ReposListOptions specifies the options for listing repositories.
@@ -253,6 +277,26 @@ type ReposListOptions struct {
 	// OnlyArchived excludes non-archived repositories from the list.
 	OnlyArchived bool
 
+	// Topics is a list of code host topics, all of which must be labelled on
+	// all repositories returned in the list.
+	Topics []string
+
+	// ExcludeTopics is a list of code host topics, none of which may be labelled
+	// on any repository returned in the list.
+	ExcludeTopics []string
+
+	// Languages, if set, excludes repositories whose primary language (as
+	// reported by the code host) is not in the list.
+	Languages []string
+
+	// Visibilities, if set, excludes repositories whose code host visibility
+	// is not in the list.
+	Visibilities []string
+
+	// ExternalServiceIDs, if set, excludes repositories not yielded by any of
+	// the given external services.
+	ExternalServiceIDs []int64
+
 	// OnlyRepoIDs skips fetching of RepoFields in each Repo.
 	OnlyRepoIDs bool
 
@@ -441,6 +485,29 @@ func (*repos) listSQL(opt ReposListOptions) (conds []*sqlf.Query, err error) {
 	if opt.OnlyArchived {
 		conds = append(conds, sqlf.Sprintf("archived"))
 	}
+	if len(opt.Topics) > 0 {
+		topics, err := json.Marshal(opt.Topics)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, sqlf.Sprintf("topics @> %s::jsonb", string(topics)))
+	}
+	for _, topic := range opt.ExcludeTopics {
+		conds = append(conds, sqlf.Sprintf("NOT topics ? %s", topic))
+	}
+	if len(opt.Languages) > 0 {
+		conds = append(conds, sqlf.Sprintf("lower(language) = ANY(%s)", pq.Array(lowerAll(opt.Languages))))
+	}
+	if len(opt.Visibilities) > 0 {
+		conds = append(conds, sqlf.Sprintf("visibility = ANY(%s)", pq.Array(opt.Visibilities)))
+	}
+	if len(opt.ExternalServiceIDs) > 0 {
+		conds = append(conds, sqlf.Sprintf(`EXISTS (
+			SELECT 1 FROM external_services es
+			WHERE es.id = ANY(%s) AND es.deleted_at IS NULL
+			AND repo.sources ? ('extsvc:' || lower(es.kind) || ':' || es.id)
+		)`, pq.Array(opt.ExternalServiceIDs)))
+	}
 
 	if opt.Index != nil {
 		// We don't currently have an index column, but when we want the
@@ -455,6 +522,14 @@ func (*repos) listSQL(opt ReposListOptions) (conds []*sqlf.Query, err error) {
 	return conds, nil
 }
 
+func lowerAll(ss []string) []string {
+	lowered := make([]string, len(ss))
+	for i, s := range ss {
+		lowered[i] = strings.ToLower(s)
+	}
+	return lowered
+}
+
 // parseIncludePattern either (1) parses the pattern into a list of exact possible
 // string values and LIKE patterns if such a list can be determined from the pattern,
 // and (2) returns the original regexp if those patterns are not equivalent to the
@@ -306,6 +306,60 @@ func TestRepos_List_fork(t *testing.T) {
 	}
 }
 
+func TestRepos_List_topics(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+
+	MockAuthzFilter = func(ctx context.Context, repos []*types.Repo, p authz.Perms) ([]*types.Repo, error) {
+		return repos, nil
+	}
+	defer func() { MockAuthzFilter = nil }()
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+	ctx = actor.WithActor(ctx, &actor.Actor{})
+
+	setTopics := func(name api.RepoName, topics string) {
+		if _, err := dbconn.Global.ExecContext(ctx, `UPDATE repo SET topics = $1 WHERE name = $2`, topics, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mustCreate(ctx, t,
+		&types.Repo{Name: "a/payments"},
+		&types.Repo{Name: "a/billing"},
+		&types.Repo{Name: "a/docs"},
+	)
+	setTopics("a/payments", `["payments", "backend"]`)
+	setTopics("a/billing", `["payments"]`)
+
+	for _, tc := range []struct {
+		topics []string
+		want   []api.RepoName
+	}{
+		{topics: nil, want: []api.RepoName{"a/billing", "a/docs", "a/payments"}},
+		{topics: []string{"payments"}, want: []api.RepoName{"a/billing", "a/payments"}},
+		{topics: []string{"payments", "backend"}, want: []api.RepoName{"a/payments"}},
+		{topics: []string{"frontend"}, want: nil},
+	} {
+		repos, err := Repos.List(ctx, ReposListOptions{Topics: tc.topics})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := sortedRepoNames(repos); !reflect.DeepEqual(have, tc.want) {
+			t.Errorf("topics %q: have %q, want %q", tc.topics, have, tc.want)
+		}
+	}
+
+	repo, err := Repos.GetByName(ctx, "a/payments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"payments", "backend"}; !reflect.DeepEqual(repo.Topics, want) {
+		t.Errorf("have topics %q, want %q", repo.Topics, want)
+	}
+}
+
 func TestRepos_List_pagination(t *testing.T) {
 	if testing.Short() {
 		t.Skip()
@@ -16,6 +16,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 )
@@ -29,6 +30,55 @@ var authzFilterDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 
 var MockAuthzFilter func(ctx context.Context, repos []*types.Repo, p authz.Perms) ([]*types.Repo, error)
 
+// AuthzRule is the rule of the enforcement policy of authzFilter that decides whether a repository
+// is accessible.
+type AuthzRule string
+
+const (
+	AuthzRuleInternalActor  AuthzRule = "INTERNAL_ACTOR"   // internal actors can access all repositories
+	AuthzRuleSiteAdmin      AuthzRule = "SITE_ADMIN"       // site admins can access all repositories
+	AuthzRuleAccessGrant    AuthzRule = "ACCESS_GRANT"     // the user has an access grant for the repository
+	AuthzRuleUserMapping    AuthzRule = "USER_MAPPING"     // the permissions user mapping decides
+	AuthzRuleAllowByDefault AuthzRule = "ALLOW_BY_DEFAULT" // no authz providers, and everyone can access all repositories
+	AuthzRulePublic         AuthzRule = "PUBLIC"           // background sync is enabled, and the repository is public
+	AuthzRulePublicOnly     AuthzRule = "PUBLIC_ONLY"      // background sync is enabled, but no authz providers (or anonymous user)
+	AuthzRuleSyncedPerms    AuthzRule = "SYNCED_PERMS"     // background sync is enabled, and the synced permissions decide
+	AuthzRuleProvider       AuthzRule = "PROVIDER"         // the authz provider of the repository's code host decides
+	AuthzRuleAllowUnmatched AuthzRule = "ALLOW_UNMATCHED"  // no authz provider for the repository's code host, and authzAllowByDefault is true
+	AuthzRuleDenyUnmatched  AuthzRule = "DENY_UNMATCHED"   // no authz provider for the repository's code host, and authzAllowByDefault is false
+	AuthzRuleNoExternalRepo AuthzRule = "NO_EXTERNAL_REPO" // the repository has no external repo spec, and is never accessible
+)
+
+type authzRulesKey struct{}
+
+// authzRules records the rules that authzFilter applied to repositories, by repository ID.
+type authzRules map[api.RepoID]AuthzRule
+
+func authzRulesFromContext(ctx context.Context) authzRules {
+	rules, _ := ctx.Value(authzRulesKey{}).(authzRules)
+	return rules
+}
+
+func (rs authzRules) record(rule AuthzRule, repos ...*types.Repo) {
+	if rs == nil {
+		return
+	}
+	for _, r := range repos {
+		rs[r.ID] = rule
+	}
+}
+
+// ExplainRepoPerms evaluates whether the currently authenticated user has the permission p for
+// the repository, and returns the rule of the enforcement policy that decided it.
+func ExplainRepoPerms(ctx context.Context, repo *types.Repo, p authz.Perms) (allowed bool, rule AuthzRule, err error) {
+	rules := authzRules{}
+	filtered, err := authzFilter(context.WithValue(ctx, authzRulesKey{}, rules), []*types.Repo{repo}, p)
+	if err != nil {
+		return false, "", err
+	}
+	return len(filtered) == 1, rules[repo.ID], nil
+}
+
 // authzFilter is the enforcement mechanism for repository permissions. It is the root
 // repository-permission-enforcing function (i.e., all other code that wants to check/enforce
 // permissions and is not itself part of the permission-checking code should call this function).
@@ -40,6 +90,10 @@ var MockAuthzFilter func(ctx context.Context, repos []*types.Repo, p authz.Perms
 //
 // The enforcement policy:
 //
+// - Repositories the user has active time-limited access grants for (directly or through the
+//   membership of an organization) are always accessible with read permission, in addition to
+//   the ones allowed by the rest of the policy.
+//
 // - If permissions user mapping is enabled, directly check permissions against local Postgres.
 //
 // - If there are no authz providers and `authzAllowByDefault` is true, then the repository is
@@ -63,6 +117,7 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 	}
 
 	var currentUser *types.User
+	rules := authzRulesFromContext(ctx)
 
 	began := time.Now()
 	tr, ctx := trace.New(ctx, "authzFilter", "")
@@ -90,6 +145,7 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 	}()
 
 	if isInternalActor(ctx) {
+		rules.record(AuthzRuleInternalActor, repos...)
 		return repos, nil
 	}
 
@@ -100,10 +156,42 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 			return nil, err
 		}
 		if currentUser.SiteAdmin {
+			rules.record(AuthzRuleSiteAdmin, repos...)
 			return repos, nil
 		}
 	}
 
+	// Time-limited access grants are honoured in addition to all other permissions, so the
+	// granted repositories are added back to whatever the rest of the policy filters in.
+	var granted []*types.Repo
+	if currentUser != nil && len(repos) > 0 {
+		granted, err = Authz.AccessGrantedRepos(ctx, &AuthorizedReposArgs{
+			Repos:  repos,
+			UserID: currentUser.ID,
+			Perm:   p,
+			Type:   authz.PermRepos,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "load access grants")
+		}
+	}
+	if len(granted) > 0 {
+		// Copy the candidates because they are filtered in place.
+		all := append([]*types.Repo(nil), repos...)
+		defer func() {
+			if err == nil {
+				// Access grants only decide about the repositories the rest of the policy
+				// filtered out.
+				for _, r := range granted {
+					if !containsRepo(filtered, r.ID) {
+						rules.record(AuthzRuleAccessGrant, r)
+					}
+				}
+				filtered = withAccessGranted(all, filtered, granted)
+			}
+		}()
+	}
+
 	authzAllowByDefault, authzProviders := authz.GetProviders()
 	tr.LogFields(
 		otlog.Bool("authzAllowByDefault", authzAllowByDefault),
@@ -119,6 +207,7 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 			return nil, errors.New("Anonymous access is not allow when permissions user mapping is enabled.")
 		}
 
+		rules.record(AuthzRuleUserMapping, repos...)
 		return Authz.AuthorizedRepos(ctx, &AuthorizedReposArgs{
 			Repos:  repos,
 			UserID: currentUser.ID,
@@ -137,6 +226,7 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 
 	// Permissions are not enforced by authz providers and everyone can see all repositories.
 	if authzAllowByDefault && len(authzProviders) == 0 {
+		rules.record(AuthzRuleAllowByDefault, repos...)
 		return repos, nil
 	}
 
@@ -154,12 +244,14 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 
 			filtered = append(filtered, r)
 		}
+		rules.record(AuthzRulePublic, filtered...)
 
 		// At this point, only show public repositories when:
 		//   1. The user is unauthenticated.
 		//   2. Permissions are not enforced by authz providers but NOT everyone can see all repositories.
 		//      Wouldn't reach this far when "authzAllowByDefault" is true and no authz providers.
 		if currentUser == nil || len(authzProviders) == 0 {
+			rules.record(AuthzRulePublicOnly, toVerify...)
 			return filtered, nil
 		}
 
@@ -230,6 +322,7 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 		}
 
 		// We should have no known pending permissions for the user at this point.
+		rules.record(AuthzRuleSyncedPerms, toVerify...)
 		verified, err := Authz.AuthorizedRepos(ctx, &AuthorizedReposArgs{
 			Repos:  toVerify,
 			UserID: currentUser.ID,
@@ -305,6 +398,7 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 		}
 
 		// check the perms on our repos
+		rules.record(AuthzRuleProvider, *ours...)
 		perms, err := authzProvider.RepoPerms(ctx, providerAcct, *ours)
 		if err != nil {
 			return nil, err
@@ -319,6 +413,16 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 		delete(toverify, serviceID)
 	}
 
+	for serviceID, rs := range toverify {
+		switch {
+		case serviceID == "":
+			rules.record(AuthzRuleNoExternalRepo, *rs...)
+		case authzAllowByDefault:
+			rules.record(AuthzRuleAllowUnmatched, *rs...)
+		default:
+			rules.record(AuthzRuleDenyUnmatched, *rs...)
+		}
+	}
 	if authzAllowByDefault {
 		for serviceID, rs := range toverify {
 			// 🚨 SECURITY: Defensively bar access to repos with no external repo spec (we don't know
@@ -345,6 +449,35 @@ func authzFilter(ctx context.Context, repos []*types.Repo, p authz.Perms) (filte
 	return filtered, nil
 }
 
+// withAccessGranted returns the repositories in the candidate list that are either filtered
+// in or access granted, preserving their order. The candidate list is filtered in place.
+func withAccessGranted(candidates, filtered, granted []*types.Repo) []*types.Repo {
+	ids := roaring.NewBitmap()
+	for _, r := range filtered {
+		ids.Add(uint32(r.ID))
+	}
+	for _, r := range granted {
+		ids.Add(uint32(r.ID))
+	}
+
+	merged := candidates[:0]
+	for _, r := range candidates {
+		if ids.Contains(uint32(r.ID)) {
+			merged = append(merged, r)
+		}
+	}
+	return merged
+}
+
+func containsRepo(repos []*types.Repo, id api.RepoID) bool {
+	for _, r := range repos {
+		if r.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // isInternalActor returns true if the actor represents an internal agent (i.e., non-user-bound
 // request that originates from within Sourcegraph itself).
 //
@@ -68,6 +68,20 @@ func (r authzFilter_Test) run(t *testing.T) {
 		Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.ExternalAccountSpec, data extsvc.ExternalAccountData) error { return nil }
 		Mocks.ExternalAccounts.List = func(ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) { return c.userAccounts, nil }
 
+		// The explanation of the decision about each repository must agree with the filter.
+		for _, repo := range c.repos {
+			allowed, rule, err := ExplainRepoPerms(ctx, repo, c.perm)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := containsRepo(c.expFilteredRepos, repo.ID); allowed != want {
+				t.Errorf("repo %q: explained allowed %v (rule %s), want %v", repo.Name, allowed, rule, want)
+			}
+			if rule == "" {
+				t.Errorf("repo %q: no rule explains the decision", repo.Name)
+			}
+		}
+
 		filteredRepos, err := authzFilter(ctx, c.repos, c.perm)
 		if err != nil {
 			t.Fatal(err)
@@ -918,6 +932,12 @@ func Test_authzFilter_permissionsBackgroudSync(t *testing.T) {
 		if diff := cmp.Diff(expRepos, repos); diff != "" {
 			t.Fatal(diff)
 		}
+
+		if allowed, rule, err := ExplainRepoPerms(ctx, privateRepo, authz.Read); err != nil {
+			t.Fatal(err)
+		} else if allowed || rule != AuthzRulePublicOnly {
+			t.Errorf("want denied by rule %s but got allowed %v by %s", AuthzRulePublicOnly, allowed, rule)
+		}
 	})
 
 	t.Run("authenticated user with matching external account should see all repos", func(t *testing.T) {
@@ -1057,6 +1077,89 @@ func Test_authzFilter_permissionsBackgroudSync(t *testing.T) {
 	})
 }
 
+func Test_authzFilter_accessGrants(t *testing.T) {
+	authz.SetProviders(true,
+		[]authz.Provider{
+			&MockAuthzProvider{
+				serviceID:   "https://gitlab.mine/",
+				serviceType: "gitlab",
+			},
+		},
+	)
+	defer authz.SetProviders(true, nil)
+
+	denied := makeRepo("gitlab.mine/user/denied", 1, true)
+	allowed := makeRepo("gitlab.other/user/allowed", 2, false)
+	granted := makeRepo("gitlab.mine/user/granted", 3, true)
+
+	user := &types.User{ID: 1}
+	Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return user, nil
+	}
+	Mocks.ExternalAccounts.List = func(ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		return nil, nil
+	}
+	Mocks.Authz.AccessGrantedRepos = func(_ context.Context, args *AuthorizedReposArgs) ([]*types.Repo, error) {
+		if args.UserID != user.ID {
+			return nil, fmt.Errorf("UserID: want %d but got %d", user.ID, args.UserID)
+		}
+		for _, r := range args.Repos {
+			if r.ID == granted.ID {
+				return []*types.Repo{r}, nil
+			}
+		}
+		return []*types.Repo{}, nil
+	}
+	defer func() {
+		Mocks.Users = MockUsers{}
+		Mocks.ExternalAccounts = MockExternalAccounts{}
+		Mocks.Authz = MockAuthz{}
+	}()
+
+	t.Run("unauthenticated user can't use access grants", func(t *testing.T) {
+		repos, err := authzFilter(context.Background(), []*types.Repo{denied, allowed, granted}, authz.Read)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expRepos := []*types.Repo{allowed}
+		if diff := cmp.Diff(expRepos, repos); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+
+	t.Run("authenticated user can see granted repos in order", func(t *testing.T) {
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: user.ID})
+		repos, err := authzFilter(ctx, []*types.Repo{granted, denied, allowed}, authz.Read)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expRepos := []*types.Repo{granted, allowed}
+		if diff := cmp.Diff(expRepos, repos); diff != "" {
+			t.Fatal(diff)
+		}
+
+		for _, tc := range []struct {
+			repo    *types.Repo
+			allowed bool
+			rule    AuthzRule
+		}{
+			{repo: granted, allowed: true, rule: AuthzRuleAccessGrant},
+			{repo: denied, allowed: false, rule: AuthzRuleProvider},
+			{repo: allowed, allowed: true, rule: AuthzRuleAllowUnmatched},
+		} {
+			ok, rule, err := ExplainRepoPerms(ctx, tc.repo, authz.Read)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.allowed || rule != tc.rule {
+				t.Errorf("repo %q: want allowed %v by rule %s but got %v by %s", tc.repo.Name, tc.allowed, tc.rule, ok, rule)
+			}
+		}
+	})
+}
+
 func acct(userID int32, serviceType, serviceID, accountID string) *extsvc.ExternalAccount {
 	return &extsvc.ExternalAccount{
 		UserID: userID,
@@ -3,11 +3,13 @@ package db
 var (
 	AccessTokens              = &accessTokens{}
 	ExternalServices          = &ExternalServicesStore{}
+	ExternalServiceSyncRuns   = &externalServiceSyncRuns{}
 	DefaultRepos              = &defaultRepos{}
 	DiscussionThreads         = &discussionThreads{}
 	DiscussionComments        = &discussionComments{}
 	DiscussionMailReplyTokens = &discussionMailReplyTokens{}
 	Repos                     = &repos{}
+	RepoGroups                = &repoGroups{}
 	Phabricator               = &phabricator{}
 	QueryRunnerState          = &queryRunnerState{}
 	Orgs                      = &orgs{}
@@ -16,6 +18,7 @@ var (
 	Settings                  = &settings{}
 	Users                     = &users{}
 	UserEmails                = &userEmails{}
+	UserSessions              = &userSessions{}
 	EventLogs                 = &eventLogs{}
 
 	SurveyResponses = &surveyResponses{}
@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/keegancsmith/sqlf"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// UserSession is a registered session of a user (such as a signed-in browser). The session data
+// itself is stored in the session store (Redis), and refers to its registered session by key.
+type UserSession struct {
+	ID         int64
+	UserID     int32
+	Key        string // the random key stored in the session data
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	ExpiresAt  time.Time // when the session expires unless it is seen again
+}
+
+// UserSessionNotFoundError occurs when a session is not found (usually because it was revoked).
+type UserSessionNotFoundError struct {
+	args []interface{}
+}
+
+func (err UserSessionNotFoundError) Error() string {
+	return fmt.Sprintf("user session not found: %v", err.args)
+}
+
+func (UserSessionNotFoundError) NotFound() bool { return true }
+
+type userSessions struct{}
+
+// Create registers a new session for the user. The ID, CreatedAt and LastSeenAt fields of s are
+// set from the created row. It also deletes the user's expired sessions, so that the registry
+// doesn't grow with every sign-in.
+func (*userSessions) Create(ctx context.Context, s *UserSession) error {
+	if Mocks.UserSessions.Create != nil {
+		return Mocks.UserSessions.Create(ctx, s)
+	}
+
+	if _, err := dbconn.Global.ExecContext(ctx, "DELETE FROM user_sessions WHERE user_id=$1 AND expires_at<=now()", s.UserID); err != nil {
+		return err
+	}
+	return dbconn.Global.QueryRowContext(ctx,
+		"INSERT INTO user_sessions(user_id, key, user_agent, ip, expires_at) VALUES($1, $2, $3, $4, $5) RETURNING id, created_at, last_seen_at",
+		s.UserID, s.Key, s.UserAgent, s.IP, s.ExpiresAt,
+	).Scan(&s.ID, &s.CreatedAt, &s.LastSeenAt)
+}
+
+// GetByID returns the session with the given ID.
+func (s *userSessions) GetByID(ctx context.Context, id int64) (*UserSession, error) {
+	if Mocks.UserSessions.GetByID != nil {
+		return Mocks.UserSessions.GetByID(ctx, id)
+	}
+	return s.getOne(ctx, sqlf.Sprintf("id=%s", id))
+}
+
+// GetByKey returns the session with the given key. It returns a UserSessionNotFoundError if the
+// session was revoked or expired.
+func (s *userSessions) GetByKey(ctx context.Context, key string) (*UserSession, error) {
+	if Mocks.UserSessions.GetByKey != nil {
+		return Mocks.UserSessions.GetByKey(ctx, key)
+	}
+	return s.getOne(ctx, sqlf.Sprintf("key=%s", key))
+}
+
+// ListByUser returns the unexpired sessions of the user, most recently seen first.
+func (s *userSessions) ListByUser(ctx context.Context, userID int32) ([]*UserSession, error) {
+	if Mocks.UserSessions.ListByUser != nil {
+		return Mocks.UserSessions.ListByUser(ctx, userID)
+	}
+	return s.list(ctx, sqlf.Sprintf("user_id=%s", userID))
+}
+
+// Touch records that the session with the given key was seen now, from the IP address, and that it
+// now expires at expiresAt.
+func (*userSessions) Touch(ctx context.Context, key, ip string, expiresAt time.Time) error {
+	if Mocks.UserSessions.Touch != nil {
+		return Mocks.UserSessions.Touch(ctx, key, ip, expiresAt)
+	}
+
+	_, err := dbconn.Global.ExecContext(ctx, "UPDATE user_sessions SET last_seen_at=now(), ip=$1, expires_at=$2 WHERE key=$3", ip, expiresAt, key)
+	return err
+}
+
+// Delete revokes the session with the given ID. It returns a UserSessionNotFoundError if the
+// session doesn't exist.
+func (*userSessions) Delete(ctx context.Context, id int64) error {
+	if Mocks.UserSessions.Delete != nil {
+		return Mocks.UserSessions.Delete(ctx, id)
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx, "DELETE FROM user_sessions WHERE id=$1", id)
+	if err != nil {
+		return err
+	}
+	nrows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if nrows == 0 {
+		return UserSessionNotFoundError{args: []interface{}{id}}
+	}
+	return nil
+}
+
+// DeleteByKey revokes the session with the given key, if it exists.
+func (*userSessions) DeleteByKey(ctx context.Context, key string) error {
+	if Mocks.UserSessions.DeleteByKey != nil {
+		return Mocks.UserSessions.DeleteByKey(ctx, key)
+	}
+
+	_, err := dbconn.Global.ExecContext(ctx, "DELETE FROM user_sessions WHERE key=$1", key)
+	return err
+}
+
+// DeleteByUser revokes all sessions of the user, except the session with the key exceptKey (if
+// not empty).
+func (*userSessions) DeleteByUser(ctx context.Context, userID int32, exceptKey string) error {
+	if Mocks.UserSessions.DeleteByUser != nil {
+		return Mocks.UserSessions.DeleteByUser(ctx, userID, exceptKey)
+	}
+
+	_, err := dbconn.Global.ExecContext(ctx, "DELETE FROM user_sessions WHERE user_id=$1 AND key<>$2", userID, exceptKey)
+	return err
+}
+
+func (s *userSessions) getOne(ctx context.Context, cond *sqlf.Query) (*UserSession, error) {
+	sessions, err := s.list(ctx, cond)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) != 1 {
+		return nil, UserSessionNotFoundError{args: cond.Args()}
+	}
+	return sessions[0], nil
+}
+
+func (*userSessions) list(ctx context.Context, cond *sqlf.Query) ([]*UserSession, error) {
+	q := sqlf.Sprintf("SELECT id, user_id, key, user_agent, ip, created_at, last_seen_at, expires_at FROM user_sessions WHERE %s AND expires_at>now() ORDER BY last_seen_at DESC, id DESC", cond)
+	rows, err := dbconn.Global.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*UserSession
+	for rows.Next() {
+		var s UserSession
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Key, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, &s)
+	}
+	return sessions, rows.Err()
+}
@@ -0,0 +1,17 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type MockUserSessions struct {
+	Create       func(ctx context.Context, s *UserSession) error
+	GetByID      func(ctx context.Context, id int64) (*UserSession, error)
+	GetByKey     func(ctx context.Context, key string) (*UserSession, error)
+	ListByUser   func(ctx context.Context, userID int32) ([]*UserSession, error)
+	Touch        func(ctx context.Context, key, ip string, expiresAt time.Time) error
+	Delete       func(ctx context.Context, id int64) error
+	DeleteByKey  func(ctx context.Context, key string) error
+	DeleteByUser func(ctx context.Context, userID int32, exceptKey string) error
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func TestUserSessions(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	u1, err := Users.Create(ctx, NewUser{Username: "u1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u2, err := Users.Create(ctx, NewUser{Username: "u2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	create := func(userID int32, key string) *UserSession {
+		t.Helper()
+		s := &UserSession{UserID: userID, Key: key, UserAgent: "agent", IP: "127.0.0.1", ExpiresAt: expiresAt}
+		if err := UserSessions.Create(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	s1a := create(u1.ID, "k1a")
+	create(u1.ID, "k1b")
+	s1c := create(u1.ID, "k1c")
+	create(u2.ID, "k2a")
+
+	if s, err := UserSessions.GetByKey(ctx, "k1a"); err != nil {
+		t.Fatal(err)
+	} else if s.ID != s1a.ID || s.UserID != u1.ID || s.UserAgent != "agent" || s.IP != "127.0.0.1" {
+		t.Errorf("got %+v, want %+v", s, s1a)
+	}
+
+	if err := UserSessions.Touch(ctx, "k1a", "10.0.0.1", expiresAt.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	sessions, err := UserSessions.ListByUser(ctx, u1.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 3 {
+		t.Fatalf("got %d sessions, want 3", len(sessions))
+	}
+	if sessions[0].Key != "k1a" || sessions[0].IP != "10.0.0.1" || !sessions[0].ExpiresAt.After(expiresAt) {
+		t.Errorf("got most recently seen session %+v, want the touched session", sessions[0])
+	}
+
+	// Expired sessions are not listed, and are deleted when the user signs in again.
+	if err := UserSessions.Touch(ctx, "k1b", "10.0.0.2", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UserSessions.GetByKey(ctx, "k1b"); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+	if sessions, err := UserSessions.ListByUser(ctx, u1.ID); err != nil {
+		t.Fatal(err)
+	} else if len(sessions) != 2 {
+		t.Errorf("got %d sessions, want 2", len(sessions))
+	}
+	create(u1.ID, "k1d")
+	var n int
+	if err := dbconn.Global.QueryRowContext(ctx, "SELECT COUNT(*) FROM user_sessions WHERE user_id=$1", u1.ID).Scan(&n); err != nil {
+		t.Fatal(err)
+	} else if n != 3 {
+		t.Errorf("got %d rows, want the expired session to be deleted", n)
+	}
+
+	// Revoke one session.
+	if err := UserSessions.Delete(ctx, s1a.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UserSessions.GetByKey(ctx, "k1a"); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+	if err := UserSessions.Delete(ctx, s1a.ID); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+
+	// Revoke all other sessions.
+	if err := UserSessions.DeleteByUser(ctx, u1.ID, "k1c"); err != nil {
+		t.Fatal(err)
+	}
+	if sessions, err := UserSessions.ListByUser(ctx, u1.ID); err != nil {
+		t.Fatal(err)
+	} else if len(sessions) != 1 || sessions[0].ID != s1c.ID {
+		t.Errorf("got sessions %+v, want only the excepted session", sessions)
+	}
+
+	// Deleting a user revokes its sessions, but not other users' sessions.
+	if err := Users.Delete(ctx, u1.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := UserSessions.GetByKey(ctx, "k1c"); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want not found", err)
+	}
+	if _, err := UserSessions.GetByKey(ctx, "k2a"); err != nil {
+		t.Errorf("got error %v, want other user's session to exist", err)
+	}
+}
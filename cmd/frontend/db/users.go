@@ -428,6 +428,10 @@ func (u *users) Delete(ctx context.Context, id int32) error {
 	if _, err := tx.ExecContext(ctx, "DELETE FROM user_emails WHERE user_id=$1", id); err != nil {
 		return err
 	}
+	// Revoke the user's sessions.
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_sessions WHERE user_id=$1", id); err != nil {
+		return err
+	}
 	if _, err := tx.ExecContext(ctx, "UPDATE user_external_accounts SET deleted_at=now() WHERE user_id=$1 AND deleted_at IS NULL", id); err != nil {
 		return err
 	}
@@ -542,6 +546,53 @@ func (u *users) HardDelete(ctx context.Context, id int32) error {
 	return nil
 }
 
+// SetDeactivated deactivates or reactivates the user. Deactivating a user revokes their sessions
+// and access tokens, but keeps the user and their data, unlike Delete.
+func (u *users) SetDeactivated(ctx context.Context, id int32, deactivated bool) (err error) {
+	if Mocks.Users.SetDeactivated != nil {
+		return Mocks.Users.SetDeactivated(ctx, id, deactivated)
+	}
+
+	tx, err := dbconn.Global.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			rollErr := tx.Rollback()
+			if rollErr != nil {
+				err = multierror.Append(err, rollErr)
+			}
+			return
+		}
+		err = tx.Commit()
+	}()
+
+	res, err := tx.ExecContext(ctx, "UPDATE users SET deactivated_at=(CASE WHEN $2 THEN COALESCE(deactivated_at, now()) END) WHERE id=$1 AND deleted_at IS NULL", id, deactivated)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return userNotFoundErr{args: []interface{}{id}}
+	}
+	if !deactivated {
+		return nil
+	}
+
+	// 🚨 SECURITY: Deactivated users must immediately lose access.
+	if _, err := tx.ExecContext(ctx, "UPDATE access_tokens SET deleted_at=now() WHERE subject_user_id=$1 AND deleted_at IS NULL", id); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM user_sessions WHERE user_id=$1", id); err != nil {
+		return err
+	}
+	return nil
+}
+
 func (u *users) SetIsSiteAdmin(ctx context.Context, id int32, isSiteAdmin bool) error {
 	if Mocks.Users.SetIsSiteAdmin != nil {
 		return Mocks.Users.SetIsSiteAdmin(id, isSiteAdmin)
@@ -726,7 +777,7 @@ func (u *users) getOneBySQL(ctx context.Context, query string, args ...interface
 
 // getBySQL returns users matching the SQL query, if any exist.
 func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) ([]*types.User, error) {
-	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags FROM users u "+query, args...)
+	rows, err := dbconn.Global.QueryContext(ctx, "SELECT u.id, u.username, u.display_name, u.avatar_url, u.created_at, u.updated_at, u.site_admin, u.passwd IS NOT NULL, u.tags, u.deactivated_at FROM users u "+query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -736,7 +787,7 @@ func (*users) getBySQL(ctx context.Context, query string, args ...interface{}) (
 	for rows.Next() {
 		var u types.User
 		var displayName, avatarURL sql.NullString
-		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags))
+		err := rows.Scan(&u.ID, &u.Username, &displayName, &avatarURL, &u.CreatedAt, &u.UpdatedAt, &u.SiteAdmin, &u.BuiltinAuth, pq.Array(&u.Tags), &u.DeactivatedAt)
 		if err != nil {
 			return nil, err
 		}
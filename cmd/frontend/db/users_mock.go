@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 )
@@ -13,6 +14,7 @@ type MockUsers struct {
 	Delete                       func(ctx context.Context, id int32) error
 	HardDelete                   func(ctx context.Context, id int32) error
 	SetIsSiteAdmin               func(id int32, isSiteAdmin bool) error
+	SetDeactivated               func(ctx context.Context, id int32, deactivated bool) error
 	CheckAndDecrementInviteQuota func(ctx context.Context, userID int32) (bool, error)
 	GetByID                      func(ctx context.Context, id int32) (*types.User, error)
 	GetByUsername                func(ctx context.Context, username string) (*types.User, error)
@@ -21,6 +23,15 @@ type MockUsers struct {
 	GetByVerifiedEmail           func(ctx context.Context, email string) (*types.User, error)
 	Count                        func(ctx context.Context, opt *UsersListOptions) (int, error)
 	List                         func(ctx context.Context, opt *UsersListOptions) ([]*types.User, error)
+	GetTOTP                      func(ctx context.Context, id int32) (*UserTOTP, error)
+	SetTOTPSecret                func(ctx context.Context, id int32, secret string) error
+	EnableTOTP                   func(ctx context.Context, id int32, step int64, recoveryCodeHashes []string) (bool, error)
+	UseTOTPStep                  func(ctx context.Context, id int32, step int64) (bool, error)
+	UseTOTPRecoveryCode          func(ctx context.Context, id int32, hash string) (bool, error)
+	SetTOTPRecoveryCodes         func(ctx context.Context, id int32, recoveryCodeHashes []string) error
+	DisableTOTP                  func(ctx context.Context, id int32) error
+	AddTOTPAttempt               func(ctx context.Context, id int32, window time.Duration) (int, error)
+	ResetTOTPAttempts            func(ctx context.Context, id int32) error
 }
 
 func (s *MockUsers) MockGetByID_Return(t *testing.T, returns *types.User, returnsErr error) (called *bool) {
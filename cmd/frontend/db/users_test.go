@@ -635,6 +635,54 @@ func TestUsers_Delete(t *testing.T) {
 	}
 }
 
+func TestUsers_SetDeactivated(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	user, err := Users.Create(ctx, NewUser{Username: "u"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, token, err := AccessTokens.Create(ctx, user.ID, []string{"user:all"}, "n", user.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := UserSessions.Create(ctx, &UserSession{UserID: user.ID, Key: "k", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Users.SetDeactivated(ctx, user.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	if user, err = Users.GetByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if user.DeactivatedAt == nil {
+		t.Error("got DeactivatedAt == nil, want deactivated user")
+	}
+	if _, _, err := AccessTokens.Lookup(ctx, token, []string{"user:all"}); err != ErrAccessTokenNotFound {
+		t.Errorf("got error %v, want the access token to be revoked", err)
+	}
+	if _, err := UserSessions.GetByKey(ctx, "k"); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want the session to be revoked", err)
+	}
+
+	if err := Users.SetDeactivated(ctx, user.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	if user, err = Users.GetByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	} else if user.DeactivatedAt != nil {
+		t.Errorf("got DeactivatedAt %v, want nil", user.DeactivatedAt)
+	}
+
+	if err := Users.SetDeactivated(ctx, 12345, true); !errcode.IsNotFound(err) {
+		t.Errorf("got error %v, want ErrUserNotFound", err)
+	}
+}
+
 func normalizeUsers(users []*types.User) []*types.User {
 	for _, u := range users {
 		u.CreatedAt = u.CreatedAt.Local().Round(time.Second)
@@ -0,0 +1,200 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+)
+
+// ErrTOTPAlreadyEnabled is returned when a user who has two-factor authentication enabled starts
+// another enrollment.
+var ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+// UserTOTP is the two-factor authentication state of a user. The secret is empty if the user has
+// never started an enrollment.
+//
+// 🚨 SECURITY: The secret is stored unencrypted in the users.totp_secret column (like the tokens in
+// external service configurations), because it must be read to validate codes. Anyone who can read
+// the database can generate valid codes, so access to the database and its backups must be
+// restricted accordingly. Recovery codes are only stored hashed.
+type UserTOTP struct {
+	Secret  string
+	Enabled bool
+	// RecoveryCodes is the number of unused recovery codes.
+	RecoveryCodes int
+}
+
+// GetTOTP returns the two-factor authentication state of the user.
+func (u *users) GetTOTP(ctx context.Context, id int32) (*UserTOTP, error) {
+	if Mocks.Users.GetTOTP != nil {
+		return Mocks.Users.GetTOTP(ctx, id)
+	}
+
+	var (
+		secret sql.NullString
+		t      UserTOTP
+	)
+	err := dbconn.Global.QueryRowContext(ctx,
+		"SELECT totp_secret, totp_enabled_at IS NOT NULL, cardinality(totp_recovery_codes) FROM users WHERE deleted_at IS NULL AND id=$1", id,
+	).Scan(&secret, &t.Enabled, &t.RecoveryCodes)
+	if err == sql.ErrNoRows {
+		return nil, userNotFoundErr{args: []interface{}{"id", id}}
+	} else if err != nil {
+		return nil, err
+	}
+	t.Secret = secret.String
+	return &t, nil
+}
+
+// AddTOTPAttempt records an attempt to check a two-factor authentication code of the user, and
+// returns the number of attempts in the current window. A window starts with the first attempt
+// after the previous window (of the given duration) ended, or after ResetTOTPAttempts.
+func (u *users) AddTOTPAttempt(ctx context.Context, id int32, window time.Duration) (int, error) {
+	if Mocks.Users.AddTOTPAttempt != nil {
+		return Mocks.Users.AddTOTPAttempt(ctx, id, window)
+	}
+
+	var attempts int
+	err := dbconn.Global.QueryRowContext(ctx, `
+UPDATE users SET
+  totp_attempts=(CASE WHEN totp_attempts_since > now() - $2 * interval '1 second' THEN totp_attempts + 1 ELSE 1 END),
+  totp_attempts_since=(CASE WHEN totp_attempts_since > now() - $2 * interval '1 second' THEN totp_attempts_since ELSE now() END)
+WHERE id=$1 AND deleted_at IS NULL
+RETURNING totp_attempts`,
+		id, window.Seconds(),
+	).Scan(&attempts)
+	if err == sql.ErrNoRows {
+		return 0, userNotFoundErr{args: []interface{}{"id", id}}
+	}
+	return attempts, err
+}
+
+// ResetTOTPAttempts forgets the attempts to check two-factor authentication codes of the user,
+// after a successful attempt.
+func (u *users) ResetTOTPAttempts(ctx context.Context, id int32) error {
+	if Mocks.Users.ResetTOTPAttempts != nil {
+		return Mocks.Users.ResetTOTPAttempts(ctx, id)
+	}
+
+	_, err := dbconn.Global.ExecContext(ctx, "UPDATE users SET totp_attempts=0, totp_attempts_since=NULL WHERE id=$1", id)
+	return err
+}
+
+// SetTOTPSecret starts a two-factor authentication enrollment of the user with the secret. It
+// returns ErrTOTPAlreadyEnabled if the user has already enabled two-factor authentication.
+func (u *users) SetTOTPSecret(ctx context.Context, id int32, secret string) error {
+	if Mocks.Users.SetTOTPSecret != nil {
+		return Mocks.Users.SetTOTPSecret(ctx, id, secret)
+	}
+
+	res, err := dbconn.Global.ExecContext(ctx,
+		"UPDATE users SET totp_secret=$1, totp_last_step=NULL WHERE id=$2 AND deleted_at IS NULL AND totp_enabled_at IS NULL",
+		secret, id)
+	if err != nil {
+		return err
+	}
+	return u.checkTOTPUpdated(ctx, res, id)
+}
+
+// EnableTOTP completes the two-factor authentication enrollment of the user. The step is the time
+// step of the TOTP code that confirmed the enrollment, and the recovery codes are hashed. It
+// returns false if the user has no enrollment in progress.
+func (u *users) EnableTOTP(ctx context.Context, id int32, step int64, recoveryCodeHashes []string) (bool, error) {
+	if Mocks.Users.EnableTOTP != nil {
+		return Mocks.Users.EnableTOTP(ctx, id, step, recoveryCodeHashes)
+	}
+
+	return execTOTPUpdate(ctx,
+		"UPDATE users SET totp_enabled_at=now(), totp_last_step=$1, totp_recovery_codes=$2 WHERE id=$3 AND deleted_at IS NULL AND totp_secret IS NOT NULL AND totp_enabled_at IS NULL",
+		step, pq.Array(recoveryCodeHashes), id)
+}
+
+// UseTOTPStep records that a TOTP code of the time step was used by the user. It returns false if a
+// code of the same or a later time step has already been used, so that codes can't be replayed.
+func (u *users) UseTOTPStep(ctx context.Context, id int32, step int64) (bool, error) {
+	if Mocks.Users.UseTOTPStep != nil {
+		return Mocks.Users.UseTOTPStep(ctx, id, step)
+	}
+
+	// 🚨 SECURITY: Only one use of each time step is allowed.
+	return execTOTPUpdate(ctx,
+		"UPDATE users SET totp_last_step=$1 WHERE id=$2 AND totp_enabled_at IS NOT NULL AND (totp_last_step IS NULL OR totp_last_step < $1)",
+		step, id)
+}
+
+// UseTOTPRecoveryCode consumes the user's recovery code with the given hash. It returns false if the
+// user has no such unused recovery code.
+func (u *users) UseTOTPRecoveryCode(ctx context.Context, id int32, hash string) (bool, error) {
+	if Mocks.Users.UseTOTPRecoveryCode != nil {
+		return Mocks.Users.UseTOTPRecoveryCode(ctx, id, hash)
+	}
+
+	// 🚨 SECURITY: Remove the recovery code so the same code can't be reused.
+	return execTOTPUpdate(ctx,
+		"UPDATE users SET totp_recovery_codes=array_remove(totp_recovery_codes, $1) WHERE id=$2 AND totp_enabled_at IS NOT NULL AND $1=ANY(totp_recovery_codes)",
+		hash, id)
+}
+
+// SetTOTPRecoveryCodes replaces the recovery codes of the user, who must have two-factor
+// authentication enabled. The recovery codes are hashed.
+func (u *users) SetTOTPRecoveryCodes(ctx context.Context, id int32, recoveryCodeHashes []string) error {
+	if Mocks.Users.SetTOTPRecoveryCodes != nil {
+		return Mocks.Users.SetTOTPRecoveryCodes(ctx, id, recoveryCodeHashes)
+	}
+
+	ok, err := execTOTPUpdate(ctx,
+		"UPDATE users SET totp_recovery_codes=$1 WHERE id=$2 AND deleted_at IS NULL AND totp_enabled_at IS NOT NULL",
+		pq.Array(recoveryCodeHashes), id)
+	if err != nil {
+		return err
+	} else if !ok {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	return nil
+}
+
+// DisableTOTP disables two-factor authentication for the user and removes the secret and the
+// recovery codes. It also cancels an enrollment in progress.
+func (u *users) DisableTOTP(ctx context.Context, id int32) error {
+	if Mocks.Users.DisableTOTP != nil {
+		return Mocks.Users.DisableTOTP(ctx, id)
+	}
+
+	_, err := dbconn.Global.ExecContext(ctx,
+		"UPDATE users SET totp_secret=NULL, totp_enabled_at=NULL, totp_last_step=NULL, totp_recovery_codes='{}', totp_attempts=0, totp_attempts_since=NULL WHERE id=$1",
+		id)
+	return err
+}
+
+// checkTOTPUpdated returns the error that explains why an update of the two-factor authentication
+// state of the user affected no rows.
+func (u *users) checkTOTPUpdated(ctx context.Context, res sql.Result, id int32) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+	if _, err := u.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return ErrTOTPAlreadyEnabled
+}
+
+// execTOTPUpdate executes the update query and reports whether it affected any rows.
+func execTOTPUpdate(ctx context.Context, query string, args ...interface{}) (bool, error) {
+	res, err := dbconn.Global.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
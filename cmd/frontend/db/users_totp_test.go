@@ -0,0 +1,147 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+)
+
+func TestUsers_TOTP(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	usr, err := Users.Create(ctx, NewUser{Username: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	getTOTP := func() *UserTOTP {
+		t.Helper()
+		totp, err := Users.GetTOTP(ctx, usr.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return totp
+	}
+
+	if have, want := getTOTP(), (&UserTOTP{}); !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %+v, want %+v", have, want)
+	}
+
+	// Codes can't be used before two-factor authentication is enabled.
+	if ok, err := Users.UseTOTPStep(ctx, usr.ID, 1); err != nil || ok {
+		t.Fatalf("UseTOTPStep: have (%v, %v), want (false, nil)", ok, err)
+	}
+	if ok, err := Users.EnableTOTP(ctx, usr.ID, 1, []string{"a", "b"}); err != nil || ok {
+		t.Fatalf("EnableTOTP without secret: have (%v, %v), want (false, nil)", ok, err)
+	}
+
+	if err := Users.SetTOTPSecret(ctx, usr.ID, "SECRET"); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := getTOTP(), (&UserTOTP{Secret: "SECRET"}); !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %+v, want %+v", have, want)
+	}
+
+	if ok, err := Users.EnableTOTP(ctx, usr.ID, 10, []string{"a", "b"}); err != nil || !ok {
+		t.Fatalf("EnableTOTP: have (%v, %v), want (true, nil)", ok, err)
+	}
+	if have, want := getTOTP(), (&UserTOTP{Secret: "SECRET", Enabled: true, RecoveryCodes: 2}); !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %+v, want %+v", have, want)
+	}
+	if err := Users.SetTOTPSecret(ctx, usr.ID, "OTHER"); err != ErrTOTPAlreadyEnabled {
+		t.Fatalf("SetTOTPSecret when enabled: have %v, want %v", err, ErrTOTPAlreadyEnabled)
+	}
+
+	// Time steps can't be reused, including the one that confirmed the enrollment.
+	for _, tc := range []struct {
+		step int64
+		want bool
+	}{
+		{10, false},
+		{9, false},
+		{11, true},
+		{11, false},
+	} {
+		if ok, err := Users.UseTOTPStep(ctx, usr.ID, tc.step); err != nil || ok != tc.want {
+			t.Fatalf("UseTOTPStep(%d): have (%v, %v), want (%v, nil)", tc.step, ok, err, tc.want)
+		}
+	}
+
+	// Recovery codes can only be used once.
+	for _, tc := range []struct {
+		hash string
+		want bool
+	}{
+		{"a", true},
+		{"a", false},
+		{"c", false},
+	} {
+		if ok, err := Users.UseTOTPRecoveryCode(ctx, usr.ID, tc.hash); err != nil || ok != tc.want {
+			t.Fatalf("UseTOTPRecoveryCode(%q): have (%v, %v), want (%v, nil)", tc.hash, ok, err, tc.want)
+		}
+	}
+	if have := getTOTP().RecoveryCodes; have != 1 {
+		t.Fatalf("have %d recovery codes, want 1", have)
+	}
+
+	if err := Users.SetTOTPRecoveryCodes(ctx, usr.ID, []string{"c", "d", "e"}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := Users.UseTOTPRecoveryCode(ctx, usr.ID, "c"); err != nil || !ok {
+		t.Fatalf("UseTOTPRecoveryCode after reset: have (%v, %v), want (true, nil)", ok, err)
+	}
+
+	if err := Users.DisableTOTP(ctx, usr.ID); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := getTOTP(), (&UserTOTP{}); !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %+v, want %+v", have, want)
+	}
+	if err := Users.SetTOTPRecoveryCodes(ctx, usr.ID, []string{"f"}); err == nil {
+		t.Fatal("SetTOTPRecoveryCodes when disabled: expected an error")
+	}
+}
+
+func TestUsers_TOTPAttempts(t *testing.T) {
+	if testing.Short() {
+		t.Skip()
+	}
+	dbtesting.SetupGlobalTestDB(t)
+	ctx := context.Background()
+
+	usr, err := Users.Create(ctx, NewUser{Username: "foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for want := 1; want <= 3; want++ {
+		if have, err := Users.AddTOTPAttempt(ctx, usr.ID, time.Hour); err != nil {
+			t.Fatal(err)
+		} else if have != want {
+			t.Errorf("have %d attempts, want %d", have, want)
+		}
+	}
+
+	// Attempts outside of the window are forgotten.
+	if have, err := Users.AddTOTPAttempt(ctx, usr.ID, 0); err != nil {
+		t.Fatal(err)
+	} else if have != 1 {
+		t.Errorf("have %d attempts after the window ended, want 1", have)
+	}
+
+	if err := Users.ResetTOTPAttempts(ctx, usr.ID); err != nil {
+		t.Fatal(err)
+	}
+	if have, err := Users.AddTOTPAttempt(ctx, usr.ID, time.Hour); err != nil {
+		t.Fatal(err)
+	} else if have != 1 {
+		t.Errorf("have %d attempts after reset, want 1", have)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"sort"
 	"sync"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
@@ -17,9 +18,10 @@ import (
 )
 
 type createAccessTokenInput struct {
-	User   graphql.ID
-	Scopes []string
-	Note   string
+	User      graphql.ID
+	Scopes    []string
+	Note      string
+	ExpiresAt *DateTime
 }
 
 func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAccessTokenInput) (*createAccessTokenResult, error) {
@@ -46,18 +48,21 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 	}
 
 	// Validate scopes.
-	var hasUserAllScope bool
+	var hasUserAllScope, hasRestrictedScope bool
 	seenScope := map[string]struct{}{}
 	sort.Strings(args.Scopes)
 	for _, scope := range args.Scopes {
 		switch scope {
 		case authz.ScopeUserAll:
 			hasUserAllScope = true
-		case authz.ScopeSiteAdminSudo:
-			// 🚨 SECURITY: Only site admins may create a token with the "site-admin:sudo" scope.
+		case authz.ScopeSiteAdminSudo, authz.ScopeSiteAdminUsers:
+			// 🚨 SECURITY: Only site admins may create a token with the "site-admin:*" scopes.
 			if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
 				return nil, err
 			}
+			hasRestrictedScope = hasRestrictedScope || scope == authz.ScopeSiteAdminUsers
+		case authz.ScopeSearchRead, authz.ScopeRepoRead, authz.ScopeCampaignsWrite, authz.ScopeCodeIntelUpload:
+			hasRestrictedScope = true
 		default:
 			return nil, fmt.Errorf("unknown access token scope %q (valid scopes: %q)", scope, authz.AllScopes)
 		}
@@ -67,11 +72,19 @@ func (r *schemaResolver) CreateAccessToken(ctx context.Context, args *createAcce
 		}
 		seenScope[scope] = struct{}{}
 	}
-	if !hasUserAllScope {
-		return nil, fmt.Errorf("all access tokens must have scope %q", authz.ScopeUserAll)
+	if !hasUserAllScope && !hasRestrictedScope {
+		return nil, fmt.Errorf("access tokens must have scope %q or at least one of the scopes %q", authz.ScopeUserAll, authz.RestrictedScopes)
 	}
 
-	id, token, err := db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID)
+	var expiresAt *time.Time
+	if args.ExpiresAt != nil {
+		if !args.ExpiresAt.Time.After(time.Now()) {
+			return nil, errors.New("access token expiry must be in the future")
+		}
+		expiresAt = &args.ExpiresAt.Time
+	}
+
+	id, token, err := db.AccessTokens.Create(ctx, userID, args.Scopes, args.Note, actor.FromContext(ctx).UID, expiresAt)
 	return &createAccessTokenResult{id: marshalAccessTokenID(id), token: token}, err
 }
 
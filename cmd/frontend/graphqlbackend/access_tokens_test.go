@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	graphql "github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/gqltesting"
@@ -17,7 +18,7 @@ import (
 // 🚨 SECURITY: This tests that users can't create tokens for users they aren't allowed to do so for.
 func TestMutation_CreateAccessToken(t *testing.T) {
 	mockAccessTokensCreate := func(t *testing.T, wantCreatorUserID int32, wantScopes []string) {
-		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32) (int64, string, error) {
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time) (int64, string, error) {
 			if want := int32(1); subjectUserID != want {
 				t.Errorf("got %v, want %v", subjectUserID, want)
 			}
@@ -76,6 +77,87 @@ func TestMutation_CreateAccessToken(t *testing.T) {
 		}
 	})
 
+	t.Run("authenticated as user, using restricted scopes", func(t *testing.T) {
+		resetMocks()
+		mockAccessTokensCreate(t, 1, []string{authz.ScopeRepoRead, authz.ScopeSearchRead})
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		if _, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:   uid1GQLID,
+			Scopes: []string{authz.ScopeSearchRead, authz.ScopeRepoRead},
+			Note:   "n",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("authenticated as user, using only the sudo scope", func(t *testing.T) {
+		resetMocks()
+		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+			return &types.User{ID: 1, SiteAdmin: true}, nil
+		}
+		defer func() { db.Mocks.Users.GetByCurrentAuthUser = nil }()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		if _, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:   uid1GQLID,
+			Scopes: []string{authz.ScopeSiteAdminSudo},
+			Note:   "n",
+		}); err == nil {
+			t.Error("err == nil")
+		}
+	})
+
+	t.Run("authenticated as user, with expiry", func(t *testing.T) {
+		resetMocks()
+		expiresAt := time.Now().Add(time.Hour)
+		var gotExpiresAt *time.Time
+		db.Mocks.AccessTokens.Create = func(subjectUserID int32, scopes []string, note string, creatorUserID int32, expiresAt *time.Time) (int64, string, error) {
+			gotExpiresAt = expiresAt
+			return 1, "t", nil
+		}
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		if _, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:      uid1GQLID,
+			Scopes:    []string{authz.ScopeUserAll},
+			Note:      "n",
+			ExpiresAt: &DateTime{Time: expiresAt},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if gotExpiresAt == nil || !gotExpiresAt.Equal(expiresAt) {
+			t.Errorf("got expiresAt %v, want %v", gotExpiresAt, expiresAt)
+		}
+
+		if _, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:      uid1GQLID,
+			Scopes:    []string{authz.ScopeUserAll},
+			Note:      "n",
+			ExpiresAt: &DateTime{Time: time.Now().Add(-time.Hour)},
+		}); err == nil {
+			t.Error("want error creating an already expired access token")
+		}
+	})
+
+	// 🚨 SECURITY: Tokens with restricted scopes must not be able to create tokens with more scopes.
+	t.Run("authenticated with a restricted access token", func(t *testing.T) {
+		resetMocks()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1, Scopes: []string{authz.ScopeSearchRead}})
+		result, err := (&schemaResolver{}).CreateAccessToken(ctx, &createAccessTokenInput{
+			User:   uid1GQLID,
+			Scopes: []string{authz.ScopeUserAll},
+			Note:   "n",
+		})
+		if _, ok := err.(*backend.ScopeError); !ok {
+			t.Errorf("got err %v, want *backend.ScopeError", err)
+		}
+		if result != nil {
+			t.Errorf("got result %v, want nil", result)
+		}
+	})
+
 	t.Run("authenticated as user, using site-admin-only scopes", func(t *testing.T) {
 		resetMocks()
 		db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
@@ -12,9 +12,14 @@ var NewAuthzResolver func() AuthzResolver
 
 type AuthzResolver interface {
 	SetRepositoryPermissionsForUsers(ctx context.Context, args *RepoPermsArgs) (*EmptyResponse, error)
+	SetExplicitRepositoryPermissions(ctx context.Context, args *ExplicitRepoPermsArgs) (*EmptyResponse, error)
 	AuthorizedUserRepositories(ctx context.Context, args *AuthorizedRepoArgs) (RepositoryConnectionResolver, error)
 	UsersWithPendingPermissions(ctx context.Context) ([]string, error)
 	AuthorizedUsers(ctx context.Context, args *RepoAuthorizedUserArgs) (UserConnectionResolver, error)
+	RepositoryPermissionsExplanation(ctx context.Context, args *RepoPermsExplanationArgs) (RepositoryPermissionsExplanationResolver, error)
+	GrantRepositoryAccess(ctx context.Context, args *GrantRepoAccessArgs) (RepositoryAccessGrantResolver, error)
+	RevokeRepositoryAccessGrant(ctx context.Context, args *RevokeRepoAccessGrantArgs) (*EmptyResponse, error)
+	RepositoryAccessGrants(ctx context.Context, args *RepoAccessGrantsArgs) ([]RepositoryAccessGrantResolver, error)
 }
 
 var authzInEnterprise = errors.New("authorization mutations and queries are only available in enterprise")
@@ -25,6 +30,10 @@ func (defaultAuthzResolver) SetRepositoryPermissionsForUsers(ctx context.Context
 	return nil, authzInEnterprise
 }
 
+func (defaultAuthzResolver) SetExplicitRepositoryPermissions(ctx context.Context, args *ExplicitRepoPermsArgs) (*EmptyResponse, error) {
+	return nil, authzInEnterprise
+}
+
 func (defaultAuthzResolver) AuthorizedUserRepositories(ctx context.Context, args *AuthorizedRepoArgs) (RepositoryConnectionResolver, error) {
 	return nil, authzInEnterprise
 }
@@ -37,12 +46,33 @@ func (defaultAuthzResolver) AuthorizedUsers(ctx context.Context, args *RepoAutho
 	return nil, authzInEnterprise
 }
 
+func (defaultAuthzResolver) RepositoryPermissionsExplanation(ctx context.Context, args *RepoPermsExplanationArgs) (RepositoryPermissionsExplanationResolver, error) {
+	return nil, authzInEnterprise
+}
+
+func (defaultAuthzResolver) GrantRepositoryAccess(ctx context.Context, args *GrantRepoAccessArgs) (RepositoryAccessGrantResolver, error) {
+	return nil, authzInEnterprise
+}
+
+func (defaultAuthzResolver) RevokeRepositoryAccessGrant(ctx context.Context, args *RevokeRepoAccessGrantArgs) (*EmptyResponse, error) {
+	return nil, authzInEnterprise
+}
+
+func (defaultAuthzResolver) RepositoryAccessGrants(ctx context.Context, args *RepoAccessGrantsArgs) ([]RepositoryAccessGrantResolver, error) {
+	return nil, authzInEnterprise
+}
+
 type RepoPermsArgs struct {
 	Repository graphql.ID
 	BindIDs    []string
 	Perm       string
 }
 
+type ExplicitRepoPermsArgs struct {
+	Repository string
+	Users      []string
+}
+
 type AuthorizedRepoArgs struct {
 	Username *string
 	Email    *string
@@ -50,3 +80,71 @@ type AuthorizedRepoArgs struct {
 	First    int32
 	After    *string
 }
+
+type RepoPermsExplanationArgs struct {
+	Username   string
+	Repository string
+}
+
+type GrantRepoAccessArgs struct {
+	Repository   graphql.ID
+	User         *graphql.ID
+	Organization *graphql.ID
+	ExpiresAt    DateTime
+	Reason       *string
+}
+
+type RevokeRepoAccessGrantArgs struct {
+	ID graphql.ID
+}
+
+type RepoAccessGrantsArgs struct {
+	Repository      *graphql.ID
+	User            *graphql.ID
+	Organization    *graphql.ID
+	IncludeInactive bool
+}
+
+type RepositoryPermissionsExplanationResolver interface {
+	User() *UserResolver
+	Repository() *RepositoryResolver
+	Allowed() bool
+	Reason() string
+	Providers() []AuthorizationProviderExplanationResolver
+	UserPermissions() StoredRepositoryPermissionsResolver
+	RepositoryPermissions() StoredRepositoryPermissionsResolver
+	LastUserSync() PermissionsSyncResultResolver
+	LastRepositorySync() PermissionsSyncResultResolver
+}
+
+type AuthorizationProviderExplanationResolver interface {
+	ServiceType() string
+	ServiceID() string
+	AccountID() *string
+	Allowed() bool
+	Error() *string
+}
+
+type StoredRepositoryPermissionsResolver interface {
+	Allowed() bool
+	UpdatedAt() DateTime
+}
+
+type PermissionsSyncResultResolver interface {
+	StartedAt() DateTime
+	FinishedAt() DateTime
+	Error() *string
+}
+
+type RepositoryAccessGrantResolver interface {
+	ID() graphql.ID
+	Repository(ctx context.Context) (*RepositoryResolver, error)
+	User(ctx context.Context) (*UserResolver, error)
+	Organization(ctx context.Context) (*OrgResolver, error)
+	Creator(ctx context.Context) (*UserResolver, error)
+	Reason() string
+	ExpiresAt() DateTime
+	RevokedAt() *DateTime
+	CreatedAt() DateTime
+	Active() bool
+}
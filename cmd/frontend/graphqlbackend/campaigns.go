@@ -45,6 +45,11 @@ type CreatePatchSetFromPatchesArgs struct {
 	Patches []PatchInput
 }
 
+type CreatePatchSetFromSearchAndReplaceArgs struct {
+	Query           string
+	RewriteTemplate string
+}
+
 type PatchInput struct {
 	Repository   graphql.ID
 	BaseRevision api.CommitID
@@ -83,6 +88,55 @@ type PublishCampaignArgs struct {
 	Campaign graphql.ID
 }
 
+type SetCampaignAutoMergeArgs struct {
+	Campaign  graphql.ID
+	AutoMerge *CampaignAutoMergeInput
+}
+
+type CampaignAutoMergeInput struct {
+	Method                 campaigns.ChangesetMergeMethod
+	Days                   *[]string
+	WindowStart            *string
+	WindowEnd              *string
+	TimeZone               *string
+	MaxMergesPerRepository *int32
+}
+
+type SetCampaignPublishPolicyArgs struct {
+	Campaign      graphql.ID
+	PublishPolicy *CampaignPublishPolicyInput
+}
+
+type CampaignPublishPolicyInput struct {
+	MaxChangesetsPerHour *int32
+	Days                 *[]string
+	WindowStart          *string
+	WindowEnd            *string
+	TimeZone             *string
+	InitialBatchSize     *int32
+}
+
+type SetCampaignReviewerPolicyArgs struct {
+	Campaign       graphql.ID
+	ReviewerPolicy *CampaignReviewerPolicyInput
+}
+
+type CampaignReviewerPolicyInput struct {
+	IgnoreCodeOwners *bool
+	Reviewers        *[]string
+	Assignees        *[]string
+}
+
+type CreateChangesetActionArgs struct {
+	Campaign    graphql.ID
+	Kind        campaigns.ChangesetActionKind
+	Comment     *string
+	Labels      *[]string
+	State       *campaigns.ChangesetState
+	ReviewState *campaigns.ChangesetReviewState
+	CheckState  *campaigns.ChangesetCheckState
+}
+
 type PublishChangesetArgs struct {
 	Patch graphql.ID
 }
@@ -100,6 +154,11 @@ type CampaignsResolver interface {
 	RetryCampaign(ctx context.Context, args *RetryCampaignArgs) (CampaignResolver, error)
 	CloseCampaign(ctx context.Context, args *CloseCampaignArgs) (CampaignResolver, error)
 	PublishCampaign(ctx context.Context, args *PublishCampaignArgs) (CampaignResolver, error)
+	SetCampaignAutoMerge(ctx context.Context, args *SetCampaignAutoMergeArgs) (CampaignResolver, error)
+	SetCampaignPublishPolicy(ctx context.Context, args *SetCampaignPublishPolicyArgs) (CampaignResolver, error)
+	SetCampaignReviewerPolicy(ctx context.Context, args *SetCampaignReviewerPolicyArgs) (CampaignResolver, error)
+	CreateChangesetAction(ctx context.Context, args *CreateChangesetActionArgs) (ChangesetActionResolver, error)
+	ChangesetActionByID(ctx context.Context, id graphql.ID) (ChangesetActionResolver, error)
 	PublishChangeset(ctx context.Context, args *PublishChangesetArgs) (*EmptyResponse, error)
 	SyncChangeset(ctx context.Context, args *SyncChangesetArgs) (*EmptyResponse, error)
 
@@ -110,6 +169,7 @@ type CampaignsResolver interface {
 	AddChangesetsToCampaign(ctx context.Context, args *AddChangesetsToCampaignArgs) (CampaignResolver, error)
 
 	CreatePatchSetFromPatches(ctx context.Context, args CreatePatchSetFromPatchesArgs) (PatchSetResolver, error)
+	CreatePatchSetFromSearchAndReplace(ctx context.Context, args *CreatePatchSetFromSearchAndReplaceArgs) (PatchSetResolver, error)
 	PatchSetByID(ctx context.Context, id graphql.ID) (PatchSetResolver, error)
 
 	PatchByID(ctx context.Context, id graphql.ID) (PatchResolver, error)
@@ -151,6 +211,26 @@ func (defaultCampaignsResolver) PublishCampaign(ctx context.Context, args *Publi
 	return nil, campaignsOnlyInEnterprise
 }
 
+func (defaultCampaignsResolver) SetCampaignAutoMerge(ctx context.Context, args *SetCampaignAutoMergeArgs) (CampaignResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
+func (defaultCampaignsResolver) SetCampaignPublishPolicy(ctx context.Context, args *SetCampaignPublishPolicyArgs) (CampaignResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
+func (defaultCampaignsResolver) SetCampaignReviewerPolicy(ctx context.Context, args *SetCampaignReviewerPolicyArgs) (CampaignResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
+func (defaultCampaignsResolver) CreateChangesetAction(ctx context.Context, args *CreateChangesetActionArgs) (ChangesetActionResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
+func (defaultCampaignsResolver) ChangesetActionByID(ctx context.Context, id graphql.ID) (ChangesetActionResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
 func (defaultCampaignsResolver) PublishChangeset(ctx context.Context, args *PublishChangesetArgs) (*EmptyResponse, error) {
 	return nil, campaignsOnlyInEnterprise
 }
@@ -179,6 +259,10 @@ func (defaultCampaignsResolver) CreatePatchSetFromPatches(ctx context.Context, a
 	return nil, campaignsOnlyInEnterprise
 }
 
+func (defaultCampaignsResolver) CreatePatchSetFromSearchAndReplace(ctx context.Context, args *CreatePatchSetFromSearchAndReplaceArgs) (PatchSetResolver, error) {
+	return nil, campaignsOnlyInEnterprise
+}
+
 func (defaultCampaignsResolver) PatchSetByID(ctx context.Context, id graphql.ID) (PatchSetResolver, error) {
 	return nil, campaignsOnlyInEnterprise
 }
@@ -212,12 +296,75 @@ type CampaignResolver interface {
 	UpdatedAt() DateTime
 	Changesets(ctx context.Context, args *ListChangesetsArgs) (ExternalChangesetsConnectionResolver, error)
 	ChangesetCountsOverTime(ctx context.Context, args *ChangesetCountsArgs) ([]ChangesetCountsResolver, error)
+	Analytics(ctx context.Context) (CampaignAnalyticsResolver, error)
 	RepositoryDiffs(ctx context.Context, args *graphqlutil.ConnectionArgs) (RepositoryComparisonConnectionResolver, error)
 	PatchSet(ctx context.Context) (PatchSetResolver, error)
 	Status(context.Context) (BackgroundProcessStatus, error)
 	ClosedAt() *DateTime
 	PublishedAt(ctx context.Context) (*DateTime, error)
 	Patches(ctx context.Context, args *graphqlutil.ConnectionArgs) PatchConnectionResolver
+	AutoMerge() CampaignAutoMergeResolver
+	PublishPolicy() CampaignPublishPolicyResolver
+	ReviewerPolicy() CampaignReviewerPolicyResolver
+	ChangesetActions(ctx context.Context, args *graphqlutil.ConnectionArgs) ChangesetActionsConnectionResolver
+}
+
+type CampaignAutoMergeResolver interface {
+	Method() campaigns.ChangesetMergeMethod
+	Days() []string
+	WindowStart() *string
+	WindowEnd() *string
+	TimeZone() string
+	MaxMergesPerRepository() int32
+}
+
+type CampaignPublishPolicyResolver interface {
+	MaxChangesetsPerHour() *int32
+	Days() []string
+	WindowStart() *string
+	WindowEnd() *string
+	TimeZone() string
+	InitialBatchSize() *int32
+}
+
+type CampaignReviewerPolicyResolver interface {
+	IgnoreCodeOwners() bool
+	Reviewers() []string
+	Assignees() []string
+}
+
+type ChangesetActionResolver interface {
+	ID() graphql.ID
+	Campaign(ctx context.Context) (CampaignResolver, error)
+	Author(ctx context.Context) (*UserResolver, error)
+	Kind() campaigns.ChangesetActionKind
+	Comment() *string
+	Labels() []string
+	State() *campaigns.ChangesetState
+	ReviewState() *campaigns.ChangesetReviewState
+	CheckState() *campaigns.ChangesetCheckState
+	CreatedAt() DateTime
+	Status(ctx context.Context) (BackgroundProcessStatus, error)
+	Results(ctx context.Context, args *graphqlutil.ConnectionArgs) ChangesetActionResultsConnectionResolver
+}
+
+type ChangesetActionsConnectionResolver interface {
+	Nodes(ctx context.Context) ([]ChangesetActionResolver, error)
+	TotalCount(ctx context.Context) (int32, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
+}
+
+type ChangesetActionResultResolver interface {
+	Changeset(ctx context.Context) (ExternalChangesetResolver, error)
+	Error() *string
+	StartedAt() *DateTime
+	FinishedAt() *DateTime
+}
+
+type ChangesetActionResultsConnectionResolver interface {
+	Nodes(ctx context.Context) ([]ChangesetActionResultResolver, error)
+	TotalCount(ctx context.Context) (int32, error)
+	PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error)
 }
 
 type CampaignsConnectionResolver interface {
@@ -256,6 +403,7 @@ type ExternalChangesetResolver interface {
 	Head(ctx context.Context) (*GitRefResolver, error)
 	Base(ctx context.Context) (*GitRefResolver, error)
 	Labels(ctx context.Context) ([]ChangesetLabelResolver, error)
+	RebaseError(ctx context.Context) (*string, error)
 }
 
 type PatchConnectionResolver interface {
@@ -296,6 +444,39 @@ type ChangesetCountsResolver interface {
 	OpenPending() int32
 }
 
+type CampaignAnalyticsResolver interface {
+	TimeToMerge() DurationDistributionResolver
+	ReviewLatency() DurationDistributionResolver
+	RepositoryOwners() []RepositoryOwnerChangesetCountsResolver
+	CheckFailures() []ChangesetCheckFailureResolver
+	CSV() (string, error)
+}
+
+type DurationDistributionResolver interface {
+	Count() int32
+	MedianHours() *float64
+	P90Hours() *float64
+	Buckets() []DurationBucketResolver
+}
+
+type DurationBucketResolver interface {
+	MaxHours() *int32
+	Count() int32
+}
+
+type RepositoryOwnerChangesetCountsResolver interface {
+	Owner() string
+	Total() int32
+	Open() int32
+	Merged() int32
+	Closed() int32
+}
+
+type ChangesetCheckFailureResolver interface {
+	Name() string
+	ChangesetCount() int32
+}
+
 type BackgroundProcessStatus interface {
 	CompletedCount() int32
 	PendingCount() int32
@@ -311,6 +492,8 @@ type PatchSetResolver interface {
 	Patches(ctx context.Context, args *graphqlutil.ConnectionArgs) PatchConnectionResolver
 
 	PreviewURL() string
+
+	Status(ctx context.Context) (BackgroundProcessStatus, error)
 }
 
 type PreviewFileDiff interface {
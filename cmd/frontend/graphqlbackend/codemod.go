@@ -18,9 +18,10 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/go-diff/diff"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/goroutine"
-	"github.com/sourcegraph/sourcegraph/internal/env"
+	"github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/errcode"
 	"github.com/sourcegraph/sourcegraph/internal/lazyregexp"
+	"github.com/sourcegraph/sourcegraph/internal/replacer"
 	"github.com/sourcegraph/sourcegraph/internal/search"
 	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
@@ -143,6 +144,29 @@ func validateQuery(q query.QueryInfo) (*args, error) {
 	return &args{matchTemplate, rewriteTemplate, includeFileFilterText, excludeFileFilterText}, nil
 }
 
+// CodemodRewriteSpecification returns the specification of the rewrite that
+// the replacer performs for the given structural search query, using
+// rewriteTemplate in place of a "replace:" value in the query.
+func CodemodRewriteSpecification(queryString, rewriteTemplate string) (*protocol.RewriteSpecification, error) {
+	q, err := query.Process(queryString, query.SearchTypeStructural)
+	if err != nil {
+		return nil, err
+	}
+	cmodArgs, err := validateQuery(q)
+	if err != nil {
+		return nil, err
+	}
+	if cmodArgs.matchTemplate == "" {
+		return nil, errors.New("the query must contain a structural search pattern")
+	}
+	return &protocol.RewriteSpecification{
+		MatchTemplate:    cmodArgs.matchTemplate,
+		RewriteTemplate:  rewriteTemplate,
+		FileExtension:    cmodArgs.includeFileFilter,
+		DirectoryExclude: cmodArgs.excludeFileFilter,
+	}, nil
+}
+
 // Calls the codemod backend replacer service for a set of repository revisions.
 func performCodemod(ctx context.Context, args *search.TextParameters) ([]SearchResultResolver, *searchResultsCommon, error) {
 	cmodArgs, err := validateQuery(args.Query)
@@ -208,8 +232,6 @@ func performCodemod(ctx context.Context, args *search.TextParameters) ([]SearchR
 	return results, common, nil
 }
 
-var ReplacerURL = env.Get("REPLACER_URL", "http://replacer:3185", "replacer server URL")
-
 func toMatchResolver(fileURL string, raw *rawCodemodResult) ([]*searchResultMatchResolver, error) {
 	if !strings.Contains(raw.Diff, "@@") {
 		return nil, errors.Errorf("Invalid diff does not contain expected @@: %v", raw.Diff)
@@ -240,7 +262,7 @@ func callCodemodInRepo(ctx context.Context, repoRevs *search.RepositoryRevisions
 		return nil, errors.Wrap(err, "codemod repo lookup failed: it's possible that the repo is not cloned in gitserver. Try force a repo update another way.")
 	}
 
-	u, err := url.Parse(ReplacerURL)
+	u, err := url.Parse(replacer.URL)
 	if err != nil {
 		return nil, err
 	}
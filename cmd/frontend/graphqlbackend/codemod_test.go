@@ -39,3 +39,17 @@ func TestCodemod_resolver(t *testing.T) {
 		t.Fatalf("Expected error %q", err)
 	}
 }
+
+func TestCodemodRewriteSpecification(t *testing.T) {
+	spec, err := CodemodRewriteSpecification(`repo:^github\.com/foo/ file:.go "fmt.Sprintf(:[args])"`, "fmt.Sprint(:[args])")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spec.MatchTemplate != "fmt.Sprintf(:[args])" || spec.RewriteTemplate != "fmt.Sprint(:[args])" || spec.FileExtension != ".go" {
+		t.Fatalf("unexpected rewrite specification %+v", spec)
+	}
+
+	if _, err := CodemodRewriteSpecification(`repo:^github\.com/foo/`, "x"); err == nil {
+		t.Fatal("expected error for query without pattern")
+	}
+}
@@ -0,0 +1,84 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+func (r *externalServiceResolver) SyncRuns(ctx context.Context, args *struct {
+	graphqlutil.ConnectionArgs
+}) (*externalServiceSyncRunConnectionResolver, error) {
+	// 🚨 SECURITY: Only site admins may read external services and their sync runs.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+	opt := db.ExternalServiceSyncRunsListOptions{ExternalServiceID: r.externalService.ID}
+	args.ConnectionArgs.Set(&opt.LimitOffset)
+	return &externalServiceSyncRunConnectionResolver{opt: opt}, nil
+}
+
+type externalServiceSyncRunConnectionResolver struct {
+	opt db.ExternalServiceSyncRunsListOptions
+}
+
+func (r *externalServiceSyncRunConnectionResolver) Nodes(ctx context.Context) ([]*externalServiceSyncRunResolver, error) {
+	runs, err := db.ExternalServiceSyncRuns.List(ctx, r.opt)
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*externalServiceSyncRunResolver, 0, len(runs))
+	for _, run := range runs {
+		resolvers = append(resolvers, &externalServiceSyncRunResolver{run: run})
+	}
+
+	return resolvers, nil
+}
+
+func (r *externalServiceSyncRunConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	count, err := db.ExternalServiceSyncRuns.Count(ctx, r.opt)
+	return int32(count), err
+}
+
+func (r *externalServiceSyncRunConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	count, err := db.ExternalServiceSyncRuns.Count(ctx, r.opt)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(r.opt.LimitOffset != nil && r.opt.Offset+r.opt.Limit < count), nil
+}
+
+type externalServiceSyncRunResolver struct {
+	run *types.ExternalServiceSyncRun
+}
+
+func (r *externalServiceSyncRunResolver) StartedAt() DateTime {
+	return DateTime{Time: r.run.StartedAt}
+}
+
+func (r *externalServiceSyncRunResolver) FinishedAt() DateTime {
+	return DateTime{Time: r.run.FinishedAt}
+}
+
+func (r *externalServiceSyncRunResolver) Added() int32 { return r.run.Added }
+
+func (r *externalServiceSyncRunResolver) Deleted() int32 { return r.run.Deleted }
+
+func (r *externalServiceSyncRunResolver) Modified() int32 { return r.run.Modified }
+
+func (r *externalServiceSyncRunResolver) Unmodified() int32 { return r.run.Unmodified }
+
+func (r *externalServiceSyncRunResolver) RateLimitWaitMilliseconds() int32 {
+	return int32(r.run.RateLimitWait.Milliseconds())
+}
+
+func (r *externalServiceSyncRunResolver) Error() *string {
+	if r.run.Error == "" {
+		return nil
+	}
+	return &r.run.Error
+}
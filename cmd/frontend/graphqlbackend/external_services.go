@@ -19,6 +19,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/env"
 	"github.com/sourcegraph/sourcegraph/internal/repoupdater"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater/protocol"
 )
 
 var extsvcConfigAllowEdits, _ = strconv.ParseBool(env.Get("EXTSVC_CONFIG_ALLOW_EDITS", "false", "When EXTSVC_CONFIG_FILE is in use, allow edits in the application to be made which will be overwritten on next process restart"))
@@ -125,6 +126,82 @@ func syncExternalService(ctx context.Context, svc *types.ExternalService) error
 	return nil
 }
 
+func (*schemaResolver) PreviewExternalServiceSync(ctx context.Context, args *struct {
+	Input *struct {
+		ID     *graphql.ID
+		Kind   *string
+		Config string
+	}
+}) (*externalServiceSyncPreviewResolver, error) {
+	// 🚨 SECURITY: Only site admins may preview external service syncs (configs have secrets).
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	svc := &types.ExternalService{Config: args.Input.Config}
+	if args.Input.ID != nil {
+		id, err := unmarshalExternalServiceID(*args.Input.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		existing, err := db.ExternalServices.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		svc.ID, svc.Kind, svc.DisplayName = existing.ID, existing.Kind, existing.DisplayName
+	} else if args.Input.Kind != nil {
+		svc.Kind = *args.Input.Kind
+	} else {
+		return nil, errors.New("either id or kind must be set")
+	}
+
+	if err := db.ExternalServices.ValidateConfig(svc.Kind, svc.Config, conf.Get().AuthProviders); err != nil {
+		return nil, err
+	}
+
+	res, err := repoupdater.DefaultClient.SyncExternalServiceDryRun(ctx, api.ExternalService{
+		ID:          svc.ID,
+		Kind:        svc.Kind,
+		DisplayName: svc.DisplayName,
+		Config:      svc.Config,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &externalServiceSyncPreviewResolver{res: res}, nil
+}
+
+type externalServiceSyncPreviewResolver struct {
+	res *protocol.ExternalServiceDryRunResult
+}
+
+func (r *externalServiceSyncPreviewResolver) Added() []string {
+	return repoNameStrings(r.res.Added)
+}
+
+func (r *externalServiceSyncPreviewResolver) Deleted() []string {
+	return repoNameStrings(r.res.Deleted)
+}
+
+func (r *externalServiceSyncPreviewResolver) Modified() []string {
+	return repoNameStrings(r.res.Modified)
+}
+
+func (r *externalServiceSyncPreviewResolver) UnmodifiedCount() int32 {
+	return int32(len(r.res.Unmodified))
+}
+
+func repoNameStrings(names []api.RepoName) []string {
+	ss := make([]string, 0, len(names))
+	for _, name := range names {
+		ss = append(ss, string(name))
+	}
+	return ss
+}
+
 func (*schemaResolver) DeleteExternalService(ctx context.Context, args *struct {
 	ExternalService graphql.ID
 }) (*EmptyResponse, error) {
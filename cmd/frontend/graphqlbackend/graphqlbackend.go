@@ -86,6 +86,13 @@ VARIABLES
 
 func (prometheusTracer) TraceField(ctx context.Context, label, typeName, fieldName string, trivial bool, args map[string]interface{}) (context.Context, trace.TraceFieldFinishFunc) {
 	traceCtx, finish := trace.OpenTracingTracer{}.TraceField(ctx, label, typeName, fieldName, trivial, args)
+
+	// 🚨 SECURITY: Deny all fields of the Query and Mutation types to actors restricted by the scopes
+	// of their access token, except the ones their scopes allow.
+	if err := checkRestrictedScopeField(ctx, typeName, fieldName); err != nil {
+		traceCtx = &deniedContext{Context: traceCtx, err: err}
+	}
+
 	start := time.Now()
 	return traceCtx, func(err *gqlerrors.QueryError) {
 		isErrStr := strconv.FormatBool(err != nil)
@@ -148,6 +155,11 @@ func (r *NodeResolver) ToAccessToken() (*accessTokenResolver, bool) {
 	return n, ok
 }
 
+func (r *NodeResolver) ToUserSession() (*userSessionResolver, bool) {
+	n, ok := r.Node.(*userSessionResolver)
+	return n, ok
+}
+
 func (r *NodeResolver) ToCampaign() (CampaignResolver, bool) {
 	n, ok := r.Node.(CampaignResolver)
 	return n, ok
@@ -168,6 +180,11 @@ func (r *NodeResolver) ToPatch() (PatchResolver, bool) {
 	return n, ok
 }
 
+func (r *NodeResolver) ToChangesetAction() (ChangesetActionResolver, bool) {
+	n, ok := r.Node.(ChangesetActionResolver)
+	return n, ok
+}
+
 func (r *NodeResolver) ToChangesetEvent() (ChangesetEventResolver, bool) {
 	n, ok := r.Node.(ChangesetEventResolver)
 	return n, ok
@@ -291,9 +308,19 @@ func (r *schemaResolver) Node(ctx context.Context, args *struct{ ID graphql.ID }
 }
 
 func (r *schemaResolver) nodeByID(ctx context.Context, id graphql.ID) (Node, error) {
-	switch relay.UnmarshalKind(id) {
+	kind := relay.UnmarshalKind(id)
+
+	// 🚨 SECURITY: Actors restricted by the scopes of their access token may only look up the kinds of
+	// nodes their scopes allow.
+	if err := checkRestrictedScopeNodeKind(ctx, kind); err != nil {
+		return nil, err
+	}
+
+	switch kind {
 	case "AccessToken":
 		return accessTokenByID(ctx, id)
+	case "UserSession":
+		return userSessionByID(ctx, id)
 	case "Campaign":
 		return r.CampaignByID(ctx, id)
 	case "PatchSet":
@@ -302,6 +329,8 @@ func (r *schemaResolver) nodeByID(ctx context.Context, id graphql.ID) (Node, err
 		return r.ChangesetByID(ctx, id)
 	case "Patch":
 		return r.PatchByID(ctx, id)
+	case "ChangesetAction":
+		return r.ChangesetActionByID(ctx, id)
 	case "DiscussionComment":
 		return discussionCommentByID(ctx, id)
 	case "DiscussionThread":
@@ -2,27 +2,141 @@ package graphqlbackend
 
 import (
 	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"time"
 
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 )
 
 type repoGroup struct {
 	name         string
 	repositories []api.RepoName
+
+	// group is the rule based repo group, or nil if the group is defined in
+	// the search.repositoryGroups settings.
+	group *types.RepoGroup
+}
+
+func (g repoGroup) ID() *graphql.ID {
+	if g.group == nil {
+		return nil
+	}
+	id := marshalRepoGroupID(g.group.ID)
+	return &id
 }
 
 func (g repoGroup) Name() string { return g.name }
 
-func (g repoGroup) Repositories() []string { return repoNamesToStrings(g.repositories) }
+func (g repoGroup) Description() string {
+	if g.group == nil {
+		return ""
+	}
+	return g.group.Description
+}
+
+func (g repoGroup) Rules() *repoGroupRulesResolver {
+	if g.group == nil {
+		return nil
+	}
+	return &repoGroupRulesResolver{rules: g.group.Rules}
+}
+
+func (g repoGroup) Organization(ctx context.Context) (*OrgResolver, error) {
+	if g.group == nil || g.group.OrgID == nil {
+		return nil, nil
+	}
+	return OrgByIDInt32(ctx, *g.group.OrgID)
+}
+
+func (g repoGroup) ViewerCanAdminister(ctx context.Context) bool {
+	if g.group == nil {
+		return false
+	}
+	return checkRepoGroupAccess(ctx, g.group.OrgID) == nil
+}
+
+func (g repoGroup) Repositories(ctx context.Context) ([]string, error) {
+	if g.group == nil {
+		return repoNamesToStrings(g.repositories), nil
+	}
+
+	// The repositories of rule based groups are listed with the permissions
+	// of the viewer, so that private repositories don't leak. Like in
+	// searches, groups are truncated to repoGroupMaxRepos repositories.
+	opt := db.RepoGroupReposListOptions(g.group.Rules)
+	opt.OnlyRepoIDs = true
+	opt.LimitOffset = &db.LimitOffset{Limit: repoGroupMaxRepos}
+	repos, err := db.Repos.List(ctx, opt)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(repos))
+	for i, repo := range repos {
+		names[i] = string(repo.Name)
+	}
+	return names, nil
+}
+
+type repoGroupRulesResolver struct {
+	rules types.RepoGroupRules
+}
+
+func (r *repoGroupRulesResolver) ExternalServiceIDs() []graphql.ID {
+	ids := make([]graphql.ID, len(r.rules.ExternalServiceIDs))
+	for i, id := range r.rules.ExternalServiceIDs {
+		ids[i] = marshalExternalServiceID(id)
+	}
+	return ids
+}
+
+func (r *repoGroupRulesResolver) NamePattern() *string {
+	if r.rules.NamePattern == "" {
+		return nil
+	}
+	return &r.rules.NamePattern
+}
+
+func (r *repoGroupRulesResolver) Topics() []string { return nonNilStrings(r.rules.Topics) }
+
+func (r *repoGroupRulesResolver) Languages() []string { return nonNilStrings(r.rules.Languages) }
+
+func (r *repoGroupRulesResolver) Archived() *bool { return r.rules.Archived }
+
+func (r *repoGroupRulesResolver) Visibilities() []string { return nonNilStrings(r.rules.Visibilities) }
+
+func nonNilStrings(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
+
+func marshalRepoGroupID(id int32) graphql.ID {
+	return relay.MarshalID("RepoGroup", id)
+}
+
+func unmarshalRepoGroupID(id graphql.ID) (repoGroupID int32, err error) {
+	err = relay.UnmarshalSpec(id, &repoGroupID)
+	return
+}
 
 func (r *schemaResolver) RepoGroups(ctx context.Context) ([]*repoGroup, error) {
-	groupsByName, err := resolveRepoGroups(ctx)
+	settingsGroups, err := resolveSettingsRepoGroups(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	groups := make([]*repoGroup, 0, len(groupsByName))
-	for name, repos := range groupsByName {
+	groups := make([]*repoGroup, 0, len(settingsGroups))
+	for name, repos := range settingsGroups {
 		repoPaths := make([]api.RepoName, len(repos))
 		for i, repo := range repos {
 			repoPaths[i] = repo.Name
@@ -32,5 +146,289 @@ func (r *schemaResolver) RepoGroups(ctx context.Context) ([]*repoGroup, error) {
 			repositories: repoPaths,
 		})
 	}
+
+	ruleGroups, err := db.RepoGroups.List(ctx, db.RepoGroupsListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for _, g := range ruleGroups {
+		// Groups defined in settings take precedence, see resolveRepoGroups.
+		if _, ok := settingsGroups[g.Name]; ok {
+			continue
+		}
+		groups = append(groups, &repoGroup{name: g.Name, group: g})
+	}
 	return groups, nil
 }
+
+type repoGroupRulesInput struct {
+	ExternalServiceIDs *[]graphql.ID
+	NamePattern        *string
+	Topics             *[]string
+	Languages          *[]string
+	Archived           *bool
+	Visibilities       *[]string
+}
+
+func (in *repoGroupRulesInput) rules() (rules types.RepoGroupRules, err error) {
+	if in.ExternalServiceIDs != nil {
+		for _, gqlID := range *in.ExternalServiceIDs {
+			id, err := unmarshalExternalServiceID(gqlID)
+			if err != nil {
+				return rules, err
+			}
+			rules.ExternalServiceIDs = append(rules.ExternalServiceIDs, id)
+		}
+	}
+	if in.NamePattern != nil && *in.NamePattern != "" {
+		if _, err := regexp.Compile(*in.NamePattern); err != nil {
+			return rules, err
+		}
+		rules.NamePattern = *in.NamePattern
+	}
+	if in.Topics != nil && len(*in.Topics) > 0 {
+		rules.Topics = *in.Topics
+	}
+	if in.Languages != nil && len(*in.Languages) > 0 {
+		rules.Languages = *in.Languages
+	}
+	rules.Archived = in.Archived
+	if in.Visibilities != nil && len(*in.Visibilities) > 0 {
+		rules.Visibilities = *in.Visibilities
+	}
+
+	if rules.IsEmpty() {
+		return rules, errors.New("at least one repo group rule must be set")
+	}
+	return rules, nil
+}
+
+// checkRepoGroupAccess returns an error if the current user can't create,
+// update or delete a repo group owned by the given organization (or by site
+// admins, if orgID is nil).
+func checkRepoGroupAccess(ctx context.Context, orgID *int32) error {
+	if orgID != nil {
+		return backend.CheckOrgAccess(ctx, *orgID)
+	}
+	return backend.CheckCurrentUserIsSiteAdmin(ctx)
+}
+
+func (r *schemaResolver) CreateRepoGroup(ctx context.Context, args *struct {
+	Name         string
+	Description  *string
+	Rules        repoGroupRulesInput
+	Organization *graphql.ID
+}) (*repoGroup, error) {
+	var orgID *int32
+	if args.Organization != nil {
+		id, err := UnmarshalOrgID(*args.Organization)
+		if err != nil {
+			return nil, err
+		}
+		orgID = &id
+	}
+
+	// 🚨 SECURITY: Only site admins can create repo groups that aren't owned by an
+	// organization, and only members of an organization can create repo groups owned by it.
+	if err := checkRepoGroupAccess(ctx, orgID); err != nil {
+		return nil, err
+	}
+
+	rules, err := args.Rules.rules()
+	if err != nil {
+		return nil, err
+	}
+
+	group := &types.RepoGroup{
+		Name:  args.Name,
+		Rules: rules,
+		OrgID: orgID,
+	}
+	if args.Description != nil {
+		group.Description = *args.Description
+	}
+	if err := db.RepoGroups.Create(ctx, group); err != nil {
+		return nil, err
+	}
+	ruleRepoGroups.invalidate()
+
+	return &repoGroup{name: group.Name, group: group}, nil
+}
+
+func (r *schemaResolver) UpdateRepoGroup(ctx context.Context, args *struct {
+	ID          graphql.ID
+	Name        string
+	Description *string
+	Rules       repoGroupRulesInput
+}) (*repoGroup, error) {
+	id, err := unmarshalRepoGroupID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := db.RepoGroups.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Make sure the current user has permission to update repo groups of the group's owner.
+	if err := checkRepoGroupAccess(ctx, group.OrgID); err != nil {
+		return nil, err
+	}
+
+	rules, err := args.Rules.rules()
+	if err != nil {
+		return nil, err
+	}
+
+	group.Name = args.Name
+	group.Rules = rules
+	if args.Description != nil {
+		group.Description = *args.Description
+	}
+	if err := db.RepoGroups.Update(ctx, group); err != nil {
+		return nil, err
+	}
+	ruleRepoGroups.invalidate()
+
+	return &repoGroup{name: group.Name, group: group}, nil
+}
+
+func (r *schemaResolver) DeleteRepoGroup(ctx context.Context, args *struct {
+	ID graphql.ID
+}) (*EmptyResponse, error) {
+	id, err := unmarshalRepoGroupID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+	group, err := db.RepoGroups.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Make sure the current user has permission to delete repo groups of the group's owner.
+	if err := checkRepoGroupAccess(ctx, group.OrgID); err != nil {
+		return nil, err
+	}
+
+	if err := db.RepoGroups.Delete(ctx, id); err != nil {
+		return nil, err
+	}
+	ruleRepoGroups.invalidate()
+
+	return &EmptyResponse{}, nil
+}
+
+// repoGroupsCacheTTL is how long the repositories matching rule based repo
+// groups are cached for when resolving repogroup: search filters.
+const repoGroupsCacheTTL = time.Minute
+
+// repoGroupMaxRepos is the maximum number of repositories a rule based repo
+// group resolves to. Groups matching more are truncated.
+const repoGroupMaxRepos = 10000
+
+// repoGroupsCache caches the repositories matching each rule based repo group.
+// Once fetched, expired groups keep being served while they're refreshed in the
+// background, so that searches never wait on a refresh.
+type repoGroupsCache struct {
+	mu         sync.Mutex
+	groups     map[string][]*types.Repo
+	fetched    time.Time
+	refreshing bool
+
+	// generation is incremented by invalidate, so that fetches started before
+	// an invalidation don't store stale groups.
+	generation int
+}
+
+var ruleRepoGroups = &repoGroupsCache{}
+
+// get returns the repositories matching each rule based repo group, keyed by
+// group name.
+//
+// 🚨 SECURITY: The repositories are listed with an internal actor so that they
+// can be shared by all users. They must only be used to narrow down the
+// repositories of a search, which are subject to the viewer's permissions.
+func (c *repoGroupsCache) get(ctx context.Context) (map[string][]*types.Repo, error) {
+	c.mu.Lock()
+	groups, generation := c.groups, c.generation
+	if groups != nil && time.Since(c.fetched) >= repoGroupsCacheTTL && !c.refreshing {
+		c.refreshing = true
+		go c.refresh(generation)
+	}
+	c.mu.Unlock()
+
+	if groups != nil {
+		return groups, nil
+	}
+
+	// Nothing is cached yet, or it was invalidated: fetch the groups for this
+	// request without holding the lock.
+	groups, err := listRuleRepoGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.store(generation, groups)
+	return groups, nil
+}
+
+// refresh fetches the groups in the background and stores them.
+func (c *repoGroupsCache) refresh(generation int) {
+	groups, err := listRuleRepoGroups(context.Background())
+
+	c.mu.Lock()
+	c.refreshing = false
+	c.mu.Unlock()
+
+	if err != nil {
+		log15.Error("refreshing rule based repo groups", "error", err)
+		return
+	}
+	c.store(generation, groups)
+}
+
+// store caches the given groups unless the cache was invalidated after they
+// started being fetched.
+func (c *repoGroupsCache) store(generation int, groups map[string][]*types.Repo) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.generation == generation {
+		c.groups, c.fetched = groups, time.Now()
+	}
+}
+
+// invalidate drops the cached repositories, so that changes to repo groups are
+// visible immediately on this frontend instance.
+func (c *repoGroupsCache) invalidate() {
+	c.mu.Lock()
+	c.groups = nil
+	c.generation++
+	c.mu.Unlock()
+}
+
+// listRuleRepoGroups lists the repositories matching each rule based repo
+// group, keyed by group name.
+func listRuleRepoGroups(ctx context.Context) (map[string][]*types.Repo, error) {
+	ctx = actor.WithActor(ctx, &actor.Actor{Internal: true})
+	groups, err := db.RepoGroups.List(ctx, db.RepoGroupsListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	reposByGroup := make(map[string][]*types.Repo, len(groups))
+	for _, g := range groups {
+		opt := db.RepoGroupReposListOptions(g.Rules)
+		opt.OnlyRepoIDs = true
+		opt.LimitOffset = &db.LimitOffset{Limit: repoGroupMaxRepos + 1}
+		repos, err := db.Repos.List(ctx, opt)
+		if err != nil {
+			return nil, err
+		}
+		if len(repos) > repoGroupMaxRepos {
+			log15.Warn("rule based repo group matches too many repositories, truncating", "group", g.Name, "limit", repoGroupMaxRepos)
+			repos = repos[:repoGroupMaxRepos]
+		}
+		reposByGroup[g.Name] = repos
+	}
+
+	return reposByGroup, nil
+}
@@ -0,0 +1,258 @@
+package graphqlbackend
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+func TestRepoGroupRulesInput(t *testing.T) {
+	strs := func(ss ...string) *[]string { return &ss }
+	str := func(s string) *string { return &s }
+	archived := false
+	extsvcID := marshalExternalServiceID(3)
+
+	for _, tc := range []struct {
+		name  string
+		input repoGroupRulesInput
+		want  types.RepoGroupRules
+		err   bool
+	}{
+		{
+			name:  "no rules",
+			input: repoGroupRulesInput{Topics: strs(), NamePattern: str("")},
+			err:   true,
+		},
+		{
+			name:  "invalid name pattern",
+			input: repoGroupRulesInput{NamePattern: str("(")},
+			err:   true,
+		},
+		{
+			name: "all rules",
+			input: repoGroupRulesInput{
+				ExternalServiceIDs: &[]graphql.ID{extsvcID},
+				NamePattern:        str("^github\\.com/acme/"),
+				Topics:             strs("payments"),
+				Languages:          strs("Go"),
+				Archived:           &archived,
+				Visibilities:       strs("internal", "private"),
+			},
+			want: types.RepoGroupRules{
+				ExternalServiceIDs: []int64{3},
+				NamePattern:        "^github\\.com/acme/",
+				Topics:             []string{"payments"},
+				Languages:          []string{"Go"},
+				Archived:           &archived,
+				Visibilities:       []string{"internal", "private"},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			have, err := tc.input.rules()
+			if tc.err != (err != nil) {
+				t.Fatalf("have err %v, want err %t", err, tc.err)
+			}
+			if err == nil && !reflect.DeepEqual(have, tc.want) {
+				t.Errorf("have rules %+v, want %+v", have, tc.want)
+			}
+		})
+	}
+}
+
+func TestCreateRepoGroup(t *testing.T) {
+	ctx := context.Background()
+	defer resetMocks()
+
+	var created *types.RepoGroup
+	db.Mocks.RepoGroups.Create = func(ctx context.Context, group *types.RepoGroup) error {
+		group.ID = 1
+		created = group
+		return nil
+	}
+
+	type args = struct {
+		Name         string
+		Description  *string
+		Rules        repoGroupRulesInput
+		Organization *graphql.ID
+	}
+	topics := []string{"payments"}
+	orgID := marshalOrgID(5)
+
+	t.Run("site admin", func(t *testing.T) {
+		created = nil
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{SiteAdmin: true, ID: 1}, nil
+		}
+
+		g, err := (&schemaResolver{}).CreateRepoGroup(ctx, &args{
+			Name:  "payments",
+			Rules: repoGroupRulesInput{Topics: &topics},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created == nil || created.OrgID != nil || !reflect.DeepEqual(created.Rules.Topics, topics) {
+			t.Fatalf("unexpected repo group created: %+v", created)
+		}
+		if id := g.ID(); id == nil || *id != marshalRepoGroupID(1) {
+			t.Errorf("unexpected repo group ID %v", id)
+		}
+	})
+
+	t.Run("non site admin without organization", func(t *testing.T) {
+		created = nil
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 2}, nil
+		}
+
+		_, err := (&schemaResolver{}).CreateRepoGroup(ctx, &args{
+			Name:  "payments",
+			Rules: repoGroupRulesInput{Topics: &topics},
+		})
+		if err != backend.ErrMustBeSiteAdmin {
+			t.Fatalf("have err %v, want %v", err, backend.ErrMustBeSiteAdmin)
+		}
+		if created != nil {
+			t.Fatal("repo group was created")
+		}
+	})
+
+	t.Run("organization member", func(t *testing.T) {
+		created = nil
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 2}, nil
+		}
+		db.Mocks.OrgMembers.GetByOrgIDAndUserID = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+			return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+		}
+
+		_, err := (&schemaResolver{}).CreateRepoGroup(ctx, &args{
+			Name:         "payments",
+			Rules:        repoGroupRulesInput{Topics: &topics},
+			Organization: &orgID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if created == nil || created.OrgID == nil || *created.OrgID != 5 {
+			t.Fatalf("unexpected repo group created: %+v", created)
+		}
+	})
+
+	t.Run("non organization member", func(t *testing.T) {
+		created = nil
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{ID: 2}, nil
+		}
+		db.Mocks.OrgMembers.GetByOrgIDAndUserID = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+			return nil, &errcode.Mock{IsNotFound: true}
+		}
+
+		_, err := (&schemaResolver{}).CreateRepoGroup(ctx, &args{
+			Name:         "payments",
+			Rules:        repoGroupRulesInput{Topics: &topics},
+			Organization: &orgID,
+		})
+		if err != backend.ErrNotAnOrgMember {
+			t.Fatalf("have err %v, want %v", err, backend.ErrNotAnOrgMember)
+		}
+		if created != nil {
+			t.Fatal("repo group was created")
+		}
+	})
+}
+
+func TestRepoGroupsCache(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+
+	db.Mocks.RepoGroups.List = func(context.Context, db.RepoGroupsListOptions) ([]*types.RepoGroup, error) {
+		return []*types.RepoGroup{{Name: "g"}}, nil
+	}
+	listed := make(chan struct{}, 10)
+	var repoName api.RepoName = "a"
+	db.Mocks.Repos.List = func(_ context.Context, opt db.ReposListOptions) ([]*types.Repo, error) {
+		if opt.LimitOffset == nil || opt.Limit != repoGroupMaxRepos+1 {
+			t.Errorf("listing of repo group repos isn't bounded: %+v", opt.LimitOffset)
+		}
+		defer func() { listed <- struct{}{} }()
+		return []*types.Repo{{Name: repoName}}, nil
+	}
+
+	ctx := context.Background()
+	c := &repoGroupsCache{}
+	groupRepo := func() api.RepoName {
+		t.Helper()
+		groups, err := c.get(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return groups["g"][0].Name
+	}
+
+	if have := groupRepo(); have != "a" {
+		t.Fatalf("have %q, want %q", have, "a")
+	}
+	<-listed
+
+	// Expired groups are served while they're refreshed in the background.
+	repoName = "b"
+	c.mu.Lock()
+	c.fetched = time.Now().Add(-2 * repoGroupsCacheTTL)
+	c.mu.Unlock()
+	if have := groupRepo(); have != "a" {
+		t.Fatalf("have %q, want stale %q", have, "a")
+	}
+	<-listed
+	for {
+		c.mu.Lock()
+		refreshing := c.refreshing
+		c.mu.Unlock()
+		if !refreshing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if have := groupRepo(); have != "b" {
+		t.Fatalf("have %q, want refreshed %q", have, "b")
+	}
+
+	// Fetches started before an invalidation aren't stored.
+	c.invalidate()
+	c.store(c.generation-1, map[string][]*types.Repo{"g": {{Name: "stale"}}})
+	repoName = "c"
+	if have := groupRepo(); have != "c" {
+		t.Fatalf("have %q, want %q", have, "c")
+	}
+}
+
+func TestRepoGroupRepositories(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+
+	db.Mocks.Repos.List = func(_ context.Context, opt db.ReposListOptions) ([]*types.Repo, error) {
+		if opt.LimitOffset == nil || opt.Limit != repoGroupMaxRepos {
+			t.Errorf("listing of repo group repos isn't bounded: %+v", opt.LimitOffset)
+		}
+		return []*types.Repo{{Name: "a"}, {Name: "b"}}, nil
+	}
+
+	g := repoGroup{group: &types.RepoGroup{Name: "g", Rules: types.RepoGroupRules{Topics: []string{"payments"}}}}
+	have, err := g.Repositories(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("have %q, want %q", have, want)
+	}
+}
@@ -173,10 +173,13 @@ func (r *RepositoryResolver) DefaultBranch(ctx context.Context) (*GitRefResolver
 }
 
 func (r *RepositoryResolver) Language(ctx context.Context) string {
-	// The repository language is the most common language at the HEAD commit of the repository.
-	// Note: the repository database field is no longer updated as of
-	// https://github.com/sourcegraph/sourcegraph/issues/2586, so we do not use it anymore and
-	// instead compute the language on the fly.
+	// Prefer the primary language reported by the code host, if any.
+	if err := r.hydrate(ctx); err == nil && r.repo.Language != "" {
+		return r.repo.Language
+	}
+
+	// Otherwise, the repository language is the most common language at the HEAD commit of
+	// the repository, computed on the fly.
 
 	commitID, err := backend.Repos.ResolveRev(ctx, r.repo, "")
 	if err != nil {
@@ -193,6 +196,33 @@ func (r *RepositoryResolver) Language(ctx context.Context) string {
 	return inventory.Languages[0].Name
 }
 
+func (r *RepositoryResolver) Topics(ctx context.Context) ([]string, error) {
+	if err := r.hydrate(ctx); err != nil {
+		return nil, err
+	}
+
+	if r.repo.Topics == nil {
+		return []string{}, nil
+	}
+	return r.repo.Topics, nil
+}
+
+func (r *RepositoryResolver) Stars(ctx context.Context) (int32, error) {
+	if err := r.hydrate(ctx); err != nil {
+		return 0, err
+	}
+
+	return int32(r.repo.Stars), nil
+}
+
+func (r *RepositoryResolver) Visibility(ctx context.Context) (string, error) {
+	if err := r.hydrate(ctx); err != nil {
+		return "", err
+	}
+
+	return r.repo.Visibility, nil
+}
+
 func (r *RepositoryResolver) Enabled() bool { return true }
 
 func (r *RepositoryResolver) CreatedAt() DateTime {
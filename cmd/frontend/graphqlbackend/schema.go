@@ -48,6 +48,18 @@ type Mutation {
         # created from this PatchSet.
         patches: [PatchInput!]!
     ): PatchSet!
+    # Create a patch set by running a structural search-and-replace on the server.
+    #
+    # The patches are computed in the background for every repository with results for the query.
+    # The progress can be tracked with the PatchSet.status field. Once it is completed, call
+    # createCampaign with the returned PatchSet.id in the CreateCampaignInput.patchSet field.
+    createPatchSetFromSearchAndReplace(
+        # A structural search query whose pattern is the match template, e.g.
+        # 'repo:^github\.com/myorg/ file:.go "fmt.Sprintf(:[args])"'.
+        query: String!
+        # The comby rewrite template for the matches of the query, e.g. 'fmt.Sprint(:[args])'.
+        rewriteTemplate: String!
+    ): PatchSet!
     # Updates a campaign.
     updateCampaign(input: UpdateCampaignInput!): Campaign!
     # Retries creating changesets from the patches in the PatchSet that could not be successfully created on the code host.
@@ -78,6 +90,38 @@ type Mutation {
     # update according to the progress of turning the patches into
     # changesets.
     publishCampaign(campaign: ID!): Campaign!
+    # Sets the policy by which the changesets of an open campaign are merged
+    # automatically once they're approved and their checks pass. If autoMerge
+    # is null, auto-merge is disabled for the campaign.
+    setCampaignAutoMerge(campaign: ID!, autoMerge: CampaignAutoMergeInput): Campaign!
+    # Sets the policy that limits the rate at which the changesets of a campaign
+    # are published on the code hosts. If publishPolicy is null, changesets are
+    # published as fast as possible.
+    setCampaignPublishPolicy(campaign: ID!, publishPolicy: CampaignPublishPolicyInput): Campaign!
+    # Sets the policy that determines who is asked to review the changesets of
+    # a campaign and who they are assigned to, when they're created on the
+    # code hosts. If reviewerPolicy is null, reviews are requested from the
+    # code owners of the changed files and the changesets are assigned to them.
+    setCampaignReviewerPolicy(campaign: ID!, reviewerPolicy: CampaignReviewerPolicyInput): Campaign!
+    # Runs an action, such as commenting, on every changeset of a campaign
+    # that matches the given filters. The action is run in the background on
+    # the code hosts. The returned ChangesetAction reports its progress and the
+    # result for every changeset.
+    createChangesetAction(
+        campaign: ID!
+        kind: ChangesetActionKind!
+        # The body of the comment. Required if kind is COMMENT.
+        comment: String
+        # The labels to add or remove. Required if kind is ADD_LABELS or
+        # REMOVE_LABELS.
+        labels: [String!]
+        # Only act on changesets with the given state
+        state: ChangesetState
+        # Only act on changesets with the given review state
+        reviewState: ChangesetReviewState
+        # Only act on changesets with the given check state
+        checkState: ChangesetCheckState
+    ): ChangesetAction!
     # Creates an ExternalChangeset on the codehost asynchronously.
     # The Patch has to belong to a PatchSet that has been attached
     # to a Campaign. Otherwise an error is returned.
@@ -107,6 +151,11 @@ type Mutation {
     updateExternalService(input: UpdateExternalServiceInput!): ExternalService!
     # Delete an external service. Only site admins may perform this mutation.
     deleteExternalService(externalService: ID!): EmptyResponse!
+    # Previews the effect syncing an external service with the given
+    # configuration would have on the repositories, without saving the
+    # configuration or changing any repositories. Only site admins may perform
+    # this mutation.
+    previewExternalServiceSync(input: PreviewExternalServiceSyncInput!): ExternalServiceSyncPreview!
     # DEPRECATED: All repositories are accessible or deleted. To prevent a
     # repository from being accessed on Sourcegraph add it to the external
     # service exclude configuration. This mutation will be removed in 3.6.
@@ -190,6 +239,32 @@ type Mutation {
     deleteUser(user: ID!, hard: Boolean): EmptyResponse
     # Updates the current user's password. The oldPassword arg must match the user's current password.
     updatePassword(oldPassword: String!, newPassword: String!): EmptyResponse
+    # Starts the two-factor authentication enrollment of the current user. The returned secret must be added to
+    # an authenticator app, and the enrollment confirmed with confirmTwoFactorEnrollment. Starting another
+    # enrollment replaces the secret.
+    #
+    # Only available when the builtin auth provider is enabled.
+    startTwoFactorEnrollment: TwoFactorEnrollment!
+    # Enables two-factor authentication for the current user if the code of the authenticator app is valid. The
+    # result is the user's recovery codes, which the caller is responsible for showing to the user (they are not
+    # accessible by Sourcegraph after this mutation).
+    confirmTwoFactorEnrollment(code: String!): [String!]!
+    # Replaces the recovery codes of the current user with new ones, which are returned. The code must be a valid
+    # two-factor authentication code or recovery code.
+    regenerateTwoFactorRecoveryCodes(code: String!): [String!]!
+    # Disables two-factor authentication for a user. Users must provide a valid two-factor authentication code
+    # or recovery code to disable their own. Site admins can disable it for other users without one (e.g., for
+    # users who have lost their authenticator app and recovery codes).
+    disableTwoFactorAuthentication(user: ID!, code: String): EmptyResponse!
+    # Revokes a session of a user (see User.sessions), which signs out the browser or device that used it.
+    #
+    # Only the user and site admins may revoke the user's sessions.
+    revokeUserSession(session: ID!): EmptyResponse!
+    # Revokes all sessions of a user (see User.sessions). If keepCurrent is true, the session of the current
+    # request is not revoked.
+    #
+    # Only the user and site admins may revoke the user's sessions.
+    revokeAllUserSessions(user: ID!, keepCurrent: Boolean = false): EmptyResponse!
     # Creates an access token that grants the privileges of the specified user (referred to as the access token's
     # "subject" user after token creation). The result is the access token value, which the caller is responsible
     # for storing (it is not accessible by Sourcegraph after creation).
@@ -200,8 +275,19 @@ type Mutation {
     # - "site-admin:sudo": Ability to perform any action as any other user. (Only site admins may create tokens
     #   with this scope.)
     #
+    # Access tokens without the "user:all" scope can only perform the actions granted by their other scopes:
+    #
+    # - "search:read": Ability to run searches.
+    # - "repo:read": Ability to read repositories and their files.
+    # - "campaigns:write": Ability to read, create and update campaigns.
+    # - "code-intel:upload": Ability to upload LSIF data.
+    # - "site-admin:users": Ability to manage users, including with SCIM. (Only site admins may create tokens
+    #   with this scope.)
+    #
+    # If expiresAt is set, the access token can't be used after that time.
+    #
     # Only the user or site admins may perform this mutation.
-    createAccessToken(user: ID!, scopes: [String!]!, note: String!): CreateAccessTokenResult!
+    createAccessToken(user: ID!, scopes: [String!]!, note: String!, expiresAt: DateTime): CreateAccessTokenResult!
     # Deletes and immediately revokes the specified access token, specified by either its ID or by the token
     # itself.
     #
@@ -389,6 +475,35 @@ type Mutation {
     ): SavedSearch!
     # Deletes a saved search
     deleteSavedSearch(id: ID!): EmptyResponse
+    # Creates a repository group defined by rules, which can be searched with the repogroup: filter.
+    #
+    # Only site admins may create repository groups that aren't owned by an organization. Members of an
+    # organization may create repository groups owned by it.
+    createRepoGroup(
+        # The name of the group, used in repogroup: filters. It may only contain letters, digits, '_', '.'
+        # and '-', and must be unique.
+        name: String!
+        # An optional description of the group.
+        description: String
+        # The rules repositories must match to be part of the group.
+        rules: RepoGroupRulesInput!
+        # The organization owning the group, or null if the group is owned by site admins.
+        organization: ID
+    ): RepoGroup!
+    # Updates the name, description and rules of a repository group defined by rules. The owner of a
+    # repository group can't be changed.
+    updateRepoGroup(
+        # The ID of the repository group.
+        id: ID!
+        # The new name of the group.
+        name: String!
+        # The new description of the group. If null, the description is not changed.
+        description: String
+        # The new rules of the group.
+        rules: RepoGroupRulesInput!
+    ): RepoGroup!
+    # Deletes a repository group defined by rules.
+    deleteRepoGroup(id: ID!): EmptyResponse
 
     # (experimental) The LSIF API may change substantially in the near future as we
     # continue to adjust it for our use cases. Changes will not be documented in the
@@ -405,6 +520,37 @@ type Mutation {
         # The level of repository permission.
         perm: RepositoryPermission = READ
     ): EmptyResponse!
+    # Set the explicit permissions of a repository, replacing the full set of users who can read it.
+    # The repository must be from a Gitolite, Phabricator or other Git host external service whose
+    # "authorization" is set.
+    #
+    # Only site admins may perform this mutation.
+    setExplicitRepositoryPermissions(
+        # The name of the repository.
+        repository: String!
+        # The usernames or verified email addresses of the users who can read the repository.
+        users: [String!]!
+    ): EmptyResponse!
+    # Grant a user or an organization read access to a repository until the given time, regardless
+    # of the permissions on the code host. Exactly one of "user" or "organization" is required.
+    #
+    # Only site admins may perform this mutation.
+    grantRepositoryAccess(
+        # The repository to grant access to.
+        repository: ID!
+        # The user to grant access to.
+        user: ID
+        # The organization whose members are granted access.
+        organization: ID
+        # When the access expires. It must be in the future.
+        expiresAt: DateTime!
+        # Why the access is granted (e.g., the incident being responded to).
+        reason: String
+    ): RepositoryAccessGrant!
+    # Revoke an active repository access grant before it expires.
+    #
+    # Only site admins may perform this mutation.
+    revokeRepositoryAccessGrant(id: ID!): EmptyResponse!
 }
 
 # A patch to apply to a repository (in a new branch) when a campaign is created
@@ -491,6 +637,10 @@ type PatchSet implements Node {
 
     # The URL where the PatchSet can be previewed and a campaign can be created from it.
     previewURL: String!
+
+    # The status of computing the patches on the server. It is completed without any items for
+    # PatchSets created from patches computed by the caller.
+    status: BackgroundProcessStatus!
 }
 
 # A paginated list of repository diffs committed to git.
@@ -596,6 +746,10 @@ type Campaign implements Node {
         to: DateTime
     ): [ChangesetCounts!]!
 
+    # Analytics of the changesets in this campaign, such as how long it takes
+    # to review and merge them.
+    analytics: CampaignAnalytics!
+
     # The date and time when the campaign was closed.
     closedAt: DateTime
 
@@ -614,6 +768,262 @@ type Campaign implements Node {
     # Campaign.status increments with every Patch turned into an
     # ExternalChangeset.
     patches(first: Int): PatchConnection!
+
+    # The policy by which the changesets of the campaign are merged
+    # automatically. Null if auto-merge is disabled.
+    autoMerge: CampaignAutoMerge
+
+    # The policy that limits the rate at which the changesets of the campaign
+    # are published. Null if they're published as fast as possible.
+    publishPolicy: CampaignPublishPolicy
+
+    # The policy that overrides who is asked to review the changesets of the
+    # campaign and who they are assigned to. Null if reviews are requested
+    # from the code owners of the changed files.
+    reviewerPolicy: CampaignReviewerPolicy
+
+    # The actions that were run on the changesets of the campaign, oldest first.
+    changesetActions(first: Int): ChangesetActionConnection!
+}
+
+# The policy by which the changesets of a campaign are merged automatically
+# once they're approved and their checks pass.
+type CampaignAutoMerge {
+    # The method used to merge the changesets.
+    method: ChangesetMergeMethod!
+    # The days of the week (in timeZone) on which changesets are merged. If
+    # empty, changesets are merged on every day.
+    days: [Weekday!]!
+    # The time of day (HH:MM in timeZone) from which changesets are merged.
+    # Null if changesets are merged at any time of day.
+    windowStart: String
+    # The time of day (HH:MM in timeZone) until which changesets are merged. If
+    # it's before windowStart, the window spans midnight.
+    windowEnd: String
+    # The IANA time zone (such as "Europe/Berlin") of days, windowStart and
+    # windowEnd.
+    timeZone: String!
+    # The maximum number of changesets that are merged in the same repository
+    # at once, including the changesets of other campaigns.
+    maxMergesPerRepository: Int!
+}
+
+# The input to the setCampaignAutoMerge mutation.
+input CampaignAutoMergeInput {
+    # The method used to merge the changesets.
+    method: ChangesetMergeMethod!
+    # The days of the week (in timeZone) on which changesets are merged. If
+    # null or empty, changesets are merged on every day.
+    days: [Weekday!]
+    # The time of day (HH:MM in timeZone) from which changesets are merged.
+    # Must be set together with windowEnd. If both are null, changesets are
+    # merged at any time of day.
+    windowStart: String
+    # The time of day (HH:MM in timeZone) until which changesets are merged. If
+    # it's before windowStart, the window spans midnight.
+    windowEnd: String
+    # The IANA time zone (such as "Europe/Berlin") of days, windowStart and
+    # windowEnd. Defaults to UTC.
+    timeZone: String
+    # The maximum number of changesets that are merged in the same repository
+    # at once, including the changesets of other campaigns. Merging a
+    # changeset moves the base branch of the other changesets in the
+    # repository, so they are merged in later runs, after they have been
+    # rebased. Defaults to 1.
+    maxMergesPerRepository: Int
+}
+
+# The policy that limits the rate at which the changesets of a campaign are
+# published on the code hosts.
+type CampaignPublishPolicy {
+    # The maximum number of changesets published per hour. Null if the number
+    # isn't limited.
+    maxChangesetsPerHour: Int
+    # The days of the week (in timeZone) on which changesets are published. If
+    # empty, changesets are published on every day.
+    days: [Weekday!]!
+    # The time of day (HH:MM in timeZone) from which changesets are published.
+    # Null if changesets are published at any time of day.
+    windowStart: String
+    # The time of day (HH:MM in timeZone) until which changesets are
+    # published. If it's before windowStart, the window spans midnight.
+    windowEnd: String
+    # The IANA time zone (such as "Europe/Berlin") of days, windowStart and
+    # windowEnd.
+    timeZone: String!
+    # The number of changesets published in a first batch. The remaining
+    # changesets are only published once all changesets of the first batch
+    # have been merged, closed or deleted. Null if all changesets are published in one batch.
+    initialBatchSize: Int
+}
+
+# The input to the setCampaignPublishPolicy mutation.
+input CampaignPublishPolicyInput {
+    # The maximum number of changesets published per hour. If null or 0, the
+    # number isn't limited.
+    maxChangesetsPerHour: Int
+    # The days of the week (in timeZone) on which changesets are published. If
+    # null or empty, changesets are published on every day.
+    days: [Weekday!]
+    # The time of day (HH:MM in timeZone) from which changesets are published.
+    # Must be set together with windowEnd. If both are null, changesets are
+    # published at any time of day.
+    windowStart: String
+    # The time of day (HH:MM in timeZone) until which changesets are
+    # published. If it's before windowStart, the window spans midnight.
+    windowEnd: String
+    # The IANA time zone (such as "Europe/Berlin") of days, windowStart and
+    # windowEnd. Defaults to UTC.
+    timeZone: String
+    # The number of changesets published in a first batch. The remaining
+    # changesets are only published once all changesets of the first batch
+    # have been merged, closed or deleted. If null or 0, all changesets are published in one
+    # batch.
+    initialBatchSize: Int
+}
+
+# The policy that determines who is asked to review the changesets of a
+# campaign and who they are assigned to. Reviewers and assignees are given
+# like in CODEOWNERS files: "@username", "@org/team" or an e-mail address.
+type CampaignReviewerPolicy {
+    # Whether reviews from the code owners of the changed files, as defined in
+    # the CODEOWNERS file of the repository, are not requested.
+    ignoreCodeOwners: Boolean!
+    # The reviewers of every changeset, in addition to the code owners.
+    reviewers: [String!]!
+    # The assignees of every changeset. If empty, the changesets are assigned
+    # to the code owners.
+    assignees: [String!]!
+}
+
+# The input to the setCampaignReviewerPolicy mutation.
+input CampaignReviewerPolicyInput {
+    # Whether reviews from the code owners of the changed files, as defined in
+    # the CODEOWNERS file of the repository, are not requested. Defaults to
+    # false.
+    ignoreCodeOwners: Boolean
+    # The reviewers of every changeset, in addition to the code owners.
+    reviewers: [String!]
+    # The assignees of every changeset. If null or empty, the changesets are
+    # assigned to the code owners.
+    assignees: [String!]
+}
+
+# The method by which a changeset is merged on the code host.
+enum ChangesetMergeMethod {
+    # Merge the changeset with a merge commit.
+    MERGE
+    # Squash the commits of the changeset into a single commit.
+    SQUASH
+    # Rebase the commits of the changeset onto the base branch.
+    REBASE
+}
+
+# The kind of an action run on many changesets at once.
+enum ChangesetActionKind {
+    # Comment on the changesets.
+    COMMENT
+    # Add labels to the changesets, creating the labels that don't exist yet.
+    ADD_LABELS
+    # Remove labels from the changesets.
+    REMOVE_LABELS
+    # Request reviews again from the reviewers who didn't approve the
+    # changesets in their latest review.
+    REREQUEST_REVIEWS
+    # Re-run the failed checks of the changesets.
+    RERUN_CHECKS
+}
+
+# An action run on the changesets of a campaign that matched its filters.
+type ChangesetAction implements Node {
+    # The unique ID for the changeset action.
+    id: ID!
+
+    # The campaign whose changesets the action is run on.
+    campaign: Campaign!
+
+    # The user who created the action.
+    author: User!
+
+    # The kind of the action.
+    kind: ChangesetActionKind!
+
+    # The body of the comment, if kind is COMMENT.
+    comment: String
+
+    # The labels that are added or removed, if kind is ADD_LABELS or
+    # REMOVE_LABELS.
+    labels: [String!]!
+
+    # The state the changesets were filtered by, if any.
+    state: ChangesetState
+
+    # The review state the changesets were filtered by, if any.
+    reviewState: ChangesetReviewState
+
+    # The check state the changesets were filtered by, if any.
+    checkState: ChangesetCheckState
+
+    # The date and time when the action was created.
+    createdAt: DateTime!
+
+    # The progress of running the action on the changesets.
+    status: BackgroundProcessStatus!
+
+    # The results of running the action on every changeset it matched.
+    results(first: Int): ChangesetActionResultConnection!
+}
+
+# A list of changeset actions.
+type ChangesetActionConnection {
+    # A list of changeset actions.
+    nodes: [ChangesetAction!]!
+
+    # The total number of changeset actions in the connection.
+    totalCount: Int!
+
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# The result of running a changeset action on a single changeset.
+type ChangesetActionResult {
+    # The changeset the action is run on.
+    changeset: ExternalChangeset!
+
+    # The error that occurred on the code host, if any.
+    error: String
+
+    # The date and time when running the action on the changeset started.
+    # Null if it hasn't started yet.
+    startedAt: DateTime
+
+    # The date and time when running the action on the changeset finished.
+    # Null if it hasn't finished yet.
+    finishedAt: DateTime
+}
+
+# A list of changeset action results.
+type ChangesetActionResultConnection {
+    # A list of changeset action results.
+    nodes: [ChangesetActionResult!]!
+
+    # The total number of changeset action results in the connection.
+    totalCount: Int!
+
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# A day of the week.
+enum Weekday {
+    SUNDAY
+    MONDAY
+    TUESDAY
+    WEDNESDAY
+    THURSDAY
+    FRIDAY
+    SATURDAY
 }
 
 # The counts of changesets in certain states at a specific point in time.
@@ -636,6 +1046,71 @@ type ChangesetCounts {
     openPending: Int!
 }
 
+# Analytics of the changesets in a campaign.
+type CampaignAnalytics {
+    # How long it took the merged changesets to be merged after they were
+    # opened.
+    timeToMerge: DurationDistribution!
+    # How long it took the reviewed changesets to receive their first review
+    # after they were opened.
+    reviewLatency: DurationDistribution!
+    # The counts of changesets per owner of their repository, such as a GitHub
+    # organization or a GitLab group, sorted by the number of changesets in
+    # descending order.
+    repositoryOwners: [RepositoryOwnerChangesetCounts!]!
+    # The checks that fail on open changesets, sorted by the number of
+    # changesets they fail on in descending order.
+    checkFailures: [ChangesetCheckFailure!]!
+    # The analytics of every changeset as CSV, one changeset per row, with a
+    # header row.
+    csv: String!
+}
+
+# The distribution of a set of durations.
+type DurationDistribution {
+    # The number of durations.
+    count: Int!
+    # The median of the durations in hours. Null if there are no durations.
+    medianHours: Float
+    # The 90th percentile of the durations in hours. Null if there are no
+    # durations.
+    p90Hours: Float
+    # The number of durations in each of a fixed set of ranges, shortest first.
+    buckets: [DurationBucket!]!
+}
+
+# The number of durations in a range of durations.
+type DurationBucket {
+    # The upper bound of the range in hours, exclusive. The lower bound is the
+    # upper bound of the previous bucket. Null if the range is unbounded.
+    maxHours: Int
+    # The number of durations in the range.
+    count: Int!
+}
+
+# The counts of the changesets in the repositories of a single owner.
+type RepositoryOwnerChangesetCounts {
+    # The owner of the repositories, such as a GitHub organization or a GitLab
+    # group. Empty if the repositories aren't known anymore.
+    owner: String!
+    # The total number of changesets.
+    total: Int!
+    # The number of open changesets.
+    open: Int!
+    # The number of merged changesets.
+    merged: Int!
+    # The number of closed or deleted changesets.
+    closed: Int!
+}
+
+# A check that fails on open changesets.
+type ChangesetCheckFailure {
+    # The name of the check.
+    name: String!
+    # The number of open changesets the check fails on.
+    changesetCount: Int!
+}
+
 # A list of campaigns.
 type CampaignConnection {
     # A list of campaigns.
@@ -771,6 +1246,11 @@ type ExternalChangeset implements Node {
     # The state of the continuous integration checks on this changeset.
     # It can be null if no checks have been configured.
     checkState: ChangesetCheckState
+
+    # The error of the last attempt to re-apply the campaign's patch onto the
+    # latest commit of the base branch. It is null if the last attempt
+    # succeeded or if the changeset was never rebased.
+    rebaseError: String
 }
 
 # A list of changesets.
@@ -841,6 +1321,16 @@ input UpdateExternalServiceInput {
     config: String
 }
 
+# The configuration of an external service to preview a sync of.
+input PreviewExternalServiceSyncInput {
+    # The id of the external service being updated, if it already exists.
+    id: ID
+    # The kind of the external service. Required if id is not set.
+    kind: ExternalServiceKind
+    # The proposed JSON configuration of the external service.
+    config: String!
+}
+
 # A selection within a file.
 input DiscussionThreadTargetRepoSelectionInput {
     # The line that the selection started on (zero-based, inclusive).
@@ -1380,6 +1870,32 @@ type Query {
     # Returns a list of usernames or emails that have associated pending permissions.
     # The returned list can be used to query authorizedUserRepositories for pending permissions.
     usersWithPendingPermissions: [String!]!
+
+    # Explains whether a user can read a repository, including the authorization providers of the
+    # repository's code host, the permissions stored in the database and the results of the last
+    # background permissions syncs.
+    #
+    # Only site admins may perform this query.
+    repositoryPermissionsExplanation(
+        # The username of the user.
+        username: String!
+        # The name of the repository.
+        repository: String!
+    ): RepositoryPermissionsExplanation!
+
+    # The repository access grants, newest first.
+    #
+    # Only site admins may perform this query.
+    repositoryAccessGrants(
+        # Only return the access grants to this repository.
+        repository: ID
+        # Only return the access grants to this user.
+        user: ID
+        # Only return the access grants to this organization.
+        organization: ID
+        # Whether to also return the access grants that have expired or been revoked.
+        includeInactive: Boolean = false
+    ): [RepositoryAccessGrant!]!
 }
 
 # The version of the search syntax.
@@ -1626,12 +2142,59 @@ type SearchQueryDescription {
 
 # A group of repositories.
 type RepoGroup {
+    # The unique ID of the group, or null if the group is defined in the search.repositoryGroups settings.
+    id: ID
     # The name.
     name: String!
-    # The repositories.
+    # The description, or an empty string if the group is defined in settings.
+    description: String!
+    # The rules repositories must match to be part of the group, or null if the group is a static list of
+    # repositories defined in settings.
+    rules: RepoGroupRules
+    # The organization owning the group, or null if the group is owned by site admins or defined in settings.
+    organization: Org
+    # Whether the viewer can update or delete the group.
+    viewerCanAdminister: Boolean!
+    # The repositories. Rule based groups list at most 10000 repositories.
     repositories: [String!]!
 }
 
+# The rules a repository must match to be part of a repository group. Repositories must match all the rules
+# that are set.
+type RepoGroupRules {
+    # The external services yielding the repositories of the group. If empty, repositories of all external
+    # services match.
+    externalServiceIDs: [ID!]!
+    # A regular expression repository names must match, if any.
+    namePattern: String
+    # The code host topics repositories must all be labelled with.
+    topics: [String!]!
+    # The primary languages of the repositories, as reported by their code host. If empty, repositories of
+    # all languages match.
+    languages: [String!]!
+    # Whether only archived (or only non-archived) repositories match, if set.
+    archived: Boolean
+    # The code host visibilities of the repositories (such as "public", "internal" or "private"). If empty,
+    # repositories of all visibilities match.
+    visibilities: [String!]!
+}
+
+# The rules a repository must match to be part of a repository group. At least one rule must be set.
+input RepoGroupRulesInput {
+    # The external services yielding the repositories of the group.
+    externalServiceIDs: [ID!]
+    # A regular expression repository names must match.
+    namePattern: String
+    # The code host topics repositories must all be labelled with.
+    topics: [String!]
+    # The primary languages of the repositories, as reported by their code host.
+    languages: [String!]
+    # Whether only archived (or only non-archived) repositories match.
+    archived: Boolean
+    # The code host visibilities of the repositories (such as "public", "internal" or "private").
+    visibilities: [String!]
+}
+
 # A diff between two diffable Git objects.
 type Diff {
     # The diff's repository.
@@ -1752,6 +2315,57 @@ type ExternalService implements Node {
     # It is a field on ExternalService instead of a separate thing in order to
     # not break the API and stay backwards compatible.
     warning: String
+    # The history of syncs of the external service's repositories, most recent first.
+    syncRuns(
+        # Returns the first n sync runs from the list.
+        first: Int
+    ): ExternalServiceSyncRunConnection!
+}
+
+# A list of external service sync runs.
+type ExternalServiceSyncRunConnection {
+    # A list of sync runs.
+    nodes: [ExternalServiceSyncRun!]!
+    # The total count of sync runs in the connection. This total count may be larger than the number of nodes
+    # in this object when the result is paginated.
+    totalCount: Int!
+    # Pagination information.
+    pageInfo: PageInfo!
+}
+
+# A single sync of the repositories of an external service.
+type ExternalServiceSyncRun {
+    # When the sync started.
+    startedAt: DateTime!
+    # When the sync finished.
+    finishedAt: DateTime!
+    # The number of repositories that were added.
+    added: Int!
+    # The number of repositories that were deleted.
+    deleted: Int!
+    # The number of repositories that were modified.
+    modified: Int!
+    # The number of repositories that were unmodified.
+    unmodified: Int!
+    # The time spent waiting for the code host's rate limit, in milliseconds.
+    rateLimitWaitMilliseconds: Int!
+    # The error the sync ran into, if any. Failed syncs don't change any repositories.
+    error: String
+}
+
+# The effect syncing an external service with a proposed configuration would
+# have on the repositories.
+type ExternalServiceSyncPreview {
+    # The names of the repositories that would be added.
+    added: [String!]!
+    # The names of the repositories that would be deleted. Repositories also
+    # synced by other external services are never deleted.
+    deleted: [String!]!
+    # The names of the repositories that would be modified.
+    modified: [String!]!
+    # The number of repositories synced by the external service that would
+    # remain unmodified.
+    unmodifiedCount: Int!
 }
 
 # A list of repositories.
@@ -1788,6 +2402,14 @@ type Repository implements Node & GenericSearchResultInterface {
     description: String!
     # The primary programming language in the repository.
     language: String!
+    # The topics (or tags) the repository is labelled with on its code host. Repositories can be searched by
+    # topic with the repo:has.topic(name) predicate.
+    topics: [String!]!
+    # The number of stars (or likes) the repository has on its code host.
+    stars: Int!
+    # The visibility of the repository on its code host (such as "public", "internal" or "private"), or an empty
+    # string if the code host doesn't report it.
+    visibility: String!
     # DEPRECATED: This field is unused in known clients.
     #
     # The date when this repository was created on Sourcegraph.
@@ -2970,6 +3592,10 @@ type User implements Node & SettingsSubject & Namespace {
     siteAdmin: Boolean!
     # Whether the user account uses built in auth.
     builtinAuth: Boolean!
+    # Whether the user has enabled two-factor authentication for signing in with a username and password.
+    #
+    # Only the user and site admins can access this field.
+    twoFactorAuthenticationEnabled: Boolean!
     # The latest settings for the user.
     #
     # Only the user and site admins can access this field.
@@ -3021,6 +3647,11 @@ type User implements Node & SettingsSubject & Namespace {
     # Only the currently authenticated user can access this field. Site admins are not able to access sessions for
     # other users.
     session: Session!
+    # The user's signed-in sessions (such as browsers and devices), most recently seen first. Sessions are
+    # revoked when the user changes or resets their password and when the user is deleted.
+    #
+    # Only the user and site admins can access this field.
+    sessions: [UserSession!]!
     # Whether the viewer has admin privileges on this user. The user has admin privileges on their own user, and
     # site admins have admin privileges on all users.
     viewerCanAdminister: Boolean!
@@ -3048,6 +3679,14 @@ type User implements Node & SettingsSubject & Namespace {
     namespaceName: String!
 }
 
+# A two-factor authentication enrollment in progress.
+type TwoFactorEnrollment {
+    # The secret to add to an authenticator app, encoded in base32.
+    secret: String!
+    # The otpauth:// URI of the secret, which authenticator apps can import from a QR code.
+    keyURI: String!
+}
+
 # An access token that grants to the holder the privileges of the user who created it.
 type AccessToken implements Node {
     # The unique ID for the access token.
@@ -3065,6 +3704,8 @@ type AccessToken implements Node {
     createdAt: DateTime!
     # The date when the access token was last used to authenticate a request.
     lastUsedAt: DateTime
+    # The date after which the access token can't be used, or null if it never expires.
+    expiresAt: DateTime
 }
 
 # A list of access tokens.
@@ -3150,6 +3791,23 @@ type Session {
     canSignOut: Boolean!
 }
 
+# A signed-in session of a user.
+type UserSession implements Node {
+    # The unique ID for the session.
+    id: ID!
+    # The User-Agent HTTP request header of the browser or device that signed in.
+    userAgent: String!
+    # The IP address that the session was last seen from. It is taken from the X-Forwarded-For HTTP request
+    # header if present.
+    ip: String!
+    # The date when the user signed in.
+    createdAt: DateTime!
+    # The date when the session was last used (updated at most every 5 minutes).
+    lastSeenAt: DateTime!
+    # Whether this is the session of the current request.
+    isCurrent: Boolean!
+}
+
 # An organization membership.
 type OrganizationMembership {
     # The organization.
@@ -4720,6 +5378,88 @@ enum RepositoryPermission {
     READ
 }
 
+# An explanation of whether a user can read a repository.
+type RepositoryPermissionsExplanation {
+    # The user.
+    user: User!
+    # The repository.
+    repository: Repository!
+    # Whether the user can read the repository, as evaluated by the repository permissions enforcement.
+    allowed: Boolean!
+    # The rule of the repository permissions enforcement that decides whether the user can read
+    # the repository.
+    reason: String!
+    # The authorization providers of the repository's code host. Permissions are enforced by the
+    # first one unless they are synced in the background.
+    providers: [AuthorizationProviderExplanation!]!
+    # The permissions of the user stored in the database, or null if none are stored.
+    userPermissions: StoredRepositoryPermissions
+    # The permissions of the repository stored in the database, or null if none are stored.
+    repositoryPermissions: StoredRepositoryPermissions
+    # The result of the last background permissions sync of the user, or null if the user hasn't been
+    # synced since repo-updater started.
+    lastUserSync: PermissionsSyncResult
+    # The result of the last background permissions sync of the repository, or null if the repository
+    # hasn't been synced since repo-updater started.
+    lastRepositorySync: PermissionsSyncResult
+}
+
+# The decision of an authorization provider on whether a user can read a repository.
+type AuthorizationProviderExplanation {
+    # The type of the provider's code host (e.g., "github").
+    serviceType: String!
+    # The ID of the provider's code host (e.g., "https://github.com/").
+    serviceID: String!
+    # The ID of the user's external account on the code host, or null if the user has none.
+    accountID: String
+    # Whether the provider grants the user read access to the repository.
+    allowed: Boolean!
+    # The error of the provider, if any.
+    error: String
+}
+
+# Repository permissions stored in the database.
+type StoredRepositoryPermissions {
+    # Whether the stored permissions grant the user read access to the repository.
+    allowed: Boolean!
+    # When the permissions were last updated.
+    updatedAt: DateTime!
+}
+
+# The result of a background permissions sync.
+type PermissionsSyncResult {
+    # When the sync started.
+    startedAt: DateTime!
+    # When the sync finished.
+    finishedAt: DateTime!
+    # The error of the sync, or null if it succeeded.
+    error: String
+}
+
+# A time-limited grant of read access to a repository for a user or the members of an organization.
+type RepositoryAccessGrant {
+    # The unique ID of the access grant.
+    id: ID!
+    # The repository the access is granted to.
+    repository: Repository!
+    # The user who is granted access, or null if the access is granted to an organization.
+    user: User
+    # The organization whose members are granted access, or null if the access is granted to a user.
+    organization: Org
+    # The site admin who granted the access, or null if they have since been deleted.
+    creator: User
+    # Why the access is granted.
+    reason: String!
+    # When the access expires.
+    expiresAt: DateTime!
+    # When the access was revoked, or null if it hasn't been revoked.
+    revokedAt: DateTime
+    # When the access was granted.
+    createdAt: DateTime!
+    # Whether the access grant is in effect, i.e. it has neither expired nor been revoked.
+    active: Boolean!
+}
+
 # A single user event that has been logged.
 type EventLog {
     # The name of the event.
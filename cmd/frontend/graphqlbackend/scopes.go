@@ -0,0 +1,118 @@
+package graphqlbackend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+// restrictedScopeFields are the fields of the Query and Mutation types that actors restricted by the
+// scopes of their access token (see (*actor.Actor).IsRestricted) may resolve, by scope.
+//
+// 🚨 SECURITY: All other fields of the Query and Mutation types are denied to restricted actors, so
+// only add fields here whose resolvers (and nested resolvers) do nothing beyond what the scope grants.
+var restrictedScopeFields = map[string][]string{
+	authz.ScopeSearchRead: {
+		"Query.search",
+	},
+	authz.ScopeRepoRead: {
+		"Query.node",
+		"Query.repository",
+		"Query.repositoryRedirect",
+		"Query.repositories",
+	},
+	authz.ScopeCampaignsWrite: {
+		"Query.node",
+		"Query.campaigns",
+		"Mutation.createChangesets",
+		"Mutation.addChangesetsToCampaign",
+		"Mutation.createCampaign",
+		"Mutation.createPatchSetFromPatches",
+		"Mutation.createPatchSetFromSearchAndReplace",
+		"Mutation.updateCampaign",
+		"Mutation.retryCampaign",
+		"Mutation.deleteCampaign",
+		"Mutation.closeCampaign",
+		"Mutation.publishCampaign",
+		"Mutation.setCampaignAutoMerge",
+		"Mutation.setCampaignPublishPolicy",
+		"Mutation.setCampaignReviewerPolicy",
+		"Mutation.createChangesetAction",
+		"Mutation.publishChangeset",
+		"Mutation.syncChangeset",
+	},
+	authz.ScopeSiteAdminUsers: {
+		"Mutation.createUser",
+		"Mutation.randomizeUserPassword",
+		"Mutation.setUserEmailVerified",
+		"Mutation.deleteUser",
+	},
+}
+
+// restrictedScopeNodeKinds are the kinds of nodes that restricted actors may look up with the node
+// field, by scope.
+var restrictedScopeNodeKinds = map[string][]string{
+	authz.ScopeRepoRead: {
+		"Repository",
+		"GitCommit",
+		"GitRef",
+	},
+	authz.ScopeCampaignsWrite: {
+		"Campaign",
+		"PatchSet",
+		"Patch",
+		"ExternalChangeset",
+		"ChangesetAction",
+	},
+}
+
+// checkRestrictedScopeField returns an error if the current actor is restricted by the scopes of its
+// access token and none of them allows it to resolve the field of the Query or Mutation type.
+// Introspection fields are always allowed.
+func checkRestrictedScopeField(ctx context.Context, typeName, fieldName string) error {
+	if typeName != "Query" && typeName != "Mutation" || strings.HasPrefix(fieldName, "__") {
+		return nil
+	}
+	field := typeName + "." + fieldName
+	return checkRestrictedScope(ctx, restrictedScopeFields, field, field)
+}
+
+// checkRestrictedScopeNodeKind returns an error if the current actor is restricted by the scopes of
+// its access token and none of them allows it to look up nodes of the kind.
+func checkRestrictedScopeNodeKind(ctx context.Context, kind string) error {
+	return checkRestrictedScope(ctx, restrictedScopeNodeKinds, kind, kind+" nodes")
+}
+
+func checkRestrictedScope(ctx context.Context, allowed map[string][]string, name, description string) error {
+	a := actor.FromContext(ctx)
+	if !a.IsRestricted() {
+		return nil
+	}
+	for _, scope := range a.Scopes {
+		for _, n := range allowed[scope] {
+			if n == name {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("access token scopes %q do not allow %s (only the %q scope allows all actions)", a.Scopes, description, authz.ScopeUserAll)
+}
+
+// deniedContext is the context in which a field denied to the actor is resolved. graphql-go doesn't
+// call the field's resolver if its context is done, and reports the context's error for the field.
+type deniedContext struct {
+	context.Context
+	err error
+}
+
+var closedChan = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+func (c *deniedContext) Done() <-chan struct{} { return closedChan }
+func (c *deniedContext) Err() error            { return c.err }
@@ -0,0 +1,96 @@
+package graphqlbackend
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestCheckRestrictedScopeField(t *testing.T) {
+	tests := map[string]struct {
+		actor     *actor.Actor
+		typeName  string
+		fieldName string
+		wantErr   bool
+	}{
+		"unrestricted":               {actor: &actor.Actor{UID: 1}, typeName: "Query", fieldName: "currentUser"},
+		"restricted, allowed":        {actor: &actor.Actor{UID: 1, Scopes: []string{authz.ScopeSearchRead}}, typeName: "Query", fieldName: "search"},
+		"restricted, other scope":    {actor: &actor.Actor{UID: 1, Scopes: []string{authz.ScopeSearchRead, authz.ScopeRepoRead}}, typeName: "Query", fieldName: "repository"},
+		"restricted, denied":         {actor: &actor.Actor{UID: 1, Scopes: []string{authz.ScopeRepoRead}}, typeName: "Query", fieldName: "search", wantErr: true},
+		"restricted, denied by type": {actor: &actor.Actor{UID: 1, Scopes: []string{authz.ScopeCampaignsWrite}}, typeName: "Query", fieldName: "createCampaign", wantErr: true},
+		"restricted, no scopes":      {actor: &actor.Actor{UID: 1, Scopes: []string{}}, typeName: "Mutation", fieldName: "deleteUser", wantErr: true},
+		"restricted, nested field":   {actor: &actor.Actor{UID: 1, Scopes: []string{authz.ScopeSearchRead}}, typeName: "User", fieldName: "username"},
+		"restricted, introspection":  {actor: &actor.Actor{UID: 1, Scopes: []string{authz.ScopeSearchRead}}, typeName: "Query", fieldName: "__schema"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := actor.WithActor(context.Background(), test.actor)
+			if err := checkRestrictedScopeField(ctx, test.typeName, test.fieldName); (err != nil) != test.wantErr {
+				t.Errorf("got error %v, want error %t", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestRestrictedScopes(t *testing.T) {
+	resetMocks()
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, Username: "alice"}, nil
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "alice"}, nil
+	}
+	defer resetMocks()
+
+	schema := mustParseGraphQLSchema(t)
+	exec := func(a *actor.Actor, query string) (string, []string) {
+		response := schema.Exec(actor.WithActor(context.Background(), a), query, "", nil)
+		var errs []string
+		for _, err := range response.Errors {
+			errs = append(errs, err.Message)
+		}
+		return string(response.Data), errs
+	}
+
+	t.Run("unrestricted", func(t *testing.T) {
+		data, errs := exec(&actor.Actor{UID: 1}, `{ currentUser { username } node(id: "VXNlcjox") { id } }`)
+		if len(errs) != 0 {
+			t.Fatal(errs)
+		}
+		if want := `{"currentUser":{"username":"alice"},"node":{"id":"VXNlcjox"}}`; data != want {
+			t.Errorf("got %s, want %s", data, want)
+		}
+	})
+
+	t.Run("restricted field", func(t *testing.T) {
+		data, errs := exec(&actor.Actor{UID: 1, Scopes: []string{authz.ScopeSearchRead}}, `{ __typename currentUser { username } }`)
+		if len(errs) != 1 || !strings.Contains(errs[0], "do not allow Query.currentUser") {
+			t.Errorf("got errors %q", errs)
+		}
+		if want := `{"__typename":"Query","currentUser":null}`; data != want {
+			t.Errorf("got %s, want %s", data, want)
+		}
+	})
+
+	t.Run("restricted node kind", func(t *testing.T) {
+		data, errs := exec(&actor.Actor{UID: 1, Scopes: []string{authz.ScopeRepoRead}}, `{ node(id: "VXNlcjox") { id } }`)
+		if len(errs) != 1 || !strings.Contains(errs[0], "do not allow User nodes") {
+			t.Errorf("got errors %q", errs)
+		}
+		if want := `{"node":null}`; data != want {
+			t.Errorf("got %s, want %s", data, want)
+		}
+	})
+
+	t.Run("restricted mutation", func(t *testing.T) {
+		_, errs := exec(&actor.Actor{UID: 1, Scopes: []string{authz.ScopeCampaignsWrite}}, `mutation { deleteUser(user: "VXNlcjox") { alwaysNil } }`)
+		if len(errs) != 1 || !strings.Contains(errs[0], "do not allow Mutation.deleteUser") {
+			t.Errorf("got errors %q", errs)
+		}
+	})
+}
@@ -240,6 +240,29 @@ func resolveRepoGroups(ctx context.Context) (map[string][]*types.Repo, error) {
 		return mockResolveRepoGroups()
 	}
 
+	groups, err := resolveSettingsRepoGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Repo groups can also be defined by rules, which are evaluated against
+	// the repositories known to Sourcegraph. Groups defined in settings take
+	// precedence.
+	ruleGroups, err := ruleRepoGroups.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for name, repos := range ruleGroups {
+		if _, ok := groups[name]; !ok {
+			groups[name] = repos
+		}
+	}
+
+	return groups, nil
+}
+
+// resolveSettingsRepoGroups returns the repo groups defined in the viewer's settings.
+func resolveSettingsRepoGroups(ctx context.Context) (map[string][]*types.Repo, error) {
 	groups := map[string][]*types.Repo{}
 
 	// Repo groups can be defined in the search.repoGroups settings field.
@@ -284,6 +307,33 @@ func exactlyOneRepo(repoFilters []string) bool {
 	return false
 }
 
+// repoTopicPredicate matches repo: field values of the form has.topic(name),
+// which select repositories labelled with the given topic on their code host.
+var repoTopicPredicate = lazyregexp.New(`^has\.topic\(([^()]+)\)$`)
+
+// splitRepoTopicPredicates separates the has.topic(...) predicates from the
+// given repo: field values, returning the remaining patterns and the topics.
+func splitRepoTopicPredicates(values []string) (patterns, topics []string) {
+	for _, v := range values {
+		if m := repoTopicPredicate.FindStringSubmatch(v); m != nil {
+			topics = append(topics, m[1])
+		} else {
+			patterns = append(patterns, v)
+		}
+	}
+	return patterns, topics
+}
+
+// repoFieldValues returns the regexp patterns of the query's repo: fields with
+// the has.topic(...) predicates separated out, so that callers never compile a
+// predicate as a repository name pattern.
+func (r *searchResolver) repoFieldValues() (repoFilters, minusRepoFilters, topics, minusTopics []string) {
+	repoFilters, minusRepoFilters = r.query.RegexpPatterns(query.FieldRepo)
+	repoFilters, topics = splitRepoTopicPredicates(repoFilters)
+	minusRepoFilters, minusTopics = splitRepoTopicPredicates(minusRepoFilters)
+	return repoFilters, minusRepoFilters, topics, minusTopics
+}
+
 // resolveRepositories calls doResolveRepositories, caching the result for the common
 // case where effectiveRepoFieldValues == nil.
 func (r *searchResolver) resolveRepositories(ctx context.Context, effectiveRepoFieldValues []string) (repoRevs, missingRepoRevs []*search.RepositoryRevisions, overLimit bool, err error) {
@@ -305,7 +355,7 @@ func (r *searchResolver) resolveRepositories(ctx context.Context, effectiveRepoF
 		}
 	}
 
-	repoFilters, minusRepoFilters := r.query.RegexpPatterns(query.FieldRepo)
+	repoFilters, minusRepoFilters, topics, minusTopics := r.repoFieldValues()
 	if effectiveRepoFieldValues != nil {
 		repoFilters = effectiveRepoFieldValues
 	}
@@ -330,6 +380,8 @@ func (r *searchResolver) resolveRepositories(ctx context.Context, effectiveRepoF
 		repoFilters:      repoFilters,
 		minusRepoFilters: minusRepoFilters,
 		repoGroupFilters: repoGroupFilters,
+		topics:           topics,
+		minusTopics:      minusTopics,
 		onlyForks:        fork == Only || fork == True,
 		noForks:          fork == No || fork == False,
 		onlyArchived:     archived == Only || archived == True,
@@ -443,6 +495,8 @@ type resolveRepoOp struct {
 	repoFilters      []string
 	minusRepoFilters []string
 	repoGroupFilters []string
+	topics           []string
+	minusTopics      []string
 	noForks          bool
 	onlyForks        bool
 	noArchived       bool
@@ -497,7 +551,7 @@ func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions,
 	}
 
 	var defaultRepos []*types.Repo
-	if envvar.SourcegraphDotComMode() && len(includePatterns) == 0 {
+	if envvar.SourcegraphDotComMode() && len(includePatterns) == 0 && len(op.topics) == 0 {
 		getIndexedRepos := func(ctx context.Context, revs []*search.RepositoryRevisions) (indexed, unindexed []*search.RepositoryRevisions, err error) {
 			return zoektIndexedRepos(ctx, search.Indexed(), revs, nil)
 		}
@@ -520,11 +574,13 @@ func resolveRepositories(ctx context.Context, op resolveRepoOp) (repoRevisions,
 			IncludePatterns: includePatterns,
 			ExcludePattern:  unionRegExps(excludePatterns),
 			// List N+1 repos so we can see if there are repos omitted due to our repo limit.
-			LimitOffset:  &db.LimitOffset{Limit: maxRepoListSize + 1},
-			NoForks:      op.noForks,
-			OnlyForks:    op.onlyForks,
-			NoArchived:   op.noArchived,
-			OnlyArchived: op.onlyArchived,
+			LimitOffset:   &db.LimitOffset{Limit: maxRepoListSize + 1},
+			NoForks:       op.noForks,
+			OnlyForks:     op.onlyForks,
+			NoArchived:    op.noArchived,
+			OnlyArchived:  op.onlyArchived,
+			Topics:        op.topics,
+			ExcludeTopics: op.minusTopics,
 		})
 		tr.LazyPrintf("Repos.List - done")
 		if err != nil {
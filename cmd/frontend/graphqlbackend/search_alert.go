@@ -122,7 +122,7 @@ func reposExist(ctx context.Context, options resolveRepoOp) bool {
 }
 
 func (r *searchResolver) alertForNoResolvedRepos(ctx context.Context) *searchAlert {
-	repoFilters, minusRepoFilters := r.query.RegexpPatterns(query.FieldRepo)
+	repoFilters, minusRepoFilters, topics, minusTopics := r.repoFieldValues()
 	repoGroupFilters, _ := r.query.StringValues(query.FieldRepoGroup)
 	fork, _ := r.query.StringValue(query.FieldFork)
 	onlyForks, noForks := fork == "only", fork == "no"
@@ -130,6 +130,15 @@ func (r *searchResolver) alertForNoResolvedRepos(ctx context.Context) *searchAle
 	archived, _ := r.query.StringValue(query.FieldArchived)
 	archivedNotSet := len(archived) == 0
 
+	// Handle has.topic-only scenarios.
+	if len(repoFilters) == 0 && len(topics) > 0 {
+		return &searchAlert{
+			prometheusType: "no_resolved_repos__topic_empty",
+			title:          "No repositories have all of the requested topics",
+			description:    fmt.Sprintf("No repository is labelled with all of the topics %s on its code host.", strings.Join(topics, ", ")),
+		}
+	}
+
 	// Handle repogroup-only scenarios.
 	if len(repoFilters) == 0 && len(repoGroupFilters) == 0 {
 		return &searchAlert{
@@ -170,6 +179,8 @@ func (r *searchResolver) alertForNoResolvedRepos(ctx context.Context) *searchAle
 		tryRemoveRepoGroup := resolveRepoOp{
 			repoFilters:      repoFilters,
 			minusRepoFilters: minusRepoFilters,
+			topics:           topics,
+			minusTopics:      minusTopics,
 			onlyForks:        onlyForks,
 			noForks:          noForks,
 		}
@@ -187,6 +198,8 @@ func (r *searchResolver) alertForNoResolvedRepos(ctx context.Context) *searchAle
 		tryAnyRepo := resolveRepoOp{
 			repoFilters:      []string{unionRepoFilter},
 			minusRepoFilters: minusRepoFilters,
+			topics:           topics,
+			minusTopics:      minusTopics,
 			repoGroupFilters: repoGroupFilters,
 			onlyForks:        onlyForks,
 			noForks:          noForks,
@@ -217,6 +230,8 @@ func (r *searchResolver) alertForNoResolvedRepos(ctx context.Context) *searchAle
 		tryRemoveRepoGroup := resolveRepoOp{
 			repoFilters:      repoFilters,
 			minusRepoFilters: minusRepoFilters,
+			topics:           topics,
+			minusTopics:      minusTopics,
 			onlyForks:        onlyForks,
 			noForks:          noForks,
 		}
@@ -247,6 +262,8 @@ func (r *searchResolver) alertForNoResolvedRepos(ctx context.Context) *searchAle
 		tryAnyRepo := resolveRepoOp{
 			repoFilters:      []string{unionRepoFilter},
 			minusRepoFilters: minusRepoFilters,
+			topics:           topics,
+			minusTopics:      minusTopics,
 			repoGroupFilters: repoGroupFilters,
 			onlyForks:        onlyForks,
 			noForks:          noForks,
@@ -392,7 +409,7 @@ outer:
 			break
 		}
 		repoParentPattern := "^" + regexp.QuoteMeta(repoParent) + "/"
-		repoFieldValues, _ := r.query.RegexpPatterns(query.FieldRepo)
+		repoFieldValues, _, _, _ := r.repoFieldValues()
 
 		for _, v := range repoFieldValues {
 			if strings.HasPrefix(v, strings.TrimSuffix(repoParentPattern, "/")) {
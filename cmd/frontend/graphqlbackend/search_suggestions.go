@@ -72,7 +72,7 @@ func (r *searchResolver) Suggestions(ctx context.Context, args *searchSuggestion
 		if len(r.query.Values(query.FieldDefault)) == 1 && (len(r.query.Fields()) == 1 || (len(r.query.Fields()) == 2 && len(r.query.Values(query.FieldRepoGroup)) == 1)) {
 			effectiveRepoFieldValues = append(effectiveRepoFieldValues, r.query.Values(query.FieldDefault)[0].ToString())
 		} else if len(r.query.Values(query.FieldRepo)) > 0 && ((len(r.query.Values(query.FieldRepoGroup)) > 0 && len(r.query.Fields()) == 2) || (len(r.query.Values(query.FieldRepoGroup)) == 0 && len(r.query.Fields()) == 1)) {
-			effectiveRepoFieldValues, _ = r.query.RegexpPatterns(query.FieldRepo)
+			effectiveRepoFieldValues, _, _, _ = r.repoFieldValues()
 		}
 
 		// If we have a query which is not valid, just ignore it since this is for a suggestion.
@@ -132,7 +132,7 @@ func (r *searchResolver) Suggestions(ctx context.Context, args *searchSuggestion
 		if len(r.query.Values(query.FieldRepo)) == 0 {
 			return nil, nil
 		}
-		effectiveRepoFieldValues, _ := r.query.RegexpPatterns(query.FieldRepo)
+		effectiveRepoFieldValues, _, topics, _ := r.repoFieldValues()
 
 		validValues := effectiveRepoFieldValues[:0]
 		for _, v := range effectiveRepoFieldValues {
@@ -146,13 +146,14 @@ func (r *searchResolver) Suggestions(ctx context.Context, args *searchSuggestion
 				validValues = append(validValues, v)
 			}
 		}
-		if len(validValues) == 0 {
+		if len(validValues) == 0 && len(topics) == 0 {
 			return nil, nil
 		}
 
 		// Only care about the first found repository.
 		repos, err := backend.Repos.List(ctx, db.ReposListOptions{
 			IncludePatterns: validValues,
+			Topics:          topics,
 			OnlyRepoIDs:     true,
 			LimitOffset: &db.LimitOffset{
 				Limit: 1,
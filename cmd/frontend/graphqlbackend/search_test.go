@@ -376,6 +376,43 @@ func Test_exactlyOneRepo(t *testing.T) {
 	}
 }
 
+func Test_splitRepoTopicPredicates(t *testing.T) {
+	cases := []struct {
+		values       []string
+		wantPatterns []string
+		wantTopics   []string
+	}{
+		{
+			values:       []string{`^github\.com/sourcegraph/zoekt$`},
+			wantPatterns: []string{`^github\.com/sourcegraph/zoekt$`},
+		},
+		{
+			values:     []string{`has.topic(payments)`},
+			wantTopics: []string{"payments"},
+		},
+		{
+			values:       []string{`sourcegraph`, `has.topic(payments)`, `has.topic(go)`},
+			wantPatterns: []string{`sourcegraph`},
+			wantTopics:   []string{"payments", "go"},
+		},
+		{
+			values:       []string{`has.topic()`, `xhas.topic(payments)`},
+			wantPatterns: []string{`has.topic()`, `xhas.topic(payments)`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(strings.Join(c.values, " "), func(t *testing.T) {
+			patterns, topics := splitRepoTopicPredicates(c.values)
+			if !reflect.DeepEqual(patterns, c.wantPatterns) {
+				t.Errorf("got patterns %q, want %q", patterns, c.wantPatterns)
+			}
+			if !reflect.DeepEqual(topics, c.wantTopics) {
+				t.Errorf("got topics %q, want %q", topics, c.wantTopics)
+			}
+		})
+	}
+}
+
 func Test_QuoteSuggestions(t *testing.T) {
 	t.Run("regex error", func(t *testing.T) {
 		raw := "*"
@@ -11,12 +11,22 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 )
 
+// checkSiteAdminUsersAccess returns an error if the current user is not a site admin, or if the
+// actor was authenticated with an access token that doesn't have the "site-admin:users" scope.
+func checkSiteAdminUsersAccess(ctx context.Context) error {
+	ctx, err := backend.WithAccessTokenScope(ctx, authz.ScopeSiteAdminUsers)
+	if err != nil {
+		return err
+	}
+	return backend.CheckCurrentUserIsSiteAdmin(ctx)
+}
+
 func (*schemaResolver) DeleteUser(ctx context.Context, args *struct {
 	User graphql.ID
 	Hard *bool
 }) (*EmptyResponse, error) {
 	// 🚨 SECURITY: Only site admins can delete users.
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkSiteAdminUsersAccess(ctx); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,112 @@
+package graphqlbackend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func (r *UserResolver) TwoFactorAuthenticationEnabled(ctx context.Context) (bool, error) {
+	// 🚨 SECURITY: Only the user and admins are allowed to determine if the user has two-factor
+	// authentication enabled.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.user.ID); err != nil {
+		return false, err
+	}
+
+	t, err := db.Users.GetTOTP(ctx, r.user.ID)
+	if err != nil {
+		return false, err
+	}
+	return t.Enabled, nil
+}
+
+type twoFactorEnrollmentResolver struct {
+	secret string
+	keyURI string
+}
+
+func (r *twoFactorEnrollmentResolver) Secret() string { return r.secret }
+func (r *twoFactorEnrollmentResolver) KeyURI() string { return r.keyURI }
+
+func (*schemaResolver) StartTwoFactorEnrollment(ctx context.Context) (*twoFactorEnrollmentResolver, error) {
+	if !providers.BuiltinAuthEnabled() {
+		return nil, errors.New("two-factor authentication is only available with the builtin auth provider")
+	}
+
+	// 🚨 SECURITY: A user can only enroll themselves.
+	user, err := db.Users.GetByCurrentAuthUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("no authenticated user")
+	}
+
+	secret, keyURI, err := backend.StartTwoFactorEnrollment(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &twoFactorEnrollmentResolver{secret: secret, keyURI: keyURI}, nil
+}
+
+func (*schemaResolver) ConfirmTwoFactorEnrollment(ctx context.Context, args *struct {
+	Code string
+}) ([]string, error) {
+	// 🚨 SECURITY: A user can only enroll themselves.
+	a := actor.FromContext(ctx)
+	if !a.IsAuthenticated() {
+		return nil, backend.ErrNotAuthenticated
+	}
+	return backend.ConfirmTwoFactorEnrollment(ctx, a.UID, args.Code)
+}
+
+func (*schemaResolver) RegenerateTwoFactorRecoveryCodes(ctx context.Context, args *struct {
+	Code string
+}) ([]string, error) {
+	// 🚨 SECURITY: A user can only regenerate their own recovery codes, with a valid code.
+	a := actor.FromContext(ctx)
+	if !a.IsAuthenticated() {
+		return nil, backend.ErrNotAuthenticated
+	}
+	if ok, err := backend.CheckTwoFactorCode(ctx, a.UID, args.Code); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, backend.ErrInvalidTwoFactorCode
+	}
+	return backend.RegenerateTwoFactorRecoveryCodes(ctx, a.UID)
+}
+
+func (*schemaResolver) DisableTwoFactorAuthentication(ctx context.Context, args *struct {
+	User graphql.ID
+	Code *string
+}) (*EmptyResponse, error) {
+	userID, err := UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+
+	// 🚨 SECURITY: Users can disable their own two-factor authentication with a valid code, and site
+	// admins can disable it for other users.
+	if a := actor.FromContext(ctx); a.IsAuthenticated() && a.UID == userID {
+		if args.Code == nil {
+			return nil, errors.New("a two-factor authentication code is required")
+		}
+		if ok, err := backend.CheckTwoFactorCode(ctx, userID, *args.Code); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, backend.ErrInvalidTwoFactorCode
+		}
+	} else if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if err := db.Users.DisableTOTP(ctx, userID); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
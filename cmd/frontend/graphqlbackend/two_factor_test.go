@@ -0,0 +1,80 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/totp"
+)
+
+func TestDisableTwoFactorAuthentication(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: actor.FromContext(ctx).UID, SiteAdmin: actor.FromContext(ctx).UID == 1}, nil
+	}
+	db.Mocks.Users.GetTOTP = func(context.Context, int32) (*db.UserTOTP, error) {
+		return &db.UserTOTP{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Enabled: true, RecoveryCodes: 1}, nil
+	}
+	db.Mocks.Users.UseTOTPRecoveryCode = func(_ context.Context, _ int32, hash string) (bool, error) {
+		return hash == totp.HashRecoveryCode("abcde-fghjk"), nil
+	}
+	db.Mocks.Users.AddTOTPAttempt = func(context.Context, int32, time.Duration) (int, error) {
+		return 1, nil
+	}
+	db.Mocks.Users.ResetTOTPAttempts = func(context.Context, int32) error {
+		return nil
+	}
+	var disabled []int32
+	db.Mocks.Users.DisableTOTP = func(_ context.Context, id int32) error {
+		disabled = append(disabled, id)
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		actor   int32
+		user    int32
+		code    *string
+		wantErr string
+	}{
+		{name: "site admin for another user", actor: 1, user: 2},
+		{name: "non-admin for another user", actor: 2, user: 3, wantErr: backend.ErrMustBeSiteAdmin.Error()},
+		{name: "own without code", actor: 2, user: 2, wantErr: "a two-factor authentication code is required"},
+		{name: "own with invalid code", actor: 2, user: 2, code: strptr("invalid"), wantErr: backend.ErrInvalidTwoFactorCode.Error()},
+		{name: "own with recovery code", actor: 2, user: 2, code: strptr("abcde-fghjk")},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			disabled = nil
+			ctx := actor.WithActor(context.Background(), &actor.Actor{UID: test.actor})
+			_, err := (&schemaResolver{}).DisableTwoFactorAuthentication(ctx, &struct {
+				User graphql.ID
+				Code *string
+			}{User: MarshalUserID(test.user), Code: test.code})
+
+			if test.wantErr != "" {
+				if err == nil || err.Error() != test.wantErr {
+					t.Fatalf("err: want %q but got %v", test.wantErr, err)
+				}
+				if len(disabled) > 0 {
+					t.Fatalf("want two-factor authentication not to be disabled, but it was for %v", disabled)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(disabled) != 1 || disabled[0] != test.user {
+				t.Fatalf("disabled: want [%d] but got %v", test.user, disabled)
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/envvar"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/pkg/suspiciousnames"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/session"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
@@ -303,6 +304,11 @@ func (r *schemaResolver) UpdatePassword(ctx context.Context, args *struct {
 	if err := db.Users.UpdatePassword(ctx, user.ID, args.OldPassword, args.NewPassword); err != nil {
 		return nil, err
 	}
+	// 🚨 SECURITY: Revoke the user's other sessions, which may have been signed in by someone who
+	// knew the old password.
+	if err := db.UserSessions.DeleteByUser(ctx, user.ID, session.CurrentKey(ctx)); err != nil {
+		return nil, err
+	}
 	return &EmptyResponse{}, nil
 }
 
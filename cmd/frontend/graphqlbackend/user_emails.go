@@ -111,7 +111,7 @@ func (r *schemaResolver) SetUserEmailVerified(ctx context.Context, args *struct
 }) (*EmptyResponse, error) {
 	// 🚨 SECURITY: Only site admins (NOT users themselves) can manually set email verification
 	// status. Users themselves must go through the normal email verification process.
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkSiteAdminUsersAccess(ctx); err != nil {
 		return nil, err
 	}
 
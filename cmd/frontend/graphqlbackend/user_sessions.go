@@ -0,0 +1,108 @@
+package graphqlbackend
+
+import (
+	"context"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/internal/session"
+)
+
+// userSessionResolver resolves a registered session of a user (see db.UserSessions).
+type userSessionResolver struct {
+	session   *db.UserSession
+	isCurrent bool
+}
+
+func newUserSessionResolver(ctx context.Context, s *db.UserSession) *userSessionResolver {
+	return &userSessionResolver{session: s, isCurrent: s.Key == session.CurrentKey(ctx)}
+}
+
+func userSessionByID(ctx context.Context, id graphql.ID) (*userSessionResolver, error) {
+	sessionID, err := unmarshalUserSessionID(id)
+	if err != nil {
+		return nil, err
+	}
+	s, err := db.UserSessions.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: Only the user and site admins can view the user's sessions.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, s.UserID); err != nil {
+		return nil, err
+	}
+	return newUserSessionResolver(ctx, s), nil
+}
+
+func marshalUserSessionID(id int64) graphql.ID { return relay.MarshalID("UserSession", id) }
+
+func unmarshalUserSessionID(id graphql.ID) (sessionID int64, err error) {
+	err = relay.UnmarshalSpec(id, &sessionID)
+	return
+}
+
+func (r *userSessionResolver) ID() graphql.ID       { return marshalUserSessionID(r.session.ID) }
+func (r *userSessionResolver) UserAgent() string    { return r.session.UserAgent }
+func (r *userSessionResolver) IP() string           { return r.session.IP }
+func (r *userSessionResolver) CreatedAt() DateTime  { return DateTime{Time: r.session.CreatedAt} }
+func (r *userSessionResolver) LastSeenAt() DateTime { return DateTime{Time: r.session.LastSeenAt} }
+func (r *userSessionResolver) IsCurrent() bool      { return r.isCurrent }
+
+func (r *UserResolver) Sessions(ctx context.Context) ([]*userSessionResolver, error) {
+	// 🚨 SECURITY: Only the user and site admins can view the user's sessions.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, r.user.ID); err != nil {
+		return nil, err
+	}
+
+	sessions, err := db.UserSessions.ListByUser(ctx, r.user.ID)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]*userSessionResolver, len(sessions))
+	for i, s := range sessions {
+		resolvers[i] = newUserSessionResolver(ctx, s)
+	}
+	return resolvers, nil
+}
+
+func (*schemaResolver) RevokeUserSession(ctx context.Context, args *struct {
+	Session graphql.ID
+}) (*EmptyResponse, error) {
+	// 🚨 SECURITY: Only the user and site admins can revoke the user's sessions (checked by
+	// userSessionByID).
+	r, err := userSessionByID(ctx, args.Session)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.UserSessions.Delete(ctx, r.session.ID); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
+
+func (*schemaResolver) RevokeAllUserSessions(ctx context.Context, args *struct {
+	User        graphql.ID
+	KeepCurrent bool
+}) (*EmptyResponse, error) {
+	userID, err := UnmarshalUserID(args.User)
+	if err != nil {
+		return nil, err
+	}
+	// 🚨 SECURITY: Only the user and site admins can revoke the user's sessions.
+	if err := backend.CheckSiteAdminOrSameUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	var exceptKey string
+	if args.KeepCurrent {
+		// The current session is only excepted if it is a session of the user (which
+		// DeleteByUser ensures).
+		exceptKey = session.CurrentKey(ctx)
+	}
+	if err := db.UserSessions.DeleteByUser(ctx, userID, exceptKey); err != nil {
+		return nil, err
+	}
+	return &EmptyResponse{}, nil
+}
@@ -0,0 +1,102 @@
+package graphqlbackend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+)
+
+func TestRevokeUserSession(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: actor.FromContext(ctx).UID, SiteAdmin: actor.FromContext(ctx).UID == 1}, nil
+	}
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "u"}, nil
+	}
+	db.Mocks.UserSessions.GetByID = func(_ context.Context, id int64) (*db.UserSession, error) {
+		return &db.UserSession{ID: id, UserID: 2, Key: "k"}, nil
+	}
+	var deleted []int64
+	db.Mocks.UserSessions.Delete = func(_ context.Context, id int64) error {
+		deleted = append(deleted, id)
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		actor   int32
+		wantErr bool
+	}{
+		{name: "own session", actor: 2},
+		{name: "site admin", actor: 1},
+		{name: "other user", actor: 3, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deleted = nil
+			ctx := actor.WithActor(context.Background(), &actor.Actor{UID: test.actor})
+			_, err := (&schemaResolver{}).RevokeUserSession(ctx, &struct{ Session graphql.ID }{Session: marshalUserSessionID(7)})
+			if test.wantErr {
+				if _, ok := err.(*backend.InsufficientAuthorizationError); !ok {
+					t.Fatalf("err: want InsufficientAuthorizationError but got %v", err)
+				}
+				if len(deleted) > 0 {
+					t.Fatalf("want session not to be revoked, but got %v revoked", deleted)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(deleted) != 1 || deleted[0] != 7 {
+				t.Fatalf("deleted: want [7] but got %v", deleted)
+			}
+		})
+	}
+}
+
+func TestRevokeAllUserSessions(t *testing.T) {
+	resetMocks()
+	defer resetMocks()
+
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		return &types.User{ID: actor.FromContext(ctx).UID}, nil
+	}
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: "u"}, nil
+	}
+	var revokedUser int32
+	db.Mocks.UserSessions.DeleteByUser = func(_ context.Context, userID int32, exceptKey string) error {
+		revokedUser = userID
+		return nil
+	}
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 2})
+	if _, err := (&schemaResolver{}).RevokeAllUserSessions(ctx, &struct {
+		User        graphql.ID
+		KeepCurrent bool
+	}{User: MarshalUserID(3)}); err == nil {
+		t.Fatal("want error revoking sessions of another user")
+	}
+	if revokedUser != 0 {
+		t.Fatalf("want no sessions revoked, but got sessions of user %d revoked", revokedUser)
+	}
+
+	if _, err := (&schemaResolver{}).RevokeAllUserSessions(ctx, &struct {
+		User        graphql.ID
+		KeepCurrent bool
+	}{User: MarshalUserID(2), KeepCurrent: true}); err != nil {
+		t.Fatal(err)
+	}
+	if revokedUser != 2 {
+		t.Fatalf("want sessions of user 2 revoked, but got %d", revokedUser)
+	}
+}
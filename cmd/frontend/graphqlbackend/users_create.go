@@ -17,7 +17,7 @@ func (*schemaResolver) CreateUser(ctx context.Context, args *struct {
 	Email    *string
 }) (*createUserResult, error) {
 	// 🚨 SECURITY: Only site admins can create user accounts.
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkSiteAdminUsersAccess(ctx); err != nil {
 		return nil, err
 	}
 
@@ -34,7 +34,7 @@ func (*schemaResolver) RandomizeUserPassword(ctx context.Context, args *struct {
 	User graphql.ID
 }) (*randomizeUserPasswordResult, error) {
 	// 🚨 SECURITY: Only site admins can randomize user passwords.
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkSiteAdminUsersAccess(ctx); err != nil {
 		return nil, err
 	}
 
@@ -46,6 +46,10 @@ func (*schemaResolver) RandomizeUserPassword(ctx context.Context, args *struct {
 	if err := db.Users.RandomizePasswordAndClearPasswordResetRateLimit(ctx, userID); err != nil {
 		return nil, err
 	}
+	// 🚨 SECURITY: Revoke the user's sessions, so that the user must sign in with the new password.
+	if err := db.UserSessions.DeleteByUser(ctx, userID, ""); err != nil {
+		return nil, err
+	}
 
 	return &randomizeUserPasswordResult{userID: userID}, nil
 }
@@ -0,0 +1,7 @@
+package httpapi
+
+import "net/http"
+
+// NewRepoPermissionsUploadHandler returns the handler of bulk uploads of explicit repository
+// permissions. It is set by enterprise frontend.
+var NewRepoPermissionsUploadHandler func() http.Handler
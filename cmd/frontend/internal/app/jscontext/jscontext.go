@@ -30,6 +30,7 @@ var BillingPublishableKey string
 
 type authProviderInfo struct {
 	IsBuiltin         bool   `json:"isBuiltin"`
+	ServiceType       string `json:"serviceType"`
 	DisplayName       string `json:"displayName"`
 	AuthenticationURL string `json:"authenticationURL"`
 }
@@ -121,6 +122,7 @@ func NewJSContextFromRequest(req *http.Request) JSContext {
 		if info != nil {
 			authProviders = append(authProviders, authProviderInfo{
 				IsBuiltin:         p.Config().Builtin != nil,
+				ServiceType:       p.ConfigID().Type,
 				DisplayName:       info.DisplayName,
 				AuthenticationURL: info.AuthenticationURL,
 			})
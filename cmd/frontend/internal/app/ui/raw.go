@@ -17,6 +17,8 @@ import (
 	"github.com/inconshreveable/log15"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
 
@@ -60,6 +62,11 @@ import (
 //
 
 func serveRaw(w http.ResponseWriter, r *http.Request) (err error) {
+	// 🚨 SECURITY: Access tokens with restricted scopes need the repo:read scope to read files.
+	if err := backend.CheckAccessTokenScope(r.Context(), authz.ScopeRepoRead); err != nil {
+		return err
+	}
+
 	var common *Common
 	for {
 		// newCommon provides various repository handling features that we want, so
@@ -25,6 +25,8 @@ type credentials struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// TwoFactorCode is the TOTP code or a recovery code of users with two-factor authentication.
+	TwoFactorCode string `json:"twoFactorCode"`
 }
 
 // HandleSignUp handles submission of the user signup form.
@@ -229,6 +231,16 @@ func HandleSignIn(w http.ResponseWriter, r *http.Request) {
 		httpLogAndError(w, "Authentication failed", http.StatusUnauthorized)
 		return
 	}
+	// 🚨 SECURITY: deactivated users can't sign in
+	if usr.DeactivatedAt != nil {
+		httpLogAndError(w, "Your account was deactivated. Ask a site admin for help.", http.StatusUnauthorized)
+		return
+	}
+	// 🚨 SECURITY: check the second factor of users with two-factor authentication
+	recoveryCodes, ok := checkTwoFactor(w, r, usr, creds.TwoFactorCode)
+	if !ok {
+		return
+	}
 	actor := &actor.Actor{UID: usr.ID}
 
 	// Write the session cookie
@@ -236,6 +248,10 @@ func HandleSignIn(w http.ResponseWriter, r *http.Request) {
 		httpLogAndError(w, "Could not create new user session", http.StatusInternalServerError)
 		return
 	}
+
+	if len(recoveryCodes) > 0 {
+		writeTwoFactorResponse(w, http.StatusOK, &twoFactorResponse{RecoveryCodes: recoveryCodes})
+	}
 }
 
 func httpLogAndError(w http.ResponseWriter, msg string, code int, errArgs ...interface{}) {
@@ -125,6 +125,13 @@ func HandleResetPasswordCode(w http.ResponseWriter, r *http.Request) {
 		httpLogAndError(w, "Password reset failed", http.StatusUnauthorized)
 		return
 	}
+
+	// 🚨 SECURITY: Revoke the user's sessions, which may have been signed in by someone who knew the
+	// old password.
+	if err := db.UserSessions.DeleteByUser(ctx, params.UserID, ""); err != nil {
+		httpLogAndError(w, "Unexpected error", http.StatusInternalServerError, "err", err)
+		return
+	}
 }
 
 func handleNotAuthenticatedCheck(w http.ResponseWriter, r *http.Request) (handled bool) {
@@ -0,0 +1,98 @@
+package userpasswd
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+)
+
+// twoFactorResponse is the JSON body of the sign-in responses of users with two-factor
+// authentication.
+type twoFactorResponse struct {
+	// TwoFactorRequired is set when the user must sign in again with a two-factor authentication code.
+	TwoFactorRequired bool `json:"twoFactorRequired,omitempty"`
+	// EnrollmentRequired is set when the user must add the secret to an authenticator app and sign in
+	// again with a code of it, because site config requires two-factor authentication.
+	EnrollmentRequired bool   `json:"twoFactorEnrollmentRequired,omitempty"`
+	Secret             string `json:"twoFactorSecret,omitempty"`
+	KeyURI             string `json:"twoFactorKeyURI,omitempty"`
+	// RecoveryCodes are the recovery codes of a completed enrollment, which are only shown once.
+	RecoveryCodes []string `json:"recoveryCodes,omitempty"`
+}
+
+// requireTwoFactor reports whether site config requires two-factor authentication for users who sign
+// in with a username and password.
+func requireTwoFactor() bool {
+	pc, _ := getProviderConfig()
+	return pc != nil && pc.RequireTwoFactor
+}
+
+// checkTwoFactor checks the second factor of a user whose password is correct. If the user can't be
+// signed in yet, it writes the response (asking for a code, or starting an enrollment) and returns
+// false. If the sign-in completes an enrollment, it returns the new recovery codes.
+func checkTwoFactor(w http.ResponseWriter, r *http.Request, usr *types.User, code string) (recoveryCodes []string, ok bool) {
+	ctx := r.Context()
+	t, err := db.Users.GetTOTP(ctx, usr.ID)
+	if err != nil {
+		httpLogAndError(w, "Error checking two-factor authentication", http.StatusInternalServerError, "err", err)
+		return nil, false
+	}
+
+	switch {
+	case t.Enabled:
+		if code == "" {
+			writeTwoFactorResponse(w, http.StatusUnauthorized, &twoFactorResponse{TwoFactorRequired: true})
+			return nil, false
+		}
+		// 🚨 SECURITY: check two-factor authentication code
+		valid, err := backend.CheckTwoFactorCode(ctx, usr.ID, code)
+		if err == backend.ErrTwoFactorRateLimit {
+			httpLogAndError(w, "Too many two-factor authentication attempts, try again later", http.StatusTooManyRequests, "userID", usr.ID)
+			return nil, false
+		} else if err != nil {
+			httpLogAndError(w, "Error checking two-factor authentication code", http.StatusInternalServerError, "err", err)
+			return nil, false
+		}
+		if !valid {
+			httpLogAndError(w, "Authentication failed", http.StatusUnauthorized)
+			return nil, false
+		}
+		return nil, true
+
+	case requireTwoFactor():
+		if code == "" || t.Secret == "" {
+			secret, keyURI, err := backend.StartTwoFactorEnrollment(ctx, usr)
+			if err != nil {
+				httpLogAndError(w, "Error starting two-factor authentication enrollment", http.StatusInternalServerError, "err", err)
+				return nil, false
+			}
+			writeTwoFactorResponse(w, http.StatusUnauthorized, &twoFactorResponse{
+				EnrollmentRequired: true,
+				Secret:             secret,
+				KeyURI:             keyURI,
+			})
+			return nil, false
+		}
+		// 🚨 SECURITY: check two-factor authentication code of the enrollment
+		recoveryCodes, err := backend.ConfirmTwoFactorEnrollment(ctx, usr.ID, code)
+		if err == backend.ErrInvalidTwoFactorCode {
+			httpLogAndError(w, "Authentication failed", http.StatusUnauthorized)
+			return nil, false
+		} else if err != nil {
+			httpLogAndError(w, "Error confirming two-factor authentication enrollment", http.StatusInternalServerError, "err", err)
+			return nil, false
+		}
+		return recoveryCodes, true
+	}
+
+	return nil, true
+}
+
+func writeTwoFactorResponse(w http.ResponseWriter, code int, resp *twoFactorResponse) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(resp)
+}
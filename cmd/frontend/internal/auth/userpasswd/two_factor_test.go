@@ -0,0 +1,141 @@
+package userpasswd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/totp"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func Test_checkTwoFactor(t *testing.T) {
+	defer conf.Mock(nil)
+	defer func() { db.Mocks.Users = db.MockUsers{} }()
+
+	var secretSet string
+	db.Mocks.Users.SetTOTPSecret = func(_ context.Context, _ int32, secret string) error {
+		secretSet = secret
+		return nil
+	}
+	db.Mocks.Users.UseTOTPRecoveryCode = func(_ context.Context, _ int32, hash string) (bool, error) {
+		return hash == totp.HashRecoveryCode("abcde-fghjk"), nil
+	}
+	var attempts int
+	db.Mocks.Users.AddTOTPAttempt = func(context.Context, int32, time.Duration) (int, error) {
+		attempts++
+		return attempts, nil
+	}
+	db.Mocks.Users.ResetTOTPAttempts = func(context.Context, int32) error {
+		attempts = 0
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		require    bool
+		totp       *db.UserTOTP
+		attempts   int
+		code       string
+		wantOK     bool
+		wantStatus int
+		wantResp   *twoFactorResponse
+	}{
+		{
+			name:   "not enabled and not required",
+			totp:   &db.UserTOTP{},
+			wantOK: true,
+		},
+		{
+			name:       "enabled without code",
+			totp:       &db.UserTOTP{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Enabled: true},
+			wantStatus: http.StatusUnauthorized,
+			wantResp:   &twoFactorResponse{TwoFactorRequired: true},
+		},
+		{
+			name:       "enabled with invalid code",
+			totp:       &db.UserTOTP{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Enabled: true},
+			code:       "invalid",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "enabled with too many attempts",
+			totp:       &db.UserTOTP{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Enabled: true},
+			attempts:   5,
+			code:       "abcde-fghjk",
+			wantStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:   "enabled with recovery code",
+			totp:   &db.UserTOTP{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Enabled: true},
+			code:   "abcde-fghjk",
+			wantOK: true,
+		},
+		{
+			name:       "required and not enrolled",
+			require:    true,
+			totp:       &db.UserTOTP{},
+			code:       "123456",
+			wantStatus: http.StatusUnauthorized,
+			wantResp:   &twoFactorResponse{EnrollmentRequired: true},
+		},
+		{
+			name:       "required with enrollment in progress and invalid code",
+			require:    true,
+			totp:       &db.UserTOTP{Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"},
+			code:       "invalid",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{AuthProviders: []schema.AuthProviders{
+				{Builtin: &schema.BuiltinAuthProvider{Type: "builtin", RequireTwoFactor: test.require}},
+			}}})
+			db.Mocks.Users.GetTOTP = func(context.Context, int32) (*db.UserTOTP, error) {
+				return test.totp, nil
+			}
+			secretSet = ""
+			attempts = test.attempts
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("POST", "/-/sign-in", nil)
+			_, ok := checkTwoFactor(w, r, &types.User{ID: 1, Username: "alice"}, test.code)
+			if ok != test.wantOK {
+				t.Fatalf("ok: want %v but got %v", test.wantOK, ok)
+			}
+			if ok {
+				return
+			}
+			if w.Code != test.wantStatus {
+				t.Fatalf("status: want %d but got %d", test.wantStatus, w.Code)
+			}
+			if test.wantResp == nil {
+				return
+			}
+
+			var resp twoFactorResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if test.wantResp.EnrollmentRequired {
+				if resp.Secret == "" || resp.Secret != secretSet {
+					t.Errorf("secret: want the stored secret %q but got %q", secretSet, resp.Secret)
+				}
+				if resp.KeyURI == "" {
+					t.Error("expected a key URI")
+				}
+				resp.Secret, resp.KeyURI = "", ""
+			}
+			if resp.TwoFactorRequired != test.wantResp.TwoFactorRequired || resp.EnrollmentRequired != test.wantResp.EnrollmentRequired {
+				t.Errorf("response: want %+v but got %+v", test.wantResp, resp)
+			}
+		})
+	}
+}
@@ -113,7 +113,7 @@ func InitDB() error {
 }
 
 // Main is the main entrypoint for the frontend server program.
-func Main(githubWebhook, bitbucketServerWebhook http.Handler) error {
+func Main(githubWebhook, gitlabWebhook, bitbucketServerWebhook, bitbucketCloudWebhook http.Handler) error {
 	log.SetFlags(0)
 	log.SetPrefix("")
 
@@ -236,7 +236,7 @@ func Main(githubWebhook, bitbucketServerWebhook http.Handler) error {
 	}
 
 	// Create the external HTTP handler.
-	externalHandler, err := newExternalHTTPHandler(schema, githubWebhook, bitbucketServerWebhook, lsifServerProxy)
+	externalHandler, err := newExternalHTTPHandler(schema, githubWebhook, gitlabWebhook, bitbucketServerWebhook, bitbucketCloudWebhook, lsifServerProxy)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package httpapi
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/inconshreveable/log15"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
@@ -36,7 +37,9 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 			if err != nil {
 				if authz.IsUnrecognizedScheme(err) {
 					// Ignore Authorization headers that we don't handle.
-					log15.Warn("Ignoring unrecognized Authorization header.", "err", err, "value", headerValue)
+					// 🚨 SECURITY: Don't log the header value, which may be a credential (such as
+					// the bearer tokens of SCIM clients).
+					log15.Warn("Ignoring unrecognized Authorization header.", "err", err, "scheme", strings.SplitN(headerValue, " ", 2)[0])
 					next.ServeHTTP(w, r)
 					return
 				}
@@ -61,13 +64,13 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 			//
 			// 🚨 SECURITY: It's important we check for the correct scopes to know what this token
 			// is allowed to do.
-			var requiredScope string
+			var requiredScopes []string
 			if sudoUser == "" {
-				requiredScope = authz.ScopeUserAll
+				requiredScopes = append([]string{authz.ScopeUserAll}, authz.RestrictedScopes...)
 			} else {
-				requiredScope = authz.ScopeSiteAdminSudo
+				requiredScopes = []string{authz.ScopeSiteAdminSudo}
 			}
-			subjectUserID, err := db.AccessTokens.Lookup(r.Context(), token, requiredScope)
+			subjectUserID, scopes, err := db.AccessTokens.Lookup(r.Context(), token, requiredScopes)
 			if err != nil {
 				log15.Error("Invalid access token.", "token", token, "err", err)
 				http.Error(w, "Invalid access token.", http.StatusUnauthorized)
@@ -105,9 +108,39 @@ func AccessTokenAuthMiddleware(next http.Handler) http.Handler {
 				log15.Debug("HTTP request used sudo token.", "requestURI", r.URL.RequestURI(), "tokenSubjectUserID", subjectUserID, "actorUserID", actorUserID, "actorUsername", user.Username)
 			}
 
-			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID}))
+			// 🚨 SECURITY: Tokens without the "user:all" scope restrict the actor to the actions
+			// granted by their scopes.
+			r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: actorUserID, Scopes: actorScopes(scopes)}))
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+// actorScopes returns the scopes that restrict the actor authenticated with an access token with
+// the given scopes, or nil if the actor is not restricted.
+func actorScopes(tokenScopes []string) []string {
+	for _, scope := range tokenScopes {
+		if scope == authz.ScopeUserAll {
+			return nil
+		}
+	}
+	if tokenScopes == nil {
+		return []string{}
+	}
+	return tokenScopes
+}
+
+// requireAccessTokenScope returns a handler that responds with an error to requests of actors that
+// are restricted by the scopes of their access token, unless the access token has the scope. The
+// handler h is called with a context returned by backend.WithAccessTokenScope.
+func requireAccessTokenScope(scope string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, err := backend.WithAccessTokenScope(r.Context(), scope)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
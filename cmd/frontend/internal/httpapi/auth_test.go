@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"reflect"
 	"testing"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
@@ -69,9 +70,9 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", "token badbad")
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
-			return 0, errors.New("x")
+			return 0, nil, errors.New("x")
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusUnauthorized, "Invalid access token.\n")
@@ -85,15 +86,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			req, _ := http.NewRequest("GET", "/", nil)
 			req.Header.Set("Authorization", headerValue)
 			var calledAccessTokensLookup bool
-			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 				calledAccessTokensLookup = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
+				if want := append([]string{authz.ScopeUserAll}, authz.RestrictedScopes...); !reflect.DeepEqual(requiredScopes, want) {
+					t.Errorf("got %q, want %q", requiredScopes, want)
 				}
-				return 123, nil
+				return 123, []string{authz.ScopeUserAll}, nil
 			}
 			defer func() { db.Mocks = db.MockStores{} }()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
@@ -103,21 +104,51 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		})
 	}
 
+	// 🚨 SECURITY: Test that tokens without the "user:all" scope restrict the actor to their scopes.
+	t.Run("valid non-sudo token with restricted scopes", func(t *testing.T) {
+		for _, tc := range []struct {
+			tokenScopes []string
+			wantScopes  []string
+		}{
+			{tokenScopes: []string{authz.ScopeUserAll}, wantScopes: nil},
+			{tokenScopes: []string{authz.ScopeUserAll, authz.ScopeSearchRead}, wantScopes: nil},
+			{tokenScopes: []string{authz.ScopeSearchRead, authz.ScopeRepoRead}, wantScopes: []string{authz.ScopeSearchRead, authz.ScopeRepoRead}},
+		} {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("Authorization", "token abcdef")
+			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
+				return 123, tc.tokenScopes, nil
+			}
+			var gotActor *actor.Actor
+			AccessTokenAuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotActor = actor.FromContext(r.Context())
+			})).ServeHTTP(httptest.NewRecorder(), req)
+			db.Mocks = db.MockStores{}
+
+			if gotActor == nil || gotActor.UID != 123 {
+				t.Fatalf("token scopes %q: got actor %v, want UID 123", tc.tokenScopes, gotActor)
+			}
+			if !reflect.DeepEqual(gotActor.Scopes, tc.wantScopes) {
+				t.Errorf("token scopes %q: got actor scopes %q, want %q", tc.tokenScopes, gotActor.Scopes, tc.wantScopes)
+			}
+		}
+	})
+
 	// Test that an access token overwrites the actor set by a prior auth middleware.
 	t.Run("actor present, valid non-sudo token", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", "token abcdef")
 		req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeUserAll; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := append([]string{authz.ScopeUserAll}, authz.RestrictedScopes...); !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return 123, []string{authz.ScopeUserAll}, nil
 		}
 		defer func() { db.Mocks = db.MockStores{} }()
 		checkHTTPResponse(t, req, http.StatusOK, "user 123")
@@ -143,15 +174,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 			}
 			req = req.WithContext(actor.WithActor(context.Background(), &actor.Actor{UID: 456}))
 			var calledAccessTokensLookup bool
-			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+			db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 				calledAccessTokensLookup = true
 				if want := "abcdef"; tokenHexEncoded != want {
 					t.Errorf("got %q, want %q", tokenHexEncoded, want)
 				}
-				if want := authz.ScopeUserAll; requiredScope != want {
-					t.Errorf("got %q, want %q", requiredScope, want)
+				if want := append([]string{authz.ScopeUserAll}, authz.RestrictedScopes...); !reflect.DeepEqual(requiredScopes, want) {
+					t.Errorf("got %q, want %q", requiredScopes, want)
 				}
-				return 123, nil
+				return 123, []string{authz.ScopeUserAll}, nil
 			}
 			defer func() { db.Mocks = db.MockStores{} }()
 			checkHTTPResponse(t, req, http.StatusOK, "user 123")
@@ -165,15 +196,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := []string{authz.ScopeSiteAdminSudo}; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return 123, []string{authz.ScopeUserAll}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -210,15 +241,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="alice"`)
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := []string{authz.ScopeSiteAdminSudo}; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return 123, []string{authz.ScopeUserAll}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -242,15 +273,15 @@ func TestAccessTokenAuthMiddleware(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/", nil)
 		req.Header.Set("Authorization", `token-sudo token="abcdef",user="doesntexist"`)
 		var calledAccessTokensLookup bool
-		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded, requiredScope string) (subjectUserID int32, err error) {
+		db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (subjectUserID int32, scopes []string, err error) {
 			calledAccessTokensLookup = true
 			if want := "abcdef"; tokenHexEncoded != want {
 				t.Errorf("got %q, want %q", tokenHexEncoded, want)
 			}
-			if want := authz.ScopeSiteAdminSudo; requiredScope != want {
-				t.Errorf("got %q, want %q", requiredScope, want)
+			if want := []string{authz.ScopeSiteAdminSudo}; !reflect.DeepEqual(requiredScopes, want) {
+				t.Errorf("got %q, want %q", requiredScopes, want)
 			}
-			return 123, nil
+			return 123, []string{authz.ScopeUserAll}, nil
 		}
 		var calledUsersGetByID bool
 		db.Mocks.Users.GetByID = func(ctx context.Context, userID int32) (*types.User, error) {
@@ -0,0 +1,147 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// filter is a SCIM filter (RFC 7644 section 3.4.2.2) consisting of a single attribute
+// expression, such as `userName eq "alice"` or `emails pr`. Logical operators and grouping are
+// not supported, since identity providers only use single comparisons to look up resources.
+type filter struct {
+	// attr is the attribute path, such as "userName" or "emails.value".
+	attr string
+	// op is the lowercase comparison operator.
+	op string
+	// value is the comparison value, which is nil for the "pr" operator.
+	value interface{}
+}
+
+var filterOps = map[string]bool{
+	"eq": true, "ne": true, "co": true, "sw": true, "ew": true,
+	"gt": true, "ge": true, "lt": true, "le": true, "pr": true,
+}
+
+func parseFilter(s string) (*filter, error) {
+	invalid := func(format string, args ...interface{}) error {
+		return badRequest("invalidFilter", "invalid filter %q: %s", s, fmt.Sprintf(format, args...))
+	}
+
+	s = strings.TrimSpace(s)
+	i := strings.IndexByte(s, ' ')
+	if i == -1 {
+		return nil, invalid("expected an attribute and an operator")
+	}
+	f := &filter{attr: s[:i]}
+	rest := strings.TrimSpace(s[i+1:])
+
+	if i = strings.IndexByte(rest, ' '); i == -1 {
+		f.op, rest = strings.ToLower(rest), ""
+	} else {
+		f.op, rest = strings.ToLower(rest[:i]), strings.TrimSpace(rest[i+1:])
+	}
+	if !filterOps[f.op] {
+		return nil, invalid("unsupported operator %q", f.op)
+	}
+
+	if f.op == "pr" {
+		if rest != "" {
+			return nil, invalid("unexpected %q after the pr operator", rest)
+		}
+		return f, nil
+	}
+
+	if rest == "" {
+		return nil, invalid("expected a value")
+	}
+	dec := json.NewDecoder(strings.NewReader(rest))
+	if err := dec.Decode(&f.value); err != nil {
+		return nil, invalid("invalid value: %s", err)
+	}
+	if dec.More() {
+		return nil, invalid("logical operators are not supported")
+	}
+	return f, nil
+}
+
+// stringValue returns the comparison value as a string, which is what all lookups by filter use.
+func (f *filter) stringValue() (string, bool) {
+	s, ok := f.value.(string)
+	return s, ok
+}
+
+// is reports whether the filter is an "eq" comparison of one of the given attributes (which
+// are compared case-insensitively, like all attribute names) with a string.
+func (f *filter) is(attrs ...string) (string, bool) {
+	if f.op != "eq" {
+		return "", false
+	}
+	for _, attr := range attrs {
+		if strings.EqualFold(f.attr, attr) {
+			return f.stringValue()
+		}
+	}
+	return "", false
+}
+
+// match reports whether the value of the filter's attribute in the generic JSON representation
+// of a resource (or a value of a multi-valued attribute) matches the filter. String comparisons
+// are case-insensitive.
+func (f *filter) match(v map[string]interface{}) bool {
+	attr := v[key(v, f.attr)]
+	if f.op == "pr" {
+		return attr != nil && attr != ""
+	}
+
+	switch want := f.value.(type) {
+	case string:
+		have, ok := attr.(string)
+		if !ok {
+			return false
+		}
+		have, want = strings.ToLower(have), strings.ToLower(want)
+		switch f.op {
+		case "eq":
+			return have == want
+		case "ne":
+			return have != want
+		case "co":
+			return strings.Contains(have, want)
+		case "sw":
+			return strings.HasPrefix(have, want)
+		case "ew":
+			return strings.HasSuffix(have, want)
+		case "gt":
+			return have > want
+		case "ge":
+			return have >= want
+		case "lt":
+			return have < want
+		case "le":
+			return have <= want
+		}
+	default:
+		switch f.op {
+		case "eq":
+			return attr == want
+		case "ne":
+			return attr != want
+		}
+	}
+	return false
+}
+
+// key returns the key of the attribute with the given name in the generic JSON representation
+// of a resource. Attribute names are case-insensitive.
+func key(m map[string]interface{}, name string) string {
+	if _, ok := m[name]; ok {
+		return name
+	}
+	for k := range m {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
@@ -0,0 +1,71 @@
+package scim
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	for _, tc := range []struct {
+		in      string
+		want    *filter
+		wantErr bool
+	}{
+		{in: `userName eq "alice"`, want: &filter{attr: "userName", op: "eq", value: "alice"}},
+		{in: `  emails.value EQ "a@example.com" `, want: &filter{attr: "emails.value", op: "eq", value: "a@example.com"}},
+		{in: `displayName eq "Eng \"core\""`, want: &filter{attr: "displayName", op: "eq", value: `Eng "core"`}},
+		{in: `active eq true`, want: &filter{attr: "active", op: "eq", value: true}},
+		{in: `title pr`, want: &filter{attr: "title", op: "pr"}},
+		{in: `userName`, wantErr: true},
+		{in: `userName xx "alice"`, wantErr: true},
+		{in: `userName eq`, wantErr: true},
+		{in: `userName eq alice`, wantErr: true},
+		{in: `title pr "x"`, wantErr: true},
+		{in: `userName eq "alice" and active eq true`, wantErr: true},
+	} {
+		t.Run(tc.in, func(t *testing.T) {
+			f, err := parseFilter(tc.in)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, have %+v", f)
+				}
+				if e, ok := err.(*scimError); !ok || e.ScimType != "invalidFilter" {
+					t.Errorf("unexpected error %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(f, tc.want) {
+				t.Errorf("have %+v, want %+v", f, tc.want)
+			}
+		})
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	v := map[string]interface{}{"Value": "Alice@Example.com", "type": "work", "primary": true}
+	for _, tc := range []struct {
+		filter string
+		want   bool
+	}{
+		{`value eq "alice@example.com"`, true},
+		{`value ne "alice@example.com"`, false},
+		{`value co "example"`, true},
+		{`value sw "alice"`, true},
+		{`value ew ".org"`, false},
+		{`type eq "home"`, false},
+		{`primary eq true`, true},
+		{`display pr`, false},
+		{`type pr`, true},
+	} {
+		f, err := parseFilter(tc.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have := f.match(v); have != tc.want {
+			t.Errorf("%s: have %v, want %v", tc.filter, have, tc.want)
+		}
+	}
+}
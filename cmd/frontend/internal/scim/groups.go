@@ -0,0 +1,348 @@
+package scim
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// groupResource is a SCIM group (RFC 7643 section 4.2), which is a Sourcegraph organization.
+type groupResource struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []multiValue `json:"members"`
+	Meta        *meta        `json:"meta,omitempty"`
+}
+
+func orgDisplayName(org *types.Org) string {
+	if org.DisplayName != nil && *org.DisplayName != "" {
+		return *org.DisplayName
+	}
+	return org.Name
+}
+
+func toGroupResource(ctx context.Context, org *types.Org) (*groupResource, error) {
+	g := &groupResource{
+		Schemas:     []string{schemaGroup},
+		ID:          strconv.Itoa(int(org.ID)),
+		DisplayName: orgDisplayName(org),
+		Members:     []multiValue{},
+		Meta: &meta{
+			ResourceType: "Group",
+			Created:      org.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: org.UpdatedAt.UTC().Format(time.RFC3339),
+			Location:     location("Groups", org.ID),
+		},
+	}
+
+	members, err := db.OrgMembers.GetByOrgID(ctx, org.ID)
+	if err != nil || len(members) == 0 {
+		return g, err
+	}
+	userIDs := make([]int32, 0, len(members))
+	for _, m := range members {
+		userIDs = append(userIDs, m.UserID)
+	}
+	users, err := db.Users.List(ctx, &db.UsersListOptions{UserIDs: userIDs})
+	if err != nil {
+		return nil, err
+	}
+	for _, user := range users {
+		g.Members = append(g.Members, multiValue{
+			Value:   strconv.Itoa(int(user.ID)),
+			Display: user.Username,
+			Type:    "User",
+			Ref:     location("Users", user.ID),
+		})
+	}
+	return g, nil
+}
+
+func serveListGroups(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	p, err := parseListParams(r)
+	if err != nil {
+		return err
+	}
+
+	var orgs []*types.Org
+	var total int
+	if p.filter == nil {
+		if orgs, err = db.Orgs.List(ctx, &db.OrgsListOptions{LimitOffset: p.limitOffset()}); err != nil {
+			return err
+		}
+		if total, err = db.Orgs.Count(ctx, db.OrgsListOptions{}); err != nil {
+			return err
+		}
+	} else {
+		if orgs, err = findOrgs(ctx, p.filter); err != nil {
+			return err
+		}
+		total = len(orgs)
+		if p.startIndex > len(orgs) {
+			orgs = nil
+		} else {
+			orgs = orgs[p.startIndex-1:]
+		}
+		if len(orgs) > p.count {
+			orgs = orgs[:p.count]
+		}
+	}
+
+	resources := make([]*groupResource, 0, len(orgs))
+	for _, org := range orgs {
+		g, err := toGroupResource(ctx, org)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, g)
+	}
+	writeJSON(w, http.StatusOK, listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   p.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+	return nil
+}
+
+// findOrgs returns the organizations matching the filter. Only the lookups identity providers
+// use to find existing groups are supported.
+func findOrgs(ctx context.Context, f *filter) ([]*types.Org, error) {
+	if v, ok := f.is("id"); ok {
+		id, err := strconv.ParseInt(v, 10, 32)
+		if err != nil {
+			return nil, nil
+		}
+		org, err := db.Orgs.GetByID(ctx, int32(id))
+		if errcode.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		return []*types.Org{org}, nil
+	}
+
+	v, ok := f.is("displayName")
+	if !ok {
+		return nil, badRequest("invalidFilter", "unsupported filter: only eq comparisons of displayName and id are supported")
+	}
+
+	// Display names can be changed, so they are compared with those of all organizations (of
+	// which there are few).
+	orgs, err := db.Orgs.List(ctx, &db.OrgsListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var matches []*types.Org
+	for _, org := range orgs {
+		if strings.EqualFold(orgDisplayName(org), v) {
+			matches = append(matches, org)
+		}
+	}
+	return matches, nil
+}
+
+func serveGetGroup(w http.ResponseWriter, r *http.Request) error {
+	id, err := resourceID(r)
+	if err != nil {
+		return err
+	}
+	org, err := db.Orgs.GetByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	g, err := toGroupResource(r.Context(), org)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, g)
+	return nil
+}
+
+func serveCreateGroup(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	var g groupResource
+	if err := readJSON(r, &g); err != nil {
+		return err
+	}
+	name, err := auth.NormalizeUsername(g.DisplayName)
+	if err != nil {
+		return badRequest("invalidValue", "invalid displayName: %s", err)
+	}
+	userIDs, err := memberIDs(ctx, g.Members)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Orgs.GetByName(ctx, name); err == nil {
+		return &scimError{Status: http.StatusConflict, ScimType: "uniqueness", Detail: "an organization named " + name + " already exists"}
+	} else if !errcode.IsNotFound(err) {
+		return err
+	}
+
+	displayName := g.DisplayName
+	org, err := db.Orgs.Create(ctx, name, &displayName)
+	if err != nil {
+		return err
+	}
+	if err := setMembers(ctx, org.ID, userIDs); err != nil {
+		return err
+	}
+
+	res, err := toGroupResource(ctx, org)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", res.Meta.Location)
+	writeJSON(w, http.StatusCreated, res)
+	return nil
+}
+
+func serveReplaceGroup(w http.ResponseWriter, r *http.Request) error {
+	id, err := resourceID(r)
+	if err != nil {
+		return err
+	}
+	org, err := db.Orgs.GetByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	var g groupResource
+	if err := readJSON(r, &g); err != nil {
+		return err
+	}
+	return replaceGroup(w, r, org, &g)
+}
+
+func servePatchGroup(w http.ResponseWriter, r *http.Request) error {
+	id, err := resourceID(r)
+	if err != nil {
+		return err
+	}
+	org, err := db.Orgs.GetByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	g, err := toGroupResource(r.Context(), org)
+	if err != nil {
+		return err
+	}
+	if err := applyPatchRequest(r, g); err != nil {
+		return err
+	}
+	return replaceGroup(w, r, org, g)
+}
+
+// replaceGroup updates the organization to match the SCIM group. The name of the organization
+// doesn't change, since it is part of the URLs of the organization's resources.
+func replaceGroup(w http.ResponseWriter, r *http.Request, org *types.Org, g *groupResource) error {
+	ctx := r.Context()
+
+	userIDs, err := memberIDs(ctx, g.Members)
+	if err != nil {
+		return err
+	}
+
+	if g.DisplayName != "" && g.DisplayName != orgDisplayName(org) {
+		displayName := g.DisplayName
+		if org, err = db.Orgs.Update(ctx, org.ID, &displayName); err != nil {
+			return err
+		}
+	}
+	if err := setMembers(ctx, org.ID, userIDs); err != nil {
+		return err
+	}
+
+	res, err := toGroupResource(ctx, org)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, res)
+	return nil
+}
+
+// memberIDs returns the IDs of the users who are the members of a group.
+func memberIDs(ctx context.Context, members []multiValue) ([]int32, error) {
+	userIDs := make([]int32, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 32)
+		if err != nil {
+			return nil, badRequest("invalidValue", "invalid member %q", m.Value)
+		}
+		userIDs = append(userIDs, int32(id))
+	}
+	if len(userIDs) == 0 {
+		return userIDs, nil
+	}
+
+	users, err := db.Users.List(ctx, &db.UsersListOptions{UserIDs: userIDs})
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[int32]bool, len(users))
+	for _, user := range users {
+		found[user.ID] = true
+	}
+	for _, id := range userIDs {
+		if !found[id] {
+			return nil, badRequest("invalidValue", "member %d is not a user", id)
+		}
+	}
+	return userIDs, nil
+}
+
+// setMembers adds and removes members of the organization so that the given users are its only
+// members.
+func setMembers(ctx context.Context, orgID int32, userIDs []int32) error {
+	members, err := db.OrgMembers.GetByOrgID(ctx, orgID)
+	if err != nil {
+		return err
+	}
+
+	want := make(map[int32]bool, len(userIDs))
+	for _, id := range userIDs {
+		want[id] = true
+	}
+	have := make(map[int32]bool, len(members))
+	for _, m := range members {
+		have[m.UserID] = true
+		if !want[m.UserID] {
+			if err := db.OrgMembers.Remove(ctx, orgID, m.UserID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, id := range userIDs {
+		if have[id] {
+			continue
+		}
+		have[id] = true
+		if _, err := db.OrgMembers.Create(ctx, orgID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func serveDeleteGroup(w http.ResponseWriter, r *http.Request) error {
+	id, err := resourceID(r)
+	if err != nil {
+		return err
+	}
+	if err := db.Orgs.Delete(r.Context(), id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -0,0 +1,257 @@
+package scim
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// patchOp is an operation of a PATCH request (RFC 7644 section 3.5.2).
+type patchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// patchPath is the target of a patch operation, such as `name.givenName` or
+// `members[value eq "1"]`.
+type patchPath struct {
+	attr string
+	// filter (if any) selects values of a multi-valued attribute.
+	filter *filter
+	// sub (if any) is the sub-attribute of a complex attribute or of the selected values.
+	sub string
+}
+
+func parsePatchPath(s string) (*patchPath, error) {
+	// Strip the schema URN prefix of fully qualified paths, such as
+	// "urn:ietf:params:scim:schemas:core:2.0:User:userName".
+	if strings.HasPrefix(strings.ToLower(s), "urn:") {
+		if i := strings.LastIndexByte(strings.SplitN(s, "[", 2)[0], ':'); i != -1 {
+			s = s[i+1:]
+		}
+	}
+
+	p := &patchPath{attr: s}
+	if i := strings.IndexByte(s, '['); i != -1 {
+		j := strings.LastIndexByte(s, ']')
+		if j < i {
+			return nil, badRequest("invalidPath", "invalid path %q", s)
+		}
+		f, err := parseFilter(s[i+1 : j])
+		if err != nil {
+			return nil, badRequest("invalidPath", "invalid path %q: %s", s, err.(*scimError).Detail)
+		}
+		p.attr, p.filter = s[:i], f
+		s = s[j+1:]
+		if s != "" {
+			if !strings.HasPrefix(s, ".") {
+				return nil, badRequest("invalidPath", "invalid path %q", p.attr)
+			}
+			p.sub = s[1:]
+		}
+	} else if i := strings.IndexByte(s, '.'); i != -1 {
+		p.attr, p.sub = s[:i], s[i+1:]
+	}
+
+	if p.attr == "" {
+		return nil, badRequest("invalidPath", "invalid path %q", s)
+	}
+	return p, nil
+}
+
+// applyPatch applies the patch operation to the generic JSON representation of a resource.
+func applyPatch(m map[string]interface{}, op patchOp) error {
+	kind := strings.ToLower(op.Op)
+	if kind != "add" && kind != "replace" && kind != "remove" {
+		return badRequest("invalidSyntax", "unsupported patch operation %q", op.Op)
+	}
+
+	var value interface{}
+	if len(op.Value) > 0 {
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return badRequest("invalidValue", "invalid value of %s operation: %s", op.Op, err)
+		}
+	}
+
+	if op.Path == "" {
+		if kind == "remove" {
+			return badRequest("noTarget", "remove operation without a path")
+		}
+		// Without a path, the value is an object with the attributes to add or replace. Their
+		// names may be paths themselves, such as "name.givenName".
+		attrs, ok := value.(map[string]interface{})
+		if !ok {
+			return badRequest("invalidValue", "value of %s operation without a path must be an object", op.Op)
+		}
+		for name, v := range attrs {
+			p, err := parsePatchPath(name)
+			if err != nil {
+				return err
+			}
+			if err := apply(m, kind, p, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	p, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	return apply(m, kind, p, value)
+}
+
+func apply(m map[string]interface{}, kind string, p *patchPath, value interface{}) error {
+	k := key(m, p.attr)
+
+	if p.filter != nil {
+		return applyFiltered(m, k, kind, p, value)
+	}
+
+	if p.sub != "" {
+		obj, _ := m[k].(map[string]interface{})
+		if obj == nil {
+			if kind == "remove" {
+				return nil
+			}
+			obj = map[string]interface{}{}
+			m[k] = obj
+		}
+		if kind == "remove" {
+			delete(obj, key(obj, p.sub))
+		} else {
+			obj[key(obj, p.sub)] = value
+		}
+		return nil
+	}
+
+	switch kind {
+	case "remove":
+		// Some clients remove values of a multi-valued attribute by listing them in the value
+		// rather than with a value filter in the path.
+		if values, ok := value.([]interface{}); ok {
+			if existing, ok := m[k].([]interface{}); ok {
+				m[k] = removeValues(existing, values)
+				return nil
+			}
+		}
+		delete(m, k)
+
+	case "add":
+		if existing, ok := m[k].([]interface{}); ok {
+			if values, ok := value.([]interface{}); ok {
+				m[k] = append(existing, values...)
+				return nil
+			}
+			if value != nil {
+				m[k] = append(existing, value)
+				return nil
+			}
+		}
+		m[k] = merge(m[k], value)
+
+	case "replace":
+		m[k] = merge(m[k], value)
+	}
+	return nil
+}
+
+// applyFiltered applies the patch operation to the values of the multi-valued attribute k
+// selected by the path's filter.
+func applyFiltered(m map[string]interface{}, k, kind string, p *patchPath, value interface{}) error {
+	existing, _ := m[k].([]interface{})
+	values := make([]interface{}, 0, len(existing))
+	matched := false
+	for _, v := range existing {
+		obj, ok := v.(map[string]interface{})
+		if !ok || !p.filter.match(obj) {
+			values = append(values, v)
+			continue
+		}
+
+		matched = true
+		switch {
+		case kind == "remove" && p.sub == "":
+			continue
+		case kind == "remove":
+			delete(obj, key(obj, p.sub))
+		case p.sub == "":
+			v = merge(obj, value)
+		default:
+			obj[key(obj, p.sub)] = value
+		}
+		values = append(values, v)
+	}
+
+	if !matched && kind != "remove" {
+		// Add a value matching the filter, such as a work email for `emails[type eq "work"].value`.
+		if p.filter.op != "eq" {
+			return badRequest("noTarget", "no values of %s match the filter", p.attr)
+		}
+		obj := map[string]interface{}{p.filter.attr: p.filter.value}
+		if p.sub != "" {
+			obj[p.sub] = value
+		} else if attrs, ok := value.(map[string]interface{}); ok {
+			merge(obj, attrs)
+		} else if value != nil {
+			return badRequest("invalidValue", "value of %s must be an object", p.attr)
+		}
+		values = append(values, obj)
+	}
+
+	m[k] = values
+	return nil
+}
+
+// merge returns the result of replacing the existing value with the new value. If both are
+// complex values, the new sub-attributes replace the existing ones.
+func merge(existing, value interface{}) interface{} {
+	obj, ok := existing.(map[string]interface{})
+	if !ok {
+		return value
+	}
+	attrs, ok := value.(map[string]interface{})
+	if !ok {
+		if value == nil {
+			return obj
+		}
+		return value
+	}
+	for name, v := range attrs {
+		obj[key(obj, name)] = v
+	}
+	return obj
+}
+
+// removeValues returns the values of a multi-valued attribute except those whose "value"
+// sub-attribute equals that of one of the values to remove.
+func removeValues(existing, remove []interface{}) []interface{} {
+	// valueOf returns the value of v, or nil if it isn't a simple value.
+	valueOf := func(v interface{}) interface{} {
+		if obj, ok := v.(map[string]interface{}); ok {
+			v = obj[key(obj, "value")]
+		}
+		switch v.(type) {
+		case string, float64, bool:
+			return v
+		}
+		return nil
+	}
+
+	removed := make(map[interface{}]bool, len(remove))
+	for _, v := range remove {
+		if rv := valueOf(v); rv != nil {
+			removed[rv] = true
+		}
+	}
+
+	values := make([]interface{}, 0, len(existing))
+	for _, v := range existing {
+		if ev := valueOf(v); ev != nil && removed[ev] {
+			continue
+		}
+		values = append(values, v)
+	}
+	return values
+}
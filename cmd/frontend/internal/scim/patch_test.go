@@ -0,0 +1,180 @@
+package scim
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestApplyPatch(t *testing.T) {
+	const user = `{
+		"userName": "alice",
+		"name": {"givenName": "Alice", "familyName": "Zhao"},
+		"emails": [{"value": "alice@example.com", "type": "work", "primary": true}],
+		"active": true
+	}`
+	const group = `{
+		"displayName": "Engineering",
+		"members": [{"value": "1", "display": "alice"}, {"value": "2", "display": "bob"}]
+	}`
+
+	for _, tc := range []struct {
+		name     string
+		resource string
+		ops      string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "replace without path (Okta deactivation)",
+			resource: user,
+			ops:      `[{"op": "replace", "value": {"active": false}}]`,
+			want: `{
+				"userName": "alice",
+				"name": {"givenName": "Alice", "familyName": "Zhao"},
+				"emails": [{"value": "alice@example.com", "type": "work", "primary": true}],
+				"active": false
+			}`,
+		},
+		{
+			name:     "replace with paths (Azure AD)",
+			resource: user,
+			ops: `[
+				{"op": "Replace", "path": "name.familyName", "value": "Chen"},
+				{"op": "Replace", "path": "emails[type eq \"work\"].value", "value": "alice@corp.example.com"},
+				{"op": "Add", "path": "urn:ietf:params:scim:schemas:core:2.0:User:displayName", "value": "Alice Chen"}
+			]`,
+			want: `{
+				"userName": "alice",
+				"displayName": "Alice Chen",
+				"name": {"givenName": "Alice", "familyName": "Chen"},
+				"emails": [{"value": "alice@corp.example.com", "type": "work", "primary": true}],
+				"active": true
+			}`,
+		},
+		{
+			name:     "sub-attribute paths in value",
+			resource: user,
+			ops:      `[{"op": "replace", "value": {"name.givenName": "Al", "userName": "al"}}]`,
+			want: `{
+				"userName": "al",
+				"name": {"givenName": "Al", "familyName": "Zhao"},
+				"emails": [{"value": "alice@example.com", "type": "work", "primary": true}],
+				"active": true
+			}`,
+		},
+		{
+			name:     "add value matching a filter",
+			resource: user,
+			ops:      `[{"op": "add", "path": "emails[type eq \"home\"].value", "value": "alice@home.example.com"}]`,
+			want: `{
+				"userName": "alice",
+				"name": {"givenName": "Alice", "familyName": "Zhao"},
+				"emails": [
+					{"value": "alice@example.com", "type": "work", "primary": true},
+					{"value": "alice@home.example.com", "type": "home"}
+				],
+				"active": true
+			}`,
+		},
+		{
+			name:     "add members",
+			resource: group,
+			ops:      `[{"op": "add", "path": "members", "value": [{"value": "3"}]}]`,
+			want: `{
+				"displayName": "Engineering",
+				"members": [{"value": "1", "display": "alice"}, {"value": "2", "display": "bob"}, {"value": "3"}]
+			}`,
+		},
+		{
+			name:     "remove member by filter (Okta)",
+			resource: group,
+			ops:      `[{"op": "remove", "path": "members[value eq \"1\"]"}]`,
+			want: `{
+				"displayName": "Engineering",
+				"members": [{"value": "2", "display": "bob"}]
+			}`,
+		},
+		{
+			name:     "remove members by value (Azure AD)",
+			resource: group,
+			ops:      `[{"op": "Remove", "path": "members", "value": [{"value": "2"}, {"value": "1"}]}]`,
+			want:     `{"displayName": "Engineering", "members": []}`,
+		},
+		{
+			name:     "remove missing member",
+			resource: group,
+			ops:      `[{"op": "remove", "path": "members[value eq \"9\"]"}]`,
+			want:     group,
+		},
+		{
+			name:     "replace members",
+			resource: group,
+			ops:      `[{"op": "replace", "path": "members", "value": [{"value": "4"}]}, {"op": "replace", "path": "displayName", "value": "Eng"}]`,
+			want:     `{"displayName": "Eng", "members": [{"value": "4"}]}`,
+		},
+		{
+			name:     "remove all members",
+			resource: group,
+			ops:      `[{"op": "remove", "path": "members"}]`,
+			want:     `{"displayName": "Engineering"}`,
+		},
+		{
+			name:     "remove without path",
+			resource: group,
+			ops:      `[{"op": "remove"}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "unknown operation",
+			resource: group,
+			ops:      `[{"op": "move", "path": "members"}]`,
+			wantErr:  true,
+		},
+		{
+			name:     "invalid path",
+			resource: group,
+			ops:      `[{"op": "remove", "path": "members[value eq"}]`,
+			wantErr:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var m map[string]interface{}
+			if err := json.Unmarshal([]byte(tc.resource), &m); err != nil {
+				t.Fatal(err)
+			}
+			var ops []patchOp
+			if err := json.Unmarshal([]byte(tc.ops), &ops); err != nil {
+				t.Fatal(err)
+			}
+
+			var err error
+			for _, op := range ops {
+				if err = applyPatch(m, op); err != nil {
+					break
+				}
+			}
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want map[string]interface{}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			// Compare the JSON representations, since removing values leaves an empty slice of
+			// a different type.
+			have, _ := json.Marshal(m)
+			wantJSON, _ := json.Marshal(want)
+			if !reflect.DeepEqual(have, wantJSON) {
+				t.Errorf("have %s, want %s", have, wantJSON)
+			}
+		})
+	}
+}
@@ -0,0 +1,394 @@
+// Package scim implements a SCIM 2.0 (RFC 7643 and RFC 7644) HTTP API for provisioning users and
+// organizations from an identity provider (such as Okta or Azure AD).
+//
+// SCIM users are Sourcegraph users and SCIM groups are Sourcegraph organizations. Deactivating a
+// user (setting "active" to false) deactivates the Sourcegraph user, which revokes its sessions and
+// access tokens but keeps it so that it can be reactivated. Deleting a user deletes the Sourcegraph
+// user.
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/globals"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// PathPrefix is the path under which the SCIM API is served.
+const PathPrefix = "/.api/scim/v2"
+
+const (
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	schemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+)
+
+// maxResults is the maximum number of resources returned in a single list response.
+const maxResults = 100
+
+// NewHandler returns the handler of the SCIM API.
+//
+// 🚨 SECURITY: The handler authenticates the client itself (see authenticate) and only serves
+// requests of site admins, so it may be accessible to anonymous clients.
+func NewHandler() http.Handler {
+	r := mux.NewRouter().PathPrefix(PathPrefix).Subrouter()
+	r.StrictSlash(true)
+
+	r.Path("/ServiceProviderConfig").Methods("GET").Handler(handler(serveServiceProviderConfig))
+	r.Path("/ResourceTypes").Methods("GET").Handler(handler(serveResourceTypes))
+
+	r.Path("/Users").Methods("GET").Handler(handler(serveListUsers))
+	r.Path("/Users").Methods("POST").Handler(handler(serveCreateUser))
+	r.Path("/Users/{id}").Methods("GET").Handler(handler(serveGetUser))
+	r.Path("/Users/{id}").Methods("PUT").Handler(handler(serveReplaceUser))
+	r.Path("/Users/{id}").Methods("PATCH").Handler(handler(servePatchUser))
+	r.Path("/Users/{id}").Methods("DELETE").Handler(handler(serveDeleteUser))
+
+	r.Path("/Groups").Methods("GET").Handler(handler(serveListGroups))
+	r.Path("/Groups").Methods("POST").Handler(handler(serveCreateGroup))
+	r.Path("/Groups/{id}").Methods("GET").Handler(handler(serveGetGroup))
+	r.Path("/Groups/{id}").Methods("PUT").Handler(handler(serveReplaceGroup))
+	r.Path("/Groups/{id}").Methods("PATCH").Handler(handler(servePatchGroup))
+	r.Path("/Groups/{id}").Methods("DELETE").Handler(handler(serveDeleteGroup))
+
+	r.NotFoundHandler = handler(func(w http.ResponseWriter, r *http.Request) error {
+		return &scimError{Status: http.StatusNotFound, Detail: "no such SCIM endpoint"}
+	})
+	r.MethodNotAllowedHandler = handler(func(w http.ResponseWriter, r *http.Request) error {
+		return &scimError{Status: http.StatusMethodNotAllowed, Detail: "method not allowed"}
+	})
+	return authenticate(r)
+}
+
+// authenticate is a middleware that authenticates the client with the access token in the
+// "Authorization: Bearer" header, which is what SCIM clients send, and requires the client to
+// be a site admin. Clients already authenticated by other means (such as an access token in an
+// "Authorization: token" header) are accepted too.
+func authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if token := bearerToken(r); token != "" && !actor.FromContext(ctx).IsAuthenticated() {
+			if conf.AccessTokensAllow() == conf.AccessTokensNone {
+				writeError(w, &scimError{Status: http.StatusUnauthorized, Detail: "access token authorization is disabled"})
+				return
+			}
+
+			// 🚨 SECURITY: The token must have the user:all or site-admin:users scope, since it is
+			// used to manage users on behalf of its subject.
+			userID, scopes, err := db.AccessTokens.Lookup(ctx, token, []string{authz.ScopeUserAll, authz.ScopeSiteAdminUsers})
+			if err != nil {
+				log15.Error("SCIM: invalid access token.", "err", err)
+				writeError(w, &scimError{Status: http.StatusUnauthorized, Detail: "invalid access token"})
+				return
+			}
+			a := &actor.Actor{UID: userID, Scopes: scopes}
+			if a.HasScope(authz.ScopeUserAll) {
+				a.Scopes = nil
+			}
+			ctx = actor.WithActor(ctx, a)
+			r = r.WithContext(ctx)
+		}
+
+		if !actor.FromContext(ctx).IsAuthenticated() {
+			writeError(w, &scimError{Status: http.StatusUnauthorized, Detail: "authentication required"})
+			return
+		}
+
+		// 🚨 SECURITY: Access tokens with restricted scopes must have the site-admin:users scope.
+		ctx, err := backend.WithAccessTokenScope(ctx, authz.ScopeSiteAdminUsers)
+		if err != nil {
+			writeError(w, &scimError{Status: http.StatusForbidden, Detail: err.Error()})
+			return
+		}
+
+		// 🚨 SECURITY: Only site admins may provision users and organizations.
+		if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+			writeError(w, &scimError{Status: http.StatusForbidden, Detail: "must be site admin"})
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func bearerToken(r *http.Request) string {
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// handler wraps a SCIM endpoint, writing the errors it returns as SCIM error responses.
+func handler(serve func(w http.ResponseWriter, r *http.Request) error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := serve(w, r); err != nil {
+			writeError(w, err)
+		}
+	})
+}
+
+// scimError is a SCIM error response (RFC 7644 section 3.12).
+type scimError struct {
+	Status   int
+	ScimType string
+	Detail   string
+}
+
+func (e *scimError) Error() string {
+	return fmt.Sprintf("SCIM error %d %s: %s", e.Status, e.ScimType, e.Detail)
+}
+
+func badRequest(scimType, format string, args ...interface{}) error {
+	return &scimError{Status: http.StatusBadRequest, ScimType: scimType, Detail: fmt.Sprintf(format, args...)}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	e, ok := err.(*scimError)
+	if !ok {
+		switch {
+		case errcode.IsNotFound(err):
+			e = &scimError{Status: http.StatusNotFound, Detail: "resource not found"}
+		case db.IsUsernameExists(err), db.IsEmailExists(err):
+			e = &scimError{Status: http.StatusConflict, ScimType: "uniqueness", Detail: err.Error()}
+		default:
+			log15.Error("SCIM: request failed.", "err", err)
+			e = &scimError{Status: http.StatusInternalServerError, Detail: "internal error"}
+		}
+	}
+
+	writeJSON(w, e.Status, struct {
+		Schemas  []string `json:"schemas"`
+		Status   string   `json:"status"`
+		ScimType string   `json:"scimType,omitempty"`
+		Detail   string   `json:"detail,omitempty"`
+	}{
+		Schemas:  []string{schemaError},
+		Status:   strconv.Itoa(e.Status),
+		ScimType: e.ScimType,
+		Detail:   e.Detail,
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/scim+json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log15.Error("SCIM: failed to write response.", "err", err)
+	}
+}
+
+func readJSON(r *http.Request, v interface{}) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return badRequest("invalidSyntax", "invalid request body: %s", err)
+	}
+	return nil
+}
+
+// meta is the metadata of a resource.
+type meta struct {
+	ResourceType string `json:"resourceType"`
+	Created      string `json:"created,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+	Location     string `json:"location"`
+}
+
+func location(resourceType string, id int32) string {
+	return fmt.Sprintf("%s%s/%s/%d", strings.TrimSuffix(globals.ExternalURL().String(), "/"), PathPrefix, resourceType, id)
+}
+
+// multiValue is a value of a multi-valued attribute, such as the emails of a user or the
+// members of a group.
+type multiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// listResponse is the response of a list or query request.
+type listResponse struct {
+	Schemas      []string    `json:"schemas"`
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    interface{} `json:"Resources"`
+}
+
+// listParams are the query parameters of a list request.
+type listParams struct {
+	filter *filter
+	// startIndex is the 1-based index of the first result.
+	startIndex int
+	count      int
+}
+
+func (p listParams) limitOffset() *db.LimitOffset {
+	return &db.LimitOffset{Limit: p.count, Offset: p.startIndex - 1}
+}
+
+func parseListParams(r *http.Request) (p listParams, err error) {
+	q := r.URL.Query()
+
+	if s := q.Get("filter"); s != "" {
+		if p.filter, err = parseFilter(s); err != nil {
+			return p, err
+		}
+	}
+
+	p.startIndex = 1
+	if s := q.Get("startIndex"); s != "" {
+		if p.startIndex, err = strconv.Atoi(s); err != nil {
+			return p, badRequest("invalidValue", "invalid startIndex %q", s)
+		}
+		if p.startIndex < 1 {
+			p.startIndex = 1
+		}
+	}
+
+	p.count = maxResults
+	if s := q.Get("count"); s != "" {
+		if p.count, err = strconv.Atoi(s); err != nil {
+			return p, badRequest("invalidValue", "invalid count %q", s)
+		}
+		if p.count < 0 {
+			p.count = 0
+		} else if p.count > maxResults {
+			p.count = maxResults
+		}
+	}
+	return p, nil
+}
+
+// page returns the page of the resources described by the list params.
+func page(resources []interface{}, p listParams) []interface{} {
+	if p.startIndex > len(resources) {
+		return []interface{}{}
+	}
+	resources = resources[p.startIndex-1:]
+	if len(resources) > p.count {
+		resources = resources[:p.count]
+	}
+	return resources
+}
+
+func resourceID(r *http.Request) (int32, error) {
+	id, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 32)
+	if err != nil {
+		return 0, &scimError{Status: http.StatusNotFound, Detail: "resource not found"}
+	}
+	return int32(id), nil
+}
+
+// patchRequest is the body of a PATCH request.
+type patchRequest struct {
+	Schemas    []string  `json:"schemas"`
+	Operations []patchOp `json:"Operations"`
+}
+
+// applyPatchRequest applies the operations of the PATCH request to the resource, which is
+// converted to and from its generic JSON representation.
+func applyPatchRequest(r *http.Request, resource interface{}) error {
+	var req patchRequest
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	var m map[string]interface{}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return err
+	}
+
+	for _, op := range req.Operations {
+		if err := applyPatch(m, op); err != nil {
+			return err
+		}
+	}
+
+	// Some clients send booleans as strings.
+	if k := key(m, "active"); m[k] != nil {
+		if s, ok := m[k].(string); ok {
+			v, err := strconv.ParseBool(s)
+			if err != nil {
+				return badRequest("invalidValue", "invalid value %q for active", s)
+			}
+			m[k] = v
+		}
+	}
+
+	if b, err = json.Marshal(m); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, resource); err != nil {
+		return badRequest("invalidValue", "invalid patched resource: %s", err)
+	}
+	return nil
+}
+
+// serveServiceProviderConfig serves the SCIM features supported by this service provider.
+func serveServiceProviderConfig(w http.ResponseWriter, r *http.Request) error {
+	supported := func(b bool) map[string]interface{} { return map[string]interface{}{"supported": b} }
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"schemas":        []string{schemaServiceProviderConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]interface{}{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]interface{}{"supported": true, "maxResults": maxResults},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]interface{}{{
+			"type":        "oauthbearertoken",
+			"name":        "OAuth Bearer Token",
+			"description": "Authentication with the access token of a site admin",
+		}},
+		"meta": map[string]string{"resourceType": "ServiceProviderConfig", "location": strings.TrimSuffix(globals.ExternalURL().String(), "/") + PathPrefix + "/ServiceProviderConfig"},
+	})
+	return nil
+}
+
+// serveResourceTypes serves the types of resources available from this service provider.
+func serveResourceTypes(w http.ResponseWriter, r *http.Request) error {
+	resourceType := func(name, endpoint, schema string) map[string]interface{} {
+		return map[string]interface{}{
+			"schemas":  []string{schemaResourceType},
+			"id":       name,
+			"name":     name,
+			"endpoint": endpoint,
+			"schema":   schema,
+			"meta":     map[string]string{"resourceType": "ResourceType", "location": strings.TrimSuffix(globals.ExternalURL().String(), "/") + PathPrefix + "/ResourceTypes/" + name},
+		}
+	}
+	types := []interface{}{
+		resourceType("User", "/Users", schemaUser),
+		resourceType("Group", "/Groups", schemaGroup),
+	}
+	writeJSON(w, http.StatusOK, listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: len(types),
+		StartIndex:   1,
+		ItemsPerPage: len(types),
+		Resources:    types,
+	})
+	return nil
+}
@@ -0,0 +1,218 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+func TestHandler(t *testing.T) {
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	now := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+	users := map[int32]*types.User{
+		1: {ID: 1, Username: "admin", SiteAdmin: true, CreatedAt: now, UpdatedAt: now},
+		2: {ID: 2, Username: "alice", DisplayName: "Alice Zhao", CreatedAt: now, UpdatedAt: now},
+	}
+	type token struct {
+		userID int32
+		scopes []string
+	}
+	tokens := map[string]token{
+		"admintoken":       {userID: 1, scopes: []string{authz.ScopeUserAll}},
+		"alicetoken":       {userID: 2, scopes: []string{authz.ScopeUserAll}},
+		"adminuserstoken":  {userID: 1, scopes: []string{authz.ScopeSiteAdminUsers}},
+		"adminsearchtoken": {userID: 1, scopes: []string{authz.ScopeSearchRead}},
+	}
+
+	db.Mocks.AccessTokens.Lookup = func(tokenHexEncoded string, requiredScopes []string) (int32, []string, error) {
+		if tok, ok := tokens[tokenHexEncoded]; ok {
+			for _, required := range requiredScopes {
+				for _, scope := range tok.scopes {
+					if scope == required {
+						return tok.userID, tok.scopes, nil
+					}
+				}
+			}
+		}
+		return 0, nil, errors.New("invalid token")
+	}
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		if user, ok := users[actor.FromContext(ctx).UID]; ok {
+			return user, nil
+		}
+		return nil, db.ErrNoCurrentUser
+	}
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		if user, ok := users[id]; ok {
+			return user, nil
+		}
+		return nil, db.NewUserNotFoundError(id)
+	}
+	db.Mocks.Users.GetByUsername = func(ctx context.Context, username string) (*types.User, error) {
+		for _, user := range users {
+			if user.Username == username {
+				return user, nil
+			}
+		}
+		return nil, db.NewUserNotFoundError(0)
+	}
+	verified := now
+	db.Mocks.UserEmails.ListByUser = func(ctx context.Context, opt db.UserEmailsListOptions) ([]*db.UserEmail, error) {
+		if opt.UserID != 2 {
+			return nil, nil
+		}
+		return []*db.UserEmail{
+			{UserID: 2, Email: "alice@old.example.com", CreatedAt: now.Add(-time.Hour)},
+			{UserID: 2, Email: "alice@example.com", CreatedAt: now, VerifiedAt: &verified},
+		}, nil
+	}
+	db.Mocks.ExternalAccounts.List = func(opt db.ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		if opt.ServiceType != serviceType || (opt.UserID != 2 && opt.AccountID != "00u1") {
+			return nil, nil
+		}
+		data := json.RawMessage(`{"name": {"givenName": "Alice", "familyName": "Zhao"}}`)
+		return []*extsvc.ExternalAccount{{
+			UserID:              2,
+			ExternalAccountSpec: accountSpec("00u1"),
+			ExternalAccountData: extsvc.ExternalAccountData{AccountData: &data},
+		}}, nil
+	}
+	db.Mocks.Orgs.GetByUserID = func(ctx context.Context, userID int32) ([]*types.Org, error) {
+		if userID != 2 {
+			return nil, nil
+		}
+		return []*types.Org{{ID: 7, Name: "eng"}}, nil
+	}
+
+	h := NewHandler()
+	do := func(method, path, token, body string) (int, map[string]interface{}) {
+		req := httptest.NewRequest(method, "http://example.com"+PathPrefix+path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		var resp map[string]interface{}
+		if rr.Body.Len() > 0 {
+			if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response %q: %s", rr.Body.String(), err)
+			}
+		}
+		return rr.Code, resp
+	}
+
+	t.Run("authentication", func(t *testing.T) {
+		for _, tc := range []struct {
+			token string
+			want  int
+		}{
+			{token: "", want: http.StatusUnauthorized},
+			{token: "badtoken", want: http.StatusUnauthorized},
+			{token: "alicetoken", want: http.StatusForbidden},
+			{token: "admintoken", want: http.StatusOK},
+			{token: "adminuserstoken", want: http.StatusOK},
+			{token: "adminsearchtoken", want: http.StatusUnauthorized},
+		} {
+			if code, _ := do("GET", "/Users/2", tc.token, ""); code != tc.want {
+				t.Errorf("token %q: have status %d, want %d", tc.token, code, tc.want)
+			}
+		}
+	})
+
+	t.Run("get user", func(t *testing.T) {
+		code, resp := do("GET", "/Users/2", "admintoken", "")
+		if code != http.StatusOK {
+			t.Fatalf("have status %d, response %v", code, resp)
+		}
+		have, _ := json.Marshal(resp)
+		want := `{"active":true,"displayName":"Alice Zhao",` +
+			`"emails":[{"type":"work","value":"alice@old.example.com"},{"primary":true,"type":"work","value":"alice@example.com"}],` +
+			`"externalId":"00u1",` +
+			`"groups":[{"$ref":"http://example.com/.api/scim/v2/Groups/7","display":"eng","type":"direct","value":"7"}],` +
+			`"id":"2",` +
+			`"meta":{"created":"2020-03-01T12:00:00Z","lastModified":"2020-03-01T12:00:00Z","location":"http://example.com/.api/scim/v2/Users/2","resourceType":"User"},` +
+			`"name":{"familyName":"Zhao","givenName":"Alice"},` +
+			`"schemas":["urn:ietf:params:scim:schemas:core:2.0:User"],"userName":"alice"}`
+		if string(have) != want {
+			t.Errorf("have %s\nwant %s", have, want)
+		}
+	})
+
+	t.Run("get unknown user", func(t *testing.T) {
+		code, resp := do("GET", "/Users/9", "admintoken", "")
+		if code != http.StatusNotFound || resp["status"] != "404" {
+			t.Errorf("have status %d, response %v", code, resp)
+		}
+	})
+
+	t.Run("find users", func(t *testing.T) {
+		for _, tc := range []struct {
+			filter string
+			want   float64
+		}{
+			{filter: `userName eq "alice@example.com"`, want: 1},
+			{filter: `externalId eq "00u1"`, want: 1},
+			{filter: `userName eq "bob"`, want: 0},
+		} {
+			code, resp := do("GET", "/Users?filter="+strings.Replace(tc.filter, " ", "+", -1), "admintoken", "")
+			if code != http.StatusOK || resp["totalResults"] != tc.want {
+				t.Errorf("%s: have status %d, response %v", tc.filter, code, resp)
+			}
+		}
+
+		code, resp := do("GET", "/Users?filter=title+eq+%22x%22", "admintoken", "")
+		if code != http.StatusBadRequest || resp["scimType"] != "invalidFilter" {
+			t.Errorf("unsupported filter: have status %d, response %v", code, resp)
+		}
+	})
+
+	t.Run("deactivate and reactivate user", func(t *testing.T) {
+		db.Mocks.Users.SetDeactivated = func(ctx context.Context, id int32, deactivated bool) error {
+			if deactivated {
+				users[id].DeactivatedAt = &now
+			} else {
+				users[id].DeactivatedAt = nil
+			}
+			return nil
+		}
+		db.Mocks.ExternalAccounts.AssociateUserAndSave = func(userID int32, spec extsvc.ExternalAccountSpec, data extsvc.ExternalAccountData) error {
+			return nil
+		}
+		defer func() {
+			db.Mocks.Users.SetDeactivated = nil
+			db.Mocks.ExternalAccounts.AssociateUserAndSave = nil
+			users[2].DeactivatedAt = nil
+		}()
+
+		for _, active := range []bool{false, true} {
+			code, resp := do("PATCH", "/Users/2", "admintoken", fmt.Sprintf(`{
+				"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+				"Operations": [{"op": "replace", "value": {"active": %t}}]
+			}`, active))
+			if code != http.StatusOK || resp["active"] != active {
+				t.Errorf("active %t: have status %d, response %v", active, code, resp)
+			}
+			if deactivated := users[2].DeactivatedAt != nil; deactivated == active {
+				t.Errorf("active %t: have deactivated %t", active, deactivated)
+			}
+
+			code, resp = do("GET", "/Users/2", "admintoken", "")
+			if code != http.StatusOK || resp["active"] != active {
+				t.Errorf("active %t: have status %d, response %v", active, code, resp)
+			}
+		}
+	})
+}
@@ -0,0 +1,511 @@
+package scim
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+// The external accounts of provisioned users record their SCIM externalId (as the account ID) and
+// the SCIM attributes that Sourcegraph users don't have (such as their given and family names).
+const (
+	serviceType = "scim"
+	serviceID   = "scim"
+)
+
+// userResource is a SCIM user (RFC 7643 section 4.1).
+type userResource struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *userName    `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []multiValue `json:"emails,omitempty"`
+	Active      *bool        `json:"active,omitempty"`
+	Groups      []multiValue `json:"groups,omitempty"`
+	Meta        *meta        `json:"meta,omitempty"`
+}
+
+type userName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	MiddleName string `json:"middleName,omitempty"`
+}
+
+// displayName returns the display name of the Sourcegraph user, which is the SCIM display name or
+// else the user's full name.
+func (u *userResource) displayName() string {
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	if u.Name == nil {
+		return ""
+	}
+	if u.Name.Formatted != "" {
+		return u.Name.Formatted
+	}
+	return strings.TrimSpace(strings.Join([]string{u.Name.GivenName, u.Name.MiddleName, u.Name.FamilyName}, " "))
+}
+
+// emails returns the email addresses of the user, starting with the primary email address.
+func (u *userResource) emails() []string {
+	var emails []string
+	for _, e := range u.Emails {
+		if e.Value == "" {
+			continue
+		}
+		if e.Primary {
+			emails = append([]string{e.Value}, emails...)
+		} else {
+			emails = append(emails, e.Value)
+		}
+	}
+	return emails
+}
+
+func accountSpec(externalID string) extsvc.ExternalAccountSpec {
+	return extsvc.ExternalAccountSpec{
+		ServiceType: serviceType,
+		ServiceID:   serviceID,
+		AccountID:   externalID,
+	}
+}
+
+func accountData(u *userResource) (extsvc.ExternalAccountData, error) {
+	b, err := json.Marshal(userResource{Schemas: u.Schemas, ExternalID: u.ExternalID, UserName: u.UserName, Name: u.Name, DisplayName: u.DisplayName})
+	if err != nil {
+		return extsvc.ExternalAccountData{}, err
+	}
+	data := json.RawMessage(b)
+	return extsvc.ExternalAccountData{AccountData: &data}, nil
+}
+
+// account returns the SCIM external account of the user, if any.
+func account(ctx context.Context, userID int32) (*extsvc.ExternalAccount, error) {
+	accts, err := db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{
+		UserID:      userID,
+		ServiceType: serviceType,
+		ServiceID:   serviceID,
+	})
+	if err != nil || len(accts) == 0 {
+		return nil, err
+	}
+	return accts[0], nil
+}
+
+func toUserResource(ctx context.Context, user *types.User) (*userResource, error) {
+	active := user.DeactivatedAt == nil
+	u := &userResource{
+		Schemas:     []string{schemaUser},
+		ID:          strconv.Itoa(int(user.ID)),
+		UserName:    user.Username,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Meta: &meta{
+			ResourceType: "User",
+			Created:      user.CreatedAt.UTC().Format(time.RFC3339),
+			LastModified: user.UpdatedAt.UTC().Format(time.RFC3339),
+			Location:     location("Users", user.ID),
+		},
+	}
+
+	acct, err := account(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if acct != nil {
+		u.ExternalID = acct.AccountID
+		if acct.AccountData != nil {
+			var data userResource
+			if err := json.Unmarshal(*acct.AccountData, &data); err == nil {
+				u.Name = data.Name
+			}
+		}
+	}
+	if u.Name == nil && user.DisplayName != "" {
+		u.Name = &userName{Formatted: user.DisplayName}
+	}
+
+	emails, err := db.UserEmails.ListByUser(ctx, db.UserEmailsListOptions{UserID: user.ID})
+	if err != nil {
+		return nil, err
+	}
+	// The primary email address is the oldest verified one (see db.UserEmails.GetPrimaryEmail).
+	var primary *db.UserEmail
+	for _, e := range emails {
+		switch {
+		case primary == nil:
+			primary = e
+		case (e.VerifiedAt != nil) != (primary.VerifiedAt != nil):
+			if e.VerifiedAt != nil {
+				primary = e
+			}
+		case e.CreatedAt.Before(primary.CreatedAt):
+			primary = e
+		}
+	}
+	for _, e := range emails {
+		u.Emails = append(u.Emails, multiValue{Value: e.Email, Type: "work", Primary: e == primary})
+	}
+
+	orgs, err := db.Orgs.GetByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, org := range orgs {
+		u.Groups = append(u.Groups, multiValue{
+			Value:   strconv.Itoa(int(org.ID)),
+			Display: orgDisplayName(org),
+			Type:    "direct",
+			Ref:     location("Groups", org.ID),
+		})
+	}
+	return u, nil
+}
+
+func serveListUsers(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	p, err := parseListParams(r)
+	if err != nil {
+		return err
+	}
+
+	var users []*types.User
+	var total int
+	if p.filter == nil {
+		if users, err = db.Users.List(ctx, &db.UsersListOptions{LimitOffset: p.limitOffset()}); err != nil {
+			return err
+		}
+		if total, err = db.Users.Count(ctx, nil); err != nil {
+			return err
+		}
+	} else {
+		if users, err = findUsers(ctx, p.filter); err != nil {
+			return err
+		}
+		total = len(users)
+		if p.startIndex > len(users) {
+			users = nil
+		} else {
+			users = users[p.startIndex-1:]
+		}
+		if len(users) > p.count {
+			users = users[:p.count]
+		}
+	}
+
+	resources := make([]*userResource, 0, len(users))
+	for _, user := range users {
+		u, err := toUserResource(ctx, user)
+		if err != nil {
+			return err
+		}
+		resources = append(resources, u)
+	}
+	writeJSON(w, http.StatusOK, listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   p.startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+	return nil
+}
+
+// findUsers returns the users matching the filter. Only the lookups identity providers use to
+// find existing users are supported.
+func findUsers(ctx context.Context, f *filter) ([]*types.User, error) {
+	var user *types.User
+	var err error
+	if v, ok := f.is("userName"); ok {
+		username, nerr := auth.NormalizeUsername(v)
+		if nerr != nil {
+			return nil, nil
+		}
+		user, err = db.Users.GetByUsername(ctx, username)
+	} else if v, ok := f.is("externalId"); ok {
+		var accts []*extsvc.ExternalAccount
+		accts, err = db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{
+			ServiceType: serviceType,
+			ServiceID:   serviceID,
+			AccountID:   v,
+		})
+		if err != nil || len(accts) == 0 {
+			return nil, err
+		}
+		user, err = db.Users.GetByID(ctx, accts[0].UserID)
+	} else if v, ok := f.is("emails", "emails.value"); ok {
+		user, err = db.Users.GetByVerifiedEmail(ctx, v)
+	} else if v, ok := f.is("id"); ok {
+		id, perr := strconv.ParseInt(v, 10, 32)
+		if perr != nil {
+			return nil, nil
+		}
+		user, err = db.Users.GetByID(ctx, int32(id))
+	} else {
+		return nil, badRequest("invalidFilter", "unsupported filter: only eq comparisons of userName, externalId, emails.value and id are supported")
+	}
+
+	if errcode.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return []*types.User{user}, nil
+}
+
+func serveGetUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := resourceID(r)
+	if err != nil {
+		return err
+	}
+	user, err := db.Users.GetByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	u, err := toUserResource(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, u)
+	return nil
+}
+
+func serveCreateUser(w http.ResponseWriter, r *http.Request) error {
+	ctx := r.Context()
+	var u userResource
+	if err := readJSON(r, &u); err != nil {
+		return err
+	}
+	username, err := auth.NormalizeUsername(u.UserName)
+	if err != nil {
+		return badRequest("invalidValue", "invalid userName: %s", err)
+	}
+
+	newUser := db.NewUser{
+		Username:    username,
+		DisplayName: u.displayName(),
+		// 🚨 SECURITY: The email address is provisioned by the identity provider on behalf of a
+		// site admin, so it is trusted to belong to the user.
+		EmailIsVerified: true,
+	}
+	emails := u.emails()
+	if len(emails) > 0 {
+		newUser.Email = emails[0]
+	}
+
+	var userID int32
+	if u.ExternalID != "" {
+		data, err := accountData(&u)
+		if err != nil {
+			return err
+		}
+		if userID, err = db.ExternalAccounts.CreateUserAndSave(ctx, newUser, accountSpec(u.ExternalID), data); err != nil {
+			return err
+		}
+	} else {
+		user, err := db.Users.Create(ctx, newUser)
+		if err != nil {
+			return err
+		}
+		userID = user.ID
+	}
+
+	if len(emails) > 1 {
+		if err := setEmails(ctx, userID, emails); err != nil {
+			return err
+		}
+	}
+	if u.Active != nil && !*u.Active {
+		if err := db.Users.SetDeactivated(ctx, userID, true); err != nil {
+			return err
+		}
+	}
+
+	user, err := db.Users.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	res, err := toUserResource(ctx, user)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Location", res.Meta.Location)
+	writeJSON(w, http.StatusCreated, res)
+	return nil
+}
+
+func serveReplaceUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := resourceID(r)
+	if err != nil {
+		return err
+	}
+	user, err := db.Users.GetByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	var u userResource
+	if err := readJSON(r, &u); err != nil {
+		return err
+	}
+	return replaceUser(w, r, user, &u)
+}
+
+func servePatchUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := resourceID(r)
+	if err != nil {
+		return err
+	}
+	user, err := db.Users.GetByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+	u, err := toUserResource(r.Context(), user)
+	if err != nil {
+		return err
+	}
+	if err := applyPatchRequest(r, u); err != nil {
+		return err
+	}
+	return replaceUser(w, r, user, u)
+}
+
+// replaceUser updates the user to match the SCIM user.
+func replaceUser(w http.ResponseWriter, r *http.Request, user *types.User, u *userResource) error {
+	ctx := r.Context()
+
+	// 🚨 SECURITY: Deactivating a user revokes their sessions and access tokens, so that they
+	// immediately lose access. The user is kept, so that it can be reactivated.
+	if u.Active != nil && *u.Active == (user.DeactivatedAt != nil) {
+		if err := db.Users.SetDeactivated(ctx, user.ID, !*u.Active); err != nil {
+			return err
+		}
+	}
+
+	var update db.UserUpdate
+	changed := false
+	if u.UserName != "" {
+		username, err := auth.NormalizeUsername(u.UserName)
+		if err != nil {
+			return badRequest("invalidValue", "invalid userName: %s", err)
+		}
+		if username != user.Username {
+			update.Username = username
+			changed = true
+		}
+	}
+	if displayName := u.displayName(); displayName != user.DisplayName {
+		update.DisplayName = &displayName
+		changed = true
+	}
+	if changed {
+		if err := db.Users.Update(ctx, user.ID, update); err != nil {
+			return err
+		}
+	}
+
+	if emails := u.emails(); len(emails) > 0 {
+		if err := setEmails(ctx, user.ID, emails); err != nil {
+			return err
+		}
+	}
+
+	if err := setExternalID(ctx, user.ID, u); err != nil {
+		return err
+	}
+
+	user, err := db.Users.GetByID(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	res, err := toUserResource(ctx, user)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, res)
+	return nil
+}
+
+// setEmails adds the email addresses the user doesn't have yet (as verified email addresses)
+// and removes those that aren't in the list.
+func setEmails(ctx context.Context, userID int32, emails []string) error {
+	existing, err := db.UserEmails.ListByUser(ctx, db.UserEmailsListOptions{UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]bool, len(emails))
+	for _, email := range emails {
+		want[strings.ToLower(email)] = true
+	}
+	have := make(map[string]bool, len(existing))
+	for _, e := range existing {
+		have[strings.ToLower(e.Email)] = true
+		if !want[strings.ToLower(e.Email)] {
+			if err := db.UserEmails.Remove(ctx, userID, e.Email); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, email := range emails {
+		if have[strings.ToLower(email)] {
+			continue
+		}
+		have[strings.ToLower(email)] = true
+		if err := db.UserEmails.Add(ctx, userID, email, nil); err != nil {
+			return err
+		}
+		// 🚨 SECURITY: See serveCreateUser for why provisioned email addresses are verified.
+		if err := db.UserEmails.SetVerified(ctx, userID, email, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setExternalID records the SCIM externalId and attributes of the user in its SCIM external
+// account.
+func setExternalID(ctx context.Context, userID int32, u *userResource) error {
+	acct, err := account(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.ExternalID == "" {
+		return nil
+	}
+	if acct != nil && acct.AccountID != u.ExternalID {
+		if err := db.ExternalAccounts.Delete(ctx, acct.ID); err != nil {
+			return err
+		}
+	}
+
+	data, err := accountData(u)
+	if err != nil {
+		return err
+	}
+	return db.ExternalAccounts.AssociateUserAndSave(ctx, userID, accountSpec(u.ExternalID), data)
+}
+
+func serveDeleteUser(w http.ResponseWriter, r *http.Request) error {
+	id, err := resourceID(r)
+	if err != nil {
+		return err
+	}
+	if err := db.Users.Delete(r.Context(), id); err != nil {
+		return err
+	}
+	w.WriteHeader(http.StatusNoContent)
+	return nil
+}
@@ -3,11 +3,15 @@ package session
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/textproto"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -56,6 +60,66 @@ type sessionInfo struct {
 	Actor        *actor.Actor  `json:"actor"`
 	LastActive   time.Time     `json:"lastActive"`
 	ExpiryPeriod time.Duration `json:"expiryPeriod"`
+
+	// Key is the key of the session in the registry of the user's sessions (see
+	// db.UserSessions). It is empty for sessions that were created before sessions were
+	// registered.
+	Key string `json:"key,omitempty"`
+}
+
+// userSessionStore is the registry of the users' sessions. Revoking a session deletes it from the
+// registry.
+type userSessionStore interface {
+	Create(ctx context.Context, s *db.UserSession) error
+	GetByKey(ctx context.Context, key string) (*db.UserSession, error)
+	Touch(ctx context.Context, key, ip string, expiresAt time.Time) error
+	DeleteByKey(ctx context.Context, key string) error
+}
+
+// userSessions is the registry of sessions. It is replaced in tests (see ResetMockSessionStore).
+var userSessions userSessionStore = db.UserSessions
+
+// sessionCheckInterval is how often a registered session is checked against the registry of
+// sessions. A revoked session is signed out within this interval.
+const sessionCheckInterval = time.Minute
+
+// checkedSessions records when the registered sessions used in requests to this process were last
+// checked against the registry, so that authenticateByCookie doesn't look up the session on every
+// request.
+var checkedSessions = &sessionChecks{checkedAt: map[string]time.Time{}}
+
+type sessionChecks struct {
+	mu        sync.Mutex
+	checkedAt map[string]time.Time
+}
+
+// recent returns true if the session with the key was checked within sessionCheckInterval.
+func (c *sessionChecks) recent(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	checkedAt, ok := c.checkedAt[key]
+	return ok && time.Since(checkedAt) < sessionCheckInterval
+}
+
+// add records that the session with the key was checked now. It also forgets the sessions that
+// were not checked recently, so that the map doesn't grow with every session.
+func (c *sessionChecks) add(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, checkedAt := range c.checkedAt {
+		if now.Sub(checkedAt) >= sessionCheckInterval {
+			delete(c.checkedAt, k)
+		}
+	}
+	c.checkedAt[key] = now
+}
+
+// remove forgets that the session with the key was checked, e.g. because it was revoked.
+func (c *sessionChecks) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.checkedAt, key)
 }
 
 // SetSessionStore sets the backing store used for storing sessions on the server. It should be called exactly once.
@@ -222,6 +286,15 @@ func GetData(r *http.Request, key string, value interface{}) error {
 //
 // If expiryPeriod is 0, the default expiry period is used.
 func SetActor(w http.ResponseWriter, r *http.Request, actor *actor.Actor, expiryPeriod time.Duration) error {
+	// Revoke the session being replaced (e.g., when signing out), if any.
+	var prev *sessionInfo
+	if err := GetData(r, "actor", &prev); err == nil && prev != nil && prev.Key != "" {
+		if err := userSessions.DeleteByKey(r.Context(), prev.Key); err != nil {
+			return errors.WithMessage(err, "revoking session")
+		}
+		checkedSessions.remove(prev.Key)
+	}
+
 	var value *sessionInfo
 	if actor != nil {
 		if expiryPeriod == 0 {
@@ -231,11 +304,60 @@ func SetActor(w http.ResponseWriter, r *http.Request, actor *actor.Actor, expiry
 				expiryPeriod = defaultExpiryPeriod
 			}
 		}
-		value = &sessionInfo{Actor: actor, ExpiryPeriod: expiryPeriod, LastActive: time.Now()}
+		lastActive := time.Now()
+		key, err := registerSession(r, actor.UID, lastActive.Add(expiryPeriod))
+		if err != nil {
+			return err
+		}
+		value = &sessionInfo{Actor: actor, ExpiryPeriod: expiryPeriod, LastActive: lastActive, Key: key}
 	}
 	return SetData(w, r, "actor", value)
 }
 
+// registerSession adds a new session of the user that expires at expiresAt to the registry of
+// sessions, and returns its key.
+func registerSession(r *http.Request, userID int32, expiresAt time.Time) (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	s := &db.UserSession{
+		UserID:    userID,
+		Key:       hex.EncodeToString(b[:]),
+		UserAgent: r.UserAgent(),
+		IP:        remoteIP(r),
+		ExpiresAt: expiresAt,
+	}
+	if err := userSessions.Create(r.Context(), s); err != nil {
+		return "", errors.WithMessage(err, "registering session")
+	}
+	return s.Key, nil
+}
+
+// remoteIP returns the IP address of the client. It prefers the first address of the
+// X-Forwarded-For header, because Sourcegraph is usually deployed behind a reverse proxy. The
+// result is only informational (clients can set any X-Forwarded-For header).
+func remoteIP(r *http.Request) string {
+	if v := r.Header.Get("X-Forwarded-For"); v != "" {
+		return strings.TrimSpace(strings.Split(v, ",")[0])
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+type contextKey int
+
+const sessionKeyKey contextKey = iota
+
+// CurrentKey returns the registry key of the session that authenticated the request, or "" if the
+// request was not authenticated by a session cookie.
+func CurrentKey(ctx context.Context) string {
+	key, _ := ctx.Value(sessionKeyKey).(string)
+	return key
+}
+
 func hasSessionCookie(r *http.Request) bool {
 	c, _ := r.Cookie(cookieName)
 	return c != nil
@@ -343,8 +465,8 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 			return actor.WithActor(r.Context(), &actor.Actor{})
 		}
 
-		// Check that user still exists.
-		if _, err := db.Users.GetByID(r.Context(), info.Actor.UID); err != nil {
+		// Check that user still exists and is not deactivated.
+		if user, err := db.Users.GetByID(r.Context(), info.Actor.UID); err != nil {
 			if errcode.IsNotFound(err) {
 				_ = deleteSession(w, r) // clear the bad value
 			} else {
@@ -353,6 +475,36 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 				log15.Error("Error looking up user for session.", "uid", info.Actor.UID, "error", err)
 			}
 			return r.Context() // not authenticated
+		} else if user.DeactivatedAt != nil {
+			_ = deleteSession(w, r)
+			return r.Context() // not authenticated
+		}
+
+		// Check that the session was not revoked (at most once per sessionCheckInterval). Register
+		// sessions that were created before sessions were registered, so that they can be listed
+		// and revoked too.
+		if info.Key == "" {
+			key, err := registerSession(r, info.Actor.UID, info.LastActive.Add(info.ExpiryPeriod))
+			if err != nil {
+				log15.Error("error registering existing session", "uid", info.Actor.UID, "error", err)
+				return r.Context()
+			}
+			info.Key = key
+			if err := SetData(w, r, "actor", info); err != nil {
+				log15.Error("error saving registered session", "error", err)
+				return r.Context()
+			}
+		} else if !checkedSessions.recent(info.Key) {
+			if s, err := userSessions.GetByKey(r.Context(), info.Key); err != nil || s.UserID != info.Actor.UID {
+				if err == nil || errcode.IsNotFound(err) {
+					_ = deleteSession(w, r) // the session was revoked
+				} else {
+					// Don't delete session, since the error might be an ephemeral DB error.
+					log15.Error("Error looking up registered session.", "uid", info.Actor.UID, "error", err)
+				}
+				return r.Context() // not authenticated
+			}
+			checkedSessions.add(info.Key)
 		}
 
 		// Renew session
@@ -362,10 +514,13 @@ func authenticateByCookie(r *http.Request, w http.ResponseWriter) context.Contex
 				log15.Error("error renewing session", "error", err)
 				return r.Context()
 			}
+			if err := userSessions.Touch(r.Context(), info.Key, remoteIP(r), info.LastActive.Add(info.ExpiryPeriod)); err != nil {
+				log15.Warn("error updating last seen time of session", "error", err)
+			}
 		}
 
 		info.Actor.FromSessionCookie = true
-		return actor.WithActor(r.Context(), info.Actor)
+		return context.WithValue(actor.WithActor(r.Context(), info.Actor), sessionKeyKey, info.Key)
 	}
 
 	return r.Context()
@@ -289,3 +289,84 @@ func TestRecoverFromInvalidCookieValue(t *testing.T) {
 		t.Errorf("got cookies %+v, want %+v", cookies, want)
 	}
 }
+
+func TestRevokedSession(t *testing.T) {
+	cleanup := ResetMockSessionStore(t)
+	defer cleanup()
+
+	db.Mocks.Users.GetByID = func(ctx context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id}, nil
+	}
+	defer func() { db.Mocks = db.MockStores{} }()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	actr := &actor.Actor{UID: 123, FromSessionCookie: true}
+	if err := SetActor(w, req, actr, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	newAuthedReq := func() *http.Request {
+		r := httptest.NewRequest("GET", "/", nil)
+		for _, cookie := range w.Result().Cookies() {
+			r.AddCookie(cookie)
+		}
+		return r
+	}
+
+	// Check that the session was registered.
+	registry := userSessions.(*memUserSessions)
+	if len(registry.sessions) != 1 {
+		t.Fatalf("got %d registered sessions, want 1", len(registry.sessions))
+	}
+	var key string
+	for k, s := range registry.sessions {
+		key = k
+		if s.UserID != 123 || s.UserAgent != "test-agent" || s.IP != "192.0.2.1" {
+			t.Errorf("got registered session %+v", s)
+		}
+	}
+
+	ctx := authenticateByCookie(newAuthedReq(), httptest.NewRecorder())
+	if gotActor := actor.FromContext(ctx); !reflect.DeepEqual(gotActor, actr) {
+		t.Fatalf("got actor %+v, want %+v", gotActor, actr)
+	}
+	if got := CurrentKey(ctx); got != key {
+		t.Errorf("got current key %q, want %q", got, key)
+	}
+
+	// Revoke the session. The session was checked recently, so it is only signed out once it is
+	// checked against the registry again.
+	if err := userSessions.DeleteByKey(context.Background(), key); err != nil {
+		t.Fatal(err)
+	}
+	if gotActor := actor.FromContext(authenticateByCookie(newAuthedReq(), httptest.NewRecorder())); !gotActor.IsAuthenticated() {
+		t.Errorf("got unauthenticated actor, want the session to be checked at most once per %s", sessionCheckInterval)
+	}
+	checkedSessions.remove(key) // as if sessionCheckInterval passed
+	rr := httptest.NewRecorder()
+	if gotActor := actor.FromContext(authenticateByCookie(newAuthedReq(), rr)); gotActor.IsAuthenticated() {
+		t.Errorf("got actor %+v for revoked session, want unauthenticated", gotActor)
+	}
+	checkCookieDeleted(t, rr.Result())
+}
+
+func TestSetActorSignOutRevokesSession(t *testing.T) {
+	cleanup := ResetMockSessionStore(t)
+	defer cleanup()
+
+	w := httptest.NewRecorder()
+	if err := SetActor(w, httptest.NewRequest("GET", "/", nil), &actor.Actor{UID: 123}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		req.AddCookie(cookie)
+	}
+	if err := SetActor(httptest.NewRecorder(), req, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(userSessions.(*memUserSessions).sessions); n != 0 {
+		t.Errorf("got %d registered sessions after signing out, want 0", n)
+	}
+}
@@ -1,12 +1,16 @@
 package session
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gorilla/securecookie"
 	"github.com/gorilla/sessions"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 )
 
 func ResetMockSessionStore(t *testing.T) (cleanup func()) {
@@ -23,7 +27,51 @@ func ResetMockSessionStore(t *testing.T) (cleanup func()) {
 	}()
 
 	SetSessionStore(sessions.NewFilesystemStore(tempdir, securecookie.GenerateRandomKey(2048)))
+	userSessions = &memUserSessions{sessions: map[string]*db.UserSession{}}
+	checkedSessions = &sessionChecks{checkedAt: map[string]time.Time{}}
 	return func() {
 		os.RemoveAll(tempdir)
+		userSessions = db.UserSessions
 	}
 }
+
+// memUserSessions is an in-memory registry of sessions for tests.
+type memUserSessions struct {
+	mu       sync.Mutex
+	sessions map[string]*db.UserSession
+}
+
+func (m *memUserSessions) Create(ctx context.Context, s *db.UserSession) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s.ID = int64(len(m.sessions) + 1)
+	m.sessions[s.Key] = s
+	return nil
+}
+
+func (m *memUserSessions) GetByKey(ctx context.Context, key string) (*db.UserSession, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[key]
+	if !ok {
+		return nil, db.UserSessionNotFoundError{}
+	}
+	return s, nil
+}
+
+func (m *memUserSessions) Touch(ctx context.Context, key, ip string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[key]; ok {
+		s.IP = ip
+		s.ExpiresAt = expiresAt
+	}
+	return nil
+}
+
+func (m *memUserSessions) DeleteByKey(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, key)
+	return nil
+}
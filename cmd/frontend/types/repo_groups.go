@@ -0,0 +1,46 @@
+package types
+
+import "time"
+
+// RepoGroup is a named group of repositories, defined by rules that are
+// evaluated against the repositories known to Sourcegraph. It can be searched
+// with the repogroup: filter.
+type RepoGroup struct {
+	ID          int32 // the globally unique DB ID
+	Name        string
+	Description string
+	Rules       RepoGroupRules
+	OrgID       *int32 // if non-nil, the group is owned by this organization. Otherwise, it is owned by site admins.
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// RepoGroupRules are the rules a repository must match to be part of a
+// RepoGroup. Repositories must match all the rules that are set.
+type RepoGroupRules struct {
+	// ExternalServiceIDs, if set, restricts the group to repositories yielded
+	// by any of these external services.
+	ExternalServiceIDs []int64 `json:"externalServiceIDs,omitempty"`
+	// NamePattern, if set, is a regular expression repository names must match.
+	NamePattern string `json:"namePattern,omitempty"`
+	// Topics, if set, are code host topics all repositories must be labelled with.
+	Topics []string `json:"topics,omitempty"`
+	// Languages, if set, restricts the group to repositories whose primary
+	// language (as reported by the code host) is any of these.
+	Languages []string `json:"languages,omitempty"`
+	// Archived, if set, restricts the group to archived or non-archived repositories.
+	Archived *bool `json:"archived,omitempty"`
+	// Visibilities, if set, restricts the group to repositories with any of
+	// these code host visibilities (e.g. "public", "internal" or "private").
+	Visibilities []string `json:"visibilities,omitempty"`
+}
+
+// IsEmpty returns true if no rules are set, in which case all repositories match.
+func (r *RepoGroupRules) IsEmpty() bool {
+	return len(r.ExternalServiceIDs) == 0 &&
+		r.NamePattern == "" &&
+		len(r.Topics) == 0 &&
+		len(r.Languages) == 0 &&
+		r.Archived == nil &&
+		len(r.Visibilities) == 0
+}
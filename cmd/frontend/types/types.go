@@ -16,14 +16,26 @@ type RepoFields struct {
 	// Description is a brief description of the repository.
 	Description string
 
-	// DEPRECATED: this field is always empty for new repositories as of
-	// https://github.com/sourcegraph/sourcegraph/issues/2586. Do not use it.
-	//
-	// Language is the primary programming language used in this repository.
+	// Language is the primary programming language used in this repository, as
+	// reported by the code host. It is empty for code hosts that don't detect
+	// languages.
 	Language string
 
 	// Fork is whether this repository is a fork of another repository.
 	Fork bool
+
+	// Topics are the topics (or tags) the repository is labelled with on the code host.
+	Topics []string
+
+	// Stars is the number of stars (or likes) the repository has on the code host.
+	Stars int
+
+	// DefaultBranch is the name of the repository's default branch on the code host.
+	DefaultBranch string
+
+	// Visibility is the code host specific visibility of the repository (e.g.
+	// "public", "internal" or "private").
+	Visibility string
 }
 
 // Repo represents a source code repository.
@@ -66,6 +78,21 @@ type ExternalService struct {
 	DeletedAt   *time.Time
 }
 
+// ExternalServiceSyncRun records the outcome of syncing the repositories of an
+// external service once.
+type ExternalServiceSyncRun struct {
+	ID                int64
+	ExternalServiceID int64
+	StartedAt         time.Time
+	FinishedAt        time.Time
+	Added             int32
+	Deleted           int32
+	Modified          int32
+	Unmodified        int32
+	RateLimitWait     time.Duration
+	Error             string
+}
+
 type GlobalState struct {
 	SiteID      string
 	Initialized bool // whether the initial site admin account has been created
@@ -82,6 +109,9 @@ type User struct {
 	SiteAdmin   bool
 	BuiltinAuth bool
 	Tags        []string
+	// DeactivatedAt is when the user was deactivated, or nil if the user is active. Deactivated
+	// users can't sign in or use access tokens.
+	DeactivatedAt *time.Time
 }
 
 type Org struct {
@@ -120,10 +120,11 @@ func (s *AWSCodeCommitSource) makeRepo(r *awscodecommit.Repository) (*Repo, erro
 	serviceID := awscodecommit.ServiceID(s.awsPartition, s.awsRegion, r.AccountID)
 
 	return &Repo{
-		Name:         string(reposource.AWSRepoName(s.config.RepositoryPathPattern, r.Name)),
-		URI:          string(reposource.AWSRepoName("", r.Name)),
-		ExternalRepo: awscodecommit.ExternalRepoSpec(r, serviceID),
-		Description:  r.Description,
+		Name:          string(reposource.AWSRepoName(s.config.RepositoryPathPattern, r.Name)),
+		URI:           string(reposource.AWSRepoName("", r.Name)),
+		ExternalRepo:  awscodecommit.ExternalRepoSpec(r, serviceID),
+		Description:   r.Description,
+		DefaultBranch: r.DefaultBranch,
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
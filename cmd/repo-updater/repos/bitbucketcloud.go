@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"strconv"
 	"sync"
 
 	"github.com/inconshreveable/log15"
@@ -14,6 +15,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -88,6 +90,142 @@ func (s BitbucketCloudSource) ExternalServices() ExternalServices {
 	return ExternalServices{s.svc}
 }
 
+var _ ChangesetSource = BitbucketCloudSource{}
+
+// CreateChangeset creates a Bitbucket Cloud pull request. If an open pull
+// request from the same branch already exists, *Changeset will be populated
+// with it and the return value will be true.
+func (s BitbucketCloudSource) CreateChangeset(ctx context.Context, c *Changeset) (bool, error) {
+	var exists bool
+	repo := c.Repo.Metadata.(*bitbucketcloud.Repo)
+	source := git.AbbreviateRef(c.HeadRef)
+	target := git.AbbreviateRef(c.BaseRef)
+
+	// Bitbucket Cloud doesn't reject duplicate pull requests, so we look for
+	// an existing one first.
+	pr, err := s.client.GetOpenPullRequestByRefs(ctx, repo, source, target)
+	switch err {
+	case nil:
+		exists = true
+	case bitbucketcloud.ErrPullRequestNotFound:
+		pr, err = s.client.CreatePullRequest(ctx, repo, bitbucketcloud.CreatePullRequestOpts{
+			Title:        c.Title,
+			Description:  c.Body,
+			SourceBranch: source,
+			TargetBranch: target,
+		})
+		if err != nil {
+			return exists, errors.Wrap(err, "creating pull request")
+		}
+	default:
+		return exists, errors.Wrap(err, "fetching existing pull request")
+	}
+
+	if err := s.loadPullRequestData(ctx, repo, pr); err != nil {
+		return false, errors.Wrap(err, "loading extra metadata")
+	}
+	if err := c.SetMetadata(pr); err != nil {
+		return false, errors.Wrap(err, "setting changeset metadata")
+	}
+
+	return exists, nil
+}
+
+// CloseChangeset declines the pull request on Bitbucket Cloud and updates the
+// Metadata column in the *campaigns.Changeset to the newly declined pull
+// request.
+func (s BitbucketCloudSource) CloseChangeset(ctx context.Context, c *Changeset) error {
+	pr, ok := c.Changeset.Metadata.(*bitbucketcloud.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a Bitbucket Cloud pull request")
+	}
+	repo := c.Repo.Metadata.(*bitbucketcloud.Repo)
+
+	declined, err := s.client.DeclinePullRequest(ctx, repo, pr)
+	if err != nil {
+		return err
+	}
+	declined.Activities, declined.Statuses = pr.Activities, pr.Statuses
+
+	c.Changeset.Metadata = declined
+	return nil
+}
+
+// LoadChangesets loads the latest state of the given Changesets from Bitbucket
+// Cloud.
+func (s BitbucketCloudSource) LoadChangesets(ctx context.Context, cs ...*Changeset) error {
+	var notFound []*Changeset
+
+	for i := range cs {
+		repo := cs[i].Repo.Metadata.(*bitbucketcloud.Repo)
+		id, err := strconv.ParseInt(cs[i].ExternalID, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "parsing changeset external id")
+		}
+
+		pr, err := s.client.GetPullRequest(ctx, repo, id)
+		if err != nil {
+			if bitbucketcloud.IsNotFound(err) {
+				notFound = append(notFound, cs[i])
+				if cs[i].Changeset.Metadata == nil {
+					cs[i].Changeset.Metadata = &bitbucketcloud.PullRequest{ID: id}
+				}
+				continue
+			}
+			return err
+		}
+
+		if err := s.loadPullRequestData(ctx, repo, pr); err != nil {
+			return errors.Wrap(err, "loading pull request data")
+		}
+		if err := cs[i].SetMetadata(pr); err != nil {
+			return errors.Wrap(err, "setting changeset metadata")
+		}
+	}
+
+	if len(notFound) > 0 {
+		return ChangesetsNotFoundError{Changesets: notFound}
+	}
+
+	return nil
+}
+
+// loadPullRequestData loads the activities and commit statuses of the pull
+// request, which are the events of the changeset.
+func (s BitbucketCloudSource) loadPullRequestData(ctx context.Context, repo *bitbucketcloud.Repo, pr *bitbucketcloud.PullRequest) error {
+	if err := s.client.LoadPullRequestActivities(ctx, repo, pr); err != nil {
+		return errors.Wrap(err, "loading pull request activities")
+	}
+
+	if err := s.client.LoadPullRequestStatuses(ctx, repo, pr); err != nil {
+		return errors.Wrap(err, "loading pull request statuses")
+	}
+
+	return nil
+}
+
+// UpdateChangeset updates the pull request on Bitbucket Cloud.
+func (s BitbucketCloudSource) UpdateChangeset(ctx context.Context, c *Changeset) error {
+	pr, ok := c.Changeset.Metadata.(*bitbucketcloud.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a Bitbucket Cloud pull request")
+	}
+	repo := c.Repo.Metadata.(*bitbucketcloud.Repo)
+
+	updated, err := s.client.UpdatePullRequest(ctx, repo, pr, bitbucketcloud.UpdatePullRequestOpts{
+		Title:        c.Title,
+		Description:  c.Body,
+		TargetBranch: git.AbbreviateRef(c.BaseRef),
+	})
+	if err != nil {
+		return err
+	}
+	updated.Activities, updated.Statuses = pr.Activities, pr.Statuses
+
+	c.Changeset.Metadata = updated
+	return nil
+}
+
 func (s BitbucketCloudSource) makeRepo(r *bitbucketcloud.Repo) *Repo {
 	host, err := url.Parse(s.config.Url)
 	if err != nil {
@@ -96,6 +234,11 @@ func (s BitbucketCloudSource) makeRepo(r *bitbucketcloud.Repo) *Repo {
 	}
 	host = extsvc.NormalizeBaseURL(host)
 
+	var defaultBranch string
+	if r.MainBranch != nil {
+		defaultBranch = r.MainBranch.Name
+	}
+
 	urn := s.svc.URN()
 	return &Repo{
 		Name: string(reposource.BitbucketCloudRepoName(
@@ -113,9 +256,12 @@ func (s BitbucketCloudSource) makeRepo(r *bitbucketcloud.Repo) *Repo {
 			ServiceType: bitbucketcloud.ServiceType,
 			ServiceID:   host.String(),
 		},
-		Description: r.Description,
-		Fork:        r.Parent != nil,
-		Private:     r.IsPrivate,
+		Description:   r.Description,
+		Language:      r.Language,
+		Fork:          r.Parent != nil,
+		Private:       r.IsPrivate,
+		DefaultBranch: defaultBranch,
+		Visibility:    visibility(r.IsPrivate),
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
@@ -5,15 +5,21 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/testutil"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
@@ -108,6 +114,313 @@ func TestBitbucketCloudSource_ListRepos(t *testing.T) {
 	}
 }
 
+func TestBitbucketCloudSource_CreateChangeset(t *testing.T) {
+	testCases := []struct {
+		name     string
+		existing bool
+		exists   bool
+		requests []string
+	}{
+		{
+			name: "success",
+			requests: []string{
+				"GET /2.0/repositories/sourcegraph/automation-testing/pullrequests",
+				"POST /2.0/repositories/sourcegraph/automation-testing/pullrequests",
+				"GET /2.0/repositories/sourcegraph/automation-testing/pullrequests/1/activity",
+				"GET /2.0/repositories/sourcegraph/automation-testing/pullrequests/1/statuses",
+			},
+		},
+		{
+			name:     "already exists",
+			existing: true,
+			exists:   true,
+			requests: []string{
+				"GET /2.0/repositories/sourcegraph/automation-testing/pullrequests",
+				"GET /2.0/repositories/sourcegraph/automation-testing/pullrequests/1/activity",
+				"GET /2.0/repositories/sourcegraph/automation-testing/pullrequests/1/statuses",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			api := newFakeBitbucketCloudAPI()
+			if tc.existing {
+				api.prs[1] = &bitbucketcloud.PullRequest{
+					ID:          1,
+					Title:       "Existing",
+					State:       bitbucketcloud.PullRequestStateOpen,
+					Source:      bitbucketcloud.PullRequestEndpoint{Branch: bitbucketcloud.PullRequestBranch{Name: "test-pr-6"}},
+					Destination: bitbucketcloud.PullRequestEndpoint{Branch: bitbucketcloud.PullRequestBranch{Name: "master"}},
+				}
+			}
+			src, done := newTestBitbucketCloudSource(t, api)
+			defer done()
+
+			cs := &Changeset{
+				Title:     "This is a test PR",
+				Body:      "This is the description of the test PR",
+				HeadRef:   "refs/heads/test-pr-6",
+				BaseRef:   "refs/heads/master",
+				Repo:      &Repo{Metadata: &bitbucketcloud.Repo{FullName: "sourcegraph/automation-testing"}},
+				Changeset: &campaigns.Changeset{},
+			}
+
+			exists, err := src.CreateChangeset(context.Background(), cs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have, want := exists, tc.exists; have != want {
+				t.Errorf("exists:\nhave: %t\nwant: %t", have, want)
+			}
+			if diff := cmp.Diff(api.requests, tc.requests); diff != "" {
+				t.Errorf("wrong requests: %s", diff)
+			}
+			if have, want := api.queries[0], `source.branch.name = "test-pr-6" AND destination.branch.name = "master" AND state = "OPEN"`; have != want {
+				t.Errorf("wrong query:\nhave: %s\nwant: %s", have, want)
+			}
+
+			pr, ok := cs.Changeset.Metadata.(*bitbucketcloud.PullRequest)
+			if !ok {
+				t.Fatal("Metadata does not contain PR")
+			}
+			if want := api.prs[1]; pr.Title != want.Title || pr.Source.Branch.Name != "test-pr-6" || pr.Destination.Branch.Name != "master" {
+				t.Errorf("wrong pull request: %+v", pr)
+			}
+			if len(pr.Activities) != 1 || len(pr.Statuses) != 1 {
+				t.Errorf("pull request data not loaded: %+v", pr)
+			}
+			if have, want := cs.Changeset.ExternalID, "1"; have != want {
+				t.Errorf("external ID:\nhave: %q\nwant: %q", have, want)
+			}
+		})
+	}
+}
+
+func TestBitbucketCloudSource_CloseChangeset(t *testing.T) {
+	api := newFakeBitbucketCloudAPI()
+	api.prs[1] = &bitbucketcloud.PullRequest{ID: 1, State: bitbucketcloud.PullRequestStateOpen}
+	src, done := newTestBitbucketCloudSource(t, api)
+	defer done()
+
+	activities := []*bitbucketcloud.Activity{{Kind: bitbucketcloud.ActivityKindApproved}}
+	cs := &Changeset{
+		Repo:      &Repo{Metadata: &bitbucketcloud.Repo{FullName: "sourcegraph/automation-testing"}},
+		Changeset: &campaigns.Changeset{Metadata: &bitbucketcloud.PullRequest{ID: 1, Activities: activities}},
+	}
+	if err := src.CloseChangeset(context.Background(), cs); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"POST /2.0/repositories/sourcegraph/automation-testing/pullrequests/1/decline"}
+	if diff := cmp.Diff(api.requests, want); diff != "" {
+		t.Errorf("wrong requests: %s", diff)
+	}
+
+	pr := cs.Changeset.Metadata.(*bitbucketcloud.PullRequest)
+	if pr.State != bitbucketcloud.PullRequestStateDeclined {
+		t.Errorf("pull request not declined: %+v", pr)
+	}
+	if !reflect.DeepEqual(pr.Activities, activities) {
+		t.Errorf("activities not kept: %+v", pr)
+	}
+}
+
+func TestBitbucketCloudSource_UpdateChangeset(t *testing.T) {
+	api := newFakeBitbucketCloudAPI()
+	api.prs[1] = &bitbucketcloud.PullRequest{ID: 1, Title: "Old title", State: bitbucketcloud.PullRequestStateOpen}
+	src, done := newTestBitbucketCloudSource(t, api)
+	defer done()
+
+	cs := &Changeset{
+		Title:     "This is a new title",
+		Body:      "This is a new body",
+		BaseRef:   "refs/heads/main",
+		Repo:      &Repo{Metadata: &bitbucketcloud.Repo{FullName: "sourcegraph/automation-testing"}},
+		Changeset: &campaigns.Changeset{Metadata: &bitbucketcloud.PullRequest{ID: 1, Title: "Old title"}},
+	}
+	if err := src.UpdateChangeset(context.Background(), cs); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"PUT /2.0/repositories/sourcegraph/automation-testing/pullrequests/1"}
+	if diff := cmp.Diff(api.requests, want); diff != "" {
+		t.Errorf("wrong requests: %s", diff)
+	}
+
+	pr := cs.Changeset.Metadata.(*bitbucketcloud.PullRequest)
+	if pr.Title != "This is a new title" || pr.Description != "This is a new body" || pr.Destination.Branch.Name != "main" {
+		t.Errorf("wrong pull request: %+v", pr)
+	}
+}
+
+func TestBitbucketCloudSource_LoadChangesets(t *testing.T) {
+	api := newFakeBitbucketCloudAPI()
+	api.prs[1] = &bitbucketcloud.PullRequest{ID: 1, Title: "First", Source: bitbucketcloud.PullRequestEndpoint{Branch: bitbucketcloud.PullRequestBranch{Name: "first"}}}
+	api.prs[2] = &bitbucketcloud.PullRequest{ID: 2, Title: "Second", Source: bitbucketcloud.PullRequestEndpoint{Branch: bitbucketcloud.PullRequestBranch{Name: "second"}}}
+
+	repo := &Repo{Metadata: &bitbucketcloud.Repo{FullName: "sourcegraph/automation-testing"}}
+
+	testCases := []struct {
+		name string
+		cs   []*Changeset
+		err  string
+	}{
+		{
+			name: "found",
+			cs: []*Changeset{
+				{Repo: repo, Changeset: &campaigns.Changeset{ExternalID: "1"}},
+				{Repo: repo, Changeset: &campaigns.Changeset{ExternalID: "2"}},
+			},
+		},
+		{
+			name: "not found",
+			cs: []*Changeset{
+				{Repo: repo, Changeset: &campaigns.Changeset{ExternalID: "1"}},
+				{Repo: repo, Changeset: &campaigns.Changeset{ExternalID: "3"}},
+			},
+			err: `Changeset with external ID "3" not found`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			src, done := newTestBitbucketCloudSource(t, api)
+			defer done()
+
+			if tc.err == "" {
+				tc.err = "<nil>"
+			}
+			err := src.LoadChangesets(context.Background(), tc.cs...)
+			if have, want := fmt.Sprint(err), tc.err; have != want {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+
+			for _, cs := range tc.cs {
+				pr := cs.Changeset.Metadata.(*bitbucketcloud.PullRequest)
+				if want, ok := api.prs[pr.ID]; ok {
+					if pr.Title != want.Title || len(pr.Activities) != 1 || len(pr.Statuses) != 1 || cs.Changeset.ExternalBranch != want.Source.Branch.Name {
+						t.Errorf("wrong metadata for %s: %+v", cs.Changeset.ExternalID, pr)
+					}
+				} else if have, want := fmt.Sprint(pr.ID), cs.Changeset.ExternalID; have != want {
+					t.Errorf("wrong placeholder for missing pull request. want=%s, have=%s", want, have)
+				}
+			}
+		})
+	}
+}
+
+// newTestBitbucketCloudSource returns a BitbucketCloudSource using the fake
+// API, and a function that stops the server of the API.
+func newTestBitbucketCloudSource(t *testing.T, api *fakeBitbucketCloudAPI) (*BitbucketCloudSource, func()) {
+	t.Helper()
+
+	srv := httptest.NewServer(api)
+
+	svc := &ExternalService{
+		Kind: "BITBUCKETCLOUD",
+		Config: marshalJSON(t, &schema.BitbucketCloudConnection{
+			Url:         "https://bitbucket.org",
+			ApiURL:      srv.URL,
+			Username:    "alice",
+			AppPassword: "secret",
+		}),
+	}
+	src, err := NewBitbucketCloudSource(svc, httpcli.NewFactory(nil))
+	if err != nil {
+		srv.Close()
+		t.Fatal(err)
+	}
+	return src, srv.Close
+}
+
+// fakeBitbucketCloudAPI is a fake of the pull request API of Bitbucket Cloud.
+// Every pull request has one approval and one commit status.
+type fakeBitbucketCloudAPI struct {
+	prs      map[int64]*bitbucketcloud.PullRequest
+	requests []string
+	queries  []string
+}
+
+func newFakeBitbucketCloudAPI() *fakeBitbucketCloudAPI {
+	return &fakeBitbucketCloudAPI{prs: map[int64]*bitbucketcloud.PullRequest{}}
+}
+
+func (a *fakeBitbucketCloudAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.requests = append(a.requests, r.Method+" "+r.URL.Path)
+
+	var payload struct {
+		Title       string                             `json:"title"`
+		Description string                             `json:"description"`
+		Source      bitbucketcloud.PullRequestEndpoint `json:"source"`
+		Destination bitbucketcloud.PullRequestEndpoint `json:"destination"`
+	}
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/2.0/repositories/sourcegraph/automation-testing/pullrequests"), "/")
+	var pr *bitbucketcloud.PullRequest
+	if len(parts) > 1 {
+		id, _ := strconv.ParseInt(parts[1], 10, 64)
+		if pr = a.prs[id]; pr == nil {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	var result interface{}
+	switch {
+	case len(parts) == 1 && r.Method == "GET":
+		q := r.URL.Query().Get("q")
+		a.queries = append(a.queries, q)
+		values := []*bitbucketcloud.PullRequest{}
+		for _, pr := range a.prs {
+			if strings.Contains(q, fmt.Sprintf("source.branch.name = %q", pr.Source.Branch.Name)) {
+				values = append(values, pr)
+			}
+		}
+		result = map[string]interface{}{"values": values}
+	case len(parts) == 1 && r.Method == "POST":
+		pr = &bitbucketcloud.PullRequest{
+			ID:          int64(len(a.prs) + 1),
+			Title:       payload.Title,
+			Description: payload.Description,
+			State:       bitbucketcloud.PullRequestStateOpen,
+			Source:      payload.Source,
+			Destination: payload.Destination,
+		}
+		a.prs[pr.ID] = pr
+		result = pr
+	case len(parts) == 2 && r.Method == "GET":
+		result = pr
+	case len(parts) == 2 && r.Method == "PUT":
+		pr.Title, pr.Description, pr.Destination = payload.Title, payload.Description, payload.Destination
+		result = pr
+	case len(parts) == 3 && parts[2] == "decline":
+		pr.State = bitbucketcloud.PullRequestStateDeclined
+		result = pr
+	case len(parts) == 3 && parts[2] == "activity":
+		result = map[string]interface{}{"values": []interface{}{
+			map[string]interface{}{"approval": map[string]interface{}{"date": "2020-04-01T10:00:00Z", "user": map[string]string{"uuid": "{user-uuid}"}}},
+		}}
+	case len(parts) == 3 && parts[2] == "statuses":
+		result = map[string]interface{}{"values": []interface{}{
+			map[string]string{"key": "ci", "state": "SUCCESSFUL"},
+		}}
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(result)
+}
+
 func TestBitbucketCloudSource_makeRepo(t *testing.T) {
 	b, err := ioutil.ReadFile(filepath.Join("testdata", "bitbucketcloud-repos.json"))
 	if err != nil {
@@ -11,6 +11,7 @@ import (
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
@@ -85,11 +86,15 @@ func newBitbucketServerSource(svc *ExternalService, c *schema.BitbucketServerCon
 
 // ListRepos returns all BitbucketServer repositories accessible to all connections configured
 // in Sourcegraph via the external services configuration.
+//
+// BitbucketServerSource isn't an IncrementalSource: Bitbucket Server's REST
+// API can't list the repositories changed since a point in time, so every
+// sync lists them in full.
 func (s BitbucketServerSource) ListRepos(ctx context.Context, results chan SourceResult) {
 	s.listAllRepos(ctx, results)
 }
 
-var _ ChangesetSource = BitbucketServerSource{}
+var _ ChangesetMerger = BitbucketServerSource{}
 
 // CreateChangeset creates the given *Changeset in the code host.
 func (s BitbucketServerSource) CreateChangeset(ctx context.Context, c *Changeset) (bool, error) {
@@ -149,6 +154,37 @@ func (s BitbucketServerSource) CloseChangeset(ctx context.Context, c *Changeset)
 	return nil
 }
 
+// bitbucketServerMergeStrategies maps merge methods to the IDs of the
+// corresponding Bitbucket Server merge strategies.
+var bitbucketServerMergeStrategies = map[campaigns.ChangesetMergeMethod]string{
+	campaigns.ChangesetMergeMethodMerge:  "no-ff",
+	campaigns.ChangesetMergeMethodSquash: "squash",
+	campaigns.ChangesetMergeMethodRebase: "rebase-no-ff",
+}
+
+// MergeChangeset merges the given *Changeset on the code host and updates the
+// Metadata column in the *campaigns.Changeset to the newly merged pull request.
+func (s BitbucketServerSource) MergeChangeset(ctx context.Context, c *Changeset, method campaigns.ChangesetMergeMethod) error {
+	pr, ok := c.Changeset.Metadata.(*bitbucketserver.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a Bitbucket Server pull request")
+	}
+
+	strategy, ok := bitbucketServerMergeStrategies[method]
+	if !ok {
+		return errors.Errorf("unsupported merge method %q", method)
+	}
+
+	err := s.client.MergePullRequest(ctx, pr, strategy)
+	if err != nil {
+		return err
+	}
+
+	c.Changeset.Metadata = pr
+
+	return nil
+}
+
 // LoadChangesets loads the latest state of the given Changesets from the codehost.
 func (s BitbucketServerSource) LoadChangesets(ctx context.Context, cs ...*Changeset) error {
 	var notFound []*Changeset
@@ -310,6 +346,7 @@ func (s BitbucketServerSource) makeRepo(repo *bitbucketserver.Repo, isArchived b
 		Fork:        repo.Origin != nil,
 		Archived:    isArchived,
 		Private:     !repo.Public,
+		Visibility:  visibility(!repo.Public),
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
@@ -5,13 +5,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	multierror "github.com/hashicorp/go-multierror"
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/conf/reposource"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
@@ -33,13 +36,14 @@ type GithubSource struct {
 	githubDotCom    bool
 	baseURL         *url.URL
 	client          *github.Client
-	// searchClient is for using the GitHub search API, which has an independent
-	// rate limit much lower than non-search API requests.
-	searchClient *github.Client
 
 	// originalHostname is the hostname of config.Url (differs from client APIURL, whose host is api.github.com
 	// for an originalHostname of github.com).
 	originalHostname string
+
+	// since, when non-zero, restricts listings to the repositories pushed to
+	// since then. See ListReposSince.
+	since time.Time
 }
 
 // NewGithubSource returns a new GithubSource from the given external service.
@@ -114,7 +118,6 @@ func newGithubSource(svc *ExternalService, c *schema.GitHubConnection, cf *httpc
 		baseURL:          baseURL,
 		githubDotCom:     githubDotCom,
 		client:           github.NewClient(apiURL, c.Token, cli),
-		searchClient:     github.NewClient(apiURL, c.Token, cli),
 		originalHostname: originalHostname,
 	}, nil
 }
@@ -146,12 +149,61 @@ func (s GithubSource) ListRepos(ctx context.Context, results chan SourceResult)
 	}
 }
 
+// githubCursorOverlap is subtracted from the time recorded in a cursor to
+// tolerate clock skew between Sourcegraph and the GitHub instance.
+const githubCursorOverlap = 5 * time.Minute
+
+// ListReposSince lists the GitHub repositories that were pushed to since the
+// given cursor, which is the time the previous listing started at.
+//
+// Organization, user and affiliated repositories are listed most recently
+// pushed first, stopping at the first one pushed to before the cursor. Search
+// queries are restricted with a pushed qualifier. Explicitly configured repos
+// and the "public" repository query can't be filtered by push time and are
+// always listed in full. Changes that don't involve a push, like renames or
+// topic edits, are picked up by the next full listing.
+func (s GithubSource) ListReposSince(ctx context.Context, cursor string, results chan SourceResult) (next string) {
+	next = time.Now().UTC().Format(time.RFC3339)
+
+	if cursor != "" {
+		t, err := time.Parse(time.RFC3339, cursor)
+		if err != nil {
+			results <- SourceResult{Source: s, Err: errors.Wrapf(err, "invalid GitHub sync cursor %q", cursor)}
+			return cursor
+		}
+		s.since = t.Add(-githubCursorOverlap)
+	}
+
+	s.ListRepos(ctx, results)
+	return next
+}
+
+// pushedSince returns the leading repos of a page of repos listed most
+// recently pushed first that were pushed to since s.since, and whether the
+// listing should continue with the next page.
+func (s *GithubSource) pushedSince(repos []*github.Repository, hasNext bool) ([]*github.Repository, bool) {
+	for i, r := range repos {
+		if r.PushedAt.Before(s.since) {
+			return repos[:i], false
+		}
+	}
+	return repos, hasNext
+}
+
 // ExternalServices returns a singleton slice containing the external service.
 func (s GithubSource) ExternalServices() ExternalServices {
 	return ExternalServices{s.svc}
 }
 
-var _ ChangesetSource = GithubSource{}
+var (
+	_ IncrementalSource          = GithubSource{}
+	_ ChangesetMerger            = GithubSource{}
+	_ ChangesetReviewRequester   = GithubSource{}
+	_ ChangesetCommenter         = GithubSource{}
+	_ ChangesetLabeler           = GithubSource{}
+	_ ChangesetReviewRerequester = GithubSource{}
+	_ ChangesetCheckRerunner     = GithubSource{}
+)
 
 // CreateChangeset creates the given *Changeset in the code host.
 func (s GithubSource) CreateChangeset(ctx context.Context, c *Changeset) (bool, error) {
@@ -206,6 +258,235 @@ func (s GithubSource) CloseChangeset(ctx context.Context, c *Changeset) error {
 	return nil
 }
 
+// MergeChangeset merges the given *Changeset on the code host and updates the
+// Metadata column in the *campaigns.Changeset to the newly merged pull request.
+func (s GithubSource) MergeChangeset(ctx context.Context, c *Changeset, method campaigns.ChangesetMergeMethod) error {
+	pr, ok := c.Changeset.Metadata.(*github.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitHub pull request")
+	}
+
+	// Github's merge methods have the same names as ours.
+	err := s.client.MergePullRequest(ctx, pr, string(method))
+	if err != nil {
+		return err
+	}
+
+	c.Changeset.Metadata = pr
+
+	return nil
+}
+
+// RequestReviews requests reviews of the pull request from the given users
+// and teams and assigns it to the given users. E-mail addresses, teams of
+// other organizations than the repository's owner and the author of the pull
+// request are skipped, since GitHub can't request reviews from them. So are
+// users and teams GitHub rejects, such as users who aren't collaborators of
+// the repository. The pull request is assigned even if requesting the reviews
+// fails.
+func (s GithubSource) RequestReviews(ctx context.Context, c *Changeset, reviewers, assignees []string) error {
+	pr, ok := c.Changeset.Metadata.(*github.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitHub pull request")
+	}
+
+	repo := c.Repo.Metadata.(*github.Repository)
+	pr.RepoWithOwner = repo.NameWithOwner
+	org := strings.SplitN(repo.NameWithOwner, "/", 2)[0]
+
+	var users, teams []string
+	for _, r := range reviewers {
+		login, team := parseGithubOwner(r)
+		switch {
+		case team != "":
+			if strings.EqualFold(login, org) {
+				teams = append(teams, team)
+			}
+		case login != "" && !strings.EqualFold(login, pr.Author.Login):
+			users = append(users, login)
+		}
+	}
+
+	var errs *multierror.Error
+	if len(users) > 0 || len(teams) > 0 {
+		if err := s.requestReviews(ctx, pr, users, teams); err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, "requesting reviews"))
+		}
+	}
+
+	var logins []string
+	for _, a := range assignees {
+		if login, team := parseGithubOwner(a); login != "" && team == "" {
+			logins = append(logins, login)
+		}
+	}
+
+	if len(logins) > 0 {
+		if err := s.client.AddAssignees(ctx, pr, logins); err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, "adding assignees"))
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// requestReviews requests reviews of the pull request from the given users and
+// teams. GitHub rejects the whole request with 422 Unprocessable Entity if any
+// of them can't be requested, in which case they are requested one by one and
+// the rejected ones are skipped.
+func (s GithubSource) requestReviews(ctx context.Context, pr *github.PullRequest, users, teams []string) error {
+	err := s.client.RequestReviews(ctx, pr, users, teams)
+	if github.HTTPErrorCode(err) != http.StatusUnprocessableEntity {
+		return err
+	}
+
+	for _, u := range users {
+		err := s.client.RequestReviews(ctx, pr, []string{u}, nil)
+		if err != nil && github.HTTPErrorCode(err) != http.StatusUnprocessableEntity {
+			return err
+		}
+	}
+	for _, t := range teams {
+		err := s.client.RequestReviews(ctx, pr, nil, []string{t})
+		if err != nil && github.HTTPErrorCode(err) != http.StatusUnprocessableEntity {
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateComment comments on the pull request.
+func (s GithubSource) CreateComment(ctx context.Context, c *Changeset, body string) error {
+	pr, ok := c.Changeset.Metadata.(*github.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitHub pull request")
+	}
+	pr.RepoWithOwner = c.Repo.Metadata.(*github.Repository).NameWithOwner
+
+	return s.client.CreateComment(ctx, pr, body)
+}
+
+// AddLabels adds the given labels to the pull request, creating the ones that
+// don't exist in the repository yet.
+func (s GithubSource) AddLabels(ctx context.Context, c *Changeset, labels []string) error {
+	pr, ok := c.Changeset.Metadata.(*github.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitHub pull request")
+	}
+	pr.RepoWithOwner = c.Repo.Metadata.(*github.Repository).NameWithOwner
+
+	return s.client.AddLabels(ctx, pr, labels)
+}
+
+// RemoveLabels removes the given labels from the pull request.
+func (s GithubSource) RemoveLabels(ctx context.Context, c *Changeset, labels []string) error {
+	pr, ok := c.Changeset.Metadata.(*github.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitHub pull request")
+	}
+	pr.RepoWithOwner = c.Repo.Metadata.(*github.Repository).NameWithOwner
+
+	for _, label := range labels {
+		if err := s.client.RemoveLabel(ctx, pr, label); err != nil && !github.IsNotFound(err) {
+			return errors.Wrapf(err, "removing label %q", label)
+		}
+	}
+	return nil
+}
+
+// RerequestReviews requests reviews of the pull request again from the users
+// whose latest review didn't approve it.
+func (s GithubSource) RerequestReviews(ctx context.Context, c *Changeset) error {
+	pr, ok := c.Changeset.Metadata.(*github.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitHub pull request")
+	}
+	pr.RepoWithOwner = c.Repo.Metadata.(*github.Repository).NameWithOwner
+
+	reviewers := githubReviewersToRerequest(pr)
+	if len(reviewers) == 0 {
+		return nil
+	}
+
+	return s.client.RequestReviews(ctx, pr, reviewers, nil)
+}
+
+// githubReviewersToRerequest returns the logins of the users who reviewed the
+// pull request and didn't approve it in their latest review, in the order of
+// their first review.
+func githubReviewersToRerequest(pr *github.PullRequest) []string {
+	var logins []string
+	latest := map[string]string{}
+	for _, item := range pr.TimelineItems {
+		review, ok := item.Item.(*github.PullRequestReview)
+		if !ok || review.State == "PENDING" {
+			continue
+		}
+		login := review.Author.Login
+		if login == "" || strings.EqualFold(login, pr.Author.Login) {
+			continue
+		}
+		if _, ok := latest[login]; !ok {
+			logins = append(logins, login)
+		}
+		latest[login] = review.State
+	}
+
+	reviewers := logins[:0]
+	for _, login := range logins {
+		if latest[login] != "APPROVED" {
+			reviewers = append(reviewers, login)
+		}
+	}
+	return reviewers
+}
+
+// RerunChecks re-runs the check suites of the pull request's head commit that
+// didn't succeed. Checks reported as commit statuses can't be re-run.
+func (s GithubSource) RerunChecks(ctx context.Context, c *Changeset) error {
+	pr, ok := c.Changeset.Metadata.(*github.PullRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitHub pull request")
+	}
+	repo := c.Repo.Metadata.(*github.Repository)
+
+	if len(pr.Commits.Nodes) == 0 {
+		return errors.New("pull request has no commits")
+	}
+	head := pr.Commits.Nodes[len(pr.Commits.Nodes)-1].Commit
+
+	var rerun int
+	for _, suite := range head.CheckSuites.Nodes {
+		switch suite.Conclusion {
+		case "FAILURE", "TIMED_OUT", "CANCELLED", "ACTION_REQUIRED", "STALE":
+		default:
+			continue
+		}
+		if err := s.client.RerequestCheckSuite(ctx, repo.ID, suite.ID); err != nil {
+			return errors.Wrapf(err, "re-running check suite %s", suite.ID)
+		}
+		rerun++
+	}
+
+	if rerun == 0 {
+		return errors.New("pull request has no failed check suites to re-run")
+	}
+	return nil
+}
+
+// parseGithubOwner parses a code owner of the form "@login" or "@org/team".
+// It returns an empty login for e-mail addresses.
+func parseGithubOwner(owner string) (login, team string) {
+	if !strings.HasPrefix(owner, "@") {
+		return "", ""
+	}
+	owner = strings.TrimPrefix(owner, "@")
+	if i := strings.Index(owner, "/"); i >= 0 {
+		return owner[:i], owner[i+1:]
+	}
+	return owner, ""
+}
+
 // LoadChangesets loads the latest state of the given Changesets from the codehost.
 func (s GithubSource) LoadChangesets(ctx context.Context, cs ...*Changeset) error {
 	prs := make([]*github.PullRequest, len(cs))
@@ -270,6 +551,13 @@ func (s GithubSource) GetRepo(ctx context.Context, nameWithOwner string) (*Repo,
 }
 
 func (s GithubSource) makeRepo(r *github.Repository) *Repo {
+	// The push time and star count aren't stored in the metadata, so that
+	// pushes and stars alone don't mark the repo as modified when its
+	// metadata is compared.
+	meta := *r
+	meta.PushedAt = time.Time{}
+	meta.Stargazers.TotalCount = 0
+
 	urn := s.svc.URN()
 	return &Repo{
 		Name: string(reposource.GitHubRepoName(
@@ -282,19 +570,33 @@ func (s GithubSource) makeRepo(r *github.Repository) *Repo {
 			s.originalHostname,
 			r.NameWithOwner,
 		)),
-		ExternalRepo: github.ExternalRepoSpec(r, *s.baseURL),
-		Description:  r.Description,
-		Fork:         r.IsFork,
-		Archived:     r.IsArchived,
-		Private:      r.IsPrivate,
+		ExternalRepo:  github.ExternalRepoSpec(r, *s.baseURL),
+		Description:   r.Description,
+		Language:      r.PrimaryLanguage.Name,
+		Fork:          r.IsFork,
+		Archived:      r.IsArchived,
+		Private:       r.IsPrivate,
+		Topics:        r.Topics(),
+		Stars:         r.Stargazers.TotalCount,
+		DefaultBranch: r.DefaultBranchRef.Name,
+		Visibility:    githubVisibility(r),
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
 				CloneURL: s.authenticatedRemoteURL(r),
 			},
 		},
-		Metadata: r,
+		Metadata: &meta,
+	}
+}
+
+// githubVisibility returns the visibility of the repository, falling back to
+// deriving it from IsPrivate when GitHub didn't report it.
+func githubVisibility(r *github.Repository) string {
+	if r.Visibility != "" {
+		return strings.ToLower(r.Visibility)
 	}
+	return visibility(r.IsPrivate)
 }
 
 // authenticatedRemoteURL returns the repository's Git remote URL with the configured
@@ -365,7 +667,7 @@ func (s *GithubSource) paginate(ctx context.Context, results chan *githubResult,
 		}
 
 		if hasNext && cost > 0 {
-			time.Sleep(s.client.RateLimit.RecommendedWaitForBackgroundOp(cost))
+			waitForRateLimit(ctx, s.svc, s.client.RateLimit.RecommendedWaitForBackgroundOp(cost))
 		}
 	}
 }
@@ -397,7 +699,12 @@ func (s *GithubSource) listOrg(ctx context.Context, org string, results chan *gi
 				"retryAfter", retry,
 			)
 		}()
-		return s.client.ListOrgRepositories(ctx, org, page)
+		if s.since.IsZero() {
+			return s.client.ListOrgRepositories(ctx, org, page)
+		}
+		repos, hasNext, cost, err = s.client.ListOrgRepositoriesByPushed(ctx, org, page)
+		repos, hasNext = s.pushedSince(repos, hasNext)
+		return repos, hasNext, cost, err
 	})
 
 	// Handle 404 from org repos endpoint by trying user repos endpoint
@@ -429,7 +736,12 @@ func (s *GithubSource) listUser(ctx context.Context, user string, results chan *
 				"retryAfter", retry,
 			)
 		}()
-		return s.client.ListUserRepositories(ctx, user, page)
+		if s.since.IsZero() {
+			return s.client.ListUserRepositories(ctx, user, page)
+		}
+		repos, hasNext, cost, err = s.client.ListUserRepositoriesByPushed(ctx, user, page)
+		repos, hasNext = s.pushedSince(repos, hasNext)
+		return repos, hasNext, cost, err
 	})
 	return
 }
@@ -481,7 +793,7 @@ func (s *GithubSource) listRepos(ctx context.Context, repos []string, results ch
 
 		results <- &githubResult{repo: repo}
 
-		time.Sleep(s.client.RateLimit.RecommendedWaitForBackgroundOp(1)) // 0-duration sleep unless nearing rate limit exhaustion
+		waitForRateLimit(ctx, s.svc, s.client.RateLimit.RecommendedWaitForBackgroundOp(1)) // 0-duration sleep unless nearing rate limit exhaustion
 	}
 }
 
@@ -536,7 +848,12 @@ func (s *GithubSource) listAffiliated(ctx context.Context, results chan *githubR
 				"retryAfter", retry,
 			)
 		}()
-		return s.client.ListAffiliatedRepositories(ctx, page)
+		if s.since.IsZero() {
+			return s.client.ListAffiliatedRepositories(ctx, page)
+		}
+		repos, hasNext, cost, err = s.client.ListAffiliatedRepositoriesByPushed(ctx, page)
+		repos, hasNext = s.pushedSince(repos, hasNext)
+		return repos, hasNext, cost, err
 	})
 }
 
@@ -544,8 +861,11 @@ func (s *GithubSource) listAffiliated(ctx context.Context, results chan *githubR
 // It returns the repositories resulting from from GitHub's advanced repository search
 // by hitting the /search/repositories endpoint.
 func (s *GithubSource) listSearch(ctx context.Context, query string, results chan *githubResult) {
+	if !s.since.IsZero() {
+		query += " pushed:>=" + s.since.UTC().Format(time.RFC3339)
+	}
 	s.paginate(ctx, results, func(page int) ([]*github.Repository, bool, int, error) {
-		reposPage, err := s.searchClient.ListRepositoriesForSearch(ctx, query, page)
+		reposPage, err := s.client.ListRepositoriesForSearch(ctx, query, page)
 		if err != nil {
 			return nil, false, 0, errors.Wrapf(err, "failed to list GitHub repositories for search: page=%d, searchString=%q", page, query)
 		}
@@ -559,7 +879,7 @@ func (s *GithubSource) listSearch(ctx context.Context, query string, results cha
 		}
 
 		repos, hasNext := reposPage.Repos, reposPage.HasNextPage
-		remaining, reset, retry, ok := s.searchClient.RateLimit.Get()
+		remaining, reset, retry, ok := s.client.SearchRateLimit.Get()
 		log15.Debug(
 			"github sync: ListRepositoriesForSearch",
 			"searchString", query,
@@ -697,7 +1017,7 @@ func (s *GithubSource) fetchAllRepositoriesInBatches(ctx context.Context, result
 			results <- &githubResult{repo: r}
 		}
 
-		time.Sleep(s.client.RateLimit.RecommendedWaitForBackgroundOp(1)) // 0-duration sleep unless nearing rate limit exhaustion
+		waitForRateLimit(ctx, s.svc, s.client.GraphQLRateLimit.RecommendedWaitForBackgroundOp(1)) // 0-duration sleep unless nearing rate limit exhaustion
 	}
 
 	return nil
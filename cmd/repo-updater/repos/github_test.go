@@ -6,12 +6,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/inconshreveable/log15"
@@ -525,6 +527,187 @@ func TestMatchOrg(t *testing.T) {
 	}
 }
 
+func TestGithubSource_pushedSince(t *testing.T) {
+	now := time.Now()
+	repo := func(name string, pushedAgo time.Duration) *github.Repository {
+		return &github.Repository{NameWithOwner: name, PushedAt: now.Add(-pushedAgo)}
+	}
+	page := []*github.Repository{
+		repo("o/a", time.Minute),
+		repo("o/b", time.Hour),
+		repo("o/c", 3*time.Hour),
+	}
+
+	for _, tc := range []struct {
+		name     string
+		since    time.Duration
+		hasNext  bool
+		wantLen  int
+		wantNext bool
+	}{
+		{name: "whole page changed", since: 4 * time.Hour, hasNext: true, wantLen: 3, wantNext: true},
+		{name: "whole last page changed", since: 4 * time.Hour, hasNext: false, wantLen: 3, wantNext: false},
+		{name: "stops at first unchanged", since: 2 * time.Hour, hasNext: true, wantLen: 2, wantNext: false},
+		{name: "nothing changed", since: 0, hasNext: true, wantLen: 0, wantNext: false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &GithubSource{since: now.Add(-tc.since)}
+			repos, hasNext := s.pushedSince(page, tc.hasNext)
+			if len(repos) != tc.wantLen || hasNext != tc.wantNext {
+				t.Errorf("have (%d repos, %t), want (%d repos, %t)", len(repos), hasNext, tc.wantLen, tc.wantNext)
+			}
+		})
+	}
+}
+
+func TestParseGithubOwner(t *testing.T) {
+	testCases := map[string][2]string{
+		"@mrnugget":                 {"mrnugget", ""},
+		"@sourcegraph/campaigns":    {"sourcegraph", "campaigns"},
+		"campaigns@sourcegraph.com": {"", ""},
+		"":                          {"", ""},
+	}
+
+	for owner, want := range testCases {
+		login, team := parseGithubOwner(owner)
+		if have := [2]string{login, team}; have != want {
+			t.Errorf("parseGithubOwner(%q):\nhave: %q\nwant: %q", owner, have, want)
+		}
+	}
+}
+
+func TestGithubReviewersToRerequest(t *testing.T) {
+	review := func(login, state string) github.TimelineItem {
+		return github.TimelineItem{
+			Type: "PullRequestReview",
+			Item: &github.PullRequestReview{Author: github.Actor{Login: login}, State: state},
+		}
+	}
+
+	pr := &github.PullRequest{
+		Author: github.Actor{Login: "mrnugget"},
+		TimelineItems: []github.TimelineItem{
+			review("alice", "CHANGES_REQUESTED"),
+			review("bob", "COMMENTED"),
+			review("carol", "CHANGES_REQUESTED"),
+			{Type: "IssueComment", Item: &github.IssueComment{Author: github.Actor{Login: "dave"}}},
+			review("mrnugget", "COMMENTED"),
+			review("carol", "APPROVED"),
+			review("erin", "PENDING"),
+			review("bob", "DISMISSED"),
+		},
+	}
+
+	have := githubReviewersToRerequest(pr)
+	if want := []string{"alice", "bob"}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("wrong reviewers. want=%v, have=%v", want, have)
+	}
+}
+
+func TestGithubSource_RequestReviews(t *testing.T) {
+	testCases := []struct {
+		name string
+		// down makes the review request endpoint fail with 500.
+		down bool
+
+		wantRequests  []string
+		wantAssignees []string
+		wantErr       bool
+	}{
+		{
+			name: "non-collaborators are skipped",
+			wantRequests: []string{
+				"alice,mallory,campaigns",
+				"alice",
+				"mallory",
+				"campaigns",
+			},
+			wantAssignees: []string{"bob"},
+		},
+		{
+			name:          "assignees are added if requesting reviews fails",
+			down:          true,
+			wantRequests:  []string{"alice,mallory,campaigns"},
+			wantAssignees: []string{"bob"},
+			wantErr:       true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			collaborators := map[string]bool{"alice": true, "bob": true}
+
+			var requests, assignees []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Reviewers     []string `json:"reviewers"`
+					TeamReviewers []string `json:"team_reviewers"`
+					Assignees     []string `json:"assignees"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+
+				switch r.URL.Path {
+				case "/api/v3/repos/sourcegraph/sourcegraph/pulls/7/requested_reviewers":
+					requests = append(requests, strings.Join(append(body.Reviewers, body.TeamReviewers...), ","))
+					if tc.down {
+						http.Error(w, `{"message": "Server Error"}`, http.StatusInternalServerError)
+						return
+					}
+					for _, login := range body.Reviewers {
+						if !collaborators[login] {
+							http.Error(w, `{"message": "Reviews may only be requested from collaborators."}`, http.StatusUnprocessableEntity)
+							return
+						}
+					}
+				case "/api/v3/repos/sourcegraph/sourcegraph/issues/7/assignees":
+					assignees = append(assignees, body.Assignees...)
+				default:
+					http.NotFound(w, r)
+					return
+				}
+				fmt.Fprint(w, "{}")
+			}))
+			defer srv.Close()
+
+			svc := &ExternalService{
+				Kind: "GITHUB",
+				Config: marshalJSON(t, &schema.GitHubConnection{
+					Url:   srv.URL,
+					Token: "secret",
+				}),
+			}
+			src, err := NewGithubSource(svc, httpcli.NewFactory(nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := &Changeset{
+				Repo: &Repo{Metadata: &github.Repository{NameWithOwner: "sourcegraph/sourcegraph"}},
+				Changeset: &campaigns.Changeset{Metadata: &github.PullRequest{
+					Number: 7,
+					Author: github.Actor{Login: "carol"},
+				}},
+			}
+
+			reviewers := []string{"@alice", "@carol", "@mallory", "@sourcegraph/campaigns", "@other/team", "dave@example.com"}
+			err = src.RequestReviews(context.Background(), c, reviewers, []string{"@bob"})
+			if have, want := err != nil, tc.wantErr; have != want {
+				t.Fatalf("wrong error. wantErr=%t, have=%v", want, err)
+			}
+
+			if diff := cmp.Diff(tc.wantRequests, requests); diff != "" {
+				t.Errorf("wrong review requests (-want +have):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAssignees, assignees); diff != "" {
+				t.Errorf("wrong assignees (-want +have):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestGithubSource_ListRepos(t *testing.T) {
 	assertAllReposListed := func(want []string) ReposAssertion {
 		return func(t testing.TB, rs Repos) {
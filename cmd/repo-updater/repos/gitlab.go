@@ -16,6 +16,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/jsonc"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -89,7 +90,32 @@ func newGitLabSource(svc *ExternalService, c *schema.GitLabConnection, cf *httpc
 // ListRepos returns all GitLab repositories accessible to all connections configured
 // in Sourcegraph via the external services configuration.
 func (s GitLabSource) ListRepos(ctx context.Context, results chan SourceResult) {
-	s.listAllProjects(ctx, results)
+	s.listAllProjects(ctx, time.Time{}, results)
+}
+
+// gitLabCursorOverlap is subtracted from the time recorded in a cursor to
+// tolerate clock skew between Sourcegraph and the GitLab instance.
+const gitLabCursorOverlap = 5 * time.Minute
+
+// ListReposSince lists the GitLab projects matched by the configured project
+// queries that had activity since the given cursor, which is the time the
+// previous listing started at. Explicitly configured projects are always
+// listed.
+func (s GitLabSource) ListReposSince(ctx context.Context, cursor string, results chan SourceResult) (next string) {
+	next = time.Now().UTC().Format(time.RFC3339)
+
+	var since time.Time
+	if cursor != "" {
+		t, err := time.Parse(time.RFC3339, cursor)
+		if err != nil {
+			results <- SourceResult{Source: s, Err: errors.Wrapf(err, "invalid GitLab sync cursor %q", cursor)}
+			return cursor
+		}
+		since = t.Add(-gitLabCursorOverlap)
+	}
+
+	s.listAllProjects(ctx, since, results)
+	return next
 }
 
 // GetRepo returns the GitLab repository with the given pathWithNamespace.
@@ -111,7 +137,250 @@ func (s GitLabSource) ExternalServices() ExternalServices {
 	return ExternalServices{s.svc}
 }
 
+var (
+	_ ChangesetReviewRequester = GitLabSource{}
+	_ ChangesetCommenter       = GitLabSource{}
+	_ ChangesetLabeler         = GitLabSource{}
+	_ ChangesetCheckRerunner   = GitLabSource{}
+)
+
+// CreateChangeset creates a GitLab merge request. If it already exists,
+// *Changeset will be populated and the return value will be true.
+func (s GitLabSource) CreateChangeset(ctx context.Context, c *Changeset) (bool, error) {
+	var exists bool
+	project := c.Repo.Metadata.(*gitlab.Project)
+	source := git.AbbreviateRef(c.HeadRef)
+	target := git.AbbreviateRef(c.BaseRef)
+
+	mr, err := s.client.CreateMergeRequest(ctx, project, gitlab.CreateMergeRequestOpts{
+		SourceBranch: source,
+		TargetBranch: target,
+		Title:        c.Title,
+		Description:  c.Body,
+	})
+	if err != nil {
+		if err != gitlab.ErrMergeRequestAlreadyExists {
+			return exists, errors.Wrap(err, "creating merge request")
+		}
+		mr, err = s.client.GetOpenMergeRequestByRefs(ctx, project, source, target)
+		if err != nil {
+			return exists, errors.Wrap(err, "fetching existing merge request")
+		}
+		exists = true
+	}
+
+	if err := s.loadMergeRequestData(ctx, project, mr); err != nil {
+		return false, errors.Wrap(err, "loading extra metadata")
+	}
+	if err := c.SetMetadata(mr); err != nil {
+		return false, errors.Wrap(err, "setting changeset metadata")
+	}
+
+	return exists, nil
+}
+
+// CloseChangeset closes the merge request on GitLab and updates the Metadata
+// column in the *campaigns.Changeset to the newly closed merge request.
+func (s GitLabSource) CloseChangeset(ctx context.Context, c *Changeset) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+	project := c.Repo.Metadata.(*gitlab.Project)
+
+	updated, err := s.client.UpdateMergeRequest(ctx, project, mr, gitlab.UpdateMergeRequestOpts{StateEvent: "close"})
+	if err != nil {
+		return err
+	}
+	updated.Notes, updated.Pipelines = mr.Notes, mr.Pipelines
+
+	c.Changeset.Metadata = updated
+	return nil
+}
+
+// RequestReviews assigns the merge request to the given reviewers and
+// assignees, since GitLab has no review requests. Only users can be assigned:
+// groups and e-mail addresses are skipped, as are usernames that don't exist.
+func (s GitLabSource) RequestReviews(ctx context.Context, c *Changeset, reviewers, assignees []string) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+	project := c.Repo.Metadata.(*gitlab.Project)
+
+	var ids []int32
+	seen := map[string]bool{}
+	for _, owner := range append(assignees, reviewers...) {
+		if !strings.HasPrefix(owner, "@") || strings.Contains(owner, "/") || seen[owner] {
+			continue
+		}
+		seen[owner] = true
+
+		users, _, err := s.client.ListUsers(ctx, "users?username="+url.QueryEscape(strings.TrimPrefix(owner, "@")))
+		if err != nil {
+			return errors.Wrapf(err, "looking up user %q", owner)
+		}
+		if len(users) == 1 && users[0].ID != mr.Author.ID {
+			ids = append(ids, users[0].ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	updated, err := s.client.UpdateMergeRequest(ctx, project, mr, gitlab.UpdateMergeRequestOpts{AssigneeIDs: ids})
+	if err != nil {
+		return errors.Wrap(err, "assigning merge request")
+	}
+	updated.Notes, updated.Pipelines = mr.Notes, mr.Pipelines
+
+	c.Changeset.Metadata = updated
+	return nil
+}
+
+// CreateComment adds a note with the given body to the merge request.
+func (s GitLabSource) CreateComment(ctx context.Context, c *Changeset, body string) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+	project := c.Repo.Metadata.(*gitlab.Project)
+
+	_, err := s.client.CreateMergeRequestNote(ctx, project, mr, body)
+	return err
+}
+
+// AddLabels adds the given labels to the merge request.
+func (s GitLabSource) AddLabels(ctx context.Context, c *Changeset, labels []string) error {
+	return s.updateLabels(ctx, c, gitlab.UpdateMergeRequestOpts{AddLabels: strings.Join(labels, ",")})
+}
+
+// RemoveLabels removes the given labels from the merge request.
+func (s GitLabSource) RemoveLabels(ctx context.Context, c *Changeset, labels []string) error {
+	return s.updateLabels(ctx, c, gitlab.UpdateMergeRequestOpts{RemoveLabels: strings.Join(labels, ",")})
+}
+
+func (s GitLabSource) updateLabels(ctx context.Context, c *Changeset, opts gitlab.UpdateMergeRequestOpts) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+	project := c.Repo.Metadata.(*gitlab.Project)
+
+	updated, err := s.client.UpdateMergeRequest(ctx, project, mr, opts)
+	if err != nil {
+		return err
+	}
+	updated.Notes, updated.Pipelines = mr.Notes, mr.Pipelines
+
+	c.Changeset.Metadata = updated
+	return nil
+}
+
+// RerunChecks retries the failed and canceled jobs of the latest pipeline of
+// the merge request.
+func (s GitLabSource) RerunChecks(ctx context.Context, c *Changeset) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+	project := c.Repo.Metadata.(*gitlab.Project)
+
+	var latest *gitlab.Pipeline
+	for _, p := range mr.Pipelines {
+		if latest == nil || p.ID > latest.ID {
+			latest = p
+		}
+	}
+	if latest == nil {
+		return errors.New("merge request has no pipelines to re-run")
+	}
+
+	_, err := s.client.RetryPipeline(ctx, project, latest.ID)
+	return err
+}
+
+// LoadChangesets loads the latest state of the given Changesets from GitLab.
+func (s GitLabSource) LoadChangesets(ctx context.Context, cs ...*Changeset) error {
+	var notFound []*Changeset
+
+	for i := range cs {
+		project := cs[i].Repo.Metadata.(*gitlab.Project)
+		iid, err := strconv.ParseInt(cs[i].ExternalID, 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "parsing changeset external id")
+		}
+
+		mr, err := s.client.GetMergeRequest(ctx, project, iid)
+		if err != nil {
+			if gitlab.IsNotFound(err) {
+				notFound = append(notFound, cs[i])
+				if cs[i].Changeset.Metadata == nil {
+					cs[i].Changeset.Metadata = &gitlab.MergeRequest{IID: iid}
+				}
+				continue
+			}
+			return err
+		}
+
+		if err := s.loadMergeRequestData(ctx, project, mr); err != nil {
+			return errors.Wrap(err, "loading merge request data")
+		}
+		if err := cs[i].SetMetadata(mr); err != nil {
+			return errors.Wrap(err, "setting changeset metadata")
+		}
+	}
+
+	if len(notFound) > 0 {
+		return ChangesetsNotFoundError{Changesets: notFound}
+	}
+
+	return nil
+}
+
+// loadMergeRequestData loads the notes and pipelines of the merge request,
+// which are the events of the changeset.
+func (s GitLabSource) loadMergeRequestData(ctx context.Context, project *gitlab.Project, mr *gitlab.MergeRequest) error {
+	if err := s.client.LoadMergeRequestNotes(ctx, project, mr); err != nil {
+		return errors.Wrap(err, "loading merge request notes")
+	}
+
+	if err := s.client.LoadMergeRequestPipelines(ctx, project, mr); err != nil {
+		return errors.Wrap(err, "loading merge request pipelines")
+	}
+
+	return nil
+}
+
+// UpdateChangeset updates the merge request on GitLab.
+func (s GitLabSource) UpdateChangeset(ctx context.Context, c *Changeset) error {
+	mr, ok := c.Changeset.Metadata.(*gitlab.MergeRequest)
+	if !ok {
+		return errors.New("Changeset is not a GitLab merge request")
+	}
+	project := c.Repo.Metadata.(*gitlab.Project)
+
+	updated, err := s.client.UpdateMergeRequest(ctx, project, mr, gitlab.UpdateMergeRequestOpts{
+		Title:        c.Title,
+		Description:  c.Body,
+		TargetBranch: git.AbbreviateRef(c.BaseRef),
+	})
+	if err != nil {
+		return err
+	}
+	updated.Notes, updated.Pipelines = mr.Notes, mr.Pipelines
+
+	c.Changeset.Metadata = updated
+	return nil
+}
+
 func (s GitLabSource) makeRepo(proj *gitlab.Project) *Repo {
+	// The star count isn't stored in the metadata, so that stars alone don't
+	// mark the repo as modified when its metadata is compared.
+	meta := *proj
+	meta.StarCount = 0
+
 	urn := s.svc.URN()
 	return &Repo{
 		Name: string(reposource.GitLabRepoName(
@@ -126,18 +395,22 @@ func (s GitLabSource) makeRepo(proj *gitlab.Project) *Repo {
 			proj.PathWithNamespace,
 			s.nameTransformations,
 		)),
-		ExternalRepo: gitlab.ExternalRepoSpec(proj, *s.baseURL),
-		Description:  proj.Description,
-		Fork:         proj.ForkedFromProject != nil,
-		Archived:     proj.Archived,
-		Private:      proj.Visibility == "private",
+		ExternalRepo:  gitlab.ExternalRepoSpec(proj, *s.baseURL),
+		Description:   proj.Description,
+		Fork:          proj.ForkedFromProject != nil,
+		Archived:      proj.Archived,
+		Private:       proj.Visibility == "private",
+		Topics:        proj.TagList,
+		Stars:         proj.StarCount,
+		DefaultBranch: proj.DefaultBranch,
+		Visibility:    string(proj.Visibility),
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
 				CloneURL: s.authenticatedRemoteURL(proj),
 			},
 		},
-		Metadata: proj,
+		Metadata: &meta,
 	}
 }
 
@@ -164,7 +437,9 @@ func (s *GitLabSource) excludes(p *gitlab.Project) bool {
 	return s.exclude(p.PathWithNamespace) || s.exclude(strconv.Itoa(p.ID))
 }
 
-func (s *GitLabSource) listAllProjects(ctx context.Context, results chan SourceResult) {
+// listAllProjects lists all configured GitLab projects. If since is non-zero,
+// project queries only list projects with activity after it.
+func (s *GitLabSource) listAllProjects(ctx context.Context, since time.Time, results chan SourceResult) {
 	type batch struct {
 		projs []*gitlab.Project
 		err   error
@@ -198,7 +473,7 @@ func (s *GitLabSource) listAllProjects(ctx context.Context, results chan SourceR
 					ch <- batch{projs: []*gitlab.Project{proj}}
 				}
 
-				time.Sleep(s.client.RateLimit.RecommendedWaitForBackgroundOp(1))
+				waitForRateLimit(ctx, s.svc, s.client.RateLimit.RecommendedWaitForBackgroundOp(1))
 			}
 		}()
 	}
@@ -229,6 +504,9 @@ func (s *GitLabSource) listAllProjects(ctx context.Context, results chan SourceR
 			defer wg.Done()
 
 			url, err := projectQueryToURL(projectQuery, perPage) // first page URL
+			if err == nil && !since.IsZero() {
+				url, err = withLastActivityAfter(url, since)
+			}
 			if err != nil {
 				ch <- batch{err: errors.Wrapf(err, "invalid GitLab projectQuery=%q", projectQuery)}
 				return
@@ -251,7 +529,7 @@ func (s *GitLabSource) listAllProjects(ctx context.Context, results chan SourceR
 				url = *nextPageURL
 
 				// 0-duration sleep unless nearing rate limit exhaustion
-				time.Sleep(s.client.RateLimit.RecommendedWaitForBackgroundOp(1))
+				waitForRateLimit(ctx, s.svc, s.client.RateLimit.RecommendedWaitForBackgroundOp(1))
 			}
 		}(projectQuery)
 	}
@@ -300,3 +578,17 @@ func projectQueryToURL(projectQuery string, perPage int) (string, error) {
 
 	return u.String(), nil
 }
+
+// withLastActivityAfter restricts the given GitLab projects URL to projects
+// with activity after t.
+func withLastActivityAfter(projectsURL string, t time.Time) (string, error) {
+	u, err := url.Parse(projectsURL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("last_activity_after", t.UTC().Format(time.RFC3339))
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
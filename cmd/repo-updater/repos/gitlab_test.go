@@ -3,15 +3,19 @@ package repos
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/inconshreveable/log15"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
 	"github.com/sourcegraph/sourcegraph/internal/testutil"
@@ -62,6 +66,29 @@ func Test_projectQueryToURL(t *testing.T) {
 	}
 }
 
+func Test_withLastActivityAfter(t *testing.T) {
+	since := time.Date(2020, 3, 1, 12, 0, 0, 0, time.FixedZone("CET", 3600))
+
+	for _, test := range []struct {
+		url    string
+		expURL string
+	}{{
+		url:    "projects?membership=true&per_page=100",
+		expURL: "projects?last_activity_after=2020-03-01T11%3A00%3A00Z&membership=true&per_page=100",
+	}, {
+		url:    "groups/groupID/projects?last_activity_after=2019-01-01T00%3A00%3A00Z",
+		expURL: "groups/groupID/projects?last_activity_after=2020-03-01T11%3A00%3A00Z",
+	}} {
+		url, err := withLastActivityAfter(test.url, since)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if url != test.expURL {
+			t.Errorf("expected %v, got %v", test.expURL, url)
+		}
+	}
+}
+
 func TestGitLabSource_GetRepo(t *testing.T) {
 	testCases := []struct {
 		name                 string
@@ -157,6 +184,382 @@ func TestGitLabSource_GetRepo(t *testing.T) {
 	}
 }
 
+func TestGitLabSource_CreateChangeset(t *testing.T) {
+	repo := &Repo{Metadata: &gitlab.Project{ProjectCommon: gitlab.ProjectCommon{ID: 1, PathWithNamespace: "sourcegraph/automation-testing"}}}
+
+	testCases := []struct {
+		name      string
+		createErr error
+		existing  *gitlab.MergeRequest
+		err       string
+		exists    bool
+	}{
+		{
+			name: "success",
+		},
+		{
+			name:      "already exists",
+			createErr: gitlab.ErrMergeRequestAlreadyExists,
+			existing:  &gitlab.MergeRequest{IID: 5, Title: "Existing", SourceBranch: "test-pr-6", TargetBranch: "master"},
+			exists:    true,
+		},
+		{
+			name:      "already exists, not found",
+			createErr: gitlab.ErrMergeRequestAlreadyExists,
+			err:       "fetching existing merge request: merge request not found",
+		},
+		{
+			name:      "error",
+			createErr: errors.New("boom"),
+			err:       "creating merge request: boom",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			var opts gitlab.CreateMergeRequestOpts
+			gitlab.MockCreateMergeRequest = func(c *gitlab.Client, ctx context.Context, project *gitlab.Project, o gitlab.CreateMergeRequestOpts) (*gitlab.MergeRequest, error) {
+				opts = o
+				if tc.createErr != nil {
+					return nil, tc.createErr
+				}
+				return &gitlab.MergeRequest{IID: 6, Title: o.Title, Description: o.Description, SourceBranch: o.SourceBranch, TargetBranch: o.TargetBranch}, nil
+			}
+			gitlab.MockGetOpenMergeRequestByRefs = func(c *gitlab.Client, ctx context.Context, project *gitlab.Project, source, target string) (*gitlab.MergeRequest, error) {
+				if source != "test-pr-6" || target != "master" {
+					t.Errorf("wrong refs. want=%q/%q, have=%q/%q", "test-pr-6", "master", source, target)
+				}
+				if tc.existing == nil {
+					return nil, gitlab.ErrMergeRequestNotFound
+				}
+				return tc.existing, nil
+			}
+			defer mockGitLabMergeRequestData()()
+
+			src := newTestGitLabSource(t)
+			cs := &Changeset{
+				Title:     "This is a test MR",
+				Body:      "This is the description of the test MR",
+				HeadRef:   "refs/heads/test-pr-6",
+				BaseRef:   "refs/heads/master",
+				Repo:      repo,
+				Changeset: &campaigns.Changeset{},
+			}
+
+			if tc.err == "" {
+				tc.err = "<nil>"
+			}
+			exists, err := src.CreateChangeset(context.Background(), cs)
+			if have, want := fmt.Sprint(err), tc.err; have != want {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+			if err != nil {
+				return
+			}
+
+			want := gitlab.CreateMergeRequestOpts{
+				SourceBranch: "test-pr-6",
+				TargetBranch: "master",
+				Title:        "This is a test MR",
+				Description:  "This is the description of the test MR",
+			}
+			if diff := cmp.Diff(opts, want); diff != "" {
+				t.Errorf("wrong create options: %s", diff)
+			}
+			if have, want := exists, tc.exists; have != want {
+				t.Errorf("exists:\nhave: %t\nwant: %t", have, want)
+			}
+
+			mr, ok := cs.Changeset.Metadata.(*gitlab.MergeRequest)
+			if !ok {
+				t.Fatal("Metadata does not contain merge request")
+			}
+			if len(mr.Notes) != 1 || len(mr.Pipelines) != 1 {
+				t.Errorf("merge request data not loaded: %+v", mr)
+			}
+			if have, want := cs.Changeset.ExternalID, fmt.Sprint(mr.IID); have != want {
+				t.Errorf("external ID:\nhave: %q\nwant: %q", have, want)
+			}
+		})
+	}
+}
+
+func TestGitLabSource_CloseChangeset(t *testing.T) {
+	notes := []*gitlab.Note{{ID: 1}}
+	pipelines := []*gitlab.Pipeline{{ID: 2}}
+
+	var opts gitlab.UpdateMergeRequestOpts
+	gitlab.MockUpdateMergeRequest = func(c *gitlab.Client, ctx context.Context, project *gitlab.Project, mr *gitlab.MergeRequest, o gitlab.UpdateMergeRequestOpts) (*gitlab.MergeRequest, error) {
+		opts = o
+		return &gitlab.MergeRequest{IID: mr.IID, State: gitlab.MergeRequestStateClosed}, nil
+	}
+	defer func() { gitlab.MockUpdateMergeRequest = nil }()
+
+	src := newTestGitLabSource(t)
+
+	if err := src.CloseChangeset(context.Background(), &Changeset{
+		Repo:      &Repo{Metadata: &gitlab.Project{}},
+		Changeset: &campaigns.Changeset{},
+	}); err == nil {
+		t.Fatal("no error for changeset without merge request")
+	}
+
+	cs := &Changeset{
+		Repo:      &Repo{Metadata: &gitlab.Project{}},
+		Changeset: &campaigns.Changeset{Metadata: &gitlab.MergeRequest{IID: 7, Notes: notes, Pipelines: pipelines}},
+	}
+	if err := src.CloseChangeset(context.Background(), cs); err != nil {
+		t.Fatal(err)
+	}
+	if have, want := opts.StateEvent, "close"; have != want {
+		t.Errorf("wrong state event. want=%q, have=%q", want, have)
+	}
+
+	mr := cs.Changeset.Metadata.(*gitlab.MergeRequest)
+	if mr.State != gitlab.MergeRequestStateClosed {
+		t.Errorf("merge request not closed: %+v", mr)
+	}
+	if !reflect.DeepEqual(mr.Notes, notes) || !reflect.DeepEqual(mr.Pipelines, pipelines) {
+		t.Errorf("notes and pipelines not kept: %+v", mr)
+	}
+}
+
+func TestGitLabSource_UpdateChangeset(t *testing.T) {
+	var opts gitlab.UpdateMergeRequestOpts
+	gitlab.MockUpdateMergeRequest = func(c *gitlab.Client, ctx context.Context, project *gitlab.Project, mr *gitlab.MergeRequest, o gitlab.UpdateMergeRequestOpts) (*gitlab.MergeRequest, error) {
+		opts = o
+		return &gitlab.MergeRequest{IID: mr.IID, Title: o.Title, Description: o.Description, TargetBranch: o.TargetBranch}, nil
+	}
+	defer func() { gitlab.MockUpdateMergeRequest = nil }()
+
+	src := newTestGitLabSource(t)
+
+	cs := &Changeset{
+		Title:     "This is a new title",
+		Body:      "This is a new body",
+		BaseRef:   "refs/heads/main",
+		Repo:      &Repo{Metadata: &gitlab.Project{}},
+		Changeset: &campaigns.Changeset{Metadata: &gitlab.MergeRequest{IID: 7, Notes: []*gitlab.Note{{ID: 1}}}},
+	}
+	if err := src.UpdateChangeset(context.Background(), cs); err != nil {
+		t.Fatal(err)
+	}
+
+	want := gitlab.UpdateMergeRequestOpts{
+		Title:        "This is a new title",
+		Description:  "This is a new body",
+		TargetBranch: "main",
+	}
+	if diff := cmp.Diff(opts, want); diff != "" {
+		t.Errorf("wrong update options: %s", diff)
+	}
+
+	mr := cs.Changeset.Metadata.(*gitlab.MergeRequest)
+	if mr.Title != "This is a new title" || mr.TargetBranch != "main" || len(mr.Notes) != 1 {
+		t.Errorf("wrong metadata: %+v", mr)
+	}
+}
+
+func TestGitLabSource_LoadChangesets(t *testing.T) {
+	mrs := map[int64]*gitlab.MergeRequest{
+		1: {IID: 1, Title: "First", SourceBranch: "first"},
+		2: {IID: 2, Title: "Second", SourceBranch: "second"},
+	}
+	gitlab.MockGetMergeRequest = func(c *gitlab.Client, ctx context.Context, project *gitlab.Project, iid int64) (*gitlab.MergeRequest, error) {
+		if mr, ok := mrs[iid]; ok {
+			return mr, nil
+		}
+		return nil, gitlab.ErrNotFound
+	}
+	defer func() { gitlab.MockGetMergeRequest = nil }()
+	defer mockGitLabMergeRequestData()()
+
+	testCases := []struct {
+		name string
+		cs   []*Changeset
+		err  string
+	}{
+		{
+			name: "found",
+			cs: []*Changeset{
+				{Repo: &Repo{Metadata: &gitlab.Project{}}, Changeset: &campaigns.Changeset{ExternalID: "1"}},
+				{Repo: &Repo{Metadata: &gitlab.Project{}}, Changeset: &campaigns.Changeset{ExternalID: "2"}},
+			},
+		},
+		{
+			name: "not found",
+			cs: []*Changeset{
+				{Repo: &Repo{Metadata: &gitlab.Project{}}, Changeset: &campaigns.Changeset{ExternalID: "1"}},
+				{Repo: &Repo{Metadata: &gitlab.Project{}}, Changeset: &campaigns.Changeset{ExternalID: "3"}},
+			},
+			err: `Changeset with external ID "3" not found`,
+		},
+		{
+			name: "invalid external ID",
+			cs: []*Changeset{
+				{Repo: &Repo{Metadata: &gitlab.Project{}}, Changeset: &campaigns.Changeset{ExternalID: "foo"}},
+			},
+			err: `parsing changeset external id: strconv.ParseInt: parsing "foo": invalid syntax`,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			src := newTestGitLabSource(t)
+
+			if tc.err == "" {
+				tc.err = "<nil>"
+			}
+			err := src.LoadChangesets(context.Background(), tc.cs...)
+			if have, want := fmt.Sprint(err), tc.err; have != want {
+				t.Errorf("error:\nhave: %q\nwant: %q", have, want)
+			}
+
+			for _, cs := range tc.cs {
+				mr, ok := cs.Changeset.Metadata.(*gitlab.MergeRequest)
+				if !ok {
+					continue
+				}
+				if want, ok := mrs[mr.IID]; ok {
+					if mr != want || len(mr.Notes) != 1 || cs.Changeset.ExternalBranch != want.SourceBranch {
+						t.Errorf("wrong metadata for %s: %+v", cs.Changeset.ExternalID, mr)
+					}
+				} else if have, want := fmt.Sprint(mr.IID), cs.Changeset.ExternalID; have != want {
+					t.Errorf("wrong placeholder for missing merge request. want=%s, have=%s", want, have)
+				}
+			}
+		})
+	}
+}
+
+func newTestGitLabSource(t *testing.T) *GitLabSource {
+	t.Helper()
+
+	svc := &ExternalService{
+		Kind: "GITLAB",
+		Config: marshalJSON(t, &schema.GitLabConnection{
+			Url: "https://gitlab.com",
+		}),
+	}
+	src, err := NewGitLabSource(svc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return src
+}
+
+// mockGitLabMergeRequestData mocks loading the notes and pipelines of merge
+// requests, and returns a function that resets the mocks.
+func mockGitLabMergeRequestData() func() {
+	gitlab.MockLoadMergeRequestNotes = func(c *gitlab.Client, ctx context.Context, project *gitlab.Project, mr *gitlab.MergeRequest) error {
+		mr.Notes = []*gitlab.Note{{ID: 1}}
+		return nil
+	}
+	gitlab.MockLoadMergeRequestPipelines = func(c *gitlab.Client, ctx context.Context, project *gitlab.Project, mr *gitlab.MergeRequest) error {
+		mr.Pipelines = []*gitlab.Pipeline{{ID: 2}}
+		return nil
+	}
+	return func() {
+		gitlab.MockLoadMergeRequestNotes = nil
+		gitlab.MockLoadMergeRequestPipelines = nil
+	}
+}
+
+func TestGitLabSource_RequestReviews(t *testing.T) {
+	users := map[string]int32{"alice": 1, "bob": 2, "carol": 3}
+	gitlab.MockListUsers = func(c *gitlab.Client, ctx context.Context, urlStr string) ([]*gitlab.User, *string, error) {
+		u, err := url.Parse(urlStr)
+		if err != nil {
+			return nil, nil, err
+		}
+		username := u.Query().Get("username")
+		if id, ok := users[username]; ok {
+			return []*gitlab.User{{ID: id, Username: username}}, nil, nil
+		}
+		return nil, nil, nil
+	}
+	defer func() { gitlab.MockListUsers = nil }()
+
+	var opts gitlab.UpdateMergeRequestOpts
+	gitlab.MockUpdateMergeRequest = func(c *gitlab.Client, ctx context.Context, project *gitlab.Project, mr *gitlab.MergeRequest, o gitlab.UpdateMergeRequestOpts) (*gitlab.MergeRequest, error) {
+		opts = o
+		updated := *mr
+		return &updated, nil
+	}
+	defer func() { gitlab.MockUpdateMergeRequest = nil }()
+
+	svc := &ExternalService{
+		Kind: "GITLAB",
+		Config: marshalJSON(t, &schema.GitLabConnection{
+			Url: "https://gitlab.com",
+		}),
+	}
+	src, err := NewGitLabSource(svc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Changeset{
+		Repo:      &Repo{Metadata: &gitlab.Project{}},
+		Changeset: &campaigns.Changeset{Metadata: &gitlab.MergeRequest{IID: 7, Author: gitlab.User{ID: 3}}},
+	}
+
+	reviewers := []string{"@alice", "@gitlab-org/campaigns", "carol@example.com", "@carol", "@nobody"}
+	if err := src.RequestReviews(context.Background(), c, reviewers, []string{"@bob", "@alice"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assignees come first, and the author and unknown users are skipped.
+	if have, want := opts.AssigneeIDs, []int32{2, 1}; !reflect.DeepEqual(have, want) {
+		t.Fatalf("wrong assignees. want=%v, have=%v", want, have)
+	}
+}
+
+func TestGitLabSource_RerunChecks(t *testing.T) {
+	var retried int64
+	gitlab.MockRetryPipeline = func(c *gitlab.Client, ctx context.Context, project *gitlab.Project, id int64) (*gitlab.Pipeline, error) {
+		retried = id
+		return &gitlab.Pipeline{ID: id, Status: gitlab.PipelineStatusRunning}, nil
+	}
+	defer func() { gitlab.MockRetryPipeline = nil }()
+
+	svc := &ExternalService{
+		Kind: "GITLAB",
+		Config: marshalJSON(t, &schema.GitLabConnection{
+			Url: "https://gitlab.com",
+		}),
+	}
+	src, err := NewGitLabSource(svc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mr := &gitlab.MergeRequest{IID: 7}
+	c := &Changeset{
+		Repo:      &Repo{Metadata: &gitlab.Project{}},
+		Changeset: &campaigns.Changeset{Metadata: mr},
+	}
+
+	if err := src.RerunChecks(context.Background(), c); err == nil {
+		t.Fatal("no error for merge request without pipelines")
+	}
+
+	mr.Pipelines = []*gitlab.Pipeline{
+		{ID: 12, Status: gitlab.PipelineStatusFailed},
+		{ID: 10, Status: gitlab.PipelineStatusSuccess},
+	}
+	if err := src.RerunChecks(context.Background(), c); err != nil {
+		t.Fatal(err)
+	}
+	if retried != 12 {
+		t.Fatalf("wrong pipeline retried. want=%d, have=%d", 12, retried)
+	}
+}
+
 func TestGitLabSource_makeRepo(t *testing.T) {
 	b, err := ioutil.ReadFile(filepath.Join("testdata", "gitlab-repos.json"))
 	if err != nil {
@@ -107,6 +107,7 @@ func (s GitoliteSource) makeRepo(repo *gitolite.Repo) *Repo {
 		Name:         name,
 		URI:          name,
 		ExternalRepo: gitolite.ExternalRepoSpec(repo, gitolite.ServiceID(s.conn.Host)),
+		Private:      s.conn.Authorization != nil,
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
@@ -22,14 +22,36 @@ type ErrorLogger interface {
 // with error logging, Prometheus metrics and tracing.
 func ObservedSource(l ErrorLogger, m SourceMetrics) func(Source) Source {
 	return func(s Source) Source {
-		return &observedSource{
+		o := &observedSource{
 			Source:  s,
 			metrics: m,
 			log:     l,
 		}
+
+		if inc, ok := s.(IncrementalSource); ok {
+			return &observedIncrementalSource{observedSource: o, inc: inc}
+		}
+
+		return o
 	}
 }
 
+// An observedIncrementalSource is an observedSource that preserves the
+// IncrementalSource implementation of the Source it wraps.
+type observedIncrementalSource struct {
+	*observedSource
+	inc IncrementalSource
+}
+
+// ListReposSince calls into the inner IncrementalSource and registers the
+// observed results.
+func (o *observedIncrementalSource) ListReposSince(ctx context.Context, cursor string, results chan SourceResult) (next string) {
+	o.observe(results, func(uncounted chan SourceResult) {
+		next = o.inc.ListReposSince(ctx, cursor, uncounted)
+	})
+	return next
+}
+
 // An observedSource wraps another Source with error logging,
 // Prometheus metrics and tracing.
 type observedSource struct {
@@ -100,6 +122,13 @@ func NewSourceMetrics() SourceMetrics {
 
 // ListRepos calls into the inner Source registers the observed results.
 func (o *observedSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	o.observe(results, func(uncounted chan SourceResult) {
+		o.Source.ListRepos(ctx, uncounted)
+	})
+}
+
+// observe runs list, forwarding and counting the results it sends.
+func (o *observedSource) observe(results chan SourceResult, list func(chan SourceResult)) {
 	var (
 		err   error
 		count float64
@@ -113,7 +142,7 @@ func (o *observedSource) ListRepos(ctx context.Context, results chan SourceResul
 
 	uncounted := make(chan SourceResult)
 	go func() {
-		o.Source.ListRepos(ctx, uncounted)
+		list(uncounted)
 		close(uncounted)
 	}()
 
@@ -163,6 +192,11 @@ type StoreMetrics struct {
 	UpsertExternalServices *OperationMetrics
 	ListExternalServices   *OperationMetrics
 	ListAllRepoNames       *OperationMetrics
+	ListSyncCursors        *OperationMetrics
+	UpsertSyncCursors      *OperationMetrics
+	ListSyncRuns           *OperationMetrics
+	InsertSyncRuns         *OperationMetrics
+	DeleteSyncRuns         *OperationMetrics
 }
 
 // NewStoreMetrics returns StoreMetrics that need to be registered
@@ -309,6 +343,106 @@ func NewStoreMetrics() StoreMetrics {
 				Help:      "Total number of errors when listing repo names",
 			}, []string{}),
 		},
+		ListSyncCursors: &OperationMetrics{
+			Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_list_sync_cursors_duration_seconds",
+				Help:      "Time spent listing sync cursors",
+			}, []string{}),
+			Count: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_list_sync_cursors_total",
+				Help:      "Total number of listed sync cursors",
+			}, []string{}),
+			Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_list_sync_cursors_errors_total",
+				Help:      "Total number of errors when listing sync cursors",
+			}, []string{}),
+		},
+		UpsertSyncCursors: &OperationMetrics{
+			Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_upsert_sync_cursors_duration_seconds",
+				Help:      "Time spent upserting sync cursors",
+			}, []string{}),
+			Count: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_upsert_sync_cursors_total",
+				Help:      "Total number of upserted sync cursors",
+			}, []string{}),
+			Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_upsert_sync_cursors_errors_total",
+				Help:      "Total number of errors when upserting sync cursors",
+			}, []string{}),
+		},
+		ListSyncRuns: &OperationMetrics{
+			Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_list_sync_runs_duration_seconds",
+				Help:      "Time spent listing sync runs",
+			}, []string{}),
+			Count: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_list_sync_runs_total",
+				Help:      "Total number of listed sync runs",
+			}, []string{}),
+			Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_list_sync_runs_errors_total",
+				Help:      "Total number of errors when listing sync runs",
+			}, []string{}),
+		},
+		InsertSyncRuns: &OperationMetrics{
+			Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_insert_sync_runs_duration_seconds",
+				Help:      "Time spent inserting sync runs",
+			}, []string{}),
+			Count: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_insert_sync_runs_total",
+				Help:      "Total number of inserted sync runs",
+			}, []string{}),
+			Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_insert_sync_runs_errors_total",
+				Help:      "Total number of errors when inserting sync runs",
+			}, []string{}),
+		},
+		DeleteSyncRuns: &OperationMetrics{
+			Duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_delete_sync_runs_duration_seconds",
+				Help:      "Time spent deleting sync runs",
+			}, []string{}),
+			Count: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_delete_sync_runs_total",
+				Help:      "Total number of sync run deletions",
+			}, []string{}),
+			Errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+				Namespace: "src",
+				Subsystem: "repoupdater",
+				Name:      "store_delete_sync_runs_errors_total",
+				Help:      "Total number of errors when deleting sync runs",
+			}, []string{}),
+		},
 	}
 }
 
@@ -492,6 +626,102 @@ func (o *ObservedStore) UpsertRepos(ctx context.Context, repos ...*Repo) (err er
 	return o.store.UpsertRepos(ctx, repos...)
 }
 
+// ListSyncCursors calls into the inner Store and registers the observed results.
+func (o *ObservedStore) ListSyncCursors(ctx context.Context, externalServiceIDs ...int64) (cs []*SyncCursor, err error) {
+	tr, ctx := o.trace(ctx, "Store.ListSyncCursors")
+	tr.LogFields(otlog.Object("external_service_ids", externalServiceIDs))
+
+	defer func(began time.Time) {
+		secs := time.Since(began).Seconds()
+		count := float64(len(cs))
+
+		o.metrics.ListSyncCursors.Observe(secs, count, &err)
+		log(o.log, "store.list-sync-cursors", &err, "count", len(cs))
+
+		tr.LogFields(otlog.Int("count", len(cs)))
+		tr.SetError(err)
+		tr.Finish()
+	}(time.Now())
+
+	return o.store.ListSyncCursors(ctx, externalServiceIDs...)
+}
+
+// UpsertSyncCursors calls into the inner Store and registers the observed results.
+func (o *ObservedStore) UpsertSyncCursors(ctx context.Context, cs ...*SyncCursor) (err error) {
+	tr, ctx := o.trace(ctx, "Store.UpsertSyncCursors")
+	tr.LogFields(otlog.Int("count", len(cs)))
+
+	defer func(began time.Time) {
+		secs := time.Since(began).Seconds()
+		count := float64(len(cs))
+
+		o.metrics.UpsertSyncCursors.Observe(secs, count, &err)
+		log(o.log, "store.upsert-sync-cursors", &err, "count", len(cs))
+
+		tr.SetError(err)
+		tr.Finish()
+	}(time.Now())
+
+	return o.store.UpsertSyncCursors(ctx, cs...)
+}
+
+// ListSyncRuns calls into the inner Store and registers the observed results.
+func (o *ObservedStore) ListSyncRuns(ctx context.Context, externalServiceIDs ...int64) (runs []*SyncRun, err error) {
+	tr, ctx := o.trace(ctx, "Store.ListSyncRuns")
+	tr.LogFields(otlog.Object("external_service_ids", externalServiceIDs))
+
+	defer func(began time.Time) {
+		secs := time.Since(began).Seconds()
+		count := float64(len(runs))
+
+		o.metrics.ListSyncRuns.Observe(secs, count, &err)
+		log(o.log, "store.list-sync-runs", &err, "count", len(runs))
+
+		tr.LogFields(otlog.Int("count", len(runs)))
+		tr.SetError(err)
+		tr.Finish()
+	}(time.Now())
+
+	return o.store.ListSyncRuns(ctx, externalServiceIDs...)
+}
+
+// InsertSyncRuns calls into the inner Store and registers the observed results.
+func (o *ObservedStore) InsertSyncRuns(ctx context.Context, runs ...*SyncRun) (err error) {
+	tr, ctx := o.trace(ctx, "Store.InsertSyncRuns")
+	tr.LogFields(otlog.Int("count", len(runs)))
+
+	defer func(began time.Time) {
+		secs := time.Since(began).Seconds()
+		count := float64(len(runs))
+
+		o.metrics.InsertSyncRuns.Observe(secs, count, &err)
+		log(o.log, "store.insert-sync-runs", &err, "count", len(runs))
+
+		tr.SetError(err)
+		tr.Finish()
+	}(time.Now())
+
+	return o.store.InsertSyncRuns(ctx, runs...)
+}
+
+// DeleteSyncRuns calls into the inner Store and registers the observed results.
+func (o *ObservedStore) DeleteSyncRuns(ctx context.Context, startedBefore time.Time) (err error) {
+	tr, ctx := o.trace(ctx, "Store.DeleteSyncRuns")
+	tr.LogFields(otlog.String("started_before", startedBefore.String()))
+
+	defer func(began time.Time) {
+		secs := time.Since(began).Seconds()
+
+		o.metrics.DeleteSyncRuns.Observe(secs, 1, &err)
+		log(o.log, "store.delete-sync-runs", &err, "started_before", startedBefore)
+
+		tr.SetError(err)
+		tr.Finish()
+	}(time.Now())
+
+	return o.store.DeleteSyncRuns(ctx, startedBefore)
+}
+
 func (o *ObservedStore) trace(ctx context.Context, family string) (*trace.Trace, context.Context) {
 	txctx := o.txctx
 	if txctx == nil {
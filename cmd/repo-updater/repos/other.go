@@ -111,6 +111,36 @@ func otherRepoCloneURL(base *url.URL, repo string) (*url.URL, error) {
 	return base.Parse(repo)
 }
 
+// otherServiceID returns the service ID of the repository with the given clone URL, which is the
+// URL without its path and query.
+func otherServiceID(cloneURL *url.URL) string {
+	u := *cloneURL
+	u.Path, u.RawPath, u.RawQuery = "", "", ""
+	return u.String()
+}
+
+// OtherServiceIDs returns the distinct service IDs of the repositories of the connection.
+func OtherServiceIDs(c *schema.OtherExternalServiceConnection) ([]string, error) {
+	if len(c.Repos) == 1 && c.Repos[0] == "src-expose" {
+		return []string{c.Url}, nil
+	}
+
+	urls, err := OtherSource{conn: c}.cloneURLs()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	seen := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		if id := otherServiceID(u); !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
 func (s OtherSource) otherRepoFromCloneURL(urn string, u *url.URL) (*Repo, error) {
 	repoURL := u.String()
 	repoSource := reposource.Other{OtherExternalServiceConnection: s.conn}
@@ -122,8 +152,6 @@ func (s OtherSource) otherRepoFromCloneURL(urn string, u *url.URL) (*Repo, error
 	if err != nil {
 		return nil, err
 	}
-	u.Path, u.RawQuery = "", ""
-	serviceID := u.String()
 
 	return &Repo{
 		Name: string(repoName),
@@ -131,8 +159,9 @@ func (s OtherSource) otherRepoFromCloneURL(urn string, u *url.URL) (*Repo, error
 		ExternalRepo: api.ExternalRepoSpec{
 			ID:          string(repoName),
 			ServiceType: "other",
-			ServiceID:   serviceID,
+			ServiceID:   otherServiceID(u),
 		},
+		Private: s.conn.Authorization != nil,
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
@@ -184,6 +213,7 @@ func (s OtherSource) srcExpose(ctx context.Context) ([]*Repo, error) {
 			ServiceType: "other",
 			ServiceID:   s.conn.Url,
 		}
+		r.Private = s.conn.Authorization != nil
 		r.Sources = map[string]*SourceInfo{
 			urn: {
 				ID: urn,
@@ -11,6 +11,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/schema"
 )
 
 func TestSrcExpose(t *testing.T) {
@@ -135,3 +136,45 @@ func TestSrcExpose(t *testing.T) {
 		})
 	}
 }
+
+func TestOtherServiceIDs(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		conn *schema.OtherExternalServiceConnection
+		want []string
+	}{
+		{
+			name: "base URL",
+			conn: &schema.OtherExternalServiceConnection{
+				Url:   "https://git.example.com/repos?access_token=secret",
+				Repos: []string{"firmware/boot", "firmware/radio.git"},
+			},
+			want: []string{"https://git.example.com"},
+		},
+		{
+			name: "absolute URLs",
+			conn: &schema.OtherExternalServiceConnection{
+				Repos: []string{"ssh://git@a.example.com:2222/x", "https://b.example.com/y", "ssh://git@a.example.com:2222/z"},
+			},
+			want: []string{"ssh://git@a.example.com:2222", "https://b.example.com"},
+		},
+		{
+			name: "src-expose",
+			conn: &schema.OtherExternalServiceConnection{
+				Url:   "http://127.0.0.1:3434",
+				Repos: []string{"src-expose"},
+			},
+			want: []string{"http://127.0.0.1:3434"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ids, err := OtherServiceIDs(tc.conn)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(ids, tc.want) {
+				t.Errorf("have %v, want %v", ids, tc.want)
+			}
+		})
+	}
+}
@@ -143,6 +143,7 @@ func (s *PhabricatorSource) makeRepo(repo *phabricator.Repo) (*Repo, error) {
 			ServiceType: "phabricator",
 			ServiceID:   serviceID,
 		},
+		Private: s.conn.Authorization != nil,
 		Sources: map[string]*SourceInfo{
 			urn: {
 				ID:       urn,
@@ -9,6 +9,7 @@ import (
 
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 )
 
@@ -87,6 +88,17 @@ type Source interface {
 	ExternalServices() ExternalServices
 }
 
+// An IncrementalSource is a Source that can list only the repos that changed
+// since a previous listing. Repos deleted on the code host aren't reported by
+// incremental listings, so a full listing must still happen periodically.
+type IncrementalSource interface {
+	Source
+	// ListReposSince sends the repos that changed since the given cursor over
+	// the passed in channel as SourceResults and returns the cursor to resume
+	// from on the next call. An empty cursor lists all repos, like ListRepos.
+	ListReposSince(ctx context.Context, cursor string, results chan SourceResult) (next string)
+}
+
 // A ChangesetSource can load the latest state of a list of Changesets.
 type ChangesetSource interface {
 	// LoadChangesets loads the given Changesets from the sources and updates
@@ -106,6 +118,66 @@ type ChangesetSource interface {
 	UpdateChangeset(context.Context, *Changeset) error
 }
 
+// A ChangesetMerger is a ChangesetSource that can also merge Changesets.
+type ChangesetMerger interface {
+	ChangesetSource
+
+	// MergeChangeset merges the Changeset on the source with the given merge
+	// method and updates its metadata to the merged state.
+	MergeChangeset(context.Context, *Changeset, campaigns.ChangesetMergeMethod) error
+}
+
+// A ChangesetReviewRequester is a ChangesetSource that can request reviews of
+// Changesets and assign them to users.
+type ChangesetReviewRequester interface {
+	ChangesetSource
+
+	// RequestReviews requests reviews of the Changeset from the given
+	// reviewers and assigns it to the given assignees. Both are given as
+	// written in CODEOWNERS files: "@username", "@org/team" or an e-mail
+	// address. Owners that can't be resolved on the codehost are skipped.
+	RequestReviews(ctx context.Context, c *Changeset, reviewers, assignees []string) error
+}
+
+// A ChangesetCommenter is a ChangesetSource that can comment on Changesets.
+type ChangesetCommenter interface {
+	ChangesetSource
+
+	// CreateComment comments on the Changeset with the given body.
+	CreateComment(ctx context.Context, c *Changeset, body string) error
+}
+
+// A ChangesetLabeler is a ChangesetSource that can add labels to and remove
+// labels from Changesets.
+type ChangesetLabeler interface {
+	ChangesetSource
+
+	// AddLabels adds the given labels to the Changeset.
+	AddLabels(ctx context.Context, c *Changeset, labels []string) error
+	// RemoveLabels removes the given labels from the Changeset. Labels the
+	// Changeset doesn't have are skipped.
+	RemoveLabels(ctx context.Context, c *Changeset, labels []string) error
+}
+
+// A ChangesetReviewRerequester is a ChangesetSource that can request reviews
+// of Changesets again from the reviewers who already reviewed them.
+type ChangesetReviewRerequester interface {
+	ChangesetSource
+
+	// RerequestReviews requests reviews of the Changeset again from everyone
+	// who reviewed it without approving it in their latest review.
+	RerequestReviews(ctx context.Context, c *Changeset) error
+}
+
+// A ChangesetCheckRerunner is a ChangesetSource that can re-run the checks of
+// Changesets.
+type ChangesetCheckRerunner interface {
+	ChangesetSource
+
+	// RerunChecks re-runs the failed checks of the Changeset's head commit.
+	RerunChecks(ctx context.Context, c *Changeset) error
+}
+
 // ChangesetsNotFoundError is returned by LoadChangesets if any of the passed
 // Changesets could not be found on the codehost.
 type ChangesetsNotFoundError struct {
@@ -29,6 +29,13 @@ type Store interface {
 	UpsertRepos(ctx context.Context, repos ...*Repo) error
 
 	ListAllRepoNames(context.Context) ([]api.RepoName, error)
+
+	ListSyncCursors(ctx context.Context, externalServiceIDs ...int64) ([]*SyncCursor, error)
+	UpsertSyncCursors(ctx context.Context, cs ...*SyncCursor) error
+
+	ListSyncRuns(ctx context.Context, externalServiceIDs ...int64) ([]*SyncRun, error)
+	InsertSyncRuns(ctx context.Context, runs ...*SyncRun) error
+	DeleteSyncRuns(ctx context.Context, startedBefore time.Time) error
 }
 
 // StoreListReposArgs is a query arguments type used by
@@ -329,6 +336,10 @@ SELECT
   archived,
   fork,
   private,
+  topics,
+  stars,
+  default_branch,
+  visibility,
   sources,
   metadata
 FROM repo
@@ -433,6 +444,225 @@ func listAllRepoNamesQuery(cursor, limit int64) *sqlf.Query {
 	return sqlf.Sprintf(listAllRepoNamesQueryFmtstr, cursor, limit)
 }
 
+// ListSyncCursors lists the stored sync cursors of the given external services.
+func (s DBStore) ListSyncCursors(ctx context.Context, externalServiceIDs ...int64) (cs []*SyncCursor, _ error) {
+	if len(externalServiceIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]*sqlf.Query, 0, len(externalServiceIDs))
+	for _, id := range externalServiceIDs {
+		ids = append(ids, sqlf.Sprintf("%d", id))
+	}
+
+	q := sqlf.Sprintf(listSyncCursorsQueryFmtstr, sqlf.Join(ids, ","))
+
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = scanAll(rows, func(sc scanner) (last, count int64, err error) {
+		var c SyncCursor
+		if err = scanSyncCursor(&c, sc); err != nil {
+			return 0, 0, err
+		}
+		cs = append(cs, &c)
+		return c.ExternalServiceID, 1, nil
+	})
+
+	return cs, err
+}
+
+const listSyncCursorsQueryFmtstr = `
+-- source: cmd/repo-updater/repos/store.go:DBStore.ListSyncCursors
+SELECT
+  external_service_id,
+  cursor,
+  last_full_sync_at,
+  updated_at
+FROM external_service_sync_cursors
+WHERE external_service_id IN (%s)
+ORDER BY external_service_id ASC
+`
+
+// UpsertSyncCursors updates or inserts the given sync cursors.
+func (s DBStore) UpsertSyncCursors(ctx context.Context, cs ...*SyncCursor) error {
+	if len(cs) == 0 {
+		return nil
+	}
+
+	vals := make([]*sqlf.Query, 0, len(cs))
+	for _, c := range cs {
+		vals = append(vals, sqlf.Sprintf(
+			upsertSyncCursorsQueryValueFmtstr,
+			c.ExternalServiceID,
+			c.Cursor,
+			nullTimeColumn(c.LastFullSyncAt.UTC()),
+			c.UpdatedAt.UTC(),
+		))
+	}
+
+	q := sqlf.Sprintf(upsertSyncCursorsQueryFmtstr, sqlf.Join(vals, ",\n"))
+
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+const upsertSyncCursorsQueryValueFmtstr = `
+  (%s, %s, %s, %s)
+`
+
+const upsertSyncCursorsQueryFmtstr = `
+-- source: cmd/repo-updater/repos/store.go:DBStore.UpsertSyncCursors
+INSERT INTO external_service_sync_cursors (
+  external_service_id,
+  cursor,
+  last_full_sync_at,
+  updated_at
+)
+VALUES %s
+ON CONFLICT(external_service_id) DO UPDATE
+SET
+  cursor            = excluded.cursor,
+  last_full_sync_at = excluded.last_full_sync_at,
+  updated_at        = excluded.updated_at
+`
+
+// ListSyncRuns lists the stored sync runs of the given external services, most
+// recent first.
+func (s DBStore) ListSyncRuns(ctx context.Context, externalServiceIDs ...int64) (runs []*SyncRun, _ error) {
+	if len(externalServiceIDs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]*sqlf.Query, 0, len(externalServiceIDs))
+	for _, id := range externalServiceIDs {
+		ids = append(ids, sqlf.Sprintf("%d", id))
+	}
+
+	q := sqlf.Sprintf(listSyncRunsQueryFmtstr, sqlf.Join(ids, ","))
+
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, _, err = scanAll(rows, func(sc scanner) (last, count int64, err error) {
+		var r SyncRun
+		if err = scanSyncRun(&r, sc); err != nil {
+			return 0, 0, err
+		}
+		runs = append(runs, &r)
+		return r.ID, 1, nil
+	})
+
+	return runs, err
+}
+
+const listSyncRunsQueryFmtstr = `
+-- source: cmd/repo-updater/repos/store.go:DBStore.ListSyncRuns
+SELECT
+  id,
+  external_service_id,
+  started_at,
+  finished_at,
+  added,
+  deleted,
+  modified,
+  unmodified,
+  rate_limit_wait_ms,
+  error
+FROM external_service_sync_runs
+WHERE external_service_id IN (%s)
+ORDER BY started_at DESC, id DESC
+`
+
+// InsertSyncRuns inserts the given sync runs and sets their IDs.
+func (s DBStore) InsertSyncRuns(ctx context.Context, runs ...*SyncRun) error {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	vals := make([]*sqlf.Query, 0, len(runs))
+	for _, r := range runs {
+		vals = append(vals, sqlf.Sprintf(
+			insertSyncRunsQueryValueFmtstr,
+			r.ExternalServiceID,
+			r.StartedAt.UTC(),
+			r.FinishedAt.UTC(),
+			r.Added,
+			r.Deleted,
+			r.Modified,
+			r.Unmodified,
+			r.RateLimitWait.Milliseconds(),
+			r.Error,
+		))
+	}
+
+	q := sqlf.Sprintf(insertSyncRunsQueryFmtstr, sqlf.Join(vals, ",\n"))
+
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+
+	i := -1
+	_, _, err = scanAll(rows, func(sc scanner) (last, count int64, err error) {
+		i++
+		if err = sc.Scan(&runs[i].ID); err != nil {
+			return 0, 0, err
+		}
+		return runs[i].ID, 1, nil
+	})
+
+	return err
+}
+
+const insertSyncRunsQueryValueFmtstr = `
+  (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+`
+
+const insertSyncRunsQueryFmtstr = `
+-- source: cmd/repo-updater/repos/store.go:DBStore.InsertSyncRuns
+INSERT INTO external_service_sync_runs (
+  external_service_id,
+  started_at,
+  finished_at,
+  added,
+  deleted,
+  modified,
+  unmodified,
+  rate_limit_wait_ms,
+  error
+)
+VALUES %s
+RETURNING id
+`
+
+// DeleteSyncRuns deletes all stored sync runs that started before the given
+// time.
+func (s DBStore) DeleteSyncRuns(ctx context.Context, startedBefore time.Time) error {
+	q := sqlf.Sprintf(deleteSyncRunsQueryFmtstr, startedBefore.UTC())
+
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return err
+	}
+
+	return rows.Close()
+}
+
+const deleteSyncRunsQueryFmtstr = `
+-- source: cmd/repo-updater/repos/store.go:DBStore.DeleteSyncRuns
+DELETE FROM external_service_sync_runs
+WHERE started_at < %s
+`
+
 // a paginatedQuery returns a query with the given pagination
 // parameters
 type paginatedQuery func(cursor, limit int64) *sqlf.Query
@@ -575,6 +805,10 @@ func batchReposQuery(fmtstr string, repos []*Repo) (_ *sqlf.Query, err error) {
 		Archived            bool            `json:"archived"`
 		Fork                bool            `json:"fork"`
 		Private             bool            `json:"private"`
+		Topics              []string        `json:"topics"`
+		Stars               int             `json:"stars"`
+		DefaultBranch       string          `json:"default_branch"`
+		Visibility          string          `json:"visibility"`
 		Sources             json.RawMessage `json:"sources"`
 		Metadata            json.RawMessage `json:"metadata"`
 	}
@@ -606,6 +840,10 @@ func batchReposQuery(fmtstr string, repos []*Repo) (_ *sqlf.Query, err error) {
 			Archived:            r.Archived,
 			Fork:                r.Fork,
 			Private:             r.Private,
+			Topics:              topicsColumn(r.Topics),
+			Stars:               r.Stars,
+			DefaultBranch:       r.DefaultBranch,
+			Visibility:          r.Visibility,
 			Sources:             sources,
 			Metadata:            metadata,
 		})
@@ -650,6 +888,10 @@ WITH batch AS (
       archived              boolean,
       fork                  boolean,
       private               boolean,
+      topics                jsonb,
+      stars                 integer,
+      default_branch        text,
+      visibility            text,
       sources               jsonb,
       metadata              jsonb
     )
@@ -673,6 +915,10 @@ SET
   archived              = batch.archived,
   fork                  = batch.fork,
   private               = batch.private,
+  topics                = batch.topics,
+  stars                 = batch.stars,
+  default_branch        = batch.default_branch,
+  visibility            = batch.visibility,
   sources               = batch.sources,
   metadata              = batch.metadata
 FROM batch
@@ -710,6 +956,10 @@ INSERT INTO repo (
   archived,
   fork,
   private,
+  topics,
+  stars,
+  default_branch,
+  visibility,
   sources,
   metadata
 )
@@ -727,6 +977,10 @@ SELECT
   archived,
   fork,
   private,
+  topics,
+  stars,
+  default_branch,
+  visibility,
   sources,
   metadata
 FROM batch
@@ -753,6 +1007,13 @@ func nullStringColumn(s string) *string {
 	return &s
 }
 
+func topicsColumn(topics []string) []string {
+	if topics == nil {
+		return []string{}
+	}
+	return topics
+}
+
 func metadataColumn(metadata interface{}) (msg json.RawMessage, err error) {
 	switch m := metadata.(type) {
 	case nil:
@@ -811,8 +1072,35 @@ func scanExternalService(svc *ExternalService, s scanner) error {
 	)
 }
 
+func scanSyncCursor(c *SyncCursor, s scanner) error {
+	return s.Scan(
+		&c.ExternalServiceID,
+		&c.Cursor,
+		&dbutil.NullTime{Time: &c.LastFullSyncAt},
+		&c.UpdatedAt,
+	)
+}
+
+func scanSyncRun(r *SyncRun, s scanner) error {
+	var waitMS int64
+	err := s.Scan(
+		&r.ID,
+		&r.ExternalServiceID,
+		&r.StartedAt,
+		&r.FinishedAt,
+		&r.Added,
+		&r.Deleted,
+		&r.Modified,
+		&r.Unmodified,
+		&waitMS,
+		&r.Error,
+	)
+	r.RateLimitWait = time.Duration(waitMS) * time.Millisecond
+	return err
+}
+
 func scanRepo(r *Repo, s scanner) error {
-	var sources, metadata json.RawMessage
+	var topics, sources, metadata json.RawMessage
 	err := s.Scan(
 		&r.ID,
 		&r.Name,
@@ -828,6 +1116,10 @@ func scanRepo(r *Repo, s scanner) error {
 		&r.Archived,
 		&r.Fork,
 		&r.Private,
+		&topics,
+		&r.Stars,
+		&r.DefaultBranch,
+		&r.Visibility,
 		&sources,
 		&metadata,
 	)
@@ -835,6 +1127,14 @@ func scanRepo(r *Repo, s scanner) error {
 		return err
 	}
 
+	if err = json.Unmarshal(topics, &r.Topics); err != nil {
+		return errors.Wrap(err, "scanRepo: failed to unmarshal topics")
+	}
+
+	if len(r.Topics) == 0 {
+		r.Topics = nil
+	}
+
 	if err = json.Unmarshal(sources, &r.Sources); err != nil {
 		return errors.Wrap(err, "scanRepo: failed to unmarshal sources")
 	}
@@ -40,6 +40,8 @@ func TestFakeStore(t *testing.T) {
 		{"ListRepos", testStoreListRepos},
 		{"ListRepos_Pagination", testStoreListReposPagination},
 		{"UpsertRepos", testStoreUpsertRepos},
+		{"SyncCursors", testStoreSyncCursors},
+		{"SyncRuns", testStoreSyncRuns},
 	} {
 		t.Run(tc.name, tc.test(repos.NewObservedStore(
 			new(repos.FakeStore),
@@ -463,11 +465,15 @@ func testStoreUpsertRepos(store repos.Store) func(*testing.T) {
 		}
 
 		github := repos.Repo{
-			Name:        "github.com/foo/bar",
-			URI:         "github.com/foo/bar",
-			Description: "The description",
-			Language:    "barlang",
-			CreatedAt:   now,
+			Name:          "github.com/foo/bar",
+			URI:           "github.com/foo/bar",
+			Description:   "The description",
+			Language:      "barlang",
+			Topics:        []string{"payments", "backend"},
+			Stars:         42,
+			DefaultBranch: "main",
+			Visibility:    "public",
+			CreatedAt:     now,
 			ExternalRepo: api.ExternalRepoSpec{
 				ID:          "AAAAA==",
 				ServiceType: "github",
@@ -483,11 +489,15 @@ func testStoreUpsertRepos(store repos.Store) func(*testing.T) {
 		}
 
 		gitlab := repos.Repo{
-			Name:        "gitlab.com/foo/bar",
-			URI:         "gitlab.com/foo/bar",
-			Description: "The description",
-			Language:    "barlang",
-			CreatedAt:   now,
+			Name:          "gitlab.com/foo/bar",
+			URI:           "gitlab.com/foo/bar",
+			Description:   "The description",
+			Language:      "barlang",
+			Topics:        []string{"payments"},
+			Stars:         7,
+			DefaultBranch: "master",
+			Visibility:    "internal",
+			CreatedAt:     now,
 			ExternalRepo: api.ExternalRepoSpec{
 				ID:          "1234",
 				ServiceType: "gitlab",
@@ -1095,6 +1105,137 @@ func testStoreListReposPagination(store repos.Store) func(*testing.T) {
 	}
 }
 
+func testStoreSyncCursors(store repos.Store) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		ctx := context.Background()
+		clock := repos.NewFakeClock(time.Now(), 0)
+		now := clock.Now().UTC().Truncate(time.Microsecond)
+
+		t.Run("", transact(ctx, store, func(t testing.TB, tx repos.Store) {
+			svc := repos.ExternalService{
+				Kind:        "GITLAB",
+				DisplayName: "GitLab - Test",
+				Config:      `{"url": "https://gitlab.com"}`,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+
+			if err := tx.UpsertExternalServices(ctx, &svc); err != nil {
+				t.Fatalf("failed to setup store: %v", err)
+			}
+
+			have, err := tx.ListSyncCursors(ctx, svc.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(have) != 0 {
+				t.Fatalf("expected no sync cursors, got %d", len(have))
+			}
+
+			want := []*repos.SyncCursor{{
+				ExternalServiceID: svc.ID,
+				Cursor:            now.Format(time.RFC3339),
+				LastFullSyncAt:    now,
+				UpdatedAt:         now,
+			}}
+
+			if err = tx.UpsertSyncCursors(ctx, want...); err != nil {
+				t.Fatal(err)
+			}
+
+			want[0].Cursor = now.Add(time.Hour).Format(time.RFC3339)
+			want[0].UpdatedAt = now.Add(time.Hour)
+
+			if err = tx.UpsertSyncCursors(ctx, want...); err != nil {
+				t.Fatal(err)
+			}
+
+			have, err = tx.ListSyncCursors(ctx, svc.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, c := range have {
+				c.LastFullSyncAt = c.LastFullSyncAt.UTC()
+				c.UpdatedAt = c.UpdatedAt.UTC()
+			}
+
+			if diff := cmp.Diff(have, want); diff != "" {
+				t.Fatalf("sync cursors:\n%s", diff)
+			}
+		}))
+	}
+}
+
+func testStoreSyncRuns(store repos.Store) func(*testing.T) {
+	return func(t *testing.T) {
+		t.Helper()
+
+		ctx := context.Background()
+		clock := repos.NewFakeClock(time.Now().UTC().Truncate(time.Microsecond), time.Minute)
+		now := clock.Now()
+
+		t.Run("", transact(ctx, store, func(t testing.TB, tx repos.Store) {
+			svc := repos.ExternalService{
+				Kind:        "GITHUB",
+				DisplayName: "Github - Test",
+				Config:      `{"url": "https://github.com"}`,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+
+			if err := tx.UpsertExternalServices(ctx, &svc); err != nil {
+				t.Fatalf("failed to setup store: %v", err)
+			}
+
+			runs := make([]*repos.SyncRun, 0, 3)
+			for i := 0; i < 3; i++ {
+				runs = append(runs, &repos.SyncRun{
+					ExternalServiceID: svc.ID,
+					StartedAt:         clock.Now(),
+					FinishedAt:        clock.Now(),
+					Added:             i,
+					Unmodified:        10,
+					RateLimitWait:     time.Duration(i) * time.Second,
+				})
+			}
+			runs[2].Error = "boom"
+
+			if err := tx.InsertSyncRuns(ctx, runs...); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, r := range runs {
+				if r.ID == 0 {
+					t.Fatalf("sync run has no ID: %+v", r)
+				}
+			}
+
+			if err := tx.DeleteSyncRuns(ctx, runs[1].StartedAt); err != nil {
+				t.Fatal(err)
+			}
+
+			have, err := tx.ListSyncRuns(ctx, svc.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, r := range have {
+				r.StartedAt = r.StartedAt.UTC()
+				r.FinishedAt = r.FinishedAt.UTC()
+			}
+
+			want := []*repos.SyncRun{runs[2], runs[1]}
+			if diff := cmp.Diff(have, want); diff != "" {
+				t.Fatalf("sync runs:\n%s", diff)
+			}
+		}))
+	}
+}
+
 func testDBStoreTransact(store *repos.DBStore) func(*testing.T) {
 	return func(t *testing.T) {
 		ctx := context.Background()
@@ -0,0 +1,170 @@
+package repos
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+)
+
+// syncRuns tracks the SyncRun of each external service listed in a Sync.
+type syncRuns struct {
+	mu    sync.Mutex
+	byID  map[int64]*SyncRun
+	byURN map[string]*SyncRun
+}
+
+func newSyncRuns(svcs []*ExternalService, startedAt time.Time) *syncRuns {
+	runs := &syncRuns{
+		byID:  make(map[int64]*SyncRun, len(svcs)),
+		byURN: make(map[string]*SyncRun, len(svcs)),
+	}
+
+	for _, svc := range svcs {
+		r := &SyncRun{ExternalServiceID: svc.ID, StartedAt: startedAt}
+		runs.byID[svc.ID] = r
+		runs.byURN[svc.URN()] = r
+	}
+
+	return runs
+}
+
+// count counts the repos of each external service in the given Diff. It must
+// be called before the deleted repos have their sources removed.
+//
+// Repos inserted while streaming show up as stored in the Diff, so stored
+// repos created after the Sync started are counted as added.
+func (rs *syncRuns) count(diff Diff) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	for _, state := range []struct {
+		repos    Repos
+		count    func(*SyncRun)
+		streamed bool // whether repos may have been inserted while streaming
+	}{
+		{diff.Added, func(r *SyncRun) { r.Added++ }, false},
+		{diff.Deleted, func(r *SyncRun) { r.Deleted++ }, false},
+		{diff.Modified, func(r *SyncRun) { r.Modified++ }, true},
+		{diff.Unmodified, func(r *SyncRun) { r.Unmodified++ }, true},
+	} {
+		for _, repo := range state.repos {
+			for urn := range repo.Sources {
+				r, ok := rs.byURN[urn]
+				if !ok {
+					continue
+				}
+
+				if state.streamed && !repo.CreatedAt.IsZero() && !repo.CreatedAt.Before(r.StartedAt) {
+					r.Added++
+				} else {
+					state.count(r)
+				}
+			}
+		}
+	}
+}
+
+// waited records time spent waiting for the rate limit of the given external
+// service.
+func (rs *syncRuns) waited(externalServiceID int64, d time.Duration) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if r, ok := rs.byID[externalServiceID]; ok {
+		r.RateLimitWait += d
+	}
+}
+
+// finish sets the finish time of all runs and attributes the given Sync error
+// to the external services it's about. Since a failed Sync doesn't store any
+// changes, runs of the remaining external services are failed too.
+func (rs *syncRuns) finish(now time.Time, err error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	var (
+		general []string
+		byID    = map[int64][]string{}
+	)
+
+	if err != nil {
+		errs := []error{err}
+		if me, ok := errors.Cause(err).(*multierror.Error); ok {
+			errs = me.Errors
+		}
+
+		for _, e := range errs {
+			if se, ok := e.(*SourceError); ok && se.ExtSvc != nil {
+				byID[se.ExtSvc.ID] = append(byID[se.ExtSvc.ID], se.Error())
+			} else {
+				general = append(general, e.Error())
+			}
+		}
+
+		if len(general) == 0 {
+			general = []string{"sync aborted because of errors in other external services"}
+		}
+	}
+
+	for id, r := range rs.byID {
+		r.FinishedAt = now
+
+		if err == nil {
+			continue
+		}
+
+		if msgs, ok := byID[id]; ok {
+			r.Error = strings.Join(msgs, "\n")
+		} else {
+			r.Error = strings.Join(general, "\n")
+		}
+	}
+}
+
+// list returns all runs sorted by external service ID.
+func (rs *syncRuns) list() []*SyncRun {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	runs := make([]*SyncRun, 0, len(rs.byID))
+	for _, r := range rs.byID {
+		runs = append(runs, r)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		return runs[i].ExternalServiceID < runs[j].ExternalServiceID
+	})
+
+	return runs
+}
+
+type syncRunsKey struct{}
+
+// contextWithSyncRuns returns a context in which Sources record their rate
+// limit waits in the given runs.
+func contextWithSyncRuns(ctx context.Context, runs *syncRuns) context.Context {
+	return context.WithValue(ctx, syncRunsKey{}, runs)
+}
+
+// waitForRateLimit sleeps for d or until ctx is done, recording the wait in
+// the SyncRun of svc if ctx belongs to a Sync.
+func waitForRateLimit(ctx context.Context, svc *ExternalService, d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	start := time.Now()
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+
+	if runs, ok := ctx.Value(syncRunsKey{}).(*syncRuns); ok {
+		runs.waited(svc.ID, time.Since(start))
+	}
+}
@@ -12,6 +12,7 @@ import (
 	otlog "github.com/opentracing/opentracing-go/log"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 )
 
@@ -29,6 +30,16 @@ type Syncer struct {
 	// Sourcegraph.com
 	FailFullSync bool
 
+	// FullSyncInterval is the maximum amount of time between two full listings
+	// of an external service whose Source is an IncrementalSource. In between,
+	// Sync only lists the repos that changed since the previous run. Zero means
+	// every Sync lists every external service in full.
+	FullSyncInterval time.Duration
+
+	// SyncRunRetention is how long the SyncRuns recorded by Sync are kept
+	// for. Zero means they are kept forever.
+	SyncRunRetention time.Duration
+
 	// Synced is sent a collection of Repos that were synced by Sync (only if Synced is non-nil)
 	Synced chan Diff
 
@@ -109,6 +120,19 @@ func (s *Syncer) Sync(ctx context.Context) (err error) {
 		return errors.New("Syncer is not enabled")
 	}
 
+	// Listing all repositories is the least urgent use of the code hosts'
+	// rate limits, see ratelimit.Priority.
+	ctx = ratelimit.WithPriority(ctx, ratelimit.PriorityRepoListing)
+
+	var svcs ExternalServices
+	if svcs, err = s.Store.ListExternalServices(ctx, StoreListExternalServicesArgs{}); err != nil {
+		return errors.Wrap(err, "syncer.sync.store.list-external-services")
+	}
+
+	runs := newSyncRuns(svcs, s.Now())
+	ctx = contextWithSyncRuns(ctx, runs)
+	defer s.saveSyncRuns(ctx, runs, &err)
+
 	var streamingInserter func(*Repo)
 	if s.DisableStreaming {
 		streamingInserter = func(*Repo) {} //noop
@@ -119,8 +143,11 @@ func (s *Syncer) Sync(ctx context.Context) (err error) {
 		}
 	}
 
-	var sourced Repos
-	if sourced, err = s.sourced(ctx, streamingInserter); err != nil {
+	var (
+		sourced Repos
+		cursors []*cursorSource
+	)
+	if sourced, cursors, err = s.sourced(ctx, svcs, streamingInserter); err != nil {
 		return errors.Wrap(err, "syncer.sync.sourced")
 	}
 
@@ -139,13 +166,18 @@ func (s *Syncer) Sync(ctx context.Context) (err error) {
 		return errors.Wrap(err, "syncer.sync.store.list-repos")
 	}
 
-	diff = NewDiff(sourced, stored)
+	diff = NewDiff(carryOver(sourced, stored, cursors), stored)
+	runs.count(diff)
 	upserts := s.upserts(diff)
 
 	if err = store.UpsertRepos(ctx, upserts...); err != nil {
 		return errors.Wrap(err, "syncer.sync.store.upsert-repos")
 	}
 
+	if err = store.UpsertSyncCursors(ctx, s.nextCursors(cursors)...); err != nil {
+		return errors.Wrap(err, "syncer.sync.store.upsert-sync-cursors")
+	}
+
 	if s.Synced != nil {
 		s.Synced <- diff
 	}
@@ -170,6 +202,62 @@ func (s *Syncer) SyncSubset(ctx context.Context, sourcedSubset ...*Repo) (err er
 	return err
 }
 
+// DryRun lists the repos of the given external service, as it would be
+// configured, and returns the Diff a Sync would apply to the repos it
+// yields or used to yield, without storing anything.
+//
+// Repos that are also yielded by other external services are never Deleted,
+// only Modified, because a Sync would just drop svc from their sources.
+func (s *Syncer) DryRun(ctx context.Context, svc *ExternalService) (diff Diff, err error) {
+	srcs, err := s.Sourcer(svc)
+	if err != nil {
+		return Diff{}, errors.Wrap(err, "syncer.dryrun.sourcer")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sourceTimeout)
+	defer cancel()
+
+	sourced, err := listAll(ctx, srcs)
+	if err != nil {
+		return Diff{}, errors.Wrap(err, "syncer.dryrun.sourced")
+	}
+
+	stored, err := s.Store.ListRepos(ctx, StoreListReposArgs{Kinds: []string{svc.Kind}})
+	if err != nil {
+		return Diff{}, errors.Wrap(err, "syncer.dryrun.store.list-repos")
+	}
+
+	// Only consider the stored repos svc yields or used to yield and pretend
+	// every other external service yields the same repos it did in the last
+	// Sync.
+	ids := make(map[api.ExternalRepoSpec]bool, len(sourced))
+	for _, r := range sourced {
+		ids[r.ExternalRepo] = true
+	}
+
+	urn := svc.URN()
+	subset := make(Repos, 0, len(sourced))
+	for _, r := range stored {
+		if _, ok := r.Sources[urn]; !ok && !ids[r.ExternalRepo] {
+			continue
+		}
+
+		// NewDiff updates the stored repos it's given, so we hand it clones.
+		subset = append(subset, r.Clone())
+
+		other := r.Clone()
+		delete(other.Sources, urn)
+		if len(other.Sources) > 0 {
+			sourced = append(sourced, other)
+		}
+	}
+
+	diff = NewDiff(sourced, subset)
+	diff.Sort()
+
+	return diff, nil
+}
+
 // insertIfNew is a specialization of SyncSubset. It will insert sourcedRepo
 // if there are no related repositories, otherwise does nothing.
 func (s *Syncer) insertIfNew(ctx context.Context, sourcedRepo *Repo) (err error) {
@@ -351,21 +439,170 @@ func merge(o, n *Repo) {
 	o.Update(n)
 }
 
-func (s *Syncer) sourced(ctx context.Context, observe ...func(*Repo)) ([]*Repo, error) {
-	svcs, err := s.Store.ListExternalServices(ctx, StoreListExternalServicesArgs{})
+func (s *Syncer) sourced(ctx context.Context, svcs []*ExternalService, observe ...func(*Repo)) ([]*Repo, []*cursorSource, error) {
+	srcs, err := s.Sourcer(svcs...)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	srcs, err := s.Sourcer(svcs...)
+	cursors, err := s.cursorSources(ctx, srcs)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, sourceTimeout)
 	defer cancel()
 
-	return listAll(ctx, srcs, observe...)
+	sourced, err := listAll(ctx, srcs, observe...)
+	return sourced, cursors, err
+}
+
+// A cursorSource lists the repos of an IncrementalSource, either in full or
+// since its stored SyncCursor, and records the cursor to resume from.
+type cursorSource struct {
+	IncrementalSource
+	cursor *SyncCursor
+	full   bool
+	next   string
+}
+
+// ListRepos lists the repos of the underlying IncrementalSource.
+func (s *cursorSource) ListRepos(ctx context.Context, results chan SourceResult) {
+	var cursor string
+	if !s.full {
+		cursor = s.cursor.Cursor
+	}
+	s.next = s.IncrementalSource.ListReposSince(ctx, cursor, results)
+}
+
+// cursorSources replaces, in place, each IncrementalSource in srcs with a
+// cursorSource and returns those.
+func (s *Syncer) cursorSources(ctx context.Context, srcs Sources) ([]*cursorSource, error) {
+	ids := make([]int64, 0, len(srcs))
+	for _, src := range srcs {
+		if _, ok := src.(IncrementalSource); ok && len(src.ExternalServices()) == 1 {
+			ids = append(ids, src.ExternalServices()[0].ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	stored, err := s.Store.ListSyncCursors(ctx, ids...)
+	if err != nil {
+		return nil, errors.Wrap(err, "syncer.sync.store.list-sync-cursors")
+	}
+
+	byID := make(map[int64]*SyncCursor, len(stored))
+	for _, c := range stored {
+		byID[c.ExternalServiceID] = c
+	}
+
+	now := s.Now()
+	cursors := make([]*cursorSource, 0, len(ids))
+	for i, src := range srcs {
+		inc, ok := src.(IncrementalSource)
+		if !ok || len(src.ExternalServices()) != 1 {
+			continue
+		}
+
+		svc := src.ExternalServices()[0]
+		c, ok := byID[svc.ID]
+		if !ok {
+			c = &SyncCursor{ExternalServiceID: svc.ID}
+		}
+
+		cs := &cursorSource{
+			IncrementalSource: inc,
+			cursor:            c,
+			full:              s.fullSyncDue(now, svc, c),
+		}
+
+		srcs[i] = cs
+		cursors = append(cursors, cs)
+	}
+
+	return cursors, nil
+}
+
+// fullSyncDue returns true if the given external service must be listed in
+// full rather than since its SyncCursor. That's the case when it was never
+// listed before, when its configuration changed after its last full listing
+// or when its last full listing is older than FullSyncInterval.
+func (s *Syncer) fullSyncDue(now time.Time, svc *ExternalService, c *SyncCursor) bool {
+	return s.FullSyncInterval <= 0 ||
+		c.Cursor == "" ||
+		c.LastFullSyncAt.IsZero() ||
+		c.LastFullSyncAt.Before(svc.UpdatedAt) ||
+		now.Sub(c.LastFullSyncAt) >= s.FullSyncInterval
+}
+
+// nextCursors returns the SyncCursors to store after a successful Sync.
+func (s *Syncer) nextCursors(cursors []*cursorSource) []*SyncCursor {
+	now := s.Now()
+	next := make([]*SyncCursor, 0, len(cursors))
+	for _, cs := range cursors {
+		c := cs.cursor.Clone()
+		c.Cursor = cs.next
+		c.UpdatedAt = now
+		if cs.full {
+			c.LastFullSyncAt = now
+		}
+		next = append(next, c)
+	}
+	return next
+}
+
+// carryOver returns sourced with the sources of stored repos that belong to
+// external services that were listed incrementally. Those only yield the repos
+// that changed since their previous listing, so a repo they didn't yield
+// must not be considered deleted from them.
+func carryOver(sourced, stored Repos, cursors []*cursorSource) Repos {
+	incremental := make(map[int64]bool, len(cursors))
+	for _, cs := range cursors {
+		if !cs.full {
+			incremental[cs.cursor.ExternalServiceID] = true
+		}
+	}
+
+	if len(incremental) == 0 {
+		return sourced
+	}
+
+	byID := make(map[api.ExternalRepoSpec]*Repo, len(sourced))
+	for _, r := range sourced {
+		byID[r.ExternalRepo] = r
+	}
+
+	for _, old := range stored {
+		carried := make(map[string]*SourceInfo, len(old.Sources))
+		for urn, info := range old.Sources {
+			if incremental[info.ExternalServiceID()] {
+				carried[urn] = info
+			}
+		}
+
+		if len(carried) == 0 {
+			continue
+		}
+
+		if r, ok := byID[old.ExternalRepo]; ok {
+			for urn, info := range carried {
+				if _, ok := r.Sources[urn]; !ok {
+					r.Sources[urn] = info
+				}
+			}
+			continue
+		}
+
+		r := old.Clone()
+		r.Sources = carried
+		sourced = append(sourced, r)
+		byID[r.ExternalRepo] = r
+	}
+
+	return sourced
 }
 
 func (s *Syncer) makeNewRepoInserter(ctx context.Context) (func(*Repo), error) {
@@ -405,6 +642,35 @@ func (s *Syncer) storedExternalIDs(ctx context.Context) (map[api.ExternalRepoSpe
 	return ids, nil
 }
 
+// saveSyncRuns stores the SyncRuns of a Sync that returned the given error
+// and deletes the ones older than the SyncRunRetention.
+func (s *Syncer) saveSyncRuns(ctx context.Context, runs *syncRuns, perr *error) {
+	var err error
+	if perr != nil {
+		err = *perr
+	}
+
+	now := s.Now()
+	runs.finish(now, err)
+
+	if ctx.Err() != nil {
+		// Still record the runs of a cancelled Sync.
+		ctx = context.Background()
+	}
+
+	if err := s.Store.InsertSyncRuns(ctx, runs.list()...); err != nil && s.Logger != nil {
+		s.Logger.Error("syncer.sync.store.insert-sync-runs", "error", err)
+	}
+
+	if s.SyncRunRetention <= 0 {
+		return
+	}
+
+	if err := s.Store.DeleteSyncRuns(ctx, now.Add(-s.SyncRunRetention)); err != nil && s.Logger != nil {
+		s.Logger.Error("syncer.sync.store.delete-sync-runs", "error", err)
+	}
+}
+
 func (s *Syncer) setOrResetLastSyncErr(perr *error) {
 	var err error
 	if perr != nil {
@@ -574,6 +574,301 @@ func testSyncerSync(s repos.Store) func(*testing.T) {
 	}
 }
 
+func TestSyncer_IncrementalSync(t *testing.T) {
+	t.Parallel()
+
+	clock := repos.NewFakeClock(time.Now(), time.Second)
+
+	svc := &repos.ExternalService{
+		ID:        10,
+		Kind:      "GITLAB",
+		UpdatedAt: clock.Time(0),
+	}
+
+	foo := (&repos.Repo{
+		Name:     "gitlab.com/org/foo",
+		Metadata: &gitlab.Project{},
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          "1",
+			ServiceID:   "https://gitlab.com/",
+			ServiceType: "gitlab",
+		},
+	}).With(repos.Opt.RepoSources(svc.URN()))
+
+	bar := foo.With(
+		repos.Opt.RepoName("gitlab.com/org/bar"),
+		repos.Opt.RepoExternalID("2"),
+	)
+
+	changed := foo.With(func(r *repos.Repo) { r.Description = "changed" })
+
+	// bar was deleted upstream, which incremental listings don't report.
+	src := repos.NewFakeIncrementalSource(svc, "cursor-1", []*repos.Repo{changed}, foo, bar)
+	store := new(repos.FakeStore)
+	ctx := context.Background()
+
+	syncer := &repos.Syncer{
+		Store:            store,
+		Sourcer:          repos.NewFakeSourcer(nil, src),
+		FullSyncInterval: time.Hour,
+		Now:              clock.Now,
+	}
+
+	sync := func(t *testing.T, wantCursor string, want ...*repos.Repo) {
+		t.Helper()
+
+		if err := syncer.Sync(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if have := src.Cursors[len(src.Cursors)-1]; have != wantCursor {
+			t.Errorf("listed since cursor %q, want %q", have, wantCursor)
+		}
+
+		stored, err := store.ListRepos(ctx, repos.StoreListReposArgs{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		have := repos.Repos(stored).With(
+			repos.Opt.RepoID(0),
+			repos.Opt.RepoCreatedAt(time.Time{}),
+		)
+		sort.Sort(have)
+
+		wanted := repos.Repos(want).Clone()
+		sort.Sort(wanted)
+
+		repos.Assert.ReposEqual(wanted...)(t, have)
+	}
+
+	t.Run("first sync lists in full", func(t *testing.T) {
+		sync(t, "", foo, bar)
+	})
+
+	t.Run("following syncs list since the stored cursor", func(t *testing.T) {
+		src.FakeSource = repos.NewFakeSource(svc, nil, changed)
+		src.Next = "cursor-2"
+		sync(t, "cursor-1", changed, bar)
+	})
+
+	t.Run("config changes force a full sync", func(t *testing.T) {
+		svc.UpdatedAt = clock.Now()
+		sync(t, "", changed)
+
+		cs, err := store.ListSyncCursors(ctx, svc.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(cs) != 1 || cs[0].Cursor != "cursor-2" || cs[0].LastFullSyncAt.Before(svc.UpdatedAt) {
+			t.Errorf("unexpected sync cursors: %+v", cs)
+		}
+	})
+
+	t.Run("full sync interval elapsed", func(t *testing.T) {
+		later := repos.NewFakeClock(clock.Now().Add(2*time.Hour), time.Second)
+		syncer.Now = later.Now
+		sync(t, "", changed)
+	})
+}
+
+func TestSyncer_DryRun(t *testing.T) {
+	t.Parallel()
+
+	svc1 := &repos.ExternalService{ID: 1, Kind: "GITLAB"}
+	svc2 := &repos.ExternalService{ID: 2, Kind: "GITLAB"}
+
+	base := repos.Repo{
+		Name:     "gitlab.com/org/foo",
+		Metadata: &gitlab.Project{},
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          "1",
+			ServiceID:   "https://gitlab.com/",
+			ServiceType: "gitlab",
+		},
+	}
+
+	foo := base.With(repos.Opt.RepoSources(svc1.URN()))
+	bar := base.With(
+		repos.Opt.RepoName("gitlab.com/org/bar"),
+		repos.Opt.RepoExternalID("2"),
+		repos.Opt.RepoSources(svc1.URN(), svc2.URN()),
+	)
+	baz := base.With(
+		repos.Opt.RepoName("gitlab.com/org/baz"),
+		repos.Opt.RepoExternalID("3"),
+		repos.Opt.RepoSources(svc2.URN()),
+	)
+	qux := base.With(
+		repos.Opt.RepoName("gitlab.com/org/qux"),
+		repos.Opt.RepoExternalID("4"),
+	)
+
+	ctx := context.Background()
+	store := new(repos.FakeStore)
+	stored := repos.Repos{foo, bar, baz}.Clone()
+	if err := store.UpsertRepos(ctx, stored...); err != nil {
+		t.Fatal(err)
+	}
+
+	// The proposed svc1 config yields only qux.
+	syncer := &repos.Syncer{
+		Store:   store,
+		Sourcer: repos.NewFakeSourcer(nil, repos.NewFakeSource(svc1, nil, qux)),
+		Now:     time.Now,
+	}
+
+	diff, err := syncer.DryRun(ctx, svc1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	have := map[string][]string{}
+	for name, rs := range map[string]repos.Repos{
+		"added":      diff.Added,
+		"deleted":    diff.Deleted,
+		"modified":   diff.Modified,
+		"unmodified": diff.Unmodified,
+	} {
+		have[name] = rs.Names()
+	}
+
+	want := map[string][]string{
+		"added":      {qux.Name},
+		"deleted":    {foo.Name},
+		"modified":   {bar.Name},
+		"unmodified": {},
+	}
+
+	if d := cmp.Diff(have, want); d != "" {
+		t.Fatalf("unexpected diff:\n%s", d)
+	}
+
+	if srcs := diff.Modified[0].Sources; len(srcs) != 1 || srcs[svc2.URN()] == nil {
+		t.Errorf("unexpected sources of modified repo: %v", srcs)
+	}
+
+	after, err := store.ListRepos(ctx, repos.StoreListReposArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Sort(repos.Repos(after))
+	repos.Assert.ReposEqual(stored...)(t, after)
+}
+
+func TestSyncer_SyncRuns(t *testing.T) {
+	t.Parallel()
+
+	clock := repos.NewFakeClock(time.Now(), time.Second)
+	ctx := context.Background()
+
+	githubSvc := &repos.ExternalService{Kind: "GITHUB", DisplayName: "GitHub", Config: "{}"}
+	gitlabSvc := &repos.ExternalService{Kind: "GITLAB", DisplayName: "GitLab", Config: "{}"}
+
+	store := new(repos.FakeStore)
+	if err := store.UpsertExternalServices(ctx, githubSvc, gitlabSvc); err != nil {
+		t.Fatal(err)
+	}
+
+	foo := &repos.Repo{
+		Name:     "github.com/org/foo",
+		Metadata: &github.Repository{},
+		ExternalRepo: api.ExternalRepoSpec{
+			ID:          "foo",
+			ServiceID:   "https://github.com/",
+			ServiceType: "github",
+		},
+	}
+
+	bar := foo.With(
+		repos.Opt.RepoName("github.com/org/bar"),
+		repos.Opt.RepoExternalID("bar"),
+	)
+
+	syncer := &repos.Syncer{
+		Store:            store,
+		Now:              clock.Now,
+		SyncRunRetention: time.Hour,
+	}
+
+	sync := func(t *testing.T, sourcer repos.Sourcer) []*repos.SyncRun {
+		t.Helper()
+
+		syncer.Sourcer = sourcer
+		_ = syncer.Sync(ctx)
+
+		runs, err := store.ListSyncRuns(ctx, githubSvc.ID, gitlabSvc.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return runs
+	}
+
+	type counts struct {
+		svc                                  int64
+		added, deleted, modified, unmodified int
+		err                                  string
+	}
+
+	check := func(t *testing.T, runs []*repos.SyncRun, want ...counts) {
+		t.Helper()
+
+		have := make([]counts, 0, len(runs))
+		for _, r := range runs {
+			if r.FinishedAt.Before(r.StartedAt) {
+				t.Errorf("run %d finished before it started", r.ID)
+			}
+			have = append(have, counts{r.ExternalServiceID, r.Added, r.Deleted, r.Modified, r.Unmodified, r.Error})
+		}
+
+		if d := cmp.Diff(have, want, cmp.AllowUnexported(counts{})); d != "" {
+			t.Fatalf("sync runs:\n%s", d)
+		}
+	}
+
+	t.Run("successful sync records counts", func(t *testing.T) {
+		runs := sync(t, repos.NewFakeSourcer(nil,
+			repos.NewFakeSource(githubSvc, nil, foo, bar),
+			repos.NewFakeSource(gitlabSvc, nil),
+		))
+
+		check(t, runs[:2],
+			counts{svc: gitlabSvc.ID},
+			counts{svc: githubSvc.ID, added: 2},
+		)
+	})
+
+	t.Run("failed sync records errors", func(t *testing.T) {
+		runs := sync(t, repos.NewFakeSourcer(nil,
+			repos.NewFakeSource(githubSvc, nil, foo),
+			repos.NewFakeSource(gitlabSvc, errors.New("boom")),
+		))
+
+		check(t, runs[:2],
+			counts{svc: gitlabSvc.ID, err: "boom"},
+			counts{svc: githubSvc.ID, err: "sync aborted because of errors in other external services"},
+		)
+	})
+
+	t.Run("old runs are deleted", func(t *testing.T) {
+		later := repos.NewFakeClock(clock.Now().Add(2*time.Hour), time.Second)
+		syncer.Now = later.Now
+
+		runs := sync(t, repos.NewFakeSourcer(nil,
+			repos.NewFakeSource(githubSvc, nil, foo),
+			repos.NewFakeSource(gitlabSvc, nil),
+		))
+
+		check(t, runs,
+			counts{svc: gitlabSvc.ID},
+			counts{svc: githubSvc.ID, deleted: 1, unmodified: 1},
+		)
+	})
+}
+
 func TestSync_SyncSubset(t *testing.T) {
 	t.Parallel()
 
@@ -793,6 +1088,14 @@ func TestDiff(t *testing.T) {
 				{Name: "2", ExternalRepo: eid("1"), Description: "foo"},
 			}},
 		},
+		{
+			name:   "star count changes alone are unmodified",
+			store:  repos.Repos{{ExternalRepo: eid("1"), Description: "foo", Stars: 1}},
+			source: repos.Repos{{ExternalRepo: eid("1"), Description: "foo", Stars: 2}},
+			diff: repos.Diff{Unmodified: repos.Repos{
+				{ExternalRepo: eid("1"), Description: "foo", Stars: 2},
+			}},
+		},
 		{
 			name: "unmodified preserves stored repo",
 			store: repos.Repos{
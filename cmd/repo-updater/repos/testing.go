@@ -64,6 +64,45 @@ func (s FakeSource) ExternalServices() ExternalServices {
 	return ExternalServices{s.svc}
 }
 
+// FakeIncrementalSource is a fake implementation of IncrementalSource to be
+// used in tests.
+type FakeIncrementalSource struct {
+	*FakeSource
+	// Changed are the repos yielded by ListReposSince for a non-empty cursor.
+	Changed []*Repo
+	// Next is the cursor returned by ListReposSince.
+	Next string
+	// Cursors records the cursors ListReposSince was called with.
+	Cursors []string
+}
+
+// NewFakeIncrementalSource returns an instance of FakeIncrementalSource that
+// yields rs when listing in full and changed when listing since a cursor.
+func NewFakeIncrementalSource(svc *ExternalService, next string, changed []*Repo, rs ...*Repo) *FakeIncrementalSource {
+	return &FakeIncrementalSource{
+		FakeSource: NewFakeSource(svc, nil, rs...),
+		Changed:    changed,
+		Next:       next,
+	}
+}
+
+// ListReposSince returns all the repos FakeIncrementalSource was instantiated
+// with if cursor is empty and the changed repos otherwise.
+func (s *FakeIncrementalSource) ListReposSince(ctx context.Context, cursor string, results chan SourceResult) string {
+	s.Cursors = append(s.Cursors, cursor)
+
+	if cursor == "" {
+		s.FakeSource.ListRepos(ctx, results)
+		return s.Next
+	}
+
+	for _, r := range s.Changed {
+		results <- SourceResult{Source: s, Repo: r.With(Opt.RepoSources(s.svc.URN()))}
+	}
+
+	return s.Next
+}
+
 // FakeStore is a fake implementation of Store to be used in tests.
 type FakeStore struct {
 	ListExternalServicesError   error // error to be returned in ListExternalServices
@@ -72,12 +111,20 @@ type FakeStore struct {
 	ListReposError              error // error to be returned in ListRepos
 	UpsertReposError            error // error to be returned in UpsertRepos
 	ListAllRepoNamesError       error // error to be returned in ListAllRepoNames
-
-	svcIDSeq  int64
-	repoIDSeq api.RepoID
-	svcByID   map[int64]*ExternalService
-	repoByID  map[api.RepoID]*Repo
-	parent    *FakeStore
+	ListSyncCursorsError        error // error to be returned in ListSyncCursors
+	UpsertSyncCursorsError      error // error to be returned in UpsertSyncCursors
+	ListSyncRunsError           error // error to be returned in ListSyncRuns
+	InsertSyncRunsError         error // error to be returned in InsertSyncRuns
+	DeleteSyncRunsError         error // error to be returned in DeleteSyncRuns
+
+	svcIDSeq    int64
+	repoIDSeq   api.RepoID
+	runIDSeq    int64
+	svcByID     map[int64]*ExternalService
+	repoByID    map[api.RepoID]*Repo
+	cursorBySvc map[int64]*SyncCursor
+	runs        []*SyncRun
+	parent      *FakeStore
 }
 
 // Transact returns a TxStore whose methods operate within the context of a transaction.
@@ -97,6 +144,16 @@ func (s *FakeStore) Transact(ctx context.Context) (TxStore, error) {
 		repoByID[r.ID] = clone
 	}
 
+	cursorBySvc := make(map[int64]*SyncCursor, len(s.cursorBySvc))
+	for id, c := range s.cursorBySvc {
+		cursorBySvc[id] = c.Clone()
+	}
+
+	runs := make([]*SyncRun, 0, len(s.runs))
+	for _, r := range s.runs {
+		runs = append(runs, r.Clone())
+	}
+
 	return &FakeStore{
 		ListExternalServicesError:   s.ListExternalServicesError,
 		UpsertExternalServicesError: s.UpsertExternalServicesError,
@@ -104,12 +161,20 @@ func (s *FakeStore) Transact(ctx context.Context) (TxStore, error) {
 		ListReposError:              s.ListReposError,
 		UpsertReposError:            s.UpsertReposError,
 		ListAllRepoNamesError:       s.ListAllRepoNamesError,
-
-		svcIDSeq:  s.svcIDSeq,
-		svcByID:   svcByID,
-		repoIDSeq: s.repoIDSeq,
-		repoByID:  repoByID,
-		parent:    s,
+		ListSyncCursorsError:        s.ListSyncCursorsError,
+		UpsertSyncCursorsError:      s.UpsertSyncCursorsError,
+		ListSyncRunsError:           s.ListSyncRunsError,
+		InsertSyncRunsError:         s.InsertSyncRunsError,
+		DeleteSyncRunsError:         s.DeleteSyncRunsError,
+
+		svcIDSeq:    s.svcIDSeq,
+		svcByID:     svcByID,
+		repoIDSeq:   s.repoIDSeq,
+		repoByID:    repoByID,
+		cursorBySvc: cursorBySvc,
+		runIDSeq:    s.runIDSeq,
+		runs:        runs,
+		parent:      s,
 	}, nil
 }
 
@@ -294,6 +359,105 @@ func (s FakeStore) ListAllRepoNames(ctx context.Context) ([]api.RepoName, error)
 	return names, nil
 }
 
+// ListSyncCursors lists the stored sync cursors of the given external services.
+func (s FakeStore) ListSyncCursors(ctx context.Context, externalServiceIDs ...int64) ([]*SyncCursor, error) {
+	if s.ListSyncCursorsError != nil {
+		return nil, s.ListSyncCursorsError
+	}
+
+	cs := make([]*SyncCursor, 0, len(externalServiceIDs))
+	for _, id := range externalServiceIDs {
+		if c, ok := s.cursorBySvc[id]; ok {
+			cs = append(cs, c.Clone())
+		}
+	}
+
+	sort.Slice(cs, func(i, j int) bool {
+		return cs[i].ExternalServiceID < cs[j].ExternalServiceID
+	})
+
+	return cs, nil
+}
+
+// UpsertSyncCursors updates or inserts the given sync cursors.
+func (s *FakeStore) UpsertSyncCursors(ctx context.Context, cs ...*SyncCursor) error {
+	if s.UpsertSyncCursorsError != nil {
+		return s.UpsertSyncCursorsError
+	}
+
+	if s.cursorBySvc == nil {
+		s.cursorBySvc = make(map[int64]*SyncCursor, len(cs))
+	}
+
+	for _, c := range cs {
+		s.cursorBySvc[c.ExternalServiceID] = c.Clone()
+	}
+
+	return nil
+}
+
+// ListSyncRuns lists the stored sync runs of the given external services, most
+// recent first.
+func (s FakeStore) ListSyncRuns(ctx context.Context, externalServiceIDs ...int64) ([]*SyncRun, error) {
+	if s.ListSyncRunsError != nil {
+		return nil, s.ListSyncRunsError
+	}
+
+	ids := make(map[int64]bool, len(externalServiceIDs))
+	for _, id := range externalServiceIDs {
+		ids[id] = true
+	}
+
+	runs := make([]*SyncRun, 0, len(s.runs))
+	for _, r := range s.runs {
+		if ids[r.ExternalServiceID] {
+			runs = append(runs, r.Clone())
+		}
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].StartedAt.After(runs[j].StartedAt)
+		}
+		return runs[i].ID > runs[j].ID
+	})
+
+	return runs, nil
+}
+
+// InsertSyncRuns inserts the given sync runs and sets their IDs.
+func (s *FakeStore) InsertSyncRuns(ctx context.Context, runs ...*SyncRun) error {
+	if s.InsertSyncRunsError != nil {
+		return s.InsertSyncRunsError
+	}
+
+	for _, r := range runs {
+		s.runIDSeq++
+		r.ID = s.runIDSeq
+		s.runs = append(s.runs, r.Clone())
+	}
+
+	return nil
+}
+
+// DeleteSyncRuns deletes all stored sync runs that started before the given
+// time.
+func (s *FakeStore) DeleteSyncRuns(ctx context.Context, startedBefore time.Time) error {
+	if s.DeleteSyncRunsError != nil {
+		return s.DeleteSyncRunsError
+	}
+
+	runs := s.runs[:0]
+	for _, r := range s.runs {
+		if !r.StartedAt.Before(startedBefore) {
+			runs = append(runs, r)
+		}
+	}
+	s.runs = runs
+
+	return nil
+}
+
 func evalOr(bs ...bool) bool {
 	if len(bs) == 0 {
 		return true
@@ -92,6 +92,8 @@ func (e ExternalService) Configuration() (cfg interface{}, _ error) {
 		cfg = &schema.AWSCodeCommitConnection{}
 	case "bitbucketserver":
 		cfg = &schema.BitbucketServerConnection{}
+	case "bitbucketcloud":
+		cfg = &schema.BitbucketCloudConnection{}
 	case "github":
 		cfg = &schema.GitHubConnection{}
 	case "gitlab":
@@ -462,6 +464,8 @@ func (e ExternalService) schema() string {
 		return schema.AWSCodeCommitSchemaJSON
 	case "bitbucketserver":
 		return schema.BitbucketServerSchemaJSON
+	case "bitbucketcloud":
+		return schema.BitbucketCloudSchemaJSON
 	case "github":
 		return schema.GitHubSchemaJSON
 	case "gitlab":
@@ -528,6 +532,51 @@ func (e *ExternalService) With(opts ...func(*ExternalService)) *ExternalService
 	return clone
 }
 
+// A SyncCursor records where the last listing of an ExternalService by an
+// IncrementalSource left off, so that the next Sync only needs to list the
+// repos that changed since.
+type SyncCursor struct {
+	ExternalServiceID int64
+	// Cursor is the opaque value returned by the last call to
+	// IncrementalSource.ListReposSince.
+	Cursor string
+	// LastFullSyncAt is when the external service was last listed in full.
+	LastFullSyncAt time.Time
+	UpdatedAt      time.Time
+}
+
+// Clone returns a clone of the given sync cursor.
+func (c *SyncCursor) Clone() *SyncCursor {
+	clone := *c
+	return &clone
+}
+
+// A SyncRun records the outcome of syncing the repos of an ExternalService in
+// a single Sync.
+type SyncRun struct {
+	ID                int64
+	ExternalServiceID int64
+	StartedAt         time.Time
+	FinishedAt        time.Time
+	// Added, Deleted, Modified and Unmodified count the repos of the
+	// external service in each part of the sync's Diff.
+	Added      int
+	Deleted    int
+	Modified   int
+	Unmodified int
+	// RateLimitWait is the time spent waiting for the code host's rate limit
+	// while listing the repos of the external service.
+	RateLimitWait time.Duration
+	// Error is the error the sync ran into, if any.
+	Error string
+}
+
+// Clone returns a clone of the given sync run.
+func (r *SyncRun) Clone() *SyncRun {
+	clone := *r
+	return &clone
+}
+
 // Repo represents a source code repository stored in Sourcegraph.
 type Repo struct {
 	// The internal Sourcegraph repo ID.
@@ -551,6 +600,15 @@ type Repo struct {
 	Archived bool
 	// Private is whether the repository is private.
 	Private bool
+	// Topics are the topics (or tags) the repository is labelled with on the code host.
+	Topics []string
+	// Stars is the number of stars (or likes) the repository has on the code host.
+	Stars int
+	// DefaultBranch is the name of the repository's default branch on the code host.
+	DefaultBranch string
+	// Visibility is the code host specific visibility of the repository (e.g. "public",
+	// "internal" or "private").
+	Visibility string
 	// CreatedAt is when this repository was created on Sourcegraph.
 	CreatedAt time.Time
 	// UpdatedAt is when this repository's metadata was last updated on Sourcegraph.
@@ -648,6 +706,22 @@ func (r *Repo) Update(n *Repo) (modified bool) {
 		r.Private, modified = n.Private, true
 	}
 
+	if !equalStrings(r.Topics, n.Topics) {
+		r.Topics, modified = n.Topics, true
+	}
+
+	// Star counts change too often to be worth storing on their own, so
+	// they're only stored along with other changes.
+	r.Stars = n.Stars
+
+	if r.DefaultBranch != n.DefaultBranch {
+		r.DefaultBranch, modified = n.DefaultBranch, true
+	}
+
+	if r.Visibility != n.Visibility {
+		r.Visibility, modified = n.Visibility, true
+	}
+
 	if !reflect.DeepEqual(r.Sources, n.Sources) {
 		r.Sources, modified = n.Sources, true
 	}
@@ -671,9 +745,35 @@ func (r *Repo) Clone() *Repo {
 			clone.Sources[k] = v
 		}
 	}
+	if r.Topics != nil {
+		clone.Topics = append([]string(nil), r.Topics...)
+	}
 	return &clone
 }
 
+// visibility returns the visibility of a repository on code hosts that only
+// distinguish between public and private repositories.
+func visibility(private bool) string {
+	if private {
+		return "private"
+	}
+	return "public"
+}
+
+// equalStrings returns true if both slices contain the same strings in the
+// same order, treating nil and empty slices as equal.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // Apply applies the given functional options to the Repo.
 func (r *Repo) Apply(opts ...func(*Repo)) {
 	if r == nil {
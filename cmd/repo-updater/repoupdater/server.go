@@ -47,6 +47,9 @@ type Server struct {
 	ChangesetSyncer interface {
 		EnqueueChangesetSyncs(ctx context.Context, ids []int64) error
 	}
+	PermsSyncer interface {
+		SyncInfo(userID int32, repoID api.RepoID) *protocol.PermsSyncInfoResponse
+	}
 
 	notClonedCountMu        sync.Mutex
 	notClonedCount          uint64
@@ -62,8 +65,10 @@ func (s *Server) Handler() http.Handler {
 	mux.HandleFunc("/enqueue-repo-update", s.handleEnqueueRepoUpdate)
 	mux.HandleFunc("/exclude-repo", s.handleExcludeRepo)
 	mux.HandleFunc("/sync-external-service", s.handleExternalServiceSync)
+	mux.HandleFunc("/sync-external-service-dry-run", s.handleExternalServiceDryRun)
 	mux.HandleFunc("/status-messages", s.handleStatusMessages)
 	mux.HandleFunc("/enqueue-changeset-sync", s.handleEnqueueChangesetSync)
+	mux.HandleFunc("/perms-sync-info", s.handlePermsSyncInfo)
 	return mux
 }
 
@@ -337,6 +342,56 @@ func (s *Server) handleExternalServiceSync(w http.ResponseWriter, r *http.Reques
 	})
 }
 
+func (s *Server) handleExternalServiceDryRun(w http.ResponseWriter, r *http.Request) {
+	var req protocol.ExternalServiceDryRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.ExternalService.DeletedAt != nil {
+		http.Error(w, "cannot dry run a deleted external service", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := s.Syncer.DryRun(r.Context(), &repos.ExternalService{
+		ID:          req.ExternalService.ID,
+		Kind:        req.ExternalService.Kind,
+		DisplayName: req.ExternalService.DisplayName,
+		Config:      req.ExternalService.Config,
+	})
+
+	if r.Context().Err() != nil {
+		// client is gone
+		return
+	}
+
+	res := &protocol.ExternalServiceDryRunResult{ExternalService: req.ExternalService}
+	if err != nil {
+		// Errors from listing repos are reported in the result, since a
+		// Sync would also abort on them instead of deleting any repos.
+		log15.Info("server.external-service-dry-run", "kind", req.ExternalService.Kind, "error", err)
+		res.Error = err.Error()
+		respond(w, http.StatusOK, res)
+		return
+	}
+
+	res.Added = repoNames(diff.Added)
+	res.Deleted = repoNames(diff.Deleted)
+	res.Modified = repoNames(diff.Modified)
+	res.Unmodified = repoNames(diff.Unmodified)
+
+	respond(w, http.StatusOK, res)
+}
+
+func repoNames(rs repos.Repos) []api.RepoName {
+	names := make([]api.RepoName, 0, len(rs))
+	for _, r := range rs {
+		names = append(names, api.RepoName(r.Name))
+	}
+	return names
+}
+
 func externalServiceValidate(ctx context.Context, req *protocol.ExternalServiceSyncRequest) error {
 	if req.ExternalService.DeletedAt != nil {
 		// We don't need to check deleted services.
@@ -593,6 +648,21 @@ func (s *Server) handleEnqueueChangesetSync(w http.ResponseWriter, r *http.Reque
 	respond(w, http.StatusOK, nil)
 }
 
+func (s *Server) handlePermsSyncInfo(w http.ResponseWriter, r *http.Request) {
+	if s.PermsSyncer == nil {
+		log15.Warn("PermsSyncer is nil")
+		respond(w, http.StatusForbidden, nil)
+		return
+	}
+
+	var req protocol.PermsSyncInfoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respond(w, http.StatusBadRequest, err)
+		return
+	}
+	respond(w, http.StatusOK, s.PermsSyncer.SyncInfo(req.UserID, req.RepoID))
+}
+
 func newRepoInfo(r *repos.Repo) (*protocol.RepoInfo, error) {
 	urls := r.CloneURLs()
 	if len(urls) == 0 {
@@ -37,6 +37,8 @@ type EnterpriseInit func(db *sql.DB, store repos.Store, cf *httpcli.Factory, ser
 
 func Main(enterpriseInit EnterpriseInit) {
 	streamingSyncer, _ := strconv.ParseBool(env.Get("SRC_STREAMING_SYNCER_ENABLED", "true", "Use the new, streaming repo metadata syncer."))
+	fullSyncInterval := env.Get("SRC_REPOS_FULL_SYNC_INTERVAL", "1h", "Maximum interval between full listings of code hosts that support incremental repo listing. 0 disables incremental listing.")
+	syncRunRetention := env.Get("SRC_REPOS_SYNC_RUN_RETENTION", "720h", "How long the history of external service sync runs is kept for. 0 keeps it forever.")
 
 	ctx := context.Background()
 	env.Lock()
@@ -83,6 +85,11 @@ func Main(enterpriseInit EnterpriseInit) {
 			m.ListExternalServices,
 			m.UpsertExternalServices,
 			m.ListAllRepoNames,
+			m.ListSyncCursors,
+			m.UpsertSyncCursors,
+			m.ListSyncRuns,
+			m.InsertSyncRuns,
+			m.DeleteSyncRuns,
 		} {
 			om.MustRegister(prometheus.DefaultRegisterer)
 		}
@@ -173,10 +180,22 @@ func Main(enterpriseInit EnterpriseInit) {
 
 	gps := repos.NewGitolitePhabricatorMetadataSyncer(store)
 
+	fullSyncIntervalDuration, err := time.ParseDuration(fullSyncInterval)
+	if err != nil {
+		log.Fatalf("parsing $SRC_REPOS_FULL_SYNC_INTERVAL: %v", err)
+	}
+
+	syncRunRetentionDuration, err := time.ParseDuration(syncRunRetention)
+	if err != nil {
+		log.Fatalf("parsing $SRC_REPOS_SYNC_RUN_RETENTION: %v", err)
+	}
+
 	syncer := &repos.Syncer{
 		Store:            store,
 		Sourcer:          src,
 		DisableStreaming: !streamingSyncer,
+		FullSyncInterval: fullSyncIntervalDuration,
+		SyncRunRetention: syncRunRetentionDuration,
 		Logger:           log15.Root(),
 		Now:              clock,
 	}
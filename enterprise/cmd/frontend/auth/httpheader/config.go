@@ -1,23 +1,38 @@
 package httpheader
 
 import (
+	"fmt"
+	"net/http"
+	"net/textproto"
+
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
-// getProviderConfig returns the HTTP header auth provider config. At most 1 can be specified in
-// site config; if there is more than 1, it returns multiple == true (which the caller should handle
-// by returning an error and refusing to proceed with auth).
-func getProviderConfig() (pc *schema.HTTPHeaderAuthProvider, multiple bool) {
+// getProviderConfigs returns the HTTP header auth provider configs in site config.
+func getProviderConfigs() (pcs []*schema.HTTPHeaderAuthProvider) {
 	for _, p := range conf.Get().AuthProviders {
 		if p.HttpHeader != nil {
-			if pc != nil {
-				return pc, true // multiple http-header auth providers
-			}
-			pc = p.HttpHeader
+			pcs = append(pcs, p.HttpHeader)
+		}
+	}
+	return pcs
+}
+
+// getProviderConfig returns the config of the HTTP header auth provider to use for the request: the
+// first provider whose discriminator header (if any) matches the request. If the providers are
+// misconfigured, it returns misconfigured == true (which the caller should handle by returning an
+// error and refusing to proceed with auth).
+func getProviderConfig(r *http.Request) (pc *schema.HTTPHeaderAuthProvider, misconfigured bool) {
+	if len(providerProblems(conf.Get().AuthProviders)) > 0 {
+		return nil, true
+	}
+	for _, pc := range getProviderConfigs() {
+		if pc.DiscriminatorHeader == "" || r.Header.Get(pc.DiscriminatorHeader) == pc.DiscriminatorValue {
+			return pc, false
 		}
 	}
-	return pc, false
+	return nil, false
 }
 
 func init() {
@@ -25,14 +40,60 @@ func init() {
 }
 
 func validateConfig(c conf.Unified) (problems conf.Problems) {
-	var httpHeaderAuthProviders int
-	for _, p := range c.AuthProviders {
+	for _, msg := range providerProblems(c.AuthProviders) {
+		problems = append(problems, conf.NewSiteProblem(msg))
+	}
+	return problems
+}
+
+// providerProblems returns the problems with the http-header auth providers that prevent them from
+// being used. Multiple providers must be distinguished by their discriminator headers, because
+// otherwise the provider to use for a request would be ambiguous.
+func providerProblems(authProviders []schema.AuthProviders) (problems []string) {
+	type discriminator struct{ header, value string }
+	var (
+		count          int
+		seenConfigIDs  = map[string]int{}
+		seenDiscrimins = map[discriminator]int{}
+	)
+	for _, p := range authProviders {
 		if p.HttpHeader != nil {
-			httpHeaderAuthProviders++
+			count++
 		}
 	}
-	if httpHeaderAuthProviders >= 2 {
-		problems = append(problems, conf.NewSiteProblem(`at most 1 http-header auth provider may be used`))
+
+	for i, p := range authProviders {
+		pc := p.HttpHeader
+		if pc == nil {
+			continue
+		}
+
+		// 🚨 SECURITY: An empty discriminator value would match requests without the header.
+		if pc.DiscriminatorHeader != "" && pc.DiscriminatorValue == "" {
+			problems = append(problems, fmt.Sprintf("http-header auth provider at index %d must set discriminatorValue if discriminatorHeader is set", i))
+		}
+		if count < 2 {
+			continue
+		}
+
+		if pc.ConfigID == "" {
+			problems = append(problems, fmt.Sprintf("http-header auth provider at index %d must set configID (required if there is more than 1 http-header auth provider)", i))
+		} else if j, ok := seenConfigIDs[pc.ConfigID]; ok {
+			problems = append(problems, fmt.Sprintf("http-header auth provider at index %d has the same configID as index %d", i, j))
+		} else {
+			seenConfigIDs[pc.ConfigID] = i
+		}
+
+		if pc.DiscriminatorHeader == "" {
+			problems = append(problems, fmt.Sprintf("http-header auth provider at index %d must set discriminatorHeader (required if there is more than 1 http-header auth provider)", i))
+			continue
+		}
+		d := discriminator{header: textproto.CanonicalMIMEHeaderKey(pc.DiscriminatorHeader), value: pc.DiscriminatorValue}
+		if j, ok := seenDiscrimins[d]; ok {
+			problems = append(problems, fmt.Sprintf("http-header auth provider at index %d has the same discriminatorHeader and discriminatorValue as index %d", i, j))
+		} else {
+			seenDiscrimins[d] = i
+		}
 	}
 	return problems
 }
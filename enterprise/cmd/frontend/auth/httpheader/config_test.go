@@ -20,14 +20,48 @@ func TestValidateCustom(t *testing.T) {
 			}},
 			wantProblems: nil,
 		},
-		"multiple": {
+		"multiple without discriminators": {
 			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
 				AuthProviders: []schema.AuthProviders{
 					{HttpHeader: &schema.HTTPHeaderAuthProvider{Type: "http-header"}},
 					{HttpHeader: &schema.HTTPHeaderAuthProvider{Type: "http-header"}},
 				},
 			}},
-			wantProblems: conf.NewSiteProblems("at most 1"),
+			wantProblems: conf.NewSiteProblems(
+				"at index 0 must set configID",
+				"at index 0 must set discriminatorHeader",
+				"at index 1 must set configID",
+				"at index 1 must set discriminatorHeader",
+			),
+		},
+		"multiple with discriminators": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{HttpHeader: &schema.HTTPHeaderAuthProvider{Type: "http-header", ConfigID: "internal", DiscriminatorHeader: "x-proxy", DiscriminatorValue: "internal"}},
+					{HttpHeader: &schema.HTTPHeaderAuthProvider{Type: "http-header", ConfigID: "contractors", DiscriminatorHeader: "x-proxy", DiscriminatorValue: "contractors"}},
+				},
+			}},
+			wantProblems: nil,
+		},
+		"multiple with duplicate discriminators": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{HttpHeader: &schema.HTTPHeaderAuthProvider{Type: "http-header", ConfigID: "a", DiscriminatorHeader: "x-proxy", DiscriminatorValue: "internal"}},
+					{HttpHeader: &schema.HTTPHeaderAuthProvider{Type: "http-header", ConfigID: "a", DiscriminatorHeader: "X-Proxy", DiscriminatorValue: "internal"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems(
+				"at index 1 has the same configID as index 0",
+				"at index 1 has the same discriminatorHeader and discriminatorValue as index 0",
+			),
+		},
+		"discriminator header without value": {
+			input: conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+				AuthProviders: []schema.AuthProviders{
+					{HttpHeader: &schema.HTTPHeaderAuthProvider{Type: "http-header", DiscriminatorHeader: "x-proxy"}},
+				},
+			}},
+			wantProblems: conf.NewSiteProblems("must set discriminatorValue"),
 		},
 	}
 	for name, test := range tests {
@@ -9,12 +9,19 @@ import (
 func init() {
 	go func() {
 		conf.Watch(func() {
-			newPC, _ := getProviderConfig()
-			if newPC == nil {
+			// The group mappings may have changed, so sync the groups of all users again.
+			resetGroupsSynced()
+
+			pcs := getProviderConfigs()
+			if len(pcs) == 0 {
 				providers.Update("httpheader", nil)
 				return
 			}
-			providers.Update("httpheader", []providers.Provider{&provider{c: newPC}})
+			newProviders := make([]providers.Provider, len(pcs))
+			for i, pc := range pcs {
+				newProviders[i] = &provider{c: pc}
+			}
+			providers.Update("httpheader", newProviders)
 		})
 	}()
 }
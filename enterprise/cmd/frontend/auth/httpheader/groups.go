@@ -0,0 +1,119 @@
+package httpheader
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// groupsSyncInterval is how often the organization memberships of a user are synced when the
+// groups header of its requests doesn't change. Requests with a changed groups header are always
+// synced.
+const groupsSyncInterval = 5 * time.Minute
+
+var timeNow = time.Now
+
+type groupsSyncKey struct {
+	configID string
+	userID   int32
+}
+
+type groupsSyncEntry struct {
+	groups   string // the value of the groups header
+	syncedAt time.Time
+}
+
+var (
+	groupsSyncedMu sync.Mutex
+	groupsSynced   = map[groupsSyncKey]groupsSyncEntry{}
+)
+
+func resetGroupsSynced() {
+	groupsSyncedMu.Lock()
+	groupsSynced = map[groupsSyncKey]groupsSyncEntry{}
+	groupsSyncedMu.Unlock()
+}
+
+// syncGroups adds the user to and removes it from the organizations of the provider's group
+// mappings according to the groups in the value of the groups header. It does nothing if the same
+// groups were synced recently.
+func syncGroups(ctx context.Context, pc *schema.HTTPHeaderAuthProvider, userID int32, header string) error {
+	key := groupsSyncKey{configID: pc.ConfigID, userID: userID}
+	groupsSyncedMu.Lock()
+	e, ok := groupsSynced[key]
+	groupsSyncedMu.Unlock()
+	if ok && e.groups == header && timeNow().Sub(e.syncedAt) < groupsSyncInterval {
+		return nil
+	}
+
+	if err := setOrgMemberships(ctx, pc.GroupMappings, userID, parseGroups(header)); err != nil {
+		return err
+	}
+
+	groupsSyncedMu.Lock()
+	groupsSynced[key] = groupsSyncEntry{groups: header, syncedAt: timeNow()}
+	groupsSyncedMu.Unlock()
+	return nil
+}
+
+// parseGroups parses the comma-separated list of groups of a groups header value.
+func parseGroups(header string) map[string]bool {
+	groups := map[string]bool{}
+	for _, g := range strings.Split(header, ",") {
+		if g = strings.TrimSpace(g); g != "" {
+			groups[g] = true
+		}
+	}
+	return groups
+}
+
+// setOrgMemberships makes the user a member of each organization of the mappings if and only if
+// one of the groups maps to the organization. Organizations that don't exist are skipped.
+func setOrgMemberships(ctx context.Context, mappings []*schema.HTTPHeaderGroupMapping, userID int32, groups map[string]bool) error {
+	want := map[string]bool{}
+	for _, m := range mappings {
+		want[m.Organization] = want[m.Organization] || groups[m.Group]
+	}
+	orgNames := make([]string, 0, len(want))
+	for name := range want {
+		orgNames = append(orgNames, name)
+	}
+	sort.Strings(orgNames)
+
+	memberships, err := db.OrgMembers.GetByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	isMember := make(map[int32]bool, len(memberships))
+	for _, m := range memberships {
+		isMember[m.OrgID] = true
+	}
+
+	for _, name := range orgNames {
+		org, err := db.Orgs.GetByName(ctx, name)
+		if _, ok := err.(*db.OrgNotFoundError); ok {
+			log15.Warn("Organization in http-header auth provider groupMappings not found.", "organization", name)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		switch {
+		case want[name] && !isMember[org.ID]:
+			if _, err := db.OrgMembers.Create(ctx, org.ID, userID); err != nil {
+				return err
+			}
+		case !want[name] && isMember[org.ID]:
+			if err := db.OrgMembers.Remove(ctx, org.ID, userID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -28,6 +28,10 @@ var Middleware = &auth.Middleware{
 // https://github.com/bitly/oauth2_proxy and is configured with the http-header auth provider in
 // site config.
 //
+// If there are multiple http-header auth providers (e.g., for multiple auth proxies), the
+// provider for a request is selected by its discriminator header. If the provider has a groups
+// header, the user's organization memberships are synced from it (see syncGroups).
+//
 // TESTING: Use the testproxy test program to test HTTP auth proxy behavior. For example, run `go
 // run cmd/frontend/auth/httpheader/testproxy.go -username=alice` then go to
 // http://localhost:4080. See `-h` for flag help.
@@ -36,9 +40,9 @@ var Middleware = &auth.Middleware{
 func middleware(next http.Handler) http.Handler {
 	const misconfiguredMessage = "Misconfigured http-header auth provider."
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		authProvider, multiple := getProviderConfig()
-		if multiple {
-			log15.Error("At most 1 HTTP header auth provider may be set in site config.")
+		authProvider, misconfigured := getProviderConfig(r)
+		if misconfigured {
+			log15.Error("The HTTP header auth providers in site config are misconfigured (multiple providers must set configID, discriminatorHeader and discriminatorValue).")
 			http.Error(w, misconfiguredMessage, http.StatusInternalServerError)
 			return
 		}
@@ -77,6 +81,7 @@ func middleware(next http.Handler) http.Handler {
 			UserProps: db.NewUser{Username: username},
 			ExternalAccount: extsvc.ExternalAccountSpec{
 				ServiceType: providerType,
+				ServiceID:   authProvider.ConfigID,
 				// Store rawUsername, not normalized username, to prevent two users with distinct
 				// pre-normalization usernames from being merged into the same normalized username
 				// (and therefore letting them each impersonate the other).
@@ -91,6 +96,14 @@ func middleware(next http.Handler) http.Handler {
 			return
 		}
 
+		if authProvider.GroupsHeader != "" {
+			if err := syncGroups(r.Context(), authProvider, userID, r.Header.Get(authProvider.GroupsHeader)); err != nil {
+				log15.Error("unable to sync organization memberships from SSO groups header", "header", authProvider.GroupsHeader, "userID", userID, "err", err)
+				http.Error(w, "unable to sync organization memberships", http.StatusInternalServerError)
+				return
+			}
+		}
+
 		r = r.WithContext(actor.WithActor(r.Context(), &actor.Actor{UID: userID}))
 		next.ServeHTTP(w, r)
 	})
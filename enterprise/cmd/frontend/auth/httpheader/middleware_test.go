@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
 
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
 	"github.com/sourcegraph/sourcegraph/enterprise/internal/license"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
@@ -165,3 +168,176 @@ func TestMiddleware_stripPrefix(t *testing.T) {
 		}
 	})
 }
+
+func TestMiddleware_multipleProviders(t *testing.T) {
+	licensing.MockGetConfiguredProductLicenseInfo = func() (*license.Info, string, error) {
+		return &license.Info{Tags: licensing.EnterpriseTags}, "test-signature", nil
+	}
+	defer func() { licensing.MockGetConfiguredProductLicenseInfo = nil }()
+
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		actor := actor.FromContext(r.Context())
+		if actor.IsAuthenticated() {
+			fmt.Fprintf(w, "user %v", actor.UID)
+		} else {
+			fmt.Fprint(w, "no user")
+		}
+	}))
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		AuthProviders: []schema.AuthProviders{
+			{
+				HttpHeader: &schema.HTTPHeaderAuthProvider{
+					ConfigID:            "internal",
+					UsernameHeader:      "x-internal-user",
+					DiscriminatorHeader: "x-proxy",
+					DiscriminatorValue:  "internal",
+				},
+			},
+			{
+				HttpHeader: &schema.HTTPHeaderAuthProvider{
+					ConfigID:            "contractors",
+					UsernameHeader:      "x-contractor-user",
+					DiscriminatorHeader: "x-proxy",
+					DiscriminatorValue:  "contractors",
+				},
+			},
+		},
+	}})
+	defer conf.Mock(nil)
+
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (userID int32, safeErrMsg string, err error) {
+		switch {
+		case op.ExternalAccount.ServiceID == "internal" && op.ExternalAccount.AccountID == "alice":
+			return 1, "", nil
+		case op.ExternalAccount.ServiceID == "contractors" && op.ExternalAccount.AccountID == "bob":
+			return 2, "", nil
+		}
+		return 0, "safeErr", fmt.Errorf("account %v not found in mock", op.ExternalAccount)
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	tests := map[string]struct {
+		headers map[string]string
+		want    string
+	}{
+		"internal proxy":          {headers: map[string]string{"x-proxy": "internal", "x-internal-user": "alice"}, want: "user 1"},
+		"contractor proxy":        {headers: map[string]string{"x-proxy": "contractors", "x-contractor-user": "bob"}, want: "user 2"},
+		"other provider's header": {headers: map[string]string{"x-proxy": "contractors", "x-internal-user": "alice"}, want: "no user"},
+		"no discriminator":        {headers: map[string]string{"x-internal-user": "alice"}, want: "no user"},
+		"unknown discriminator":   {headers: map[string]string{"x-proxy": "other", "x-internal-user": "alice"}, want: "no user"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/", nil)
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+			handler.ServeHTTP(rr, req)
+			if got := rr.Body.String(); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware_groupsHeader(t *testing.T) {
+	licensing.MockGetConfiguredProductLicenseInfo = func() (*license.Info, string, error) {
+		return &license.Info{Tags: licensing.EnterpriseTags}, "test-signature", nil
+	}
+	defer func() { licensing.MockGetConfiguredProductLicenseInfo = nil }()
+
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "user %v", actor.FromContext(r.Context()).UID)
+	}))
+
+	conf.Mock(&conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		AuthProviders: []schema.AuthProviders{
+			{
+				HttpHeader: &schema.HTTPHeaderAuthProvider{
+					UsernameHeader: "x-user",
+					GroupsHeader:   "x-groups",
+					GroupMappings: []*schema.HTTPHeaderGroupMapping{
+						{Group: "eng", Organization: "engineering"},
+						{Group: "sales", Organization: "sales"},
+						{Group: "deleted", Organization: "deleted-org"},
+					},
+				},
+			},
+		},
+	}})
+	defer conf.Mock(nil)
+	defer resetGroupsSynced()
+
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (int32, string, error) {
+		return 1, "", nil
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	orgs := map[string]int32{"engineering": 10, "sales": 20, "other": 30}
+	db.Mocks.Orgs.GetByName = func(ctx context.Context, name string) (*types.Org, error) {
+		if id, ok := orgs[name]; ok {
+			return &types.Org{ID: id, Name: name}, nil
+		}
+		return nil, &db.OrgNotFoundError{Message: name}
+	}
+	members := map[int32]bool{20: true, 30: true}
+	db.Mocks.OrgMembers.GetByUserID = func(ctx context.Context, userID int32) ([]*types.OrgMembership, error) {
+		var ms []*types.OrgMembership
+		for orgID := range members {
+			ms = append(ms, &types.OrgMembership{OrgID: orgID, UserID: userID})
+		}
+		return ms, nil
+	}
+	var calls int
+	db.Mocks.OrgMembers.Create = func(ctx context.Context, orgID, userID int32) (*types.OrgMembership, error) {
+		calls++
+		members[orgID] = true
+		return &types.OrgMembership{OrgID: orgID, UserID: userID}, nil
+	}
+	db.Mocks.OrgMembers.Remove = func(ctx context.Context, orgID, userID int32) error {
+		calls++
+		delete(members, orgID)
+		return nil
+	}
+	defer func() {
+		db.Mocks.Orgs = db.MockOrgs{}
+		db.Mocks.OrgMembers = db.MockOrgMembers{}
+	}()
+
+	serve := func(groups string) {
+		t.Helper()
+		rr := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("x-user", "alice")
+		req.Header.Set("x-groups", groups)
+		handler.ServeHTTP(rr, req)
+		if got, want := rr.Body.String(), "user 1"; got != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+
+	// The user is added to engineering and removed from sales. The "other" organization is not in
+	// the mappings, so it is not changed.
+	serve("eng, deleted")
+	if want := map[int32]bool{10: true, 30: true}; !reflect.DeepEqual(members, want) {
+		t.Errorf("got memberships %v, want %v", members, want)
+	}
+	if calls != 2 {
+		t.Errorf("got %d membership changes, want 2", calls)
+	}
+
+	// The same groups are not synced again.
+	members[20] = true
+	serve("eng, deleted")
+	if !members[20] {
+		t.Error("got groups synced again, want them not to be synced")
+	}
+
+	// Changed groups are synced.
+	serve("sales")
+	if want := map[int32]bool{20: true, 30: true}; !reflect.DeepEqual(members, want) {
+		t.Errorf("got memberships %v, want %v", members, want)
+	}
+}
@@ -14,8 +14,8 @@ type provider struct {
 }
 
 // ConfigID implements providers.Provider.
-func (provider) ConfigID() providers.ConfigID {
-	return providers.ConfigID{Type: providerType}
+func (p provider) ConfigID() providers.ConfigID {
+	return providers.ConfigID{Type: providerType, ID: p.c.ConfigID}
 }
 
 // Config implements providers.Provider.
@@ -26,7 +26,9 @@ func (p provider) Refresh(context.Context) error { return nil }
 
 // CachedInfo implements providers.Provider.
 func (p provider) CachedInfo() *providers.Info {
-	return &providers.Info{
-		DisplayName: fmt.Sprintf("HTTP authentication proxy (%q header)", textproto.CanonicalMIMEHeaderKey(p.c.UsernameHeader)),
+	displayName := fmt.Sprintf("HTTP authentication proxy (%q header)", textproto.CanonicalMIMEHeaderKey(p.c.UsernameHeader))
+	if p.c.DiscriminatorHeader != "" {
+		displayName = fmt.Sprintf("HTTP authentication proxy (%q header, %s: %s)", textproto.CanonicalMIMEHeaderKey(p.c.UsernameHeader), textproto.CanonicalMIMEHeaderKey(p.c.DiscriminatorHeader), p.c.DiscriminatorValue)
 	}
+	return &providers.Info{DisplayName: displayName}
 }
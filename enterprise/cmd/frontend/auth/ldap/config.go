@@ -0,0 +1,80 @@
+package ldap
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const providerType = "ldap"
+
+// getProvider looks up the registered ldap auth provider with the given ID.
+func getProvider(id string) *provider {
+	p, _ := providers.GetProviderByConfigID(providers.ConfigID{Type: providerType, ID: id}).(*provider)
+	return p
+}
+
+func handleGetProvider(w http.ResponseWriter, id string) (p *provider, handled bool) {
+	p = getProvider(id)
+	if p == nil {
+		log15.Error("No LDAP auth provider found with ID.", "id", id)
+		http.Error(w, "Misconfigured LDAP auth provider.", http.StatusInternalServerError)
+		return nil, true
+	}
+	if p.dir == nil {
+		log15.Error("LDAP auth provider is misconfigured.", "id", p.ConfigID(), "error", p.err)
+		http.Error(w, "Misconfigured LDAP auth provider.", http.StatusInternalServerError)
+		return nil, true
+	}
+	return p, false
+}
+
+func init() {
+	conf.ContributeValidator(validateConfig)
+}
+
+func validateConfig(c conf.Unified) (problems conf.Problems) {
+	seen := map[string]int{}
+	for i, p := range c.AuthProviders {
+		if p.Ldap == nil {
+			continue
+		}
+
+		id := providerConfigID(p.Ldap)
+		if j, ok := seen[id]; ok {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d is duplicate of index %d, ignoring", i, j)))
+		} else {
+			seen[id] = i
+		}
+
+		if u, err := url.Parse(p.Ldap.Url); err != nil || u.Hostname() == "" {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d has an invalid url %q", i, p.Ldap.Url)))
+		} else if u.Scheme == "ldap" && !p.Ldap.StartTLS {
+			problems = append(problems, conf.NewSiteProblem(fmt.Sprintf("LDAP auth provider at index %d sends passwords in cleartext: use an ldaps:// url or set startTLS", i)))
+		}
+	}
+	return problems
+}
+
+// providerConfigID produces a semi-stable identifier for an ldap auth provider config object. It
+// is used to distinguish between multiple auth providers of the same type when signing in. Its
+// value is never persisted, and it must be deterministic.
+func providerConfigID(pc *schema.LDAPAuthProvider) string {
+	if pc.ConfigID != "" {
+		return pc.ConfigID
+	}
+	data, err := json.Marshal(pc)
+	if err != nil {
+		panic(err)
+	}
+	b := sha256.Sum256(data)
+	return base64.RawURLEncoding.EncodeToString(b[:16])
+}
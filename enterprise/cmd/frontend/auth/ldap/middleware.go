@@ -0,0 +1,138 @@
+// Package ldap implements auth via LDAP.
+package ldap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/ldap"
+)
+
+// All LDAP endpoints are under this path prefix.
+const authPrefix = auth.AuthURLPrefix + "/ldap"
+
+// Middleware is middleware for LDAP authentication, adding the sign-in endpoint under the auth
+// path prefix ("/.auth").
+//
+// The sign-in endpoint accepts a POST containing the username and password of the user, checks
+// them against the LDAP server of the provider and, if they are valid, creates a new session for
+// the user (creating the user first if needed).
+//
+// 🚨 SECURITY
+var Middleware = &auth.Middleware{
+	API: func(next http.Handler) http.Handler { return next },
+	App: func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == authPrefix+"/login" {
+				handleSignIn(w, r)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	},
+}
+
+type credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// handleSignIn authenticates the current session with the LDAP credentials in the request body.
+//
+// 🚨 SECURITY
+func handleSignIn(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		http.Error(w, fmt.Sprintf("Unsupported method %s", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	// 🚨 SECURITY: This endpoint is not covered by the CSRF middleware, so require a header that
+	// cross-origin requests can't set without a CORS preflight, to prevent login CSRF.
+	if r.Header.Get("X-Requested-With") == "" {
+		http.Error(w, "Missing X-Requested-With header.", http.StatusBadRequest)
+		return
+	}
+
+	p, handled := handleGetProvider(w, r.URL.Query().Get("pc"))
+	if handled {
+		return
+	}
+
+	var creds credentials
+	if err := json.NewDecoder(r.Body).Decode(&creds); err != nil {
+		http.Error(w, "Could not decode request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	user, err := p.dir.Authenticate(ctx, strings.TrimSpace(creds.Username), creds.Password)
+	if err == ldap.ErrInvalidCredentials {
+		log15.Warn("LDAP authentication failed: invalid credentials.", "username", creds.Username)
+		http.Error(w, "Authentication failed", http.StatusUnauthorized)
+		return
+	} else if err != nil {
+		log15.Error("LDAP authentication failed.", "username", creds.Username, "error", err)
+		http.Error(w, "Authentication failed: error communicating with the LDAP server. Contact a site admin for help.", http.StatusInternalServerError)
+		return
+	}
+
+	actr, safeErrMsg, err := getOrCreateUser(ctx, p, creds.Username, user)
+	if err != nil {
+		log15.Error("LDAP authentication failed: error looking up LDAP-authenticated user.", "error", err, "userErr", safeErrMsg)
+		http.Error(w, safeErrMsg, http.StatusInternalServerError)
+		return
+	}
+
+	if err := session.SetActor(w, r, actr, 0); err != nil {
+		log15.Error("LDAP authentication failed: could not initiate session.", "error", err)
+		http.Error(w, "Authentication failed. Try signing in again (and clearing cookies for the current site). The error was: could not initiate session.", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getOrCreateUser gets or creates the Sourcegraph user of an authenticated LDAP user. It returns
+// the authenticated actor if successful; otherwise it returns a friendly error message
+// (safeErrMsg) that is safe to display to users, and a non-nil err with lower-level error
+// details.
+func getOrCreateUser(ctx context.Context, p *provider, login string, user *ldap.User) (_ *actor.Actor, safeErrMsg string, err error) {
+	unnormalizedUsername := user.Username
+	if unnormalizedUsername == "" {
+		unnormalizedUsername = login
+	}
+	username, err := auth.NormalizeUsername(unnormalizedUsername)
+	if err != nil {
+		return nil, fmt.Sprintf("Error normalizing the username %q. See https://docs.sourcegraph.com/admin/auth/#username-normalization.", unnormalizedUsername), err
+	}
+
+	var data extsvc.ExternalAccountData
+	data.SetAccountData(user)
+
+	allowSignup := p.config.AllowSignup == nil || *p.config.AllowSignup
+	userID, safeErrMsg, err := auth.GetAndSaveUser(ctx, auth.GetAndSaveUserOp{
+		UserProps: db.NewUser{
+			Username:        username,
+			Email:           user.Email,
+			EmailIsVerified: user.Email != "", // the LDAP directory is authoritative
+			DisplayName:     user.DisplayName,
+		},
+		ExternalAccount: extsvc.ExternalAccountSpec{
+			ServiceType: providerType,
+			ServiceID:   p.config.Url,
+			AccountID:   user.DN,
+		},
+		ExternalAccountData: data,
+		CreateIfNotExist:    allowSignup,
+	})
+	if err != nil {
+		return nil, safeErrMsg, err
+	}
+	return actor.FromUser(userID), "", nil
+}
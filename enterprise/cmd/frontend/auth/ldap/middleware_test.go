@@ -0,0 +1,113 @@
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/external/session"
+	"github.com/sourcegraph/sourcegraph/internal/ldap/ldaptest"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestMiddleware(t *testing.T) {
+	cleanup := session.ResetMockSessionStore(t)
+	defer cleanup()
+
+	s := ldaptest.NewServer(
+		ldaptest.NewEntry("uid=alice_zhao,ou=people,dc=example,dc=com",
+			"uid", "alice_zhao", "mail", "alice@example.com", "cn", "Alice Zhao", "userPassword", "alicepw"),
+	)
+	defer s.Close()
+
+	p := newProvider(schema.LDAPAuthProvider{
+		Type:        providerType,
+		Url:         s.URL,
+		StartTLS:    true,
+		Certificate: s.Certificate,
+		UserSearch:  schema.LDAPUserSearch{BaseDN: "ou=people,dc=example,dc=com"},
+	})
+	providers.MockProviders = []providers.Provider{p}
+	defer func() { providers.MockProviders = nil }()
+
+	var calledMock bool
+	auth.MockGetAndSaveUser = func(ctx context.Context, op auth.GetAndSaveUserOp) (userID int32, safeErrMsg string, err error) {
+		calledMock = true
+		if op.UserProps.Username != "alice-zhao" || op.UserProps.Email != "alice@example.com" || !op.UserProps.EmailIsVerified || op.UserProps.DisplayName != "Alice Zhao" {
+			t.Errorf("unexpected user props %+v", op.UserProps)
+		}
+		if !op.CreateIfNotExist {
+			t.Error("expected signup to be allowed by default")
+		}
+		if op.ExternalAccount.ServiceType == "ldap" && op.ExternalAccount.ServiceID == s.URL && op.ExternalAccount.AccountID == "uid=alice_zhao,ou=people,dc=example,dc=com" {
+			return 1, "", nil
+		}
+		return 0, "safeErr", fmt.Errorf("account %v not found in mock", op.ExternalAccount)
+	}
+	defer func() { auth.MockGetAndSaveUser = nil }()
+
+	handler := Middleware.App(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "next")
+	}))
+	loginURL := "http://example.com" + p.CachedInfo().AuthenticationURL
+
+	doRequest := func(method, url, body string, xhr bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		if xhr {
+			req.Header.Set("X-Requested-With", "Sourcegraph")
+		}
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("other path", func(t *testing.T) {
+		if rr := doRequest("GET", "http://example.com/search", "", false); rr.Body.String() != "next" {
+			t.Errorf("expected request to be passed on, got %d %q", rr.Code, rr.Body.String())
+		}
+	})
+
+	for _, tc := range []struct {
+		name   string
+		method string
+		body   string
+		xhr    bool
+		want   int
+	}{
+		{name: "GET", method: "GET", xhr: true, want: http.StatusMethodNotAllowed},
+		{name: "without X-Requested-With", method: "POST", body: `{"username": "alice_zhao", "password": "alicepw"}`, want: http.StatusBadRequest},
+		{name: "wrong password", method: "POST", body: `{"username": "alice_zhao", "password": "wrong"}`, xhr: true, want: http.StatusUnauthorized},
+		{name: "empty password", method: "POST", body: `{"username": "alice_zhao", "password": ""}`, xhr: true, want: http.StatusUnauthorized},
+		{name: "unknown user", method: "POST", body: `{"username": "bob", "password": "alicepw"}`, xhr: true, want: http.StatusUnauthorized},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calledMock = false
+			rr := doRequest(tc.method, loginURL, tc.body, tc.xhr)
+			if rr.Code != tc.want {
+				t.Errorf("have status %d, want %d (body %q)", rr.Code, tc.want, rr.Body.String())
+			}
+			if calledMock {
+				t.Error("user was signed in")
+			}
+		})
+	}
+
+	t.Run("valid credentials", func(t *testing.T) {
+		calledMock = false
+		rr := doRequest("POST", loginURL, `{"username": "alice_zhao", "password": "alicepw"}`, true)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("have status %d, want %d (body %q)", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if !calledMock {
+			t.Error("!calledMock")
+		}
+		if len(rr.Result().Cookies()) == 0 {
+			t.Error("expected a session cookie to be set")
+		}
+	})
+}
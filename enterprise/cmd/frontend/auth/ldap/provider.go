@@ -0,0 +1,60 @@
+package ldap
+
+import (
+	"context"
+	"net/url"
+	"path"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/auth/providers"
+	"github.com/sourcegraph/sourcegraph/internal/ldap"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+type provider struct {
+	config schema.LDAPAuthProvider
+
+	// dir is the directory of the LDAP server, or nil if the config is invalid (see err).
+	dir *ldap.Directory
+	err error
+}
+
+func newProvider(c schema.LDAPAuthProvider) *provider {
+	p := &provider{config: c}
+	p.dir, p.err = ldap.NewDirectory(&p.config)
+	return p
+}
+
+// ConfigID implements providers.Provider.
+func (p *provider) ConfigID() providers.ConfigID {
+	return providers.ConfigID{
+		Type: providerType,
+		ID:   providerConfigID(&p.config),
+	}
+}
+
+// Config implements providers.Provider.
+func (p *provider) Config() schema.AuthProviders {
+	return schema.AuthProviders{Ldap: &p.config}
+}
+
+// Refresh implements providers.Provider.
+func (p *provider) Refresh(context.Context) error {
+	return errors.WithMessage(p.err, "invalid LDAP auth provider config")
+}
+
+// CachedInfo implements providers.Provider.
+func (p *provider) CachedInfo() *providers.Info {
+	info := &providers.Info{
+		ServiceID:   p.config.Url,
+		DisplayName: p.config.DisplayName,
+		AuthenticationURL: (&url.URL{
+			Path:     path.Join(authPrefix, "login"),
+			RawQuery: (url.Values{"pc": []string{providerConfigID(&p.config)}}).Encode(),
+		}).String(),
+	}
+	if info.DisplayName == "" {
+		info.DisplayName = "LDAP"
+	}
+	return info
+}
@@ -16,8 +16,10 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/hooks"
 	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/bitbucketserver"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/explicit"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/github"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/gitlab"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/ldap"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
@@ -71,6 +73,21 @@ func Init(d dbutil.DB, clock func() time.Time) {
 			}
 		}
 
+		explicitProviders, _, _, err := explicitAuthzProviders(ctx, db.ExternalServices, nil)
+		if err != nil {
+			return []*graphqlbackend.Alert{{
+				TypeValue:    graphqlbackend.AlertTypeError,
+				MessageValue: fmt.Sprintf("Unable to fetch external services: %s", err),
+			}}
+		}
+		seen := map[string]bool{}
+		for _, p := range explicitProviders {
+			if !seen[p.ServiceType()] {
+				seen[p.ServiceType()] = true
+				authzTypes = append(authzTypes, explicitServiceTypeNames[p.ServiceType()])
+			}
+		}
+
 		if len(authzTypes) > 0 {
 			return []*graphqlbackend.Alert{{
 				TypeValue:    graphqlbackend.AlertTypeError,
@@ -136,6 +153,9 @@ type ExternalServicesStore interface {
 	ListGitLabConnections(context.Context) ([]*schema.GitLabConnection, error)
 	ListGitHubConnections(context.Context) ([]*schema.GitHubConnection, error)
 	ListBitbucketServerConnections(context.Context) ([]*schema.BitbucketServerConnection, error)
+	ListGitoliteConnections(context.Context) ([]*schema.GitoliteConnection, error)
+	ListPhabricatorConnections(context.Context) ([]*schema.PhabricatorConnection, error)
+	ListOtherExternalServicesConnections(context.Context) ([]*schema.OtherExternalServiceConnection, error)
 }
 
 // ProvidersFromConfig returns the set of permission-related providers derived from the site config.
@@ -146,7 +166,7 @@ func ProvidersFromConfig(
 	ctx context.Context,
 	cfg *conf.Unified,
 	s ExternalServicesStore,
-	db dbutil.DB, // Needed by Bitbucket Server authz provider
+	db dbutil.DB, // Needed by Bitbucket Server and explicit permissions authz providers
 ) (
 	allowAccessByDefault bool,
 	providers []authz.Provider,
@@ -188,6 +208,31 @@ func ProvidersFromConfig(
 		warnings = append(warnings, bbsWarnings...)
 	}
 
+	ldapProviders, ldapProblems, ldapWarnings := ldap.NewAuthzProviders(cfg)
+	providers = append(providers, ldapProviders...)
+	seriousProblems = append(seriousProblems, ldapProblems...)
+	warnings = append(warnings, ldapWarnings...)
+
+	if explicitProviders, explicitProblems, explicitWarnings, err := explicitAuthzProviders(ctx, s, db); err != nil {
+		seriousProblems = append(seriousProblems, err.Error())
+	} else {
+		// 🚨 SECURITY: Only one authz provider is consulted per code host, so refuse to guess
+		// whether explicit permissions or LDAP authorization rules apply to a code host.
+		ldapServiceIDs := make(map[string]bool, len(ldapProviders))
+		for _, p := range ldapProviders {
+			ldapServiceIDs[p.ServiceID()] = true
+		}
+		for _, p := range explicitProviders {
+			if ldapServiceIDs[p.ServiceID()] {
+				explicitProblems = append(explicitProblems, fmt.Sprintf("The repositories of the code host %s have both explicit permissions and LDAP authorization rules", p.ServiceID()))
+				continue
+			}
+			providers = append(providers, p)
+		}
+		seriousProblems = append(seriousProblems, explicitProblems...)
+		warnings = append(warnings, explicitWarnings...)
+	}
+
 	// 🚨 SECURITY: Warn the admin when both code host authz provider and the permissions user mapping are configured.
 	if cfg.SiteConfiguration.PermissionsUserMapping != nil &&
 		cfg.SiteConfiguration.PermissionsUserMapping.Enabled && len(providers) > 0 {
@@ -204,6 +249,34 @@ func ProvidersFromConfig(
 	return allowAccessByDefault, providers, seriousProblems, warnings
 }
 
+// explicitServiceTypeNames are the display names of the service types of explicit permissions
+// authz providers.
+var explicitServiceTypeNames = map[string]string{
+	"gitolite":    "Gitolite",
+	"phabricator": "Phabricator",
+	"other":       "other Git hosts",
+}
+
+// explicitAuthzProviders returns the explicit permissions authz providers of the Gitolite,
+// Phabricator and other external services.
+func explicitAuthzProviders(ctx context.Context, s ExternalServicesStore, db dbutil.DB) (providers []authz.Provider, problems, warnings []string, err error) {
+	gitolites, err := s.ListGitoliteConnections(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Could not load Gitolite external service configs: %s", err)
+	}
+	phabricators, err := s.ListPhabricatorConnections(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Could not load Phabricator external service configs: %s", err)
+	}
+	others, err := s.ListOtherExternalServicesConnections(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("Could not load Git host external service configs: %s", err)
+	}
+
+	providers, problems, warnings = explicit.NewAuthzProviders(gitolites, phabricators, others, edb.NewPermsStore(db, time.Now))
+	return providers, problems, warnings, nil
+}
+
 func init() {
 	// Report any authz provider problems in external configs.
 	conf.ContributeWarning(func(cfg conf.Unified) (problems conf.Problems) {
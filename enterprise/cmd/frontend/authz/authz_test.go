@@ -554,6 +554,9 @@ type fakeStore struct {
 	gitlabs          []*schema.GitLabConnection
 	githubs          []*schema.GitHubConnection
 	bitbucketServers []*schema.BitbucketServerConnection
+	gitolites        []*schema.GitoliteConnection
+	phabricators     []*schema.PhabricatorConnection
+	others           []*schema.OtherExternalServiceConnection
 }
 
 func (s fakeStore) ListGitHubConnections(context.Context) ([]*schema.GitHubConnection, error) {
@@ -567,3 +570,15 @@ func (s fakeStore) ListGitLabConnections(context.Context) ([]*schema.GitLabConne
 func (s fakeStore) ListBitbucketServerConnections(context.Context) ([]*schema.BitbucketServerConnection, error) {
 	return s.bitbucketServers, nil
 }
+
+func (s fakeStore) ListGitoliteConnections(context.Context) ([]*schema.GitoliteConnection, error) {
+	return s.gitolites, nil
+}
+
+func (s fakeStore) ListPhabricatorConnections(context.Context) ([]*schema.PhabricatorConnection, error) {
+	return s.phabricators, nil
+}
+
+func (s fakeStore) ListOtherExternalServicesConnections(context.Context) ([]*schema.OtherExternalServiceConnection, error) {
+	return s.others, nil
+}
@@ -0,0 +1,44 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+)
+
+// The names of the event log entries of access grants.
+const (
+	AccessGrantCreatedEvent = "RepositoryAccessGranted"
+	AccessGrantRevokedEvent = "RepositoryAccessGrantRevoked"
+	AccessGrantExpiredEvent = "RepositoryAccessGrantExpired"
+)
+
+// NewAccessGrantEvent returns the event log entry of an access grant. The userID is the user who
+// caused the event, or 0 if the event was caused by the system (e.g. on expiry).
+func NewAccessGrantEvent(name string, userID int32, g *authz.AccessGrant, now time.Time) *db.Event {
+	arg := struct {
+		ID        int64     `json:"id"`
+		RepoID    int32     `json:"repo_id"`
+		UserID    int32     `json:"user_id,omitempty"`
+		OrgID     int32     `json:"org_id,omitempty"`
+		Reason    string    `json:"reason,omitempty"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}{g.ID, g.RepoID, g.UserID, g.OrgID, g.Reason, g.ExpiresAt}
+
+	// Marshaling a struct of plain values never fails.
+	b, _ := json.Marshal(arg)
+	e := &db.Event{
+		Name:      name,
+		UserID:    uint32(userID),
+		Argument:  b,
+		Source:    "BACKEND",
+		Timestamp: now.UTC(),
+	}
+	// Event log entries must identify either a user or an anonymous user.
+	if userID == 0 {
+		e.AnonymousUserID = "backend"
+	}
+	return e
+}
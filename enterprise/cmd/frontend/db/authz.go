@@ -137,6 +137,28 @@ func (s *authzStore) AuthorizedRepos(ctx context.Context, args *db.AuthorizedRep
 	return filtered, nil
 }
 
+// AccessGrantedRepos returns the repositories in the candidate list that the user has active
+// time-limited access grants for, which implements the db.AuthzStore interface. Access grants
+// only grant read access.
+func (s *authzStore) AccessGrantedRepos(ctx context.Context, args *db.AuthorizedReposArgs) ([]*types.Repo, error) {
+	if len(args.Repos) == 0 || args.UserID <= 0 || !authz.Read.Include(args.Perm) {
+		return []*types.Repo{}, nil
+	}
+
+	ids, err := s.store.AccessGrantedRepoIDs(ctx, args.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	granted := make([]*types.Repo, 0, ids.GetCardinality())
+	for _, r := range args.Repos {
+		if ids.Contains(uint32(r.ID)) {
+			granted = append(granted, r)
+		}
+	}
+	return granted, nil
+}
+
 // RevokeUserPermissions deletes both effective and pending permissions that could be related to a user,
 // which implements the db.AuthzStore interface. It proactively clean up left-over pending permissions to
 // prevent accidental reuse (i.e. another user with same username or email address(es) but not the same person).
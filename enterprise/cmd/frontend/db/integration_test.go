@@ -42,6 +42,8 @@ func TestIntegration_PermsStore(t *testing.T) {
 		{"PermsStore/UserIDsWithOldestPerms", testPermsStore_UserIDsWithOldestPerms(db)},
 		{"PermsStore/ReposIDsWithOldestPerms", testPermsStore_ReposIDsWithOldestPerms(db)},
 		{"PermsStore/Metrics", testPermsStore_Metrics(db)},
+
+		{"PermsStore/AccessGrants", testPermsStore_AccessGrants(db)},
 	} {
 		t.Run(tc.name, tc.test)
 	}
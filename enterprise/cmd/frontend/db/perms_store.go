@@ -1521,6 +1521,207 @@ WHERE perms.repo_id NOT IN
 	return m, nil
 }
 
+// accessGrantNotFoundError is returned when an access grant doesn't exist or isn't active.
+type accessGrantNotFoundError struct {
+	id int64
+}
+
+func (e accessGrantNotFoundError) Error() string {
+	return fmt.Sprintf("active access grant %d not found", e.id)
+}
+
+func (accessGrantNotFoundError) NotFound() bool { return true }
+
+// CreateAccessGrant inserts a new access grant and sets its ID and CreatedAt.
+func (s *PermsStore) CreateAccessGrant(ctx context.Context, g *authz.AccessGrant) (err error) {
+	if Mocks.Perms.CreateAccessGrant != nil {
+		return Mocks.Perms.CreateAccessGrant(ctx, g)
+	}
+
+	ctx, save := s.observe(ctx, "CreateAccessGrant", "")
+	defer func() { save(&err, g.TracingFields()...) }()
+
+	g.CreatedAt = s.clock()
+	q := sqlf.Sprintf(`
+-- source: enterprise/cmd/frontend/db/perms_store.go:PermsStore.CreateAccessGrant
+INSERT INTO repo_access_grants
+  (repo_id, user_id, org_id, creator_user_id, reason, expires_at, created_at)
+VALUES
+  (%s, %s, %s, %s, %s, %s, %s)
+RETURNING id
+`, g.RepoID, nullInt32Column(g.UserID), nullInt32Column(g.OrgID), nullInt32Column(g.CreatorUserID),
+		g.Reason, g.ExpiresAt, g.CreatedAt)
+	return s.execute(ctx, q, &g.ID)
+}
+
+// AccessGrantsListOptions contains options for listing access grants.
+type AccessGrantsListOptions struct {
+	// Only list grants of the repository.
+	RepoID int32
+	// Only list grants to the user.
+	UserID int32
+	// Only list grants to the organization.
+	OrgID int32
+	// Only list grants that are neither revoked nor expired.
+	OnlyActive bool
+}
+
+// ListAccessGrants returns the access grants matching the options, newest first.
+func (s *PermsStore) ListAccessGrants(ctx context.Context, opts AccessGrantsListOptions) (_ []*authz.AccessGrant, err error) {
+	if Mocks.Perms.ListAccessGrants != nil {
+		return Mocks.Perms.ListAccessGrants(ctx, opts)
+	}
+
+	ctx, save := s.observe(ctx, "ListAccessGrants", "")
+	defer func() { save(&err) }()
+
+	conds := []*sqlf.Query{sqlf.Sprintf("TRUE")}
+	if opts.RepoID != 0 {
+		conds = append(conds, sqlf.Sprintf("repo_id = %s", opts.RepoID))
+	}
+	if opts.UserID != 0 {
+		conds = append(conds, sqlf.Sprintf("user_id = %s", opts.UserID))
+	}
+	if opts.OrgID != 0 {
+		conds = append(conds, sqlf.Sprintf("org_id = %s", opts.OrgID))
+	}
+	if opts.OnlyActive {
+		conds = append(conds, sqlf.Sprintf("revoked_at IS NULL AND expires_at > %s", s.clock()))
+	}
+
+	q := sqlf.Sprintf(`
+-- source: enterprise/cmd/frontend/db/perms_store.go:PermsStore.ListAccessGrants
+SELECT `+accessGrantColumns+`
+FROM repo_access_grants
+WHERE %s
+ORDER BY id DESC
+`, sqlf.Join(conds, "AND"))
+	return s.loadAccessGrants(ctx, q)
+}
+
+// RevokeAccessGrant revokes the active access grant with the given ID and returns it.
+func (s *PermsStore) RevokeAccessGrant(ctx context.Context, id int64) (_ *authz.AccessGrant, err error) {
+	if Mocks.Perms.RevokeAccessGrant != nil {
+		return Mocks.Perms.RevokeAccessGrant(ctx, id)
+	}
+
+	ctx, save := s.observe(ctx, "RevokeAccessGrant", "")
+	defer func() { save(&err, otlog.Int64("id", id)) }()
+
+	now := s.clock()
+	q := sqlf.Sprintf(`
+-- source: enterprise/cmd/frontend/db/perms_store.go:PermsStore.RevokeAccessGrant
+UPDATE repo_access_grants
+SET revoked_at = %s
+WHERE id = %s
+AND revoked_at IS NULL
+AND expires_at > %s
+RETURNING `+accessGrantColumns, now, id, now)
+	gs, err := s.loadAccessGrants(ctx, q)
+	if err != nil {
+		return nil, err
+	} else if len(gs) == 0 {
+		return nil, accessGrantNotFoundError{id: id}
+	}
+	return gs[0], nil
+}
+
+// RevokeExpiredAccessGrants revokes all access grants that have expired but haven't been
+// revoked yet, and returns them.
+func (s *PermsStore) RevokeExpiredAccessGrants(ctx context.Context) (_ []*authz.AccessGrant, err error) {
+	if Mocks.Perms.RevokeExpiredAccessGrants != nil {
+		return Mocks.Perms.RevokeExpiredAccessGrants(ctx)
+	}
+
+	ctx, save := s.observe(ctx, "RevokeExpiredAccessGrants", "")
+	defer func() { save(&err) }()
+
+	now := s.clock()
+	q := sqlf.Sprintf(`
+-- source: enterprise/cmd/frontend/db/perms_store.go:PermsStore.RevokeExpiredAccessGrants
+UPDATE repo_access_grants
+SET revoked_at = %s
+WHERE revoked_at IS NULL
+AND expires_at <= %s
+RETURNING `+accessGrantColumns, now, now)
+	return s.loadAccessGrants(ctx, q)
+}
+
+// AccessGrantedRepoIDs returns the IDs of the repositories the user has active access grants
+// for, either directly or through the membership of an organization.
+func (s *PermsStore) AccessGrantedRepoIDs(ctx context.Context, userID int32) (_ *roaring.Bitmap, err error) {
+	if Mocks.Perms.AccessGrantedRepoIDs != nil {
+		return Mocks.Perms.AccessGrantedRepoIDs(ctx, userID)
+	}
+
+	ctx, save := s.observe(ctx, "AccessGrantedRepoIDs", "")
+	defer func() { save(&err, otlog.Int32("userID", userID)) }()
+
+	q := sqlf.Sprintf(`
+-- source: enterprise/cmd/frontend/db/perms_store.go:PermsStore.AccessGrantedRepoIDs
+SELECT DISTINCT repo_id
+FROM repo_access_grants
+WHERE revoked_at IS NULL
+AND expires_at > %s
+AND (
+  user_id = %s
+  OR org_id IN (SELECT org_id FROM org_members WHERE user_id = %s)
+)
+`, s.clock(), userID, userID)
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := roaring.NewBitmap()
+	for rows.Next() {
+		var id uint32
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids.Add(id)
+	}
+	return ids, rows.Err()
+}
+
+const accessGrantColumns = `id, repo_id, user_id, org_id, creator_user_id, reason, expires_at, revoked_at, created_at`
+
+func (s *PermsStore) loadAccessGrants(ctx context.Context, q *sqlf.Query) ([]*authz.AccessGrant, error) {
+	rows, err := s.db.QueryContext(ctx, q.Query(sqlf.PostgresBindVar), q.Args()...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var gs []*authz.AccessGrant
+	for rows.Next() {
+		var g authz.AccessGrant
+		if err = rows.Scan(
+			&g.ID,
+			&g.RepoID,
+			&dbutil.NullInt32{N: &g.UserID},
+			&dbutil.NullInt32{N: &g.OrgID},
+			&dbutil.NullInt32{N: &g.CreatorUserID},
+			&g.Reason,
+			&g.ExpiresAt,
+			&dbutil.NullTime{Time: &g.RevokedAt},
+			&g.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		gs = append(gs, &g)
+	}
+	return gs, rows.Err()
+}
+
+func nullInt32Column(n int32) *int32 {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
+
 // tx begins a new transaction.
 func (s *PermsStore) tx(ctx context.Context) (*sql.Tx, error) {
 	switch t := s.db.(type) {
@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 
+	"github.com/RoaringBitmap/roaring"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 )
@@ -18,4 +19,9 @@ type MockPerms struct {
 	ListPendingUsers             func(ctx context.Context) ([]string, error)
 	ListExternalAccounts         func(ctx context.Context, userID int32) ([]*extsvc.ExternalAccount, error)
 	GetUserIDsByExternalAccounts func(ctx context.Context, accounts *extsvc.ExternalAccounts) (map[string]int32, error)
+	CreateAccessGrant            func(ctx context.Context, g *authz.AccessGrant) error
+	ListAccessGrants             func(ctx context.Context, opts AccessGrantsListOptions) ([]*authz.AccessGrant, error)
+	RevokeAccessGrant            func(ctx context.Context, id int64) (*authz.AccessGrant, error)
+	RevokeExpiredAccessGrants    func(ctx context.Context) ([]*authz.AccessGrant, error)
+	AccessGrantedRepoIDs         func(ctx context.Context, userID int32) (*roaring.Bitmap, error)
 }
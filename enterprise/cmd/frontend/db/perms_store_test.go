@@ -2219,3 +2219,90 @@ func testPermsStore_Metrics(db *sql.DB) func(*testing.T) {
 		}
 	}
 }
+
+func testPermsStore_AccessGrants(db *sql.DB) func(*testing.T) {
+	return func(t *testing.T) {
+		current := clock()
+		s := NewPermsStore(db, func() time.Time { return current })
+		t.Cleanup(func() {
+			if t.Failed() {
+				return
+			}
+			q := `TRUNCATE TABLE repo_access_grants, org_members, orgs, users, repo RESTART IDENTITY CASCADE;`
+			if err := s.execute(context.Background(), sqlf.Sprintf(q)); err != nil {
+				t.Fatal(err)
+			}
+		})
+
+		ctx := context.Background()
+
+		qs := []*sqlf.Query{
+			sqlf.Sprintf(`INSERT INTO users(username) VALUES('alice')`),            // ID=1
+			sqlf.Sprintf(`INSERT INTO users(username) VALUES('bob')`),              // ID=2
+			sqlf.Sprintf(`INSERT INTO orgs(name) VALUES('acme')`),                  // ID=1
+			sqlf.Sprintf(`INSERT INTO org_members(org_id, user_id) VALUES(1, 2)`),  // bob is a member of acme
+			sqlf.Sprintf(`INSERT INTO repo(name, private) VALUES('repo_1', TRUE)`), // ID=1
+			sqlf.Sprintf(`INSERT INTO repo(name, private) VALUES('repo_2', TRUE)`), // ID=2
+			sqlf.Sprintf(`INSERT INTO repo(name, private) VALUES('repo_3', TRUE)`), // ID=3
+		}
+		for _, q := range qs {
+			if err := s.execute(ctx, q); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		grants := []*authz.AccessGrant{
+			{RepoID: 1, UserID: 1, CreatorUserID: 2, Reason: "incident", ExpiresAt: current.Add(time.Hour)},
+			{RepoID: 2, OrgID: 1, ExpiresAt: current.Add(2 * time.Hour)},
+			{RepoID: 3, UserID: 1, ExpiresAt: current.Add(3 * time.Hour)},
+		}
+		for _, g := range grants {
+			if err := s.CreateAccessGrant(ctx, g); err != nil {
+				t.Fatal(err)
+			}
+		}
+		equal(t, "IDs", []int64{1, 2, 3}, []int64{grants[0].ID, grants[1].ID, grants[2].ID})
+
+		repoIDs := func(userID int32) []int {
+			ids, err := s.AccessGrantedRepoIDs(ctx, userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return bitmapToArray(ids)
+		}
+		equal(t, "alice's repos", []int{1, 3}, repoIDs(1))
+		equal(t, "bob's repos", []int{2}, repoIDs(2))
+
+		have, err := s.ListAccessGrants(ctx, AccessGrantsListOptions{UserID: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		equal(t, "alice's grants", []*authz.AccessGrant{grants[2], grants[0]}, have)
+
+		revoked, err := s.RevokeAccessGrant(ctx, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		equal(t, "revoked at", current, revoked.RevokedAt)
+		if _, err = s.RevokeAccessGrant(ctx, 3); err == nil {
+			t.Fatal("expected an error revoking a revoked grant")
+		}
+
+		// The first grant expires.
+		current = current.Add(time.Hour)
+		equal(t, "alice's repos after expiry", []int{}, repoIDs(1))
+
+		expired, err := s.RevokeExpiredAccessGrants(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		grants[0].RevokedAt = current
+		equal(t, "expired grants", []*authz.AccessGrant{grants[0]}, expired)
+
+		have, err = s.ListAccessGrants(ctx, AccessGrantsListOptions{OnlyActive: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		equal(t, "active grants", []*authz.AccessGrant{grants[1]}, have)
+	}
+}
@@ -0,0 +1,66 @@
+package explicit
+
+import (
+	"fmt"
+
+	"github.com/goware/urlx"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitolite"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// NewAuthzProviders returns the set of explicit permissions authz providers derived from the
+// Gitolite, Phabricator and other external services whose authorization is set. It also returns
+// any validation problems with the config, separating these into "serious problems" and
+// "warnings". "Serious problems" are those that should make Sourcegraph set
+// authz.allowAccessByDefault to false. "Warnings" are all other validation problems.
+func NewAuthzProviders(
+	gitolites []*schema.GitoliteConnection,
+	phabricators []*schema.PhabricatorConnection,
+	others []*schema.OtherExternalServiceConnection,
+	store permsStore,
+) (ps []authz.Provider, problems []string, warnings []string) {
+	seen := map[string]bool{}
+	add := func(serviceType, serviceID string) {
+		if seen[serviceID] {
+			return
+		}
+		seen[serviceID] = true
+		ps = append(ps, NewProvider(serviceType, serviceID, store))
+	}
+
+	for _, c := range gitolites {
+		if c.Authorization != nil {
+			add(gitolite.ServiceType, gitolite.ServiceID(c.Host))
+		}
+	}
+
+	for _, c := range phabricators {
+		if c.Authorization == nil {
+			continue
+		}
+		serviceID, err := urlx.NormalizeString(c.Url)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Could not parse URL for Phabricator external service config %s: %s", c.Url, err))
+			continue
+		}
+		add("phabricator", serviceID)
+	}
+
+	for _, c := range others {
+		if c.Authorization == nil {
+			continue
+		}
+		serviceIDs, err := repos.OtherServiceIDs(c)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("Could not parse the repository URLs of the Git host external service config %s: %s", c.Url, err))
+			continue
+		}
+		for _, serviceID := range serviceIDs {
+			add("other", serviceID)
+		}
+	}
+
+	return ps, problems, warnings
+}
@@ -0,0 +1,101 @@
+// Package explicit implements an authz provider for the repositories of code hosts that can't
+// enforce repository permissions themselves (Gitolite, Phabricator and other Git hosts). Site
+// admins set the permissions of these repositories explicitly, and they are stored in the
+// permissions tables.
+package explicit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+)
+
+// permsStore loads the permissions of users. It is implemented by *db.PermsStore.
+type permsStore interface {
+	LoadUserPermissions(ctx context.Context, p *authz.UserPermissions) error
+}
+
+// Provider is an authz provider for the repositories of a code host whose permissions are set
+// explicitly.
+type Provider struct {
+	serviceType string
+	serviceID   string
+	store       permsStore
+}
+
+// NewProvider returns a Provider for the repositories of the code host with the given service
+// type and ID.
+func NewProvider(serviceType, serviceID string, store permsStore) *Provider {
+	return &Provider{
+		serviceType: serviceType,
+		serviceID:   serviceID,
+		store:       store,
+	}
+}
+
+var _ authz.Provider = (*Provider)(nil)
+
+// RepoPerms implements the authz.Provider interface. It grants read access to the repositories
+// the user of the account was explicitly granted access to. Users without an account get no
+// access.
+func (p *Provider) RepoPerms(ctx context.Context, account *extsvc.ExternalAccount, repos []*types.Repo) ([]authz.RepoPerms, error) {
+	if account == nil || len(repos) == 0 {
+		return nil, nil
+	}
+	if account.ServiceType != p.serviceType || account.ServiceID != p.serviceID {
+		return nil, fmt.Errorf("not an account of the code host: %+v", account.ExternalAccountSpec)
+	}
+
+	// 🚨 SECURITY: The account must be the one FetchAccount derived from the user, so that
+	// accounts can't be used to assume the permissions of other users.
+	if account.AccountID != strconv.Itoa(int(account.UserID)) {
+		return nil, fmt.Errorf("account %q doesn't belong to user %d", account.AccountID, account.UserID)
+	}
+
+	perms := &authz.UserPermissions{
+		UserID: account.UserID,
+		Perm:   authz.Read, // Note: We currently only support read for repository permissions.
+		Type:   authz.PermRepos,
+	}
+	if err := p.store.LoadUserPermissions(ctx, perms); err == authz.ErrPermsNotFound {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return perms.AuthorizedRepos(repos), nil
+}
+
+// FetchAccount implements the authz.Provider interface. Explicit permissions are granted to
+// Sourcegraph users, so the account is identified by the ID of the user.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
+	if user == nil {
+		return nil, nil
+	}
+	return &extsvc.ExternalAccount{
+		UserID: user.ID,
+		ExternalAccountSpec: extsvc.ExternalAccountSpec{
+			ServiceType: p.serviceType,
+			ServiceID:   p.serviceID,
+			AccountID:   strconv.Itoa(int(user.ID)),
+		},
+	}, nil
+}
+
+// ServiceID implements the authz.Provider interface.
+func (p *Provider) ServiceID() string {
+	return p.serviceID
+}
+
+// ServiceType implements the authz.Provider interface.
+func (p *Provider) ServiceType() string {
+	return p.serviceType
+}
+
+// Validate implements the authz.Provider interface.
+func (p *Provider) Validate() (problems []string) {
+	return nil
+}
@@ -0,0 +1,141 @@
+package explicit
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const codeHost = "git@gitolite.example.com"
+
+type fakeStore map[int32][]uint32
+
+func (s fakeStore) LoadUserPermissions(ctx context.Context, p *authz.UserPermissions) error {
+	ids, ok := s[p.UserID]
+	if !ok {
+		return authz.ErrPermsNotFound
+	}
+	p.IDs = roaring.BitmapOf(ids...)
+	return nil
+}
+
+func TestProvider_RepoPerms(t *testing.T) {
+	p := NewProvider("gitolite", codeHost, fakeStore{1: {1, 3}, 2: {}})
+
+	var repos []*types.Repo
+	for i, name := range []string{"firmware/boot", "firmware/radio", "web"} {
+		repos = append(repos, &types.Repo{ID: api.RepoID(i + 1), Name: api.RepoName("gitolite.example.com/" + name)})
+	}
+
+	account := func(userID int32) *extsvc.ExternalAccount {
+		acct, err := p.FetchAccount(context.Background(), &types.User{ID: userID}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return acct
+	}
+
+	for _, tc := range []struct {
+		name    string
+		account *extsvc.ExternalAccount
+		want    []string
+	}{
+		{
+			name: "no account",
+		},
+		{
+			name:    "user with permissions",
+			account: account(1),
+			want:    []string{"gitolite.example.com/firmware/boot", "gitolite.example.com/web"},
+		},
+		{
+			name:    "user without permissions",
+			account: account(2),
+		},
+		{
+			name:    "user never granted permissions",
+			account: account(3),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			perms, err := p.RepoPerms(context.Background(), tc.account, repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var have []string
+			for _, p := range perms {
+				if p.Perms != authz.Read {
+					t.Errorf("unexpected perms %s for %s", p.Perms, p.Repo.Name)
+				}
+				have = append(have, string(p.Repo.Name))
+			}
+			if !reflect.DeepEqual(have, tc.want) {
+				t.Errorf("have %v, want %v", have, tc.want)
+			}
+		})
+	}
+
+	t.Run("account of another user", func(t *testing.T) {
+		acct := account(2)
+		acct.AccountID = "1"
+		if _, err := p.RepoPerms(context.Background(), acct, repos); err == nil {
+			t.Error("expected an error")
+		}
+	})
+
+	t.Run("account of another code host", func(t *testing.T) {
+		acct := account(1)
+		acct.ServiceID = "https://phabricator.example.com/"
+		if _, err := p.RepoPerms(context.Background(), acct, repos); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestNewAuthzProviders(t *testing.T) {
+	ps, problems, _ := NewAuthzProviders(
+		[]*schema.GitoliteConnection{
+			{Host: codeHost, Authorization: &schema.GitoliteAuthorization{}},
+			{Host: "git@other-gitolite.example.com"},
+		},
+		[]*schema.PhabricatorConnection{
+			{Url: "https://phabricator.example.com", Authorization: &schema.PhabricatorAuthorization{}},
+		},
+		[]*schema.OtherExternalServiceConnection{
+			{
+				Url:           "https://git.example.com/repos",
+				Repos:         []string{"a", "b"},
+				Authorization: &schema.OtherExternalServiceAuthorization{},
+			},
+			{
+				Url:           "https://git.example.com",
+				Repos:         []string{"c"},
+				Authorization: &schema.OtherExternalServiceAuthorization{},
+			},
+		},
+		fakeStore{},
+	)
+	if len(problems) > 0 {
+		t.Fatalf("unexpected problems: %v", problems)
+	}
+
+	var have []string
+	for _, p := range ps {
+		have = append(have, p.ServiceType()+" "+p.ServiceID())
+	}
+	want := []string{
+		"gitolite " + codeHost,
+		"phabricator https://phabricator.example.com",
+		"other https://git.example.com",
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
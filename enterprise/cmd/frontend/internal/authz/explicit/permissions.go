@@ -0,0 +1,139 @@
+package explicit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// RepoUsers is the full set of users who can read a repository. Users are identified by their
+// usernames or verified emails.
+type RepoUsers struct {
+	Repository string   `json:"repository"`
+	Users      []string `json:"users"`
+}
+
+// badRequestError is an error caused by invalid permissions.
+type badRequestError struct {
+	msg string
+}
+
+func (e badRequestError) Error() string    { return e.msg }
+func (e badRequestError) BadRequest() bool { return true }
+
+// SetRepoPermissions replaces the explicit permissions of the repositories. The permissions of
+// no repository are changed if any repository isn't governed by an explicit permissions
+// provider, or any user doesn't exist.
+//
+// 🚨 SECURITY: Callers must ensure that the current user is a site admin.
+func SetRepoPermissions(ctx context.Context, store *edb.PermsStore, rus []*RepoUsers) (err error) {
+	governed := map[string]bool{}
+	_, providers := authz.GetProviders()
+	for _, p := range providers {
+		if p, ok := p.(*Provider); ok {
+			governed[p.ServiceType()+":"+p.ServiceID()] = true
+		}
+	}
+
+	perms := make([]*authz.RepoPermissions, 0, len(rus))
+	var bindIDs []string
+	for _, ru := range rus {
+		repo, err := db.Repos.GetByName(ctx, api.RepoName(ru.Repository))
+		if err != nil {
+			return err
+		}
+		if !governed[repo.ExternalRepo.ServiceType+":"+repo.ExternalRepo.ServiceID] {
+			return badRequestError{fmt.Sprintf("the permissions of repository %q can't be set explicitly, because the authorization of its external service isn't set", ru.Repository)}
+		}
+		perms = append(perms, &authz.RepoPermissions{
+			RepoID:  int32(repo.ID),
+			Perm:    authz.Read, // Note: We currently only support read for repository permissions.
+			UserIDs: roaring.NewBitmap(),
+		})
+		bindIDs = append(bindIDs, ru.Users...)
+	}
+
+	userIDs, err := userIDsByBindIDs(ctx, bindIDs)
+	if err != nil {
+		return err
+	}
+	for i, ru := range rus {
+		for _, bindID := range ru.Users {
+			if id, ok := userIDs[strings.TrimSpace(bindID)]; ok {
+				perms[i].UserIDs.Add(uint32(id))
+			}
+		}
+	}
+
+	txs, err := store.Transact(ctx)
+	if err != nil {
+		return errors.Wrap(err, "start transaction")
+	}
+	defer txs.Done(&err)
+
+	for _, p := range perms {
+		if err = txs.SetRepoPermissions(ctx, p); err != nil {
+			return errors.Wrap(err, "set repository permissions")
+		}
+	}
+	return nil
+}
+
+// userIDsByBindIDs returns the IDs of the users with the given usernames or verified emails,
+// keyed by the bind IDs. It returns an error listing the bind IDs no user has.
+func userIDsByBindIDs(ctx context.Context, bindIDs []string) (map[string]int32, error) {
+	var usernames, emails []string
+	for _, bindID := range bindIDs {
+		// Usernames can't contain "@".
+		switch bindID = strings.TrimSpace(bindID); {
+		case bindID == "":
+		case strings.Contains(bindID, "@"):
+			emails = append(emails, bindID)
+		default:
+			usernames = append(usernames, bindID)
+		}
+	}
+
+	userIDs := make(map[string]int32, len(bindIDs))
+	if len(usernames) > 0 {
+		users, err := db.Users.GetByUsernames(ctx, usernames...)
+		if err != nil {
+			return nil, err
+		}
+		for _, u := range users {
+			userIDs[u.Username] = u.ID
+		}
+	}
+	if len(emails) > 0 {
+		// 🚨 SECURITY: It is critical to ensure only verified emails identify users.
+		verified, err := db.UserEmails.GetVerifiedEmails(ctx, emails...)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range verified {
+			userIDs[e.Email] = e.UserID
+		}
+	}
+
+	var unknown []string
+	seen := map[string]bool{}
+	for _, bindID := range append(usernames, emails...) {
+		if _, ok := userIDs[bindID]; !ok && !seen[bindID] {
+			seen[bindID] = true
+			unknown = append(unknown, bindID)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, badRequestError{"no users with the usernames or verified emails " + strings.Join(unknown, ", ")}
+	}
+	return userIDs, nil
+}
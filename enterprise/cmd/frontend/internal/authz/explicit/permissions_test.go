@@ -0,0 +1,164 @@
+package explicit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+func TestSetRepoPermissions(t *testing.T) {
+	authz.SetProviders(false, []authz.Provider{NewProvider("gitolite", codeHost, fakeStore{})})
+	defer authz.SetProviders(true, nil)
+
+	db.Mocks.Repos.GetByName = func(ctx context.Context, name api.RepoName) (*types.Repo, error) {
+		repo := &types.Repo{ID: 1, Name: name}
+		repo.ExternalRepo = api.ExternalRepoSpec{ServiceType: "gitolite", ServiceID: codeHost}
+		if strings.HasPrefix(string(name), "github.com/") {
+			repo.ID = 2
+			repo.ExternalRepo = api.ExternalRepoSpec{ServiceType: "github", ServiceID: "https://github.com/"}
+		}
+		return repo, nil
+	}
+	db.Mocks.Users.GetByUsernames = func(ctx context.Context, usernames ...string) ([]*types.User, error) {
+		var users []*types.User
+		for _, u := range usernames {
+			if u == "alice" {
+				users = append(users, &types.User{ID: 1, Username: "alice"})
+			}
+		}
+		return users, nil
+	}
+	db.Mocks.UserEmails.GetVerifiedEmails = func(ctx context.Context, emails ...string) ([]*db.UserEmail, error) {
+		var verified []*db.UserEmail
+		for _, e := range emails {
+			if e == "bob@example.com" {
+				verified = append(verified, &db.UserEmail{UserID: 2, Email: e})
+			}
+		}
+		return verified, nil
+	}
+
+	set := map[int32][]uint32{}
+	edb.Mocks.Perms.Transact = func(context.Context) (*edb.PermsStore, error) {
+		return edb.NewPermsStore(nil, time.Now), nil
+	}
+	edb.Mocks.Perms.SetRepoPermissions = func(_ context.Context, p *authz.RepoPermissions) error {
+		set[p.RepoID] = p.UserIDs.ToArray()
+		return nil
+	}
+	defer func() {
+		db.Mocks = db.MockStores{}
+		edb.Mocks.Perms = edb.MockPerms{}
+	}()
+
+	store := edb.NewPermsStore(nil, time.Now)
+	ctx := context.Background()
+
+	err := SetRepoPermissions(ctx, store, []*RepoUsers{
+		{Repository: "gitolite.example.com/firmware", Users: []string{"alice", " bob@example.com ", ""}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[int32][]uint32{1: {1, 2}}; !reflect.DeepEqual(set, want) {
+		t.Errorf("have %v, want %v", set, want)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		rus     []*RepoUsers
+		wantErr string
+	}{
+		{
+			name: "unknown users",
+			rus: []*RepoUsers{
+				{Repository: "gitolite.example.com/firmware", Users: []string{"alice", "cindy", "cindy@example.com"}},
+			},
+			wantErr: "no users with the usernames or verified emails cindy, cindy@example.com",
+		},
+		{
+			name: "repository without explicit permissions",
+			rus: []*RepoUsers{
+				{Repository: "gitolite.example.com/firmware", Users: []string{"alice"}},
+				{Repository: "github.com/foo/bar", Users: []string{"alice"}},
+			},
+			wantErr: `the permissions of repository "github.com/foo/bar" can't be set explicitly, because the authorization of its external service isn't set`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			set = map[int32][]uint32{}
+			err := SetRepoPermissions(ctx, store, tc.rus)
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("have error %v, want %q", err, tc.wantErr)
+			}
+			if len(set) > 0 {
+				t.Errorf("expected no permissions to be set, have %v", set)
+			}
+		})
+	}
+}
+
+func TestReadRepoUsers(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		in      string
+		want    []*RepoUsers
+		wantErr string
+	}{
+		{
+			name: "valid",
+			in: `{"repository": "gitolite.example.com/firmware", "users": ["alice", "bob@example.com"]}
+
+{"repository": "gitolite.example.com/web", "users": []}
+`,
+			want: []*RepoUsers{
+				{Repository: "gitolite.example.com/firmware", Users: []string{"alice", "bob@example.com"}},
+				{Repository: "gitolite.example.com/web", Users: []string{}},
+			},
+		},
+		{
+			name:    "invalid JSON",
+			in:      "{\"repository\": \"a\", \"users\": []}\n{\"repository\": ",
+			wantErr: "line 2: unexpected EOF",
+		},
+		{
+			name:    "unknown field",
+			in:      `{"repository": "a", "usernames": ["alice"]}`,
+			wantErr: `line 1: json: unknown field "usernames"`,
+		},
+		{
+			name:    "missing repository",
+			in:      `{"users": ["alice"]}`,
+			wantErr: "line 1: repository is required",
+		},
+		{
+			name:    "empty",
+			in:      "\n",
+			wantErr: "no repository permissions in upload",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rus, err := readRepoUsers(strings.NewReader(tc.in))
+			if tc.wantErr != "" {
+				if err == nil || err.Error() != tc.wantErr {
+					t.Fatalf("have error %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(rus, tc.want) {
+				t.Errorf("have %+v, want %+v", rus, tc.want)
+			}
+		})
+	}
+}
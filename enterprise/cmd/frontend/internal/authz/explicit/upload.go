@@ -0,0 +1,83 @@
+package explicit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/errcode"
+)
+
+// maxUploadLineSize is the maximum size of a line of an upload, which limits the number of users
+// of a repository in an upload.
+const maxUploadLineSize = 10 * 1024 * 1024
+
+// NewUploadHandler returns a handler of bulk uploads of explicit repository permissions. The
+// request body is in the JSON Lines format, where each line is a RepoUsers object that replaces
+// the full set of users who can read a repository. The permissions of no repository are changed
+// if any line is invalid.
+func NewUploadHandler(store *edb.PermsStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 🚨 SECURITY: Only site admins can set repository permissions.
+		switch err := backend.CheckCurrentUserIsSiteAdmin(r.Context()); err {
+		case nil:
+		case backend.ErrNotAuthenticated:
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		case backend.ErrMustBeSiteAdmin:
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		rus, err := readRepoUsers(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := SetRepoPermissions(r.Context(), store, rus); err != nil {
+			http.Error(w, err.Error(), errcode.HTTP(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// readRepoUsers reads the JSON Lines of an upload. Blank lines are ignored.
+func readRepoUsers(r io.Reader) ([]*RepoUsers, error) {
+	var rus []*RepoUsers
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxUploadLineSize)
+	for line := 1; s.Scan(); line++ {
+		b := bytes.TrimSpace(s.Bytes())
+		if len(b) == 0 {
+			continue
+		}
+
+		var ru RepoUsers
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&ru); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line, err)
+		}
+		if ru.Repository == "" {
+			return nil, fmt.Errorf("line %d: repository is required", line)
+		}
+		rus = append(rus, &ru)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(rus) == 0 {
+		return nil, fmt.Errorf("no repository permissions in upload")
+	}
+	return rus, nil
+}
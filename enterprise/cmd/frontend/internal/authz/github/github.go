@@ -12,6 +12,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
 )
 
@@ -24,8 +25,12 @@ type Provider struct {
 }
 
 func NewProvider(githubURL *url.URL, baseToken string, cacheTTL time.Duration, mockCache cache) *Provider {
+	return newProvider(githubURL, baseToken, cacheTTL, mockCache, nil)
+}
+
+func newProvider(githubURL *url.URL, baseToken string, cacheTTL time.Duration, mockCache cache, cli httpcli.Doer) *Provider {
 	apiURL, _ := github.APIRoot(githubURL)
-	client := github.NewClient(apiURL, baseToken, nil)
+	client := github.NewClient(apiURL, baseToken, cli)
 
 	p := &Provider{
 		codeHost: extsvc.NewCodeHost(githubURL, github.ServiceType),
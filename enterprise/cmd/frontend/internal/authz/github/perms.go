@@ -0,0 +1,102 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+)
+
+// FetchUserPerms returns a list of private repository IDs (on code host) that the given
+// account has read access to. The repository ID has the same value as it would be used
+// as api.ExternalRepoSpec.ID. The returned list includes the repositories the user owns,
+// collaborates on (including as an outside collaborator), and has access to through
+// the membership of an organization or any of its teams.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+//
+// API docs: https://developer.github.com/v3/repos/#list-repositories-for-the-authenticated-user
+func (p *Provider) FetchUserPerms(ctx context.Context, account *extsvc.ExternalAccount) ([]extsvc.ExternalRepoID, error) {
+	if account == nil {
+		return nil, errors.New("no account provided")
+	} else if !extsvc.IsHostOfAccount(p.codeHost, account) {
+		return nil, fmt.Errorf("not a code host of the account: want %+v but have %+v", account.ExternalAccountSpec, p.codeHost)
+	}
+
+	_, tok, err := github.GetExternalAccountData(&account.ExternalAccountData)
+	if err != nil {
+		return nil, errors.Wrap(err, "get external account data")
+	} else if tok == nil {
+		return nil, errors.New("no token found in the external account data")
+	}
+
+	// 100 matches the maximum page size, thus a good default to avoid multiple allocations
+	// when appending the first 100 results to the slice.
+	repoIDs := make([]extsvc.ExternalRepoID, 0, 100)
+	hasNextPage := true
+	for page := 1; hasNextPage; page++ {
+		var repos []*github.Repository
+		repos, hasNextPage, _, err = p.client.ListPrivateAffiliatedRepositories(ctx, tok.AccessToken, page)
+		if err != nil {
+			return repoIDs, err
+		}
+
+		for _, r := range repos {
+			repoIDs = append(repoIDs, extsvc.ExternalRepoID(r.ID))
+		}
+	}
+
+	return repoIDs, nil
+}
+
+// FetchRepoPerms returns a list of user IDs (on code host) who have read access to
+// the given repository on the code host. The user ID has the same value as it would
+// be used as extsvc.ExternalAccount.AccountID. The returned list includes direct
+// collaborators, outside collaborators, and the organization members with access
+// through a team or the organization's base permissions.
+//
+// This method may return partial but valid results in case of error, and it is up to
+// callers to decide whether to discard.
+//
+// API docs: https://developer.github.com/v3/repos/collaborators/#list-collaborators
+func (p *Provider) FetchRepoPerms(ctx context.Context, repo *api.ExternalRepoSpec) ([]extsvc.ExternalAccountID, error) {
+	if repo == nil {
+		return nil, errors.New("no repository provided")
+	} else if !extsvc.IsHostOfRepo(p.codeHost, repo) {
+		return nil, fmt.Errorf("not a code host of the repository: want %+v but have %+v", repo, p.codeHost)
+	}
+
+	// The external ID of a repository is its GraphQL node ID, but collaborators
+	// can only be listed by owner and name.
+	ghRepo, err := p.client.GetRepositoryByNodeID(ctx, "", repo.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, "get repository")
+	}
+	owner, name, err := github.SplitRepositoryNameWithOwner(ghRepo.NameWithOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	// 100 matches the maximum page size, thus a good default to avoid multiple allocations
+	// when appending the first 100 results to the slice.
+	userIDs := make([]extsvc.ExternalAccountID, 0, 100)
+	hasNextPage := true
+	for page := 1; hasNextPage; page++ {
+		var users []*github.Collaborator
+		users, hasNextPage, err = p.client.ListRepositoryCollaborators(ctx, owner, name, page)
+		if err != nil {
+			return userIDs, err
+		}
+
+		for _, u := range users {
+			userIDs = append(userIDs, extsvc.ExternalAccountID(strconv.FormatInt(u.DatabaseID, 10)))
+		}
+	}
+
+	return userIDs, nil
+}
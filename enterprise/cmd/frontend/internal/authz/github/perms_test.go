@@ -0,0 +1,171 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/rcache"
+)
+
+type mockDoer struct {
+	do func(*http.Request) (*http.Response, error)
+}
+
+func (c *mockDoer) Do(r *http.Request) (*http.Response, error) {
+	return c.do(r)
+}
+
+// pagedResponses returns a mockDoer that responds with the given bodies to the
+// requests of the corresponding pages, and with an empty list past the last page.
+func pagedResponses(wantToken string, bodies map[string]string) *mockDoer {
+	return &mockDoer{
+		do: func(r *http.Request) (*http.Response, error) {
+			want := "bearer " + wantToken
+			if got := r.Header.Get("Authorization"); got != want {
+				return nil, fmt.Errorf("HTTP Authorization: want %q but got %q", want, got)
+			}
+
+			body, ok := bodies[r.URL.String()]
+			if !ok {
+				body = "[]"
+			}
+			return &http.Response{
+				Status:     http.StatusText(http.StatusOK),
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(body))),
+			}, nil
+		},
+	}
+}
+
+func TestProvider_FetchUserPerms(t *testing.T) {
+	t.Run("nil account", func(t *testing.T) {
+		p := NewProvider(mustURL(t, "https://github.com"), "", 0, make(authz.MockCache))
+		_, err := p.FetchUserPerms(context.Background(), nil)
+		want := "no account provided"
+		got := fmt.Sprintf("%v", err)
+		if got != want {
+			t.Fatalf("err: want %q but got %q", want, got)
+		}
+	})
+
+	t.Run("not the code host of the account", func(t *testing.T) {
+		p := NewProvider(mustURL(t, "https://github.com"), "", 0, make(authz.MockCache))
+		_, err := p.FetchUserPerms(context.Background(),
+			&extsvc.ExternalAccount{
+				ExternalAccountSpec: extsvc.ExternalAccountSpec{
+					ServiceType: "gitlab",
+					ServiceID:   "https://gitlab.com/",
+				},
+			},
+		)
+		want := "not a code host of the account: want {ServiceType:gitlab ServiceID:https://gitlab.com/ ClientID: AccountID:} but have &{ServiceID:https://github.com/ ServiceType:github BaseURL:https://github.com/}"
+		got := fmt.Sprintf("%v", err)
+		if got != want {
+			t.Fatalf("err: want %q but got %q", want, got)
+		}
+	})
+
+	// The Provider uses the github.Client under the hood,
+	// which uses rcache, a caching layer that uses Redis.
+	// We need to clear the cache before we run the tests
+	rcache.SetupForTest(t)
+
+	p := newProvider(mustURL(t, "https://ghe.sgdev.org"), "admin_token", 0, make(authz.MockCache),
+		pagedResponses("my_access_token", map[string]string{
+			"https://ghe.sgdev.org/api/v3/user/repos?visibility=private&affiliation=owner,collaborator,organization_member&page=1&per_page=100": `[{"node_id": "MDEwOlJlcG9zaXRvcnkx"}, {"node_id": "MDEwOlJlcG9zaXRvcnky"}]`,
+			"https://ghe.sgdev.org/api/v3/user/repos?visibility=private&affiliation=owner,collaborator,organization_member&page=2&per_page=100": `[{"node_id": "MDEwOlJlcG9zaXRvcnkz"}]`,
+		}),
+	)
+
+	authData := json.RawMessage(`{"access_token": "my_access_token"}`)
+	repoIDs, err := p.FetchUserPerms(context.Background(),
+		&extsvc.ExternalAccount{
+			ExternalAccountSpec: extsvc.ExternalAccountSpec{
+				ServiceType: "github",
+				ServiceID:   "https://ghe.sgdev.org/",
+			},
+			ExternalAccountData: extsvc.ExternalAccountData{
+				AuthData: &authData,
+			},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expRepoIDs := []extsvc.ExternalRepoID{"MDEwOlJlcG9zaXRvcnkx", "MDEwOlJlcG9zaXRvcnky", "MDEwOlJlcG9zaXRvcnkz"}
+	if diff := cmp.Diff(expRepoIDs, repoIDs); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestProvider_FetchRepoPerms(t *testing.T) {
+	t.Run("nil repository", func(t *testing.T) {
+		p := NewProvider(mustURL(t, "https://github.com"), "", 0, make(authz.MockCache))
+		_, err := p.FetchRepoPerms(context.Background(), nil)
+		want := "no repository provided"
+		got := fmt.Sprintf("%v", err)
+		if got != want {
+			t.Fatalf("err: want %q but got %q", want, got)
+		}
+	})
+
+	t.Run("not the code host of the repository", func(t *testing.T) {
+		p := NewProvider(mustURL(t, "https://github.com"), "", 0, make(authz.MockCache))
+		_, err := p.FetchRepoPerms(context.Background(),
+			&api.ExternalRepoSpec{
+				ServiceType: "gitlab",
+				ServiceID:   "https://gitlab.com/",
+			},
+		)
+		want := "not a code host of the repository: want ExternalRepoSpec{https://gitlab.com/ gitlab } but have &{ServiceID:https://github.com/ ServiceType:github BaseURL:https://github.com/}"
+		got := fmt.Sprintf("%v", err)
+		if got != want {
+			t.Fatalf("err: want %q but got %q", want, got)
+		}
+	})
+
+	rcache.SetupForTest(t)
+
+	github.GetRepositoryByNodeIDMock = func(ctx context.Context, token, id string) (*github.Repository, error) {
+		if id != "MDEwOlJlcG9zaXRvcnkx" {
+			return nil, github.ErrNotFound
+		}
+		return &github.Repository{ID: id, NameWithOwner: "sourcegraph/private", IsPrivate: true}, nil
+	}
+	defer func() { github.GetRepositoryByNodeIDMock = nil }()
+
+	p := newProvider(mustURL(t, "https://ghe.sgdev.org"), "admin_token", 0, make(authz.MockCache),
+		pagedResponses("admin_token", map[string]string{
+			"https://ghe.sgdev.org/api/v3/repos/sourcegraph/private/collaborators?affiliation=all&page=1&per_page=100": `[{"id": 1, "login": "alice"}, {"id": 2, "login": "bob"}]`,
+			"https://ghe.sgdev.org/api/v3/repos/sourcegraph/private/collaborators?affiliation=all&page=2&per_page=100": `[{"id": 3, "login": "cindy"}]`,
+		}),
+	)
+
+	accountIDs, err := p.FetchRepoPerms(context.Background(),
+		&api.ExternalRepoSpec{
+			ServiceType: "github",
+			ServiceID:   "https://ghe.sgdev.org/",
+			ID:          "MDEwOlJlcG9zaXRvcnkx",
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expAccountIDs := []extsvc.ExternalAccountID{"1", "2", "3"}
+	if diff := cmp.Diff(expAccountIDs, accountIDs); diff != "" {
+		t.Fatal(diff)
+	}
+}
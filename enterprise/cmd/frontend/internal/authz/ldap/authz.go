@@ -0,0 +1,89 @@
+package ldap
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	iauthz "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/ldap"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// NewAuthzProviders returns the set of LDAP authz providers derived from the authorization
+// rules of the ldap auth providers in site config. It also returns any validation problems with
+// the config, separating these into "serious problems" and "warnings". "Serious problems" are
+// those that should make Sourcegraph set authz.allowAccessByDefault to false. "Warnings" are all
+// other validation problems.
+func NewAuthzProviders(cfg *conf.Unified) (ps []authz.Provider, problems []string, warnings []string) {
+	seen := map[string]bool{}
+	for _, ap := range cfg.AuthProviders {
+		if ap.Ldap == nil || ap.Ldap.Authorization == nil {
+			continue
+		}
+
+		p, err := newAuthzProvider(ap.Ldap)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("LDAP auth provider %s has invalid authorization config: %s", ap.Ldap.Url, err))
+			continue
+		}
+
+		// 🚨 SECURITY: Only one authz provider is consulted per code host, so refuse to guess
+		// which rules apply if multiple LDAP auth providers have rules for the same code host.
+		if seen[p.ServiceID()] {
+			problems = append(problems, fmt.Sprintf("Multiple LDAP auth providers have authorization rules for the code host %s", p.ServiceID()))
+			continue
+		}
+		seen[p.ServiceID()] = true
+		ps = append(ps, p)
+	}
+	return ps, problems, warnings
+}
+
+func newAuthzProvider(c *schema.LDAPAuthProvider) (*Provider, error) {
+	a := c.Authorization
+	if c.GroupSearch == nil {
+		return nil, fmt.Errorf("groupSearch is required to look up the groups of users")
+	}
+	if a.CodeHostURL == "" {
+		return nil, fmt.Errorf("authorization.codeHostURL is required")
+	}
+
+	ttl, err := iauthz.ParseTTL(a.Ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]rule, 0, len(a.Rules))
+	for _, r := range a.Rules {
+		rl := rule{group: r.Group}
+		for _, pattern := range r.Repos {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid repos pattern %q for group %q: %s", pattern, r.Group, err)
+			}
+			rl.repos = append(rl.repos, re)
+		}
+		rules = append(rules, rl)
+	}
+
+	dir, err := ldap.NewDirectory(c)
+	if err != nil {
+		return nil, err
+	}
+	return newProvider(normalizeCodeHostURL(a.CodeHostURL), c.Url, dir, rules, ttl, nil), nil
+}
+
+// normalizeCodeHostURL returns the service ID of the code host with the given URL, normalized like
+// the service IDs of the repositories of GitHub, GitLab and other URL based code hosts. Service IDs
+// that aren't URLs (like "git@gitolite.example.com") are returned unchanged.
+func normalizeCodeHostURL(codeHostURL string) string {
+	u, err := url.Parse(codeHostURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return codeHostURL
+	}
+	return extsvc.NormalizeBaseURL(u).String()
+}
@@ -0,0 +1,190 @@
+// Package ldap implements an authz provider that grants users access to the repositories of a
+// code host based on their LDAP group memberships.
+package ldap
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/ldap"
+	"github.com/sourcegraph/sourcegraph/internal/rcache"
+)
+
+// ServiceType is the service type of the external accounts of LDAP users (see the ldap auth
+// provider).
+const ServiceType = "ldap"
+
+// cache describes the shape of the group memberships cache that Provider uses internally.
+type cache interface {
+	Get(key string) ([]byte, bool)
+	Set(key string, b []byte)
+}
+
+type groupsCacheVal struct {
+	Groups []string
+}
+
+// directory looks up the groups of LDAP users. It is implemented by *ldap.Directory.
+type directory interface {
+	Groups(ctx context.Context, dn, username string) ([]string, error)
+}
+
+// rule grants the members of an LDAP group read access to the repositories whose names match
+// any of the patterns.
+type rule struct {
+	group string
+	repos []*regexp.Regexp
+}
+
+// Provider is an authz provider for the repositories of a code host whose access is governed
+// by LDAP group memberships.
+type Provider struct {
+	// codeHostServiceID is the service ID of the repositories governed by the rules.
+	codeHostServiceID string
+	// ldapServiceID is the service ID of the external accounts of the ldap auth provider.
+	ldapServiceID string
+
+	dir      directory
+	rules    []rule
+	cache    cache
+	cacheTTL time.Duration
+}
+
+// newProvider returns a Provider that grants access to the repositories of the code host with
+// the given service ID based on the groups of the users of the ldap auth provider with the given
+// URL, according to the rules.
+func newProvider(codeHostServiceID, ldapURL string, dir directory, rules []rule, cacheTTL time.Duration, mockCache cache) *Provider {
+	p := &Provider{
+		codeHostServiceID: codeHostServiceID,
+		ldapServiceID:     ldapURL,
+		dir:               dir,
+		rules:             rules,
+		cache:             mockCache,
+		cacheTTL:          cacheTTL,
+	}
+	if p.cache == nil {
+		p.cache = rcache.NewWithTTL(fmt.Sprintf("ldapAuthz:%s", ldapURL), int(math.Ceil(cacheTTL.Seconds())))
+	}
+	return p
+}
+
+var _ authz.Provider = (*Provider)(nil)
+
+// RepoPerms implements the authz.Provider interface. It grants read access to the repositories
+// matched by the rules of the user's groups. Users without an account get no access.
+func (p *Provider) RepoPerms(ctx context.Context, account *extsvc.ExternalAccount, repos []*types.Repo) ([]authz.RepoPerms, error) {
+	if account == nil || len(repos) == 0 {
+		return nil, nil
+	}
+	if account.ServiceType != p.ServiceType() || account.ServiceID != p.ServiceID() {
+		return nil, fmt.Errorf("not an LDAP account of the code host: %+v", account.ExternalAccountSpec)
+	}
+
+	groups, err := p.groups(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []*regexp.Regexp
+	for _, r := range p.rules {
+		if groups[r.group] {
+			patterns = append(patterns, r.repos...)
+		}
+	}
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	perms := make([]authz.RepoPerms, 0, len(repos))
+	for _, repo := range repos {
+		for _, re := range patterns {
+			if re.MatchString(string(repo.Name)) {
+				perms = append(perms, authz.RepoPerms{Repo: repo, Perms: authz.Read})
+				break
+			}
+		}
+	}
+	return perms, nil
+}
+
+// groups returns the set of groups of the user of the account, which is cached for the TTL.
+func (p *Provider) groups(ctx context.Context, account *extsvc.ExternalAccount) (map[string]bool, error) {
+	var val groupsCacheVal
+	if b, ok := p.cache.Get(account.AccountID); ok && json.Unmarshal(b, &val) == nil {
+		return toSet(val.Groups), nil
+	}
+
+	var user ldap.User
+	if account.AccountData != nil {
+		if err := json.Unmarshal(*account.AccountData, &user); err != nil {
+			return nil, err
+		}
+	}
+
+	groups, err := p.dir.Groups(ctx, account.AccountID, user.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if b, err := json.Marshal(groupsCacheVal{Groups: groups}); err == nil {
+		p.cache.Set(account.AccountID, b)
+	}
+	return toSet(groups), nil
+}
+
+func toSet(ss []string) map[string]bool {
+	set := make(map[string]bool, len(ss))
+	for _, s := range ss {
+		set[s] = true
+	}
+	return set
+}
+
+// FetchAccount implements the authz.Provider interface. It derives the account from the user's
+// account of the ldap auth provider, identifying the user by the DN of their LDAP entry.
+func (p *Provider) FetchAccount(ctx context.Context, user *types.User, current []*extsvc.ExternalAccount) (mine *extsvc.ExternalAccount, err error) {
+	if user == nil {
+		return nil, nil
+	}
+
+	for _, acct := range current {
+		if acct.ServiceType != ServiceType || acct.ServiceID != p.ldapServiceID {
+			continue
+		}
+		return &extsvc.ExternalAccount{
+			UserID: user.ID,
+			ExternalAccountSpec: extsvc.ExternalAccountSpec{
+				ServiceType: p.ServiceType(),
+				ServiceID:   p.ServiceID(),
+				AccountID:   acct.AccountID,
+			},
+			ExternalAccountData: extsvc.ExternalAccountData{
+				AccountData: acct.AccountData,
+			},
+		}, nil
+	}
+	return nil, nil
+}
+
+// ServiceID implements the authz.Provider interface. It returns the service ID of the code host
+// whose repositories the provider governs.
+func (p *Provider) ServiceID() string {
+	return p.codeHostServiceID
+}
+
+// ServiceType implements the authz.Provider interface.
+func (p *Provider) ServiceType() string {
+	return ServiceType
+}
+
+// Validate implements the authz.Provider interface.
+func (p *Provider) Validate() (problems []string) {
+	return nil
+}
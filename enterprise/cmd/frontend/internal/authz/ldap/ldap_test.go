@@ -0,0 +1,232 @@
+package ldap
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/conf"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/ldap"
+	"github.com/sourcegraph/sourcegraph/internal/ldap/ldaptest"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+const (
+	codeHost    = "gitolite.example.com"
+	codeHostURL = "https://gitolite.example.com/"
+)
+
+func newTestServer() *ldaptest.Server {
+	return ldaptest.NewServer(
+		ldaptest.NewEntry("cn=eng,ou=groups,dc=example,dc=com",
+			"cn", "eng", "member", "uid=alice,ou=people,dc=example,dc=com"),
+		ldaptest.NewEntry("cn=ops,ou=groups,dc=example,dc=com",
+			"cn", "ops", "member", "uid=alice,ou=people,dc=example,dc=com", "member", "uid=bob,ou=people,dc=example,dc=com"),
+	)
+}
+
+func newTestConfig(s *ldaptest.Server) *schema.LDAPAuthProvider {
+	return &schema.LDAPAuthProvider{
+		Type:        "ldap",
+		Url:         s.URL,
+		StartTLS:    true,
+		Certificate: s.Certificate,
+		UserSearch:  schema.LDAPUserSearch{BaseDN: "ou=people,dc=example,dc=com"},
+		GroupSearch: &schema.LDAPGroupSearch{BaseDN: "ou=groups,dc=example,dc=com"},
+		Authorization: &schema.LDAPAuthorization{
+			CodeHostURL: codeHostURL,
+			Rules: []*schema.LDAPAuthorizationRule{
+				{Group: "eng", Repos: []string{`^gitolite\.example\.com/eng/`}},
+				{Group: "ops", Repos: []string{`^gitolite\.example\.com/ops/`, `/deploy$`}},
+				{Group: "sales", Repos: []string{`.*`}},
+			},
+		},
+	}
+}
+
+func account(dn, username string) *extsvc.ExternalAccount {
+	data, _ := json.Marshal(ldap.User{DN: dn, Username: username})
+	raw := json.RawMessage(data)
+	return &extsvc.ExternalAccount{
+		ExternalAccountSpec: extsvc.ExternalAccountSpec{
+			ServiceType: "ldap",
+			ServiceID:   codeHostURL,
+			AccountID:   dn,
+		},
+		ExternalAccountData: extsvc.ExternalAccountData{AccountData: &raw},
+	}
+}
+
+func TestProvider_RepoPerms(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	c := newTestConfig(s)
+	p, err := newAuthzProvider(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := make(authz.MockCache)
+	p.cache = cache
+
+	var repos []*types.Repo
+	for i, name := range []string{"eng/backend", "ops/infra", "web/deploy", "sales/crm"} {
+		repos = append(repos, &types.Repo{ID: api.RepoID(i + 1), Name: api.RepoName(codeHost + "/" + name)})
+	}
+	names := func(perms []authz.RepoPerms) (names []string) {
+		for _, p := range perms {
+			if p.Perms != authz.Read {
+				t.Errorf("unexpected perms %s for %s", p.Perms, p.Repo.Name)
+			}
+			names = append(names, string(p.Repo.Name))
+		}
+		return names
+	}
+
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name    string
+		account *extsvc.ExternalAccount
+		want    []string
+	}{
+		{
+			name: "no account",
+		},
+		{
+			name:    "member of eng and ops",
+			account: account("uid=alice,ou=people,dc=example,dc=com", "alice"),
+			want:    []string{codeHost + "/eng/backend", codeHost + "/ops/infra", codeHost + "/web/deploy"},
+		},
+		{
+			name:    "member of ops",
+			account: account("uid=bob,ou=people,dc=example,dc=com", "bob"),
+			want:    []string{codeHost + "/ops/infra", codeHost + "/web/deploy"},
+		},
+		{
+			name:    "member of no group",
+			account: account("uid=cindy,ou=people,dc=example,dc=com", "cindy"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			perms, err := p.RepoPerms(ctx, tc.account, repos)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have := names(perms); !reflect.DeepEqual(have, tc.want) {
+				t.Errorf("have %v, want %v", have, tc.want)
+			}
+		})
+	}
+
+	t.Run("group memberships are cached", func(t *testing.T) {
+		s.SetEntries()
+		perms, err := p.RepoPerms(ctx, account("uid=bob,ou=people,dc=example,dc=com", "bob"), repos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if have, want := names(perms), []string{codeHost + "/ops/infra", codeHost + "/web/deploy"}; !reflect.DeepEqual(have, want) {
+			t.Errorf("have %v, want %v", have, want)
+		}
+
+		for k := range cache {
+			delete(cache, k)
+		}
+		perms, err = p.RepoPerms(ctx, account("uid=bob,ou=people,dc=example,dc=com", "bob"), repos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(perms) != 0 {
+			t.Errorf("expected no perms after the cache expired, have %v", names(perms))
+		}
+	})
+
+	t.Run("account of another code host", func(t *testing.T) {
+		acct := account("uid=alice,ou=people,dc=example,dc=com", "alice")
+		acct.ServiceID = "phabricator.example.com"
+		if _, err := p.RepoPerms(ctx, acct, repos); err == nil {
+			t.Error("expected an error")
+		}
+	})
+}
+
+func TestProvider_FetchAccount(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	p, err := newAuthzProvider(newTestConfig(s))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := json.RawMessage(`{"DN": "uid=alice,ou=people,dc=example,dc=com", "Username": "alice"}`)
+	current := []*extsvc.ExternalAccount{
+		{ExternalAccountSpec: extsvc.ExternalAccountSpec{ServiceType: "github", ServiceID: "https://github.com/", AccountID: "1"}},
+		{
+			ExternalAccountSpec: extsvc.ExternalAccountSpec{ServiceType: "ldap", ServiceID: s.URL, AccountID: "uid=alice,ou=people,dc=example,dc=com"},
+			ExternalAccountData: extsvc.ExternalAccountData{AccountData: &data},
+		},
+	}
+
+	acct, err := p.FetchAccount(context.Background(), &types.User{ID: 7}, current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &extsvc.ExternalAccount{
+		UserID:              7,
+		ExternalAccountSpec: extsvc.ExternalAccountSpec{ServiceType: "ldap", ServiceID: codeHostURL, AccountID: "uid=alice,ou=people,dc=example,dc=com"},
+		ExternalAccountData: extsvc.ExternalAccountData{AccountData: &data},
+	}
+	if !reflect.DeepEqual(acct, want) {
+		t.Errorf("have %+v, want %+v", acct, want)
+	}
+
+	acct, err = p.FetchAccount(context.Background(), &types.User{ID: 7}, current[:1])
+	if err != nil || acct != nil {
+		t.Errorf("user without an LDAP account: have %+v, %v, want nil", acct, err)
+	}
+}
+
+func TestNewAuthzProviders(t *testing.T) {
+	s := newTestServer()
+	defer s.Close()
+
+	noGroupSearch := newTestConfig(s)
+	noGroupSearch.GroupSearch = nil
+	invalidPattern := newTestConfig(s)
+	invalidPattern.Authorization = &schema.LDAPAuthorization{
+		CodeHostURL: "phabricator.example.com",
+		Rules:       []*schema.LDAPAuthorizationRule{{Group: "eng", Repos: []string{"("}}},
+	}
+	noAuthorization := newTestConfig(s)
+	noAuthorization.Authorization = nil
+	duplicate := newTestConfig(s)
+	duplicate.ConfigID = "other"
+	duplicate.Authorization.CodeHostURL = "https://GITOLITE.example.com"
+
+	cfg := &conf.Unified{SiteConfiguration: schema.SiteConfiguration{
+		AuthProviders: []schema.AuthProviders{
+			{Ldap: newTestConfig(s)},
+			{Ldap: noGroupSearch},
+			{Ldap: invalidPattern},
+			{Ldap: noAuthorization},
+			{Ldap: duplicate},
+			{Builtin: &schema.BuiltinAuthProvider{Type: "builtin"}},
+		},
+	}}
+
+	ps, problems, warnings := NewAuthzProviders(cfg)
+	if len(ps) != 1 || ps[0].ServiceID() != codeHostURL || ps[0].ServiceType() != "ldap" {
+		t.Errorf("unexpected providers %+v", ps)
+	}
+	if len(problems) != 3 {
+		t.Errorf("expected 3 problems, have %q", problems)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("unexpected warnings %q", warnings)
+	}
+}
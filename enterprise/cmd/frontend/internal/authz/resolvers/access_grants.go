@@ -0,0 +1,210 @@
+package resolvers
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+)
+
+// logEvent records an event log entry. It is mockable in tests.
+var logEvent = db.EventLogs.Insert
+
+func marshalAccessGrantID(id int64) graphql.ID {
+	return relay.MarshalID("RepositoryAccessGrant", id)
+}
+
+func unmarshalAccessGrantID(id graphql.ID) (grantID int64, err error) {
+	err = relay.UnmarshalSpec(id, &grantID)
+	return
+}
+
+func (r *Resolver) GrantRepositoryAccess(ctx context.Context, args *graphqlbackend.GrantRepoAccessArgs) (graphqlbackend.RepositoryAccessGrantResolver, error) {
+	// 🚨 SECURITY: Only site admins can grant repository access.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	if (args.User == nil) == (args.Organization == nil) {
+		return nil, errors.New("exactly one of user or organization is required")
+	}
+	if !args.ExpiresAt.After(r.clock()) {
+		return nil, errors.New("expiresAt must be in the future")
+	}
+
+	repoID, err := graphqlbackend.UnmarshalRepositoryID(args.Repository)
+	if err != nil {
+		return nil, err
+	}
+	// Make sure the repo ID is valid.
+	if _, err = db.Repos.Get(ctx, repoID); err != nil {
+		return nil, err
+	}
+
+	g := &authz.AccessGrant{
+		RepoID:        int32(repoID),
+		CreatorUserID: actor.FromContext(ctx).UID,
+		ExpiresAt:     args.ExpiresAt.Time,
+	}
+	if args.Reason != nil {
+		g.Reason = strings.TrimSpace(*args.Reason)
+	}
+
+	if args.User != nil {
+		if g.UserID, err = graphqlbackend.UnmarshalUserID(*args.User); err != nil {
+			return nil, err
+		}
+		// Make sure the user ID is valid.
+		if _, err = db.Users.GetByID(ctx, g.UserID); err != nil {
+			return nil, err
+		}
+	} else {
+		if g.OrgID, err = graphqlbackend.UnmarshalOrgID(*args.Organization); err != nil {
+			return nil, err
+		}
+		// Make sure the org ID is valid.
+		if _, err = db.Orgs.GetByID(ctx, g.OrgID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = r.store.CreateAccessGrant(ctx, g); err != nil {
+		return nil, err
+	}
+	r.logAccessGrantEvent(ctx, edb.AccessGrantCreatedEvent, g)
+
+	return &accessGrantResolver{grant: g, now: r.clock()}, nil
+}
+
+func (r *Resolver) RevokeRepositoryAccessGrant(ctx context.Context, args *graphqlbackend.RevokeRepoAccessGrantArgs) (*graphqlbackend.EmptyResponse, error) {
+	// 🚨 SECURITY: Only site admins can revoke repository access grants.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	id, err := unmarshalAccessGrantID(args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := r.store.RevokeAccessGrant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.logAccessGrantEvent(ctx, edb.AccessGrantRevokedEvent, g)
+
+	return &graphqlbackend.EmptyResponse{}, nil
+}
+
+func (r *Resolver) RepositoryAccessGrants(ctx context.Context, args *graphqlbackend.RepoAccessGrantsArgs) ([]graphqlbackend.RepositoryAccessGrantResolver, error) {
+	// 🚨 SECURITY: Only site admins can list repository access grants.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	opts := edb.AccessGrantsListOptions{OnlyActive: !args.IncludeInactive}
+	if args.Repository != nil {
+		repoID, err := graphqlbackend.UnmarshalRepositoryID(*args.Repository)
+		if err != nil {
+			return nil, err
+		}
+		opts.RepoID = int32(repoID)
+	}
+	if args.User != nil {
+		userID, err := graphqlbackend.UnmarshalUserID(*args.User)
+		if err != nil {
+			return nil, err
+		}
+		opts.UserID = userID
+	}
+	if args.Organization != nil {
+		orgID, err := graphqlbackend.UnmarshalOrgID(*args.Organization)
+		if err != nil {
+			return nil, err
+		}
+		opts.OrgID = orgID
+	}
+
+	grants, err := r.store.ListAccessGrants(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.clock()
+	rs := make([]graphqlbackend.RepositoryAccessGrantResolver, 0, len(grants))
+	for _, g := range grants {
+		rs = append(rs, &accessGrantResolver{grant: g, now: now})
+	}
+	return rs, nil
+}
+
+// logAccessGrantEvent records an event log entry of the access grant, caused by the current user.
+// Failures are logged but not returned, because the access grant has already been changed.
+func (r *Resolver) logAccessGrantEvent(ctx context.Context, name string, g *authz.AccessGrant) {
+	e := edb.NewAccessGrantEvent(name, actor.FromContext(ctx).UID, g, r.clock())
+	if err := logEvent(ctx, e); err != nil {
+		log15.Error("Failed to log access grant event", "event", name, "grantID", g.ID, "error", err)
+	}
+}
+
+type accessGrantResolver struct {
+	grant *authz.AccessGrant
+	now   time.Time
+}
+
+func (r *accessGrantResolver) ID() graphql.ID { return marshalAccessGrantID(r.grant.ID) }
+
+func (r *accessGrantResolver) Repository(ctx context.Context) (*graphqlbackend.RepositoryResolver, error) {
+	return graphqlbackend.RepositoryByIDInt32(ctx, api.RepoID(r.grant.RepoID))
+}
+
+func (r *accessGrantResolver) User(ctx context.Context) (*graphqlbackend.UserResolver, error) {
+	if r.grant.UserID == 0 {
+		return nil, nil
+	}
+	return graphqlbackend.UserByIDInt32(ctx, r.grant.UserID)
+}
+
+func (r *accessGrantResolver) Organization(ctx context.Context) (*graphqlbackend.OrgResolver, error) {
+	if r.grant.OrgID == 0 {
+		return nil, nil
+	}
+	return graphqlbackend.OrgByIDInt32(ctx, r.grant.OrgID)
+}
+
+func (r *accessGrantResolver) Creator(ctx context.Context) (*graphqlbackend.UserResolver, error) {
+	if r.grant.CreatorUserID == 0 {
+		return nil, nil
+	}
+	return graphqlbackend.UserByIDInt32(ctx, r.grant.CreatorUserID)
+}
+
+func (r *accessGrantResolver) Reason() string { return r.grant.Reason }
+
+func (r *accessGrantResolver) ExpiresAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.grant.ExpiresAt}
+}
+
+func (r *accessGrantResolver) RevokedAt() *graphqlbackend.DateTime {
+	if r.grant.RevokedAt.IsZero() {
+		return nil
+	}
+	return &graphqlbackend.DateTime{Time: r.grant.RevokedAt}
+}
+
+func (r *accessGrantResolver) CreatedAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.grant.CreatedAt}
+}
+
+func (r *accessGrantResolver) Active() bool { return r.grant.Active(r.now) }
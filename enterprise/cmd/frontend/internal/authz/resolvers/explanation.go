@@ -0,0 +1,275 @@
+package resolvers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater/protocol"
+)
+
+func (r *Resolver) RepositoryPermissionsExplanation(ctx context.Context, args *graphqlbackend.RepoPermsExplanationArgs) (graphqlbackend.RepositoryPermissionsExplanationResolver, error) {
+	// 🚨 SECURITY: Only site admins can query repository permissions.
+	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := db.Users.GetByUsername(ctx, args.Username)
+	if err != nil {
+		return nil, err
+	}
+	// The current user is a site admin, so the repository is never filtered out here.
+	repo, err := db.Repos.GetByName(ctx, api.RepoName(args.Repository))
+	if err != nil {
+		return nil, err
+	}
+
+	// Evaluate the decision with the enforcement policy as for a request made by the user, so
+	// that it reflects exactly what the user can read and why.
+	allowed, rule, err := db.ExplainRepoPerms(actor.WithActor(ctx, actor.FromUser(user.ID)), repo, authz.Read)
+	if err != nil {
+		return nil, errors.Wrap(err, "evaluate repository permissions")
+	}
+
+	_, providers := authz.GetProviders()
+	e := &permsExplanationResolver{
+		user:    user,
+		repo:    repo,
+		allowed: allowed,
+		reason:  permsReason(rule, repo, providers),
+	}
+
+	e.providers, err = explainProviders(ctx, user, repo, providers)
+	if err != nil {
+		return nil, err
+	}
+
+	up := &authz.UserPermissions{
+		UserID: user.ID,
+		Perm:   authz.Read, // Note: We currently only support read for repository permissions.
+		Type:   authz.PermRepos,
+	}
+	if err = r.store.LoadUserPermissions(ctx, up); err == nil {
+		e.userPerms = &storedPermsResolver{allowed: up.IDs.Contains(uint32(repo.ID)), updatedAt: up.UpdatedAt}
+	} else if err != authz.ErrPermsNotFound {
+		return nil, errors.Wrap(err, "load user permissions")
+	}
+
+	rp := &authz.RepoPermissions{
+		RepoID: int32(repo.ID),
+		Perm:   authz.Read, // Note: We currently only support read for repository permissions.
+	}
+	if err = r.store.LoadRepoPermissions(ctx, rp); err == nil {
+		e.repoPerms = &storedPermsResolver{allowed: rp.UserIDs.Contains(uint32(user.ID)), updatedAt: rp.UpdatedAt}
+	} else if err != authz.ErrPermsNotFound {
+		return nil, errors.Wrap(err, "load repository permissions")
+	}
+
+	e.syncInfo, err = repoupdater.DefaultClient.PermsSyncInfo(ctx, protocol.PermsSyncInfoRequest{
+		UserID: user.ID,
+		RepoID: repo.ID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "get permissions sync info")
+	}
+
+	return e, nil
+}
+
+// permsReason describes the rule of the repository permissions enforcement policy that decided
+// whether the user can read the repository.
+func permsReason(rule db.AuthzRule, repo *types.Repo, providers []authz.Provider) string {
+	switch rule {
+	case db.AuthzRuleInternalActor:
+		return "The request is made by Sourcegraph itself, which can read all repositories."
+	case db.AuthzRuleSiteAdmin:
+		return "The user is a site admin, who can read all repositories."
+	case db.AuthzRuleAccessGrant:
+		return "The user has a time-limited access grant for the repository."
+	case db.AuthzRuleUserMapping:
+		return "Permissions are enforced by the permissions user mapping, using the permissions stored in the database."
+	case db.AuthzRuleAllowByDefault:
+		return "No authorization providers are configured, and everyone can read all repositories."
+	case db.AuthzRulePublic:
+		return "The repository is public."
+	case db.AuthzRulePublicOnly:
+		return "No authorization providers are configured, and only public repositories can be read."
+	case db.AuthzRuleSyncedPerms:
+		return "Permissions are synced in the background, and enforced using the permissions of the user stored in the database."
+	case db.AuthzRuleProvider:
+		for _, p := range providers {
+			if p.ServiceID() == repo.ExternalRepo.ServiceID {
+				return fmt.Sprintf("Permissions are enforced by the %s authorization provider for %s.", p.ServiceType(), p.ServiceID())
+			}
+		}
+	case db.AuthzRuleAllowUnmatched:
+		return "No authorization provider governs the repository's code host, and access is allowed by default."
+	case db.AuthzRuleDenyUnmatched:
+		return "No authorization provider governs the repository's code host, and access is denied by default."
+	case db.AuthzRuleNoExternalRepo:
+		return "The repository has no code host information, and access is always denied."
+	}
+	return fmt.Sprintf("Unknown rule %q.", rule)
+}
+
+// explainProviders returns the decisions of the authorization providers of the repository's
+// code host.
+func explainProviders(ctx context.Context, user *types.User, repo *types.Repo, providers []authz.Provider) ([]graphqlbackend.AuthorizationProviderExplanationResolver, error) {
+	var accts []*extsvc.ExternalAccount
+	if len(providers) > 0 {
+		var err error
+		accts, err = db.ExternalAccounts.List(ctx, db.ExternalAccountsListOptions{UserID: user.ID})
+		if err != nil {
+			return nil, errors.Wrap(err, "list external accounts")
+		}
+	}
+
+	var rs []graphqlbackend.AuthorizationProviderExplanationResolver
+	for _, p := range providers {
+		if p.ServiceID() != repo.ExternalRepo.ServiceID {
+			continue
+		}
+
+		pr := &providerExplanationResolver{serviceType: p.ServiceType(), serviceID: p.ServiceID()}
+		for _, acct := range accts {
+			if acct.ServiceType == p.ServiceType() && acct.ServiceID == p.ServiceID() {
+				pr.account = acct
+				break
+			}
+		}
+
+		perms, err := p.RepoPerms(ctx, pr.account, []*types.Repo{repo})
+		if err != nil {
+			pr.err = err.Error()
+		}
+		for _, rp := range perms {
+			if rp.Repo.ID == repo.ID && rp.Perms.Include(authz.Read) {
+				pr.allowed = true
+			}
+		}
+		rs = append(rs, pr)
+	}
+	return rs, nil
+}
+
+type permsExplanationResolver struct {
+	user      *types.User
+	repo      *types.Repo
+	allowed   bool
+	reason    string
+	providers []graphqlbackend.AuthorizationProviderExplanationResolver
+	userPerms *storedPermsResolver
+	repoPerms *storedPermsResolver
+	syncInfo  *protocol.PermsSyncInfoResponse
+}
+
+var _ graphqlbackend.RepositoryPermissionsExplanationResolver = &permsExplanationResolver{}
+
+func (r *permsExplanationResolver) User() *graphqlbackend.UserResolver {
+	return graphqlbackend.NewUserResolver(r.user)
+}
+
+func (r *permsExplanationResolver) Repository() *graphqlbackend.RepositoryResolver {
+	return graphqlbackend.NewRepositoryResolver(r.repo)
+}
+
+func (r *permsExplanationResolver) Allowed() bool  { return r.allowed }
+func (r *permsExplanationResolver) Reason() string { return r.reason }
+
+func (r *permsExplanationResolver) Providers() []graphqlbackend.AuthorizationProviderExplanationResolver {
+	return r.providers
+}
+
+func (r *permsExplanationResolver) UserPermissions() graphqlbackend.StoredRepositoryPermissionsResolver {
+	if r.userPerms == nil {
+		return nil
+	}
+	return r.userPerms
+}
+
+func (r *permsExplanationResolver) RepositoryPermissions() graphqlbackend.StoredRepositoryPermissionsResolver {
+	if r.repoPerms == nil {
+		return nil
+	}
+	return r.repoPerms
+}
+
+func (r *permsExplanationResolver) LastUserSync() graphqlbackend.PermissionsSyncResultResolver {
+	if r.syncInfo.User == nil {
+		return nil
+	}
+	return &syncResultResolver{r.syncInfo.User}
+}
+
+func (r *permsExplanationResolver) LastRepositorySync() graphqlbackend.PermissionsSyncResultResolver {
+	if r.syncInfo.Repo == nil {
+		return nil
+	}
+	return &syncResultResolver{r.syncInfo.Repo}
+}
+
+type providerExplanationResolver struct {
+	serviceType string
+	serviceID   string
+	account     *extsvc.ExternalAccount
+	allowed     bool
+	err         string
+}
+
+func (r *providerExplanationResolver) ServiceType() string { return r.serviceType }
+func (r *providerExplanationResolver) ServiceID() string   { return r.serviceID }
+func (r *providerExplanationResolver) Allowed() bool       { return r.allowed }
+
+func (r *providerExplanationResolver) AccountID() *string {
+	if r.account == nil {
+		return nil
+	}
+	return &r.account.AccountID
+}
+
+func (r *providerExplanationResolver) Error() *string {
+	if r.err == "" {
+		return nil
+	}
+	return &r.err
+}
+
+type storedPermsResolver struct {
+	allowed   bool
+	updatedAt time.Time
+}
+
+func (r *storedPermsResolver) Allowed() bool { return r.allowed }
+
+func (r *storedPermsResolver) UpdatedAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.updatedAt}
+}
+
+type syncResultResolver struct {
+	result *protocol.PermsSyncResult
+}
+
+func (r *syncResultResolver) StartedAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.result.StartedAt}
+}
+
+func (r *syncResultResolver) FinishedAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.result.FinishedAt}
+}
+
+func (r *syncResultResolver) Error() *string {
+	if r.result.Error == "" {
+		return nil
+	}
+	return &r.result.Error
+}
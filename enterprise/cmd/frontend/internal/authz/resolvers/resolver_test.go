@@ -19,9 +19,12 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/types"
 	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/explicit"
 	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater/protocol"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -193,6 +196,86 @@ func TestResolver_SetRepositoryPermissionsForUsers(t *testing.T) {
 	}
 }
 
+func TestResolver_SetExplicitRepositoryPermissions(t *testing.T) {
+	t.Run("authenticated as non-admin", func(t *testing.T) {
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{}, nil
+		}
+		defer func() {
+			db.Mocks.Users.GetByCurrentAuthUser = nil
+		}()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		result, err := (&Resolver{}).SetExplicitRepositoryPermissions(ctx, &graphqlbackend.ExplicitRepoPermsArgs{})
+		if want := backend.ErrMustBeSiteAdmin; err != want {
+			t.Errorf("err: want %q but got %v", want, err)
+		}
+		if result != nil {
+			t.Errorf("result: want nil but got %v", result)
+		}
+	})
+
+	authz.SetProviders(false, []authz.Provider{explicit.NewProvider("gitolite", "git@gitolite.example.com", nil)})
+	defer authz.SetProviders(true, nil)
+
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{SiteAdmin: true}, nil
+	}
+	db.Mocks.Users.GetByUsernames = func(context.Context, ...string) ([]*types.User, error) {
+		return []*types.User{{ID: 1, Username: "alice"}}, nil
+	}
+	db.Mocks.UserEmails.GetVerifiedEmails = func(context.Context, ...string) ([]*db.UserEmail, error) {
+		return []*db.UserEmail{{UserID: 2, Email: "bob@example.com"}}, nil
+	}
+	db.Mocks.Repos.GetByName = func(_ context.Context, name api.RepoName) (*types.Repo, error) {
+		return &types.Repo{
+			ID:           3,
+			Name:         name,
+			ExternalRepo: api.ExternalRepoSpec{ServiceType: "gitolite", ServiceID: "git@gitolite.example.com"},
+		}, nil
+	}
+	edb.Mocks.Perms.Transact = func(_ context.Context) (*edb.PermsStore, error) {
+		return &edb.PermsStore{}, nil
+	}
+	edb.Mocks.Perms.SetRepoPermissions = func(_ context.Context, p *authz.RepoPermissions) error {
+		if p.RepoID != 3 {
+			return fmt.Errorf("RepoID: want 3 but got %d", p.RepoID)
+		}
+		if diff := cmp.Diff([]uint32{1, 2}, p.UserIDs.ToArray()); diff != "" {
+			return fmt.Errorf("p.UserIDs: %v", diff)
+		}
+		return nil
+	}
+	defer func() {
+		db.Mocks.UserEmails = db.MockUserEmails{}
+		db.Mocks.Users = db.MockUsers{}
+		db.Mocks.Repos = db.MockRepos{}
+		edb.Mocks.Perms = edb.MockPerms{}
+	}()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: mustParseGraphQLSchema(t, nil),
+			Query: `
+				mutation {
+					setExplicitRepositoryPermissions(
+						repository: "gitolite.example.com/firmware",
+						users: ["alice", "bob@example.com"]) {
+						alwaysNil
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"setExplicitRepositoryPermissions": {
+						"alwaysNil": null
+					}
+				}
+			`,
+		},
+	})
+}
+
 func TestResolver_AuthorizedUserRepositories(t *testing.T) {
 	t.Run("authenticated as non-admin", func(t *testing.T) {
 		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
@@ -523,3 +606,343 @@ func TestResolver_AuthorizedUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestResolver_RepositoryPermissionsExplanation(t *testing.T) {
+	t.Run("authenticated as non-admin", func(t *testing.T) {
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{}, nil
+		}
+		defer func() {
+			db.Mocks.Users.GetByCurrentAuthUser = nil
+		}()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		result, err := (&Resolver{}).RepositoryPermissionsExplanation(ctx, &graphqlbackend.RepoPermsExplanationArgs{})
+		if want := backend.ErrMustBeSiteAdmin; err != want {
+			t.Errorf("err: want %q but got %v", want, err)
+		}
+		if result != nil {
+			t.Errorf("result: want nil but got %v", result)
+		}
+	})
+
+	const codeHost = "git@gitolite.example.com"
+	updatedAt := time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
+
+	authz.SetProviders(false, []authz.Provider{explicit.NewProvider("gitolite", codeHost, edb.NewPermsStore(nil, clock))})
+	defer authz.SetProviders(true, nil)
+
+	db.Mocks.Users.GetByCurrentAuthUser = func(ctx context.Context) (*types.User, error) {
+		if actor.FromContext(ctx).UID == 1 {
+			return &types.User{ID: 1, Username: "alice"}, nil
+		}
+		return &types.User{SiteAdmin: true}, nil
+	}
+	db.Mocks.Users.GetByUsername = func(_ context.Context, username string) (*types.User, error) {
+		return &types.User{ID: 1, Username: username}, nil
+	}
+	db.Mocks.Repos.GetByName = func(_ context.Context, name api.RepoName) (*types.Repo, error) {
+		return &types.Repo{
+			ID:           3,
+			Name:         name,
+			Private:      true,
+			ExternalRepo: api.ExternalRepoSpec{ServiceType: "gitolite", ServiceID: codeHost},
+		}, nil
+	}
+	db.Mocks.ExternalAccounts.List = func(db.ExternalAccountsListOptions) ([]*extsvc.ExternalAccount, error) {
+		return []*extsvc.ExternalAccount{{
+			UserID: 1,
+			ExternalAccountSpec: extsvc.ExternalAccountSpec{
+				ServiceType: "gitolite",
+				ServiceID:   codeHost,
+				AccountID:   "1",
+			},
+		}}, nil
+	}
+	edb.Mocks.Perms.LoadUserPermissions = func(_ context.Context, p *authz.UserPermissions) error {
+		p.IDs = roaring.BitmapOf(3)
+		p.UpdatedAt = updatedAt
+		return nil
+	}
+	edb.Mocks.Perms.LoadRepoPermissions = func(context.Context, *authz.RepoPermissions) error {
+		return authz.ErrPermsNotFound
+	}
+	repoupdater.MockPermsSyncInfo = func(_ context.Context, args protocol.PermsSyncInfoRequest) (*protocol.PermsSyncInfoResponse, error) {
+		if args.UserID != 1 || args.RepoID != 3 {
+			return nil, fmt.Errorf("unexpected args %+v", args)
+		}
+		return &protocol.PermsSyncInfoResponse{
+			User: &protocol.PermsSyncResult{
+				StartedAt:  updatedAt.Add(-time.Minute),
+				FinishedAt: updatedAt,
+				Error:      "boom",
+			},
+		}, nil
+	}
+	defer func() {
+		db.Mocks = db.MockStores{}
+		edb.Mocks.Perms = edb.MockPerms{}
+		repoupdater.MockPermsSyncInfo = nil
+	}()
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Schema: mustParseGraphQLSchema(t, nil),
+			Query: `
+				{
+					repositoryPermissionsExplanation(username: "alice", repository: "gitolite.example.com/firmware") {
+						user {
+							username
+						}
+						repository {
+							name
+						}
+						allowed
+						reason
+						providers {
+							serviceType
+							serviceID
+							accountID
+							allowed
+							error
+						}
+						userPermissions {
+							allowed
+							updatedAt
+						}
+						repositoryPermissions {
+							allowed
+						}
+						lastUserSync {
+							startedAt
+							finishedAt
+							error
+						}
+						lastRepositorySync {
+							error
+						}
+					}
+				}
+			`,
+			ExpectedResult: `
+				{
+					"repositoryPermissionsExplanation": {
+						"user": {"username": "alice"},
+						"repository": {"name": "gitolite.example.com/firmware"},
+						"allowed": true,
+						"reason": "Permissions are enforced by the gitolite authorization provider for git@gitolite.example.com.",
+						"providers": [{
+							"serviceType": "gitolite",
+							"serviceID": "git@gitolite.example.com",
+							"accountID": "1",
+							"allowed": true,
+							"error": null
+						}],
+						"userPermissions": {
+							"allowed": true,
+							"updatedAt": "2020-04-01T12:00:00Z"
+						},
+						"repositoryPermissions": null,
+						"lastUserSync": {
+							"startedAt": "2020-04-01T11:59:00Z",
+							"finishedAt": "2020-04-01T12:00:00Z",
+							"error": "boom"
+						},
+						"lastRepositorySync": null
+					}
+				}
+			`,
+		},
+	})
+}
+
+func TestResolver_RepositoryAccessGrants(t *testing.T) {
+	t.Run("authenticated as non-admin", func(t *testing.T) {
+		db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+			return &types.User{}, nil
+		}
+		defer func() {
+			db.Mocks.Users.GetByCurrentAuthUser = nil
+		}()
+
+		ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+		if _, err := (&Resolver{}).GrantRepositoryAccess(ctx, &graphqlbackend.GrantRepoAccessArgs{}); err != backend.ErrMustBeSiteAdmin {
+			t.Errorf("GrantRepositoryAccess err: want %q but got %v", backend.ErrMustBeSiteAdmin, err)
+		}
+		if _, err := (&Resolver{}).RevokeRepositoryAccessGrant(ctx, &graphqlbackend.RevokeRepoAccessGrantArgs{}); err != backend.ErrMustBeSiteAdmin {
+			t.Errorf("RevokeRepositoryAccessGrant err: want %q but got %v", backend.ErrMustBeSiteAdmin, err)
+		}
+		if _, err := (&Resolver{}).RepositoryAccessGrants(ctx, &graphqlbackend.RepoAccessGrantsArgs{}); err != backend.ErrMustBeSiteAdmin {
+			t.Errorf("RepositoryAccessGrants err: want %q but got %v", backend.ErrMustBeSiteAdmin, err)
+		}
+	})
+
+	db.Mocks.Users.GetByCurrentAuthUser = func(context.Context) (*types.User, error) {
+		return &types.User{ID: 1, SiteAdmin: true}, nil
+	}
+	db.Mocks.Users.GetByID = func(_ context.Context, id int32) (*types.User, error) {
+		return &types.User{ID: id, Username: fmt.Sprintf("user%d", id)}, nil
+	}
+	db.Mocks.Repos.Get = func(_ context.Context, id api.RepoID) (*types.Repo, error) {
+		return &types.Repo{ID: id, Name: "github.com/acme/secret"}, nil
+	}
+
+	grants := map[int64]*authz.AccessGrant{}
+	edb.Mocks.Perms.CreateAccessGrant = func(_ context.Context, g *authz.AccessGrant) error {
+		g.ID = int64(len(grants) + 1)
+		g.CreatedAt = clock()
+		grants[g.ID] = g
+		return nil
+	}
+	edb.Mocks.Perms.RevokeAccessGrant = func(_ context.Context, id int64) (*authz.AccessGrant, error) {
+		g, ok := grants[id]
+		if !ok || !g.RevokedAt.IsZero() {
+			return nil, fmt.Errorf("access grant %d not found", id)
+		}
+		g.RevokedAt = clock()
+		return g, nil
+	}
+	edb.Mocks.Perms.ListAccessGrants = func(_ context.Context, opts edb.AccessGrantsListOptions) ([]*authz.AccessGrant, error) {
+		if want := (edb.AccessGrantsListOptions{RepoID: 3, OnlyActive: false}); opts != want {
+			return nil, fmt.Errorf("unexpected options %+v", opts)
+		}
+		return []*authz.AccessGrant{grants[1]}, nil
+	}
+
+	var events []string
+	logEvent = func(_ context.Context, e *db.Event) error {
+		events = append(events, fmt.Sprintf("%s %d %s", e.Name, e.UserID, e.Argument))
+		return nil
+	}
+	defer func() {
+		db.Mocks = db.MockStores{}
+		edb.Mocks.Perms = edb.MockPerms{}
+		logEvent = db.EventLogs.Insert
+	}()
+
+	ctx := actor.WithActor(context.Background(), &actor.Actor{UID: 1})
+	repoID := graphqlbackend.MarshalRepositoryID(3)
+	userID := graphqlbackend.MarshalUserID(2)
+	expiresAt := graphqlbackend.DateTime{Time: clock().Add(time.Hour)}
+
+	for _, tc := range []struct {
+		name    string
+		args    *graphqlbackend.GrantRepoAccessArgs
+		wantErr string
+	}{
+		{
+			name:    "no grantee",
+			args:    &graphqlbackend.GrantRepoAccessArgs{Repository: repoID, ExpiresAt: expiresAt},
+			wantErr: "exactly one of user or organization is required",
+		},
+		{
+			name:    "two grantees",
+			args:    &graphqlbackend.GrantRepoAccessArgs{Repository: repoID, User: &userID, Organization: &userID, ExpiresAt: expiresAt},
+			wantErr: "exactly one of user or organization is required",
+		},
+		{
+			name:    "expired",
+			args:    &graphqlbackend.GrantRepoAccessArgs{Repository: repoID, User: &userID, ExpiresAt: graphqlbackend.DateTime{Time: clock()}},
+			wantErr: "expiresAt must be in the future",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &Resolver{clock: clock}
+			if _, err := r.GrantRepositoryAccess(ctx, tc.args); err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("err: want %q but got %v", tc.wantErr, err)
+			}
+		})
+	}
+	if len(grants) > 0 {
+		t.Fatalf("expected no access grants to be created, got %v", grants)
+	}
+
+	gqltesting.RunTests(t, []*gqltesting.Test{
+		{
+			Context: ctx,
+			Schema:  mustParseGraphQLSchema(t, nil),
+			Query: fmt.Sprintf(`
+				mutation {
+					grantRepositoryAccess(repository: %q, user: %q, expiresAt: "2100-01-01T00:00:00Z", reason: " INC-42 ") {
+						repository {
+							name
+						}
+						user {
+							username
+						}
+						organization {
+							name
+						}
+						creator {
+							username
+						}
+						reason
+						expiresAt
+						revokedAt
+						active
+					}
+				}
+			`, repoID, userID),
+			ExpectedResult: `
+				{
+					"grantRepositoryAccess": {
+						"repository": {"name": "github.com/acme/secret"},
+						"user": {"username": "user2"},
+						"organization": null,
+						"creator": {"username": "user1"},
+						"reason": "INC-42",
+						"expiresAt": "2100-01-01T00:00:00Z",
+						"revokedAt": null,
+						"active": true
+					}
+				}
+			`,
+		},
+		{
+			Context: ctx,
+			Schema:  mustParseGraphQLSchema(t, nil),
+			Query: fmt.Sprintf(`
+				mutation {
+					revokeRepositoryAccessGrant(id: %q) {
+						alwaysNil
+					}
+				}
+			`, marshalAccessGrantID(1)),
+			ExpectedResult: `
+				{
+					"revokeRepositoryAccessGrant": {
+						"alwaysNil": null
+					}
+				}
+			`,
+		},
+		{
+			Context: ctx,
+			Schema:  mustParseGraphQLSchema(t, nil),
+			Query: fmt.Sprintf(`
+				{
+					repositoryAccessGrants(repository: %q, includeInactive: true) {
+						id
+						active
+					}
+				}
+			`, repoID),
+			ExpectedResult: fmt.Sprintf(`
+				{
+					"repositoryAccessGrants": [{
+						"id": %q,
+						"active": false
+					}]
+				}
+			`, marshalAccessGrantID(1)),
+		},
+	})
+
+	wantEvents := []string{
+		`RepositoryAccessGranted 1 {"id":1,"repo_id":3,"user_id":2,"reason":"INC-42","expires_at":"2100-01-01T00:00:00Z"}`,
+		`RepositoryAccessGrantRevoked 1 {"id":1,"repo_id":3,"user_id":2,"reason":"INC-42","expires_at":"2100-01-01T00:00:00Z"}`,
+	}
+	if diff := cmp.Diff(wantEvents, events); diff != "" {
+		t.Fatalf("events: %v", diff)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 	"database/sql"
 	"log"
+	"net/http"
 	"os"
 	"strconv"
 	"time"
@@ -21,6 +22,8 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	_ "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/auth"
 	eauthz "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/authz"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
+	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/explicit"
 	authzResolvers "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/authz/resolvers"
 	_ "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/internal/licensing"
@@ -38,6 +41,7 @@ func main() {
 	initLicensing()
 	initResolvers()
 	initLSIFEndpoints()
+	initRepoPermissionsEndpoints()
 
 	// Connect to the database.
 	if err := shared.InitDB(); err != nil {
@@ -75,6 +79,8 @@ func main() {
 	repositories := repos.NewDBStore(dbconn.Global, sql.TxOptions{})
 
 	githubWebhook := campaigns.NewGitHubWebhook(campaignsStore, repositories, clock)
+	gitlabWebhook := campaigns.NewGitLabWebhook(campaignsStore, repositories, clock)
+	bitbucketCloudWebhook := campaigns.NewBitbucketCloudWebhook(campaignsStore, repositories, clock)
 
 	bitbucketWebhookName := "sourcegraph-" + globalState.SiteID
 	bitbucketServerWebhook := campaigns.NewBitbucketServerWebhook(
@@ -86,7 +92,7 @@ func main() {
 
 	go bitbucketServerWebhook.Upsert(30 * time.Second)
 
-	shared.Main(githubWebhook, bitbucketServerWebhook)
+	shared.Main(githubWebhook, gitlabWebhook, bitbucketServerWebhook, bitbucketCloudWebhook)
 }
 
 func initLicensing() {
@@ -138,6 +144,14 @@ func initLSIFEndpoints() {
 	httpapi.NewLSIFServerProxy = proxy.NewProxy
 }
 
+func initRepoPermissionsEndpoints() {
+	httpapi.NewRepoPermissionsUploadHandler = func() http.Handler {
+		return explicit.NewUploadHandler(edb.NewPermsStore(dbconn.Global, func() time.Time {
+			return time.Now().UTC().Truncate(time.Microsecond)
+		}))
+	}
+}
+
 type usersStore struct{}
 
 func (usersStore) Count(ctx context.Context) (int, error) {
@@ -0,0 +1,67 @@
+package authz
+
+import (
+	"context"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
+)
+
+// AccessGrantsExpirer periodically revokes time-limited repository access grants that have
+// expired, and records an event log entry for each of them.
+//
+// Expired access grants are never honoured regardless, revoking them keeps the record of when
+// access actually ended.
+type AccessGrantsExpirer struct {
+	// The database interface for any permissions operations.
+	permsStore *edb.PermsStore
+	// The mockable function to return the current time.
+	clock func() time.Time
+	// The mockable function to record an event log entry.
+	logEvent func(ctx context.Context, e *db.Event) error
+}
+
+// NewAccessGrantsExpirer returns a new access grants expirer.
+func NewAccessGrantsExpirer(permsStore *edb.PermsStore, clock func() time.Time) *AccessGrantsExpirer {
+	return &AccessGrantsExpirer{
+		permsStore: permsStore,
+		clock:      clock,
+		logEvent:   db.EventLogs.Insert,
+	}
+}
+
+// revokeExpired revokes expired access grants and records an event log entry for each of them.
+func (e *AccessGrantsExpirer) revokeExpired(ctx context.Context) error {
+	grants, err := e.permsStore.RevokeExpiredAccessGrants(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range grants {
+		err = e.logEvent(ctx, edb.NewAccessGrantEvent(edb.AccessGrantExpiredEvent, 0, g, e.clock()))
+		if err != nil {
+			log15.Error("Failed to log access grant expiry", "grantID", g.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// Run revokes expired access grants every interval until the context is canceled.
+func (e *AccessGrantsExpirer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := e.revokeExpired(ctx); err != nil {
+			log15.Error("Failed to revoke expired access grants", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
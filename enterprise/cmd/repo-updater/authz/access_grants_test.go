@@ -0,0 +1,69 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
+	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
+)
+
+func TestAccessGrantsExpirer_revokeExpired(t *testing.T) {
+	now := time.Unix(1e9, 0).UTC()
+	expiresAt := now.Add(-time.Minute)
+
+	grants := []*authz.AccessGrant{
+		{ID: 1, RepoID: 1, UserID: 1, ExpiresAt: expiresAt},
+		{ID: 2, RepoID: 2, OrgID: 1, ExpiresAt: expiresAt},
+	}
+	edb.Mocks.Perms.RevokeExpiredAccessGrants = func(context.Context) ([]*authz.AccessGrant, error) {
+		return grants, nil
+	}
+	defer func() {
+		edb.Mocks.Perms = edb.MockPerms{}
+	}()
+
+	e := NewAccessGrantsExpirer(edb.NewPermsStore(nil, nil), func() time.Time { return now })
+
+	var logged []*db.Event
+	e.logEvent = func(_ context.Context, ev *db.Event) error {
+		logged = append(logged, ev)
+		return errors.New("log failure does not stop revocation")
+	}
+
+	if err := e.revokeExpired(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []*db.Event{
+		{
+			Name:            edb.AccessGrantExpiredEvent,
+			AnonymousUserID: "backend",
+			Argument:        json.RawMessage(`{"id":1,"repo_id":1,"user_id":1,"expires_at":"2001-09-09T01:45:40Z"}`),
+			Source:          "BACKEND",
+			Timestamp:       now,
+		},
+		{
+			Name:            edb.AccessGrantExpiredEvent,
+			AnonymousUserID: "backend",
+			Argument:        json.RawMessage(`{"id":2,"repo_id":2,"org_id":1,"expires_at":"2001-09-09T01:45:40Z"}`),
+			Source:          "BACKEND",
+			Timestamp:       now,
+		},
+	}
+	if diff := cmp.Diff(want, logged); diff != "" {
+		t.Fatalf("logged events: %v", diff)
+	}
+
+	edb.Mocks.Perms.RevokeExpiredAccessGrants = func(context.Context) ([]*authz.AccessGrant, error) {
+		return nil, errors.New("boom")
+	}
+	if err := e.revokeExpired(context.Background()); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/RoaringBitmap/roaring"
@@ -19,6 +20,8 @@ import (
 	edb "github.com/sourcegraph/sourcegraph/enterprise/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater/protocol"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 )
 
@@ -37,6 +40,10 @@ type PermsSyncer struct {
 	clock func() time.Time
 	// The time duration of how often to re-compute schedule for users and repositories.
 	scheduleInterval time.Duration
+	// The results of the last permissions syncs of users and repositories, keyed by their IDs.
+	resultsMu   sync.RWMutex
+	userResults map[int32]*protocol.PermsSyncResult
+	repoResults map[api.RepoID]*protocol.PermsSyncResult
 	// The metrics that are exposed to Prometheus.
 	metrics struct {
 		noPerms      *prometheus.GaugeVec
@@ -84,6 +91,8 @@ func NewPermsSyncer(
 		permsStore:       permsStore,
 		clock:            clock,
 		scheduleInterval: time.Minute,
+		userResults:      make(map[int32]*protocol.PermsSyncResult),
+		repoResults:      make(map[api.RepoID]*protocol.PermsSyncResult),
 	}
 	return s
 }
@@ -190,9 +199,10 @@ func (s *PermsSyncer) syncUserPerms(ctx context.Context, userID int32) (err erro
 		return errors.Wrap(err, "list external accounts")
 	}
 
+	fetchers := s.fetchers()
 	var repoSpecs []api.ExternalRepoSpec
 	for _, acct := range accts {
-		fetcher := s.fetchers()[acct.ServiceID]
+		fetcher := fetchers[acct.ServiceID]
 		if fetcher == nil {
 			// We have no authz provider configured for this external account.
 			continue
@@ -235,6 +245,14 @@ func (s *PermsSyncer) syncUserPerms(ctx context.Context, userID int32) (err erro
 		p.IDs.Add(uint32(rs[i].ID))
 	}
 
+	// Keep the permissions of repositories whose code hosts have no fetcher, such as explicit
+	// permissions, since they aren't synced from the code hosts and would be lost otherwise.
+	kept, err := s.unsyncedRepoIDs(ctx, userID, fetchers)
+	if err != nil {
+		return errors.Wrap(err, "load permissions of unsynced repositories")
+	}
+	p.IDs.Or(kept)
+
 	err = s.permsStore.SetUserPermissions(ctx, p)
 	if err != nil {
 		return errors.Wrap(err, "set user permissions")
@@ -244,6 +262,40 @@ func (s *PermsSyncer) syncUserPerms(ctx context.Context, userID int32) (err erro
 	return nil
 }
 
+// unsyncedRepoIDs returns the IDs of the repositories the user currently has permissions for
+// whose code hosts have no fetcher.
+func (s *PermsSyncer) unsyncedRepoIDs(ctx context.Context, userID int32, fetchers map[string]PermsFetcher) (*roaring.Bitmap, error) {
+	ids := roaring.NewBitmap()
+
+	p := &authz.UserPermissions{
+		UserID: userID,
+		Perm:   authz.Read,
+		Type:   authz.PermRepos,
+	}
+	if err := s.permsStore.LoadUserPermissions(ctx, p); err == authz.ErrPermsNotFound {
+		return ids, nil
+	} else if err != nil {
+		return nil, err
+	} else if p.IDs.IsEmpty() {
+		return ids, nil
+	}
+
+	repoIDs := make([]api.RepoID, 0, p.IDs.GetCardinality())
+	for _, id := range p.IDs.ToArray() {
+		repoIDs = append(repoIDs, api.RepoID(id))
+	}
+	rs, err := s.reposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: repoIDs})
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rs {
+		if fetchers[r.ExternalRepo.ServiceID] == nil {
+			ids.Add(uint32(r.ID))
+		}
+	}
+	return ids, nil
+}
+
 // syncRepoPerms processes permissions syncing request in repository-centric way.
 // It discards requests that are made for non-private repositories based on the
 // value of "repo.private" column.
@@ -348,19 +400,57 @@ func (s *PermsSyncer) syncRepoPerms(ctx context.Context, repoID api.RepoID) (err
 func (s *PermsSyncer) syncPerms(ctx context.Context, request *syncRequest) error {
 	defer s.queue.remove(request.Type, request.ID, true)
 
+	ctx = ratelimit.WithPriority(ctx, ratelimit.PriorityPermsSync)
+
 	var err error
+	result := new(protocol.PermsSyncResult)
 	switch request.Type {
 	case requestTypeUser:
+		result.StartedAt = s.clock()
 		err = s.syncUserPerms(ctx, request.ID)
 	case requestTypeRepo:
+		result.StartedAt = s.clock()
 		err = s.syncRepoPerms(ctx, api.RepoID(request.ID))
 	default:
-		err = fmt.Errorf("unexpected request type: %v", request.Type)
+		return fmt.Errorf("unexpected request type: %v", request.Type)
 	}
 
+	result.FinishedAt = s.clock()
+	if err != nil {
+		result.Error = err.Error()
+	}
+	s.saveResult(request.Type, request.ID, result)
+
 	return err
 }
 
+// saveResult saves the result of the last permissions sync of the user or repository.
+func (s *PermsSyncer) saveResult(typ requestType, id int32, result *protocol.PermsSyncResult) {
+	s.resultsMu.Lock()
+	defer s.resultsMu.Unlock()
+
+	switch typ {
+	case requestTypeUser:
+		s.userResults[id] = result
+	case requestTypeRepo:
+		s.repoResults[api.RepoID(id)] = result
+	}
+}
+
+// SyncInfo returns the results of the last permissions syncs of the user and the repository
+// since the syncer started.
+//
+// This method implements the repoupdater.Server.PermsSyncer.
+func (s *PermsSyncer) SyncInfo(userID int32, repoID api.RepoID) *protocol.PermsSyncInfoResponse {
+	s.resultsMu.RLock()
+	defer s.resultsMu.RUnlock()
+
+	return &protocol.PermsSyncInfoResponse{
+		User: s.userResults[userID],
+		Repo: s.repoResults[repoID],
+	}
+}
+
 func (s *PermsSyncer) runSync(ctx context.Context) {
 	log15.Debug("PermsSyncer.runSync.started")
 	defer log15.Info("PermsSyncer.runSync.stopped")
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/RoaringBitmap/roaring"
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,6 +17,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
+	"github.com/sourcegraph/sourcegraph/internal/repoupdater/protocol"
 )
 
 func TestPermsSyncer_ScheduleUsers(t *testing.T) {
@@ -110,6 +112,26 @@ func (s *mockReposStore) ListAllRepoNames(context.Context) ([]api.RepoName, erro
 	return nil, nil
 }
 
+func (s *mockReposStore) ListSyncCursors(context.Context, ...int64) ([]*repos.SyncCursor, error) {
+	return nil, nil
+}
+
+func (s *mockReposStore) UpsertSyncCursors(context.Context, ...*repos.SyncCursor) error {
+	return nil
+}
+
+func (s *mockReposStore) ListSyncRuns(context.Context, ...int64) ([]*repos.SyncRun, error) {
+	return nil, nil
+}
+
+func (s *mockReposStore) InsertSyncRuns(context.Context, ...*repos.SyncRun) error {
+	return nil
+}
+
+func (s *mockReposStore) DeleteSyncRuns(context.Context, time.Time) error {
+	return nil
+}
+
 type mockPermsStore struct {
 	listExternalAccounts func(context.Context, int32) ([]*extsvc.ExternalAccount, error)
 }
@@ -139,12 +161,17 @@ func TestPermsSyncer_syncUserPerms(t *testing.T) {
 	edb.Mocks.Perms.ListExternalAccounts = func(context.Context, int32) ([]*extsvc.ExternalAccount, error) {
 		return []*extsvc.ExternalAccount{&extAccount}, nil
 	}
+	edb.Mocks.Perms.LoadUserPermissions = func(_ context.Context, p *authz.UserPermissions) error {
+		p.IDs = roaring.BitmapOf(2, 3)
+		return nil
+	}
 	edb.Mocks.Perms.SetUserPermissions = func(_ context.Context, p *authz.UserPermissions) error {
 		if p.UserID != 1 {
 			return fmt.Errorf("UserID: want 1 but got %d", p.UserID)
 		}
 
-		expIDs := []uint32{1}
+		// Repository 2 is synced from GitLab, while the permissions of repository 3 are kept.
+		expIDs := []uint32{1, 3}
 		if diff := cmp.Diff(expIDs, p.IDs.ToArray()); diff != "" {
 			return fmt.Errorf("IDs: %v", diff)
 		}
@@ -156,6 +183,12 @@ func TestPermsSyncer_syncUserPerms(t *testing.T) {
 
 	reposStore := &mockReposStore{
 		listRepos: func(_ context.Context, args repos.StoreListReposArgs) ([]*repos.Repo, error) {
+			if len(args.IDs) > 0 {
+				return []*repos.Repo{
+					{ID: 2, ExternalRepo: api.ExternalRepoSpec{ServiceType: p.ServiceType(), ServiceID: p.ServiceID()}},
+					{ID: 3, ExternalRepo: api.ExternalRepoSpec{ServiceType: "gitolite", ServiceID: "git@gitolite.example.com"}},
+				}, nil
+			}
 			if !args.PrivateOnly {
 				return nil, errors.New("PrivateOnly want true but got false")
 			}
@@ -270,3 +303,44 @@ func TestPermsSyncer_syncPerms(t *testing.T) {
 		t.Fatalf("queue length: want 0 but got %d", s.queue.Len())
 	}
 }
+
+func TestPermsSyncer_SyncInfo(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	clock := func() time.Time { return now }
+
+	reposStore := &mockReposStore{
+		listRepos: func(context.Context, repos.StoreListReposArgs) ([]*repos.Repo, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	s := NewPermsSyncer(reposStore, edb.NewPermsStore(nil, clock), clock)
+	s.metrics.syncDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{}, []string{"type", "success"})
+	s.metrics.syncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{}, []string{"type"})
+
+	if diff := cmp.Diff(&protocol.PermsSyncInfoResponse{}, s.SyncInfo(1, 1)); diff != "" {
+		t.Fatalf("before sync: %v", diff)
+	}
+
+	request := &syncRequest{
+		requestMeta: &requestMeta{
+			Type: requestTypeRepo,
+			ID:   1,
+		},
+		acquired: true,
+	}
+	s.queue.Push(request)
+	if err := s.syncPerms(context.Background(), request); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	want := &protocol.PermsSyncInfoResponse{
+		Repo: &protocol.PermsSyncResult{
+			StartedAt:  now,
+			FinishedAt: now,
+			Error:      "list repositories: boom",
+		},
+	}
+	if diff := cmp.Diff(want, s.SyncInfo(1, 1)); diff != "" {
+		t.Fatalf("after sync: %v", diff)
+	}
+}
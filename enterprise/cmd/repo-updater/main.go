@@ -25,6 +25,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/debugserver"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/replacer"
 )
 
 func main() {
@@ -60,6 +61,26 @@ func enterpriseInit(
 	sourcer := repos.NewSourcer(cf)
 	go campaigns.RunWorkers(ctx, campaignsStore, clock, gitserver.DefaultClient, sourcer, 5*time.Second)
 
+	replacerDoer, err := cf.Doer()
+	if err != nil {
+		log.Fatalf("Failed to create replacer HTTP client: %v", err)
+	}
+	replacerClient := campaigns.NewReplacerClient(replacer.URL, replacerDoer)
+	go campaigns.RunPatchJobWorkers(ctx, campaignsStore, clock, replacerClient, 5*time.Second)
+
+	// Set up rebasing of open changesets onto their moved base branches
+	go campaigns.RunRebaseWorker(ctx, campaignsStore, clock, gitserver.DefaultClient, 30*time.Minute)
+
+	// Set up the release of changeset jobs of campaigns with a publish policy
+	go campaigns.RunPublicationScheduler(ctx, campaignsStore, clock, 1*time.Minute)
+
+	// Set up merging of approved changesets with passing checks
+	svc := campaigns.NewServiceWithClock(campaignsStore, gitserver.DefaultClient, cf, clock)
+	go campaigns.RunAutoMergeWorker(ctx, svc, 5*time.Minute)
+
+	// Set up bulk actions on the changesets of campaigns
+	go campaigns.RunChangesetActionWorkers(ctx, campaignsStore, clock, cf, 5*time.Second)
+
 	// Set up syncer
 	go syncer.Run(ctx)
 
@@ -78,9 +99,15 @@ func enterpriseInit(
 	dbconn.Global = db
 	permsStore := frontendDB.NewPermsStore(db, clock)
 	permsSyncer := authz.NewPermsSyncer(repoStore, permsStore, clock)
+	if server != nil {
+		server.PermsSyncer = permsSyncer
+	}
 	go startBackgroundPermsSync(ctx, permsSyncer, db)
 	debugDumpers = append(debugDumpers, permsSyncer)
 
+	// Set up expired repository access grants revocation
+	go authz.NewAccessGrantsExpirer(permsStore, clock).Run(ctx, time.Minute)
+
 	return debugDumpers
 }
 
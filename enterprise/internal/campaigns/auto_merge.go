@@ -0,0 +1,188 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/inconshreveable/log15"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// ErrAutoMergeClosedCampaign is returned by SetCampaignAutoMerge when
+// auto-merge is enabled for a closed Campaign.
+var ErrAutoMergeClosedCampaign = errors.New("cannot enable auto-merge for a closed campaign")
+
+// SetCampaignAutoMerge sets the AutoMergePolicy of the Campaign with the given
+// ID. A nil policy disables auto-merge.
+func (s *Service) SetCampaignAutoMerge(ctx context.Context, id int64, policy *campaigns.AutoMergePolicy) (campaign *campaigns.Campaign, err error) {
+	tr, ctx := trace.New(ctx, "service.SetCampaignAutoMerge", fmt.Sprintf("campaign: %d", id))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	if policy != nil {
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.store.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Done(&err)
+
+	campaign, err = tx.GetCampaign(ctx, GetCampaignOpts{ID: id})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting campaign")
+	}
+
+	if policy != nil && !campaign.ClosedAt.IsZero() {
+		return nil, ErrAutoMergeClosedCampaign
+	}
+
+	campaign.AutoMerge = policy
+	return campaign, tx.UpdateCampaign(ctx, campaign)
+}
+
+// RunAutoMergeWorker should be executed in a background goroutine and is
+// responsible for merging the Changesets of Campaigns with an AutoMergePolicy.
+// Every interval it calls AutoMergeChangesets.
+// ctx should be canceled to terminate the function.
+func RunAutoMergeWorker(ctx context.Context, svc *Service, interval time.Duration) {
+	for {
+		if err := svc.AutoMergeChangesets(ctx); err != nil {
+			log15.Error("Auto-merging changesets", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// AutoMergeChangesets merges the approved Changesets with passing checks of
+// all open Campaigns whose AutoMergePolicy allows merging at the current time.
+func (s *Service) AutoMergeChangesets(ctx context.Context) (err error) {
+	tr, ctx := trace.New(ctx, "service.AutoMergeChangesets", "")
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	cs, _, err := s.store.ListCampaigns(ctx, ListCampaignsOpts{
+		State:         campaigns.CampaignStateOpen,
+		OnlyAutoMerge: true,
+		Limit:         -1,
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing campaigns")
+	}
+
+	// Campaigns can have Changesets in the same repositories, so the merges
+	// per repository are counted across all Campaigns.
+	merged := make(map[api.RepoID]int)
+
+	now := s.clock()
+	errs := &multierror.Error{}
+	for _, c := range cs {
+		if !c.AutoMerge.InWindow(now) {
+			continue
+		}
+		if err := s.autoMergeCampaignChangesets(ctx, c, merged); err != nil {
+			errs = multierror.Append(errs, errors.Wrapf(err, "campaign %d", c.ID))
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// autoMergeCampaignChangesets merges the mergeable Changesets of the Campaign
+// in repositories with fewer merges than its AutoMergePolicy allows, and adds
+// the merges to the counts of merged, by repository.
+func (s *Service) autoMergeCampaignChangesets(ctx context.Context, c *campaigns.Campaign, merged map[api.RepoID]int) error {
+	cs, _, err := s.store.ListChangesets(ctx, ListChangesetsOpts{
+		CampaignID: c.ID,
+		Limit:      -1,
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing changesets")
+	}
+
+	cs = selectChangesets(cs, autoMergeable)
+	if len(cs) == 0 {
+		return nil
+	}
+
+	reposStore := repos.NewDBStore(s.store.DB(), sql.TxOptions{})
+	syncer := ChangesetSyncer{
+		ReposStore:  reposStore,
+		Store:       s.store,
+		HTTPFactory: s.cf,
+	}
+
+	bySource, err := syncer.GroupChangesetsBySource(ctx, cs...)
+	if err != nil {
+		return err
+	}
+
+	// The Changesets are only synced periodically, so they may have been
+	// closed, lost their approval or started failing their checks since. We
+	// sync them right before merging them and check their state again.
+	if err := syncer.SyncChangesetsWithSources(ctx, bySource); err != nil {
+		return errors.Wrap(err, "syncing changesets before merging")
+	}
+
+	limit := c.AutoMerge.MergesPerRepo()
+	mergedAny := false
+
+	errs := &multierror.Error{}
+	for _, s := range bySource {
+		merger, ok := s.ChangesetSource.(repos.ChangesetMerger)
+		if !ok {
+			continue
+		}
+
+		for _, ch := range s.Changesets {
+			repoID := ch.Changeset.RepoID
+			if merged[repoID] >= limit || !autoMergeable(ch.Changeset) {
+				continue
+			}
+
+			if err := merger.MergeChangeset(ctx, ch, c.AutoMerge.Method); err != nil {
+				errs = multierror.Append(errs, errors.Wrapf(err, "merging changeset %d", ch.Changeset.ID))
+				continue
+			}
+			merged[repoID]++
+			mergedAny = true
+		}
+	}
+
+	if mergedAny {
+		// Like in CloseOpenChangesets, we sync the merged Changesets to pick
+		// up the events produced by merging them.
+		if err := syncer.SyncChangesetsWithSources(ctx, bySource); err != nil {
+			errs = multierror.Append(errs, errors.Wrap(err, "syncing changesets"))
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// autoMergeable returns true if the Changeset is open, approved and its
+// checks passed.
+func autoMergeable(c *campaigns.Changeset) bool {
+	return c.ExternalState == campaigns.ChangesetStateOpen &&
+		c.ExternalReviewState == campaigns.ChangesetReviewStateApproved &&
+		c.ExternalCheckState == campaigns.ChangesetCheckStatePassed
+}
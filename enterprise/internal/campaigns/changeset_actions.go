@@ -0,0 +1,235 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// CreateChangesetAction creates the given ChangesetAction and a
+// ChangesetActionJob for every Changeset of its Campaign that matches its
+// filters. The jobs are executed in the background by the workers started
+// with RunChangesetActionWorkers.
+func (s *Service) CreateChangesetAction(ctx context.Context, a *campaigns.ChangesetAction) (err error) {
+	tr, ctx := trace.New(ctx, "service.CreateChangesetAction", fmt.Sprintf("campaign: %d, kind: %s", a.CampaignID, a.Kind))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	tx, err := s.store.Transact(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Done(&err)
+
+	if _, err = tx.GetCampaign(ctx, GetCampaignOpts{ID: a.CampaignID}); err != nil {
+		return errors.Wrap(err, "getting campaign")
+	}
+
+	opts := ListChangesetsOpts{
+		CampaignID:     a.CampaignID,
+		WithoutDeleted: true,
+		Limit:          -1,
+	}
+	if a.ExternalState != "" {
+		opts.ExternalState = &a.ExternalState
+	}
+	if a.ExternalReviewState != "" {
+		opts.ExternalReviewState = &a.ExternalReviewState
+	}
+	if a.ExternalCheckState != "" {
+		opts.ExternalCheckState = &a.ExternalCheckState
+	}
+
+	cs, _, err := tx.ListChangesets(ctx, opts)
+	if err != nil {
+		return errors.Wrap(err, "listing changesets")
+	}
+
+	if err = tx.CreateChangesetAction(ctx, a); err != nil {
+		return errors.Wrap(err, "creating changeset action")
+	}
+
+	for _, c := range cs {
+		job := &campaigns.ChangesetActionJob{ActionID: a.ID, ChangesetID: c.ID}
+		if err = tx.CreateChangesetActionJob(ctx, job); err != nil {
+			return errors.Wrap(err, "creating changeset action job")
+		}
+	}
+
+	return nil
+}
+
+// RunChangesetActionWorkers should be executed in a background goroutine and
+// is responsible for finding pending ChangesetActionJobs and executing them.
+// ctx should be canceled to terminate the function.
+func RunChangesetActionWorkers(ctx context.Context, s *Store, clock func() time.Time, cf *httpcli.Factory, backoffDuration time.Duration) {
+	process := func(ctx context.Context, s *Store, job campaigns.ChangesetActionJob) error {
+		if runErr := ExecChangesetActionJob(ctx, clock, s, cf, &job); runErr != nil {
+			log15.Error("ExecChangesetActionJob", "jobID", job.ID, "err", runErr)
+		}
+		// We don't return the error here so that we don't roll back the
+		// transaction. ExecChangesetActionJob saves the error in the job row.
+		return nil
+	}
+	worker := func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				didRun, err := s.ProcessPendingChangesetActionJobs(context.Background(), process)
+				if err != nil {
+					log15.Error("Running changeset action job", "err", err)
+				}
+				// Back off on error or when no jobs available
+				if err != nil || !didRun {
+					time.Sleep(backoffDuration)
+				}
+			}
+		}
+	}
+	for i := 0; i < workerCount(); i++ {
+		go worker()
+	}
+}
+
+// ExecChangesetActionJob executes the ChangesetAction of the given
+// ChangesetActionJob on the job's Changeset and, if that succeeded, syncs the
+// Changeset so that the effects of the action show up right away.
+func ExecChangesetActionJob(
+	ctx context.Context,
+	clock func() time.Time,
+	store *Store,
+	cf *httpcli.Factory,
+	job *campaigns.ChangesetActionJob,
+) (err error) {
+	// Store should already have an open transaction but ensure here anyway
+	store, err = store.Transact(ctx)
+	if err != nil {
+		return errors.Wrap(err, "creating transaction")
+	}
+
+	tr, ctx := trace.New(ctx, "service.ExecChangesetActionJob", fmt.Sprintf("job_id: %d", job.ID))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+	tr.LogFields(log.Int64("job_id", job.ID), log.Int64("action_id", job.ActionID), log.Int64("changeset_id", job.ChangesetID))
+
+	defer func() {
+		if err != nil {
+			job.Error = err.Error()
+		}
+		job.FinishedAt = clock()
+
+		if e := store.UpdateChangesetActionJob(ctx, job); e != nil {
+			if err == nil {
+				err = e
+			} else {
+				err = multierror.Append(err, e)
+			}
+		}
+	}()
+
+	if job.StartedAt.IsZero() {
+		job.StartedAt = clock()
+	}
+
+	a, err := store.GetChangesetAction(ctx, GetChangesetActionOpts{ID: job.ActionID})
+	if err != nil {
+		return errors.Wrap(err, "getting changeset action")
+	}
+
+	c, err := store.GetChangeset(ctx, GetChangesetOpts{ID: job.ChangesetID})
+	if err != nil {
+		return errors.Wrap(err, "getting changeset")
+	}
+
+	syncer := ChangesetSyncer{
+		ReposStore:  repos.NewDBStore(store.DB(), sql.TxOptions{}),
+		Store:       store,
+		HTTPFactory: cf,
+	}
+
+	bySource, err := syncer.GroupChangesetsBySource(ctx, c)
+	if err != nil {
+		return err
+	}
+	if len(bySource) != 1 || len(bySource[0].Changesets) != 1 {
+		return errors.Errorf("no code host connection found for changeset %d", c.ID)
+	}
+
+	if err = execChangesetAction(ctx, bySource[0].ChangesetSource, a, bySource[0].Changesets[0]); err != nil {
+		return err
+	}
+
+	// The action succeeded, so failing to sync shouldn't fail the job.
+	if err := syncer.SyncChangesetsWithSources(ctx, bySource); err != nil {
+		log15.Error("Syncing changeset after changeset action", "jobID", job.ID, "changesetID", c.ID, "err", err)
+	}
+
+	return nil
+}
+
+// execChangesetAction executes the given ChangesetAction on a single
+// Changeset with the given ChangesetSource. It returns an error if the code
+// host of the Changeset doesn't support the kind of action.
+func execChangesetAction(ctx context.Context, src repos.ChangesetSource, a *campaigns.ChangesetAction, c *repos.Changeset) error {
+	unsupported := errors.Errorf("%s is not supported by the code host of the changeset", a.Kind)
+
+	switch a.Kind {
+	case campaigns.ChangesetActionKindComment:
+		commenter, ok := src.(repos.ChangesetCommenter)
+		if !ok {
+			return unsupported
+		}
+		return commenter.CreateComment(ctx, c, a.Comment)
+
+	case campaigns.ChangesetActionKindAddLabels:
+		labeler, ok := src.(repos.ChangesetLabeler)
+		if !ok {
+			return unsupported
+		}
+		return labeler.AddLabels(ctx, c, a.Labels)
+
+	case campaigns.ChangesetActionKindRemoveLabels:
+		labeler, ok := src.(repos.ChangesetLabeler)
+		if !ok {
+			return unsupported
+		}
+		return labeler.RemoveLabels(ctx, c, a.Labels)
+
+	case campaigns.ChangesetActionKindRerequestReviews:
+		rerequester, ok := src.(repos.ChangesetReviewRerequester)
+		if !ok {
+			return unsupported
+		}
+		return rerequester.RerequestReviews(ctx, c)
+
+	case campaigns.ChangesetActionKindRerunChecks:
+		rerunner, ok := src.(repos.ChangesetCheckRerunner)
+		if !ok {
+			return unsupported
+		}
+		return rerunner.RerunChecks(ctx, c)
+
+	default:
+		return errors.Errorf("invalid changeset action %q", a.Kind)
+	}
+}
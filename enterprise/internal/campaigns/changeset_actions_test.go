@@ -0,0 +1,99 @@
+package campaigns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
+)
+
+func TestExecChangesetAction(t *testing.T) {
+	ctx := context.Background()
+	c := &repos.Changeset{Changeset: &cmpgn.Changeset{ID: 42}}
+
+	tests := []struct {
+		name      string
+		action    *cmpgn.ChangesetAction
+		wantCalls []string
+	}{
+		{
+			name:      "Comment",
+			action:    &cmpgn.ChangesetAction{Kind: cmpgn.ChangesetActionKindComment, Comment: "Please review"},
+			wantCalls: []string{"CreateComment 42 Please review"},
+		},
+		{
+			name:      "AddLabels",
+			action:    &cmpgn.ChangesetAction{Kind: cmpgn.ChangesetActionKindAddLabels, Labels: []string{"a", "b"}},
+			wantCalls: []string{"AddLabels 42 a,b"},
+		},
+		{
+			name:      "RemoveLabels",
+			action:    &cmpgn.ChangesetAction{Kind: cmpgn.ChangesetActionKindRemoveLabels, Labels: []string{"a"}},
+			wantCalls: []string{"RemoveLabels 42 a"},
+		},
+		{
+			name:      "RerequestReviews",
+			action:    &cmpgn.ChangesetAction{Kind: cmpgn.ChangesetActionKindRerequestReviews},
+			wantCalls: []string{"RerequestReviews 42"},
+		},
+		{
+			name:      "RerunChecks",
+			action:    &cmpgn.ChangesetAction{Kind: cmpgn.ChangesetActionKindRerunChecks},
+			wantCalls: []string{"RerunChecks 42"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			src := &fakeChangesetActionSource{}
+			if err := execChangesetAction(ctx, src, tc.action, c); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.wantCalls, src.calls); diff != "" {
+				t.Fatalf("wrong calls:\n%s", diff)
+			}
+		})
+
+		t.Run(tc.name+"Unsupported", func(t *testing.T) {
+			err := execChangesetAction(ctx, fakeChangesetSource{}, tc.action, c)
+			want := fmt.Sprintf("%s is not supported by the code host of the changeset", tc.action.Kind)
+			if err == nil || err.Error() != want {
+				t.Fatalf("have err %v, want %q", err, want)
+			}
+		})
+	}
+}
+
+type fakeChangesetActionSource struct {
+	fakeChangesetSource
+	calls []string
+}
+
+func (s *fakeChangesetActionSource) CreateComment(ctx context.Context, c *repos.Changeset, body string) error {
+	s.calls = append(s.calls, fmt.Sprintf("CreateComment %d %s", c.Changeset.ID, body))
+	return nil
+}
+
+func (s *fakeChangesetActionSource) AddLabels(ctx context.Context, c *repos.Changeset, labels []string) error {
+	s.calls = append(s.calls, fmt.Sprintf("AddLabels %d %s", c.Changeset.ID, strings.Join(labels, ",")))
+	return nil
+}
+
+func (s *fakeChangesetActionSource) RemoveLabels(ctx context.Context, c *repos.Changeset, labels []string) error {
+	s.calls = append(s.calls, fmt.Sprintf("RemoveLabels %d %s", c.Changeset.ID, strings.Join(labels, ",")))
+	return nil
+}
+
+func (s *fakeChangesetActionSource) RerequestReviews(ctx context.Context, c *repos.Changeset) error {
+	s.calls = append(s.calls, fmt.Sprintf("RerequestReviews %d", c.Changeset.ID))
+	return nil
+}
+
+func (s *fakeChangesetActionSource) RerunChecks(ctx context.Context, c *repos.Changeset) error {
+	s.calls = append(s.calls, fmt.Sprintf("RerunChecks %d", c.Changeset.ID))
+	return nil
+}
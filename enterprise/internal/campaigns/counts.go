@@ -1,12 +1,17 @@
 package campaigns
 
 import (
+	"encoding/csv"
 	"fmt"
+	"io"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 )
 
@@ -166,7 +171,9 @@ func computeCounts(c *ChangesetCounts, csEvents Events) error {
 
 		switch e.Type() {
 		case campaigns.ChangesetEventKindGitHubClosed,
-			campaigns.ChangesetEventKindBitbucketServerDeclined:
+			campaigns.ChangesetEventKindBitbucketServerDeclined,
+			campaigns.ChangesetEventKindGitLabClosed,
+			campaigns.ChangesetEventKindBitbucketCloudDeclined:
 
 			c.Open--
 			c.Closed++
@@ -175,7 +182,8 @@ func computeCounts(c *ChangesetCounts, csEvents Events) error {
 			c.AddReviewState(currentReviewState, -1)
 
 		case campaigns.ChangesetEventKindGitHubReopened,
-			campaigns.ChangesetEventKindBitbucketServerReopened:
+			campaigns.ChangesetEventKindBitbucketServerReopened,
+			campaigns.ChangesetEventKindGitLabReopened:
 
 			c.Open++
 			c.Closed--
@@ -184,7 +192,9 @@ func computeCounts(c *ChangesetCounts, csEvents Events) error {
 			c.AddReviewState(currentReviewState, 1)
 
 		case campaigns.ChangesetEventKindGitHubMerged,
-			campaigns.ChangesetEventKindBitbucketServerMerged:
+			campaigns.ChangesetEventKindBitbucketServerMerged,
+			campaigns.ChangesetEventKindGitLabMerged,
+			campaigns.ChangesetEventKindBitbucketCloudMerged:
 
 			// If it was closed, all "review counts" have been updated by the
 			// closed events and we just need to reverse these two counts
@@ -205,7 +215,10 @@ func computeCounts(c *ChangesetCounts, csEvents Events) error {
 
 		case campaigns.ChangesetEventKindGitHubReviewed,
 			campaigns.ChangesetEventKindBitbucketServerApproved,
-			campaigns.ChangesetEventKindBitbucketServerReviewed:
+			campaigns.ChangesetEventKindBitbucketServerReviewed,
+			campaigns.ChangesetEventKindGitLabApproved,
+			campaigns.ChangesetEventKindBitbucketCloudApproved,
+			campaigns.ChangesetEventKindBitbucketCloudChangesRequested:
 
 			s, err := reviewState(e)
 			if err != nil {
@@ -248,6 +261,9 @@ func computeCounts(c *ChangesetCounts, csEvents Events) error {
 			}
 
 		case campaigns.ChangesetEventKindBitbucketServerUnapproved,
+			campaigns.ChangesetEventKindGitLabUnapproved,
+			campaigns.ChangesetEventKindBitbucketCloudUnapproved,
+			campaigns.ChangesetEventKindBitbucketCloudChangesRequestRemoved,
 			campaigns.ChangesetEventKindGitHubReviewDismissed:
 			author, err := reviewAuthor(e)
 			if err != nil {
@@ -257,12 +273,24 @@ func computeCounts(c *ChangesetCounts, csEvents Events) error {
 				continue
 			}
 
-			if e.Type() == campaigns.ChangesetEventKindBitbucketServerUnapproved {
-				// A BitbucketServer Unapproved can only follow a previous Approved by
-				// the same author.
+			if e.Type() == campaigns.ChangesetEventKindBitbucketServerUnapproved ||
+				e.Type() == campaigns.ChangesetEventKindGitLabUnapproved ||
+				e.Type() == campaigns.ChangesetEventKindBitbucketCloudUnapproved {
+				// A BitbucketServer Unapproved, GitLab or Bitbucket Cloud unapproval
+				// can only follow a previous Approved by the same author.
 				lastReview, ok := lastReviewByAuthor[author]
 				if !ok || lastReview != campaigns.ChangesetReviewStateApproved {
-					log15.Warn("Bitbucket Server Unapproval not following an Approval", "event", e)
+					log15.Warn("Unapproval not following an Approval", "event", e)
+					continue
+				}
+			}
+
+			if e.Type() == campaigns.ChangesetEventKindBitbucketCloudChangesRequestRemoved {
+				// A Bitbucket Cloud removed change request can only follow a
+				// previous change request by the same author.
+				lastReview, ok := lastReviewByAuthor[author]
+				if !ok || lastReview != campaigns.ChangesetReviewStateChangesRequested {
+					log15.Warn("Removed change request not following a change request", "event", e)
 					continue
 				}
 			}
@@ -337,3 +365,367 @@ func computeReviewState(statesByAuthor map[string]campaigns.ChangesetReviewState
 	}
 	return campaigns.SelectReviewState(states)
 }
+
+// CampaignAnalytics are the analytics of the Changesets of a Campaign, as
+// computed by CalcAnalytics.
+type CampaignAnalytics struct {
+	// TimeToMerge is the distribution of the time it took the merged
+	// Changesets to be merged after they were opened.
+	TimeToMerge *DurationDistribution
+	// ReviewLatency is the distribution of the time it took the reviewed
+	// Changesets to receive their first review after they were opened.
+	ReviewLatency *DurationDistribution
+	// RepoOwners are the counts of Changesets per owner of their repository,
+	// sorted by the number of Changesets in descending order.
+	RepoOwners []*RepoOwnerCounts
+	// CheckFailures are the checks that fail on open Changesets, sorted by the
+	// number of Changesets they fail on in descending order.
+	CheckFailures []*CheckFailureCount
+	// Changesets are the analytics of the single Changesets, in the order in
+	// which they were passed to CalcAnalytics.
+	Changesets []*ChangesetAnalytics
+}
+
+// ChangesetAnalytics are the analytics of a single Changeset.
+type ChangesetAnalytics struct {
+	Changeset *campaigns.Changeset
+	RepoName  string
+	RepoOwner string
+
+	OpenedAt        time.Time
+	FirstReviewedAt time.Time
+	MergedAt        time.Time
+
+	FailedChecks []string
+}
+
+// TimeToMerge returns how long it took to merge the Changeset after it was
+// opened, or zero if it wasn't merged.
+func (a *ChangesetAnalytics) TimeToMerge() time.Duration {
+	if a.OpenedAt.IsZero() || a.MergedAt.IsZero() {
+		return 0
+	}
+	return a.MergedAt.Sub(a.OpenedAt)
+}
+
+// ReviewLatency returns how long it took the Changeset to receive its first
+// review after it was opened, or zero if it wasn't reviewed.
+func (a *ChangesetAnalytics) ReviewLatency() time.Duration {
+	if a.OpenedAt.IsZero() || a.FirstReviewedAt.IsZero() {
+		return 0
+	}
+	return a.FirstReviewedAt.Sub(a.OpenedAt)
+}
+
+// RepoOwnerCounts are the counts of the Changesets in the repositories of a
+// single owner, such as a GitHub organization or a GitLab group.
+type RepoOwnerCounts struct {
+	Owner  string
+	Total  int32
+	Open   int32
+	Merged int32
+	Closed int32
+}
+
+// CheckFailureCount is the number of Changesets a single check fails on.
+type CheckFailureCount struct {
+	Name       string
+	Changesets int32
+}
+
+// DurationDistribution is the distribution of a set of durations.
+type DurationDistribution struct {
+	Count   int32
+	Median  time.Duration
+	P90     time.Duration
+	Buckets []*DurationBucket
+}
+
+// DurationBucket is the number of durations that are shorter than Max and at
+// least as long as the Max of the previous bucket. The Max of the last
+// bucket is zero, which means it's unbounded.
+type DurationBucket struct {
+	Max   time.Duration
+	Count int32
+}
+
+// durationBucketMaxes are the upper bounds of the buckets of a
+// DurationDistribution, apart from the last, unbounded one.
+var durationBucketMaxes = []time.Duration{
+	24 * time.Hour,
+	3 * 24 * time.Hour,
+	7 * 24 * time.Hour,
+	14 * 24 * time.Hour,
+	28 * 24 * time.Hour,
+}
+
+// CalcAnalytics calculates the CampaignAnalytics of the given Changesets
+// and their Events. repoNames maps the IDs of the repositories of the
+// Changesets to their names, from which the owners of the repositories are
+// derived.
+func CalcAnalytics(cs []*campaigns.Changeset, repoNames map[api.RepoID]string, es ...Event) (*CampaignAnalytics, error) {
+	// Sort all events once by their timestamps
+	events := Events(es)
+	sort.Sort(events)
+
+	// Grouping Events by their Changeset ID
+	byChangesetID := make(map[int64]Events)
+	for _, e := range events {
+		id := e.Changeset()
+		byChangesetID[id] = append(byChangesetID[id], e)
+	}
+
+	var (
+		analytics     = &CampaignAnalytics{Changesets: make([]*ChangesetAnalytics, 0, len(cs))}
+		timesToMerge  []time.Duration
+		reviewLatency []time.Duration
+		byOwner       = make(map[string]*RepoOwnerCounts)
+		byCheck       = make(map[string]*CheckFailureCount)
+	)
+
+	for _, c := range cs {
+		a := &ChangesetAnalytics{
+			Changeset: c,
+			RepoName:  repoNames[c.RepoID],
+			OpenedAt:  c.ExternalCreatedAt(),
+		}
+		a.RepoOwner = repoOwner(a.RepoName)
+
+		for _, e := range byChangesetID[c.ID] {
+			if e.Timestamp().IsZero() {
+				continue
+			}
+
+			switch e.Type() {
+			case campaigns.ChangesetEventKindGitHubMerged,
+				campaigns.ChangesetEventKindBitbucketServerMerged,
+				campaigns.ChangesetEventKindGitLabMerged,
+				campaigns.ChangesetEventKindBitbucketCloudMerged:
+
+				if a.MergedAt.IsZero() {
+					a.MergedAt = e.Timestamp()
+				}
+
+			case campaigns.ChangesetEventKindGitHubReviewed,
+				campaigns.ChangesetEventKindBitbucketServerApproved,
+				campaigns.ChangesetEventKindBitbucketServerReviewed,
+				campaigns.ChangesetEventKindGitLabApproved,
+				campaigns.ChangesetEventKindBitbucketCloudApproved,
+				campaigns.ChangesetEventKindBitbucketCloudChangesRequested:
+
+				if !a.FirstReviewedAt.IsZero() {
+					continue
+				}
+
+				s, err := reviewState(e)
+				if err != nil {
+					return nil, err
+				}
+				// Pending reviews haven't been submitted yet
+				if s == campaigns.ChangesetReviewStatePending {
+					continue
+				}
+
+				author, err := reviewAuthor(e)
+				if err != nil {
+					return nil, err
+				}
+				if author == "" {
+					continue
+				}
+
+				a.FirstReviewedAt = e.Timestamp()
+			}
+		}
+
+		if d := a.TimeToMerge(); d > 0 {
+			timesToMerge = append(timesToMerge, d)
+		}
+		if d := a.ReviewLatency(); d > 0 {
+			reviewLatency = append(reviewLatency, d)
+		}
+
+		owner, ok := byOwner[a.RepoOwner]
+		if !ok {
+			owner = &RepoOwnerCounts{Owner: a.RepoOwner}
+			byOwner[a.RepoOwner] = owner
+			analytics.RepoOwners = append(analytics.RepoOwners, owner)
+		}
+		owner.Total++
+		switch c.ExternalState {
+		case campaigns.ChangesetStateOpen:
+			owner.Open++
+		case campaigns.ChangesetStateMerged:
+			owner.Merged++
+		case campaigns.ChangesetStateClosed, campaigns.ChangesetStateDeleted:
+			owner.Closed++
+		}
+
+		if c.ExternalState == campaigns.ChangesetStateOpen && c.ExternalCheckState == campaigns.ChangesetCheckStateFailed {
+			a.FailedChecks = campaigns.FailedChecks(c)
+			for _, name := range a.FailedChecks {
+				check, ok := byCheck[name]
+				if !ok {
+					check = &CheckFailureCount{Name: name}
+					byCheck[name] = check
+					analytics.CheckFailures = append(analytics.CheckFailures, check)
+				}
+				check.Changesets++
+			}
+		}
+
+		analytics.Changesets = append(analytics.Changesets, a)
+	}
+
+	analytics.TimeToMerge = calcDurationDistribution(timesToMerge)
+	analytics.ReviewLatency = calcDurationDistribution(reviewLatency)
+
+	sort.SliceStable(analytics.RepoOwners, func(i, j int) bool {
+		a, b := analytics.RepoOwners[i], analytics.RepoOwners[j]
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.Owner < b.Owner
+	})
+
+	sort.SliceStable(analytics.CheckFailures, func(i, j int) bool {
+		a, b := analytics.CheckFailures[i], analytics.CheckFailures[j]
+		if a.Changesets != b.Changesets {
+			return a.Changesets > b.Changesets
+		}
+		return a.Name < b.Name
+	})
+
+	return analytics, nil
+}
+
+// calcDurationDistribution calculates the DurationDistribution of the given
+// durations, which it sorts.
+func calcDurationDistribution(ds []time.Duration) *DurationDistribution {
+	dist := &DurationDistribution{Count: int32(len(ds))}
+	for _, max := range durationBucketMaxes {
+		dist.Buckets = append(dist.Buckets, &DurationBucket{Max: max})
+	}
+	last := &DurationBucket{}
+	dist.Buckets = append(dist.Buckets, last)
+
+	if len(ds) == 0 {
+		return dist
+	}
+
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	dist.Median = percentile(ds, 50)
+	dist.P90 = percentile(ds, 90)
+
+	for _, d := range ds {
+		bucket := last
+		for _, b := range dist.Buckets[:len(dist.Buckets)-1] {
+			if d < b.Max {
+				bucket = b
+				break
+			}
+		}
+		bucket.Count++
+	}
+
+	return dist
+}
+
+// percentile returns the p-th percentile of the sorted durations, using the
+// nearest-rank method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// repoOwner returns the owner of the repository with the given name, which is
+// the part of the name between the code host and the name of the repository,
+// such as the organization on GitHub or the (sub)group on GitLab.
+func repoOwner(name string) string {
+	parts := strings.Split(name, "/")
+	if len(parts) < 3 {
+		return ""
+	}
+	return strings.Join(parts[1:len(parts)-1], "/")
+}
+
+// WriteAnalyticsCSV writes the analytics of the single Changesets of the
+// given CampaignAnalytics as CSV to w, one Changeset per row.
+func WriteAnalyticsCSV(w io.Writer, a *CampaignAnalytics) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write([]string{
+		"changeset_id",
+		"repository",
+		"repository_owner",
+		"url",
+		"state",
+		"review_state",
+		"check_state",
+		"opened_at",
+		"first_reviewed_at",
+		"merged_at",
+		"review_latency_hours",
+		"time_to_merge_hours",
+		"failed_checks",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ca := range a.Changesets {
+		c := ca.Changeset
+
+		// Changesets without metadata don't have a URL yet
+		url, _ := c.URL()
+
+		err := cw.Write([]string{
+			strconv.FormatInt(c.ID, 10),
+			escapeCSVFormula(ca.RepoName),
+			escapeCSVFormula(ca.RepoOwner),
+			escapeCSVFormula(url),
+			string(c.ExternalState),
+			string(c.ExternalReviewState),
+			string(c.ExternalCheckState),
+			formatCSVTime(ca.OpenedAt),
+			formatCSVTime(ca.FirstReviewedAt),
+			formatCSVTime(ca.MergedAt),
+			formatCSVHours(ca.ReviewLatency()),
+			formatCSVHours(ca.TimeToMerge()),
+			escapeCSVFormula(strings.Join(ca.FailedChecks, "; ")),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// escapeCSVFormula prefixes a cell that spreadsheet applications would
+// interpret as a formula with a "'", so that opening the CSV doesn't evaluate
+// formulas in the names of repositories and checks on the codehosts.
+func escapeCSVFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatCSVHours(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(d.Hours(), 'f', 1, 64)
+}
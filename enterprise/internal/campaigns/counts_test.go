@@ -1,11 +1,13 @@
 package campaigns
 
 import (
+	"bytes"
 	"reflect"
 	"testing"
 	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
@@ -1198,6 +1200,175 @@ func TestCalcCounts(t *testing.T) {
 	}
 }
 
+func TestCalcAnalytics(t *testing.T) {
+	base := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) time.Time { return base.Add(time.Duration(hours) * time.Hour) }
+
+	withState := func(c *campaigns.Changeset, state campaigns.ChangesetState, checkState campaigns.ChangesetCheckState) *campaigns.Changeset {
+		c.ExternalState = state
+		c.ExternalCheckState = checkState
+		return c
+	}
+
+	failing := ghChangeset(2, at(0))
+	{
+		var commit github.CommitWithChecks
+		commit.Commit.Status.Contexts = []github.Context{
+			{Context: "ci/lint", State: "FAILURE"},
+			{Context: "ci/build", State: "SUCCESS"},
+		}
+		failing.Metadata.(*github.PullRequest).Commits.Nodes = []github.CommitWithChecks{commit}
+	}
+
+	cs := []*campaigns.Changeset{
+		withState(ghChangeset(1, at(0)), campaigns.ChangesetStateMerged, campaigns.ChangesetCheckStatePassed),
+		withState(failing, campaigns.ChangesetStateOpen, campaigns.ChangesetCheckStateFailed),
+		withState(bbsChangeset(3, at(0)), campaigns.ChangesetStateMerged, campaigns.ChangesetCheckStatePassed),
+		withState(ghChangeset(4, at(0)), campaigns.ChangesetStateClosed, campaigns.ChangesetCheckStateUnknown),
+	}
+	for i, c := range cs {
+		c.RepoID = api.RepoID(i + 1)
+	}
+
+	repoNames := map[api.RepoID]string{
+		1: "github.com/sourcegraph/sourcegraph",
+		2: "github.com/sourcegraph/about",
+		3: "bitbucket.example.com/PROJ/repo",
+		4: "gitlab.com/a/b/c",
+	}
+
+	events := []Event{
+		ghReview(1, at(1), "bob", "PENDING"),
+		ghReview(1, at(2), "alice", "APPROVED"),
+		ghReview(1, at(3), "bob", "COMMENTED"),
+		fakeEvent{t: at(30), kind: campaigns.ChangesetEventKindGitHubMerged, id: 1},
+		bbsActivity(3, at(10), "carol", campaigns.ChangesetEventKindBitbucketServerApproved),
+		fakeEvent{t: at(240), kind: campaigns.ChangesetEventKindBitbucketServerMerged, id: 3},
+	}
+
+	have, err := CalcAnalytics(cs, repoNames, events...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buckets := func(counts ...int32) []*DurationBucket {
+		bs := make([]*DurationBucket, 0, len(counts))
+		for i, n := range counts {
+			b := &DurationBucket{Count: n}
+			if i < len(durationBucketMaxes) {
+				b.Max = durationBucketMaxes[i]
+			}
+			bs = append(bs, b)
+		}
+		return bs
+	}
+
+	want := &CampaignAnalytics{
+		TimeToMerge: &DurationDistribution{
+			Count:   2,
+			Median:  30 * time.Hour,
+			P90:     240 * time.Hour,
+			Buckets: buckets(0, 1, 0, 1, 0, 0),
+		},
+		ReviewLatency: &DurationDistribution{
+			Count:   2,
+			Median:  2 * time.Hour,
+			P90:     10 * time.Hour,
+			Buckets: buckets(2, 0, 0, 0, 0, 0),
+		},
+		RepoOwners: []*RepoOwnerCounts{
+			{Owner: "sourcegraph", Total: 2, Open: 1, Merged: 1},
+			{Owner: "PROJ", Total: 1, Merged: 1},
+			{Owner: "a/b", Total: 1, Closed: 1},
+		},
+		CheckFailures: []*CheckFailureCount{
+			{Name: "ci/lint", Changesets: 1},
+		},
+		Changesets: []*ChangesetAnalytics{
+			{Changeset: cs[0], RepoName: repoNames[1], RepoOwner: "sourcegraph", OpenedAt: at(0), FirstReviewedAt: at(2), MergedAt: at(30)},
+			{Changeset: cs[1], RepoName: repoNames[2], RepoOwner: "sourcegraph", OpenedAt: at(0), FailedChecks: []string{"ci/lint"}},
+			{Changeset: cs[2], RepoName: repoNames[3], RepoOwner: "PROJ", OpenedAt: at(0), FirstReviewedAt: at(10), MergedAt: at(240)},
+			{Changeset: cs[3], RepoName: repoNames[4], RepoOwner: "a/b", OpenedAt: at(0)},
+		},
+	}
+
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := WriteAnalyticsCSV(&buf, have); err != nil {
+			t.Fatal(err)
+		}
+
+		want := `changeset_id,repository,repository_owner,url,state,review_state,check_state,opened_at,first_reviewed_at,merged_at,review_latency_hours,time_to_merge_hours,failed_checks
+1,github.com/sourcegraph/sourcegraph,sourcegraph,,MERGED,,PASSED,2020-03-01T00:00:00Z,2020-03-01T02:00:00Z,2020-03-02T06:00:00Z,2.0,30.0,
+2,github.com/sourcegraph/about,sourcegraph,,OPEN,,FAILED,2020-03-01T00:00:00Z,,,,,ci/lint
+3,bitbucket.example.com/PROJ/repo,PROJ,,MERGED,,PASSED,2020-03-01T00:00:00Z,2020-03-01T10:00:00Z,2020-03-11T00:00:00Z,10.0,240.0,
+4,gitlab.com/a/b/c,a/b,,CLOSED,,UNKNOWN,2020-03-01T00:00:00Z,,,,,
+`
+		if diff := cmp.Diff(want, buf.String()); diff != "" {
+			t.Fatal(diff)
+		}
+	})
+}
+
+func TestWriteAnalyticsCSVEscapesFormulas(t *testing.T) {
+	a := &CampaignAnalytics{
+		Changesets: []*ChangesetAnalytics{{
+			Changeset:    &campaigns.Changeset{ID: 1},
+			RepoName:     "@example.com/a/b",
+			RepoOwner:    "-a",
+			FailedChecks: []string{`=HYPERLINK("https://example.com")`, "ci/lint"},
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAnalyticsCSV(&buf, a); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `changeset_id,repository,repository_owner,url,state,review_state,check_state,opened_at,first_reviewed_at,merged_at,review_latency_hours,time_to_merge_hours,failed_checks
+1,'@example.com/a/b,'-a,,,,,,,,,,"'=HYPERLINK(""https://example.com""); ci/lint"
+`
+	if diff := cmp.Diff(want, buf.String()); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestCalcDurationDistribution(t *testing.T) {
+	dist := calcDurationDistribution(nil)
+	if dist.Count != 0 || dist.Median != 0 || dist.P90 != 0 {
+		t.Fatalf("unexpected distribution of no durations: %+v", dist)
+	}
+	if have, want := len(dist.Buckets), len(durationBucketMaxes)+1; have != want {
+		t.Fatalf("have %d buckets, want %d", have, want)
+	}
+
+	ds := make([]time.Duration, 0, 10)
+	for i := 10; i > 0; i-- {
+		ds = append(ds, time.Duration(i)*24*time.Hour)
+	}
+
+	dist = calcDurationDistribution(ds)
+	if have, want := dist.Median, 5*24*time.Hour; have != want {
+		t.Errorf("have median %s, want %s", have, want)
+	}
+	if have, want := dist.P90, 9*24*time.Hour; have != want {
+		t.Errorf("have p90 %s, want %s", have, want)
+	}
+
+	var counts []int32
+	for _, b := range dist.Buckets {
+		counts = append(counts, b.Count)
+	}
+	// 1 day is not shorter than the first bucket's maximum of 1 day.
+	if diff := cmp.Diff([]int32{0, 2, 4, 4, 0, 0}, counts); diff != "" {
+		t.Errorf("wrong bucket counts:\n%s", diff)
+	}
+}
+
 type fakeEvent struct {
 	t    time.Time
 	kind campaigns.ChangesetEventKind
@@ -20,6 +20,8 @@ func TestIntegration(t *testing.T) {
 
 	t.Run("Store", testStore(db))
 	t.Run("GitHubWebhook", testGitHubWebhook(db))
+	t.Run("GitLabWebhook", testGitLabWebhook(db))
+	t.Run("BitbucketCloudWebhook", testBitbucketCloudWebhook(db))
 
 	// The following tests need to be separate because testStore above wraps everything in a global transaction
 	t.Run("StoreLocking", testStoreLocking(db))
@@ -0,0 +1,239 @@
+package campaigns
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	replacerprotocol "github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// ReplacerClient rewrites the files of a repository at a given commit.
+type ReplacerClient interface {
+	// Replace returns the file diffs produced by the given request, in the
+	// order in which the replacer returned them.
+	Replace(ctx context.Context, req *replacerprotocol.Request) ([]*ReplacerFileDiff, error)
+}
+
+// ReplacerFileDiff is the rewrite of a single file returned by the replacer.
+type ReplacerFileDiff struct {
+	URI  string `json:"uri"`
+	Diff string `json:"diff"`
+}
+
+// NewReplacerClient returns a ReplacerClient that talks to the replacer
+// service at the given URL.
+func NewReplacerClient(rawurl string, cli httpcli.Doer) ReplacerClient {
+	return &replacerClient{url: rawurl, cli: cli}
+}
+
+type replacerClient struct {
+	url string
+	cli httpcli.Doer
+}
+
+func (c *replacerClient) Replace(ctx context.Context, r *replacerprotocol.Request) ([]*ReplacerFileDiff, error) {
+	u, err := url.Parse(c.url)
+	if err != nil {
+		return nil, err
+	}
+	q := u.Query()
+	q.Set("repo", string(r.Repo))
+	q.Set("commit", string(r.Commit))
+	q.Set("fetchtimeout", r.FetchTimeout)
+	q.Set("matchtemplate", r.MatchTemplate)
+	q.Set("rewritetemplate", r.RewriteTemplate)
+	q.Set("fileextension", r.FileExtension)
+	q.Set("directoryexclude", r.DirectoryExclude)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.cli.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "replacer request failed")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, errors.Errorf("replacer returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var diffs []*ReplacerFileDiff
+	scanner := bufio.NewScanner(resp.Body)
+	// Results are line encoded JSON and a single line contains the whole diff
+	// of a file, so we allow much longer lines than the default.
+	scanner.Buffer(make([]byte, 100), 10*bufio.MaxScanTokenSize)
+	for scanner.Scan() {
+		var d ReplacerFileDiff
+		if err := json.Unmarshal(scanner.Bytes(), &d); err != nil {
+			return nil, errors.Wrap(err, "decoding replacer result")
+		}
+		diffs = append(diffs, &d)
+	}
+	return diffs, scanner.Err()
+}
+
+// replacerFetchTimeout is how long the replacer waits for the archive of a
+// repository. Patch jobs run in the background, so we'd rather wait than fail.
+const replacerFetchTimeout = "1m"
+
+// RunPatchJobWorkers should be executed in a background goroutine and is
+// responsible for finding pending PatchJobs and executing them.
+// ctx should be canceled to terminate the function.
+func RunPatchJobWorkers(ctx context.Context, s *Store, clock func() time.Time, replacer ReplacerClient, backoffDuration time.Duration) {
+	process := func(ctx context.Context, s *Store, job campaigns.PatchJob) error {
+		if runErr := ExecPatchJob(ctx, clock, s, replacer, &job); runErr != nil {
+			log15.Error("ExecPatchJob", "jobID", job.ID, "err", runErr)
+		}
+		// We don't return the error here so that we don't roll back the
+		// transaction. ExecPatchJob saves the error in the job row.
+		return nil
+	}
+	worker := func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				didRun, err := s.ProcessPendingPatchJobs(context.Background(), process)
+				if err != nil {
+					log15.Error("Running patch job", "err", err)
+				}
+				// Back off on error or when no jobs available
+				if err != nil || !didRun {
+					time.Sleep(backoffDuration)
+				}
+			}
+		}
+	}
+	for i := 0; i < workerCount(); i++ {
+		go worker()
+	}
+}
+
+// ExecPatchJob runs the rewrite of the given PatchJob through the replacer
+// and, if it changed any files, creates a Patch in the job's PatchSet.
+func ExecPatchJob(
+	ctx context.Context,
+	clock func() time.Time,
+	store *Store,
+	replacer ReplacerClient,
+	job *campaigns.PatchJob,
+) (err error) {
+	// Store should already have an open transaction but ensure here anyway
+	store, err = store.Transact(ctx)
+	if err != nil {
+		return errors.Wrap(err, "creating transaction")
+	}
+
+	tr, ctx := trace.New(ctx, "service.ExecPatchJob", fmt.Sprintf("job_id: %d", job.ID))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+	tr.LogFields(log.Int64("job_id", job.ID), log.Int64("patch_set_id", job.PatchSetID))
+
+	defer func() {
+		if err != nil {
+			job.Error = err.Error()
+		}
+		job.FinishedAt = clock()
+
+		if e := store.UpdatePatchJob(ctx, job); e != nil {
+			if err == nil {
+				err = e
+			} else {
+				err = multierror.Append(err, e)
+			}
+		}
+	}()
+
+	if job.StartedAt.IsZero() {
+		job.StartedAt = clock()
+	}
+
+	reposStore := repos.NewDBStore(store.DB(), sql.TxOptions{})
+	rs, err := reposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: []api.RepoID{job.RepoID}})
+	if err != nil {
+		return err
+	}
+	if len(rs) != 1 {
+		return errors.Errorf("repo not found: %d", job.RepoID)
+	}
+
+	fileDiffs, err := replacer.Replace(ctx, &replacerprotocol.Request{
+		Repo:         api.RepoName(rs[0].Name),
+		Commit:       job.Rev,
+		FetchTimeout: replacerFetchTimeout,
+		RewriteSpecification: replacerprotocol.RewriteSpecification{
+			MatchTemplate:    job.MatchTemplate,
+			RewriteTemplate:  job.RewriteTemplate,
+			FileExtension:    job.FileExtension,
+			DirectoryExclude: job.DirectoryExclude,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	diff, err := unifiedDiff(fileDiffs)
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		return nil
+	}
+
+	return store.CreatePatch(ctx, &campaigns.Patch{
+		PatchSetID: job.PatchSetID,
+		RepoID:     job.RepoID,
+		Rev:        job.Rev,
+		BaseRef:    job.BaseRef,
+		Diff:       diff,
+	})
+}
+
+// unifiedDiff combines the file diffs returned by the replacer into a single
+// diff in the format expected by ExecChangesetJob: without `a/` and `b/`
+// filename prefixes and without a trailing newline.
+func unifiedDiff(fileDiffs []*ReplacerFileDiff) (string, error) {
+	sorted := make([]*ReplacerFileDiff, len(fileDiffs))
+	copy(sorted, fileDiffs)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].URI < sorted[j].URI })
+
+	var b strings.Builder
+	for _, d := range sorted {
+		i := strings.Index(d.Diff, "@@")
+		if i < 0 {
+			return "", errors.Errorf("invalid diff for %q does not contain expected @@", d.URI)
+		}
+		fmt.Fprintf(&b, "diff %s %s\n--- %s\n+++ %s\n", d.URI, d.URI, d.URI, d.URI)
+		b.WriteString(d.Diff[i:])
+		if !strings.HasSuffix(d.Diff, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimSuffix(b.String(), "\n"), nil
+}
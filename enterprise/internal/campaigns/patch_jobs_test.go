@@ -0,0 +1,98 @@
+package campaigns
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	replacerprotocol "github.com/sourcegraph/sourcegraph/cmd/replacer/protocol"
+)
+
+func TestReplacerClient(t *testing.T) {
+	var query map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		fmt.Fprintln(w, `{"uri":"a.go","diff":"--- a.go\n+++ a.go\n@@ -1 +1 @@\n-fmt.Sprintf(x)\n+fmt.Sprint(x)"}`)
+		fmt.Fprintln(w, `{"uri":"b.go","diff":"--- b.go\n+++ b.go\n@@ -2 +2 @@\n-fmt.Sprintf(y)\n+fmt.Sprint(y)"}`)
+	}))
+	defer srv.Close()
+
+	cli := NewReplacerClient(srv.URL, http.DefaultClient)
+	diffs, err := cli.Replace(context.Background(), &replacerprotocol.Request{
+		Repo:   "github.com/sourcegraph/sourcegraph",
+		Commit: "deadbeef",
+		RewriteSpecification: replacerprotocol.RewriteSpecification{
+			MatchTemplate:   "fmt.Sprintf(:[args])",
+			RewriteTemplate: "fmt.Sprint(:[args])",
+			FileExtension:   ".go",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for param, want := range map[string]string{
+		"repo":            "github.com/sourcegraph/sourcegraph",
+		"commit":          "deadbeef",
+		"matchtemplate":   "fmt.Sprintf(:[args])",
+		"rewritetemplate": "fmt.Sprint(:[args])",
+		"fileextension":   ".go",
+	} {
+		if have := query[param]; len(have) != 1 || have[0] != want {
+			t.Errorf("param %q: have %q, want %q", param, have, want)
+		}
+	}
+
+	if have, want := len(diffs), 2; have != want {
+		t.Fatalf("have %d diffs, want %d", have, want)
+	}
+	if have, want := diffs[1].URI, "b.go"; have != want {
+		t.Fatalf("have URI %q, want %q", have, want)
+	}
+}
+
+func TestReplacerClient_error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "comby is not installed", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	cli := NewReplacerClient(srv.URL, http.DefaultClient)
+	_, err := cli.Replace(context.Background(), &replacerprotocol.Request{})
+	if have, want := fmt.Sprint(err), "replacer returned status 500: comby is not installed"; have != want {
+		t.Fatalf("have error %q, want %q", have, want)
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	have, err := unifiedDiff([]*ReplacerFileDiff{
+		{URI: "b/b.go", Diff: "--- b/b.go\n+++ b/b.go\n@@ -2 +2 @@\n-fmt.Sprintf(y)\n+fmt.Sprint(y)\n"},
+		{URI: "a.go", Diff: "--- a.go\n+++ a.go\n@@ -1 +1 @@\n-fmt.Sprintf(x)\n+fmt.Sprint(x)"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `diff a.go a.go
+--- a.go
++++ a.go
+@@ -1 +1 @@
+-fmt.Sprintf(x)
++fmt.Sprint(x)
+diff b/b.go b/b.go
+--- b/b.go
++++ b/b.go
+@@ -2 +2 @@
+-fmt.Sprintf(y)
++fmt.Sprint(y)`
+	if diff := cmp.Diff(want, have); diff != "" {
+		t.Fatal(diff)
+	}
+
+	if _, err := unifiedDiff([]*ReplacerFileDiff{{URI: "a.go", Diff: "garbage"}}); err == nil {
+		t.Fatal("expected error for diff without hunks")
+	}
+}
@@ -0,0 +1,214 @@
+package campaigns
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+)
+
+// SetCampaignPublishPolicy sets the PublishPolicy of the Campaign with the
+// given ID. A nil policy removes any limits on publishing its Changesets.
+func (s *Service) SetCampaignPublishPolicy(ctx context.Context, id int64, policy *campaigns.PublishPolicy) (campaign *campaigns.Campaign, err error) {
+	tr, ctx := trace.New(ctx, "service.SetCampaignPublishPolicy", fmt.Sprintf("campaign: %d", id))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	if policy != nil {
+		if err := policy.Validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.store.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Done(&err)
+
+	campaign, err = tx.GetCampaign(ctx, GetCampaignOpts{ID: id})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting campaign")
+	}
+
+	campaign.PublishPolicy = policy
+	return campaign, tx.UpdateCampaign(ctx, campaign)
+}
+
+// RunPublicationScheduler should be executed in a background goroutine and is
+// responsible for releasing the ChangesetJobs of Campaigns with a
+// PublishPolicy, so that they are executed by the workers started with
+// RunWorkers. Every interval it calls ReleaseChangesetJobs for each open
+// Campaign with a PublishPolicy.
+// ctx should be canceled to terminate the function.
+func RunPublicationScheduler(ctx context.Context, s *Store, clock func() time.Time, interval time.Duration) {
+	for {
+		if err := releaseChangesetJobs(ctx, s, clock); err != nil {
+			log15.Error("Releasing changeset jobs", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func releaseChangesetJobs(ctx context.Context, s *Store, clock func() time.Time) error {
+	cs, _, err := s.ListCampaigns(ctx, ListCampaignsOpts{
+		State:             campaigns.CampaignStateOpen,
+		OnlyPublishPolicy: true,
+		Limit:             -1,
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing campaigns")
+	}
+
+	for _, c := range cs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := releaseChangesetJobsWithLock(ctx, s, clock, c); err != nil {
+			log15.Error("ReleaseChangesetJobs", "campaignID", c.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+// releaseChangesetJobsWithLock runs ReleaseChangesetJobs in a transaction,
+// unless another scheduler is already releasing the jobs of the given
+// Campaign.
+func releaseChangesetJobsWithLock(ctx context.Context, s *Store, clock func() time.Time, c *campaigns.Campaign) (err error) {
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return errors.Wrap(err, "starting transaction")
+	}
+	defer tx.Done(&err)
+
+	locked, err := tx.TryAcquireAdvisoryLock(ctx, fmt.Sprintf("release-changeset-jobs-%d", c.ID))
+	if err != nil || !locked {
+		return err
+	}
+
+	return ReleaseChangesetJobs(ctx, clock, tx, c)
+}
+
+// ReleaseChangesetJobs releases as many of the unreleased ChangesetJobs of the
+// given Campaign as its PublishPolicy allows at the current time:
+//
+//   - None, if the current time is outside of the policy's window.
+//   - No more than MaxChangesetsPerHour within the last hour.
+//   - Only the first InitialBatchSize jobs, until the Changesets published by
+//     them have all been merged, closed or deleted.
+//
+// Jobs that were started without having been released, because the
+// Campaign had no PublishPolicy at the time, count as released.
+func ReleaseChangesetJobs(ctx context.Context, clock func() time.Time, store *Store, c *campaigns.Campaign) (err error) {
+	tr, ctx := trace.New(ctx, "service.ReleaseChangesetJobs", fmt.Sprintf("campaign: %d", c.ID))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	policy := c.PublishPolicy
+	if policy == nil {
+		return nil
+	}
+
+	now := clock()
+	if !policy.InWindow(now) {
+		return nil
+	}
+
+	jobs, _, err := store.ListChangesetJobs(ctx, ListChangesetJobsOpts{
+		CampaignID: c.ID,
+		Limit:      -1,
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing changeset jobs")
+	}
+
+	var held, released []*campaigns.ChangesetJob
+	var releasedLastHour int
+	for _, j := range jobs {
+		releasedAt := j.ReleasedAt
+		if releasedAt.IsZero() {
+			releasedAt = j.StartedAt
+		}
+		if releasedAt.IsZero() {
+			held = append(held, j)
+			continue
+		}
+		released = append(released, j)
+		if releasedAt.After(now.Add(-time.Hour)) {
+			releasedLastHour++
+		}
+	}
+
+	n := len(held)
+	if max := policy.MaxChangesetsPerHour; max > 0 && max-releasedLastHour < n {
+		n = max - releasedLastHour
+	}
+	if size := policy.InitialBatchSize; size > 0 {
+		if len(released) < size {
+			if size-len(released) < n {
+				n = size - len(released)
+			}
+		} else {
+			settled, err := changesetJobsSettled(ctx, store, released[:size])
+			if err != nil {
+				return err
+			}
+			if !settled {
+				n = 0
+			}
+		}
+	}
+
+	tr.LogFields(log.Int("held", len(held)), log.Int("releasing", n))
+
+	for i := 0; i < n; i++ {
+		held[i].ReleasedAt = now
+		if err := store.UpdateChangesetJob(ctx, held[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// changesetJobsSettled returns true if all of the given ChangesetJobs have
+// published a Changeset that is no longer open. A closed or deleted Changeset
+// counts as settled too, since it would otherwise hold back the rollout
+// forever.
+func changesetJobsSettled(ctx context.Context, store *Store, jobs []*campaigns.ChangesetJob) (bool, error) {
+	ids := make([]int64, 0, len(jobs))
+	for _, j := range jobs {
+		if j.ChangesetID == 0 {
+			return false, nil
+		}
+		ids = append(ids, j.ChangesetID)
+	}
+
+	cs, _, err := store.ListChangesets(ctx, ListChangesetsOpts{IDs: ids, Limit: -1})
+	if err != nil {
+		return false, errors.Wrap(err, "listing changesets")
+	}
+	if len(cs) != len(ids) {
+		return false, nil
+	}
+
+	for _, c := range cs {
+		if c.ExternalState == campaigns.ChangesetStateOpen {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,213 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+)
+
+func TestReleaseChangesetJobs(t *testing.T) {
+	ctx := context.Background()
+
+	// A Monday at noon.
+	now := time.Date(2020, 4, 6, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	dbtesting.SetupGlobalTestDB(t)
+
+	// publishedJob is a ChangesetJob that already published a Changeset.
+	type publishedJob struct {
+		releasedAgo time.Duration
+		state       cmpgn.ChangesetState
+	}
+
+	tests := []struct {
+		name      string
+		policy    cmpgn.PublishPolicy
+		published []publishedJob
+		jobs      int
+
+		wantReleased int
+	}{
+		{
+			name:         "NoLimits",
+			jobs:         4,
+			wantReleased: 4,
+		},
+		{
+			name:         "OutsideWindow",
+			policy:       cmpgn.PublishPolicy{WindowStart: "18:00", WindowEnd: "20:00"},
+			jobs:         4,
+			wantReleased: 0,
+		},
+		{
+			name:         "InsideWindow",
+			policy:       cmpgn.PublishPolicy{Days: []time.Weekday{time.Monday}, WindowStart: "09:00", WindowEnd: "17:00"},
+			jobs:         4,
+			wantReleased: 4,
+		},
+		{
+			name:         "OutsideWindowInTimeZone",
+			policy:       cmpgn.PublishPolicy{Days: []time.Weekday{time.Monday}, WindowStart: "09:00", WindowEnd: "17:00", TimeZone: "Asia/Tokyo"},
+			jobs:         4,
+			wantReleased: 0,
+		},
+		{
+			name:   "RateLimited",
+			policy: cmpgn.PublishPolicy{MaxChangesetsPerHour: 2},
+			published: []publishedJob{
+				{releasedAgo: 10 * time.Minute, state: cmpgn.ChangesetStateOpen},
+			},
+			jobs:         3,
+			wantReleased: 1,
+		},
+		{
+			name:   "RateLimitExhausted",
+			policy: cmpgn.PublishPolicy{MaxChangesetsPerHour: 1},
+			published: []publishedJob{
+				{releasedAgo: 10 * time.Minute, state: cmpgn.ChangesetStateOpen},
+			},
+			jobs:         3,
+			wantReleased: 0,
+		},
+		{
+			name:   "RateLimitIgnoresEarlierReleases",
+			policy: cmpgn.PublishPolicy{MaxChangesetsPerHour: 2},
+			published: []publishedJob{
+				{releasedAgo: 2 * time.Hour, state: cmpgn.ChangesetStateOpen},
+			},
+			jobs:         3,
+			wantReleased: 2,
+		},
+		{
+			name:         "InitialBatch",
+			policy:       cmpgn.PublishPolicy{InitialBatchSize: 2},
+			jobs:         4,
+			wantReleased: 2,
+		},
+		{
+			name:   "InitialBatchNotMerged",
+			policy: cmpgn.PublishPolicy{InitialBatchSize: 2},
+			published: []publishedJob{
+				{releasedAgo: 2 * time.Hour, state: cmpgn.ChangesetStateMerged},
+				{releasedAgo: 2 * time.Hour, state: cmpgn.ChangesetStateOpen},
+			},
+			jobs:         2,
+			wantReleased: 0,
+		},
+		{
+			name:   "InitialBatchMerged",
+			policy: cmpgn.PublishPolicy{InitialBatchSize: 2, MaxChangesetsPerHour: 10},
+			published: []publishedJob{
+				{releasedAgo: 2 * time.Hour, state: cmpgn.ChangesetStateMerged},
+				{releasedAgo: 2 * time.Hour, state: cmpgn.ChangesetStateMerged},
+			},
+			jobs:         2,
+			wantReleased: 2,
+		},
+		{
+			name:   "InitialBatchClosedOrDeleted",
+			policy: cmpgn.PublishPolicy{InitialBatchSize: 3, MaxChangesetsPerHour: 10},
+			published: []publishedJob{
+				{releasedAgo: 2 * time.Hour, state: cmpgn.ChangesetStateMerged},
+				{releasedAgo: 2 * time.Hour, state: cmpgn.ChangesetStateClosed},
+				{releasedAgo: 2 * time.Hour, state: cmpgn.ChangesetStateDeleted},
+			},
+			jobs:         2,
+			wantReleased: 2,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := dbtest.NewTx(t, dbconn.Global)
+			s := NewStoreWithClock(tx, clock)
+
+			total := len(tc.published) + tc.jobs
+
+			var rs []*repos.Repo
+			for i := 0; i < total; i++ {
+				rs = append(rs, testRepo(i, github.ServiceType))
+			}
+			reposStore := repos.NewDBStore(tx, sql.TxOptions{})
+			if err := reposStore.UpsertRepos(ctx, rs...); err != nil {
+				t.Fatal(err)
+			}
+
+			patchSet := &cmpgn.PatchSet{}
+			if err := s.CreatePatchSet(ctx, patchSet); err != nil {
+				t.Fatal(err)
+			}
+
+			policy := tc.policy
+			campaign := &cmpgn.Campaign{
+				Name:            "Remove dead code",
+				Description:     "This campaign removes dead code.",
+				Branch:          "dead-code-b-gone",
+				AuthorID:        888,
+				NamespaceUserID: 888,
+				PatchSetID:      patchSet.ID,
+				PublishPolicy:   &policy,
+			}
+			if err := s.CreateCampaign(ctx, campaign); err != nil {
+				t.Fatal(err)
+			}
+
+			for i, repo := range rs {
+				patch := testPatch(patchSet.ID, repo.ID, now)
+				if err := s.CreatePatch(ctx, patch); err != nil {
+					t.Fatal(err)
+				}
+
+				job := &cmpgn.ChangesetJob{CampaignID: campaign.ID, PatchID: patch.ID}
+				if i < len(tc.published) {
+					changeset := &cmpgn.Changeset{
+						RepoID:        repo.ID,
+						CampaignIDs:   []int64{campaign.ID},
+						ExternalID:    fmt.Sprint(i),
+						ExternalState: tc.published[i].state,
+					}
+					if err := s.CreateChangesets(ctx, changeset); err != nil {
+						t.Fatal(err)
+					}
+
+					job.ChangesetID = changeset.ID
+					job.ReleasedAt = now.Add(-tc.published[i].releasedAgo)
+					job.StartedAt = job.ReleasedAt
+					job.FinishedAt = job.ReleasedAt
+				}
+				if err := s.CreateChangesetJob(ctx, job); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := ReleaseChangesetJobs(ctx, clock, s, campaign); err != nil {
+				t.Fatal(err)
+			}
+
+			jobs, _, err := s.ListChangesetJobs(ctx, ListChangesetJobsOpts{CampaignID: campaign.ID, Limit: -1})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var released int
+			for _, j := range jobs {
+				if j.ReleasedAt.Equal(now) {
+					released++
+				}
+			}
+			if released != tc.wantReleased {
+				t.Fatalf("wrong number of released jobs. want=%d, have=%d", tc.wantReleased, released)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/inconshreveable/log15"
+	"github.com/opentracing/opentracing-go/log"
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// RunRebaseWorker should be executed in a background goroutine and is
+// responsible for keeping the branches of open Changesets up to date with
+// their base branch. Every interval it re-applies the Patch of each open
+// Changeset whose base branch moved onto the latest commit of that branch.
+// ctx should be canceled to terminate the function.
+func RunRebaseWorker(ctx context.Context, s *Store, clock func() time.Time, gitClient GitserverClient, interval time.Duration) {
+	for {
+		if err := rebaseOpenChangesets(ctx, s, clock, gitClient); err != nil {
+			log15.Error("Rebasing open changesets", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+func rebaseOpenChangesets(ctx context.Context, s *Store, clock func() time.Time, gitClient GitserverClient) error {
+	jobs, _, err := s.ListChangesetJobs(ctx, ListChangesetJobsOpts{
+		OnlyOpenChangesets: true,
+		Limit:              -1,
+	})
+	if err != nil {
+		return errors.Wrap(err, "listing changeset jobs")
+	}
+
+	for _, job := range jobs {
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err := rebaseChangesetJobWithLock(ctx, s, clock, gitClient, job); err != nil {
+			log15.Error("RebaseChangesetJob", "jobID", job.ID, "err", err)
+		}
+	}
+	return nil
+}
+
+// rebaseChangesetJobWithLock runs RebaseChangesetJob in a transaction, unless
+// another worker is already rebasing the given job.
+func rebaseChangesetJobWithLock(ctx context.Context, s *Store, clock func() time.Time, gitClient GitserverClient, job *campaigns.ChangesetJob) (err error) {
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return errors.Wrap(err, "starting transaction")
+	}
+	defer tx.Done(&err)
+
+	locked, err := tx.TryAcquireAdvisoryLock(ctx, fmt.Sprintf("rebase-changeset-job-%d", job.ID))
+	if err != nil || !locked {
+		return err
+	}
+
+	return RebaseChangesetJob(ctx, clock, tx, gitClient, job)
+}
+
+// RebaseChangesetJob re-applies the Patch of the given published ChangesetJob
+// onto the latest commit of the Patch's base ref and force-pushes the result
+// to the job's branch. It does nothing if the base ref didn't move since the
+// Patch was last applied, or since the last failed attempt.
+//
+// If the Patch no longer applies, the failure is recorded in the job's
+// RebaseError and no error is returned. On success, the Patch's Rev is set to
+// the new base commit.
+func RebaseChangesetJob(
+	ctx context.Context,
+	clock func() time.Time,
+	store *Store,
+	gitClient GitserverClient,
+	job *campaigns.ChangesetJob,
+) (err error) {
+	tr, ctx := trace.New(ctx, "service.RebaseChangesetJob", fmt.Sprintf("job_id: %d", job.ID))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+	tr.LogFields(log.Int64("job_id", job.ID), log.Int64("campaign_id", job.CampaignID))
+
+	if job.ChangesetID == 0 || job.Branch == "" {
+		return errors.Errorf("changeset job %d has not been published", job.ID)
+	}
+
+	patch, err := store.GetPatch(ctx, GetPatchOpts{ID: job.PatchID})
+	if err != nil {
+		return err
+	}
+
+	reposStore := repos.NewDBStore(store.DB(), sql.TxOptions{})
+	rs, err := reposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: []api.RepoID{patch.RepoID}})
+	if err != nil {
+		return err
+	}
+	if len(rs) != 1 {
+		return errors.Errorf("repo not found: %d", patch.RepoID)
+	}
+	repo := rs[0]
+
+	baseRef := "refs/heads/master"
+	if patch.BaseRef != "" {
+		baseRef = patch.BaseRef
+	}
+
+	base, err := git.ResolveRevision(ctx, gitserver.Repo{Name: api.RepoName(repo.Name)}, nil, baseRef, &git.ResolveRevisionOptions{
+		NoEnsureRevision: true,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "resolving base ref %q", baseRef)
+	}
+
+	if base == patch.Rev || base == job.RebaseRev {
+		return nil
+	}
+
+	c, err := store.GetCampaign(ctx, GetCampaignOpts{ID: job.CampaignID})
+	if err != nil {
+		return errors.Wrap(err, "getting campaign")
+	}
+
+	job.RebaseRev = base
+	job.RebasedAt = clock()
+
+	// The branch was created by ExecChangesetJob, so we don't want a unique
+	// ref here: gitserver force-pushes the new commit to the existing branch.
+	_, err = gitClient.CreateCommitFromPatch(ctx, createCommitFromPatchRequest(repo, c, patch, base, job.Branch, false, job.RebasedAt))
+	if err != nil {
+		if _, ok := err.(*protocol.CreateCommitFromPatchError); !ok {
+			return err
+		}
+		job.RebaseError = createCommitFromPatchError(err).Error()
+		return store.UpdateChangesetJob(ctx, job)
+	}
+
+	job.RebaseError = ""
+	patch.Rev = base
+	if err = store.UpdatePatch(ctx, patch); err != nil {
+		return err
+	}
+	return store.UpdateChangesetJob(ctx, job)
+}
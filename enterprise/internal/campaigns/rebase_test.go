@@ -0,0 +1,164 @@
+package campaigns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbconn"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtest"
+	"github.com/sourcegraph/sourcegraph/internal/db/dbtesting"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver/protocol"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestRebaseChangesetJob(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	clock := func() time.Time { return now.UTC().Truncate(time.Microsecond) }
+
+	dbtesting.SetupGlobalTestDB(t)
+
+	applyErr := &protocol.CreateCommitFromPatchError{
+		RepositoryName: "github.com/sourcegraph/sourcegraph",
+		Command:        "git apply",
+		CombinedOutput: "error: patch failed: foobar.c:1",
+	}
+
+	tests := []struct {
+		name string
+
+		latestBase api.CommitID
+		rebaseRev  api.CommitID
+		pushErr    error
+
+		wantPushed      bool
+		wantPatchRev    api.CommitID
+		wantRebaseRev   api.CommitID
+		wantRebaseError bool
+	}{
+		{
+			name:         "BaseUnchanged",
+			latestBase:   "f00b4r",
+			wantPatchRev: "f00b4r",
+		},
+		{
+			name:          "BaseMoved",
+			latestBase:    "b4rb4z",
+			wantPushed:    true,
+			wantPatchRev:  "b4rb4z",
+			wantRebaseRev: "b4rb4z",
+		},
+		{
+			name:            "PatchNoLongerApplies",
+			latestBase:      "b4rb4z",
+			pushErr:         applyErr,
+			wantPushed:      true,
+			wantPatchRev:    "f00b4r",
+			wantRebaseRev:   "b4rb4z",
+			wantRebaseError: true,
+		},
+		{
+			name:          "AlreadyAttempted",
+			latestBase:    "b4rb4z",
+			rebaseRev:     "b4rb4z",
+			wantPatchRev:  "f00b4r",
+			wantRebaseRev: "b4rb4z",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tx := dbtest.NewTx(t, dbconn.Global)
+			s := NewStoreWithClock(tx, clock)
+
+			repo, _ := createGitHubRepo(t, ctx, now, s)
+			campaign, patch := createCampaignPatch(t, ctx, now, s, repo)
+
+			changeset := &cmpgn.Changeset{
+				RepoID:        repo.ID,
+				CampaignIDs:   []int64{campaign.ID},
+				ExternalID:    "1",
+				ExternalState: cmpgn.ChangesetStateOpen,
+			}
+			if err := s.CreateChangesets(ctx, changeset); err != nil {
+				t.Fatal(err)
+			}
+
+			job := &cmpgn.ChangesetJob{
+				CampaignID:  campaign.ID,
+				PatchID:     patch.ID,
+				ChangesetID: changeset.ID,
+				Branch:      campaign.Branch,
+				RebaseRev:   tc.rebaseRev,
+				StartedAt:   now,
+				FinishedAt:  now,
+			}
+			if err := s.CreateChangesetJob(ctx, job); err != nil {
+				t.Fatal(err)
+			}
+
+			git.Mocks.ResolveRevision = func(spec string, opt *git.ResolveRevisionOptions) (api.CommitID, error) {
+				if spec != patch.BaseRef {
+					t.Fatalf("resolved wrong ref. want=%q, have=%q", patch.BaseRef, spec)
+				}
+				return tc.latestBase, nil
+			}
+			defer func() { git.Mocks.ResolveRevision = nil }()
+
+			gitClient := &recordingGitserverClient{err: tc.pushErr}
+
+			if err := RebaseChangesetJob(ctx, clock, s, gitClient, job); err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := len(gitClient.reqs) == 1, tc.wantPushed; have != want {
+				t.Fatalf("pushed: want=%t, have=%t", want, have)
+			}
+			if tc.wantPushed {
+				req := gitClient.reqs[0]
+				if req.BaseCommit != tc.latestBase {
+					t.Errorf("wrong base commit. want=%q, have=%q", tc.latestBase, req.BaseCommit)
+				}
+				if req.TargetRef != campaign.Branch || req.UniqueRef || !req.Push {
+					t.Errorf("wrong target. want force-push to %q, have %+v", campaign.Branch, req)
+				}
+			}
+
+			job, err := s.GetChangesetJob(ctx, GetChangesetJobOpts{ID: job.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if job.RebaseRev != tc.wantRebaseRev {
+				t.Errorf("wrong rebase rev. want=%q, have=%q", tc.wantRebaseRev, job.RebaseRev)
+			}
+			if have, want := job.RebaseError != "", tc.wantRebaseError; have != want {
+				t.Errorf("rebase error: want=%t, have=%q", want, job.RebaseError)
+			}
+
+			patch, err = s.GetPatch(ctx, GetPatchOpts{ID: patch.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if patch.Rev != tc.wantPatchRev {
+				t.Errorf("wrong patch rev. want=%q, have=%q", tc.wantPatchRev, patch.Rev)
+			}
+		})
+	}
+}
+
+type recordingGitserverClient struct {
+	reqs []protocol.CreateCommitFromPatchRequest
+	err  error
+}
+
+func (c *recordingGitserverClient) CreateCommitFromPatch(ctx context.Context, req protocol.CreateCommitFromPatchRequest) (string, error) {
+	c.reqs = append(c.reqs, req)
+	if c.err != nil {
+		return "", c.err
+	}
+	return req.TargetRef, nil
+}
@@ -0,0 +1,109 @@
+package resolvers
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	ee "github.com/sourcegraph/sourcegraph/enterprise/internal/campaigns"
+)
+
+type campaignAnalyticsResolver struct {
+	analytics *ee.CampaignAnalytics
+}
+
+func (r *campaignAnalyticsResolver) TimeToMerge() graphqlbackend.DurationDistributionResolver {
+	return &durationDistributionResolver{dist: r.analytics.TimeToMerge}
+}
+
+func (r *campaignAnalyticsResolver) ReviewLatency() graphqlbackend.DurationDistributionResolver {
+	return &durationDistributionResolver{dist: r.analytics.ReviewLatency}
+}
+
+func (r *campaignAnalyticsResolver) RepositoryOwners() []graphqlbackend.RepositoryOwnerChangesetCountsResolver {
+	resolvers := make([]graphqlbackend.RepositoryOwnerChangesetCountsResolver, 0, len(r.analytics.RepoOwners))
+	for _, c := range r.analytics.RepoOwners {
+		resolvers = append(resolvers, &repositoryOwnerChangesetCountsResolver{counts: c})
+	}
+	return resolvers
+}
+
+func (r *campaignAnalyticsResolver) CheckFailures() []graphqlbackend.ChangesetCheckFailureResolver {
+	resolvers := make([]graphqlbackend.ChangesetCheckFailureResolver, 0, len(r.analytics.CheckFailures))
+	for _, f := range r.analytics.CheckFailures {
+		resolvers = append(resolvers, &changesetCheckFailureResolver{failure: f})
+	}
+	return resolvers
+}
+
+func (r *campaignAnalyticsResolver) CSV() (string, error) {
+	var buf bytes.Buffer
+	if err := ee.WriteAnalyticsCSV(&buf, r.analytics); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+type durationDistributionResolver struct {
+	dist *ee.DurationDistribution
+}
+
+func (r *durationDistributionResolver) Count() int32 { return r.dist.Count }
+
+func (r *durationDistributionResolver) MedianHours() *float64 {
+	if r.dist.Count == 0 {
+		return nil
+	}
+	return hours(r.dist.Median)
+}
+
+func (r *durationDistributionResolver) P90Hours() *float64 {
+	if r.dist.Count == 0 {
+		return nil
+	}
+	return hours(r.dist.P90)
+}
+
+func (r *durationDistributionResolver) Buckets() []graphqlbackend.DurationBucketResolver {
+	resolvers := make([]graphqlbackend.DurationBucketResolver, 0, len(r.dist.Buckets))
+	for _, b := range r.dist.Buckets {
+		resolvers = append(resolvers, &durationBucketResolver{bucket: b})
+	}
+	return resolvers
+}
+
+type durationBucketResolver struct {
+	bucket *ee.DurationBucket
+}
+
+func (r *durationBucketResolver) MaxHours() *int32 {
+	if r.bucket.Max == 0 {
+		return nil
+	}
+	h := int32(r.bucket.Max / time.Hour)
+	return &h
+}
+
+func (r *durationBucketResolver) Count() int32 { return r.bucket.Count }
+
+type repositoryOwnerChangesetCountsResolver struct {
+	counts *ee.RepoOwnerCounts
+}
+
+func (r *repositoryOwnerChangesetCountsResolver) Owner() string { return r.counts.Owner }
+func (r *repositoryOwnerChangesetCountsResolver) Total() int32  { return r.counts.Total }
+func (r *repositoryOwnerChangesetCountsResolver) Open() int32   { return r.counts.Open }
+func (r *repositoryOwnerChangesetCountsResolver) Merged() int32 { return r.counts.Merged }
+func (r *repositoryOwnerChangesetCountsResolver) Closed() int32 { return r.counts.Closed }
+
+type changesetCheckFailureResolver struct {
+	failure *ee.CheckFailureCount
+}
+
+func (r *changesetCheckFailureResolver) Name() string          { return r.failure.Name }
+func (r *changesetCheckFailureResolver) ChangesetCount() int32 { return r.failure.Changesets }
+
+func hours(d time.Duration) *float64 {
+	h := d.Hours()
+	return &h
+}
@@ -2,7 +2,9 @@ package resolvers
 
 import (
 	"context"
+	"database/sql"
 	"path"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,7 +13,9 @@ import (
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	ee "github.com/sourcegraph/sourcegraph/enterprise/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 )
 
@@ -139,6 +143,40 @@ func (r *campaignResolver) ClosedAt() *graphqlbackend.DateTime {
 	return &graphqlbackend.DateTime{Time: r.Campaign.ClosedAt}
 }
 
+func (r *campaignResolver) AutoMerge() graphqlbackend.CampaignAutoMergeResolver {
+	if r.Campaign.AutoMerge == nil {
+		return nil
+	}
+	return &campaignAutoMergeResolver{policy: r.Campaign.AutoMerge}
+}
+
+func (r *campaignResolver) PublishPolicy() graphqlbackend.CampaignPublishPolicyResolver {
+	if r.Campaign.PublishPolicy == nil {
+		return nil
+	}
+	return &campaignPublishPolicyResolver{policy: r.Campaign.PublishPolicy}
+}
+
+func (r *campaignResolver) ReviewerPolicy() graphqlbackend.CampaignReviewerPolicyResolver {
+	if r.Campaign.ReviewerPolicy == nil {
+		return nil
+	}
+	return &campaignReviewerPolicyResolver{policy: r.Campaign.ReviewerPolicy}
+}
+
+func (r *campaignResolver) ChangesetActions(
+	ctx context.Context,
+	args *graphqlutil.ConnectionArgs,
+) graphqlbackend.ChangesetActionsConnectionResolver {
+	return &changesetActionsConnectionResolver{
+		store: r.store,
+		opts: ee.ListChangesetActionsOpts{
+			CampaignID: r.Campaign.ID,
+			Limit:      int(args.GetFirst()),
+		},
+	}
+}
+
 func (r *campaignResolver) PublishedAt(ctx context.Context) (*graphqlbackend.DateTime, error) {
 	if r.Campaign.PatchSetID == 0 {
 		return &graphqlbackend.DateTime{Time: r.Campaign.CreatedAt}, nil
@@ -246,6 +284,64 @@ func (r *campaignResolver) ChangesetCountsOverTime(
 	return resolvers, nil
 }
 
+func (r *campaignResolver) Analytics(ctx context.Context) (graphqlbackend.CampaignAnalyticsResolver, error) {
+	// 🚨 SECURITY: Only site admins or users when read-access is enabled may access changesets.
+	if err := allowReadAccess(ctx); err != nil {
+		return nil, err
+	}
+
+	cs, _, err := r.store.ListChangesets(ctx, ee.ListChangesetsOpts{
+		CampaignID: r.Campaign.ID,
+		Limit:      -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changesetIDs := make([]int64, len(cs))
+	repoIDs := make([]api.RepoID, 0, len(cs))
+	seen := make(map[api.RepoID]bool, len(cs))
+	for i, c := range cs {
+		changesetIDs[i] = c.ID
+		if !seen[c.RepoID] {
+			seen[c.RepoID] = true
+			repoIDs = append(repoIDs, c.RepoID)
+		}
+	}
+
+	repoNames := make(map[api.RepoID]string, len(repoIDs))
+	if len(repoIDs) > 0 {
+		reposStore := repos.NewDBStore(r.store.DB(), sql.TxOptions{})
+		rs, err := reposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: repoIDs})
+		if err != nil {
+			return nil, err
+		}
+		for _, repo := range rs {
+			repoNames[repo.ID] = repo.Name
+		}
+	}
+
+	es, _, err := r.store.ListChangesetEvents(ctx, ee.ListChangesetEventsOpts{
+		ChangesetIDs: changesetIDs,
+		Limit:        -1,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]ee.Event, len(es))
+	for i, e := range es {
+		events[i] = e
+	}
+
+	analytics, err := ee.CalcAnalytics(cs, repoNames, events...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &campaignAnalyticsResolver{analytics: analytics}, nil
+}
+
 func (r *campaignResolver) PatchSet(ctx context.Context) (graphqlbackend.PatchSetResolver, error) {
 	if r.Campaign.PatchSetID == 0 {
 		return nil, nil
@@ -312,3 +408,111 @@ func (r *emptyPatchConnectionResolver) TotalCount(ctx context.Context) (int32, e
 func (r *emptyPatchConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
 	return graphqlutil.HasNextPage(false), nil
 }
+
+type campaignAutoMergeResolver struct {
+	policy *campaigns.AutoMergePolicy
+}
+
+func (r *campaignAutoMergeResolver) Method() campaigns.ChangesetMergeMethod {
+	return r.policy.Method
+}
+
+func (r *campaignAutoMergeResolver) Days() []string {
+	return weekdayNames(r.policy.Days)
+}
+
+func (r *campaignAutoMergeResolver) WindowStart() *string {
+	if r.policy.WindowStart == "" {
+		return nil
+	}
+	return &r.policy.WindowStart
+}
+
+func (r *campaignAutoMergeResolver) WindowEnd() *string {
+	if r.policy.WindowEnd == "" {
+		return nil
+	}
+	return &r.policy.WindowEnd
+}
+
+func (r *campaignAutoMergeResolver) TimeZone() string {
+	if r.policy.TimeZone == "" {
+		return "UTC"
+	}
+	return r.policy.TimeZone
+}
+
+func (r *campaignAutoMergeResolver) MaxMergesPerRepository() int32 {
+	return int32(r.policy.MergesPerRepo())
+}
+
+type campaignPublishPolicyResolver struct {
+	policy *campaigns.PublishPolicy
+}
+
+func (r *campaignPublishPolicyResolver) MaxChangesetsPerHour() *int32 {
+	return nullInt32(r.policy.MaxChangesetsPerHour)
+}
+
+func (r *campaignPublishPolicyResolver) Days() []string {
+	return weekdayNames(r.policy.Days)
+}
+
+func (r *campaignPublishPolicyResolver) WindowStart() *string {
+	if r.policy.WindowStart == "" {
+		return nil
+	}
+	return &r.policy.WindowStart
+}
+
+func (r *campaignPublishPolicyResolver) WindowEnd() *string {
+	if r.policy.WindowEnd == "" {
+		return nil
+	}
+	return &r.policy.WindowEnd
+}
+
+func (r *campaignPublishPolicyResolver) TimeZone() string {
+	if r.policy.TimeZone == "" {
+		return "UTC"
+	}
+	return r.policy.TimeZone
+}
+
+func (r *campaignPublishPolicyResolver) InitialBatchSize() *int32 {
+	return nullInt32(r.policy.InitialBatchSize)
+}
+
+type campaignReviewerPolicyResolver struct {
+	policy *campaigns.ReviewerPolicy
+}
+
+func (r *campaignReviewerPolicyResolver) IgnoreCodeOwners() bool {
+	return r.policy.IgnoreCodeOwners
+}
+
+func (r *campaignReviewerPolicyResolver) Reviewers() []string {
+	return append([]string{}, r.policy.Reviewers...)
+}
+
+func (r *campaignReviewerPolicyResolver) Assignees() []string {
+	return append([]string{}, r.policy.Assignees...)
+}
+
+// weekdayNames returns the names of the given days as Weekday enum values of
+// the GraphQL API.
+func weekdayNames(ds []time.Weekday) []string {
+	days := make([]string, 0, len(ds))
+	for _, d := range ds {
+		days = append(days, strings.ToUpper(d.String()))
+	}
+	return days
+}
+
+func nullInt32(n int) *int32 {
+	if n == 0 {
+		return nil
+	}
+	i := int32(n)
+	return &i
+}
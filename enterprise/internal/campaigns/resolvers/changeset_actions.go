@@ -0,0 +1,259 @@
+package resolvers
+
+import (
+	"context"
+	"sync"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend/graphqlutil"
+	ee "github.com/sourcegraph/sourcegraph/enterprise/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+)
+
+const changesetActionIDKind = "ChangesetAction"
+
+func marshalChangesetActionID(id int64) graphql.ID {
+	return relay.MarshalID(changesetActionIDKind, id)
+}
+
+func unmarshalChangesetActionID(id graphql.ID) (actionID int64, err error) {
+	err = relay.UnmarshalSpec(id, &actionID)
+	return
+}
+
+var _ graphqlbackend.ChangesetActionResolver = &changesetActionResolver{}
+
+type changesetActionResolver struct {
+	store *ee.Store
+	*campaigns.ChangesetAction
+}
+
+func (r *changesetActionResolver) ID() graphql.ID {
+	return marshalChangesetActionID(r.ChangesetAction.ID)
+}
+
+func (r *changesetActionResolver) Campaign(ctx context.Context) (graphqlbackend.CampaignResolver, error) {
+	campaign, err := r.store.GetCampaign(ctx, ee.GetCampaignOpts{ID: r.CampaignID})
+	if err != nil {
+		return nil, err
+	}
+	return &campaignResolver{store: r.store, Campaign: campaign}, nil
+}
+
+func (r *changesetActionResolver) Author(ctx context.Context) (*graphqlbackend.UserResolver, error) {
+	return graphqlbackend.UserByIDInt32(ctx, r.AuthorID)
+}
+
+func (r *changesetActionResolver) Kind() campaigns.ChangesetActionKind {
+	return r.ChangesetAction.Kind
+}
+
+func (r *changesetActionResolver) Comment() *string {
+	if r.ChangesetAction.Comment == "" {
+		return nil
+	}
+	return &r.ChangesetAction.Comment
+}
+
+func (r *changesetActionResolver) Labels() []string {
+	if r.ChangesetAction.Labels == nil {
+		return []string{}
+	}
+	return r.ChangesetAction.Labels
+}
+
+func (r *changesetActionResolver) State() *campaigns.ChangesetState {
+	if r.ExternalState == "" {
+		return nil
+	}
+	return &r.ExternalState
+}
+
+func (r *changesetActionResolver) ReviewState() *campaigns.ChangesetReviewState {
+	if r.ExternalReviewState == "" {
+		return nil
+	}
+	return &r.ExternalReviewState
+}
+
+func (r *changesetActionResolver) CheckState() *campaigns.ChangesetCheckState {
+	if r.ExternalCheckState == "" {
+		return nil
+	}
+	return &r.ExternalCheckState
+}
+
+func (r *changesetActionResolver) CreatedAt() graphqlbackend.DateTime {
+	return graphqlbackend.DateTime{Time: r.ChangesetAction.CreatedAt}
+}
+
+func (r *changesetActionResolver) Status(ctx context.Context) (graphqlbackend.BackgroundProcessStatus, error) {
+	return r.store.GetChangesetActionStatus(ctx, r.ChangesetAction.ID)
+}
+
+func (r *changesetActionResolver) Results(
+	ctx context.Context,
+	args *graphqlutil.ConnectionArgs,
+) graphqlbackend.ChangesetActionResultsConnectionResolver {
+	return &changesetActionResultsConnectionResolver{
+		store: r.store,
+		opts: ee.ListChangesetActionJobsOpts{
+			ActionID: r.ChangesetAction.ID,
+			Limit:    int(args.GetFirst()),
+		},
+	}
+}
+
+type changesetActionsConnectionResolver struct {
+	store *ee.Store
+	opts  ee.ListChangesetActionsOpts
+
+	// cache results because they are used by multiple fields
+	once    sync.Once
+	actions []*campaigns.ChangesetAction
+	next    int64
+	err     error
+}
+
+func (r *changesetActionsConnectionResolver) Nodes(ctx context.Context) ([]graphqlbackend.ChangesetActionResolver, error) {
+	actions, _, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]graphqlbackend.ChangesetActionResolver, 0, len(actions))
+	for _, a := range actions {
+		resolvers = append(resolvers, &changesetActionResolver{store: r.store, ChangesetAction: a})
+	}
+	return resolvers, nil
+}
+
+func (r *changesetActionsConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	opts := ee.CountChangesetActionsOpts{CampaignID: r.opts.CampaignID}
+	count, err := r.store.CountChangesetActions(ctx, opts)
+	return int32(count), err
+}
+
+func (r *changesetActionsConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	_, next, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(next != 0), nil
+}
+
+func (r *changesetActionsConnectionResolver) compute(ctx context.Context) ([]*campaigns.ChangesetAction, int64, error) {
+	r.once.Do(func() {
+		r.actions, r.next, r.err = r.store.ListChangesetActions(ctx, r.opts)
+	})
+	return r.actions, r.next, r.err
+}
+
+type changesetActionResultsConnectionResolver struct {
+	store *ee.Store
+	opts  ee.ListChangesetActionJobsOpts
+
+	// cache results because they are used by multiple fields
+	once           sync.Once
+	jobs           []*campaigns.ChangesetActionJob
+	changesetsByID map[int64]*campaigns.Changeset
+	next           int64
+	err            error
+}
+
+func (r *changesetActionResultsConnectionResolver) Nodes(ctx context.Context) ([]graphqlbackend.ChangesetActionResultResolver, error) {
+	jobs, changesetsByID, _, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resolvers := make([]graphqlbackend.ChangesetActionResultResolver, 0, len(jobs))
+	for _, j := range jobs {
+		resolvers = append(resolvers, &changesetActionResultResolver{
+			store:     r.store,
+			job:       j,
+			changeset: changesetsByID[j.ChangesetID],
+		})
+	}
+	return resolvers, nil
+}
+
+func (r *changesetActionResultsConnectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	status, err := r.store.GetChangesetActionStatus(ctx, r.opts.ActionID)
+	if err != nil {
+		return 0, err
+	}
+	return status.Total, nil
+}
+
+func (r *changesetActionResultsConnectionResolver) PageInfo(ctx context.Context) (*graphqlutil.PageInfo, error) {
+	_, _, next, err := r.compute(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return graphqlutil.HasNextPage(next != 0), nil
+}
+
+func (r *changesetActionResultsConnectionResolver) compute(ctx context.Context) ([]*campaigns.ChangesetActionJob, map[int64]*campaigns.Changeset, int64, error) {
+	r.once.Do(func() {
+		r.jobs, r.next, r.err = r.store.ListChangesetActionJobs(ctx, r.opts)
+		if r.err != nil || len(r.jobs) == 0 {
+			return
+		}
+
+		ids := make([]int64, len(r.jobs))
+		for i, j := range r.jobs {
+			ids[i] = j.ChangesetID
+		}
+
+		cs, _, err := r.store.ListChangesets(ctx, ee.ListChangesetsOpts{IDs: ids, Limit: -1})
+		if err != nil {
+			r.err = err
+			return
+		}
+
+		r.changesetsByID = make(map[int64]*campaigns.Changeset, len(cs))
+		for _, c := range cs {
+			r.changesetsByID[c.ID] = c
+		}
+	})
+	return r.jobs, r.changesetsByID, r.next, r.err
+}
+
+type changesetActionResultResolver struct {
+	store     *ee.Store
+	job       *campaigns.ChangesetActionJob
+	changeset *campaigns.Changeset
+}
+
+func (r *changesetActionResultResolver) Changeset(ctx context.Context) (graphqlbackend.ExternalChangesetResolver, error) {
+	if r.changeset == nil {
+		c, err := r.store.GetChangeset(ctx, ee.GetChangesetOpts{ID: r.job.ChangesetID})
+		if err != nil {
+			return nil, err
+		}
+		r.changeset = c
+	}
+	return &changesetResolver{store: r.store, Changeset: r.changeset}, nil
+}
+
+func (r *changesetActionResultResolver) Error() *string {
+	if r.job.Error == "" {
+		return nil
+	}
+	return &r.job.Error
+}
+
+func (r *changesetActionResultResolver) StartedAt() *graphqlbackend.DateTime {
+	if r.job.StartedAt.IsZero() {
+		return nil
+	}
+	return &graphqlbackend.DateTime{Time: r.job.StartedAt}
+}
+
+func (r *changesetActionResultResolver) FinishedAt() *graphqlbackend.DateTime {
+	if r.job.FinishedAt.IsZero() {
+		return nil
+	}
+	return &graphqlbackend.DateTime{Time: r.job.FinishedAt}
+}
@@ -230,6 +230,20 @@ func (r *changesetResolver) CheckState(ctx context.Context) (*campaigns.Changese
 	return &state, nil
 }
 
+func (r *changesetResolver) RebaseError(ctx context.Context) (*string, error) {
+	job, err := r.store.GetChangesetJob(ctx, ee.GetChangesetJobOpts{ChangesetID: r.Changeset.ID})
+	if err == ee.ErrNoResults {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if job.RebaseError == "" {
+		return nil, nil
+	}
+	return &job.RebaseError, nil
+}
+
 func (r *changesetResolver) Labels(ctx context.Context) ([]graphqlbackend.ChangesetLabelResolver, error) {
 	// Only GitHub supports labels on pull requests so don't make a DB call unless we need to
 	if _, ok := r.Changeset.Metadata.(*github.PullRequest); !ok {
@@ -6,23 +6,27 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/graph-gophers/graphql-go"
 	"github.com/graph-gophers/graphql-go/relay"
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/sourcegraph/cmd/frontend/authz"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/backend"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/db"
 	"github.com/sourcegraph/sourcegraph/cmd/frontend/graphqlbackend"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
 	ee "github.com/sourcegraph/sourcegraph/enterprise/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/actor"
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/gitserver"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/repoupdater"
+	"github.com/sourcegraph/sourcegraph/internal/search/query"
 	"github.com/sourcegraph/sourcegraph/internal/trace"
 )
 
@@ -38,6 +42,12 @@ func NewResolver(db *sql.DB) graphqlbackend.CampaignsResolver {
 }
 
 func allowReadAccess(ctx context.Context) error {
+	// 🚨 SECURITY: Access tokens with restricted scopes need the campaigns:write scope.
+	ctx, err := backend.WithAccessTokenScope(ctx, authz.ScopeCampaignsWrite)
+	if err != nil {
+		return err
+	}
+
 	if readAccess := conf.CampaignsReadAccessEnabled(); readAccess {
 		return nil
 	}
@@ -49,6 +59,16 @@ func allowReadAccess(ctx context.Context) error {
 	return nil
 }
 
+// checkWriteAccess returns an error if the current user is not a site admin, or if the actor was
+// authenticated with an access token that doesn't have the campaigns:write scope.
+func checkWriteAccess(ctx context.Context) error {
+	ctx, err := backend.WithAccessTokenScope(ctx, authz.ScopeCampaignsWrite)
+	if err != nil {
+		return err
+	}
+	return backend.CheckCurrentUserIsSiteAdmin(ctx)
+}
+
 func (r *Resolver) ChangesetByID(ctx context.Context, id graphql.ID) (graphqlbackend.ExternalChangesetResolver, error) {
 	// 🚨 SECURITY: Only site admins or users when read-access is enabled may access changesets.
 	if err := allowReadAccess(ctx); err != nil {
@@ -137,9 +157,31 @@ func (r *Resolver) PatchSetByID(ctx context.Context, id graphql.ID) (graphqlback
 	return &patchSetResolver{store: r.store, patchSet: patchSet}, nil
 }
 
+func (r *Resolver) ChangesetActionByID(ctx context.Context, id graphql.ID) (graphqlbackend.ChangesetActionResolver, error) {
+	// 🚨 SECURITY: Only site admins or users when read-access is enabled may access changeset actions.
+	if err := allowReadAccess(ctx); err != nil {
+		return nil, err
+	}
+
+	actionID, err := unmarshalChangesetActionID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := r.store.GetChangesetAction(ctx, ee.GetChangesetActionOpts{ID: actionID})
+	if err != nil {
+		if err == ee.ErrNoResults {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &changesetActionResolver{store: r.store, ChangesetAction: action}, nil
+}
+
 func (r *Resolver) AddChangesetsToCampaign(ctx context.Context, args *graphqlbackend.AddChangesetsToCampaignArgs) (_ graphqlbackend.CampaignResolver, err error) {
 	// 🚨 SECURITY: Only site admins may modify changesets and campaigns for now.
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, err
 	}
 
@@ -273,7 +315,7 @@ func (r *Resolver) UpdateCampaign(ctx context.Context, args *graphqlbackend.Upda
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, err
 	}
 
@@ -322,7 +364,7 @@ func (r *Resolver) DeleteCampaign(ctx context.Context, args *graphqlbackend.Dele
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, err
 	}
 
@@ -345,7 +387,7 @@ func (r *Resolver) RetryCampaign(ctx context.Context, args *graphqlbackend.Retry
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
@@ -391,7 +433,7 @@ func (r *Resolver) Campaigns(ctx context.Context, args *graphqlbackend.ListCampa
 
 func (r *Resolver) CreateChangesets(ctx context.Context, args *graphqlbackend.CreateChangesetsArgs) (_ []graphqlbackend.ExternalChangesetResolver, err error) {
 	// 🚨 SECURITY: Only site admins may create changesets for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, err
 	}
 
@@ -539,7 +581,7 @@ func (r *Resolver) CreatePatchSetFromPatches(ctx context.Context, args graphqlba
 	}()
 
 	// 🚨 SECURITY: Only site admins may create patch sets for now.
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, err
 	}
 
@@ -587,6 +629,108 @@ func (r *Resolver) CreatePatchSetFromPatches(ctx context.Context, args graphqlba
 	return &patchSetResolver{store: r.store, patchSet: patchSet}, nil
 }
 
+// maxSearchAndReplaceResults is the number of search results fetched to find
+// the repositories of a search-and-replace patch set, unless the query
+// specifies a count itself.
+const maxSearchAndReplaceResults = 5000
+
+func (r *Resolver) CreatePatchSetFromSearchAndReplace(ctx context.Context, args *graphqlbackend.CreatePatchSetFromSearchAndReplaceArgs) (_ graphqlbackend.PatchSetResolver, err error) {
+	tr, ctx := trace.New(ctx, "Resolver.CreatePatchSetFromSearchAndReplace", fmt.Sprintf("Query: %q", args.Query))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	// 🚨 SECURITY: Only site admins may create patch sets for now.
+	if err := checkWriteAccess(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := backend.CurrentUser(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "%v", backend.ErrNotAuthenticated)
+	}
+	if user == nil {
+		return nil, backend.ErrNotAuthenticated
+	}
+
+	spec, err := graphqlbackend.CodemodRewriteSpecification(args.Query, args.RewriteTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	q := args.Query
+	if info, err := query.Process(q, query.SearchTypeStructural); err == nil && len(info.Values(query.FieldCount)) == 0 {
+		q += fmt.Sprintf(" count:%d", maxSearchAndReplaceResults)
+	}
+
+	patternType := "structural"
+	search, err := graphqlbackend.NewSearchImplementer(&graphqlbackend.SearchArgs{
+		Version:     "V2",
+		PatternType: &patternType,
+		Query:       q,
+	})
+	if err != nil {
+		return nil, err
+	}
+	results, err := search.Results(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []*campaigns.PatchJob
+	seen := make(map[api.RepoID]bool)
+	for _, res := range results.Results() {
+		fm, ok := res.ToFileMatch()
+		if !ok {
+			continue
+		}
+
+		repo := fm.Repository()
+		repoID, err := graphqlbackend.UnmarshalRepositoryID(repo.ID())
+		if err != nil {
+			return nil, err
+		}
+		if seen[repoID] {
+			continue
+		}
+		seen[repoID] = true
+
+		defaultBranch, err := repo.DefaultBranch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if defaultBranch == nil {
+			return nil, errors.Errorf("repository %q has no default branch", repo.Name())
+		}
+
+		jobs = append(jobs, &campaigns.PatchJob{
+			RepoID:           repoID,
+			Rev:              api.CommitID(fm.File().Commit().OID()),
+			BaseRef:          defaultBranch.Name(),
+			MatchTemplate:    spec.MatchTemplate,
+			RewriteTemplate:  spec.RewriteTemplate,
+			FileExtension:    spec.FileExtension,
+			DirectoryExclude: spec.DirectoryExclude,
+		})
+	}
+
+	if len(jobs) == 0 {
+		if alert := results.Alert(); alert != nil {
+			return nil, errors.Errorf("no repositories matched the query: %s", alert.Title())
+		}
+		return nil, errors.New("no repositories matched the query")
+	}
+
+	svc := ee.NewService(r.store, gitserver.DefaultClient, r.httpFactory)
+	patchSet, err := svc.CreatePatchSetFromPatchJobs(ctx, jobs, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &patchSetResolver{store: r.store, patchSet: patchSet}, nil
+}
+
 func (r *Resolver) CloseCampaign(ctx context.Context, args *graphqlbackend.CloseCampaignArgs) (_ graphqlbackend.CampaignResolver, err error) {
 	tr, ctx := trace.New(ctx, "Resolver.CloseCampaign", fmt.Sprintf("Campaign: %q", args.Campaign))
 	defer func() {
@@ -595,7 +739,7 @@ func (r *Resolver) CloseCampaign(ctx context.Context, args *graphqlbackend.Close
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
@@ -622,7 +766,7 @@ func (r *Resolver) PublishCampaign(ctx context.Context, args *graphqlbackend.Pub
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
@@ -640,6 +784,223 @@ func (r *Resolver) PublishCampaign(ctx context.Context, args *graphqlbackend.Pub
 	return &campaignResolver{store: r.store, Campaign: campaign}, nil
 }
 
+func (r *Resolver) SetCampaignAutoMerge(ctx context.Context, args *graphqlbackend.SetCampaignAutoMergeArgs) (_ graphqlbackend.CampaignResolver, err error) {
+	tr, ctx := trace.New(ctx, "Resolver.SetCampaignAutoMerge", fmt.Sprintf("Campaign: %q", args.Campaign))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	// 🚨 SECURITY: Only site admins may update campaigns for now
+	if err := checkWriteAccess(ctx); err != nil {
+		return nil, errors.Wrap(err, "checking if user is admin")
+	}
+
+	campaignID, err := unmarshalCampaignID(args.Campaign)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling campaign id")
+	}
+
+	var policy *campaigns.AutoMergePolicy
+	if in := args.AutoMerge; in != nil {
+		policy = &campaigns.AutoMergePolicy{Method: in.Method}
+		if policy.Days, err = parseWeekdays(in.Days); err != nil {
+			return nil, err
+		}
+		if in.WindowStart != nil {
+			policy.WindowStart = *in.WindowStart
+		}
+		if in.WindowEnd != nil {
+			policy.WindowEnd = *in.WindowEnd
+		}
+		if in.TimeZone != nil {
+			policy.TimeZone = *in.TimeZone
+		}
+		if in.MaxMergesPerRepository != nil {
+			policy.MaxMergesPerRepo = int(*in.MaxMergesPerRepository)
+		}
+	}
+
+	svc := ee.NewService(r.store, gitserver.DefaultClient, r.httpFactory)
+	campaign, err := svc.SetCampaignAutoMerge(ctx, campaignID, policy)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting campaign auto-merge")
+	}
+
+	return &campaignResolver{store: r.store, Campaign: campaign}, nil
+}
+
+func (r *Resolver) SetCampaignPublishPolicy(ctx context.Context, args *graphqlbackend.SetCampaignPublishPolicyArgs) (_ graphqlbackend.CampaignResolver, err error) {
+	tr, ctx := trace.New(ctx, "Resolver.SetCampaignPublishPolicy", fmt.Sprintf("Campaign: %q", args.Campaign))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	// 🚨 SECURITY: Only site admins may update campaigns for now
+	if err := checkWriteAccess(ctx); err != nil {
+		return nil, errors.Wrap(err, "checking if user is admin")
+	}
+
+	campaignID, err := unmarshalCampaignID(args.Campaign)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling campaign id")
+	}
+
+	var policy *campaigns.PublishPolicy
+	if in := args.PublishPolicy; in != nil {
+		policy = &campaigns.PublishPolicy{}
+		if in.MaxChangesetsPerHour != nil {
+			policy.MaxChangesetsPerHour = int(*in.MaxChangesetsPerHour)
+		}
+		if policy.Days, err = parseWeekdays(in.Days); err != nil {
+			return nil, err
+		}
+		if in.WindowStart != nil {
+			policy.WindowStart = *in.WindowStart
+		}
+		if in.WindowEnd != nil {
+			policy.WindowEnd = *in.WindowEnd
+		}
+		if in.TimeZone != nil {
+			policy.TimeZone = *in.TimeZone
+		}
+		if in.InitialBatchSize != nil {
+			policy.InitialBatchSize = int(*in.InitialBatchSize)
+		}
+	}
+
+	svc := ee.NewService(r.store, gitserver.DefaultClient, r.httpFactory)
+	campaign, err := svc.SetCampaignPublishPolicy(ctx, campaignID, policy)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting campaign publish policy")
+	}
+
+	return &campaignResolver{store: r.store, Campaign: campaign}, nil
+}
+
+func (r *Resolver) SetCampaignReviewerPolicy(ctx context.Context, args *graphqlbackend.SetCampaignReviewerPolicyArgs) (_ graphqlbackend.CampaignResolver, err error) {
+	tr, ctx := trace.New(ctx, "Resolver.SetCampaignReviewerPolicy", fmt.Sprintf("Campaign: %q", args.Campaign))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	// 🚨 SECURITY: Only site admins may update campaigns for now
+	if err := checkWriteAccess(ctx); err != nil {
+		return nil, errors.Wrap(err, "checking if user is admin")
+	}
+
+	campaignID, err := unmarshalCampaignID(args.Campaign)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling campaign id")
+	}
+
+	var policy *campaigns.ReviewerPolicy
+	if in := args.ReviewerPolicy; in != nil {
+		policy = &campaigns.ReviewerPolicy{}
+		if in.IgnoreCodeOwners != nil {
+			policy.IgnoreCodeOwners = *in.IgnoreCodeOwners
+		}
+		if in.Reviewers != nil {
+			policy.Reviewers = *in.Reviewers
+		}
+		if in.Assignees != nil {
+			policy.Assignees = *in.Assignees
+		}
+	}
+
+	svc := ee.NewService(r.store, gitserver.DefaultClient, r.httpFactory)
+	campaign, err := svc.SetCampaignReviewerPolicy(ctx, campaignID, policy)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting campaign reviewer policy")
+	}
+
+	return &campaignResolver{store: r.store, Campaign: campaign}, nil
+}
+
+func (r *Resolver) CreateChangesetAction(ctx context.Context, args *graphqlbackend.CreateChangesetActionArgs) (_ graphqlbackend.ChangesetActionResolver, err error) {
+	tr, ctx := trace.New(ctx, "Resolver.CreateChangesetAction", fmt.Sprintf("Campaign: %q, Kind: %s", args.Campaign, args.Kind))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	// 🚨 SECURITY: Only site admins may update campaigns for now
+	if err := checkWriteAccess(ctx); err != nil {
+		return nil, errors.Wrap(err, "checking if user is admin")
+	}
+
+	campaignID, err := unmarshalCampaignID(args.Campaign)
+	if err != nil {
+		return nil, errors.Wrap(err, "unmarshaling campaign id")
+	}
+
+	action := &campaigns.ChangesetAction{
+		CampaignID: campaignID,
+		AuthorID:   actor.FromContext(ctx).UID,
+		Kind:       args.Kind,
+	}
+	if args.Comment != nil {
+		action.Comment = *args.Comment
+	}
+	if args.Labels != nil {
+		action.Labels = *args.Labels
+	}
+
+	opts, err := listChangesetOptsFromArgs(&graphqlbackend.ListChangesetsArgs{
+		State:       args.State,
+		ReviewState: args.ReviewState,
+		CheckState:  args.CheckState,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if opts.ExternalState != nil {
+		action.ExternalState = *opts.ExternalState
+	}
+	if opts.ExternalReviewState != nil {
+		action.ExternalReviewState = *opts.ExternalReviewState
+	}
+	if opts.ExternalCheckState != nil {
+		action.ExternalCheckState = *opts.ExternalCheckState
+	}
+
+	svc := ee.NewService(r.store, gitserver.DefaultClient, r.httpFactory)
+	if err = svc.CreateChangesetAction(ctx, action); err != nil {
+		return nil, errors.Wrap(err, "creating changeset action")
+	}
+
+	return &changesetActionResolver{store: r.store, ChangesetAction: action}, nil
+}
+
+// parseWeekdays parses the names of Weekday enum values of the GraphQL API.
+func parseWeekdays(days *[]string) ([]time.Weekday, error) {
+	if days == nil {
+		return nil, nil
+	}
+
+	ds := make([]time.Weekday, 0, len(*days))
+	for _, day := range *days {
+		d, err := parseWeekday(day)
+		if err != nil {
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	return ds, nil
+}
+
+// parseWeekday parses the name of a Weekday enum value of the GraphQL API.
+func parseWeekday(s string) (time.Weekday, error) {
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		if strings.ToUpper(d.String()) == s {
+			return d, nil
+		}
+	}
+	return 0, errors.Errorf("invalid day of the week %q", s)
+}
+
 func (r *Resolver) PublishChangeset(ctx context.Context, args *graphqlbackend.PublishChangesetArgs) (_ *graphqlbackend.EmptyResponse, err error) {
 	tr, ctx := trace.New(ctx, "Resolver.PublishChangeset", fmt.Sprintf("Patch: %q", args.Patch))
 	defer func() {
@@ -648,7 +1009,7 @@ func (r *Resolver) PublishChangeset(ctx context.Context, args *graphqlbackend.Pu
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
@@ -674,7 +1035,7 @@ func (r *Resolver) SyncChangeset(ctx context.Context, args *graphqlbackend.SyncC
 	}()
 
 	// 🚨 SECURITY: Only site admins may update campaigns for now
-	if err := backend.CheckCurrentUserIsSiteAdmin(ctx); err != nil {
+	if err := checkWriteAccess(ctx); err != nil {
 		return nil, errors.Wrap(err, "checking if user is admin")
 	}
 
@@ -0,0 +1,151 @@
+package campaigns
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sourcegraph/go-diff/diff"
+	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/codeowners"
+	"github.com/sourcegraph/sourcegraph/internal/gitserver"
+	"github.com/sourcegraph/sourcegraph/internal/trace"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+// maxCodeOwnersBytes is the maximum size of a CODEOWNERS file that is read.
+// GitHub ignores CODEOWNERS files larger than 3 MB, too.
+const maxCodeOwnersBytes = 3 * 1024 * 1024
+
+// SetCampaignReviewerPolicy sets the ReviewerPolicy of the Campaign with the
+// given ID. A nil policy requests reviews from the code owners of the changed
+// files only. It only applies to Changesets created after it has been set.
+func (s *Service) SetCampaignReviewerPolicy(ctx context.Context, id int64, policy *campaigns.ReviewerPolicy) (campaign *campaigns.Campaign, err error) {
+	tr, ctx := trace.New(ctx, "service.SetCampaignReviewerPolicy", fmt.Sprintf("campaign: %d", id))
+	defer func() {
+		tr.SetError(err)
+		tr.Finish()
+	}()
+
+	tx, err := s.store.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Done(&err)
+
+	campaign, err = tx.GetCampaign(ctx, GetCampaignOpts{ID: id})
+	if err != nil {
+		return nil, errors.Wrap(err, "getting campaign")
+	}
+
+	campaign.ReviewerPolicy = policy
+	return campaign, tx.UpdateCampaign(ctx, campaign)
+}
+
+// requestChangesetReviews requests reviews of a newly created Changeset from
+// the reviewers determined by changesetReviewers and assigns it, if the
+// ChangesetSource supports it.
+func requestChangesetReviews(ctx context.Context, src repos.ChangesetSource, c *campaigns.Campaign, patch *campaigns.Patch, cs *repos.Changeset) error {
+	rr, ok := src.(repos.ChangesetReviewRequester)
+	if !ok {
+		return nil
+	}
+
+	var owners *codeowners.File
+	if c.ReviewerPolicy == nil || !c.ReviewerPolicy.IgnoreCodeOwners {
+		var err error
+		owners, err = loadCodeOwners(ctx, api.RepoName(cs.Repo.Name), patch.Rev)
+		if err != nil {
+			return err
+		}
+	}
+
+	reviewers, assignees, err := changesetReviewers(owners, patch.Diff, c.ReviewerPolicy)
+	if err != nil {
+		return err
+	}
+	if len(reviewers) == 0 && len(assignees) == 0 {
+		return nil
+	}
+
+	return rr.RequestReviews(ctx, cs, reviewers, assignees)
+}
+
+// loadCodeOwners reads and parses the CODEOWNERS file of the given repository
+// at the given revision. It returns nil if the repository doesn't have one.
+func loadCodeOwners(ctx context.Context, repo api.RepoName, rev api.CommitID) (*codeowners.File, error) {
+	for _, path := range codeowners.Paths {
+		content, err := git.ReadFile(ctx, gitserver.Repo{Name: repo}, rev, path, maxCodeOwnersBytes)
+		if err != nil {
+			if os.IsNotExist(errors.Cause(err)) {
+				continue
+			}
+			return nil, errors.Wrapf(err, "reading %s", path)
+		}
+
+		f, err := codeowners.Parse(bytes.NewReader(content))
+		if err != nil {
+			return nil, errors.Wrapf(err, "parsing %s", path)
+		}
+		return f, nil
+	}
+	return nil, nil
+}
+
+// changesetReviewers returns who to request reviews of a Changeset from and
+// whom to assign it to, given the Changeset's diff. The reviewers are the
+// code owners of the changed files, as defined in owners, and the reviewers
+// of the policy. The assignees are the assignees of the policy or, if it
+// doesn't have any, the code owners. owners and policy can be nil.
+func changesetReviewers(owners *codeowners.File, fileDiff string, policy *campaigns.ReviewerPolicy) (reviewers, assignees []string, err error) {
+	var codeOwners []string
+	if owners != nil {
+		// Patches are applied with -p0, so the names of the files in the diff
+		// are the paths in the repository.
+		fds, err := diff.ParseMultiFileDiff([]byte(fileDiff))
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "parsing diff")
+		}
+
+		for _, fd := range fds {
+			for _, name := range []string{fd.OrigName, fd.NewName} {
+				if name == "/dev/null" {
+					continue
+				}
+				codeOwners = appendUnique(codeOwners, owners.Owners(name)...)
+			}
+		}
+	}
+
+	reviewers = appendUnique(nil, codeOwners...)
+	assignees = codeOwners
+	if policy != nil {
+		reviewers = appendUnique(reviewers, policy.Reviewers...)
+		if len(policy.Assignees) > 0 {
+			assignees = appendUnique(nil, policy.Assignees...)
+		}
+	}
+
+	return reviewers, assignees, nil
+}
+
+// appendUnique appends the elements of vs to ss that aren't in ss yet.
+func appendUnique(ss []string, vs ...string) []string {
+	for _, v := range vs {
+		found := false
+		for _, s := range ss {
+			if s == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			ss = append(ss, v)
+		}
+	}
+	return ss
+}
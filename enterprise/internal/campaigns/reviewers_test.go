@@ -0,0 +1,115 @@
+package campaigns
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/internal/api"
+	cmpgn "github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/vcs/git"
+)
+
+func TestChangesetReviewers(t *testing.T) {
+	ctx := context.Background()
+
+	git.Mocks.ReadFile = func(commit api.CommitID, name string) ([]byte, error) {
+		if commit != "deadbeef" {
+			t.Fatalf("wrong commit: %s", commit)
+		}
+		if name != "CODEOWNERS" {
+			return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+		}
+		return []byte("* @sourcegraph/core\n*.go @mrnugget\n/docs/ @sourcegraph/docs docs@sourcegraph.com\n"), nil
+	}
+	defer func() { git.Mocks.ReadFile = nil }()
+
+	owners, err := loadCodeOwners(ctx, "github.com/sourcegraph/sourcegraph", "deadbeef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if owners == nil {
+		t.Fatal("no CODEOWNERS file loaded")
+	}
+
+	const fileDiff = `diff cmd/main.go cmd/main.go
+--- cmd/main.go
++++ cmd/main.go
+@@ -1 +1 @@
+-fmt.Sprintf(x)
++fmt.Sprint(x)
+diff docs/old.md docs/old.md
+--- docs/old.md
++++ /dev/null
+@@ -1 +0,0 @@
+-Old
+`
+
+	tests := []struct {
+		name          string
+		owners        bool
+		policy        *cmpgn.ReviewerPolicy
+		wantReviewers []string
+		wantAssignees []string
+	}{
+		{
+			name:          "NoCodeOwners",
+			wantReviewers: nil,
+			wantAssignees: nil,
+		},
+		{
+			name:          "CodeOwners",
+			owners:        true,
+			wantReviewers: []string{"@mrnugget", "@sourcegraph/docs", "docs@sourcegraph.com"},
+			wantAssignees: []string{"@mrnugget", "@sourcegraph/docs", "docs@sourcegraph.com"},
+		},
+		{
+			name:   "AdditionalReviewers",
+			owners: true,
+			policy: &cmpgn.ReviewerPolicy{
+				Reviewers: []string{"@mrnugget", "@eseliger"},
+			},
+			wantReviewers: []string{"@mrnugget", "@sourcegraph/docs", "docs@sourcegraph.com", "@eseliger"},
+			wantAssignees: []string{"@mrnugget", "@sourcegraph/docs", "docs@sourcegraph.com"},
+		},
+		{
+			name:   "OverriddenAssignees",
+			owners: true,
+			policy: &cmpgn.ReviewerPolicy{
+				Assignees: []string{"@eseliger"},
+			},
+			wantReviewers: []string{"@mrnugget", "@sourcegraph/docs", "docs@sourcegraph.com"},
+			wantAssignees: []string{"@eseliger"},
+		},
+		{
+			name: "IgnoredCodeOwners",
+			policy: &cmpgn.ReviewerPolicy{
+				IgnoreCodeOwners: true,
+				Reviewers:        []string{"@eseliger"},
+			},
+			wantReviewers: []string{"@eseliger"},
+			wantAssignees: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			f := owners
+			if !tc.owners {
+				f = nil
+			}
+
+			reviewers, assignees, err := changesetReviewers(f, fileDiff, tc.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.wantReviewers, reviewers); diff != "" {
+				t.Errorf("wrong reviewers:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.wantAssignees, assignees); diff != "" {
+				t.Errorf("wrong assignees:\n%s", diff)
+			}
+		})
+	}
+}
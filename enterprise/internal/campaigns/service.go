@@ -97,6 +97,72 @@ func (s *Service) CreatePatchSetFromPatches(ctx context.Context, patches []*camp
 	return patchSet, nil
 }
 
+// CreatePatchSetFromPatchJobs creates a PatchSet and the given PatchJobs,
+// which compute its Patches in the background. The progress can be tracked
+// with Store.GetPatchSetStatus.
+func (s *Service) CreatePatchSetFromPatchJobs(ctx context.Context, jobs []*campaigns.PatchJob, userID int32) (_ *campaigns.PatchSet, err error) {
+	if userID == 0 {
+		return nil, backend.ErrNotAuthenticated
+	}
+	// Look up all repositories
+	reposStore := repos.NewDBStore(s.store.DB(), sql.TxOptions{})
+	repoIDs := make([]api.RepoID, len(jobs))
+	for i, job := range jobs {
+		repoIDs[i] = job.RepoID
+	}
+	allRepos, err := reposStore.ListRepos(ctx, repos.StoreListReposArgs{IDs: repoIDs})
+	if err != nil {
+		return nil, err
+	}
+	reposByID := make(map[api.RepoID]*repos.Repo, len(jobs))
+	for _, repo := range allRepos {
+		reposByID[repo.ID] = repo
+	}
+
+	tx, err := s.store.Transact(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Done(&err)
+
+	patchSet := &campaigns.PatchSet{UserID: userID}
+	err = tx.CreatePatchSet(ctx, patchSet)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, job := range jobs {
+		repo := reposByID[job.RepoID]
+		if repo == nil {
+			err = fmt.Errorf("repository ID %d not found", job.RepoID)
+			return nil, err
+		}
+		if !campaigns.IsRepoSupported(&repo.ExternalRepo) {
+			continue
+		}
+
+		job.PatchSetID = patchSet.ID
+		if err = tx.CreatePatchJob(ctx, job); err != nil {
+			return nil, err
+		}
+	}
+
+	return patchSet, nil
+}
+
+// checkPatchSetFinished returns ErrPatchSetProcessing if the PatchJobs of the
+// PatchSet with the given ID haven't finished yet.
+func checkPatchSetFinished(ctx context.Context, store *Store, patchSetID int64) error {
+	status, err := store.GetPatchSetStatus(ctx, patchSetID)
+	if err != nil {
+		return err
+	}
+	if status.Processing() {
+		return ErrPatchSetProcessing
+	}
+	return nil
+}
+
 // CreateCampaign creates the Campaign. When a PatchSetID is set on the
 // Campaign and the Campaign is not created as a draft, it calls
 // CreateChangesetJobs inside the same transaction in which it creates the
@@ -128,6 +194,10 @@ func (s *Service) CreateCampaign(ctx context.Context, c *campaigns.Campaign, dra
 			err = ErrPatchSetDuplicate
 			return err
 		}
+
+		if err = checkPatchSetFinished(ctx, tx, c.PatchSetID); err != nil {
+			return err
+		}
 	}
 
 	c.CreatedAt = s.clock()
@@ -482,6 +552,10 @@ var ErrPublishedCampaignBranchChange = errors.New("Published campaign branch can
 // specified patch set is already attached to another campaign.
 var ErrPatchSetDuplicate = errors.New("Campaign cannot use the same patch set as another campaign")
 
+// ErrPatchSetProcessing is returned by CreateCampaign or UpdateCampaign if
+// the patches of the specified patch set are still being computed.
+var ErrPatchSetProcessing = errors.New("cannot use a patch set whose patches are still being computed")
+
 // ErrClosedCampaignUpdatePatchIllegal is returned by UpdateCampaign if a patch set
 // is to be attached to a closed campaign.
 var ErrClosedCampaignUpdatePatchIllegal = errors.New("cannot update the patch set of a closed campaign")
@@ -545,6 +619,10 @@ func (s *Service) UpdateCampaign(ctx context.Context, args UpdateCampaignArgs) (
 			return nil, nil, ErrPatchSetDuplicate
 		}
 
+		if err = checkPatchSetFinished(ctx, tx, *args.PatchSet); err != nil {
+			return nil, nil, err
+		}
+
 		campaign.PatchSetID = *args.PatchSet
 		updatePatchSetID = true
 	}
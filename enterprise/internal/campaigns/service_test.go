@@ -436,6 +436,59 @@ func TestService(t *testing.T) {
 		}
 	})
 
+	t.Run("SetCampaignAutoMerge", func(t *testing.T) {
+		svc := NewServiceWithClock(store, gitClient, cf, clock)
+
+		campaign := testCampaign(user.ID, 0)
+		if err := store.CreateCampaign(ctx, campaign); err != nil {
+			t.Fatal(err)
+		}
+
+		invalid := &campaigns.AutoMergePolicy{Method: "FASTFORWARD"}
+		if _, err := svc.SetCampaignAutoMerge(ctx, campaign.ID, invalid); err == nil {
+			t.Fatal("no error for invalid auto-merge policy")
+		}
+
+		policy := &campaigns.AutoMergePolicy{
+			Method:      campaigns.ChangesetMergeMethodSquash,
+			Days:        []time.Weekday{time.Monday, time.Tuesday},
+			WindowStart: "09:00",
+			WindowEnd:   "17:00",
+		}
+		updated, err := svc.SetCampaignAutoMerge(ctx, campaign.ID, policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		have, err := store.GetCampaign(ctx, GetCampaignOpts{ID: campaign.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(updated, have); diff != "" {
+			t.Fatal(diff)
+		}
+		if diff := cmp.Diff(policy, have.AutoMerge); diff != "" {
+			t.Fatal(diff)
+		}
+
+		have.ClosedAt = now
+		if err := store.UpdateCampaign(ctx, have); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := svc.SetCampaignAutoMerge(ctx, campaign.ID, policy); err != ErrAutoMergeClosedCampaign {
+			t.Fatalf("wrong error. want=%q, have=%v", ErrAutoMergeClosedCampaign, err)
+		}
+
+		// Disabling auto-merge is always possible.
+		updated, err = svc.SetCampaignAutoMerge(ctx, campaign.ID, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if updated.AutoMerge != nil {
+			t.Fatalf("auto-merge not disabled: %+v", updated.AutoMerge)
+		}
+	})
 }
 
 type repoNames []string
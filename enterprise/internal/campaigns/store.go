@@ -15,8 +15,10 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/db/dbutil"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 // Store exposes methods to read and write campaigns domain models
@@ -99,6 +101,7 @@ UPDATE changeset_jobs j SET started_at = now() WHERE id = (
 	SELECT j.id FROM changeset_jobs j
 	JOIN campaigns c ON c.id = j.campaign_id
 	WHERE j.started_at IS NULL AND c.patch_set_id IS NOT NULL
+	AND (c.publish_policy IS NULL OR j.released_at IS NOT NULL)
 	ORDER BY j.id ASC
 	FOR UPDATE SKIP LOCKED LIMIT 1
 )
@@ -110,6 +113,10 @@ RETURNING j.id,
   j.error,
   j.started_at,
   j.finished_at,
+  j.rebase_rev,
+  j.rebase_error,
+  j.rebased_at,
+  j.released_at,
   j.created_at,
   j.updated_at
 `
@@ -1103,9 +1110,12 @@ INSERT INTO campaigns (
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  auto_merge,
+  publish_policy,
+  reviewer_policy
 )
-VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 RETURNING
   id,
   name,
@@ -1118,7 +1128,10 @@ RETURNING
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  auto_merge,
+  publish_policy,
+  reviewer_policy
 `
 
 func (s *Store) createCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error) {
@@ -1127,6 +1140,21 @@ func (s *Store) createCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error)
 		return nil, err
 	}
 
+	autoMerge, err := nullJSONColumn(c.AutoMerge)
+	if err != nil {
+		return nil, err
+	}
+
+	publishPolicy, err := nullJSONColumn(c.PublishPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewerPolicy, err := nullJSONColumn(c.ReviewerPolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	if c.CreatedAt.IsZero() {
 		c.CreatedAt = s.now()
 	}
@@ -1148,6 +1176,9 @@ func (s *Store) createCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error)
 		changesetIDs,
 		nullInt64Column(c.PatchSetID),
 		nullTimeColumn(c.ClosedAt),
+		autoMerge,
+		publishPolicy,
+		reviewerPolicy,
 	), nil
 }
 
@@ -1205,8 +1236,11 @@ SET (
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
-) = (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+  closed_at,
+  auto_merge,
+  publish_policy,
+  reviewer_policy
+) = (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 WHERE id = %s
 RETURNING
   id,
@@ -1220,7 +1254,10 @@ RETURNING
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  auto_merge,
+  publish_policy,
+  reviewer_policy
 `
 
 func (s *Store) updateCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error) {
@@ -1229,6 +1266,21 @@ func (s *Store) updateCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error)
 		return nil, err
 	}
 
+	autoMerge, err := nullJSONColumn(c.AutoMerge)
+	if err != nil {
+		return nil, err
+	}
+
+	publishPolicy, err := nullJSONColumn(c.PublishPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewerPolicy, err := nullJSONColumn(c.ReviewerPolicy)
+	if err != nil {
+		return nil, err
+	}
+
 	c.UpdatedAt = s.now()
 
 	return sqlf.Sprintf(
@@ -1243,6 +1295,9 @@ func (s *Store) updateCampaignQuery(c *campaigns.Campaign) (*sqlf.Query, error)
 		changesetIDs,
 		nullInt64Column(c.PatchSetID),
 		nullTimeColumn(c.ClosedAt),
+		autoMerge,
+		publishPolicy,
+		reviewerPolicy,
 		c.ID,
 	), nil
 }
@@ -1354,7 +1409,10 @@ SELECT
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  auto_merge,
+  publish_policy,
+  reviewer_policy
 FROM campaigns
 WHERE %s
 LIMIT 1
@@ -1385,6 +1443,11 @@ type ListCampaignsOpts struct {
 	Limit       int
 	State       campaigns.CampaignState
 	HasPatchSet *bool
+	// OnlyAutoMerge limits the results to Campaigns with an AutoMergePolicy.
+	OnlyAutoMerge bool
+	// OnlyPublishPolicy limits the results to Campaigns with a
+	// PublishPolicy.
+	OnlyPublishPolicy bool
 }
 
 // ListCampaigns lists Campaigns with the given filters.
@@ -1423,7 +1486,10 @@ SELECT
   updated_at,
   changeset_ids,
   patch_set_id,
-  closed_at
+  closed_at,
+  auto_merge,
+  publish_policy,
+  reviewer_policy
 FROM campaigns
 WHERE %s
 ORDER BY id ASC
@@ -1459,6 +1525,14 @@ func listCampaignsQuery(opts *ListCampaignsOpts) *sqlf.Query {
 		}
 	}
 
+	if opts.OnlyAutoMerge {
+		preds = append(preds, sqlf.Sprintf("auto_merge IS NOT NULL"))
+	}
+
+	if opts.OnlyPublishPolicy {
+		preds = append(preds, sqlf.Sprintf("publish_policy IS NOT NULL"))
+	}
+
 	return sqlf.Sprintf(
 		listCampaignsQueryFmtstr,
 		sqlf.Join(preds, "\n AND "),
@@ -1603,6 +1677,16 @@ NOT EXISTS (
   JOIN changesets ON changesets.id = changeset_jobs.changeset_id
   WHERE
     (SELECT COUNT(*) FROM jsonb_object_keys(changesets.campaign_ids)) > 0
+)
+AND
+NOT EXISTS (
+  SELECT 1
+  FROM
+    patch_jobs
+  WHERE
+    patch_jobs.patch_set_id = patch_sets.id
+  AND
+    patch_jobs.finished_at IS NULL
 );
 `
 
@@ -1774,6 +1858,307 @@ func listPatchSetsQuery(opts *ListPatchSetsOpts) *sqlf.Query {
 	)
 }
 
+// GetPatchSetStatus gets the campaigns.BackgroundProcessStatus for the
+// PatchJobs of a PatchSet.
+func (s *Store) GetPatchSetStatus(ctx context.Context, id int64) (*campaigns.BackgroundProcessStatus, error) {
+	return s.queryBackgroundProcessStatus(ctx, sqlf.Sprintf(
+		getPatchSetStatusQueryFmtstr,
+		sqlf.Sprintf("patch_set_id = %s", id),
+	))
+}
+
+var getPatchSetStatusQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:GetPatchSetStatus
+SELECT
+  -- canceled is here so that this can be used with scanBackgroundProcessStatus
+  false AS canceled,
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE finished_at IS NULL) AS pending,
+  COUNT(*) FILTER (WHERE finished_at IS NOT NULL) AS completed,
+  array_agg(error) FILTER (WHERE error != '') AS errors
+FROM patch_jobs
+WHERE %s
+LIMIT 1
+`
+
+// CreatePatchJob creates the given PatchJob.
+func (s *Store) CreatePatchJob(ctx context.Context, c *campaigns.PatchJob) error {
+	q, err := s.createPatchJobQuery(c)
+	if err != nil {
+		return err
+	}
+
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanPatchJob(c, sc)
+		return c.ID, 1, err
+	})
+}
+
+var createPatchJobQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:CreatePatchJob
+INSERT INTO patch_jobs (
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  match_template,
+  rewrite_template,
+  file_extension,
+  directory_exclude,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+RETURNING
+  id,
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  match_template,
+  rewrite_template,
+  file_extension,
+  directory_exclude,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+`
+
+func (s *Store) createPatchJobQuery(c *campaigns.PatchJob) (*sqlf.Query, error) {
+	if c.CreatedAt.IsZero() {
+		c.CreatedAt = s.now()
+	}
+
+	if c.UpdatedAt.IsZero() {
+		c.UpdatedAt = c.CreatedAt
+	}
+
+	return sqlf.Sprintf(
+		createPatchJobQueryFmtstr,
+		c.PatchSetID,
+		c.RepoID,
+		c.Rev,
+		c.BaseRef,
+		c.MatchTemplate,
+		c.RewriteTemplate,
+		c.FileExtension,
+		c.DirectoryExclude,
+		c.Error,
+		nullTimeColumn(c.StartedAt),
+		nullTimeColumn(c.FinishedAt),
+		c.CreatedAt,
+		c.UpdatedAt,
+	), nil
+}
+
+// UpdatePatchJob updates the given PatchJob.
+func (s *Store) UpdatePatchJob(ctx context.Context, c *campaigns.PatchJob) error {
+	q, err := s.updatePatchJobQuery(c)
+	if err != nil {
+		return err
+	}
+
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanPatchJob(c, sc)
+		return c.ID, 1, err
+	})
+}
+
+var updatePatchJobQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:UpdatePatchJob
+UPDATE patch_jobs
+SET (
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  match_template,
+  rewrite_template,
+  file_extension,
+  directory_exclude,
+  error,
+  started_at,
+  finished_at,
+  updated_at
+) = (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+WHERE id = %s
+RETURNING
+  id,
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  match_template,
+  rewrite_template,
+  file_extension,
+  directory_exclude,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+`
+
+func (s *Store) updatePatchJobQuery(c *campaigns.PatchJob) (*sqlf.Query, error) {
+	c.UpdatedAt = s.now()
+
+	return sqlf.Sprintf(
+		updatePatchJobQueryFmtstr,
+		c.PatchSetID,
+		c.RepoID,
+		c.Rev,
+		c.BaseRef,
+		c.MatchTemplate,
+		c.RewriteTemplate,
+		c.FileExtension,
+		c.DirectoryExclude,
+		c.Error,
+		nullTimeColumn(c.StartedAt),
+		nullTimeColumn(c.FinishedAt),
+		c.UpdatedAt,
+		c.ID,
+	), nil
+}
+
+// ListPatchJobsOpts captures the query options needed for
+// listing patch jobs.
+type ListPatchJobsOpts struct {
+	PatchSetID int64
+	Cursor     int64
+	Limit      int
+}
+
+// ListPatchJobs lists PatchJobs with the given filters.
+func (s *Store) ListPatchJobs(ctx context.Context, opts ListPatchJobsOpts) (cs []*campaigns.PatchJob, next int64, err error) {
+	q := listPatchJobsQuery(&opts)
+
+	cs = make([]*campaigns.PatchJob, 0, opts.Limit)
+	_, _, err = s.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var c campaigns.PatchJob
+		if err = scanPatchJob(&c, sc); err != nil {
+			return 0, 0, err
+		}
+		cs = append(cs, &c)
+		return c.ID, 1, err
+	})
+
+	if opts.Limit != 0 && len(cs) == opts.Limit {
+		next = cs[len(cs)-1].ID
+		cs = cs[:len(cs)-1]
+	}
+
+	return cs, next, err
+}
+
+var listPatchJobsQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:ListPatchJobs
+SELECT
+  id,
+  patch_set_id,
+  repo_id,
+  rev,
+  base_ref,
+  match_template,
+  rewrite_template,
+  file_extension,
+  directory_exclude,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+FROM patch_jobs
+WHERE %s
+ORDER BY id ASC
+%s
+`
+
+func listPatchJobsQuery(opts *ListPatchJobsOpts) *sqlf.Query {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListLimit
+	}
+	opts.Limit++
+
+	var limitClause *sqlf.Query
+	if opts.Limit > 0 {
+		limitClause = sqlf.Sprintf("LIMIT %s", opts.Limit)
+	} else {
+		limitClause = sqlf.Sprintf("")
+	}
+
+	preds := []*sqlf.Query{
+		sqlf.Sprintf("id >= %s", opts.Cursor),
+	}
+
+	if opts.PatchSetID != 0 {
+		preds = append(preds, sqlf.Sprintf("patch_set_id = %s", opts.PatchSetID))
+	}
+
+	return sqlf.Sprintf(
+		listPatchJobsQueryFmtstr,
+		sqlf.Join(preds, "\n AND "),
+		limitClause,
+	)
+}
+
+// ProcessPendingPatchJobs attempts to fetch one pending patch job.
+// A pending job is one that has never been started.
+// If found, 'process' is called with exclusive global access to the job,
+// just like in ProcessPendingChangesetJobs.
+// NOTE: It should not be called from within an existing transaction
+func (s *Store) ProcessPendingPatchJobs(ctx context.Context, process func(ctx context.Context, s *Store, job campaigns.PatchJob) error) (didRun bool, err error) {
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "starting transaction")
+	}
+	defer tx.Done(&err)
+	q := sqlf.Sprintf(getPendingPatchJobQuery)
+	var job campaigns.PatchJob
+	_, count, err := tx.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanPatchJob(&job, sc)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "scanning patch job row")
+		}
+		return job.ID, 1, nil
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "querying for pending patch job")
+	}
+	if count == 0 {
+		return false, nil
+	}
+	err = process(ctx, tx, job)
+	return true, err
+}
+
+const getPendingPatchJobQuery = `
+UPDATE patch_jobs j SET started_at = now() WHERE id = (
+	SELECT j.id FROM patch_jobs j
+	WHERE j.started_at IS NULL
+	ORDER BY j.id ASC
+	FOR UPDATE SKIP LOCKED LIMIT 1
+)
+RETURNING j.id,
+  j.patch_set_id,
+  j.repo_id,
+  j.rev,
+  j.base_ref,
+  j.match_template,
+  j.rewrite_template,
+  j.file_extension,
+  j.directory_exclude,
+  j.error,
+  j.started_at,
+  j.finished_at,
+  j.created_at,
+  j.updated_at
+`
+
 // CreatePatch creates the given Patch.
 // Due to a unique constraint in the DB it is safe to call this more than once
 // with the same input. Only one job will be added and the other calls will return an error
@@ -2124,10 +2509,14 @@ INSERT INTO changeset_jobs (
   error,
   started_at,
   finished_at,
+  rebase_rev,
+  rebase_error,
+  rebased_at,
+  released_at,
   created_at,
   updated_at
 )
-VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 RETURNING
   id,
   campaign_id,
@@ -2137,6 +2526,10 @@ RETURNING
   error,
   started_at,
   finished_at,
+  rebase_rev,
+  rebase_error,
+  rebased_at,
+  released_at,
   created_at,
   updated_at
 `
@@ -2159,6 +2552,10 @@ func (s *Store) createChangesetJobQuery(c *campaigns.ChangesetJob) (*sqlf.Query,
 		nullStringColumn(c.Error),
 		nullTimeColumn(c.StartedAt),
 		nullTimeColumn(c.FinishedAt),
+		c.RebaseRev,
+		c.RebaseError,
+		nullTimeColumn(c.RebasedAt),
+		nullTimeColumn(c.ReleasedAt),
 		c.CreatedAt,
 		c.UpdatedAt,
 	), nil
@@ -2188,8 +2585,12 @@ SET (
   error,
   started_at,
   finished_at,
+  rebase_rev,
+  rebase_error,
+  rebased_at,
+  released_at,
   updated_at
-) = (%s, %s, %s, %s, %s, %s, %s, %s)
+) = (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
 WHERE id = %s
 RETURNING
   id,
@@ -2200,6 +2601,10 @@ RETURNING
   error,
   started_at,
   finished_at,
+  rebase_rev,
+  rebase_error,
+  rebased_at,
+  released_at,
   created_at,
   updated_at
 `
@@ -2216,6 +2621,10 @@ func (s *Store) updateChangesetJobQuery(c *campaigns.ChangesetJob) (*sqlf.Query,
 		nullStringColumn(c.Error),
 		nullTimeColumn(c.StartedAt),
 		nullTimeColumn(c.FinishedAt),
+		c.RebaseRev,
+		c.RebaseError,
+		nullTimeColumn(c.RebasedAt),
+		nullTimeColumn(c.ReleasedAt),
 		c.UpdatedAt,
 		c.ID,
 	), nil
@@ -2338,6 +2747,10 @@ SELECT
   error,
   started_at,
   finished_at,
+  rebase_rev,
+  rebase_error,
+  rebased_at,
+  released_at,
   created_at,
   updated_at
 FROM changeset_jobs
@@ -2377,6 +2790,10 @@ type ListChangesetJobsOpts struct {
 	PatchSetID int64
 	Cursor     int64
 	Limit      int
+
+	// OnlyOpenChangesets limits the results to jobs that published a
+	// Changeset that is still open, in a Campaign that is still open.
+	OnlyOpenChangesets bool
 }
 
 // ListChangesetJobs lists ChangesetJobs with the given filters.
@@ -2412,6 +2829,10 @@ SELECT
   changeset_jobs.error,
   changeset_jobs.started_at,
   changeset_jobs.finished_at,
+  changeset_jobs.rebase_rev,
+  changeset_jobs.rebase_error,
+  changeset_jobs.rebased_at,
+  changeset_jobs.released_at,
   changeset_jobs.created_at,
   changeset_jobs.updated_at
 FROM changeset_jobs
@@ -2441,6 +2862,10 @@ func listChangesetJobsQuery(opts *ListChangesetJobsOpts) *sqlf.Query {
 		preds = append(preds, sqlf.Sprintf("changeset_jobs.campaign_id = %s", opts.CampaignID))
 	}
 
+	if opts.OnlyOpenChangesets {
+		preds = append(preds, sqlf.Sprintf(onlyOpenChangesetsQueryFmtstr, campaigns.ChangesetStateOpen))
+	}
+
 	var joinClause string
 	if opts.PatchSetID != 0 {
 		joinClause = "JOIN campaigns ON changeset_jobs.campaign_id = campaigns.id"
@@ -2454,8 +2879,27 @@ func listChangesetJobsQuery(opts *ListChangesetJobsOpts) *sqlf.Query {
 	return sqlf.Sprintf(queryTemplate, sqlf.Join(preds, "\n AND "))
 }
 
-// ResetFailedChangesetJobs resets the Error, StartedAt and FinishedAt fields
-// of the ChangesetJobs belonging to the Campaign with the given ID that
+var onlyOpenChangesetsQueryFmtstr = `
+EXISTS (
+  SELECT 1
+  FROM changesets
+  WHERE
+    changesets.id = changeset_jobs.changeset_id
+  AND
+    changesets.external_state = %s
+)
+AND EXISTS (
+  SELECT 1
+  FROM campaigns
+  WHERE
+    campaigns.id = changeset_jobs.campaign_id
+  AND
+    campaigns.closed_at IS NULL
+)
+`
+
+// ResetFailedChangesetJobs resets the Error, StartedAt, FinishedAt and rebase
+// fields of the ChangesetJobs belonging to the Campaign with the given ID that
 // resulted in an error.
 func (s *Store) ResetFailedChangesetJobs(ctx context.Context, campaignID int64) (err error) {
 	q := resetChangesetJobsQuery(campaignID, true)
@@ -2465,7 +2909,7 @@ func (s *Store) ResetFailedChangesetJobs(ctx context.Context, campaignID int64)
 	})
 }
 
-// ResetChangesetJobs resets the Error, StartedAt and FinishedAt fields
+// ResetChangesetJobs resets the Error, StartedAt, FinishedAt and rebase fields
 // of all ChangesetJobs belonging to the Campaign with the given ID.
 func (s *Store) ResetChangesetJobs(ctx context.Context, campaignID int64) (err error) {
 	q := resetChangesetJobsQuery(campaignID, false)
@@ -2496,16 +2940,477 @@ UPDATE changeset_jobs
 SET
   error = '',
   started_at = NULL,
-  finished_at = NULL
+  finished_at = NULL,
+  rebase_rev = '',
+  rebase_error = '',
+  rebased_at = NULL
 WHERE %s
 `
 
-// GetChangesetExternalIDs allows us to find the external ids for pull requests based on
-// a slice of head refs. We need this in order to match incoming webhooks to pull requests as
-// the only information they provide is the remote branch
-func (s *Store) GetChangesetExternalIDs(ctx context.Context, spec api.ExternalRepoSpec, refs []string) ([]string, error) {
-	queryFmtString := `
-	SELECT cs.external_id FROM changesets cs
+// CreateChangesetAction creates the given ChangesetAction.
+func (s *Store) CreateChangesetAction(ctx context.Context, a *campaigns.ChangesetAction) error {
+	q, err := s.createChangesetActionQuery(a)
+	if err != nil {
+		return err
+	}
+
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanChangesetAction(a, sc)
+		return a.ID, 1, err
+	})
+}
+
+var createChangesetActionQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:CreateChangesetAction
+INSERT INTO changeset_actions (
+  campaign_id,
+  author_id,
+  kind,
+  comment,
+  labels,
+  external_state,
+  external_review_state,
+  external_check_state,
+  created_at,
+  updated_at
+)
+VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+RETURNING
+  id,
+  campaign_id,
+  author_id,
+  kind,
+  comment,
+  labels,
+  external_state,
+  external_review_state,
+  external_check_state,
+  created_at,
+  updated_at
+`
+
+func (s *Store) createChangesetActionQuery(a *campaigns.ChangesetAction) (*sqlf.Query, error) {
+	if a.CreatedAt.IsZero() {
+		a.CreatedAt = s.now()
+	}
+
+	if a.UpdatedAt.IsZero() {
+		a.UpdatedAt = a.CreatedAt
+	}
+
+	labels := a.Labels
+	if labels == nil {
+		labels = []string{}
+	}
+	labelsColumn, err := json.Marshal(labels)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlf.Sprintf(
+		createChangesetActionQueryFmtstr,
+		a.CampaignID,
+		a.AuthorID,
+		a.Kind,
+		a.Comment,
+		labelsColumn,
+		nullStringColumn(string(a.ExternalState)),
+		nullStringColumn(string(a.ExternalReviewState)),
+		nullStringColumn(string(a.ExternalCheckState)),
+		a.CreatedAt,
+		a.UpdatedAt,
+	), nil
+}
+
+// GetChangesetActionOpts captures the query options needed for getting a
+// ChangesetAction.
+type GetChangesetActionOpts struct {
+	ID int64
+}
+
+// GetChangesetAction gets a ChangesetAction matching the given options.
+func (s *Store) GetChangesetAction(ctx context.Context, opts GetChangesetActionOpts) (*campaigns.ChangesetAction, error) {
+	q := sqlf.Sprintf(getChangesetActionQueryFmtstr, opts.ID)
+
+	var a campaigns.ChangesetAction
+	err := s.exec(ctx, q, func(sc scanner) (_, _ int64, err error) {
+		return 0, 0, scanChangesetAction(&a, sc)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if a.ID == 0 {
+		return nil, ErrNoResults
+	}
+
+	return &a, nil
+}
+
+var getChangesetActionQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:GetChangesetAction
+SELECT
+  id,
+  campaign_id,
+  author_id,
+  kind,
+  comment,
+  labels,
+  external_state,
+  external_review_state,
+  external_check_state,
+  created_at,
+  updated_at
+FROM changeset_actions
+WHERE id = %s
+LIMIT 1
+`
+
+// CountChangesetActionsOpts captures the query options needed for
+// counting changeset actions.
+type CountChangesetActionsOpts struct {
+	CampaignID int64
+}
+
+// CountChangesetActions returns the number of ChangesetActions in the database.
+func (s *Store) CountChangesetActions(ctx context.Context, opts CountChangesetActionsOpts) (count int64, _ error) {
+	q := sqlf.Sprintf(countChangesetActionsQueryFmtstr, opts.CampaignID)
+	return count, s.exec(ctx, q, func(sc scanner) (_, _ int64, err error) {
+		err = sc.Scan(&count)
+		return 0, count, err
+	})
+}
+
+var countChangesetActionsQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:CountChangesetActions
+SELECT COUNT(id)
+FROM changeset_actions
+WHERE campaign_id = %s
+`
+
+// ListChangesetActionsOpts captures the query options needed for
+// listing changeset actions.
+type ListChangesetActionsOpts struct {
+	CampaignID int64
+	Cursor     int64
+	Limit      int
+}
+
+// ListChangesetActions lists ChangesetActions with the given filters.
+func (s *Store) ListChangesetActions(ctx context.Context, opts ListChangesetActionsOpts) (as []*campaigns.ChangesetAction, next int64, err error) {
+	q := listChangesetActionsQuery(&opts)
+
+	as = make([]*campaigns.ChangesetAction, 0, opts.Limit)
+	_, _, err = s.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var a campaigns.ChangesetAction
+		if err = scanChangesetAction(&a, sc); err != nil {
+			return 0, 0, err
+		}
+		as = append(as, &a)
+		return a.ID, 1, err
+	})
+
+	if opts.Limit != 0 && len(as) == opts.Limit {
+		next = as[len(as)-1].ID
+		as = as[:len(as)-1]
+	}
+
+	return as, next, err
+}
+
+var listChangesetActionsQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:ListChangesetActions
+SELECT
+  id,
+  campaign_id,
+  author_id,
+  kind,
+  comment,
+  labels,
+  external_state,
+  external_review_state,
+  external_check_state,
+  created_at,
+  updated_at
+FROM changeset_actions
+WHERE %s
+ORDER BY id ASC
+%s
+`
+
+func listChangesetActionsQuery(opts *ListChangesetActionsOpts) *sqlf.Query {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListLimit
+	}
+	opts.Limit++
+
+	var limitClause *sqlf.Query
+	if opts.Limit > 0 {
+		limitClause = sqlf.Sprintf("LIMIT %s", opts.Limit)
+	} else {
+		limitClause = sqlf.Sprintf("")
+	}
+
+	preds := []*sqlf.Query{
+		sqlf.Sprintf("id >= %s", opts.Cursor),
+	}
+
+	if opts.CampaignID != 0 {
+		preds = append(preds, sqlf.Sprintf("campaign_id = %s", opts.CampaignID))
+	}
+
+	return sqlf.Sprintf(
+		listChangesetActionsQueryFmtstr,
+		sqlf.Join(preds, "\n AND "),
+		limitClause,
+	)
+}
+
+// GetChangesetActionStatus gets the campaigns.BackgroundProcessStatus for the
+// ChangesetActionJobs of a ChangesetAction.
+func (s *Store) GetChangesetActionStatus(ctx context.Context, id int64) (*campaigns.BackgroundProcessStatus, error) {
+	return s.queryBackgroundProcessStatus(ctx, sqlf.Sprintf(
+		getChangesetActionStatusQueryFmtstr,
+		sqlf.Sprintf("action_id = %s", id),
+	))
+}
+
+var getChangesetActionStatusQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:GetChangesetActionStatus
+SELECT
+  -- canceled is here so that this can be used with scanBackgroundProcessStatus
+  false AS canceled,
+  COUNT(*) AS total,
+  COUNT(*) FILTER (WHERE finished_at IS NULL) AS pending,
+  COUNT(*) FILTER (WHERE finished_at IS NOT NULL) AS completed,
+  array_agg(error) FILTER (WHERE error != '') AS errors
+FROM changeset_action_jobs
+WHERE %s
+LIMIT 1
+`
+
+// CreateChangesetActionJob creates the given ChangesetActionJob.
+func (s *Store) CreateChangesetActionJob(ctx context.Context, j *campaigns.ChangesetActionJob) error {
+	if j.CreatedAt.IsZero() {
+		j.CreatedAt = s.now()
+	}
+
+	if j.UpdatedAt.IsZero() {
+		j.UpdatedAt = j.CreatedAt
+	}
+
+	q := sqlf.Sprintf(
+		createChangesetActionJobQueryFmtstr,
+		j.ActionID,
+		j.ChangesetID,
+		j.Error,
+		nullTimeColumn(j.StartedAt),
+		nullTimeColumn(j.FinishedAt),
+		j.CreatedAt,
+		j.UpdatedAt,
+	)
+
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanChangesetActionJob(j, sc)
+		return j.ID, 1, err
+	})
+}
+
+var createChangesetActionJobQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:CreateChangesetActionJob
+INSERT INTO changeset_action_jobs (
+  action_id,
+  changeset_id,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+)
+VALUES (%s, %s, %s, %s, %s, %s, %s)
+RETURNING
+  id,
+  action_id,
+  changeset_id,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+`
+
+// UpdateChangesetActionJob updates the given ChangesetActionJob.
+func (s *Store) UpdateChangesetActionJob(ctx context.Context, j *campaigns.ChangesetActionJob) error {
+	j.UpdatedAt = s.now()
+
+	q := sqlf.Sprintf(
+		updateChangesetActionJobQueryFmtstr,
+		j.ActionID,
+		j.ChangesetID,
+		j.Error,
+		nullTimeColumn(j.StartedAt),
+		nullTimeColumn(j.FinishedAt),
+		j.UpdatedAt,
+		j.ID,
+	)
+
+	return s.exec(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanChangesetActionJob(j, sc)
+		return j.ID, 1, err
+	})
+}
+
+var updateChangesetActionJobQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:UpdateChangesetActionJob
+UPDATE changeset_action_jobs
+SET (
+  action_id,
+  changeset_id,
+  error,
+  started_at,
+  finished_at,
+  updated_at
+) = (%s, %s, %s, %s, %s, %s)
+WHERE id = %s
+RETURNING
+  id,
+  action_id,
+  changeset_id,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+`
+
+// ListChangesetActionJobsOpts captures the query options needed for
+// listing changeset action jobs.
+type ListChangesetActionJobsOpts struct {
+	ActionID int64
+	Cursor   int64
+	Limit    int
+}
+
+// ListChangesetActionJobs lists ChangesetActionJobs with the given filters.
+func (s *Store) ListChangesetActionJobs(ctx context.Context, opts ListChangesetActionJobsOpts) (js []*campaigns.ChangesetActionJob, next int64, err error) {
+	q := listChangesetActionJobsQuery(&opts)
+
+	js = make([]*campaigns.ChangesetActionJob, 0, opts.Limit)
+	_, _, err = s.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		var j campaigns.ChangesetActionJob
+		if err = scanChangesetActionJob(&j, sc); err != nil {
+			return 0, 0, err
+		}
+		js = append(js, &j)
+		return j.ID, 1, err
+	})
+
+	if opts.Limit != 0 && len(js) == opts.Limit {
+		next = js[len(js)-1].ID
+		js = js[:len(js)-1]
+	}
+
+	return js, next, err
+}
+
+var listChangesetActionJobsQueryFmtstr = `
+-- source: enterprise/internal/campaigns/store.go:ListChangesetActionJobs
+SELECT
+  id,
+  action_id,
+  changeset_id,
+  error,
+  started_at,
+  finished_at,
+  created_at,
+  updated_at
+FROM changeset_action_jobs
+WHERE %s
+ORDER BY id ASC
+%s
+`
+
+func listChangesetActionJobsQuery(opts *ListChangesetActionJobsOpts) *sqlf.Query {
+	if opts.Limit == 0 {
+		opts.Limit = defaultListLimit
+	}
+	opts.Limit++
+
+	var limitClause *sqlf.Query
+	if opts.Limit > 0 {
+		limitClause = sqlf.Sprintf("LIMIT %s", opts.Limit)
+	} else {
+		limitClause = sqlf.Sprintf("")
+	}
+
+	preds := []*sqlf.Query{
+		sqlf.Sprintf("id >= %s", opts.Cursor),
+	}
+
+	if opts.ActionID != 0 {
+		preds = append(preds, sqlf.Sprintf("action_id = %s", opts.ActionID))
+	}
+
+	return sqlf.Sprintf(
+		listChangesetActionJobsQueryFmtstr,
+		sqlf.Join(preds, "\n AND "),
+		limitClause,
+	)
+}
+
+// ProcessPendingChangesetActionJobs attempts to fetch one pending changeset
+// action job. A pending job is one that has never been started.
+// If found, 'process' is called with exclusive global access to the job,
+// just like in ProcessPendingChangesetJobs.
+// NOTE: It should not be called from within an existing transaction
+func (s *Store) ProcessPendingChangesetActionJobs(ctx context.Context, process func(ctx context.Context, s *Store, job campaigns.ChangesetActionJob) error) (didRun bool, err error) {
+	tx, err := s.Transact(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "starting transaction")
+	}
+	defer tx.Done(&err)
+	q := sqlf.Sprintf(getPendingChangesetActionJobQuery)
+	var job campaigns.ChangesetActionJob
+	_, count, err := tx.query(ctx, q, func(sc scanner) (last, count int64, err error) {
+		err = scanChangesetActionJob(&job, sc)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "scanning changeset action job row")
+		}
+		return job.ID, 1, nil
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "querying for pending changeset action job")
+	}
+	if count == 0 {
+		return false, nil
+	}
+	err = process(ctx, tx, job)
+	return true, err
+}
+
+const getPendingChangesetActionJobQuery = `
+UPDATE changeset_action_jobs j SET started_at = now() WHERE id = (
+	SELECT j.id FROM changeset_action_jobs j
+	WHERE j.started_at IS NULL
+	ORDER BY j.id ASC
+	FOR UPDATE SKIP LOCKED LIMIT 1
+)
+RETURNING j.id,
+  j.action_id,
+  j.changeset_id,
+  j.error,
+  j.started_at,
+  j.finished_at,
+  j.created_at,
+  j.updated_at
+`
+
+// GetChangesetExternalIDs allows us to find the external ids for pull requests based on
+// a slice of head refs. We need this in order to match incoming webhooks to pull requests as
+// the only information they provide is the remote branch
+func (s *Store) GetChangesetExternalIDs(ctx context.Context, spec api.ExternalRepoSpec, refs []string) ([]string, error) {
+	queryFmtString := `
+	SELECT cs.external_id FROM changesets cs
 	JOIN repo r ON cs.repo_id = r.id
 	WHERE cs.external_service_type = %s
 	AND cs.external_branch IN (%s)
@@ -2619,6 +3524,10 @@ func scanChangeset(t *campaigns.Changeset, s scanner) error {
 		t.Metadata = new(github.PullRequest)
 	case bitbucketserver.ServiceType:
 		t.Metadata = new(bitbucketserver.PullRequest)
+	case gitlab.ServiceType:
+		t.Metadata = new(gitlab.MergeRequest)
+	case bitbucketcloud.ServiceType:
+		t.Metadata = new(bitbucketcloud.PullRequest)
 	default:
 		return errors.New("unknown external service type")
 	}
@@ -2659,7 +3568,9 @@ func scanChangesetEvent(e *campaigns.ChangesetEvent, s scanner) error {
 }
 
 func scanCampaign(c *campaigns.Campaign, s scanner) error {
-	return s.Scan(
+	var autoMerge, publishPolicy, reviewerPolicy []byte
+
+	err := s.Scan(
 		&c.ID,
 		&c.Name,
 		&c.Description,
@@ -2672,7 +3583,36 @@ func scanCampaign(c *campaigns.Campaign, s scanner) error {
 		&dbutil.JSONInt64Set{Set: &c.ChangesetIDs},
 		&dbutil.NullInt64{N: &c.PatchSetID},
 		&dbutil.NullTime{Time: &c.ClosedAt},
+		&autoMerge,
+		&publishPolicy,
+		&reviewerPolicy,
 	)
+	if err != nil {
+		return err
+	}
+
+	c.AutoMerge = nil
+	if autoMerge != nil {
+		c.AutoMerge = new(campaigns.AutoMergePolicy)
+		if err := json.Unmarshal(autoMerge, c.AutoMerge); err != nil {
+			return err
+		}
+	}
+
+	c.PublishPolicy = nil
+	if publishPolicy != nil {
+		c.PublishPolicy = new(campaigns.PublishPolicy)
+		if err := json.Unmarshal(publishPolicy, c.PublishPolicy); err != nil {
+			return err
+		}
+	}
+
+	c.ReviewerPolicy = nil
+	if reviewerPolicy != nil {
+		c.ReviewerPolicy = new(campaigns.ReviewerPolicy)
+		return json.Unmarshal(reviewerPolicy, c.ReviewerPolicy)
+	}
+	return nil
 }
 
 func scanPatchSet(c *campaigns.PatchSet, s scanner) error {
@@ -2692,6 +3632,25 @@ func scanPatch(c *campaigns.Patch, s scanner) error {
 	)
 }
 
+func scanPatchJob(c *campaigns.PatchJob, s scanner) error {
+	return s.Scan(
+		&c.ID,
+		&c.PatchSetID,
+		&c.RepoID,
+		&c.Rev,
+		&c.BaseRef,
+		&c.MatchTemplate,
+		&c.RewriteTemplate,
+		&c.FileExtension,
+		&c.DirectoryExclude,
+		&c.Error,
+		&dbutil.NullTime{Time: &c.StartedAt},
+		&dbutil.NullTime{Time: &c.FinishedAt},
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
+
 func scanChangesetJob(c *campaigns.ChangesetJob, s scanner) error {
 	return s.Scan(
 		&c.ID,
@@ -2702,11 +3661,57 @@ func scanChangesetJob(c *campaigns.ChangesetJob, s scanner) error {
 		&dbutil.NullString{S: &c.Error},
 		&dbutil.NullTime{Time: &c.StartedAt},
 		&dbutil.NullTime{Time: &c.FinishedAt},
+		&c.RebaseRev,
+		&c.RebaseError,
+		&dbutil.NullTime{Time: &c.RebasedAt},
+		&dbutil.NullTime{Time: &c.ReleasedAt},
 		&c.CreatedAt,
 		&c.UpdatedAt,
 	)
 }
 
+func scanChangesetAction(a *campaigns.ChangesetAction, s scanner) error {
+	var labels []byte
+	var state, reviewState, checkState string
+
+	err := s.Scan(
+		&a.ID,
+		&a.CampaignID,
+		&a.AuthorID,
+		&a.Kind,
+		&a.Comment,
+		&labels,
+		&dbutil.NullString{S: &state},
+		&dbutil.NullString{S: &reviewState},
+		&dbutil.NullString{S: &checkState},
+		&a.CreatedAt,
+		&a.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	a.ExternalState = campaigns.ChangesetState(state)
+	a.ExternalReviewState = campaigns.ChangesetReviewState(reviewState)
+	a.ExternalCheckState = campaigns.ChangesetCheckState(checkState)
+
+	a.Labels = nil
+	return json.Unmarshal(labels, &a.Labels)
+}
+
+func scanChangesetActionJob(j *campaigns.ChangesetActionJob, s scanner) error {
+	return s.Scan(
+		&j.ID,
+		&j.ActionID,
+		&j.ChangesetID,
+		&j.Error,
+		&dbutil.NullTime{Time: &j.StartedAt},
+		&dbutil.NullTime{Time: &j.FinishedAt},
+		&j.CreatedAt,
+		&j.UpdatedAt,
+	)
+}
+
 func scanBackgroundProcessStatus(b *campaigns.BackgroundProcessStatus, s scanner) error {
 	return s.Scan(
 		&b.Canceled,
@@ -2733,6 +3738,16 @@ func metadataColumn(metadata interface{}) (msg json.RawMessage, err error) {
 	return
 }
 
+// nullJSONColumn returns the JSON encoding of v, or nil if v is a nil
+// pointer.
+func nullJSONColumn(v interface{}) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || string(b) == "null" {
+		return nil, err
+	}
+	return b, nil
+}
+
 func jsonSetColumn(ids []int64) ([]byte, error) {
 	set := make(map[int64]*struct{}, len(ids))
 	for _, id := range ids {
@@ -72,6 +72,23 @@ func testStore(db *sql.DB) func(*testing.T) {
 						c.PatchSetID = 0
 						// Don't close the first one
 						c.ClosedAt = time.Time{}
+						// Only merge the first one automatically
+						c.AutoMerge = &cmpgn.AutoMergePolicy{
+							Method:      cmpgn.ChangesetMergeMethodSquash,
+							Days:        []time.Weekday{time.Monday, time.Tuesday},
+							WindowStart: "09:00",
+							WindowEnd:   "17:00",
+						}
+						// And only throttle publishing of the first one
+						c.PublishPolicy = &cmpgn.PublishPolicy{
+							MaxChangesetsPerHour: 10,
+							InitialBatchSize:     5,
+						}
+						// And only override the reviewers of the first one
+						c.ReviewerPolicy = &cmpgn.ReviewerPolicy{
+							Reviewers: []string{"@sourcegraph/campaigns"},
+							Assignees: []string{"@mrnugget"},
+						}
 					}
 
 					if i%2 == 0 {
@@ -269,6 +286,26 @@ func testStore(db *sql.DB) func(*testing.T) {
 						t.Fatal(diff)
 					}
 				})
+
+				t.Run("ListCampaigns OnlyAutoMerge", func(t *testing.T) {
+					have, _, err := s.ListCampaigns(ctx, ListCampaignsOpts{OnlyAutoMerge: true})
+					if err != nil {
+						t.Fatal(err)
+					}
+					if diff := cmp.Diff(have, campaigns[0:1]); diff != "" {
+						t.Fatal(diff)
+					}
+				})
+
+				t.Run("ListCampaigns OnlyPublishPolicy", func(t *testing.T) {
+					have, _, err := s.ListCampaigns(ctx, ListCampaignsOpts{OnlyPublishPolicy: true})
+					if err != nil {
+						t.Fatal(err)
+					}
+					if diff := cmp.Diff(have, campaigns[0:1]); diff != "" {
+						t.Fatal(diff)
+					}
+				})
 			})
 
 			t.Run("Update", func(t *testing.T) {
@@ -1947,6 +1984,321 @@ func testStore(db *sql.DB) func(*testing.T) {
 			}
 		})
 
+		t.Run("PatchJobs", func(t *testing.T) {
+			patchSetID := int64(4242)
+			jobs := make([]*cmpgn.PatchJob, 0, 3)
+
+			t.Run("Create", func(t *testing.T) {
+				for i := 0; i < cap(jobs); i++ {
+					j := &cmpgn.PatchJob{
+						PatchSetID:      patchSetID,
+						RepoID:          1,
+						Rev:             api.CommitID("deadbeef"),
+						BaseRef:         "refs/heads/master",
+						MatchTemplate:   "fmt.Sprintf(:[args])",
+						RewriteTemplate: "fmt.Sprint(:[args])",
+						FileExtension:   ".go",
+					}
+
+					want := j.Clone()
+					have := j
+
+					err := s.CreatePatchJob(ctx, have)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if have.ID == 0 {
+						t.Fatal("ID should not be zero")
+					}
+
+					want.ID = have.ID
+					want.CreatedAt = now
+					want.UpdatedAt = now
+
+					if diff := cmp.Diff(have, want); diff != "" {
+						t.Fatal(diff)
+					}
+
+					jobs = append(jobs, j)
+				}
+			})
+
+			t.Run("List", func(t *testing.T) {
+				for i := 1; i <= len(jobs); i++ {
+					opts := ListPatchJobsOpts{PatchSetID: patchSetID, Limit: i}
+					have, next, err := s.ListPatchJobs(ctx, opts)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					{
+						have, want := next, int64(0)
+						if i < len(jobs) {
+							want = jobs[i].ID
+						}
+
+						if have != want {
+							t.Fatalf("opts: %+v: have next %v, want %v", opts, have, want)
+						}
+					}
+
+					if diff := cmp.Diff(have, jobs[:i]); diff != "" {
+						t.Fatalf("opts: %+v, diff: %s", opts, diff)
+					}
+				}
+			})
+
+			t.Run("Update", func(t *testing.T) {
+				jobs[0].StartedAt = now
+				jobs[0].FinishedAt = now
+				jobs[1].StartedAt = now
+				jobs[1].FinishedAt = now
+				jobs[1].Error = "replacer failed"
+
+				for _, j := range jobs[:2] {
+					want := j.Clone()
+					if err := s.UpdatePatchJob(ctx, j); err != nil {
+						t.Fatal(err)
+					}
+					if diff := cmp.Diff(j, want); diff != "" {
+						t.Fatal(diff)
+					}
+				}
+			})
+
+			t.Run("GetPatchSetStatus", func(t *testing.T) {
+				status, err := s.GetPatchSetStatus(ctx, patchSetID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := &cmpgn.BackgroundProcessStatus{
+					ProcessState:  cmpgn.BackgroundProcessStateProcessing,
+					Total:         3,
+					Completed:     2,
+					Pending:       1,
+					ProcessErrors: []string{"replacer failed"},
+				}
+				if diff := cmp.Diff(status, want); diff != "" {
+					t.Fatalf("wrong diff: %s", diff)
+				}
+
+				// PatchSets without PatchJobs are completed.
+				status, err = s.GetPatchSetStatus(ctx, patchSetID+1)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if have, want := status.ProcessState, cmpgn.BackgroundProcessStateCompleted; have != want {
+					t.Fatalf("have state %q, want %q", have, want)
+				}
+			})
+		})
+
+		t.Run("ChangesetActions", func(t *testing.T) {
+			campaignID := int64(4343)
+			actions := make([]*cmpgn.ChangesetAction, 0, 3)
+
+			t.Run("Create", func(t *testing.T) {
+				for i := 0; i < cap(actions); i++ {
+					a := &cmpgn.ChangesetAction{
+						CampaignID: campaignID,
+						AuthorID:   23,
+						Kind:       cmpgn.ChangesetActionKindAddLabels,
+						Labels:     []string{"automation", "campaign"},
+					}
+
+					if i == 1 {
+						a.Kind = cmpgn.ChangesetActionKindComment
+						a.Comment = "Please review"
+						a.Labels = []string{}
+						a.ExternalState = cmpgn.ChangesetStateOpen
+						a.ExternalReviewState = cmpgn.ChangesetReviewStatePending
+						a.ExternalCheckState = cmpgn.ChangesetCheckStatePassed
+					}
+
+					want := a.Clone()
+					have := a
+
+					err := s.CreateChangesetAction(ctx, have)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if have.ID == 0 {
+						t.Fatal("ID should not be zero")
+					}
+
+					want.ID = have.ID
+					want.CreatedAt = now
+					want.UpdatedAt = now
+
+					if diff := cmp.Diff(have, want); diff != "" {
+						t.Fatal(diff)
+					}
+
+					actions = append(actions, a)
+				}
+			})
+
+			t.Run("Count", func(t *testing.T) {
+				count, err := s.CountChangesetActions(ctx, CountChangesetActionsOpts{CampaignID: campaignID})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if have, want := count, int64(len(actions)); have != want {
+					t.Fatalf("have count: %d, want: %d", have, want)
+				}
+
+				count, err = s.CountChangesetActions(ctx, CountChangesetActionsOpts{CampaignID: campaignID + 1})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if have, want := count, int64(0); have != want {
+					t.Fatalf("have count: %d, want: %d", have, want)
+				}
+			})
+
+			t.Run("List", func(t *testing.T) {
+				for i := 1; i <= len(actions); i++ {
+					opts := ListChangesetActionsOpts{CampaignID: campaignID, Limit: i}
+					have, next, err := s.ListChangesetActions(ctx, opts)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					{
+						have, want := next, int64(0)
+						if i < len(actions) {
+							want = actions[i].ID
+						}
+
+						if have != want {
+							t.Fatalf("opts: %+v: have next %v, want %v", opts, have, want)
+						}
+					}
+
+					if diff := cmp.Diff(have, actions[:i]); diff != "" {
+						t.Fatalf("opts: %+v, diff: %s", opts, diff)
+					}
+				}
+			})
+
+			t.Run("Get", func(t *testing.T) {
+				want := actions[1]
+				have, err := s.GetChangesetAction(ctx, GetChangesetActionOpts{ID: want.ID})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if diff := cmp.Diff(have, want); diff != "" {
+					t.Fatal(diff)
+				}
+
+				_, err = s.GetChangesetAction(ctx, GetChangesetActionOpts{ID: 0xdeadbeef})
+				if have, want := err, ErrNoResults; have != want {
+					t.Fatalf("have err %v, want %v", have, want)
+				}
+			})
+
+			actionID := actions[0].ID
+			jobs := make([]*cmpgn.ChangesetActionJob, 0, 3)
+
+			t.Run("CreateJobs", func(t *testing.T) {
+				for i := 0; i < cap(jobs); i++ {
+					j := &cmpgn.ChangesetActionJob{
+						ActionID:    actionID,
+						ChangesetID: int64(i + 1),
+					}
+
+					want := j.Clone()
+					have := j
+
+					err := s.CreateChangesetActionJob(ctx, have)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if have.ID == 0 {
+						t.Fatal("ID should not be zero")
+					}
+
+					want.ID = have.ID
+					want.CreatedAt = now
+					want.UpdatedAt = now
+
+					if diff := cmp.Diff(have, want); diff != "" {
+						t.Fatal(diff)
+					}
+
+					jobs = append(jobs, j)
+				}
+			})
+
+			t.Run("ListJobs", func(t *testing.T) {
+				for i := 1; i <= len(jobs); i++ {
+					opts := ListChangesetActionJobsOpts{ActionID: actionID, Limit: i}
+					have, next, err := s.ListChangesetActionJobs(ctx, opts)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					{
+						have, want := next, int64(0)
+						if i < len(jobs) {
+							want = jobs[i].ID
+						}
+
+						if have != want {
+							t.Fatalf("opts: %+v: have next %v, want %v", opts, have, want)
+						}
+					}
+
+					if diff := cmp.Diff(have, jobs[:i]); diff != "" {
+						t.Fatalf("opts: %+v, diff: %s", opts, diff)
+					}
+				}
+			})
+
+			t.Run("UpdateJobs", func(t *testing.T) {
+				jobs[0].StartedAt = now
+				jobs[0].FinishedAt = now
+				jobs[1].StartedAt = now
+				jobs[1].FinishedAt = now
+				jobs[1].Error = "label not found"
+
+				for _, j := range jobs[:2] {
+					want := j.Clone()
+					if err := s.UpdateChangesetActionJob(ctx, j); err != nil {
+						t.Fatal(err)
+					}
+					if diff := cmp.Diff(j, want); diff != "" {
+						t.Fatal(diff)
+					}
+				}
+			})
+
+			t.Run("GetChangesetActionStatus", func(t *testing.T) {
+				status, err := s.GetChangesetActionStatus(ctx, actionID)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want := &cmpgn.BackgroundProcessStatus{
+					ProcessState:  cmpgn.BackgroundProcessStateProcessing,
+					Total:         3,
+					Completed:     2,
+					Pending:       1,
+					ProcessErrors: []string{"label not found"},
+				}
+				if diff := cmp.Diff(status, want); diff != "" {
+					t.Fatalf("wrong diff: %s", diff)
+				}
+			})
+		})
+
 		t.Run("ChangesetJobs", func(t *testing.T) {
 			changesetJobs := make([]*cmpgn.ChangesetJob, 0, 3)
 
@@ -2723,6 +3075,50 @@ func testProcessChangesetJob(db *sql.DB) func(*testing.T) {
 			}
 		})
 
+		t.Run("GetPendingChangesetJobWithPublishPolicy", func(t *testing.T) {
+			tx := dbtest.NewTx(t, db)
+			s := NewStoreWithClock(tx, clock)
+
+			process := func(ctx context.Context, s *Store, job cmpgn.ChangesetJob) error {
+				return errors.New("rollback")
+			}
+
+			throttled := campaign.Clone()
+			throttled.PublishPolicy = &cmpgn.PublishPolicy{MaxChangesetsPerHour: 1}
+			if err := s.UpdateCampaign(ctx, throttled); err != nil {
+				t.Fatal(err)
+			}
+
+			job := &cmpgn.ChangesetJob{
+				CampaignID: campaign.ID,
+				PatchID:    patch.ID,
+			}
+			if err := s.CreateChangesetJob(ctx, job); err != nil {
+				t.Fatal(err)
+			}
+
+			ran, err := s.ProcessPendingChangesetJobs(ctx, process)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ran {
+				t.Fatalf("process function should not have run for unreleased job")
+			}
+
+			job.ReleasedAt = clock()
+			if err := s.UpdateChangesetJob(ctx, job); err != nil {
+				t.Fatal(err)
+			}
+
+			ran, err = s.ProcessPendingChangesetJobs(ctx, process)
+			if err != nil && err.Error() != "rollback" {
+				t.Fatal(err)
+			}
+			if !ran {
+				t.Fatalf("process function should have run for released job")
+			}
+		})
+
 		t.Run("GetPendingChangesetJobsWhenAvailableLocking", func(t *testing.T) {
 			s := NewStoreWithClock(db, clock)
 
@@ -11,6 +11,7 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 )
 
 // A ChangesetSyncer periodically syncs metadata of changesets
@@ -104,7 +105,7 @@ func (s *ChangesetSyncer) Run(ctx context.Context) {
 			}
 			s.queue.Upsert(schedule...)
 		case <-timerChan:
-			err := s.syncFunc(ctx, next.changesetID)
+			err := s.syncFunc(ratelimit.WithPriority(ctx, ratelimit.PriorityChangesetSync), next.changesetID)
 			if err != nil {
 				log15.Error("Syncing changeset", "err", err)
 				// We'll continue and remove it as it'll get retried on next schedule
@@ -2,6 +2,7 @@ package campaigns
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
 	"encoding/json"
 	"fmt"
@@ -20,8 +21,10 @@ import (
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
 	"github.com/sourcegraph/sourcegraph/internal/conf"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
 	bbs "github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/schema"
 )
 
@@ -31,7 +34,7 @@ type Webhook struct {
 	Now   func() time.Time
 
 	// ServiceType corresponds to api.ExternalRepoSpec.ServiceType
-	// Example values: bitbucketserver.ServiceType, github.ServiceType
+	// Example values: bitbucketserver.ServiceType, github.ServiceType, gitlab.ServiceType
 	ServiceType string
 }
 
@@ -79,6 +82,10 @@ func extractExternalServiceID(extSvc *repos.ExternalService) (string, error) {
 		serviceID = c.Url
 	case *schema.BitbucketServerConnection:
 		serviceID = c.Url
+	case *schema.GitLabConnection:
+		serviceID = c.Url
+	case *schema.BitbucketCloudConnection:
+		serviceID = c.Url
 	}
 	if serviceID == "" {
 		return "", errors.New("could not determine service id")
@@ -184,10 +191,32 @@ type BitbucketServerWebhook struct {
 	Name string
 }
 
+// GitLabWebhook receives GitLab project and group webhook events that are
+// relevant to campaigns, normalizes those events into ChangesetEvents
+// and upserts them to the database.
+type GitLabWebhook struct {
+	*Webhook
+}
+
+// BitbucketCloudWebhook receives Bitbucket Cloud repository webhook events
+// that are relevant to campaigns, normalizes those events into
+// ChangesetEvents and upserts them to the database.
+type BitbucketCloudWebhook struct {
+	*Webhook
+}
+
 func NewGitHubWebhook(store *Store, repos repos.Store, now func() time.Time) *GitHubWebhook {
 	return &GitHubWebhook{&Webhook{store, repos, now, github.ServiceType}}
 }
 
+func NewGitLabWebhook(store *Store, repos repos.Store, now func() time.Time) *GitLabWebhook {
+	return &GitLabWebhook{&Webhook{store, repos, now, gitlab.ServiceType}}
+}
+
+func NewBitbucketCloudWebhook(store *Store, repos repos.Store, now func() time.Time) *BitbucketCloudWebhook {
+	return &BitbucketCloudWebhook{&Webhook{store, repos, now, bitbucketcloud.ServiceType}}
+}
+
 func NewBitbucketServerWebhook(store *Store, repos repos.Store, now func() time.Time, name string) *BitbucketServerWebhook {
 	return &BitbucketServerWebhook{
 		Webhook: &Webhook{store, repos, now, bbs.ServiceType},
@@ -740,6 +769,7 @@ func (*GitHubWebhook) checkSuiteEvent(cs *gh.CheckSuite) *github.CheckSuite {
 func (*GitHubWebhook) checkRunEvent(cr *gh.CheckRun) *github.CheckRun {
 	return &github.CheckRun{
 		ID:         cr.GetNodeID(),
+		Name:       cr.GetName(),
 		Status:     cr.GetStatus(),
 		Conclusion: cr.GetConclusion(),
 		ReceivedAt: time.Now(),
@@ -902,6 +932,251 @@ func (h *BitbucketServerWebhook) convertEvent(theirs interface{}) (prs []PR, our
 	return
 }
 
+// ServeHTTP implements the http.Handler interface.
+func (h *GitLabWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e, extSvc, hErr := h.parseEvent(r)
+	if hErr != nil {
+		respond(w, hErr.code, hErr)
+		return
+	}
+
+	externalServiceID, err := extractExternalServiceID(extSvc)
+	if err != nil {
+		respond(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	prs, ev := h.convertEvent(e)
+	if len(prs) == 0 || ev == nil {
+		respond(w, http.StatusOK, nil) // Nothing to do
+		return
+	}
+
+	m := new(multierror.Error)
+	for _, pr := range prs {
+		err := h.upsertChangesetEvent(r.Context(), externalServiceID, pr, ev)
+		if err != nil {
+			m = multierror.Append(m, err)
+		}
+	}
+	if m.ErrorOrNil() != nil {
+		respond(w, http.StatusInternalServerError, m)
+	}
+}
+
+func (h *GitLabWebhook) parseEvent(r *http.Request) (interface{}, *repos.ExternalService, *httpError) {
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusInternalServerError, err}
+	}
+
+	// 🚨 SECURITY: Authenticate the request with the secret token of any of the
+	// webhooks in the GitLab external services config. GitLab sends the token
+	// itself rather than a signature, so it is compared in constant time.
+	// If no token matches, we return a 401 to the client.
+	args := repos.StoreListExternalServicesArgs{Kinds: []string{"GITLAB"}}
+	es, err := h.Repos.ListExternalServices(r.Context(), args)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusInternalServerError, err}
+	}
+
+	token := r.Header.Get(gitlab.WebhookTokenHeader)
+
+	var extSvc *repos.ExternalService
+	if token != "" {
+	outer:
+		for _, e := range es {
+			c, _ := e.Configuration()
+			con, ok := c.(*schema.GitLabConnection)
+			if !ok {
+				continue
+			}
+			for _, hook := range con.Webhooks {
+				if hook.Secret == "" {
+					continue
+				}
+				if subtle.ConstantTimeCompare([]byte(hook.Secret), []byte(token)) == 1 {
+					extSvc = e
+					break outer
+				}
+			}
+		}
+	}
+
+	if extSvc == nil {
+		return nil, nil, &httpError{http.StatusUnauthorized, nil}
+	}
+
+	e, err := gitlab.ParseWebhookEvent(gitlab.WebhookEventType(r), payload)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusBadRequest, err}
+	}
+	return e, extSvc, nil
+}
+
+func (h *GitLabWebhook) convertEvent(theirs interface{}) (prs []PR, ours interface{ Key() string }) {
+	log15.Debug("GitLab webhook received", "type", fmt.Sprintf("%T", theirs))
+
+	switch e := theirs.(type) {
+	case *gitlab.MergeRequestHookEvent:
+		ours = e.ToEvent()
+		if ours == nil {
+			return nil, nil
+		}
+		repoID := strconv.FormatInt(e.Project.ID, 10)
+		prs = append(prs, PR{ID: e.MergeRequestIID(), RepoExternalID: repoID})
+		return prs, ours
+
+	case *gitlab.NoteHookEvent:
+		ours = e.ToEvent()
+		if ours == nil {
+			return nil, nil
+		}
+		repoID := strconv.FormatInt(e.Project.ID, 10)
+		prs = append(prs, PR{ID: e.MergeRequest.IID, RepoExternalID: repoID})
+		return prs, ours
+
+	case *gitlab.PipelineHookEvent:
+		// Only pipelines for merge requests are relevant.
+		if e.MergeRequest == nil {
+			return nil, nil
+		}
+		repoID := strconv.FormatInt(e.Project.ID, 10)
+		prs = append(prs, PR{ID: e.MergeRequest.IID, RepoExternalID: repoID})
+		return prs, e.ToPipeline()
+	}
+
+	return nil, nil
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *BitbucketCloudWebhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	e, extSvc, hErr := h.parseEvent(r)
+	if hErr != nil {
+		respond(w, hErr.code, hErr)
+		return
+	}
+
+	externalServiceID, err := extractExternalServiceID(extSvc)
+	if err != nil {
+		respond(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	prs, ev := h.convertEvent(r.Context(), externalServiceID, e)
+	if len(prs) == 0 || ev == nil {
+		respond(w, http.StatusOK, nil) // Nothing to do
+		return
+	}
+
+	m := new(multierror.Error)
+	for _, pr := range prs {
+		err := h.upsertChangesetEvent(r.Context(), externalServiceID, pr, ev)
+		if err != nil {
+			m = multierror.Append(m, err)
+		}
+	}
+	if m.ErrorOrNil() != nil {
+		respond(w, http.StatusInternalServerError, m)
+	}
+}
+
+func (h *BitbucketCloudWebhook) parseEvent(r *http.Request) (interface{}, *repos.ExternalService, *httpError) {
+	payload, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusInternalServerError, err}
+	}
+
+	// 🚨 SECURITY: Bitbucket Cloud doesn't sign webhook requests, so the secret
+	// of the webhook is part of its URL. Authenticate the request with the
+	// secret of any of the webhooks in the Bitbucket Cloud external services
+	// config, compared in constant time. If no secret matches, we return a 401
+	// to the client.
+	args := repos.StoreListExternalServicesArgs{Kinds: []string{"BITBUCKETCLOUD"}}
+	es, err := h.Repos.ListExternalServices(r.Context(), args)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusInternalServerError, err}
+	}
+
+	secret := r.URL.Query().Get("secret")
+
+	var extSvc *repos.ExternalService
+	if secret != "" {
+	outer:
+		for _, e := range es {
+			c, _ := e.Configuration()
+			con, ok := c.(*schema.BitbucketCloudConnection)
+			if !ok {
+				continue
+			}
+			for _, hook := range con.Webhooks {
+				if hook.Secret == "" {
+					continue
+				}
+				if subtle.ConstantTimeCompare([]byte(hook.Secret), []byte(secret)) == 1 {
+					extSvc = e
+					break outer
+				}
+			}
+		}
+	}
+
+	if extSvc == nil {
+		return nil, nil, &httpError{http.StatusUnauthorized, nil}
+	}
+
+	e, err := bitbucketcloud.ParseWebhookEvent(bitbucketcloud.WebhookEventType(r), payload)
+	if err != nil {
+		return nil, nil, &httpError{http.StatusBadRequest, err}
+	}
+	return e, extSvc, nil
+}
+
+func (h *BitbucketCloudWebhook) convertEvent(ctx context.Context, externalServiceID string, theirs interface{}) (prs []PR, ours interface{ Key() string }) {
+	log15.Debug("Bitbucket Cloud webhook received", "type", fmt.Sprintf("%T", theirs))
+
+	switch e := theirs.(type) {
+	case *bitbucketcloud.PullRequestEvent:
+		a := e.ToActivity()
+		if a == nil {
+			return nil, nil
+		}
+		prs = append(prs, PR{ID: e.PullRequest.ID, RepoExternalID: e.Repository.UUID})
+		return prs, a
+
+	case *bitbucketcloud.CommitStatusEvent:
+		// Commit status events don't reference pull requests, so we find them
+		// by the branch of the status.
+		if e.CommitStatus.RefName == "" {
+			return nil, nil
+		}
+
+		spec := api.ExternalRepoSpec{
+			ID:          e.Repository.UUID,
+			ServiceID:   externalServiceID,
+			ServiceType: bitbucketcloud.ServiceType,
+		}
+
+		ids, err := h.Store.GetChangesetExternalIDs(ctx, spec, []string{e.CommitStatus.RefName})
+		if err != nil {
+			log15.Error("Error executing GetChangesetExternalIDs", "err", err)
+			return nil, nil
+		}
+
+		for _, id := range ids {
+			i, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				log15.Error("Error parsing external id", "err", err)
+				continue
+			}
+			prs = append(prs, PR{ID: i, RepoExternalID: e.Repository.UUID})
+		}
+		return prs, &e.CommitStatus
+	}
+
+	return nil, nil
+}
+
 type httpError struct {
 	code int
 	err  error
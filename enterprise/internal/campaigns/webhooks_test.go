@@ -9,10 +9,12 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httputil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -23,8 +25,11 @@ import (
 	"github.com/google/go-cmp/cmp"
 	gh "github.com/google/go-github/github"
 	"github.com/sourcegraph/sourcegraph/cmd/repo-updater/repos"
+	"github.com/sourcegraph/sourcegraph/internal/api"
 	"github.com/sourcegraph/sourcegraph/internal/campaigns"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/httptestutil"
 	"github.com/sourcegraph/sourcegraph/internal/rcache"
@@ -269,6 +274,716 @@ func testGitHubWebhook(db *sql.DB) func(*testing.T) {
 	}
 }
 
+// Ran in integration_test.go
+func testGitLabWebhook(db *sql.DB) func(*testing.T) {
+	return func(t *testing.T) {
+		now := time.Now()
+		clock := func() time.Time {
+			return now.UTC().Truncate(time.Microsecond)
+		}
+		now = clock()
+
+		ctx := context.Background()
+
+		var userID int32
+		err := db.QueryRow("INSERT INTO users (username) VALUES ('gitlab-admin') RETURNING id").Scan(&userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		secret := "secret"
+		repoStore := repos.NewDBStore(db, sql.TxOptions{})
+		gitlabExtSvc := &repos.ExternalService{
+			Kind:        "GITLAB",
+			DisplayName: "GitLab",
+			Config: marshalJSON(t, &schema.GitLabConnection{
+				Url:      "https://gitlab.com",
+				Token:    "token",
+				Webhooks: []*schema.GitLabWebhook{{Secret: secret}},
+			}),
+		}
+		if err := repoStore.UpsertExternalServices(ctx, gitlabExtSvc); err != nil {
+			t.Fatal(err)
+		}
+
+		gitlabRepo := &repos.Repo{
+			Name: "gitlab.com/sourcegraph/automation-testing",
+			ExternalRepo: api.ExternalRepoSpec{
+				ID:          "42",
+				ServiceType: gitlab.ServiceType,
+				ServiceID:   "https://gitlab.com/",
+			},
+			Sources: map[string]*repos.SourceInfo{
+				gitlabExtSvc.URN(): {
+					ID:       gitlabExtSvc.URN(),
+					CloneURL: "https://gitlab.com/sourcegraph/automation-testing.git",
+				},
+			},
+		}
+		if err := repoStore.UpsertRepos(ctx, gitlabRepo); err != nil {
+			t.Fatal(err)
+		}
+
+		store := NewStoreWithClock(db, clock)
+
+		campaign := &campaigns.Campaign{
+			Name:            "Test GitLab campaign",
+			Description:     "Testing the GitLab webhooks",
+			AuthorID:        userID,
+			NamespaceUserID: userID,
+		}
+		if err := store.CreateCampaign(ctx, campaign); err != nil {
+			t.Fatal(err)
+		}
+
+		changeset := &campaigns.Changeset{
+			RepoID:      gitlabRepo.ID,
+			CampaignIDs: []int64{campaign.ID},
+		}
+		if err := changeset.SetMetadata(&gitlab.MergeRequest{
+			IID:          7,
+			State:        gitlab.MergeRequestStateOpened,
+			SourceBranch: "campaign",
+			TargetBranch: "master",
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateChangesets(ctx, changeset); err != nil {
+			t.Fatal(err)
+		}
+
+		hook := NewGitLabWebhook(store, repoStore, clock)
+		note := `{
+			"user": {"name": "Alice", "username": "alice"},
+			"project": {"id": %d},
+			"merge_request": {"iid": 7},
+			"object_attributes": {
+				"id": 123,
+				"note": %q,
+				"noteable_type": "MergeRequest",
+				"created_at": "2020-04-01T10:00:00Z",
+				"updated_at": "2020-04-01T10:00:00Z"
+			}
+		}`
+
+		for _, tc := range []struct {
+			name  string
+			token string
+			event string
+			body  string
+			code  int
+			want  []string
+		}{
+			{
+				name:  "missing token",
+				event: "Note Hook",
+				body:  fmt.Sprintf(note, 42, "LGTM"),
+				code:  http.StatusUnauthorized,
+			},
+			{
+				name:  "wrong token",
+				token: "wrong-secret",
+				event: "Note Hook",
+				body:  fmt.Sprintf(note, 42, "LGTM"),
+				code:  http.StatusUnauthorized,
+			},
+			{
+				name:  "non-existent-repo",
+				token: secret,
+				event: "Note Hook",
+				body:  fmt.Sprintf(note, 43, "LGTM"),
+				code:  http.StatusOK,
+			},
+			{
+				name:  "non-existent-changeset-event",
+				token: secret,
+				event: "Note Hook",
+				body:  fmt.Sprintf(note, 42, "LGTM"),
+				code:  http.StatusOK,
+				want:  []string{"LGTM"},
+			},
+			{
+				name:  "existent-changeset-event",
+				token: secret,
+				event: "Note Hook",
+				body:  fmt.Sprintf(note, 42, "LGTM, edited"),
+				code:  http.StatusOK,
+				want:  []string{"LGTM, edited"},
+			},
+		} {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				req, err := http.NewRequest("POST", "", strings.NewReader(tc.body))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				req.Header.Set("X-Gitlab-Event", tc.event)
+				if tc.token != "" {
+					req.Header.Set(gitlab.WebhookTokenHeader, tc.token)
+				}
+
+				rec := httptest.NewRecorder()
+				hook.ServeHTTP(rec, req)
+				resp := rec.Result()
+
+				if tc.code != resp.StatusCode {
+					bs, err := httputil.DumpResponse(resp, true)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					t.Log(string(bs))
+					t.Errorf("have status code %d, want %d", resp.StatusCode, tc.code)
+				}
+
+				events, _, err := store.ListChangesetEvents(ctx, ListChangesetEventsOpts{
+					ChangesetIDs: []int64{changeset.ID},
+					Limit:        -1,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var have []string
+				for _, e := range events {
+					if e.Kind != campaigns.ChangesetEventKindGitLabCommented || e.Key != "123" {
+						t.Errorf("unexpected event %+v", e)
+					}
+					have = append(have, e.Metadata.(*gitlab.Note).Body)
+				}
+				if diff := cmp.Diff(have, tc.want); diff != "" {
+					t.Error(diff)
+				}
+			})
+		}
+	}
+}
+
+// Ran in integration_test.go
+func testBitbucketCloudWebhook(db *sql.DB) func(*testing.T) {
+	return func(t *testing.T) {
+		now := time.Now()
+		clock := func() time.Time {
+			return now.UTC().Truncate(time.Microsecond)
+		}
+		now = clock()
+
+		ctx := context.Background()
+
+		var userID int32
+		err := db.QueryRow("INSERT INTO users (username) VALUES ('bitbucket-cloud-admin') RETURNING id").Scan(&userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		secret := "secret"
+		repoStore := repos.NewDBStore(db, sql.TxOptions{})
+		// The URL of the code host is configured without the trailing slash of
+		// the normalized service ID of its repos.
+		bbcExtSvc := &repos.ExternalService{
+			Kind:        "BITBUCKETCLOUD",
+			DisplayName: "Bitbucket Cloud",
+			Config: marshalJSON(t, &schema.BitbucketCloudConnection{
+				Url:         "https://bitbucket.org",
+				Username:    "user",
+				AppPassword: "password",
+				Webhooks:    []*schema.BitbucketCloudWebhook{{Secret: secret}},
+			}),
+		}
+		if err := repoStore.UpsertExternalServices(ctx, bbcExtSvc); err != nil {
+			t.Fatal(err)
+		}
+
+		bbcRepo := &repos.Repo{
+			Name: "bitbucket.org/sourcegraph/automation-testing",
+			ExternalRepo: api.ExternalRepoSpec{
+				ID:          "{repo-uuid}",
+				ServiceType: bitbucketcloud.ServiceType,
+				ServiceID:   "https://bitbucket.org/",
+			},
+			Sources: map[string]*repos.SourceInfo{
+				bbcExtSvc.URN(): {
+					ID:       bbcExtSvc.URN(),
+					CloneURL: "https://bitbucket.org/sourcegraph/automation-testing.git",
+				},
+			},
+		}
+		if err := repoStore.UpsertRepos(ctx, bbcRepo); err != nil {
+			t.Fatal(err)
+		}
+
+		store := NewStoreWithClock(db, clock)
+
+		campaign := &campaigns.Campaign{
+			Name:            "Test Bitbucket Cloud campaign",
+			Description:     "Testing the Bitbucket Cloud webhooks",
+			AuthorID:        userID,
+			NamespaceUserID: userID,
+		}
+		if err := store.CreateCampaign(ctx, campaign); err != nil {
+			t.Fatal(err)
+		}
+
+		changeset := &campaigns.Changeset{
+			RepoID:      bbcRepo.ID,
+			CampaignIDs: []int64{campaign.ID},
+		}
+		if err := changeset.SetMetadata(&bitbucketcloud.PullRequest{
+			ID:          7,
+			State:       bitbucketcloud.PullRequestStateOpen,
+			Source:      bitbucketcloud.PullRequestEndpoint{Branch: bitbucketcloud.PullRequestBranch{Name: "campaign"}},
+			Destination: bitbucketcloud.PullRequestEndpoint{Branch: bitbucketcloud.PullRequestBranch{Name: "master"}},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.CreateChangesets(ctx, changeset); err != nil {
+			t.Fatal(err)
+		}
+
+		hook := NewBitbucketCloudWebhook(store, repoStore, clock)
+		approved := `{
+			"pullrequest": {"id": 7},
+			"repository": {"full_name": "sourcegraph/automation-testing", "uuid": "{repo-uuid}"},
+			"approval": {"date": "2020-04-01T10:00:00Z", "user": {"uuid": "{user-uuid}", "display_name": "Alice"}}
+		}`
+		// Commit status events don't reference the pull request, which is
+		// looked up by the branch of the status.
+		commitStatus := `{
+			"repository": {"full_name": "sourcegraph/automation-testing", "uuid": "{repo-uuid}"},
+			"commit_status": {
+				"key": "ci",
+				"state": "SUCCESSFUL",
+				"refname": %q,
+				"links": {"commit": {"href": "https://api.bitbucket.org/2.0/repositories/sourcegraph/automation-testing/commit/deadbeef"}}
+			}
+		}`
+
+		for _, tc := range []struct {
+			name   string
+			secret string
+			event  string
+			body   string
+			code   int
+			want   []campaigns.ChangesetEventKind
+		}{
+			{
+				name:  "missing secret",
+				event: "pullrequest:approved",
+				body:  approved,
+				code:  http.StatusUnauthorized,
+			},
+			{
+				name:   "wrong secret",
+				secret: "wrong-secret",
+				event:  "pullrequest:approved",
+				body:   approved,
+				code:   http.StatusUnauthorized,
+			},
+			{
+				name:   "pull request event",
+				secret: secret,
+				event:  "pullrequest:approved",
+				body:   approved,
+				code:   http.StatusOK,
+				want:   []campaigns.ChangesetEventKind{campaigns.ChangesetEventKindBitbucketCloudApproved},
+			},
+			{
+				name:   "commit status of another branch",
+				secret: secret,
+				event:  "repo:commit_status_created",
+				body:   fmt.Sprintf(commitStatus, "other"),
+				code:   http.StatusOK,
+				want:   []campaigns.ChangesetEventKind{campaigns.ChangesetEventKindBitbucketCloudApproved},
+			},
+			{
+				name:   "commit status of the branch of the pull request",
+				secret: secret,
+				event:  "repo:commit_status_created",
+				body:   fmt.Sprintf(commitStatus, "campaign"),
+				code:   http.StatusOK,
+				want: []campaigns.ChangesetEventKind{
+					campaigns.ChangesetEventKindBitbucketCloudApproved,
+					campaigns.ChangesetEventKindBitbucketCloudCommitStatus,
+				},
+			},
+		} {
+			tc := tc
+			t.Run(tc.name, func(t *testing.T) {
+				target := "/.api/bitbucket-cloud-webhooks"
+				if tc.secret != "" {
+					target += "?secret=" + url.QueryEscape(tc.secret)
+				}
+				req := httptest.NewRequest("POST", target, strings.NewReader(tc.body))
+				req.Header.Set("X-Event-Key", tc.event)
+
+				rec := httptest.NewRecorder()
+				hook.ServeHTTP(rec, req)
+				resp := rec.Result()
+
+				if tc.code != resp.StatusCode {
+					bs, err := httputil.DumpResponse(resp, true)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					t.Log(string(bs))
+					t.Errorf("have status code %d, want %d", resp.StatusCode, tc.code)
+				}
+
+				events, _, err := store.ListChangesetEvents(ctx, ListChangesetEventsOpts{
+					ChangesetIDs: []int64{changeset.ID},
+					Limit:        -1,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var have []campaigns.ChangesetEventKind
+				for _, e := range events {
+					have = append(have, e.Kind)
+				}
+				if diff := cmp.Diff(have, tc.want); diff != "" {
+					t.Error(diff)
+				}
+			})
+		}
+
+		t.Run("commit status branch lookup", func(t *testing.T) {
+			for branch, want := range map[string][]PR{
+				"campaign": {{ID: 7, RepoExternalID: "{repo-uuid}"}},
+				"other":    nil,
+				"":         nil,
+			} {
+				e, err := bitbucketcloud.ParseWebhookEvent("repo:commit_status_updated", []byte(fmt.Sprintf(commitStatus, branch)))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				prs, ev := hook.convertEvent(ctx, "https://bitbucket.org/", e)
+				if diff := cmp.Diff(prs, want); diff != "" {
+					t.Errorf("branch %q: %s", branch, diff)
+				}
+				if len(want) > 0 && ev == nil {
+					t.Errorf("branch %q: no event", branch)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractExternalServiceID(t *testing.T) {
+	for _, tc := range []struct {
+		kind   string
+		config interface{}
+		want   string
+	}{
+		{"GITHUB", &schema.GitHubConnection{Url: "https://github.com"}, "https://github.com/"},
+		{"GITLAB", &schema.GitLabConnection{Url: "https://GitLab.example.com/gitlab"}, "https://gitlab.example.com/gitlab/"},
+		{"BITBUCKETSERVER", &schema.BitbucketServerConnection{Url: "https://bitbucket.example.com/"}, "https://bitbucket.example.com/"},
+		{"BITBUCKETCLOUD", &schema.BitbucketCloudConnection{Url: "https://bitbucket.org"}, "https://bitbucket.org/"},
+	} {
+		t.Run(tc.kind, func(t *testing.T) {
+			have, err := extractExternalServiceID(&repos.ExternalService{Kind: tc.kind, Config: marshalJSON(t, tc.config)})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if have != tc.want {
+				t.Errorf("have service ID %q, want %q", have, tc.want)
+			}
+		})
+	}
+}
+
+func TestBitbucketCloudWebhook_parseEvent(t *testing.T) {
+	ctx := context.Background()
+
+	repoStore := new(repos.FakeStore)
+	withSecret := &repos.ExternalService{
+		Kind:   "BITBUCKETCLOUD",
+		Config: marshalJSON(t, &schema.BitbucketCloudConnection{Url: "https://bitbucket.org", Webhooks: []*schema.BitbucketCloudWebhook{{Secret: "secret"}}}),
+	}
+	withoutSecret := &repos.ExternalService{
+		Kind:   "BITBUCKETCLOUD",
+		Config: marshalJSON(t, &schema.BitbucketCloudConnection{Url: "https://bitbucket.org", Webhooks: []*schema.BitbucketCloudWebhook{{Secret: ""}}}),
+	}
+	gitlabSvc := &repos.ExternalService{
+		Kind:   "GITLAB",
+		Config: marshalJSON(t, &schema.GitLabConnection{Url: "https://gitlab.com", Webhooks: []*schema.GitLabWebhook{{Secret: "gitlab-secret"}}}),
+	}
+	if err := repoStore.UpsertExternalServices(ctx, withSecret, withoutSecret, gitlabSvc); err != nil {
+		t.Fatal(err)
+	}
+
+	hook := NewBitbucketCloudWebhook(nil, repoStore, time.Now)
+	body := `{"pullrequest": {"id": 7}, "repository": {"uuid": "{repo-uuid}"}, "approval": {"user": {"uuid": "{user-uuid}"}}}`
+
+	for _, tc := range []struct {
+		name   string
+		target string
+		code   int
+		extSvc *repos.ExternalService
+	}{
+		{name: "missing secret", target: "/", code: http.StatusUnauthorized},
+		{name: "empty secret", target: "/?secret=", code: http.StatusUnauthorized},
+		{name: "wrong secret", target: "/?secret=wrong-secret", code: http.StatusUnauthorized},
+		{name: "secret of another kind of code host", target: "/?secret=gitlab-secret", code: http.StatusUnauthorized},
+		{name: "matching secret", target: "/?secret=secret", extSvc: withSecret},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tc.target, strings.NewReader(body))
+			req.Header.Set("X-Event-Key", "pullrequest:approved")
+
+			e, extSvc, hErr := hook.parseEvent(req)
+			if tc.code != 0 {
+				if hErr == nil || hErr.code != tc.code {
+					t.Fatalf("have error %v, want status code %d", hErr, tc.code)
+				}
+
+				rec := httptest.NewRecorder()
+				hook.ServeHTTP(rec, req)
+				if have := rec.Result().StatusCode; have != tc.code {
+					t.Errorf("have status code %d, want %d", have, tc.code)
+				}
+				return
+			}
+			if hErr != nil {
+				t.Fatal(hErr)
+			}
+
+			if extSvc != tc.extSvc {
+				t.Errorf("have external service %+v, want %+v", extSvc, tc.extSvc)
+			}
+			ev, ok := e.(*bitbucketcloud.PullRequestEvent)
+			if !ok {
+				t.Fatalf("unexpected event %T", e)
+			}
+			if ev.PullRequest.ID != 7 || ev.Repository.UUID != "{repo-uuid}" || ev.Approval == nil {
+				t.Errorf("unexpected event %+v", ev)
+			}
+		})
+	}
+}
+
+func TestBitbucketCloudWebhook_convertEvent(t *testing.T) {
+	parse := func(eventType, payload string) interface{} {
+		t.Helper()
+		e, err := bitbucketcloud.ParseWebhookEvent(eventType, []byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	// The store is only used to look up the pull requests of commit statuses
+	// with a branch, which is tested in testBitbucketCloudWebhook.
+	hook := NewBitbucketCloudWebhook(nil, nil, time.Now)
+
+	for _, tc := range []struct {
+		name  string
+		event interface{}
+		prs   []PR
+		kind  campaigns.ChangesetEventKind
+	}{
+		{
+			name:  "approved pull request",
+			event: parse("pullrequest:approved", `{"pullrequest": {"id": 7}, "repository": {"uuid": "{repo-uuid}"}, "approval": {"user": {"uuid": "{user-uuid}"}}}`),
+			prs:   []PR{{ID: 7, RepoExternalID: "{repo-uuid}"}},
+			kind:  campaigns.ChangesetEventKindBitbucketCloudApproved,
+		},
+		{
+			name:  "approved pull request without approval",
+			event: parse("pullrequest:approved", `{"pullrequest": {"id": 7}, "repository": {"uuid": "{repo-uuid}"}}`),
+		},
+		{
+			name:  "merged pull request",
+			event: parse("pullrequest:fulfilled", `{"pullrequest": {"id": 7}, "repository": {"uuid": "{repo-uuid}"}}`),
+			prs:   []PR{{ID: 7, RepoExternalID: "{repo-uuid}"}},
+			kind:  campaigns.ChangesetEventKindBitbucketCloudMerged,
+		},
+		{
+			name:  "commit status without branch",
+			event: parse("repo:commit_status_created", `{"repository": {"uuid": "{repo-uuid}"}, "commit_status": {"key": "ci", "state": "SUCCESSFUL"}}`),
+		},
+		{
+			name:  "unrelated event",
+			event: parse("repo:push", `{}`),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			prs, ev := hook.convertEvent(context.Background(), "https://bitbucket.org/", tc.event)
+			if diff := cmp.Diff(prs, tc.prs); diff != "" {
+				t.Errorf("wrong PRs: %s", diff)
+			}
+			if tc.kind == "" {
+				if ev != nil {
+					t.Errorf("unexpected event %+v", ev)
+				}
+				return
+			}
+			if ev == nil {
+				t.Fatal("no event")
+			}
+			if have := campaigns.ChangesetEventKindFor(ev); have != tc.kind {
+				t.Errorf("have event kind %q, want %q", have, tc.kind)
+			}
+		})
+	}
+}
+
+func TestGitLabWebhook_parseEvent(t *testing.T) {
+	ctx := context.Background()
+
+	repoStore := new(repos.FakeStore)
+	withSecret := &repos.ExternalService{
+		Kind:   "GITLAB",
+		Config: marshalJSON(t, &schema.GitLabConnection{Url: "https://gitlab.com", Webhooks: []*schema.GitLabWebhook{{Secret: "secret"}}}),
+	}
+	withoutSecret := &repos.ExternalService{
+		Kind:   "GITLAB",
+		Config: marshalJSON(t, &schema.GitLabConnection{Url: "https://gitlab.example.com", Webhooks: []*schema.GitLabWebhook{{Secret: ""}}}),
+	}
+	githubSvc := &repos.ExternalService{
+		Kind:   "GITHUB",
+		Config: marshalJSON(t, &schema.GitHubConnection{Url: "https://github.com", Webhooks: []*schema.GitHubWebhook{{Org: "sourcegraph", Secret: "github-secret"}}}),
+	}
+	if err := repoStore.UpsertExternalServices(ctx, withSecret, withoutSecret, githubSvc); err != nil {
+		t.Fatal(err)
+	}
+
+	hook := NewGitLabWebhook(nil, repoStore, time.Now)
+	body := `{"project": {"id": 42}, "object_attributes": {"iid": 7, "action": "merge", "updated_at": "2020-04-01 10:00:00 UTC"}}`
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		code   int
+		extSvc *repos.ExternalService
+	}{
+		{name: "missing token", code: http.StatusUnauthorized},
+		{name: "wrong token", token: "wrong-secret", code: http.StatusUnauthorized},
+		{name: "token of another kind of code host", token: "github-secret", code: http.StatusUnauthorized},
+		{name: "matching token", token: "secret", extSvc: withSecret},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			req, err := http.NewRequest("POST", "", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("X-Gitlab-Event", "Merge Request Hook")
+			if tc.token != "" {
+				req.Header.Set(gitlab.WebhookTokenHeader, tc.token)
+			}
+
+			e, extSvc, hErr := hook.parseEvent(req)
+			if tc.code != 0 {
+				if hErr == nil || hErr.code != tc.code {
+					t.Fatalf("have error %v, want status code %d", hErr, tc.code)
+				}
+
+				rec := httptest.NewRecorder()
+				hook.ServeHTTP(rec, req)
+				if have := rec.Result().StatusCode; have != tc.code {
+					t.Errorf("have status code %d, want %d", have, tc.code)
+				}
+				return
+			}
+			if hErr != nil {
+				t.Fatal(hErr)
+			}
+
+			if extSvc != tc.extSvc {
+				t.Errorf("have external service %+v, want %+v", extSvc, tc.extSvc)
+			}
+			ev, ok := e.(*gitlab.MergeRequestHookEvent)
+			if !ok {
+				t.Fatalf("unexpected event %T", e)
+			}
+			if ev.Project.ID != 42 || ev.MergeRequestIID() != 7 || ev.ObjectAttributes.Action != "merge" {
+				t.Errorf("unexpected event %+v", ev)
+			}
+		})
+	}
+}
+
+func TestGitLabWebhook_convertEvent(t *testing.T) {
+	parse := func(eventType, payload string) interface{} {
+		t.Helper()
+		e, err := gitlab.ParseWebhookEvent(eventType, []byte(payload))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+
+	hook := NewGitLabWebhook(nil, nil, time.Now)
+
+	for _, tc := range []struct {
+		name  string
+		event interface{}
+		prs   []PR
+		kind  campaigns.ChangesetEventKind
+	}{
+		{
+			name:  "merged merge request",
+			event: parse("Merge Request Hook", `{"project": {"id": 42}, "object_attributes": {"iid": 7, "action": "merge"}}`),
+			prs:   []PR{{ID: 7, RepoExternalID: "42"}},
+			kind:  campaigns.ChangesetEventKindGitLabMerged,
+		},
+		{
+			name:  "updated merge request",
+			event: parse("Merge Request Hook", `{"project": {"id": 42}, "object_attributes": {"iid": 7, "action": "update"}}`),
+		},
+		{
+			name:  "comment on merge request",
+			event: parse("Note Hook", `{"project": {"id": 42}, "merge_request": {"iid": 7}, "object_attributes": {"id": 1, "note": "LGTM", "noteable_type": "MergeRequest"}}`),
+			prs:   []PR{{ID: 7, RepoExternalID: "42"}},
+			kind:  campaigns.ChangesetEventKindGitLabCommented,
+		},
+		{
+			name:  "comment on issue",
+			event: parse("Note Hook", `{"project": {"id": 42}, "object_attributes": {"id": 1, "note": "LGTM", "noteable_type": "Issue"}}`),
+		},
+		{
+			name:  "pipeline of merge request",
+			event: parse("Pipeline Hook", `{"project": {"id": 42}, "merge_request": {"iid": 7}, "object_attributes": {"id": 3, "status": "success"}}`),
+			prs:   []PR{{ID: 7, RepoExternalID: "42"}},
+			kind:  campaigns.ChangesetEventKindGitLabPipeline,
+		},
+		{
+			name:  "pipeline of branch",
+			event: parse("Pipeline Hook", `{"project": {"id": 42}, "object_attributes": {"id": 3, "status": "success"}}`),
+		},
+		{
+			name:  "unrelated event",
+			event: parse("Push Hook", `{}`),
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			prs, ev := hook.convertEvent(tc.event)
+			if diff := cmp.Diff(prs, tc.prs); diff != "" {
+				t.Errorf("wrong PRs: %s", diff)
+			}
+			if tc.kind == "" {
+				if ev != nil {
+					t.Errorf("unexpected event %+v", ev)
+				}
+				return
+			}
+			if ev == nil {
+				t.Fatal("no event")
+			}
+			if have := campaigns.ChangesetEventKindFor(ev); have != tc.kind {
+				t.Errorf("have event kind %q, want %q", have, tc.kind)
+			}
+		})
+	}
+}
+
 type event struct {
 	name  string
 	event interface{}
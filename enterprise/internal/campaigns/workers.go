@@ -30,11 +30,6 @@ const defaultWorkerCount = 8
 // for finding pending ChangesetJobs and executing them.
 // ctx should be canceled to terminate the function.
 func RunWorkers(ctx context.Context, s *Store, clock func() time.Time, gitClient GitserverClient, sourcer repos.Sourcer, backoffDuration time.Duration) {
-	workerCount, err := strconv.Atoi(maxWorkers)
-	if err != nil {
-		log15.Error("Parsing max worker count failed. Falling back to default.", "default", defaultWorkerCount, "err", err)
-		workerCount = defaultWorkerCount
-	}
 	process := func(ctx context.Context, s *Store, job campaigns.ChangesetJob) error {
 		c, err := s.GetCampaign(ctx, GetCampaignOpts{
 			ID: job.CampaignID,
@@ -67,11 +62,22 @@ func RunWorkers(ctx context.Context, s *Store, clock func() time.Time, gitClient
 			}
 		}
 	}
-	for i := 0; i < workerCount; i++ {
+	for i := 0; i < workerCount(); i++ {
 		go worker()
 	}
 }
 
+// workerCount returns the number of workers to run in parallel, as
+// configured by CAMPAIGNS_MAX_WORKERS.
+func workerCount() int {
+	n, err := strconv.Atoi(maxWorkers)
+	if err != nil {
+		log15.Error("Parsing max worker count failed. Falling back to default.", "default", defaultWorkerCount, "err", err)
+		return defaultWorkerCount
+	}
+	return n
+}
+
 // ExecChangesetJob will execute the given ChangesetJob for the given campaign.
 // It is idempotent and if the job has already been executed it will not be
 // executed.
@@ -154,34 +160,9 @@ func ExecChangesetJob(
 		ensureUniqueRef = false
 	}
 
-	ref, err := gitClient.CreateCommitFromPatch(ctx, protocol.CreateCommitFromPatchRequest{
-		Repo:       api.RepoName(repo.Name),
-		BaseCommit: patch.Rev,
-		// IMPORTANT: We add a trailing newline here, otherwise `git apply`
-		// will fail with "corrupt patch at line <N>" where N is the last line.
-		Patch:     patch.Diff + "\n",
-		TargetRef: branch,
-		UniqueRef: ensureUniqueRef,
-		CommitInfo: protocol.PatchCommitInfo{
-			Message:     c.Name,
-			AuthorName:  "Sourcegraph Bot",
-			AuthorEmail: "campaigns@sourcegraph.com",
-			Date:        job.CreatedAt,
-		},
-		// We use unified diffs, not git diffs, which means they're missing the
-		// `a/` and `/b` filename prefixes. `-p0` tells `git apply` to not
-		// expect and strip prefixes.
-		// Since we also produce diffs manually, we might not have context lines,
-		// so we need to disable that check with `--unidiff-zero`.
-		GitApplyArgs: []string{"-p0", "--unidiff-zero"},
-		Push:         true,
-	})
+	ref, err := gitClient.CreateCommitFromPatch(ctx, createCommitFromPatchRequest(repo, c, patch, patch.Rev, branch, ensureUniqueRef, job.CreatedAt))
 	if err != nil {
-		if diffErr, ok := err.(*protocol.CreateCommitFromPatchError); ok {
-			return errors.Errorf("creating commit from patch for repo %q: %q (command: %q, output: %q)",
-				diffErr.RepositoryName, diffErr.InternalError, diffErr.Command, diffErr.CombinedOutput)
-		}
-		return err
+		return createCommitFromPatchError(err)
 	}
 	if job.Branch != "" && job.Branch != ref {
 		return fmt.Errorf("ref %q doesn't match ChangesetJob's branch %q", ref, job.Branch)
@@ -212,6 +193,14 @@ func ExecChangesetJob(
 				if cfg.Token != "" {
 					externalService = e
 				}
+			case *schema.GitLabConnection:
+				if cfg.Token != "" {
+					externalService = e
+				}
+			case *schema.BitbucketCloudConnection:
+				if cfg.AppPassword != "" {
+					externalService = e
+				}
 			}
 			if externalService != nil {
 				break
@@ -262,7 +251,13 @@ func ExecChangesetJob(
 		return errors.Wrap(err, "creating changeset")
 	}
 	// If the Changeset already exists and our source can update it, we try to update it
-	if exists {
+	if !exists {
+		// Failing to request reviews shouldn't fail the job, since the
+		// Changeset has been created already.
+		if err := requestChangesetReviews(ctx, ccs, c, patch, &cs); err != nil {
+			log15.Error("Requesting changeset reviews", "jobID", job.ID, "repo", repo.Name, "err", err)
+		}
+	} else {
 		outdated, err := isOutdated(&cs)
 		if err != nil {
 			return errors.Wrap(err, "could not determine whether changeset needs update")
@@ -322,3 +317,48 @@ func ExecChangesetJob(
 	runFinalUpdate(ctx, store)
 	return
 }
+
+// createCommitFromPatchRequest returns the request to apply the given Patch
+// on top of base and push the resulting commit to branch.
+func createCommitFromPatchRequest(
+	repo *repos.Repo,
+	c *campaigns.Campaign,
+	patch *campaigns.Patch,
+	base api.CommitID,
+	branch string,
+	uniqueRef bool,
+	date time.Time,
+) protocol.CreateCommitFromPatchRequest {
+	return protocol.CreateCommitFromPatchRequest{
+		Repo:       api.RepoName(repo.Name),
+		BaseCommit: base,
+		// IMPORTANT: We add a trailing newline here, otherwise `git apply`
+		// will fail with "corrupt patch at line <N>" where N is the last line.
+		Patch:     patch.Diff + "\n",
+		TargetRef: branch,
+		UniqueRef: uniqueRef,
+		CommitInfo: protocol.PatchCommitInfo{
+			Message:     c.Name,
+			AuthorName:  "Sourcegraph Bot",
+			AuthorEmail: "campaigns@sourcegraph.com",
+			Date:        date,
+		},
+		// We use unified diffs, not git diffs, which means they're missing the
+		// `a/` and `/b` filename prefixes. `-p0` tells `git apply` to not
+		// expect and strip prefixes.
+		// Since we also produce diffs manually, we might not have context lines,
+		// so we need to disable that check with `--unidiff-zero`.
+		GitApplyArgs: []string{"-p0", "--unidiff-zero"},
+		Push:         true,
+	}
+}
+
+// createCommitFromPatchError adds the details of a failed `git` command to
+// errors returned by CreateCommitFromPatch.
+func createCommitFromPatchError(err error) error {
+	if diffErr, ok := err.(*protocol.CreateCommitFromPatchError); ok {
+		return errors.Errorf("creating commit from patch for repo %q: %q (command: %q, output: %q)",
+			diffErr.RepositoryName, diffErr.InternalError, diffErr.Command, diffErr.CombinedOutput)
+	}
+	return err
+}
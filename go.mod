@@ -34,7 +34,9 @@ require (
 	github.com/gin-gonic/gin v1.5.0 // indirect
 	github.com/gitchander/permutation v0.0.0-20181107151852-9e56b92e9909
 	github.com/glycerine/go-unsnap-stream v0.0.0-20190901134440-81cf024a9e0a // indirect
+	github.com/go-asn1-ber/asn1-ber v1.3.1
 	github.com/go-delve/delve v1.4.0
+	github.com/go-ldap/ldap/v3 v3.1.10
 	github.com/go-playground/universal-translator v0.17.0 // indirect
 	github.com/go-redsync/redsync v1.3.1
 	github.com/gobwas/glob v0.2.3
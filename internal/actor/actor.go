@@ -24,6 +24,11 @@ type Actor struct {
 	// to selectively display a logout link. (If the actor wasn't authenticated with a session
 	// cookie, logout would be ineffective.)
 	FromSessionCookie bool `json:"-"`
+
+	// Scopes are the scopes of the access token used to authenticate the actor, if the token
+	// restricts the actor to some actions (i.e., it doesn't have the "user:all" scope). It is nil
+	// for actors that are not restricted.
+	Scopes []string `json:"-"`
 }
 
 // FromUser returns an actor corresponding to a user
@@ -36,6 +41,26 @@ func (a *Actor) String() string {
 	return fmt.Sprintf("Actor UID %d, internal %t", a.UID, a.Internal)
 }
 
+// IsRestricted returns true if the actor is restricted to the actions granted by the scopes of the
+// access token used to authenticate it.
+func (a *Actor) IsRestricted() bool {
+	return a != nil && a.Scopes != nil
+}
+
+// HasScope returns true if the actor is not restricted, or if the access token used to authenticate
+// it has the scope.
+func (a *Actor) HasScope(scope string) bool {
+	if !a.IsRestricted() {
+		return true
+	}
+	for _, s := range a.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
 // IsAuthenticated returns true if the Actor is derived from an authenticated user.
 func (a *Actor) IsAuthenticated() bool {
 	return a != nil && a.UID != 0
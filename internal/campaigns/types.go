@@ -12,8 +12,10 @@ import (
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/internal/api"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 // SupportedExternalServices are the external service types currently supported
@@ -23,6 +25,8 @@ import (
 var SupportedExternalServices = map[string]struct{}{
 	github.ServiceType:          {},
 	bitbucketserver.ServiceType: {},
+	gitlab.ServiceType:          {},
+	bitbucketcloud.ServiceType:  {},
 }
 
 // IsRepoSupported returns whether the given ExternalRepoSpec is supported by
@@ -70,6 +74,37 @@ func (c *Patch) Clone() *Patch {
 	return &cc
 }
 
+// A PatchJob is the server-side computation of a Patch by rewriting the
+// files of a repository at a specific revision with a comby match and
+// rewrite template.
+type PatchJob struct {
+	ID         int64
+	PatchSetID int64
+
+	RepoID  api.RepoID
+	Rev     api.CommitID
+	BaseRef string
+
+	MatchTemplate    string
+	RewriteTemplate  string
+	FileExtension    string
+	DirectoryExclude string
+
+	Error string
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Clone returns a clone of a PatchJob.
+func (c *PatchJob) Clone() *PatchJob {
+	cc := *c
+	return &cc
+}
+
 // A Campaign of changesets over multiple Repos over time.
 type Campaign struct {
 	ID              int64
@@ -84,12 +119,31 @@ type Campaign struct {
 	ChangesetIDs    []int64
 	PatchSetID      int64
 	ClosedAt        time.Time
+	// AutoMerge is the policy by which the Campaign's Changesets are merged
+	// automatically. It's nil if auto-merge is disabled.
+	AutoMerge *AutoMergePolicy
+	// PublishPolicy limits the rate at which the Campaign's Changesets are
+	// published. It's nil if they're published as fast as possible.
+	PublishPolicy *PublishPolicy
+	// ReviewerPolicy overrides who is asked to review the Campaign's
+	// Changesets and who they are assigned to. If nil, the code owners of the
+	// changed files are.
+	ReviewerPolicy *ReviewerPolicy
 }
 
 // Clone returns a clone of a Campaign.
 func (c *Campaign) Clone() *Campaign {
 	cc := *c
 	cc.ChangesetIDs = c.ChangesetIDs[:len(c.ChangesetIDs):len(c.ChangesetIDs)]
+	if c.AutoMerge != nil {
+		cc.AutoMerge = c.AutoMerge.Clone()
+	}
+	if c.PublishPolicy != nil {
+		cc.PublishPolicy = c.PublishPolicy.Clone()
+	}
+	if c.ReviewerPolicy != nil {
+		cc.ReviewerPolicy = c.ReviewerPolicy.Clone()
+	}
 	return &cc
 }
 
@@ -143,6 +197,253 @@ const (
 	CampaignStateClosed CampaignState = "CLOSED"
 )
 
+// ChangesetMergeMethod is the method by which a Changeset is merged on the
+// codehost.
+type ChangesetMergeMethod string
+
+// ChangesetMergeMethod constants.
+const (
+	ChangesetMergeMethodMerge  ChangesetMergeMethod = "MERGE"
+	ChangesetMergeMethodSquash ChangesetMergeMethod = "SQUASH"
+	ChangesetMergeMethodRebase ChangesetMergeMethod = "REBASE"
+)
+
+// Valid returns true if the given Changeset merge method is valid.
+func (m ChangesetMergeMethod) Valid() bool {
+	switch m {
+	case ChangesetMergeMethodMerge,
+		ChangesetMergeMethodSquash,
+		ChangesetMergeMethodRebase:
+		return true
+	default:
+		return false
+	}
+}
+
+// windowLayout is the layout of the start and end of a merge or publication
+// window.
+const windowLayout = "15:04"
+
+// An AutoMergePolicy defines when and how the Changesets of a Campaign are
+// merged automatically, once they have been approved and their checks pass.
+type AutoMergePolicy struct {
+	Method ChangesetMergeMethod `json:"method"`
+
+	// Days are the days of the week on which Changesets are merged. If empty,
+	// Changesets are merged on every day.
+	Days []time.Weekday `json:"days,omitempty"`
+	// WindowStart and WindowEnd are the times of day, formatted as "15:04",
+	// between which Changesets are merged. If both are empty, Changesets are
+	// merged at any time of day. If WindowEnd is before WindowStart, the
+	// window spans midnight.
+	WindowStart string `json:"windowStart,omitempty"`
+	WindowEnd   string `json:"windowEnd,omitempty"`
+	// TimeZone is the name of the IANA time zone, such as "Europe/Berlin", of
+	// Days, WindowStart and WindowEnd. If empty, they are in UTC.
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// MaxMergesPerRepo is the maximum number of Changesets that are merged in
+	// the same repository at once, since merging one moves the base branch of
+	// the others. Merges of the Changesets of other Campaigns count towards
+	// it. If zero, one Changeset is merged per repository at once.
+	MaxMergesPerRepo int `json:"maxMergesPerRepo,omitempty"`
+}
+
+// Clone returns a clone of an AutoMergePolicy.
+func (p *AutoMergePolicy) Clone() *AutoMergePolicy {
+	pp := *p
+	pp.Days = append([]time.Weekday(nil), p.Days...)
+	return &pp
+}
+
+// Validate returns an error if the AutoMergePolicy is invalid.
+func (p *AutoMergePolicy) Validate() error {
+	if !p.Method.Valid() {
+		return errors.Errorf("invalid merge method %q", p.Method)
+	}
+
+	if err := validateWindow("merge", p.Days, p.WindowStart, p.WindowEnd, p.TimeZone); err != nil {
+		return err
+	}
+
+	if p.MaxMergesPerRepo < 0 {
+		return errors.New("maximum number of merges per repository must not be negative")
+	}
+
+	return nil
+}
+
+// InWindow returns true if Changesets may be merged at the given time
+// according to the AutoMergePolicy.
+func (p *AutoMergePolicy) InWindow(t time.Time) bool {
+	return inWindow(t, p.Days, p.WindowStart, p.WindowEnd, p.TimeZone)
+}
+
+// MergesPerRepo returns the maximum number of Changesets that are merged in
+// the same repository at once.
+func (p *AutoMergePolicy) MergesPerRepo() int {
+	if p.MaxMergesPerRepo <= 0 {
+		return 1
+	}
+	return p.MaxMergesPerRepo
+}
+
+// A PublishPolicy limits the rate at which the Changesets of a Campaign are
+// published on the codehosts.
+type PublishPolicy struct {
+	// MaxChangesetsPerHour is the maximum number of Changesets published per
+	// hour. If zero, the number is not limited.
+	MaxChangesetsPerHour int `json:"maxChangesetsPerHour,omitempty"`
+
+	// Days, WindowStart, WindowEnd and TimeZone restrict publishing to a
+	// window of time, like in an AutoMergePolicy.
+	Days        []time.Weekday `json:"days,omitempty"`
+	WindowStart string         `json:"windowStart,omitempty"`
+	WindowEnd   string         `json:"windowEnd,omitempty"`
+	TimeZone    string         `json:"timeZone,omitempty"`
+
+	// InitialBatchSize is the number of Changesets published in a first
+	// batch. The remaining Changesets are only published once all Changesets
+	// of the first batch have been merged, closed or deleted. If zero, all Changesets are
+	// published in a single batch.
+	InitialBatchSize int `json:"initialBatchSize,omitempty"`
+}
+
+// Clone returns a clone of a PublishPolicy.
+func (p *PublishPolicy) Clone() *PublishPolicy {
+	pp := *p
+	pp.Days = append([]time.Weekday(nil), p.Days...)
+	return &pp
+}
+
+// Validate returns an error if the PublishPolicy is invalid.
+func (p *PublishPolicy) Validate() error {
+	if p.MaxChangesetsPerHour < 0 {
+		return errors.New("maximum number of changesets per hour must not be negative")
+	}
+
+	if err := validateWindow("publication", p.Days, p.WindowStart, p.WindowEnd, p.TimeZone); err != nil {
+		return err
+	}
+
+	if p.InitialBatchSize < 0 {
+		return errors.New("initial batch size must not be negative")
+	}
+
+	return nil
+}
+
+// InWindow returns true if Changesets may be published at the given time
+// according to the PublishPolicy.
+func (p *PublishPolicy) InWindow(t time.Time) bool {
+	return inWindow(t, p.Days, p.WindowStart, p.WindowEnd, p.TimeZone)
+}
+
+// A ReviewerPolicy defines who is asked to review the Changesets of a
+// Campaign and who they are assigned to, when they are created on the
+// codehosts. Reviewers and assignees are given like in CODEOWNERS files:
+// "@username", "@org/team" or an e-mail address.
+type ReviewerPolicy struct {
+	// IgnoreCodeOwners disables requesting reviews from the code owners of
+	// the changed files, as defined in the CODEOWNERS file of the repository,
+	// and assigning the Changesets to them.
+	IgnoreCodeOwners bool `json:"ignoreCodeOwners,omitempty"`
+	// Reviewers are asked to review every Changeset, in addition to the code
+	// owners.
+	Reviewers []string `json:"reviewers,omitempty"`
+	// Assignees are assigned to every Changeset, instead of the code owners.
+	Assignees []string `json:"assignees,omitempty"`
+}
+
+// Clone returns a clone of a ReviewerPolicy.
+func (p *ReviewerPolicy) Clone() *ReviewerPolicy {
+	pp := *p
+	pp.Reviewers = append([]string(nil), p.Reviewers...)
+	pp.Assignees = append([]string(nil), p.Assignees...)
+	return &pp
+}
+
+// validateWindow returns an error if the given days, times of day and time
+// zone don't define a valid window of time. kind is used in the returned
+// errors.
+func validateWindow(kind string, days []time.Weekday, start, end, timeZone string) error {
+	for _, d := range days {
+		if d < time.Sunday || d > time.Saturday {
+			return errors.Errorf("invalid day of the week %d", d)
+		}
+	}
+
+	if (start == "") != (end == "") {
+		return errors.Errorf("%s window needs both a start and an end", kind)
+	}
+	for _, t := range []string{start, end} {
+		if t == "" {
+			continue
+		}
+		if _, err := time.Parse(windowLayout, t); err != nil {
+			return errors.Errorf("invalid %s window time %q, expected HH:MM", kind, t)
+		}
+	}
+
+	if start != "" && windowMinutes(start) == windowMinutes(end) {
+		return errors.Errorf("%s window must not be empty", kind)
+	}
+
+	// "Local" is accepted by time.LoadLocation, but depends on the server.
+	if _, err := time.LoadLocation(timeZone); err != nil || timeZone == "Local" {
+		return errors.Errorf("invalid %s window time zone %q", kind, timeZone)
+	}
+
+	return nil
+}
+
+// inWindow returns true if t is within the window of time defined by the
+// given days, validated times of day and validated time zone.
+func inWindow(t time.Time, days []time.Weekday, start, end, timeZone string) bool {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	day := t.Weekday()
+
+	if start != "" {
+		now, start, end := clockMinutes(t), windowMinutes(start), windowMinutes(end)
+		switch {
+		case start < end:
+			if now < start || now >= end {
+				return false
+			}
+		case now < end:
+			// We're in the part of a window spanning midnight that started
+			// on the previous day.
+			day = (day + 6) % 7
+		case now < start:
+			return false
+		}
+	}
+
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+func clockMinutes(t time.Time) int {
+	return t.Hour()*60 + t.Minute()
+}
+
+// windowMinutes returns the minutes since midnight of a validated window time.
+func windowMinutes(s string) int {
+	t, _ := time.Parse(windowLayout, s)
+	return clockMinutes(t)
+}
+
 // BackgroundProcessStatus defines the status of a background process.
 type BackgroundProcessStatus struct {
 	Canceled      bool
@@ -243,6 +544,19 @@ type ChangesetJob struct {
 	StartedAt  time.Time
 	FinishedAt time.Time
 
+	// RebaseRev is the base commit onto which the Patch was last re-applied
+	// by the rebase worker, whether that succeeded or not.
+	RebaseRev api.CommitID
+	// RebaseError is set when the Patch could not be re-applied onto
+	// RebaseRev and cleared again after the next successful rebase.
+	RebaseError string
+	RebasedAt   time.Time
+
+	// ReleasedAt is the time at which the ChangesetJob was released for
+	// publication according to the PublishPolicy of its Campaign. Jobs of
+	// Campaigns with a PublishPolicy are only executed once released.
+	ReleasedAt time.Time
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -266,6 +580,111 @@ func (c *ChangesetJob) Reset() {
 	c.FinishedAt = time.Time{}
 }
 
+// ChangesetActionKind is the kind of a ChangesetAction.
+type ChangesetActionKind string
+
+// ChangesetActionKind constants.
+const (
+	ChangesetActionKindComment          ChangesetActionKind = "COMMENT"
+	ChangesetActionKindAddLabels        ChangesetActionKind = "ADD_LABELS"
+	ChangesetActionKindRemoveLabels     ChangesetActionKind = "REMOVE_LABELS"
+	ChangesetActionKindRerequestReviews ChangesetActionKind = "REREQUEST_REVIEWS"
+	ChangesetActionKindRerunChecks      ChangesetActionKind = "RERUN_CHECKS"
+)
+
+// Valid returns true if the given ChangesetActionKind is valid.
+func (k ChangesetActionKind) Valid() bool {
+	switch k {
+	case ChangesetActionKindComment,
+		ChangesetActionKindAddLabels,
+		ChangesetActionKindRemoveLabels,
+		ChangesetActionKindRerequestReviews,
+		ChangesetActionKindRerunChecks:
+		return true
+	default:
+		return false
+	}
+}
+
+// A ChangesetAction is an action taken in bulk on the Changesets of a
+// Campaign, such as commenting on them. It is executed for each Changeset
+// that matched its filters at the time it was created by a
+// ChangesetActionJob.
+type ChangesetAction struct {
+	ID         int64
+	CampaignID int64
+	AuthorID   int32
+
+	Kind ChangesetActionKind
+	// Comment is the body of the comment of a ChangesetActionKindComment.
+	Comment string
+	// Labels are the labels that are added or removed by a
+	// ChangesetActionKindAddLabels or ChangesetActionKindRemoveLabels.
+	Labels []string
+
+	// The filters that selected the Changesets. Empty filters match every
+	// Changeset.
+	ExternalState       ChangesetState
+	ExternalReviewState ChangesetReviewState
+	ExternalCheckState  ChangesetCheckState
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Clone returns a clone of a ChangesetAction.
+func (a *ChangesetAction) Clone() *ChangesetAction {
+	aa := *a
+	aa.Labels = append([]string(nil), a.Labels...)
+	return &aa
+}
+
+// Validate returns an error if the ChangesetAction lacks the arguments its
+// Kind requires.
+func (a *ChangesetAction) Validate() error {
+	switch a.Kind {
+	case ChangesetActionKindComment:
+		if strings.TrimSpace(a.Comment) == "" {
+			return errors.New("comment must not be empty")
+		}
+	case ChangesetActionKindAddLabels, ChangesetActionKindRemoveLabels:
+		if len(a.Labels) == 0 {
+			return errors.New("labels must not be empty")
+		}
+		for _, l := range a.Labels {
+			if strings.TrimSpace(l) == "" {
+				return errors.New("labels must not be blank")
+			}
+		}
+	case ChangesetActionKindRerequestReviews, ChangesetActionKindRerunChecks:
+	default:
+		return errors.Errorf("invalid changeset action %q", a.Kind)
+	}
+	return nil
+}
+
+// A ChangesetActionJob is the execution of a ChangesetAction on a single
+// Changeset.
+type ChangesetActionJob struct {
+	ID          int64
+	ActionID    int64
+	ChangesetID int64
+
+	Error string
+
+	StartedAt  time.Time
+	FinishedAt time.Time
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Clone returns a clone of a ChangesetActionJob.
+func (j *ChangesetActionJob) Clone() *ChangesetActionJob {
+	jj := *j
+	return &jj
+}
+
 // A Changeset is a changeset on a code host belonging to a Repository and many
 // Campaigns.
 type Changeset struct {
@@ -306,6 +725,18 @@ func (c *Changeset) SetMetadata(meta interface{}) error {
 		c.ExternalServiceType = bitbucketserver.ServiceType
 		c.ExternalBranch = git.AbbreviateRef(pr.FromRef.ID)
 		c.ExternalUpdatedAt = unixMilliToTime(int64(pr.UpdatedDate))
+	case *gitlab.MergeRequest:
+		c.Metadata = pr
+		c.ExternalID = strconv.FormatInt(pr.IID, 10)
+		c.ExternalServiceType = gitlab.ServiceType
+		c.ExternalBranch = pr.SourceBranch
+		c.ExternalUpdatedAt = pr.UpdatedAt
+	case *bitbucketcloud.PullRequest:
+		c.Metadata = pr
+		c.ExternalID = strconv.FormatInt(pr.ID, 10)
+		c.ExternalServiceType = bitbucketcloud.ServiceType
+		c.ExternalBranch = pr.Source.Branch.Name
+		c.ExternalUpdatedAt = pr.UpdatedOn
 	default:
 		return errors.New("unknown changeset type")
 	}
@@ -350,6 +781,10 @@ func (c *Changeset) Title() (string, error) {
 		return m.Title, nil
 	case *bitbucketserver.PullRequest:
 		return m.Title, nil
+	case *gitlab.MergeRequest:
+		return m.Title, nil
+	case *bitbucketcloud.PullRequest:
+		return m.Title, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -364,6 +799,10 @@ func (c *Changeset) ExternalCreatedAt() time.Time {
 		return m.CreatedAt
 	case *bitbucketserver.PullRequest:
 		return unixMilliToTime(int64(m.CreatedDate))
+	case *gitlab.MergeRequest:
+		return m.CreatedAt
+	case *bitbucketcloud.PullRequest:
+		return m.CreatedOn
 	default:
 		return time.Time{}
 	}
@@ -376,6 +815,10 @@ func (c *Changeset) Body() (string, error) {
 		return m.Body, nil
 	case *bitbucketserver.PullRequest:
 		return m.Description, nil
+	case *gitlab.MergeRequest:
+		return m.Description, nil
+	case *bitbucketcloud.PullRequest:
+		return m.Description, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -409,6 +852,24 @@ func (c *Changeset) state() (s ChangesetState, err error) {
 		} else {
 			s = ChangesetState(m.State)
 		}
+	case *gitlab.MergeRequest:
+		switch m.State {
+		case gitlab.MergeRequestStateOpened:
+			s = ChangesetStateOpen
+		case gitlab.MergeRequestStateClosed, gitlab.MergeRequestStateLocked:
+			s = ChangesetStateClosed
+		case gitlab.MergeRequestStateMerged:
+			s = ChangesetStateMerged
+		default:
+			s = ChangesetState(m.State)
+		}
+	case *bitbucketcloud.PullRequest:
+		switch m.State {
+		case bitbucketcloud.PullRequestStateDeclined, bitbucketcloud.PullRequestStateSuperseded:
+			s = ChangesetStateClosed
+		default:
+			s = ChangesetState(m.State)
+		}
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -431,6 +892,10 @@ func (c *Changeset) URL() (s string, err error) {
 		}
 		selfLink := m.Links.Self[0]
 		return selfLink.Href, nil
+	case *gitlab.MergeRequest:
+		return m.WebURL, nil
+	case *bitbucketcloud.PullRequest:
+		return m.Links.HTML.Href, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -483,6 +948,43 @@ func (c *Changeset) Events() (events []*ChangesetEvent) {
 			addEvent(s)
 		}
 
+	case *gitlab.MergeRequest:
+		events = make([]*ChangesetEvent, 0, len(m.Notes)+len(m.Pipelines))
+		addEvent := func(e Keyer) {
+			events = append(events, &ChangesetEvent{
+				ChangesetID: c.ID,
+				Key:         e.Key(),
+				Kind:        ChangesetEventKindFor(e),
+				Metadata:    e,
+			})
+		}
+		for _, n := range m.Notes {
+			// Only comments and system notes recording an approval or a
+			// change of the state are events.
+			if e := n.ToEvent(); e != nil {
+				addEvent(e)
+			}
+		}
+		for _, p := range m.Pipelines {
+			addEvent(p)
+		}
+
+	case *bitbucketcloud.PullRequest:
+		events = make([]*ChangesetEvent, 0, len(m.Activities)+len(m.Statuses))
+		addEvent := func(e Keyer) {
+			events = append(events, &ChangesetEvent{
+				ChangesetID: c.ID,
+				Key:         e.Key(),
+				Kind:        ChangesetEventKindFor(e),
+				Metadata:    e,
+			})
+		}
+		for _, a := range m.Activities {
+			addEvent(a)
+		}
+		for _, s := range m.Statuses {
+			addEvent(s)
+		}
 	}
 	return events
 }
@@ -496,6 +998,11 @@ func (c *Changeset) HeadRefOid() (string, error) {
 		return m.HeadRefOid, nil
 	case *bitbucketserver.PullRequest:
 		return "", nil
+	case *gitlab.MergeRequest:
+		return m.SHA, nil
+	case *bitbucketcloud.PullRequest:
+		// Bitbucket Cloud only returns abbreviated commit hashes.
+		return "", nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -509,6 +1016,10 @@ func (c *Changeset) HeadRef() (string, error) {
 		return "refs/heads/" + m.HeadRefName, nil
 	case *bitbucketserver.PullRequest:
 		return m.FromRef.ID, nil
+	case *gitlab.MergeRequest:
+		return "refs/heads/" + m.SourceBranch, nil
+	case *bitbucketcloud.PullRequest:
+		return "refs/heads/" + m.Source.Branch.Name, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -523,6 +1034,10 @@ func (c *Changeset) BaseRefOid() (string, error) {
 		return m.BaseRefOid, nil
 	case *bitbucketserver.PullRequest:
 		return "", nil
+	case *gitlab.MergeRequest:
+		return m.DiffRefs.BaseSHA, nil
+	case *bitbucketcloud.PullRequest:
+		return "", nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -536,6 +1051,10 @@ func (c *Changeset) BaseRef() (string, error) {
 		return "refs/heads/" + m.BaseRefName, nil
 	case *bitbucketserver.PullRequest:
 		return m.ToRef.ID, nil
+	case *gitlab.MergeRequest:
+		return "refs/heads/" + m.TargetBranch, nil
+	case *bitbucketcloud.PullRequest:
+		return "refs/heads/" + m.Destination.Branch.Name, nil
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -558,8 +1077,8 @@ func (c *Changeset) Labels() []ChangesetLabel {
 	}
 }
 
-// reviewState of a Changeset. GitHub doesn't keep the review state on a
-// changeset, so a GitHub Changeset will always return
+// reviewState of a Changeset. GitHub and GitLab don't keep the review state
+// on a changeset, so a GitHub or GitLab Changeset will always return
 // ChangesetReviewStatePending.
 // This method should not be called directly. Use ComputeReviewState instead.
 func (c *Changeset) reviewState() (s ChangesetReviewState, err error) {
@@ -571,6 +1090,10 @@ func (c *Changeset) reviewState() (s ChangesetReviewState, err error) {
 		log15.Warn("Changeset.ReviewState() called, but GitHub review state is calculated through ChangesetEvents.ReviewState", "changeset", c)
 		return ChangesetReviewStatePending, nil
 
+	case *gitlab.MergeRequest:
+		// For GitLab approvals are system notes, which are events.
+		return ChangesetReviewStatePending, nil
+
 	case *bitbucketserver.PullRequest:
 		for _, r := range m.Reviewers {
 			switch r.Status {
@@ -582,6 +1105,20 @@ func (c *Changeset) reviewState() (s ChangesetReviewState, err error) {
 				states[ChangesetReviewStateApproved] = true
 			}
 		}
+
+	case *bitbucketcloud.PullRequest:
+		for _, p := range m.Participants {
+			switch p.State {
+			case bitbucketcloud.ParticipantStateChangesRequested:
+				states[ChangesetReviewStateChangesRequested] = true
+			case bitbucketcloud.ParticipantStateApproved:
+				states[ChangesetReviewStateApproved] = true
+			default:
+				if p.Role == "REVIEWER" {
+					states[ChangesetReviewStatePending] = true
+				}
+			}
+		}
 	default:
 		return "", errors.New("unknown changeset type")
 	}
@@ -679,11 +1216,14 @@ func (ce ChangesetEvents) State() ChangesetState {
 	state := ChangesetStateOpen
 	for _, e := range ce {
 		switch e.Kind {
-		case ChangesetEventKindGitHubClosed, ChangesetEventKindBitbucketServerDeclined:
+		case ChangesetEventKindGitHubClosed, ChangesetEventKindBitbucketServerDeclined,
+			ChangesetEventKindGitLabClosed, ChangesetEventKindBitbucketCloudDeclined:
 			state = ChangesetStateClosed
-		case ChangesetEventKindGitHubMerged, ChangesetEventKindBitbucketServerMerged:
+		case ChangesetEventKindGitHubMerged, ChangesetEventKindBitbucketServerMerged,
+			ChangesetEventKindGitLabMerged, ChangesetEventKindBitbucketCloudMerged:
 			state = ChangesetStateMerged
-		case ChangesetEventKindGitHubReopened, ChangesetEventKindBitbucketServerReopened:
+		case ChangesetEventKindGitHubReopened, ChangesetEventKindBitbucketServerReopened,
+			ChangesetEventKindGitLabReopened:
 			state = ChangesetStateOpen
 		}
 	}
@@ -699,11 +1239,97 @@ func ComputeCheckState(c *Changeset, events ChangesetEvents) ChangesetCheckState
 
 	case *bitbucketserver.PullRequest:
 		return computeBitbucketBuildStatus(c.UpdatedAt, m, events)
+
+	case *gitlab.MergeRequest:
+		return computeGitLabCheckState(m, events)
+
+	case *bitbucketcloud.PullRequest:
+		return computeBitbucketCloudBuildStatus(m, events)
 	}
 
 	return ChangesetCheckStateUnknown
 }
 
+// FailedChecks returns the sorted names of the checks that failed on the head
+// commit of the Changeset when it was last synced. Failed checks whose names
+// the code host doesn't report are left out.
+func FailedChecks(c *Changeset) []string {
+	var names []string
+
+	switch m := c.Metadata.(type) {
+	case *github.PullRequest:
+		if len(m.Commits.Nodes) == 0 {
+			break
+		}
+		// We only request the most recent commit
+		commit := m.Commits.Nodes[0].Commit
+		for _, ctx := range commit.Status.Contexts {
+			if parseGithubCheckState(ctx.State) == ChangesetCheckStateFailed {
+				names = append(names, ctx.Context)
+			}
+		}
+		for _, suite := range commit.CheckSuites.Nodes {
+			for _, r := range suite.CheckRuns.Nodes {
+				if parseGithubCheckSuiteState(r.Status, r.Conclusion) == ChangesetCheckStateFailed {
+					names = append(names, r.Name)
+				}
+			}
+		}
+
+	case *bitbucketserver.PullRequest:
+		for _, status := range m.CommitStatus {
+			if parseBitbucketBuildState(status.Status.State) == ChangesetCheckStateFailed {
+				name := status.Status.Name
+				if name == "" {
+					name = status.Status.Key
+				}
+				names = append(names, name)
+			}
+		}
+
+	case *gitlab.MergeRequest:
+		var latest *gitlab.Pipeline
+		for _, p := range m.Pipelines {
+			if latest == nil || p.ID > latest.ID {
+				latest = p
+			}
+		}
+		if latest != nil && parseGitLabPipelineStatus(latest.Status) == ChangesetCheckStateFailed {
+			names = append(names, "pipeline "+string(latest.Status))
+		}
+
+	case *bitbucketcloud.PullRequest:
+		var head string
+		if m.Source.Commit != nil {
+			head = m.Source.Commit.Hash
+		}
+		for _, status := range m.Statuses {
+			if head == "" || !strings.HasPrefix(status.Commit(), head) {
+				continue
+			}
+			if parseBitbucketCloudBuildState(status.State) == ChangesetCheckStateFailed {
+				name := status.Name
+				if name == "" {
+					name = status.StatusKey
+				}
+				names = append(names, name)
+			}
+		}
+	}
+
+	seen := make(map[string]bool, len(names))
+	failed := names[:0]
+	for _, n := range names {
+		if n == "" || seen[n] {
+			continue
+		}
+		seen[n] = true
+		failed = append(failed, n)
+	}
+	sort.Strings(failed)
+	return failed
+}
+
 // ComputeChangesetState computes the overall state for the changeset and its
 // associated events. The events should be presorted.
 func ComputeChangesetState(c *Changeset, events ChangesetEvents) (ChangesetState, error) {
@@ -724,9 +1350,9 @@ func ComputeReviewState(c *Changeset, events ChangesetEvents) (ChangesetReviewSt
 		return c.reviewState()
 	}
 
-	// GitHub only stores the ReviewState in events, we can't look at the
-	// Changeset.
-	if c.ExternalServiceType == github.ServiceType {
+	// GitHub and GitLab only store the ReviewState in events, we can't look
+	// at the Changeset.
+	if c.ExternalServiceType == github.ServiceType || c.ExternalServiceType == gitlab.ServiceType {
 		return events.reviewState()
 	}
 
@@ -790,6 +1416,99 @@ func parseBitbucketBuildState(s string) ChangesetCheckState {
 	}
 }
 
+// computeGitLabCheckState computes the check state of a merge request from the
+// status of its most recent pipeline. Pipelines received by webhooks since the
+// last sync take precedence over the synced pipelines if they are newer.
+func computeGitLabCheckState(mr *gitlab.MergeRequest, events []*ChangesetEvent) ChangesetCheckState {
+	pipelines := make(map[int64]*gitlab.Pipeline, len(mr.Pipelines))
+	for _, p := range mr.Pipelines {
+		pipelines[p.ID] = p
+	}
+	for _, e := range events {
+		if p, ok := e.Metadata.(*gitlab.Pipeline); ok {
+			if synced, ok := pipelines[p.ID]; !ok || p.UpdatedAt.After(synced.UpdatedAt) {
+				pipelines[p.ID] = p
+			}
+		}
+	}
+
+	var latest *gitlab.Pipeline
+	for _, p := range pipelines {
+		if latest == nil || p.ID > latest.ID {
+			latest = p
+		}
+	}
+	if latest == nil {
+		return ChangesetCheckStateUnknown
+	}
+	return parseGitLabPipelineStatus(latest.Status)
+}
+
+// computeBitbucketCloudBuildStatus computes the check state of a pull request
+// from the statuses of its head commit. Statuses received by webhooks since the
+// last sync take precedence over the synced statuses if they are newer.
+func computeBitbucketCloudBuildStatus(pr *bitbucketcloud.PullRequest, events []*ChangesetEvent) ChangesetCheckState {
+	// The API only returns the abbreviated hash of the head commit.
+	var head string
+	if pr.Source.Commit != nil {
+		head = pr.Source.Commit.Hash
+	}
+	isHead := func(s *bitbucketcloud.CommitStatus) bool {
+		return head != "" && strings.HasPrefix(s.Commit(), head)
+	}
+
+	statuses := make(map[string]*bitbucketcloud.CommitStatus)
+	for _, s := range pr.Statuses {
+		if isHead(s) {
+			statuses[s.Key()] = s
+		}
+	}
+	for _, e := range events {
+		if s, ok := e.Metadata.(*bitbucketcloud.CommitStatus); ok && isHead(s) {
+			if synced, ok := statuses[s.Key()]; !ok || s.UpdatedOn.After(synced.UpdatedOn) {
+				statuses[s.Key()] = s
+			}
+		}
+	}
+
+	states := make([]ChangesetCheckState, 0, len(statuses))
+	for _, s := range statuses {
+		states = append(states, parseBitbucketCloudBuildState(s.State))
+	}
+	return combineCheckStates(states)
+}
+
+func parseBitbucketCloudBuildState(s bitbucketcloud.CommitStatusState) ChangesetCheckState {
+	switch s {
+	case bitbucketcloud.CommitStatusStateFailed, bitbucketcloud.CommitStatusStateStopped:
+		return ChangesetCheckStateFailed
+	case bitbucketcloud.CommitStatusStateInProgress:
+		return ChangesetCheckStatePending
+	case bitbucketcloud.CommitStatusStateSuccessful:
+		return ChangesetCheckStatePassed
+	default:
+		return ChangesetCheckStateUnknown
+	}
+}
+
+func parseGitLabPipelineStatus(s gitlab.PipelineStatus) ChangesetCheckState {
+	switch s {
+	case gitlab.PipelineStatusCreated,
+		gitlab.PipelineStatusWaitingForResource,
+		gitlab.PipelineStatusPreparing,
+		gitlab.PipelineStatusPending,
+		gitlab.PipelineStatusRunning,
+		gitlab.PipelineStatusScheduled:
+		return ChangesetCheckStatePending
+	case gitlab.PipelineStatusSuccess:
+		return ChangesetCheckStatePassed
+	case gitlab.PipelineStatusFailed, gitlab.PipelineStatusCanceled:
+		return ChangesetCheckStateFailed
+	default:
+		return ChangesetCheckStateUnknown
+	}
+}
+
 func computeGitHubCheckState(lastSynced time.Time, pr *github.PullRequest, events []*ChangesetEvent) ChangesetCheckState {
 	// We should only consider the latest commit. This could be from a sync or a webhook that
 	// has occurred later
@@ -1020,6 +1739,20 @@ func (e *ChangesetEvent) Actor() string {
 		a = e.Actor.Login
 	case *github.LabelEvent:
 		a = e.Actor.Login
+	case *gitlab.Note:
+		a = e.Author.Username
+	case *gitlab.ReviewApprovedEvent:
+		a = e.Author.Username
+	case *gitlab.ReviewUnapprovedEvent:
+		a = e.Author.Username
+	case *gitlab.MergeRequestClosedEvent:
+		a = e.Author.Username
+	case *gitlab.MergeRequestReopenedEvent:
+		a = e.Author.Username
+	case *gitlab.MergeRequestMergedEvent:
+		a = e.Author.Username
+	case *bitbucketcloud.Activity:
+		a = e.User.Nickname
 	}
 
 	return a
@@ -1048,6 +1781,27 @@ func (e *ChangesetEvent) ReviewAuthor() (string, error) {
 			return "", errors.New("activity user is blank")
 		}
 		return username, nil
+
+	case *gitlab.ReviewApprovedEvent:
+		username := meta.Author.Username
+		if username == "" {
+			return "", errors.New("approval author is blank")
+		}
+		return username, nil
+
+	case *gitlab.ReviewUnapprovedEvent:
+		username := meta.Author.Username
+		if username == "" {
+			return "", errors.New("unapproval author is blank")
+		}
+		return username, nil
+
+	case *bitbucketcloud.Activity:
+		uuid := meta.User.UUID
+		if uuid == "" {
+			return "", errors.New("activity user is blank")
+		}
+		return uuid, nil
 	default:
 		return "", nil
 	}
@@ -1056,12 +1810,15 @@ func (e *ChangesetEvent) ReviewAuthor() (string, error) {
 // ReviewState returns the review state of the ChangesetEvent if it is a review event.
 func (e *ChangesetEvent) ReviewState() (ChangesetReviewState, error) {
 	switch e.Kind {
-	case ChangesetEventKindBitbucketServerApproved:
+	case ChangesetEventKindBitbucketServerApproved,
+		ChangesetEventKindGitLabApproved,
+		ChangesetEventKindBitbucketCloudApproved:
 		return ChangesetReviewStateApproved, nil
 
 	// BitbucketServer's "REVIEWED" activity is created when someone clicks
 	// the "Needs work" button in the UI, which is why we map it to "Changes Requested"
-	case ChangesetEventKindBitbucketServerReviewed:
+	case ChangesetEventKindBitbucketServerReviewed,
+		ChangesetEventKindBitbucketCloudChangesRequested:
 		return ChangesetReviewStateChangesRequested, nil
 
 	case ChangesetEventKindGitHubReviewed:
@@ -1079,7 +1836,10 @@ func (e *ChangesetEvent) ReviewState() (ChangesetReviewState, error) {
 		return s, nil
 
 	case ChangesetEventKindGitHubReviewDismissed,
-		ChangesetEventKindBitbucketServerUnapproved:
+		ChangesetEventKindBitbucketServerUnapproved,
+		ChangesetEventKindGitLabUnapproved,
+		ChangesetEventKindBitbucketCloudUnapproved,
+		ChangesetEventKindBitbucketCloudChangesRequestRemoved:
 		return ChangesetReviewStateDismissed, nil
 
 	default:
@@ -1139,6 +1899,27 @@ func (e *ChangesetEvent) Timestamp() time.Time {
 		t = unixMilliToTime(int64(e.CreatedDate))
 	case *bitbucketserver.CommitStatus:
 		t = unixMilliToTime(int64(e.Status.DateAdded))
+	case *gitlab.Note:
+		t = e.UpdatedAt
+	case *gitlab.ReviewApprovedEvent:
+		t = e.CreatedAt
+	case *gitlab.ReviewUnapprovedEvent:
+		t = e.CreatedAt
+	case *gitlab.MergeRequestClosedEvent:
+		t = e.CreatedAt
+	case *gitlab.MergeRequestReopenedEvent:
+		t = e.CreatedAt
+	case *gitlab.MergeRequestMergedEvent:
+		t = e.CreatedAt
+	case *gitlab.Pipeline:
+		t = e.UpdatedAt
+	case *bitbucketcloud.Activity:
+		t = e.Date
+		if e.Comment != nil {
+			t = e.Comment.UpdatedOn
+		}
+	case *bitbucketcloud.CommitStatus:
+		t = e.UpdatedOn
 	}
 
 	return t
@@ -1460,6 +2241,48 @@ func (e *ChangesetEvent) Update(o *ChangesetEvent) {
 		}
 		e.CheckRuns = o.CheckRuns
 
+	case *gitlab.Note:
+		o := o.Metadata.(*gitlab.Note)
+		// We always get the full note, so safe to replace it
+		*e = *o
+
+	case *gitlab.ReviewApprovedEvent:
+		o := o.Metadata.(*gitlab.ReviewApprovedEvent)
+		*e.Note = *o.Note
+
+	case *gitlab.ReviewUnapprovedEvent:
+		o := o.Metadata.(*gitlab.ReviewUnapprovedEvent)
+		*e.Note = *o.Note
+
+	case *gitlab.MergeRequestClosedEvent:
+		o := o.Metadata.(*gitlab.MergeRequestClosedEvent)
+		*e.Note = *o.Note
+
+	case *gitlab.MergeRequestReopenedEvent:
+		o := o.Metadata.(*gitlab.MergeRequestReopenedEvent)
+		*e.Note = *o.Note
+
+	case *gitlab.MergeRequestMergedEvent:
+		o := o.Metadata.(*gitlab.MergeRequestMergedEvent)
+		*e.Note = *o.Note
+
+	case *gitlab.Pipeline:
+		o := o.Metadata.(*gitlab.Pipeline)
+		// Webhook events don't include the web URL of the pipeline.
+		if o.WebURL == "" {
+			o.WebURL = e.WebURL
+		}
+		*e = *o
+
+	case *bitbucketcloud.Activity:
+		o := o.Metadata.(*bitbucketcloud.Activity)
+		// Activities are always received in full, so safe to replace them
+		*e = *o
+
+	case *bitbucketcloud.CommitStatus:
+		o := o.Metadata.(*bitbucketcloud.CommitStatus)
+		*e = *o
+
 	default:
 		panic(errors.Errorf("unknown changeset event metadata %T", e))
 	}
@@ -1591,6 +2414,24 @@ func ChangesetEventKindFor(e interface{}) ChangesetEventKind {
 		return ChangesetEventKind("bitbucketserver:" + strings.ToLower(string(e.Action)))
 	case *bitbucketserver.CommitStatus:
 		return ChangesetEventKindBitbucketServerCommitStatus
+	case *gitlab.Note:
+		return ChangesetEventKindGitLabCommented
+	case *gitlab.ReviewApprovedEvent:
+		return ChangesetEventKindGitLabApproved
+	case *gitlab.ReviewUnapprovedEvent:
+		return ChangesetEventKindGitLabUnapproved
+	case *gitlab.MergeRequestClosedEvent:
+		return ChangesetEventKindGitLabClosed
+	case *gitlab.MergeRequestReopenedEvent:
+		return ChangesetEventKindGitLabReopened
+	case *gitlab.MergeRequestMergedEvent:
+		return ChangesetEventKindGitLabMerged
+	case *gitlab.Pipeline:
+		return ChangesetEventKindGitLabPipeline
+	case *bitbucketcloud.Activity:
+		return ChangesetEventKind("bitbucketcloud:" + string(e.Kind))
+	case *bitbucketcloud.CommitStatus:
+		return ChangesetEventKindBitbucketCloudCommitStatus
 	default:
 		panic(errors.Errorf("unknown changeset event kind for %T", e))
 	}
@@ -1607,6 +2448,30 @@ func NewChangesetEventMetadata(k ChangesetEventKind) (interface{}, error) {
 		default:
 			return new(bitbucketserver.Activity), nil
 		}
+	case strings.HasPrefix(string(k), "bitbucketcloud"):
+		switch k {
+		case ChangesetEventKindBitbucketCloudCommitStatus:
+			return new(bitbucketcloud.CommitStatus), nil
+		default:
+			return new(bitbucketcloud.Activity), nil
+		}
+	case strings.HasPrefix(string(k), "gitlab"):
+		switch k {
+		case ChangesetEventKindGitLabCommented:
+			return new(gitlab.Note), nil
+		case ChangesetEventKindGitLabApproved:
+			return &gitlab.ReviewApprovedEvent{Note: new(gitlab.Note)}, nil
+		case ChangesetEventKindGitLabUnapproved:
+			return &gitlab.ReviewUnapprovedEvent{Note: new(gitlab.Note)}, nil
+		case ChangesetEventKindGitLabClosed:
+			return &gitlab.MergeRequestClosedEvent{Note: new(gitlab.Note)}, nil
+		case ChangesetEventKindGitLabReopened:
+			return &gitlab.MergeRequestReopenedEvent{Note: new(gitlab.Note)}, nil
+		case ChangesetEventKindGitLabMerged:
+			return &gitlab.MergeRequestMergedEvent{Note: new(gitlab.Note)}, nil
+		case ChangesetEventKindGitLabPipeline:
+			return new(gitlab.Pipeline), nil
+		}
 	case strings.HasPrefix(string(k), "github"):
 		switch k {
 		case ChangesetEventKindGitHubAssigned:
@@ -1687,6 +2552,23 @@ const (
 	ChangesetEventKindBitbucketServerCommented    ChangesetEventKind = "bitbucketserver:commented"
 	ChangesetEventKindBitbucketServerMerged       ChangesetEventKind = "bitbucketserver:merged"
 	ChangesetEventKindBitbucketServerCommitStatus ChangesetEventKind = "bitbucketserver:commit_status"
+
+	ChangesetEventKindGitLabApproved   ChangesetEventKind = "gitlab:approved"
+	ChangesetEventKindGitLabUnapproved ChangesetEventKind = "gitlab:unapproved"
+	ChangesetEventKindGitLabClosed     ChangesetEventKind = "gitlab:closed"
+	ChangesetEventKindGitLabReopened   ChangesetEventKind = "gitlab:reopened"
+	ChangesetEventKindGitLabMerged     ChangesetEventKind = "gitlab:merged"
+	ChangesetEventKindGitLabCommented  ChangesetEventKind = "gitlab:commented"
+	ChangesetEventKindGitLabPipeline   ChangesetEventKind = "gitlab:pipeline"
+
+	ChangesetEventKindBitbucketCloudApproved              ChangesetEventKind = "bitbucketcloud:approved"
+	ChangesetEventKindBitbucketCloudUnapproved            ChangesetEventKind = "bitbucketcloud:unapproved"
+	ChangesetEventKindBitbucketCloudChangesRequested      ChangesetEventKind = "bitbucketcloud:changes_requested"
+	ChangesetEventKindBitbucketCloudChangesRequestRemoved ChangesetEventKind = "bitbucketcloud:changes_request_removed"
+	ChangesetEventKindBitbucketCloudCommented             ChangesetEventKind = "bitbucketcloud:commented"
+	ChangesetEventKindBitbucketCloudDeclined              ChangesetEventKind = "bitbucketcloud:declined"
+	ChangesetEventKindBitbucketCloudMerged                ChangesetEventKind = "bitbucketcloud:merged"
+	ChangesetEventKindBitbucketCloudCommitStatus          ChangesetEventKind = "bitbucketcloud:commit_status"
 )
 
 // ChangesetSyncData represents data about the sync status of a changeset
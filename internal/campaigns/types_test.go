@@ -1,6 +1,7 @@
 package campaigns
 
 import (
+	"fmt"
 	"sort"
 	"testing"
 	"time"
@@ -8,8 +9,10 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketcloud"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/bitbucketserver"
 	"github.com/sourcegraph/sourcegraph/internal/extsvc/github"
+	"github.com/sourcegraph/sourcegraph/internal/extsvc/gitlab"
 )
 
 func TestChangesetMetadata(t *testing.T) {
@@ -245,6 +248,93 @@ func TestChangesetEvents(t *testing.T) {
 		})
 	}
 
+	{ // GitLab
+		user := gitlab.User{Username: "jane-doe"}
+		notes := []*gitlab.Note{
+			{ID: 1, Author: user, Body: "looks good"},
+			{ID: 2, Author: user, Body: "approved this merge request", System: true},
+			{ID: 3, Author: user, Body: "added 1 commit", System: true},
+			{ID: 4, Author: user, Body: "merged", System: true},
+		}
+		pipeline := &gitlab.Pipeline{ID: 5, Status: gitlab.PipelineStatusSuccess}
+
+		approved := &gitlab.ReviewApprovedEvent{Note: notes[1]}
+		merged := &gitlab.MergeRequestMergedEvent{Note: notes[3]}
+
+		cases = append(cases, testCase{"gitlab",
+			Changeset{
+				ID: 25,
+				Metadata: &gitlab.MergeRequest{
+					Notes:     notes,
+					Pipelines: []*gitlab.Pipeline{pipeline},
+				},
+			},
+			[]*ChangesetEvent{{
+				ChangesetID: 25,
+				Kind:        ChangesetEventKindGitLabCommented,
+				Key:         "1",
+				Metadata:    notes[0],
+			}, {
+				ChangesetID: 25,
+				Kind:        ChangesetEventKindGitLabApproved,
+				Key:         "2",
+				Metadata:    approved,
+			}, {
+				ChangesetID: 25,
+				Kind:        ChangesetEventKindGitLabMerged,
+				Key:         "4",
+				Metadata:    merged,
+			}, {
+				ChangesetID: 25,
+				Kind:        ChangesetEventKindGitLabPipeline,
+				Key:         "5",
+				Metadata:    pipeline,
+			}},
+		})
+	}
+
+	{ // Bitbucket Cloud
+		user := bitbucketcloud.Account{UUID: "{jane}", Nickname: "jane-doe"}
+		comment := &bitbucketcloud.Activity{
+			Kind:    bitbucketcloud.ActivityKindCommented,
+			User:    user,
+			Comment: &bitbucketcloud.Comment{ID: 3, User: user},
+		}
+		approval := &bitbucketcloud.Activity{
+			Kind: bitbucketcloud.ActivityKindApproved,
+			User: user,
+			Date: time.Unix(10, 0),
+		}
+		status := &bitbucketcloud.CommitStatus{StatusKey: "ci", State: bitbucketcloud.CommitStatusStateSuccessful}
+		status.Links.Commit.Href = "https://api.bitbucket.org/2.0/repositories/org/repo/commit/deadbeef"
+
+		cases = append(cases, testCase{"bitbucketcloud",
+			Changeset{
+				ID: 26,
+				Metadata: &bitbucketcloud.PullRequest{
+					Activities: []*bitbucketcloud.Activity{comment, approval},
+					Statuses:   []*bitbucketcloud.CommitStatus{status},
+				},
+			},
+			[]*ChangesetEvent{{
+				ChangesetID: 26,
+				Kind:        ChangesetEventKindBitbucketCloudCommented,
+				Key:         "commented:3",
+				Metadata:    comment,
+			}, {
+				ChangesetID: 26,
+				Kind:        ChangesetEventKindBitbucketCloudApproved,
+				Key:         "approved:{jane}:10",
+				Metadata:    approval,
+			}, {
+				ChangesetID: 26,
+				Kind:        ChangesetEventKindBitbucketCloudCommitStatus,
+				Key:         "deadbeef:ci",
+				Metadata:    status,
+			}},
+		})
+	}
+
 	for _, tc := range cases {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
@@ -303,6 +393,22 @@ func TestChangesetEventsReviewState(t *testing.T) {
 		}
 	}
 
+	glApproval := func(t time.Time, username string, kind ChangesetEventKind) *ChangesetEvent {
+		note := &gitlab.Note{CreatedAt: t, Author: gitlab.User{Username: username}}
+		e := &ChangesetEvent{Kind: kind}
+		if kind == ChangesetEventKindGitLabApproved {
+			e.Metadata = &gitlab.ReviewApprovedEvent{Note: note}
+		} else {
+			e.Metadata = &gitlab.ReviewUnapprovedEvent{Note: note}
+		}
+		return e
+	}
+
+	bbcActivity := func(t time.Time, uuid string, kind bitbucketcloud.ActivityKind) *ChangesetEvent {
+		a := &bitbucketcloud.Activity{Kind: kind, User: bitbucketcloud.Account{UUID: uuid}, Date: t}
+		return &ChangesetEvent{Kind: ChangesetEventKindFor(a), Metadata: a}
+	}
+
 	tests := []struct {
 		events ChangesetEvents
 		want   ChangesetReviewState
@@ -466,6 +572,49 @@ func TestChangesetEventsReviewState(t *testing.T) {
 			},
 			want: ChangesetReviewStateChangesRequested,
 		},
+		{
+			events: ChangesetEvents{
+				glApproval(daysAgo(1), "user1", ChangesetEventKindGitLabApproved),
+			},
+			want: ChangesetReviewStateApproved,
+		},
+		{
+			events: ChangesetEvents{
+				glApproval(daysAgo(2), "user1", ChangesetEventKindGitLabApproved),
+				glApproval(daysAgo(1), "user1", ChangesetEventKindGitLabUnapproved),
+			},
+			want: ChangesetReviewStatePending,
+		},
+		{
+			events: ChangesetEvents{
+				glApproval(daysAgo(2), "user1", ChangesetEventKindGitLabApproved),
+				glApproval(daysAgo(1), "user2", ChangesetEventKindGitLabApproved),
+				glApproval(daysAgo(0), "user1", ChangesetEventKindGitLabUnapproved),
+			},
+			want: ChangesetReviewStateApproved,
+		},
+		{
+			events: ChangesetEvents{
+				bbcActivity(daysAgo(2), "user1", bitbucketcloud.ActivityKindApproved),
+				bbcActivity(daysAgo(1), "user2", bitbucketcloud.ActivityKindChangesRequested),
+			},
+			want: ChangesetReviewStateChangesRequested,
+		},
+		{
+			events: ChangesetEvents{
+				bbcActivity(daysAgo(2), "user1", bitbucketcloud.ActivityKindApproved),
+				bbcActivity(daysAgo(1), "user2", bitbucketcloud.ActivityKindChangesRequested),
+				bbcActivity(daysAgo(0), "user2", bitbucketcloud.ActivityKindChangesRequestRemoved),
+			},
+			want: ChangesetReviewStateApproved,
+		},
+		{
+			events: ChangesetEvents{
+				bbcActivity(daysAgo(1), "user1", bitbucketcloud.ActivityKindApproved),
+				bbcActivity(daysAgo(0), "user1", bitbucketcloud.ActivityKindUnapproved),
+			},
+			want: ChangesetReviewStatePending,
+		},
 	}
 
 	for i, tc := range tests {
@@ -729,6 +878,258 @@ func TestComputeBitbucketBuildStatus(t *testing.T) {
 	}
 }
 
+func TestComputeGitLabCheckState(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	pipelineEvent := func(id int64, status gitlab.PipelineStatus, updatedAt time.Time) *ChangesetEvent {
+		return &ChangesetEvent{
+			Kind:     ChangesetEventKindGitLabPipeline,
+			Metadata: &gitlab.Pipeline{ID: id, Status: status, UpdatedAt: updatedAt},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		pipelines []*gitlab.Pipeline
+		events    []*ChangesetEvent
+		want      ChangesetCheckState
+	}{
+		{
+			name: "no pipelines",
+			want: ChangesetCheckStateUnknown,
+		},
+		{
+			name: "synced pipeline",
+			pipelines: []*gitlab.Pipeline{
+				{ID: 1, Status: gitlab.PipelineStatusRunning, UpdatedAt: now},
+			},
+			want: ChangesetCheckStatePending,
+		},
+		{
+			name: "latest pipeline has precedence",
+			pipelines: []*gitlab.Pipeline{
+				{ID: 1, Status: gitlab.PipelineStatusFailed, UpdatedAt: now},
+				{ID: 2, Status: gitlab.PipelineStatusSuccess, UpdatedAt: now},
+			},
+			want: ChangesetCheckStatePassed,
+		},
+		{
+			name: "newer webhook event",
+			pipelines: []*gitlab.Pipeline{
+				{ID: 1, Status: gitlab.PipelineStatusRunning, UpdatedAt: now},
+			},
+			events: []*ChangesetEvent{
+				pipelineEvent(1, gitlab.PipelineStatusFailed, now.Add(time.Minute)),
+			},
+			want: ChangesetCheckStateFailed,
+		},
+		{
+			name: "older webhook event",
+			pipelines: []*gitlab.Pipeline{
+				{ID: 1, Status: gitlab.PipelineStatusSuccess, UpdatedAt: now},
+			},
+			events: []*ChangesetEvent{
+				pipelineEvent(1, gitlab.PipelineStatusRunning, now.Add(-time.Minute)),
+			},
+			want: ChangesetCheckStatePassed,
+		},
+		{
+			name: "new pipeline from webhook",
+			pipelines: []*gitlab.Pipeline{
+				{ID: 1, Status: gitlab.PipelineStatusSuccess, UpdatedAt: now},
+			},
+			events: []*ChangesetEvent{
+				pipelineEvent(2, gitlab.PipelineStatusCanceled, now.Add(-time.Minute)),
+			},
+			want: ChangesetCheckStateFailed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mr := &gitlab.MergeRequest{Pipelines: tc.pipelines}
+			have := computeGitLabCheckState(mr, tc.events)
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func TestComputeBitbucketCloudBuildStatus(t *testing.T) {
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	status := func(commit, key string, state bitbucketcloud.CommitStatusState, updatedOn time.Time) *bitbucketcloud.CommitStatus {
+		s := &bitbucketcloud.CommitStatus{StatusKey: key, State: state, UpdatedOn: updatedOn}
+		s.Links.Commit.Href = "https://api.bitbucket.org/2.0/repositories/org/repo/commit/" + commit
+		return s
+	}
+	event := func(s *bitbucketcloud.CommitStatus) *ChangesetEvent {
+		return &ChangesetEvent{Kind: ChangesetEventKindBitbucketCloudCommitStatus, Metadata: s}
+	}
+
+	tests := []struct {
+		name     string
+		statuses []*bitbucketcloud.CommitStatus
+		events   []*ChangesetEvent
+		want     ChangesetCheckState
+	}{
+		{
+			name: "no statuses",
+			want: ChangesetCheckStateUnknown,
+		},
+		{
+			name: "statuses of head commit",
+			statuses: []*bitbucketcloud.CommitStatus{
+				status("abcdef0123456789", "build", bitbucketcloud.CommitStatusStateSuccessful, now),
+				status("abcdef0123456789", "lint", bitbucketcloud.CommitStatusStateInProgress, now),
+			},
+			want: ChangesetCheckStatePending,
+		},
+		{
+			name: "statuses of other commits are ignored",
+			statuses: []*bitbucketcloud.CommitStatus{
+				status("0123456789abcdef", "build", bitbucketcloud.CommitStatusStateFailed, now),
+				status("abcdef0123456789", "build", bitbucketcloud.CommitStatusStateSuccessful, now),
+			},
+			want: ChangesetCheckStatePassed,
+		},
+		{
+			name: "newer webhook event",
+			statuses: []*bitbucketcloud.CommitStatus{
+				status("abcdef0123456789", "build", bitbucketcloud.CommitStatusStateInProgress, now),
+			},
+			events: []*ChangesetEvent{
+				event(status("abcdef0123456789", "build", bitbucketcloud.CommitStatusStateStopped, now.Add(time.Minute))),
+			},
+			want: ChangesetCheckStateFailed,
+		},
+		{
+			name: "older webhook event",
+			statuses: []*bitbucketcloud.CommitStatus{
+				status("abcdef0123456789", "build", bitbucketcloud.CommitStatusStateSuccessful, now),
+			},
+			events: []*ChangesetEvent{
+				event(status("abcdef0123456789", "build", bitbucketcloud.CommitStatusStateInProgress, now.Add(-time.Minute))),
+			},
+			want: ChangesetCheckStatePassed,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &bitbucketcloud.PullRequest{
+				Source: bitbucketcloud.PullRequestEndpoint{
+					Commit: &bitbucketcloud.PullRequestCommit{Hash: "abcdef012345"},
+				},
+				Statuses: tc.statuses,
+			}
+			have := computeBitbucketCloudBuildStatus(pr, tc.events)
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatalf(diff)
+			}
+		})
+	}
+}
+
+func TestFailedChecks(t *testing.T) {
+	ghPullRequest := func() *github.PullRequest {
+		var commit github.CommitWithChecks
+		commit.Commit.Status.Contexts = []github.Context{
+			{Context: "ci/lint", State: "FAILURE"},
+			{Context: "ci/build", State: "SUCCESS"},
+			{Context: "ci/deploy", State: "ERROR"},
+		}
+		suite := github.CheckSuite{ID: "cs1", Status: "COMPLETED", Conclusion: "FAILURE"}
+		suite.CheckRuns.Nodes = []github.CheckRun{
+			{ID: "cr1", Name: "test", Status: "COMPLETED", Conclusion: "TIMED_OUT"},
+			{ID: "cr2", Name: "ci/lint", Status: "COMPLETED", Conclusion: "FAILURE"},
+			{ID: "cr3", Name: "vet", Status: "COMPLETED", Conclusion: "SUCCESS"},
+			{ID: "cr4", Status: "COMPLETED", Conclusion: "FAILURE"},
+		}
+		commit.Commit.CheckSuites.Nodes = []github.CheckSuite{suite}
+
+		pr := &github.PullRequest{}
+		pr.Commits.Nodes = []github.CommitWithChecks{commit}
+		return pr
+	}
+
+	bbcsStatus := func(commit, key, name string, state bitbucketcloud.CommitStatusState) *bitbucketcloud.CommitStatus {
+		s := &bitbucketcloud.CommitStatus{StatusKey: key, Name: name, State: state}
+		s.Links.Commit.Href = "https://api.bitbucket.org/2.0/repositories/org/repo/commit/" + commit
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		metadata interface{}
+		want     []string
+	}{
+		{
+			name:     "no metadata",
+			metadata: nil,
+			want:     nil,
+		},
+		{
+			name:     "GitHub statuses and check runs",
+			metadata: ghPullRequest(),
+			want:     []string{"ci/deploy", "ci/lint", "test"},
+		},
+		{
+			name: "Bitbucket Server commit statuses",
+			metadata: &bitbucketserver.PullRequest{
+				CommitStatus: []*bitbucketserver.CommitStatus{
+					{Status: bitbucketserver.BuildStatus{State: "FAILED", Key: "build-key", Name: "build"}},
+					{Status: bitbucketserver.BuildStatus{State: "FAILED", Key: "lint-key"}},
+					{Status: bitbucketserver.BuildStatus{State: "SUCCESSFUL", Key: "test-key", Name: "test"}},
+				},
+			},
+			want: []string{"build", "lint-key"},
+		},
+		{
+			name: "GitLab failed latest pipeline",
+			metadata: &gitlab.MergeRequest{
+				Pipelines: []*gitlab.Pipeline{
+					{ID: 2, Status: gitlab.PipelineStatusCanceled},
+					{ID: 1, Status: gitlab.PipelineStatusSuccess},
+				},
+			},
+			want: []string{"pipeline canceled"},
+		},
+		{
+			name: "GitLab passed latest pipeline",
+			metadata: &gitlab.MergeRequest{
+				Pipelines: []*gitlab.Pipeline{
+					{ID: 1, Status: gitlab.PipelineStatusFailed},
+					{ID: 2, Status: gitlab.PipelineStatusSuccess},
+				},
+			},
+			want: nil,
+		},
+		{
+			name: "Bitbucket Cloud statuses of head commit",
+			metadata: &bitbucketcloud.PullRequest{
+				Source: bitbucketcloud.PullRequestEndpoint{
+					Commit: &bitbucketcloud.PullRequestCommit{Hash: "abcdef012345"},
+				},
+				Statuses: []*bitbucketcloud.CommitStatus{
+					bbcsStatus("abcdef0123456789", "build", "Build", bitbucketcloud.CommitStatusStateStopped),
+					bbcsStatus("abcdef0123456789", "lint", "", bitbucketcloud.CommitStatusStateFailed),
+					bbcsStatus("0123456789abcdef", "test", "Test", bitbucketcloud.CommitStatusStateFailed),
+				},
+			},
+			want: []string{"Build", "lint"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			have := FailedChecks(&Changeset{Metadata: tc.metadata})
+			if diff := cmp.Diff(tc.want, have); diff != "" {
+				t.Fatal(diff)
+			}
+		})
+	}
+}
+
 func TestChangesetEventsLabels(t *testing.T) {
 	now := time.Now()
 	labelEvent := func(name string, kind ChangesetEventKind, when time.Time) *ChangesetEvent {
@@ -820,6 +1221,274 @@ func TestChangesetEventsLabels(t *testing.T) {
 	}
 }
 
+func TestAutoMergePolicyInWindow(t *testing.T) {
+	// 2020-04-06 is a Monday.
+	at := func(day int, clock string) time.Time {
+		t, err := time.Parse("2006-01-02 15:04", fmt.Sprintf("2020-04-%02d %s", day, clock))
+		if err != nil {
+			panic(err)
+		}
+		return t
+	}
+
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+	tests := []struct {
+		name   string
+		policy AutoMergePolicy
+		t      time.Time
+		want   bool
+	}{
+		{
+			name:   "no window",
+			policy: AutoMergePolicy{},
+			t:      at(5, "03:00"),
+			want:   true,
+		},
+		{
+			name:   "on allowed day",
+			policy: AutoMergePolicy{Days: weekdays},
+			t:      at(6, "03:00"),
+			want:   true,
+		},
+		{
+			name:   "on other day",
+			policy: AutoMergePolicy{Days: weekdays},
+			t:      at(5, "03:00"),
+			want:   false,
+		},
+		{
+			name:   "in window",
+			policy: AutoMergePolicy{WindowStart: "09:00", WindowEnd: "17:00"},
+			t:      at(6, "09:00"),
+			want:   true,
+		},
+		{
+			name:   "at end of window",
+			policy: AutoMergePolicy{WindowStart: "09:00", WindowEnd: "17:00"},
+			t:      at(6, "17:00"),
+			want:   false,
+		},
+		{
+			name:   "in window spanning midnight",
+			policy: AutoMergePolicy{WindowStart: "22:00", WindowEnd: "04:00"},
+			t:      at(6, "23:30"),
+			want:   true,
+		},
+		{
+			name:   "outside window spanning midnight",
+			policy: AutoMergePolicy{WindowStart: "22:00", WindowEnd: "04:00"},
+			t:      at(6, "12:00"),
+			want:   false,
+		},
+		{
+			name:   "window spanning midnight started on allowed day",
+			policy: AutoMergePolicy{Days: weekdays, WindowStart: "22:00", WindowEnd: "04:00"},
+			t:      at(11, "02:00"),
+			want:   true,
+		},
+		{
+			name:   "window spanning midnight started on other day",
+			policy: AutoMergePolicy{Days: weekdays, WindowStart: "22:00", WindowEnd: "04:00"},
+			t:      at(6, "02:00"),
+			want:   false,
+		},
+		{
+			name:   "converts to UTC",
+			policy: AutoMergePolicy{WindowStart: "09:00", WindowEnd: "17:00"},
+			t:      at(6, "10:00").In(time.FixedZone("UTC-10", -10*60*60)),
+			want:   true,
+		},
+		{
+			name:   "in window in time zone",
+			policy: AutoMergePolicy{WindowStart: "09:00", WindowEnd: "17:00", TimeZone: "America/Los_Angeles"},
+			t:      at(6, "20:00"),
+			want:   true,
+		},
+		{
+			name:   "outside window in time zone",
+			policy: AutoMergePolicy{WindowStart: "09:00", WindowEnd: "17:00", TimeZone: "America/Los_Angeles"},
+			t:      at(6, "10:00"),
+			want:   false,
+		},
+		{
+			name:   "on other day in time zone",
+			policy: AutoMergePolicy{Days: weekdays, TimeZone: "Asia/Tokyo"},
+			t:      at(10, "16:00"),
+			want:   false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if have := tc.policy.InWindow(tc.t); have != tc.want {
+				t.Fatalf("InWindow(%s): have %t, want %t", tc.t, have, tc.want)
+			}
+		})
+	}
+}
+
+func TestAutoMergePolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy AutoMergePolicy
+		err    string
+	}{
+		{
+			name:   "valid",
+			policy: AutoMergePolicy{Method: ChangesetMergeMethodSquash, Days: []time.Weekday{time.Monday}, WindowStart: "22:00", WindowEnd: "04:00"},
+			err:    "<nil>",
+		},
+		{
+			name:   "invalid method",
+			policy: AutoMergePolicy{Method: "FAST_FORWARD"},
+			err:    `invalid merge method "FAST_FORWARD"`,
+		},
+		{
+			name:   "only window start",
+			policy: AutoMergePolicy{Method: ChangesetMergeMethodMerge, WindowStart: "09:00"},
+			err:    "merge window needs both a start and an end",
+		},
+		{
+			name:   "invalid window time",
+			policy: AutoMergePolicy{Method: ChangesetMergeMethodMerge, WindowStart: "9am", WindowEnd: "17:00"},
+			err:    `invalid merge window time "9am", expected HH:MM`,
+		},
+		{
+			name:   "empty window",
+			policy: AutoMergePolicy{Method: ChangesetMergeMethodMerge, WindowStart: "09:00", WindowEnd: "09:00"},
+			err:    "merge window must not be empty",
+		},
+		{
+			name:   "valid time zone",
+			policy: AutoMergePolicy{Method: ChangesetMergeMethodMerge, WindowStart: "09:00", WindowEnd: "17:00", TimeZone: "Europe/Berlin"},
+			err:    "<nil>",
+		},
+		{
+			name:   "invalid time zone",
+			policy: AutoMergePolicy{Method: ChangesetMergeMethodMerge, TimeZone: "Mars/Olympus_Mons"},
+			err:    `invalid merge window time zone "Mars/Olympus_Mons"`,
+		},
+		{
+			name:   "local time zone",
+			policy: AutoMergePolicy{Method: ChangesetMergeMethodMerge, TimeZone: "Local"},
+			err:    `invalid merge window time zone "Local"`,
+		},
+		{
+			name:   "negative merges per repo",
+			policy: AutoMergePolicy{Method: ChangesetMergeMethodRebase, MaxMergesPerRepo: -1},
+			err:    "maximum number of merges per repository must not be negative",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if have, want := fmt.Sprint(tc.policy.Validate()), tc.err; have != want {
+				t.Fatalf("error:\nhave: %q\nwant: %q", have, want)
+			}
+		})
+	}
+}
+
+func TestPublishPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy PublishPolicy
+		err    string
+	}{
+		{
+			name:   "valid",
+			policy: PublishPolicy{MaxChangesetsPerHour: 10, Days: []time.Weekday{time.Monday}, WindowStart: "09:00", WindowEnd: "17:00", TimeZone: "Asia/Kolkata", InitialBatchSize: 10},
+			err:    "<nil>",
+		},
+		{
+			name:   "negative changesets per hour",
+			policy: PublishPolicy{MaxChangesetsPerHour: -1},
+			err:    "maximum number of changesets per hour must not be negative",
+		},
+		{
+			name:   "only window end",
+			policy: PublishPolicy{WindowEnd: "17:00"},
+			err:    "publication window needs both a start and an end",
+		},
+		{
+			name:   "invalid day",
+			policy: PublishPolicy{Days: []time.Weekday{7}},
+			err:    "invalid day of the week 7",
+		},
+		{
+			name:   "invalid time zone",
+			policy: PublishPolicy{WindowStart: "09:00", WindowEnd: "17:00", TimeZone: "CEST"},
+			err:    `invalid publication window time zone "CEST"`,
+		},
+		{
+			name:   "negative initial batch size",
+			policy: PublishPolicy{InitialBatchSize: -1},
+			err:    "initial batch size must not be negative",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if have, want := fmt.Sprint(tc.policy.Validate()), tc.err; have != want {
+				t.Fatalf("error:\nhave: %q\nwant: %q", have, want)
+			}
+		})
+	}
+}
+
+func TestChangesetActionValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		action ChangesetAction
+		err    string
+	}{
+		{
+			name:   "comment",
+			action: ChangesetAction{Kind: ChangesetActionKindComment, Comment: "Friendly reminder"},
+			err:    "<nil>",
+		},
+		{
+			name:   "empty comment",
+			action: ChangesetAction{Kind: ChangesetActionKindComment, Comment: " "},
+			err:    "comment must not be empty",
+		},
+		{
+			name:   "add labels",
+			action: ChangesetAction{Kind: ChangesetActionKindAddLabels, Labels: []string{"campaign"}},
+			err:    "<nil>",
+		},
+		{
+			name:   "no labels",
+			action: ChangesetAction{Kind: ChangesetActionKindRemoveLabels},
+			err:    "labels must not be empty",
+		},
+		{
+			name:   "blank label",
+			action: ChangesetAction{Kind: ChangesetActionKindAddLabels, Labels: []string{"campaign", ""}},
+			err:    "labels must not be blank",
+		},
+		{
+			name:   "rerun checks",
+			action: ChangesetAction{Kind: ChangesetActionKindRerunChecks},
+			err:    "<nil>",
+		},
+		{
+			name:   "invalid kind",
+			action: ChangesetAction{Kind: "CLOSE"},
+			err:    `invalid changeset action "CLOSE"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if have, want := fmt.Sprint(tc.action.Validate()), tc.err; have != want {
+				t.Fatalf("error:\nhave: %q\nwant: %q", have, want)
+			}
+		})
+	}
+}
+
 func timeToUnixMilli(t time.Time) int {
 	return int(t.UnixNano()) / int(time.Millisecond)
 }
@@ -0,0 +1,234 @@
+// Package codeowners parses CODEOWNERS files, as used by GitHub and GitLab,
+// and finds the owners of paths in a repository.
+package codeowners
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Paths are the paths at which CODEOWNERS files are looked up in a
+// repository, in order of precedence. GitHub and GitLab use the first one
+// that exists.
+var Paths = []string{
+	".github/CODEOWNERS",
+	"CODEOWNERS",
+	"docs/CODEOWNERS",
+	".gitlab/CODEOWNERS",
+}
+
+// A File is a parsed CODEOWNERS file.
+type File struct {
+	Sections []*Section
+}
+
+// A Section is a list of rules. Rules without a section header in the file
+// belong to a section without a name. GitLab evaluates the rules of each
+// section separately.
+type Section struct {
+	Name  string
+	Rules []*Rule
+}
+
+// A Rule assigns owners to the paths matching a pattern.
+type Rule struct {
+	Pattern string
+	// Owners are the owners of the matching paths as written in the file:
+	// "@username", "@org/team" or an e-mail address. It's empty if the
+	// matching paths have no owners.
+	Owners []string
+
+	re *regexp.Regexp
+}
+
+// Match returns true if the Rule's pattern matches the given path.
+func (r *Rule) Match(path string) bool {
+	return r.re.MatchString(strings.TrimPrefix(path, "/"))
+}
+
+// Parse parses a CODEOWNERS file in the syntax used by GitHub, including the
+// sections supported by GitLab.
+func Parse(r io.Reader) (*File, error) {
+	var (
+		f        = &File{}
+		section  = &Section{}
+		defaults []string
+	)
+	f.Sections = append(f.Sections, section)
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") || strings.HasPrefix(line, "^[") {
+			name, owners, err := parseSectionHeader(line)
+			if err != nil {
+				return nil, errors.Wrapf(err, "line %d", n)
+			}
+			section, defaults = &Section{Name: name}, owners
+			f.Sections = append(f.Sections, section)
+			continue
+		}
+
+		fields := splitFields(line)
+		rule := &Rule{Pattern: fields[0], Owners: fields[1:]}
+		if len(rule.Owners) == 0 {
+			rule.Owners = defaults
+		}
+
+		re, err := compilePattern(rule.Pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "line %d: invalid pattern %q", n, rule.Pattern)
+		}
+		rule.re = re
+
+		section.Rules = append(section.Rules, rule)
+	}
+
+	return f, s.Err()
+}
+
+// Owners returns the owners of the given path. In each section, the last
+// matching rule determines the owners. The owners of all sections are
+// combined.
+func (f *File) Owners(path string) []string {
+	var owners []string
+	seen := map[string]bool{}
+
+	for _, s := range f.Sections {
+		for i := len(s.Rules) - 1; i >= 0; i-- {
+			if !s.Rules[i].Match(path) {
+				continue
+			}
+			for _, o := range s.Rules[i].Owners {
+				if !seen[o] {
+					seen[o] = true
+					owners = append(owners, o)
+				}
+			}
+			break
+		}
+	}
+
+	return owners
+}
+
+// parseSectionHeader parses a GitLab section header, such as
+// "^[Documentation][2] @docs-team", into the section name and default owners.
+func parseSectionHeader(line string) (name string, owners []string, err error) {
+	line = strings.TrimPrefix(line, "^")
+
+	end := strings.Index(line, "]")
+	if end < 0 {
+		return "", nil, errors.New("unterminated section header")
+	}
+	name, line = line[1:end], line[end+1:]
+
+	// Skip the number of required approvals.
+	if strings.HasPrefix(line, "[") {
+		end := strings.Index(line, "]")
+		if end < 0 {
+			return "", nil, errors.New("unterminated number of approvals")
+		}
+		line = line[end+1:]
+	}
+
+	if fields := splitFields(line); len(fields) > 0 {
+		owners = fields
+	}
+	return name, owners, nil
+}
+
+// splitFields splits a line into whitespace separated fields, up to an inline
+// comment. Escaped spaces and number signs are part of a field.
+func splitFields(line string) (fields []string) {
+	var field strings.Builder
+	flush := func() {
+		if field.Len() > 0 {
+			fields = append(fields, field.String())
+			field.Reset()
+		}
+	}
+
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line) && (line[i+1] == ' ' || line[i+1] == '#'):
+			field.WriteByte(line[i+1])
+			i++
+		case c == ' ' || c == '\t':
+			flush()
+		case c == '#' && field.Len() == 0:
+			return fields
+		default:
+			field.WriteByte(c)
+		}
+	}
+	flush()
+
+	return fields
+}
+
+// compilePattern compiles a gitignore-style pattern into a regular expression
+// matching relative paths:
+//
+//   - A pattern containing a slash, other than a trailing one, is relative to
+//     the root of the repository. Other patterns match at any depth.
+//   - "*" matches anything but a slash, "?" any single character but a slash
+//     and "**" matches anything, including slashes.
+//   - A pattern matching a directory matches all files in it, unless its last
+//     segment ends with "*", which only matches the files directly in it.
+//   - A pattern ending in a slash only matches directories.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	p := pattern
+	onlyDirs := strings.HasSuffix(p, "/")
+	p = strings.TrimSuffix(p, "/")
+
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return nil, errors.New("empty pattern")
+	}
+
+	var b strings.Builder
+	b.WriteString("^")
+	if !anchored {
+		b.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(p); i++ {
+		switch c := p[i]; c {
+		case '*':
+			switch {
+			case strings.HasPrefix(p[i:], "**/"):
+				b.WriteString("(?:.*/)?")
+				i += 2
+			case strings.HasPrefix(p[i:], "**"):
+				b.WriteString(".*")
+				i++
+			default:
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	switch {
+	case onlyDirs:
+		b.WriteString("/.*")
+	case !strings.HasSuffix(p, "*"):
+		b.WriteString("(?:/.*)?")
+	}
+	b.WriteString("$")
+
+	return regexp.Compile(b.String())
+}
@@ -0,0 +1,148 @@
+package codeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{
+			pattern: "*",
+			match:   []string{"README.md", "cmd/main.go"},
+		},
+		{
+			pattern: "*.js",
+			match:   []string{"index.js", "web/src/app.js"},
+			noMatch: []string{"index.jsx", "web/src/app.ts"},
+		},
+		{
+			pattern: "/docs/",
+			match:   []string{"docs/index.md", "docs/api/graphql.md"},
+			noMatch: []string{"docs", "web/docs/index.md"},
+		},
+		{
+			pattern: "apps/",
+			match:   []string{"apps/main.go", "web/apps/main.go"},
+			noMatch: []string{"apps", "myapps/main.go"},
+		},
+		{
+			pattern: "docs/*",
+			match:   []string{"docs/getting-started.md"},
+			noMatch: []string{"docs/build-app/troubleshooting.md", "web/docs/getting-started.md"},
+		},
+		{
+			pattern: "/build/logs",
+			match:   []string{"build/logs", "build/logs/out.log"},
+			noMatch: []string{"build/logs.txt", "src/build/logs/out.log"},
+		},
+		{
+			pattern: "**/logs",
+			match:   []string{"logs/out.log", "build/logs/out.log", "deeply/nested/logs/out.log"},
+			noMatch: []string{"build/logs.txt"},
+		},
+		{
+			pattern: "internal/**/testdata",
+			match:   []string{"internal/testdata/a.json", "internal/vcs/git/testdata/a.json"},
+			noMatch: []string{"cmd/internal/testdata/a.json"},
+		},
+		{
+			pattern: "/scripts/**",
+			match:   []string{"scripts/build.sh", "scripts/ci/test.sh"},
+			noMatch: []string{"web/scripts/build.sh"},
+		},
+		{
+			pattern: "go.?od",
+			match:   []string{"go.mod", "internal/go.mod"},
+			noMatch: []string{"go.sum"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern, func(t *testing.T) {
+			re, err := compilePattern(tc.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := &Rule{Pattern: tc.pattern, re: re}
+
+			for _, path := range tc.match {
+				if !r.Match(path) {
+					t.Errorf("%q does not match %q", tc.pattern, path)
+				}
+			}
+			for _, path := range tc.noMatch {
+				if r.Match(path) {
+					t.Errorf("%q matches %q", tc.pattern, path)
+				}
+			}
+		})
+	}
+}
+
+func TestFileOwners(t *testing.T) {
+	f, err := Parse(strings.NewReader(`
+# Default owners of everything.
+*       @global-owner1 @global-owner2
+
+*.js    @js-owner # Owners of JavaScript files.
+*.go    docs@example.com
+/build/logs/ @doctocat
+/vendor/
+foo\ bar/ @spaces
+
+[Documentation] @docs-team
+docs/
+README.md @readme-owner
+
+^[Database][2] @database-team
+*.sql
+/migrations/ @dba
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want []string
+	}{
+		{path: "cmd/main.c", want: []string{"@global-owner1", "@global-owner2"}},
+		{path: "web/app.js", want: []string{"@js-owner"}},
+		{path: "cmd/main.go", want: []string{"docs@example.com"}},
+		{path: "build/logs/out.log", want: []string{"@doctocat"}},
+		{path: "vendor/lib.go", want: nil},
+		{path: "foo bar/baz.txt", want: []string{"@spaces"}},
+		{path: "docs/index.md", want: []string{"@global-owner1", "@global-owner2", "@docs-team"}},
+		{path: "README.md", want: []string{"@global-owner1", "@global-owner2", "@readme-owner"}},
+		{path: "migrations/1_init.up.sql", want: []string{"@global-owner1", "@global-owner2", "@dba"}},
+		{path: "schema.sql", want: []string{"@global-owner1", "@global-owner2", "@database-team"}},
+	}
+
+	for _, tc := range tests {
+		if diff := cmp.Diff(tc.want, f.Owners(tc.path)); diff != "" {
+			t.Errorf("owners of %q:\n%s", tc.path, diff)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, tc := range []struct {
+		file string
+		err  string
+	}{
+		{file: "[Docs @docs-team", err: "line 1: unterminated section header"},
+		{file: "*.go @go\n/ @root", err: `line 2: invalid pattern "/": empty pattern`},
+	} {
+		_, err := Parse(strings.NewReader(tc.file))
+		if err == nil || err.Error() != tc.err {
+			t.Errorf("Parse(%q): have error %v, want %q", tc.file, err, tc.err)
+		}
+	}
+}
@@ -13,13 +13,14 @@ import (
 
 // Repository is an AWS CodeCommit repository.
 type Repository struct {
-	ARN          string     // the ARN (Amazon Resource Name) of the repository
-	AccountID    string     // the ID of the AWS account associated with the repository
-	ID           string     // the ID of the repository
-	Name         string     // the name of the repository
-	Description  string     // the description of the repository
-	HTTPCloneURL string     // the HTTP(S) clone URL of the repository
-	LastModified *time.Time // the last modified date of the repository
+	ARN           string     // the ARN (Amazon Resource Name) of the repository
+	AccountID     string     // the ID of the AWS account associated with the repository
+	ID            string     // the ID of the repository
+	Name          string     // the name of the repository
+	Description   string     // the description of the repository
+	HTTPCloneURL  string     // the HTTP(S) clone URL of the repository
+	LastModified  *time.Time // the last modified date of the repository
+	DefaultBranch string     // the name of the repository's default branch, empty if it has no commits
 }
 
 func (c *Client) repositoryCacheKey(ctx context.Context, arn string) (string, error) {
@@ -232,5 +233,8 @@ func fromRepoMetadata(m *codecommit.RepositoryMetadata) *Repository {
 	if m.RepositoryDescription != nil {
 		repo.Description = *m.RepositoryDescription
 	}
+	if m.DefaultBranch != nil {
+		repo.DefaultBranch = *m.DefaultBranch
+	}
 	return &repo
 }
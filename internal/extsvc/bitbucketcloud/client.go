@@ -18,6 +18,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/metrics"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"golang.org/x/time/rate"
 )
 
@@ -59,6 +60,11 @@ type Client struct {
 	// RateLimit is the self-imposed rate limiter (since Bitbucket does not have a concept
 	// of rate limiting in HTTP response headers).
 	RateLimit *rate.Limiter
+
+	// budgets is used to get the rate limit budget of the client's
+	// credentials. All clients share ratelimit.DefaultBudgetManager, except
+	// in tests.
+	budgets *ratelimit.BudgetManager
 }
 
 // NewClient creates a new Bitbucket Cloud API client with given apiURL. If a nil httpClient
@@ -92,9 +98,18 @@ func NewClient(apiURL *url.URL, httpClient httpcli.Doer) *Client {
 		httpClient: httpClient,
 		URL:        apiURL,
 		RateLimit:  l,
+		budgets:    ratelimit.DefaultBudgetManager,
 	}
 }
 
+// budget returns the rate limit budget of the client's credentials. Bitbucket
+// Cloud doesn't report its remaining rate limit, so the budget only makes
+// requests with a background priority honour Retry-After responses, on top of
+// the self-imposed RateLimit.
+func (c *Client) budget() *ratelimit.Budget {
+	return c.budgets.Get(c.URL, c.Username+":"+c.AppPassword, "", "X-")
+}
+
 // Repos returns a list of repositories that are fetched and populated based on given account
 // name and pagination criteria. If the account requested is a team, results will be filtered
 // down to the ones that the app password's user has access to.
@@ -167,6 +182,11 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 		return err
 	}
 
+	budget := c.budget()
+	if err := budget.Reserve(ctx, 1); err != nil {
+		return err
+	}
+
 	startWait := time.Now()
 	if err := c.RateLimit.Wait(ctx); err != nil {
 		return err
@@ -182,6 +202,7 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 	}
 
 	defer resp.Body.Close()
+	budget.Update(resp.Header)
 
 	bs, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
@@ -242,9 +263,17 @@ type Repo struct {
 	Description string `json:"description"`
 	Parent      *Repo  `json:"parent"`
 	IsPrivate   bool   `json:"is_private"`
+	Language    string `json:"language"`
+	MainBranch  *Ref   `json:"mainbranch,omitempty"`
 	Links       Links  `json:"links"`
 }
 
+// Ref is a named reference (such as a branch) of a repository.
+type Ref struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
 type Links struct {
 	Clone CloneLinks `json:"clone"`
 	HTML  Link       `json:"html"`
@@ -23,12 +23,13 @@ func TestClient_Repos(t *testing.T) {
 
 	repos := map[string]*Repo{
 		"mux": {
-			Slug:      "mux",
-			Name:      "mux",
-			FullName:  "sglocal/mux",
-			UUID:      "{e1e75436-05e6-4c38-8543-9c36ec26fad1}",
-			SCM:       "git",
-			IsPrivate: true,
+			Slug:       "mux",
+			Name:       "mux",
+			FullName:   "sglocal/mux",
+			UUID:       "{e1e75436-05e6-4c38-8543-9c36ec26fad1}",
+			SCM:        "git",
+			IsPrivate:  true,
+			MainBranch: &Ref{Type: "branch", Name: "master"},
 			Links: Links{
 				Clone: CloneLinks{
 					{"https://Unknwon@bitbucket.org/sglocal/mux.git", "https"},
@@ -38,12 +39,13 @@ func TestClient_Repos(t *testing.T) {
 			},
 		},
 		"python-langserver": {
-			Slug:      "python-langserver",
-			Name:      "python-langserver",
-			FullName:  "sglocal/python-langserver",
-			UUID:      "{421b93e9-1f00-4054-8156-4d821d4a768b}",
-			SCM:       "git",
-			IsPrivate: false,
+			Slug:       "python-langserver",
+			Name:       "python-langserver",
+			FullName:   "sglocal/python-langserver",
+			UUID:       "{421b93e9-1f00-4054-8156-4d821d4a768b}",
+			SCM:        "git",
+			IsPrivate:  false,
+			MainBranch: &Ref{Type: "branch", Name: "master"},
 			Links: Links{
 				Clone: CloneLinks{
 					{"https://Unknwon@bitbucket.org/sglocal/python-langserver.git", "https"},
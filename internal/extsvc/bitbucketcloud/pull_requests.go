@@ -0,0 +1,410 @@
+package bitbucketcloud
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PullRequestState is the state of a Bitbucket Cloud pull request.
+type PullRequestState string
+
+// Known PullRequestStates
+const (
+	PullRequestStateOpen       PullRequestState = "OPEN"
+	PullRequestStateMerged     PullRequestState = "MERGED"
+	PullRequestStateDeclined   PullRequestState = "DECLINED"
+	PullRequestStateSuperseded PullRequestState = "SUPERSEDED"
+)
+
+// A PullRequest of a Bitbucket Cloud repository.
+type PullRequest struct {
+	ID                int64               `json:"id"`
+	Title             string              `json:"title"`
+	Description       string              `json:"description"`
+	State             PullRequestState    `json:"state"`
+	Author            Account             `json:"author"`
+	Source            PullRequestEndpoint `json:"source"`
+	Destination       PullRequestEndpoint `json:"destination"`
+	Participants      []Participant       `json:"participants"`
+	CloseSourceBranch bool                `json:"close_source_branch"`
+	CreatedOn         time.Time           `json:"created_on"`
+	UpdatedOn         time.Time           `json:"updated_on"`
+	Links             Links               `json:"links"`
+
+	// Activities and Statuses aren't part of the pull request returned by the
+	// API. They are loaded with LoadPullRequestActivities and
+	// LoadPullRequestStatuses.
+	Activities []*Activity     `json:"activities,omitempty"`
+	Statuses   []*CommitStatus `json:"statuses,omitempty"`
+}
+
+// An Account is a Bitbucket Cloud user or team.
+type Account struct {
+	UUID        string `json:"uuid"`
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname,omitempty"`
+	AccountID   string `json:"account_id,omitempty"`
+}
+
+// PullRequestEndpoint is the source or destination of a pull request.
+type PullRequestEndpoint struct {
+	Branch     PullRequestBranch  `json:"branch"`
+	Commit     *PullRequestCommit `json:"commit,omitempty"`
+	Repository *PullRequestRepo   `json:"repository,omitempty"`
+}
+
+// PullRequestBranch is the branch of a PullRequestEndpoint.
+type PullRequestBranch struct {
+	Name string `json:"name"`
+}
+
+// PullRequestCommit is the commit of a PullRequestEndpoint. The API returns
+// abbreviated hashes.
+type PullRequestCommit struct {
+	Hash string `json:"hash"`
+}
+
+// PullRequestRepo is the repository of a PullRequestEndpoint.
+type PullRequestRepo struct {
+	FullName string `json:"full_name"`
+	UUID     string `json:"uuid"`
+}
+
+// A Participant of a pull request, such as a reviewer.
+type Participant struct {
+	User           Account    `json:"user"`
+	Role           string     `json:"role"`
+	Approved       bool       `json:"approved"`
+	State          string     `json:"state,omitempty"`
+	ParticipatedOn *time.Time `json:"participated_on,omitempty"`
+}
+
+// Known Participant states
+const (
+	ParticipantStateApproved         = "approved"
+	ParticipantStateChangesRequested = "changes_requested"
+)
+
+// ActivityKind is the kind of an Activity.
+type ActivityKind string
+
+// Known ActivityKinds
+const (
+	ActivityKindApproved              ActivityKind = "approved"
+	ActivityKindUnapproved            ActivityKind = "unapproved"
+	ActivityKindChangesRequested      ActivityKind = "changes_requested"
+	ActivityKindChangesRequestRemoved ActivityKind = "changes_request_removed"
+	ActivityKindCommented             ActivityKind = "commented"
+	ActivityKindDeclined              ActivityKind = "declined"
+	ActivityKindMerged                ActivityKind = "merged"
+)
+
+// An Activity is an event in the lifetime of a pull request. The activity API
+// of Bitbucket Cloud doesn't list unapprovals and removed change requests,
+// those are only received by webhooks.
+type Activity struct {
+	Kind    ActivityKind `json:"kind"`
+	User    Account      `json:"user"`
+	Date    time.Time    `json:"date"`
+	Comment *Comment     `json:"comment,omitempty"`
+}
+
+// Key is a unique key identifying this activity in the context of its pull
+// request. A pull request can only be declined or merged once, and comments
+// have IDs; other activities are identified by their kind, user and time.
+func (a *Activity) Key() string {
+	switch a.Kind {
+	case ActivityKindDeclined, ActivityKindMerged:
+		return string(a.Kind)
+	case ActivityKindCommented:
+		if a.Comment != nil {
+			return fmt.Sprintf("%s:%d", a.Kind, a.Comment.ID)
+		}
+	}
+	return fmt.Sprintf("%s:%s:%d", a.Kind, a.User.UUID, a.Date.Unix())
+}
+
+// A Comment on a pull request.
+type Comment struct {
+	ID        int64          `json:"id"`
+	Content   CommentContent `json:"content"`
+	User      Account        `json:"user"`
+	CreatedOn time.Time      `json:"created_on"`
+	UpdatedOn time.Time      `json:"updated_on"`
+}
+
+// CommentContent is the content of a Comment.
+type CommentContent struct {
+	Raw string `json:"raw"`
+}
+
+// CommitStatusState is the state of a CommitStatus.
+type CommitStatusState string
+
+// Known CommitStatusStates
+const (
+	CommitStatusStateSuccessful CommitStatusState = "SUCCESSFUL"
+	CommitStatusStateFailed     CommitStatusState = "FAILED"
+	CommitStatusStateInProgress CommitStatusState = "INPROGRESS"
+	CommitStatusStateStopped    CommitStatusState = "STOPPED"
+)
+
+// A CommitStatus is the status of a build of a commit.
+type CommitStatus struct {
+	StatusKey string            `json:"key"`
+	Name      string            `json:"name"`
+	URL       string            `json:"url"`
+	State     CommitStatusState `json:"state"`
+	RefName   string            `json:"refname,omitempty"`
+	CreatedOn time.Time         `json:"created_on"`
+	UpdatedOn time.Time         `json:"updated_on"`
+	Links     struct {
+		Commit Link `json:"commit"`
+	} `json:"links"`
+}
+
+// Commit returns the hash of the commit of the status.
+func (s *CommitStatus) Commit() string {
+	return path.Base(s.Links.Commit.Href)
+}
+
+// Key is a unique key identifying this status in the context of its pull
+// request.
+func (s *CommitStatus) Key() string {
+	return fmt.Sprintf("%s:%s", s.Commit(), s.StatusKey)
+}
+
+// ErrPullRequestNotFound is returned by GetOpenPullRequestByRefs if no open
+// pull request exists for the given branches.
+var ErrPullRequestNotFound = errors.New("pull request not found")
+
+// CreatePullRequestOpts are the options of CreatePullRequest.
+type CreatePullRequestOpts struct {
+	Title        string
+	Description  string
+	SourceBranch string
+	TargetBranch string
+}
+
+// CreatePullRequest creates a pull request in the repository.
+func (c *Client) CreatePullRequest(ctx context.Context, repo *Repo, opts CreatePullRequestOpts) (*PullRequest, error) {
+	payload := map[string]interface{}{
+		"title":       opts.Title,
+		"description": opts.Description,
+		"source":      PullRequestEndpoint{Branch: PullRequestBranch{Name: opts.SourceBranch}},
+		"destination": PullRequestEndpoint{Branch: PullRequestBranch{Name: opts.TargetBranch}},
+	}
+
+	req, err := newJSONRequest("POST", fmt.Sprintf("/2.0/repositories/%s/pullrequests", repo.FullName), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr PullRequest
+	if err := c.do(ctx, req, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// GetPullRequest returns the pull request of the repository with the given ID.
+func (c *Client) GetPullRequest(ctx context.Context, repo *Repo, id int64) (*PullRequest, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("/2.0/repositories/%s/pullrequests/%d", repo.FullName, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr PullRequest
+	if err := c.do(ctx, req, &pr); err != nil {
+		return nil, err
+	}
+	return &pr, nil
+}
+
+// GetOpenPullRequestByRefs returns the open pull request of the repository
+// from the source branch into the target branch. It returns
+// ErrPullRequestNotFound if there is none.
+func (c *Client) GetOpenPullRequestByRefs(ctx context.Context, repo *Repo, source, target string) (*PullRequest, error) {
+	qry := url.Values{
+		"q": []string{fmt.Sprintf(
+			`source.branch.name = %q AND destination.branch.name = %q AND state = "OPEN"`,
+			source, target,
+		)},
+	}
+
+	var prs []*PullRequest
+	if _, err := c.page(ctx, fmt.Sprintf("/2.0/repositories/%s/pullrequests", repo.FullName), qry, nil, &prs); err != nil {
+		return nil, err
+	}
+	if len(prs) == 0 {
+		return nil, ErrPullRequestNotFound
+	}
+	return prs[0], nil
+}
+
+// UpdatePullRequestOpts are the options of UpdatePullRequest.
+type UpdatePullRequestOpts struct {
+	Title        string
+	Description  string
+	TargetBranch string
+}
+
+// UpdatePullRequest updates the title, description and target branch of the
+// pull request.
+func (c *Client) UpdatePullRequest(ctx context.Context, repo *Repo, pr *PullRequest, opts UpdatePullRequestOpts) (*PullRequest, error) {
+	payload := map[string]interface{}{
+		"title":       opts.Title,
+		"description": opts.Description,
+		"destination": PullRequestEndpoint{Branch: PullRequestBranch{Name: opts.TargetBranch}},
+	}
+
+	req, err := newJSONRequest("PUT", fmt.Sprintf("/2.0/repositories/%s/pullrequests/%d", repo.FullName, pr.ID), payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated PullRequest
+	if err := c.do(ctx, req, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// DeclinePullRequest declines the pull request.
+func (c *Client) DeclinePullRequest(ctx context.Context, repo *Repo, pr *PullRequest) (*PullRequest, error) {
+	req, err := http.NewRequest("POST", fmt.Sprintf("/2.0/repositories/%s/pullrequests/%d/decline", repo.FullName, pr.ID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var declined PullRequest
+	if err := c.do(ctx, req, &declined); err != nil {
+		return nil, err
+	}
+	return &declined, nil
+}
+
+// LoadPullRequestActivities loads the approvals, change requests, comments,
+// declines and merges of the pull request into pr.Activities.
+func (c *Client) LoadPullRequestActivities(ctx context.Context, repo *Repo, pr *PullRequest) error {
+	var activities []*Activity
+	err := c.listAll(ctx, fmt.Sprintf("/2.0/repositories/%s/pullrequests/%d/activity", repo.FullName, pr.ID), func() interface{} {
+		return &[]*activityItem{}
+	}, func(page interface{}) {
+		for _, item := range *page.(*[]*activityItem) {
+			if a := item.toActivity(); a != nil {
+				activities = append(activities, a)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	pr.Activities = activities
+	return nil
+}
+
+// LoadPullRequestStatuses loads the commit statuses of the pull request into
+// pr.Statuses.
+func (c *Client) LoadPullRequestStatuses(ctx context.Context, repo *Repo, pr *PullRequest) error {
+	var statuses []*CommitStatus
+	err := c.listAll(ctx, fmt.Sprintf("/2.0/repositories/%s/pullrequests/%d/statuses", repo.FullName, pr.ID), func() interface{} {
+		return &[]*CommitStatus{}
+	}, func(page interface{}) {
+		statuses = append(statuses, *page.(*[]*CommitStatus)...)
+	})
+	if err != nil {
+		return err
+	}
+
+	pr.Statuses = statuses
+	return nil
+}
+
+// listAll requests all pages of the given path, decoding each into a new value
+// returned by newPage, which is passed to add.
+func (c *Client) listAll(ctx context.Context, path string, newPage func() interface{}, add func(page interface{})) error {
+	page := &PageToken{Pagelen: 50}
+	for first := true; first || page.HasMore(); first = false {
+		values := newPage()
+
+		var err error
+		if page.HasMore() {
+			page, err = c.reqPage(ctx, page.Next, values)
+		} else {
+			page, err = c.page(ctx, path, nil, page, values)
+		}
+		if err != nil {
+			return err
+		}
+
+		add(values)
+	}
+	return nil
+}
+
+// activityItem is an item of the activity API of a pull request, which has
+// exactly one of its fields set.
+type activityItem struct {
+	Update *struct {
+		State  PullRequestState `json:"state"`
+		Date   time.Time        `json:"date"`
+		Author Account          `json:"author"`
+	} `json:"update"`
+	Approval *struct {
+		Date time.Time `json:"date"`
+		User Account   `json:"user"`
+	} `json:"approval"`
+	ChangesRequested *struct {
+		Date time.Time `json:"date"`
+		User Account   `json:"user"`
+	} `json:"changes_requested"`
+	Comment *Comment `json:"comment"`
+}
+
+// toActivity returns the Activity of the item, or nil if it's an update that
+// doesn't decline or merge the pull request.
+func (i *activityItem) toActivity() *Activity {
+	switch {
+	case i.Approval != nil:
+		return &Activity{Kind: ActivityKindApproved, User: i.Approval.User, Date: i.Approval.Date}
+	case i.ChangesRequested != nil:
+		return &Activity{Kind: ActivityKindChangesRequested, User: i.ChangesRequested.User, Date: i.ChangesRequested.Date}
+	case i.Comment != nil:
+		return &Activity{Kind: ActivityKindCommented, User: i.Comment.User, Date: i.Comment.CreatedOn, Comment: i.Comment}
+	case i.Update != nil:
+		switch i.Update.State {
+		case PullRequestStateDeclined:
+			return &Activity{Kind: ActivityKindDeclined, User: i.Update.Author, Date: i.Update.Date}
+		case PullRequestStateMerged:
+			return &Activity{Kind: ActivityKindMerged, User: i.Update.Author, Date: i.Update.Date}
+		}
+	}
+	return nil
+}
+
+func newJSONRequest(method, path string, body interface{}) (*http.Request, error) {
+	bs, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequest(method, path, bytes.NewReader(bs))
+}
+
+// IsNotFound reports whether err is a Bitbucket Cloud API not found error.
+func IsNotFound(err error) bool {
+	switch e := errors.Cause(err).(type) {
+	case *httpError:
+		return e.NotFound()
+	}
+	return false
+}
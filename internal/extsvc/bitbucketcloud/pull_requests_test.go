@@ -0,0 +1,127 @@
+package bitbucketcloud
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type mockDoer struct {
+	requests  []*http.Request
+	responses []string
+}
+
+func (d *mockDoer) Do(req *http.Request) (*http.Response, error) {
+	d.requests = append(d.requests, req)
+	body := d.responses[0]
+	d.responses = d.responses[1:]
+	return &http.Response{
+		Request:    req,
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+	}, nil
+}
+
+func newMockClient(responses ...string) (*Client, *mockDoer) {
+	doer := &mockDoer{responses: responses}
+	return NewClient(&url.URL{Scheme: "https", Host: "api.bitbucket.org"}, doer), doer
+}
+
+func TestClient_LoadPullRequestActivities(t *testing.T) {
+	cli, doer := newMockClient(`{
+		"values": [
+			{"approval": {"date": "2020-04-01T10:30:00.123456+00:00", "user": {"uuid": "{a}", "nickname": "alice"}}},
+			{"update": {"state": "OPEN", "date": "2020-04-01T10:00:00+00:00", "author": {"uuid": "{b}"}}}
+		],
+		"next": "https://api.bitbucket.org/2.0/repositories/org/repo/pullrequests/1/activity?page=2"
+	}`, `{
+		"values": [
+			{"comment": {"id": 7, "content": {"raw": "LGTM"}, "user": {"uuid": "{a}"}, "created_on": "2020-04-01T11:00:00+00:00"}},
+			{"changes_requested": {"date": "2020-04-01T12:00:00+00:00", "user": {"uuid": "{c}"}}},
+			{"update": {"state": "MERGED", "date": "2020-04-01T13:00:00+00:00", "author": {"uuid": "{b}"}}}
+		]
+	}`)
+
+	pr := &PullRequest{ID: 1}
+	if err := cli.LoadPullRequestActivities(context.Background(), &Repo{FullName: "org/repo"}, pr); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doer.requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(doer.requests))
+	}
+
+	var keys []string
+	for _, a := range pr.Activities {
+		keys = append(keys, a.Key())
+	}
+	want := []string{
+		fmt.Sprintf("approved:{a}:%d", time.Date(2020, 4, 1, 10, 30, 0, 0, time.UTC).Unix()),
+		"commented:7",
+		fmt.Sprintf("changes_requested:{c}:%d", time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC).Unix()),
+		"merged",
+	}
+	if diff := cmp.Diff(want, keys); diff != "" {
+		t.Fatal(diff)
+	}
+}
+
+func TestClient_GetOpenPullRequestByRefs(t *testing.T) {
+	cli, doer := newMockClient(`{"values": []}`)
+
+	_, err := cli.GetOpenPullRequestByRefs(context.Background(), &Repo{FullName: "org/repo"}, "campaign", "master")
+	if err != ErrPullRequestNotFound {
+		t.Fatalf("got err %v, want ErrPullRequestNotFound", err)
+	}
+
+	q := doer.requests[0].URL.Query().Get("q")
+	if want := `source.branch.name = "campaign" AND destination.branch.name = "master" AND state = "OPEN"`; q != want {
+		t.Fatalf("got query %q, want %q", q, want)
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	e, err := ParseWebhookEvent("pullrequest:unapproved", []byte(`{
+		"actor": {"uuid": "{a}"},
+		"pullrequest": {"id": 3},
+		"repository": {"uuid": "{r}", "full_name": "org/repo"},
+		"approval": {"date": "2020-04-01T10:30:00+00:00", "user": {"uuid": "{a}"}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pe := e.(*PullRequestEvent)
+	a := pe.ToActivity()
+	if a == nil || a.Kind != ActivityKindUnapproved || a.User.UUID != "{a}" {
+		t.Fatalf("unexpected activity %+v", a)
+	}
+	if pe.PullRequest.ID != 3 || pe.Repository.UUID != "{r}" {
+		t.Fatalf("unexpected event %+v", pe)
+	}
+
+	e, err = ParseWebhookEvent("repo:commit_status_updated", []byte(`{
+		"repository": {"uuid": "{r}"},
+		"commit_status": {
+			"key": "ci", "state": "FAILED", "refname": "campaign",
+			"links": {"commit": {"href": "https://api.bitbucket.org/2.0/repositories/org/repo/commit/abcdef"}}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := e.(*CommitStatusEvent).CommitStatus.Key(), "abcdef:ci"; have != want {
+		t.Fatalf("got key %q, want %q", have, want)
+	}
+
+	e, err = ParseWebhookEvent("repo:push", []byte(`{}`))
+	if err != nil || e != nil {
+		t.Fatalf("got (%v, %v), want (nil, nil) for unsupported event type", e, err)
+	}
+}
@@ -0,0 +1,93 @@
+package bitbucketcloud
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+const eventTypeHeader = "X-Event-Key"
+
+// WebhookEventType returns the type of the webhook event of the request, such
+// as "pullrequest:approved".
+func WebhookEventType(r *http.Request) string {
+	return r.Header.Get(eventTypeHeader)
+}
+
+// ParseWebhookEvent parses the payload of a webhook event of the given type.
+// It returns a nil event for event types that aren't related to pull requests.
+// See https://confluence.atlassian.com/bitbucket/event-payloads-740262817.html.
+func ParseWebhookEvent(eventType string, payload []byte) (e interface{}, err error) {
+	switch eventType {
+	case "pullrequest:approved",
+		"pullrequest:unapproved",
+		"pullrequest:changes_request_created",
+		"pullrequest:changes_request_removed",
+		"pullrequest:comment_created",
+		"pullrequest:comment_updated",
+		"pullrequest:fulfilled",
+		"pullrequest:rejected":
+		e = &PullRequestEvent{Type: eventType}
+	case "repo:commit_status_created", "repo:commit_status_updated":
+		e = &CommitStatusEvent{}
+	default:
+		return nil, nil
+	}
+	return e, json.Unmarshal(payload, e)
+}
+
+// PullRequestEvent is a webhook event for a change of a pull request.
+type PullRequestEvent struct {
+	Type           string          `json:"-"`
+	Actor          Account         `json:"actor"`
+	PullRequest    PullRequest     `json:"pullrequest"`
+	Repository     PullRequestRepo `json:"repository"`
+	Approval       *reviewPayload  `json:"approval"`
+	ChangesRequest *reviewPayload  `json:"changes_request"`
+	Comment        *Comment        `json:"comment"`
+}
+
+type reviewPayload struct {
+	Date time.Time `json:"date"`
+	User Account   `json:"user"`
+}
+
+// ToActivity returns the Activity of the event, or nil if the payload lacks
+// the data of the change.
+func (e *PullRequestEvent) ToActivity() *Activity {
+	review := func(kind ActivityKind, p *reviewPayload) *Activity {
+		if p == nil {
+			return nil
+		}
+		return &Activity{Kind: kind, User: p.User, Date: p.Date}
+	}
+
+	switch e.Type {
+	case "pullrequest:approved":
+		return review(ActivityKindApproved, e.Approval)
+	case "pullrequest:unapproved":
+		return review(ActivityKindUnapproved, e.Approval)
+	case "pullrequest:changes_request_created":
+		return review(ActivityKindChangesRequested, e.ChangesRequest)
+	case "pullrequest:changes_request_removed":
+		return review(ActivityKindChangesRequestRemoved, e.ChangesRequest)
+	case "pullrequest:comment_created", "pullrequest:comment_updated":
+		if e.Comment == nil {
+			return nil
+		}
+		return &Activity{Kind: ActivityKindCommented, User: e.Comment.User, Date: e.Comment.CreatedOn, Comment: e.Comment}
+	case "pullrequest:fulfilled":
+		return &Activity{Kind: ActivityKindMerged, User: e.Actor, Date: e.PullRequest.UpdatedOn}
+	case "pullrequest:rejected":
+		return &Activity{Kind: ActivityKindDeclined, User: e.Actor, Date: e.PullRequest.UpdatedOn}
+	}
+	return nil
+}
+
+// CommitStatusEvent is a webhook event for a created or updated commit
+// status. It doesn't reference pull requests, which have to be found by the
+// branch of the status.
+type CommitStatusEvent struct {
+	CommitStatus CommitStatus    `json:"commit_status"`
+	Repository   PullRequestRepo `json:"repository"`
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/segmentio/fasthash/fnv1"
 	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 	"github.com/sourcegraph/sourcegraph/internal/metrics"
+	"github.com/sourcegraph/sourcegraph/internal/ratelimit"
 	"github.com/sourcegraph/sourcegraph/schema"
 	"golang.org/x/time/rate"
 )
@@ -84,6 +85,11 @@ type Client struct {
 	// OAuth client used to authenticate requests, if set via SetOAuth.
 	// Takes precedence over Token and Username / Password authentication.
 	Oauth *oauth.Client
+
+	// budgets is used to get the rate limit budget of the client's
+	// credentials. All clients share ratelimit.DefaultBudgetManager, except
+	// in tests.
+	budgets *ratelimit.BudgetManager
 }
 
 // NewClient returns a new Bitbucket Server API client at url. If a nil
@@ -110,9 +116,27 @@ func NewClient(url *url.URL, httpClient httpcli.Doer) *Client {
 		httpClient: httpClient,
 		URL:        url,
 		RateLimit:  l,
+		budgets:    ratelimit.DefaultBudgetManager,
 	}
 }
 
+// budget returns the rate limit budget of the client's credentials. Bitbucket
+// Server doesn't report its remaining rate limit, so the budget only makes
+// requests with a background priority honour Retry-After responses, on top of
+// the self-imposed RateLimit.
+func (c *Client) budget() *ratelimit.Budget {
+	var credentials string
+	switch {
+	case c.Oauth != nil:
+		credentials = "oauth:" + c.Oauth.Credentials.Token + ":" + c.Username
+	case c.Token != "":
+		credentials = "token:" + c.Token
+	case c.Username != "":
+		credentials = "basic:" + c.Username + ":" + c.Password
+	}
+	return c.budgets.Get(c.URL, credentials, "", "X-")
+}
+
 // NewClientWithConfig returns an authenticated Bitbucket Server API client with
 // the provided configuration.
 func NewClientWithConfig(c *schema.BitbucketServerConnection) (*Client, error) {
@@ -564,6 +588,37 @@ func (c *Client) DeclinePullRequest(ctx context.Context, pr *PullRequest) error
 	return c.send(ctx, "POST", path, qry, nil, pr)
 }
 
+// MergePullRequest merges the given PullRequest with the merge strategy with
+// the given ID, such as "no-ff" or "squash". If strategyID is empty, the
+// default strategy of the repository is used.
+func (c *Client) MergePullRequest(ctx context.Context, pr *PullRequest, strategyID string) error {
+	if pr.ToRef.Repository.Slug == "" {
+		return errors.New("repository slug empty")
+	}
+
+	if pr.ToRef.Repository.Project.Key == "" {
+		return errors.New("project key empty")
+	}
+
+	path := fmt.Sprintf(
+		"rest/api/1.0/projects/%s/repos/%s/pull-requests/%d/merge",
+		pr.ToRef.Repository.Project.Key,
+		pr.ToRef.Repository.Slug,
+		pr.ID,
+	)
+
+	qry := url.Values{"version": {strconv.Itoa(pr.Version)}}
+
+	var payload interface{}
+	if strategyID != "" {
+		payload = struct {
+			StrategyID string `json:"strategyId"`
+		}{StrategyID: strategyID}
+	}
+
+	return c.send(ctx, "POST", path, qry, payload, pr)
+}
+
 // LoadPullRequestActivities loads the given PullRequest's timeline of activities,
 // returning an error in case of failure.
 func (c *Client) LoadPullRequestActivities(ctx context.Context, pr *PullRequest) (err error) {
@@ -790,6 +845,11 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 		return err
 	}
 
+	budget := c.budget()
+	if err := budget.Reserve(ctx, 1); err != nil {
+		return err
+	}
+
 	startWait := time.Now()
 	if err := c.RateLimit.Wait(ctx); err != nil {
 		return err
@@ -805,6 +865,7 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 	}
 
 	defer resp.Body.Close()
+	budget.Update(resp.Header)
 
 	bs, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
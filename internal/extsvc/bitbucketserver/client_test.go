@@ -6,6 +6,7 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 	"net/url"
 	"os"
 	"reflect"
@@ -16,6 +17,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/inconshreveable/log15"
 	"github.com/sergi/go-diff/diffmatchpatch"
+	"github.com/sourcegraph/sourcegraph/internal/httpcli"
 )
 
 var update = flag.Bool("update", false, "update testdata")
@@ -641,6 +643,68 @@ func TestClient_DeclinePullRequest(t *testing.T) {
 	}
 }
 
+func TestClient_MergePullRequest(t *testing.T) {
+	var (
+		req  *http.Request
+		body string
+	)
+	doer := httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+		req = r
+		if r.Body != nil {
+			b, _ := ioutil.ReadAll(r.Body)
+			body = string(b)
+		}
+		return &http.Response{
+			Request:    r,
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(`{"id": 63, "version": 3, "state": "MERGED"}`)),
+		}, nil
+	})
+	cli := NewClient(&url.URL{Scheme: "https", Host: "bitbucket.example.com"}, doer)
+
+	newPR := func() *PullRequest {
+		pr := &PullRequest{ID: 63, Version: 2}
+		pr.ToRef.Repository.Slug = "automation-testing"
+		pr.ToRef.Repository.Project.Key = "SOUR"
+		return pr
+	}
+
+	t.Run("ToRef repo not set", func(t *testing.T) {
+		pr := newPR()
+		pr.ToRef.Repository.Slug = ""
+		if have, want := fmt.Sprint(cli.MergePullRequest(context.Background(), pr, "")), "repository slug empty"; have != want {
+			t.Fatalf("error:\nhave: %q\nwant: %q", have, want)
+		}
+	})
+
+	for _, tc := range []struct {
+		name       string
+		strategyID string
+		body       string
+	}{
+		{name: "default strategy", body: ""},
+		{name: "squash", strategyID: "squash", body: `{"strategyId":"squash"}` + "\n"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := newPR()
+			body = ""
+			if err := cli.MergePullRequest(context.Background(), pr, tc.strategyID); err != nil {
+				t.Fatal(err)
+			}
+
+			if have, want := req.URL.String(), "https://bitbucket.example.com/rest/api/1.0/projects/SOUR/repos/automation-testing/pull-requests/63/merge?version=2"; have != want {
+				t.Errorf("url:\nhave: %q\nwant: %q", have, want)
+			}
+			if body != tc.body {
+				t.Errorf("body:\nhave: %q\nwant: %q", body, tc.body)
+			}
+			if pr.State != "MERGED" || pr.Version != 3 {
+				t.Errorf("pull request not updated: %+v", pr)
+			}
+		})
+	}
+}
+
 func TestClient_LoadPullRequestActivities(t *testing.T) {
 	instanceURL := os.Getenv("BITBUCKET_SERVER_URL")
 	if instanceURL == "" {
@@ -75,8 +75,23 @@ type Client struct {
 	// repoCacheTTL is the TTL of cache entries.
 	repoCacheTTL time.Duration
 
-	// RateLimit is the API rate limit monitor.
+	// RateLimit is the API rate limit monitor of the default token.
 	RateLimit *ratelimit.Monitor
+
+	// SearchRateLimit is the search API rate limit monitor of the default
+	// token. The search API has an independent rate limit much lower than
+	// non-search API requests.
+	SearchRateLimit *ratelimit.Monitor
+
+	// GraphQLRateLimit is the GraphQL API rate limit monitor of the default
+	// token. The GraphQL API has an independent rate limit, measured in points
+	// rather than requests.
+	GraphQLRateLimit *ratelimit.Monitor
+
+	// budgets is used to get the rate limit budgets of the tokens used by
+	// this client. All clients share ratelimit.DefaultBudgetManager, except
+	// in tests.
+	budgets *ratelimit.BudgetManager
 }
 
 // APIError is an error type returned by Client when the GitHub API responds with
@@ -151,14 +166,26 @@ func NewClient(apiURL *url.URL, defaultToken string, cli httpcli.Doer) *Client {
 		return category
 	})
 
-	return &Client{
+	c := &Client{
 		apiURL:       apiURL,
 		githubDotCom: urlIsGitHubDotCom(apiURL),
 		defaultToken: defaultToken,
 		httpClient:   cli,
-		RateLimit:    &ratelimit.Monitor{HeaderPrefix: "X-"},
 		repoCache:    map[string]*rcache.Cache{},
+		budgets:      ratelimit.DefaultBudgetManager,
 	}
+	c.RateLimit = c.budget("", "").Monitor
+	c.SearchRateLimit = c.budget("", "search").Monitor
+	c.GraphQLRateLimit = c.budget("", "graphql").Monitor
+	return c
+}
+
+// budget returns the rate limit budget of the token (or the default token, if
+// empty) for the given GitHub API resource. See
+// https://developer.github.com/v3/rate_limit/ for the resources with
+// independent rate limits.
+func (c *Client) budget(token, resource string) *ratelimit.Budget {
+	return c.budgets.Get(c.apiURL, firstNonEmpty(token, c.defaultToken), resource, "X-")
 }
 
 // cache returns the cache associated with the token (which can be empty, in which case the default
@@ -187,6 +214,14 @@ func (c *Client) cache(explicitToken string) *rcache.Cache {
 }
 
 func (c *Client) do(ctx context.Context, token string, req *http.Request, result interface{}) (err error) {
+	var resource string
+	switch p := strings.TrimPrefix(req.URL.Path, "/"); {
+	case strings.HasPrefix(p, "search/"):
+		resource = "search"
+	case p == "graphql" || p == "../graphql":
+		resource = "graphql"
+	}
+
 	req.URL.Path = path.Join(c.apiURL.Path, req.URL.Path)
 	req.URL = c.apiURL.ResolveReference(req.URL)
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
@@ -196,6 +231,11 @@ func (c *Client) do(ctx context.Context, token string, req *http.Request, result
 		req.Header.Set("Authorization", "bearer "+c.defaultToken)
 	}
 
+	budget := c.budget(token, resource)
+	if err := budget.Reserve(ctx, 1); err != nil {
+		return err
+	}
+
 	var resp *http.Response
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, "GitHub")
@@ -216,7 +256,7 @@ func (c *Client) do(ctx context.Context, token string, req *http.Request, result
 	}
 
 	defer resp.Body.Close()
-	c.RateLimit.Update(resp.Header)
+	budget.Update(resp.Header)
 	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
 		var err APIError
 		if body, readErr := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<13)); readErr != nil { // 8kb
@@ -239,9 +279,9 @@ func (c *Client) do(ctx context.Context, token string, req *http.Request, result
 // - /users/:user/repos
 // - /orgs/:org/repos
 // - /user/repos
-func (c *Client) listRepositories(ctx context.Context, requestURI string) ([]*Repository, error) {
+func (c *Client) listRepositories(ctx context.Context, token, requestURI string) ([]*Repository, error) {
 	var restRepos []restRepository
-	if err := c.requestGet(ctx, "", requestURI, &restRepos); err != nil {
+	if err := c.requestGet(ctx, token, requestURI, &restRepos); err != nil {
 		return nil, err
 	}
 	repos := make([]*Repository, 0, len(restRepos))
@@ -288,6 +328,35 @@ func (c *Client) requestGet(ctx context.Context, token, requestURI string, resul
 	return c.do(ctx, token, req, result)
 }
 
+// requestPost sends the JSON encoding of body to the given REST API endpoint
+// and discards the response.
+func (c *Client) requestPost(ctx context.Context, requestURI string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", requestURI, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	var result json.RawMessage
+	return c.do(ctx, "", req, &result)
+}
+
+// requestDelete sends a DELETE request to the given REST API endpoint and
+// discards the response.
+func (c *Client) requestDelete(ctx context.Context, requestURI string) error {
+	req, err := http.NewRequest("DELETE", requestURI, nil)
+	if err != nil {
+		return err
+	}
+
+	var result json.RawMessage
+	return c.do(ctx, "", req, &result)
+}
+
 func (c *Client) requestGraphQL(ctx context.Context, token, query string, vars map[string]interface{}, result interface{}) (err error) {
 	reqBody, err := json.Marshal(struct {
 		Query     string                 `json:"query"`
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"os"
@@ -244,6 +245,133 @@ func TestClient_CreatePullRequest(t *testing.T) {
 	}
 }
 
+func TestClient_MergePullRequest(t *testing.T) {
+	var vars struct {
+		Input struct {
+			ID              string `json:"pullRequestId"`
+			MergeMethod     string `json:"mergeMethod"`
+			ExpectedHeadOid string `json:"expectedHeadOid"`
+		} `json:"input"`
+	}
+	doer := httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+		var body struct {
+			Variables json.RawMessage `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(body.Variables, &vars); err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Request:    r,
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body: ioutil.NopCloser(strings.NewReader(`{"data": {"mergePullRequest": {"pullRequest": {
+				"id": "MDExOlB1bGxSZXF1ZXN0MzQxMDU5OTY5",
+				"number": 44,
+				"state": "MERGED",
+				"timelineItems": {"nodes": [{"__typename": "MergedEvent"}]}
+			}}}}`)),
+		}, nil
+	})
+	cli := NewClient(&url.URL{Scheme: "https", Host: "api.github.com"}, "", doer)
+
+	pr := &PullRequest{ID: "MDExOlB1bGxSZXF1ZXN0MzQxMDU5OTY5", HeadRefOid: "deadbeef"}
+	if err := cli.MergePullRequest(context.Background(), pr, "SQUASH"); err != nil {
+		t.Fatal(err)
+	}
+
+	if have, want := vars.Input.ID, "MDExOlB1bGxSZXF1ZXN0MzQxMDU5OTY5"; have != want {
+		t.Errorf("pullRequestId: have %q, want %q", have, want)
+	}
+	if have, want := vars.Input.MergeMethod, "SQUASH"; have != want {
+		t.Errorf("mergeMethod: have %q, want %q", have, want)
+	}
+	if have, want := vars.Input.ExpectedHeadOid, "deadbeef"; have != want {
+		t.Errorf("expectedHeadOid: have %q, want %q", have, want)
+	}
+	if pr.State != "MERGED" || pr.Number != 44 || len(pr.TimelineItems) != 1 {
+		t.Errorf("pull request not updated: %+v", pr)
+	}
+}
+
+func TestClient_RequestReviewsAndAddAssignees(t *testing.T) {
+	bodies := map[string]string{}
+	doer := httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		bodies[r.Method+" "+r.URL.Path] = string(body)
+		return &http.Response{
+			Request:    r,
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"number": 44}`)),
+		}, nil
+	})
+	cli := NewClient(&url.URL{Scheme: "https", Host: "api.github.com"}, "", doer)
+
+	pr := &PullRequest{RepoWithOwner: "sourcegraph/sourcegraph", Number: 44}
+	if err := cli.RequestReviews(context.Background(), pr, []string{"alice"}, []string{"campaigns"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.AddAssignees(context.Background(), pr, []string{"bob"}); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]string{
+		"POST /repos/sourcegraph/sourcegraph/pulls/44/requested_reviewers": `{"reviewers":["alice"],"team_reviewers":["campaigns"]}`,
+		"POST /repos/sourcegraph/sourcegraph/issues/44/assignees":          `{"assignees":["bob"]}`,
+	}
+	if !reflect.DeepEqual(bodies, want) {
+		t.Fatalf("requests:\nhave: %v\nwant: %v", bodies, want)
+	}
+}
+
+func TestClient_CommentAndLabel(t *testing.T) {
+	var requests []string
+	doer := httpcli.DoerFunc(func(r *http.Request) (*http.Response, error) {
+		var body []byte
+		if r.Body != nil {
+			var err error
+			if body, err = ioutil.ReadAll(r.Body); err != nil {
+				return nil, err
+			}
+		}
+		requests = append(requests, strings.TrimSpace(r.Method+" "+r.URL.EscapedPath()+" "+string(body)))
+		return &http.Response{
+			Request:    r,
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{}`)),
+		}, nil
+	})
+	cli := NewClient(&url.URL{Scheme: "https", Host: "api.github.com"}, "", doer)
+
+	ctx := context.Background()
+	pr := &PullRequest{RepoWithOwner: "sourcegraph/sourcegraph", Number: 44}
+	if err := cli.CreateComment(ctx, pr, "Friendly reminder"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.AddLabels(ctx, pr, []string{"campaign", "needs review"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cli.RemoveLabel(ctx, pr, "needs review"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		`POST /repos/sourcegraph/sourcegraph/issues/44/comments {"body":"Friendly reminder"}`,
+		`POST /repos/sourcegraph/sourcegraph/issues/44/labels {"labels":["campaign","needs review"]}`,
+		`DELETE /repos/sourcegraph/sourcegraph/issues/44/labels/needs%20review`,
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Fatalf("requests:\nhave: %q\nwant: %q", requests, want)
+	}
+}
+
 func TestClient_ClosePullRequest(t *testing.T) {
 	cli, save := newClient(t, "ClosePullRequest")
 	defer save()
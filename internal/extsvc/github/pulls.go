@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -64,7 +65,8 @@ func (c *CheckSuite) Key() string {
 
 // CheckRun represents the status of a checkrun
 type CheckRun struct {
-	ID string
+	ID   string
+	Name string
 	// One of COMPLETED, IN_PROGRESS, QUEUED, REQUESTED
 	Status string
 	// One of ACTION_REQUIRED, CANCELLED, FAILURE, NEUTRAL, SUCCESS, TIMED_OUT
@@ -588,6 +590,120 @@ func (c *Client) ClosePullRequest(ctx context.Context, pr *PullRequest) error {
 	return nil
 }
 
+// MergePullRequest merges the PullRequest on Github with the given merge
+// method, which is one of "MERGE", "SQUASH" or "REBASE". If the PullRequest
+// has a HeadRefOid, Github refuses to merge it if its head moved since.
+func (c *Client) MergePullRequest(ctx context.Context, pr *PullRequest, mergeMethod string) error {
+	var q strings.Builder
+	q.WriteString(pullRequestFragments)
+	q.WriteString(`mutation	MergePullRequest($input:MergePullRequestInput!) {
+  mergePullRequest(input:$input) {
+    pullRequest {
+      ... pr
+    }
+  }
+}`)
+
+	var result struct {
+		MergePullRequest struct {
+			PullRequest struct {
+				PullRequest
+				Participants  struct{ Nodes []Actor }
+				TimelineItems struct{ Nodes []TimelineItem }
+			} `json:"pullRequest"`
+		} `json:"mergePullRequest"`
+	}
+
+	input := map[string]interface{}{"input": struct {
+		ID              string `json:"pullRequestId"`
+		MergeMethod     string `json:"mergeMethod"`
+		ExpectedHeadOid string `json:"expectedHeadOid,omitempty"`
+	}{ID: pr.ID, MergeMethod: mergeMethod, ExpectedHeadOid: pr.HeadRefOid}}
+	err := c.requestGraphQL(ctx, "", q.String(), input, &result)
+	if err != nil {
+		return err
+	}
+
+	*pr = result.MergePullRequest.PullRequest.PullRequest
+	pr.TimelineItems = result.MergePullRequest.PullRequest.TimelineItems.Nodes
+	pr.Participants = result.MergePullRequest.PullRequest.Participants.Nodes
+
+	return nil
+}
+
+// RequestReviews requests reviews of the PullRequest from the given users and
+// teams. Teams are given by their slug and must belong to the organization
+// owning the repository.
+func (c *Client) RequestReviews(ctx context.Context, pr *PullRequest, reviewers, teamReviewers []string) error {
+	body := struct {
+		Reviewers     []string `json:"reviewers,omitempty"`
+		TeamReviewers []string `json:"team_reviewers,omitempty"`
+	}{Reviewers: reviewers, TeamReviewers: teamReviewers}
+
+	uri := fmt.Sprintf("repos/%s/pulls/%d/requested_reviewers", pr.RepoWithOwner, pr.Number)
+	return c.requestPost(ctx, uri, body)
+}
+
+// AddAssignees assigns the PullRequest to the given users, in addition to
+// its current assignees.
+func (c *Client) AddAssignees(ctx context.Context, pr *PullRequest, assignees []string) error {
+	body := struct {
+		Assignees []string `json:"assignees"`
+	}{Assignees: assignees}
+
+	uri := fmt.Sprintf("repos/%s/issues/%d/assignees", pr.RepoWithOwner, pr.Number)
+	return c.requestPost(ctx, uri, body)
+}
+
+// CreateComment comments on the PullRequest with the given body.
+func (c *Client) CreateComment(ctx context.Context, pr *PullRequest, body string) error {
+	in := struct {
+		Body string `json:"body"`
+	}{Body: body}
+
+	uri := fmt.Sprintf("repos/%s/issues/%d/comments", pr.RepoWithOwner, pr.Number)
+	return c.requestPost(ctx, uri, in)
+}
+
+// AddLabels adds the given labels to the PullRequest. Labels that don't exist
+// in the repository yet are created.
+func (c *Client) AddLabels(ctx context.Context, pr *PullRequest, labels []string) error {
+	in := struct {
+		Labels []string `json:"labels"`
+	}{Labels: labels}
+
+	uri := fmt.Sprintf("repos/%s/issues/%d/labels", pr.RepoWithOwner, pr.Number)
+	return c.requestPost(ctx, uri, in)
+}
+
+// RemoveLabel removes the given label from the PullRequest. It returns an
+// error for which IsNotFound is true if the PullRequest doesn't have the
+// label.
+func (c *Client) RemoveLabel(ctx context.Context, pr *PullRequest, label string) error {
+	uri := fmt.Sprintf("repos/%s/issues/%d/labels/%s", pr.RepoWithOwner, pr.Number, url.PathEscape(label))
+	return c.requestDelete(ctx, uri)
+}
+
+// RerequestCheckSuite re-runs the CheckSuite with the given ID in the
+// repository with the given ID. Both are GraphQL node IDs.
+func (c *Client) RerequestCheckSuite(ctx context.Context, repositoryID, checkSuiteID string) error {
+	q := `mutation RerequestCheckSuite($input:RerequestCheckSuiteInput!) {
+  rerequestCheckSuite(input:$input) {
+    checkSuite {
+      id
+    }
+  }
+}`
+
+	input := map[string]interface{}{"input": struct {
+		RepositoryID string `json:"repositoryId"`
+		CheckSuiteID string `json:"checkSuiteId"`
+	}{RepositoryID: repositoryID, CheckSuiteID: checkSuiteID}}
+
+	var result json.RawMessage
+	return c.requestGraphQL(ctx, "", q, input, &result)
+}
+
 // LoadPullRequests loads a list of PullRequests from Github.
 func (c *Client) LoadPullRequests(ctx context.Context, prs ...*PullRequest) error {
 	const batchSize = 15
@@ -767,6 +883,7 @@ fragment commitWithChecks on Commit {
       checkRuns(last: 20){
         nodes{
           id
+          name
           status
           conclusion
         }
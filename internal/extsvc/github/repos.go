@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/inconshreveable/log15"
 	"github.com/pkg/errors"
@@ -35,6 +36,34 @@ type Repository struct {
 	IsFork           bool   // whether the repository is a fork of another repository
 	IsArchived       bool   // whether the repository is archived on the code host
 	ViewerPermission string // ADMIN, WRITE, READ, or empty if unknown. Only the graphql api populates this. https://developer.github.com/v4/enum/repositorypermission/
+	Visibility       string // PUBLIC, PRIVATE, INTERNAL, or empty if unknown. The graphql api only populates this on github.com. https://developer.github.com/v4/enum/repositoryvisibility/
+
+	PrimaryLanguage  struct{ Name string }             // primary language of the repository, if detected
+	Stargazers       struct{ TotalCount int }          // number of users who starred the repository
+	DefaultBranchRef struct{ Name string }             // default branch of the repository, empty if the repository is empty
+	RepositoryTopics struct{ Nodes []RepositoryTopic } // topics the repository is labelled with
+
+	// PushedAt is when the repository was last pushed to. Only the REST API
+	// populates it, and it isn't serialized since it's only needed while
+	// listing repositories and changes far more often than the rest.
+	PushedAt time.Time `json:"-"`
+}
+
+// RepositoryTopic is a topic a GitHub repository is labelled with.
+type RepositoryTopic struct {
+	Topic struct{ Name string }
+}
+
+// Topics returns the names of the topics the repository is labelled with.
+func (r *Repository) Topics() []string {
+	if len(r.RepositoryTopics.Nodes) == 0 {
+		return nil
+	}
+	topics := make([]string, 0, len(r.RepositoryTopics.Nodes))
+	for _, n := range r.RepositoryTopics.Nodes {
+		topics = append(topics, n.Topic.Name)
+	}
+	return topics
 }
 
 // repositoryFieldsGraphQLFragment returns a GraphQL fragment that contains the fields needed to populate the
@@ -52,12 +81,18 @@ fragment RepositoryFields on Repository {
 	isFork
 	isArchived
 	viewerPermission
+	visibility
+	primaryLanguage { name }
+	stargazers { totalCount }
+	defaultBranchRef { name }
+	repositoryTopics(first: 100) { nodes { topic { name } } }
 }
 	`
 	}
 	// Some fields are not yet available on GitHub Enterprise yet
 	// or are available but too new to expect our customers to have updated:
 	// - viewerPermission
+	// - visibility
 	return `
 fragment RepositoryFields on Repository {
 	id
@@ -68,6 +103,10 @@ fragment RepositoryFields on Repository {
 	isPrivate
 	isFork
 	isArchived
+	primaryLanguage { name }
+	stargazers { totalCount }
+	defaultBranchRef { name }
+	repositoryTopics(first: 100) { nodes { topic { name } } }
 }
 	`
 }
@@ -252,6 +291,13 @@ type restRepository struct {
 	Fork        bool
 	Archived    bool
 	Permissions restRepositoryPermissions `json:"permissions"`
+
+	Language        string
+	StargazersCount int    `json:"stargazers_count"`
+	DefaultBranch   string `json:"default_branch"`
+	Topics          []string
+	Visibility      string
+	PushedAt        time.Time `json:"pushed_at"`
 }
 
 // getRepositoryFromAPI attempts to fetch a repository from the GitHub API without use of the redis cache.
@@ -273,7 +319,7 @@ func (c *Client) getRepositoryFromAPI(ctx context.Context, owner, name string) (
 // convertRestRepo converts repo information returned by the rest API
 // to a standard format.
 func convertRestRepo(restRepo restRepository) *Repository {
-	return &Repository{
+	repo := &Repository{
 		ID:               restRepo.ID,
 		DatabaseID:       restRepo.DatabaseID,
 		NameWithOwner:    restRepo.FullName,
@@ -283,7 +329,18 @@ func convertRestRepo(restRepo restRepository) *Repository {
 		IsFork:           restRepo.Fork,
 		IsArchived:       restRepo.Archived,
 		ViewerPermission: convertRestRepoPermissions(restRepo.Permissions),
+		Visibility:       strings.ToUpper(restRepo.Visibility),
+	}
+	repo.PrimaryLanguage.Name = restRepo.Language
+	repo.Stargazers.TotalCount = restRepo.StargazersCount
+	repo.DefaultBranchRef.Name = restRepo.DefaultBranch
+	repo.PushedAt = restRepo.PushedAt
+	for _, topic := range restRepo.Topics {
+		var t RepositoryTopic
+		t.Topic.Name = topic
+		repo.RepositoryTopics.Nodes = append(repo.RepositoryTopics.Nodes, t)
 	}
+	return repo
 }
 
 // convertRestRepoPermissions converts repo information returned by the rest API
@@ -311,7 +368,7 @@ func (c *Client) getPublicRepositories(ctx context.Context, sinceRepoID int64) (
 	if sinceRepoID > 0 {
 		path += "?per_page=100&since=" + strconv.FormatInt(sinceRepoID, 10)
 	}
-	return c.listRepositories(ctx, path)
+	return c.listRepositories(ctx, "", path)
 }
 
 // getRepositoryByNodeIDFromAPI attempts to fetch a repository by GraphQL node ID from the GitHub
@@ -480,8 +537,18 @@ func (c *Client) ListPublicRepositories(ctx context.Context, sinceRepoID int64)
 // token. page is the page of results to return. Pages are 1-indexed (so the
 // first call should be for page 1).
 func (c *Client) ListAffiliatedRepositories(ctx context.Context, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
-	path := fmt.Sprintf("user/repos?sort=created&page=%d&per_page=100", page)
-	repos, err = c.listRepositories(ctx, path)
+	return c.listAffiliatedRepositories(ctx, "created", page)
+}
+
+// ListAffiliatedRepositoriesByPushed is like ListAffiliatedRepositories, but
+// lists the most recently pushed to repositories first.
+func (c *Client) ListAffiliatedRepositoriesByPushed(ctx context.Context, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
+	return c.listAffiliatedRepositories(ctx, "pushed", page)
+}
+
+func (c *Client) listAffiliatedRepositories(ctx context.Context, sort string, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
+	path := fmt.Sprintf("user/repos?sort=%s&page=%d&per_page=100", sort, page)
+	repos, err = c.listRepositories(ctx, "", path)
 	if err == nil {
 		// 🚨 SECURITY: must forward token here to ensure caching by token
 		c.addRepositoriesToCache("", repos)
@@ -490,20 +557,54 @@ func (c *Client) ListAffiliatedRepositories(ctx context.Context, page int) (repo
 	return repos, len(repos) > 0, 1, err
 }
 
+// ListPrivateAffiliatedRepositories lists the private GitHub repositories that
+// the user of the given token can access: as owner, as collaborator (including
+// outside collaborators), or through the membership of an organization or any
+// of its teams. page is the page of results to return. Pages are 1-indexed (so
+// the first call should be for page 1).
+//
+// 🚨 SECURITY: The results are not cached, since they are specific to the
+// user of the token.
+func (c *Client) ListPrivateAffiliatedRepositories(ctx context.Context, token string, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
+	path := fmt.Sprintf("user/repos?visibility=private&affiliation=owner,collaborator,organization_member&page=%d&per_page=100", page)
+	repos, err = c.listRepositories(ctx, token, path)
+	return repos, len(repos) > 0, 1, err
+}
+
 // ListOrgRepositories lists GitHub repositories from the specified organization.
 // org is the name of the organization. page is the page of results to return.
 // Pages are 1-indexed (so the first call should be for page 1).
 func (c *Client) ListOrgRepositories(ctx context.Context, org string, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
-	path := fmt.Sprintf("orgs/%s/repos?sort=created&page=%d&per_page=100", org, page)
-	repos, err = c.listRepositories(ctx, path)
+	return c.listOrgRepositories(ctx, org, "created", page)
+}
+
+// ListOrgRepositoriesByPushed is like ListOrgRepositories, but lists the most
+// recently pushed to repositories first.
+func (c *Client) ListOrgRepositoriesByPushed(ctx context.Context, org string, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
+	return c.listOrgRepositories(ctx, org, "pushed", page)
+}
+
+func (c *Client) listOrgRepositories(ctx context.Context, org, sort string, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
+	path := fmt.Sprintf("orgs/%s/repos?sort=%s&page=%d&per_page=100", org, sort, page)
+	repos, err = c.listRepositories(ctx, "", path)
 	return repos, len(repos) > 0, 1, err
 }
 
 // ListUserRepositories lists GitHub repositories from the specified user.
 // Pages are 1-indexed (so the first call should be for page 1)
 func (c *Client) ListUserRepositories(ctx context.Context, user string, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
-	path := fmt.Sprintf("users/%s/repos?sort=created&type=owner&page=%d&per_page=100", user, page)
-	repos, err = c.listRepositories(ctx, path)
+	return c.listUserRepositories(ctx, user, "created", page)
+}
+
+// ListUserRepositoriesByPushed is like ListUserRepositories, but lists the
+// most recently pushed to repositories first.
+func (c *Client) ListUserRepositoriesByPushed(ctx context.Context, user string, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
+	return c.listUserRepositories(ctx, user, "pushed", page)
+}
+
+func (c *Client) listUserRepositories(ctx context.Context, user, sort string, page int) (repos []*Repository, hasNextPage bool, rateLimitCost int, err error) {
+	path := fmt.Sprintf("users/%s/repos?sort=%s&type=owner&page=%d&per_page=100", user, sort, page)
+	repos, err = c.listRepositories(ctx, "", path)
 	return repos, len(repos) > 0, 1, err
 }
 
@@ -567,3 +668,30 @@ func (c *Client) ListTopicsOnRepository(ctx context.Context, ownerAndName string
 	}
 	return result.Names, nil
 }
+
+// Collaborator is a GitHub user with access to a repository.
+type Collaborator struct {
+	ID    string `json:"node_id"` // GraphQL ID
+	Login string `json:"login"`
+	// DatabaseID is the ID of the user in the database of the GitHub instance,
+	// which is used as extsvc.ExternalAccount.AccountID of GitHub accounts.
+	DatabaseID int64 `json:"id"`
+}
+
+// ListRepositoryCollaborators lists the users with access to the given
+// repository, including outside collaborators and the organization members
+// with access through a team or the organization's base permissions. page is
+// the page of results to return. Pages are 1-indexed (so the first call
+// should be for page 1).
+//
+// The client token must have push access to the repository.
+func (c *Client) ListRepositoryCollaborators(ctx context.Context, owner, name string, page int) (users []*Collaborator, hasNextPage bool, err error) {
+	path := fmt.Sprintf("/repos/%s/%s/collaborators?affiliation=all&page=%d&per_page=100", owner, name, page)
+	if err := c.requestGet(ctx, "", path, &users); err != nil {
+		if HTTPErrorCode(err) == http.StatusNotFound {
+			return nil, false, ErrNotFound
+		}
+		return nil, false, err
+	}
+	return users, len(users) > 0, nil
+}
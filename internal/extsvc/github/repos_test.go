@@ -71,14 +71,18 @@ func (s mockHTTPEmptyResponse) Do(req *http.Request) (*http.Response, error) {
 
 func newTestClient(t *testing.T, cli httpcli.Doer) *Client {
 	rcache.SetupForTest(t)
-	return &Client{
+	c := &Client{
 		apiURL:          &url.URL{Scheme: "https", Host: "example.com", Path: "/"},
 		httpClient:      cli,
-		RateLimit:       &ratelimit.Monitor{},
 		repoCache:       map[string]*rcache.Cache{},
 		repoCachePrefix: "__test__gh_repo",
 		repoCacheTTL:    1000,
+		budgets:         ratelimit.NewBudgetManager(),
 	}
+	c.RateLimit = c.budget("", "").Monitor
+	c.SearchRateLimit = c.budget("", "search").Monitor
+	c.GraphQLRateLimit = c.budget("", "graphql").Monitor
+	return c
 }
 
 // TestClient_GetRepository tests the behavior of GetRepository.
@@ -427,7 +431,7 @@ func repoListsAreEqual(a []*Repository, b []*Repository) bool {
 		return false
 	}
 	for i := 0; i < len(a); i++ {
-		if *a[i] != *b[i] {
+		if !reflect.DeepEqual(a[i], b[i]) {
 			return false
 		}
 	}
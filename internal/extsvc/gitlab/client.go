@@ -67,7 +67,9 @@ type ClientProvider struct {
 	gitlabClients   map[string]*Client
 	gitlabClientsMu sync.Mutex
 
-	RateLimit *ratelimit.Monitor // the API rate limit monitor
+	// budgets is used to get the rate limit budgets of the tokens of the
+	// clients.
+	budgets *ratelimit.BudgetManager
 }
 
 type CommonOp struct {
@@ -93,7 +95,7 @@ func NewClientProvider(baseURL *url.URL, cli httpcli.Doer) *ClientProvider {
 		baseURL:       baseURL.ResolveReference(&url.URL{Path: path.Join(baseURL.Path, "api/v4") + "/"}),
 		httpClient:    cli,
 		gitlabClients: make(map[string]*Client),
-		RateLimit:     &ratelimit.Monitor{},
+		budgets:       ratelimit.DefaultBudgetManager,
 	}
 }
 
@@ -141,7 +143,7 @@ func (p *ClientProvider) getClient(op getClientOp) *Client {
 		return c
 	}
 
-	c := p.newClient(p.baseURL, op, p.httpClient, p.RateLimit)
+	c := p.newClient(p.baseURL, op, p.httpClient)
 	p.gitlabClients[key] = c
 	return c
 }
@@ -165,6 +167,10 @@ type Client struct {
 	OAuthToken          string // an OAuth bearer token, if set
 	Sudo                string // Sudo user value, if set
 	RateLimit           *ratelimit.Monitor
+
+	// budget is the rate limit budget of the client's token, shared by all
+	// clients using the same token.
+	budget *ratelimit.Budget
 }
 
 // newClient creates a new GitLab API client with an optional personal access token to authenticate requests.
@@ -173,7 +179,7 @@ type Client struct {
 // http[s]://[gitlab-hostname] for self-hosted GitLab instances.
 //
 // See the docstring of Client for the meaning of the parameters.
-func (p *ClientProvider) newClient(baseURL *url.URL, op getClientOp, httpClient httpcli.Doer, rateLimit *ratelimit.Monitor) *Client {
+func (p *ClientProvider) newClient(baseURL *url.URL, op getClientOp, httpClient httpcli.Doer) *Client {
 	// Cache for GitLab project metadata.
 	var cacheTTL time.Duration
 	if isGitLabDotComURL(baseURL) && op.personalAccessToken == "" && op.oauthToken == "" {
@@ -184,6 +190,9 @@ func (p *ClientProvider) newClient(baseURL *url.URL, op getClientOp, httpClient
 	key := sha256.Sum256([]byte(op.personalAccessToken + ":" + op.oauthToken + ":" + baseURL.String()))
 	projCache := rcache.NewWithTTL("gl_proj:"+base64.URLEncoding.EncodeToString(key[:]), int(cacheTTL/time.Second))
 
+	// Only one of the tokens is set, see getClient.
+	budget := p.budgets.Get(baseURL, op.personalAccessToken+op.oauthToken, "", "")
+
 	return &Client{
 		baseURL:             baseURL,
 		httpClient:          httpClient,
@@ -191,7 +200,8 @@ func (p *ClientProvider) newClient(baseURL *url.URL, op getClientOp, httpClient
 		PersonalAccessToken: op.personalAccessToken,
 		OAuthToken:          op.oauthToken,
 		Sudo:                op.sudo,
-		RateLimit:           rateLimit,
+		RateLimit:           budget.Monitor,
+		budget:              budget,
 	}
 }
 
@@ -213,6 +223,10 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 		req.Header.Set("Sudo", c.Sudo)
 	}
 
+	if err := c.budget.Reserve(ctx, 1); err != nil {
+		return nil, err
+	}
+
 	var resp *http.Response
 
 	span, ctx := opentracing.StartSpanFromContext(ctx, "GitLab")
@@ -235,8 +249,8 @@ func (c *Client) do(ctx context.Context, req *http.Request, result interface{})
 	defer resp.Body.Close()
 	trace("GitLab API", "method", req.Method, "url", req.URL.String(), "respCode", resp.StatusCode)
 
-	c.RateLimit.Update(resp.Header)
-	if resp.StatusCode != http.StatusOK {
+	c.budget.Update(resp.Header)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return nil, errors.Wrap(httpError(resp.StatusCode), fmt.Sprintf("unexpected response from GitLab API (%s)", req.URL))
 	}
 
@@ -0,0 +1,394 @@
+package gitlab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/peterhellberg/link"
+	"github.com/pkg/errors"
+)
+
+// MergeRequestState is the state of a GitLab merge request.
+type MergeRequestState string
+
+const (
+	MergeRequestStateOpened MergeRequestState = "opened"
+	MergeRequestStateClosed MergeRequestState = "closed"
+	MergeRequestStateLocked MergeRequestState = "locked"
+	MergeRequestStateMerged MergeRequestState = "merged"
+)
+
+// MergeRequest is a GitLab merge request (equivalent to a GitHub pull request).
+//
+// The Notes and Pipelines fields are not part of the GitLab API representation
+// of a merge request. They are loaded separately (see LoadMergeRequestNotes and
+// LoadMergeRequestPipelines) so that a merge request can be stored as the
+// metadata of a campaign changeset along with its timeline.
+type MergeRequest struct {
+	ID              int64             `json:"id"`
+	IID             int64             `json:"iid"`
+	ProjectID       int64             `json:"project_id"`
+	SourceProjectID int64             `json:"source_project_id"`
+	TargetProjectID int64             `json:"target_project_id"`
+	Title           string            `json:"title"`
+	Description     string            `json:"description"`
+	State           MergeRequestState `json:"state"`
+	CreatedAt       time.Time         `json:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at"`
+	MergedAt        *time.Time        `json:"merged_at,omitempty"`
+	ClosedAt        *time.Time        `json:"closed_at,omitempty"`
+	SourceBranch    string            `json:"source_branch"`
+	TargetBranch    string            `json:"target_branch"`
+	WebURL          string            `json:"web_url"`
+	SHA             string            `json:"sha"` // the head commit of the source branch
+	DiffRefs        DiffRefs          `json:"diff_refs"`
+	Author          User              `json:"author"`
+	Labels          []string          `json:"labels"`
+
+	Notes     []*Note     `json:"notes,omitempty"`
+	Pipelines []*Pipeline `json:"pipelines,omitempty"`
+}
+
+// DiffRefs are the commits a merge request diff is based on.
+type DiffRefs struct {
+	BaseSHA  string `json:"base_sha"`
+	HeadSHA  string `json:"head_sha"`
+	StartSHA string `json:"start_sha"`
+}
+
+// Note is a comment on a merge request. GitLab also records changes of a merge
+// request, such as approvals, as system notes.
+type Note struct {
+	ID        int64     `json:"id"`
+	Body      string    `json:"body"`
+	Author    User      `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	System    bool      `json:"system"`
+}
+
+// Key is a unique key identifying this note in the context of its merge
+// request. Notes received by webhooks that describe a change of a merge request
+// have no ID, so they are identified by their author and time.
+func (n *Note) Key() string {
+	if n.ID != 0 {
+		return strconv.FormatInt(n.ID, 10)
+	}
+	return fmt.Sprintf("%s:%d", n.Author.Username, n.CreatedAt.Unix())
+}
+
+// ToEvent returns the event the note represents: an approval, unapproval,
+// close, reopen or merge of the merge request for system notes recording one of
+// those, the note itself for comments, and nil for other system notes.
+func (n *Note) ToEvent() interface{ Key() string } {
+	if !n.System {
+		return n
+	}
+	// Older GitLab versions prefix state changes with "Status changed to".
+	switch strings.TrimPrefix(strings.ToLower(n.Body), "status changed to ") {
+	case "approved this merge request":
+		return &ReviewApprovedEvent{Note: n}
+	case "unapproved this merge request":
+		return &ReviewUnapprovedEvent{Note: n}
+	case "closed":
+		return &MergeRequestClosedEvent{Note: n}
+	case "reopened":
+		return &MergeRequestReopenedEvent{Note: n}
+	case "merged":
+		return &MergeRequestMergedEvent{Note: n}
+	}
+	return nil
+}
+
+// ReviewApprovedEvent is an approval of a merge request.
+type ReviewApprovedEvent struct{ *Note }
+
+// ReviewUnapprovedEvent is a withdrawn approval of a merge request.
+type ReviewUnapprovedEvent struct{ *Note }
+
+// MergeRequestClosedEvent is the closing of a merge request.
+type MergeRequestClosedEvent struct{ *Note }
+
+// MergeRequestReopenedEvent is the reopening of a closed merge request.
+type MergeRequestReopenedEvent struct{ *Note }
+
+// MergeRequestMergedEvent is the merge of a merge request.
+type MergeRequestMergedEvent struct{ *Note }
+
+// PipelineStatus is the status of a GitLab CI pipeline.
+type PipelineStatus string
+
+const (
+	PipelineStatusCreated            PipelineStatus = "created"
+	PipelineStatusWaitingForResource PipelineStatus = "waiting_for_resource"
+	PipelineStatusPreparing          PipelineStatus = "preparing"
+	PipelineStatusPending            PipelineStatus = "pending"
+	PipelineStatusRunning            PipelineStatus = "running"
+	PipelineStatusSuccess            PipelineStatus = "success"
+	PipelineStatusFailed             PipelineStatus = "failed"
+	PipelineStatusCanceled           PipelineStatus = "canceled"
+	PipelineStatusSkipped            PipelineStatus = "skipped"
+	PipelineStatusManual             PipelineStatus = "manual"
+	PipelineStatusScheduled          PipelineStatus = "scheduled"
+)
+
+// Pipeline is a GitLab CI pipeline run for a merge request.
+type Pipeline struct {
+	ID        int64          `json:"id"`
+	SHA       string         `json:"sha"`
+	Ref       string         `json:"ref"`
+	Status    PipelineStatus `json:"status"`
+	WebURL    string         `json:"web_url"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// Key is a unique key identifying this pipeline in the context of its merge
+// request.
+func (p *Pipeline) Key() string {
+	return strconv.FormatInt(p.ID, 10)
+}
+
+// ErrMergeRequestAlreadyExists is returned by CreateMergeRequest if an open
+// merge request for the source branch already exists.
+var ErrMergeRequestAlreadyExists = errors.New("merge request already exists")
+
+// ErrMergeRequestNotFound is returned by GetOpenMergeRequestByRefs if no open
+// merge request between the branches exists.
+var ErrMergeRequestNotFound = errors.New("merge request not found")
+
+// CreateMergeRequestOpts are the options of a new merge request.
+type CreateMergeRequestOpts struct {
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Title        string `json:"title"`
+	Description  string `json:"description,omitempty"`
+}
+
+// CreateMergeRequest creates a merge request in the project. It returns
+// ErrMergeRequestAlreadyExists if an open merge request for the source branch
+// already exists.
+func (c *Client) CreateMergeRequest(ctx context.Context, project *Project, opts CreateMergeRequestOpts) (*MergeRequest, error) {
+	if MockCreateMergeRequest != nil {
+		return MockCreateMergeRequest(c, ctx, project, opts)
+	}
+
+	req, err := newJSONRequest("POST", fmt.Sprintf("projects/%d/merge_requests", project.ID), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		if HTTPErrorCode(err) == http.StatusConflict {
+			return nil, ErrMergeRequestAlreadyExists
+		}
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// GetMergeRequest returns the merge request of the project with the given IID.
+func (c *Client) GetMergeRequest(ctx context.Context, project *Project, iid int64) (*MergeRequest, error) {
+	if MockGetMergeRequest != nil {
+		return MockGetMergeRequest(c, ctx, project, iid)
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%d/merge_requests/%d", project.ID, iid), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr MergeRequest
+	if _, err := c.do(ctx, req, &mr); err != nil {
+		return nil, err
+	}
+	return &mr, nil
+}
+
+// GetOpenMergeRequestByRefs returns the open merge request of the project from
+// the source branch to the target branch.
+func (c *Client) GetOpenMergeRequestByRefs(ctx context.Context, project *Project, source, target string) (*MergeRequest, error) {
+	if MockGetOpenMergeRequestByRefs != nil {
+		return MockGetOpenMergeRequestByRefs(c, ctx, project, source, target)
+	}
+
+	values := url.Values{
+		"state":         {string(MergeRequestStateOpened)},
+		"source_branch": {source},
+		"target_branch": {target},
+	}
+	req, err := http.NewRequest("GET", fmt.Sprintf("projects/%d/merge_requests?%s", project.ID, values.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var mrs []*MergeRequest
+	if _, err := c.do(ctx, req, &mrs); err != nil {
+		return nil, err
+	}
+	if len(mrs) == 0 {
+		return nil, ErrMergeRequestNotFound
+	}
+	return mrs[0], nil
+}
+
+// UpdateMergeRequestOpts are the changes to a merge request. Empty fields are
+// not changed.
+type UpdateMergeRequestOpts struct {
+	TargetBranch string `json:"target_branch,omitempty"`
+	Title        string `json:"title,omitempty"`
+	Description  string `json:"description,omitempty"`
+	// StateEvent is "close" or "reopen".
+	StateEvent string `json:"state_event,omitempty"`
+	// AssigneeIDs are the IDs of the users the merge request is assigned to.
+	AssigneeIDs []int32 `json:"assignee_ids,omitempty"`
+	// AddLabels and RemoveLabels are comma-separated lists of labels that are
+	// added to or removed from the merge request.
+	AddLabels    string `json:"add_labels,omitempty"`
+	RemoveLabels string `json:"remove_labels,omitempty"`
+}
+
+// UpdateMergeRequest updates the merge request and returns the updated merge
+// request. The Notes and Pipelines of mr are not loaded on the returned merge
+// request.
+func (c *Client) UpdateMergeRequest(ctx context.Context, project *Project, mr *MergeRequest, opts UpdateMergeRequestOpts) (*MergeRequest, error) {
+	if MockUpdateMergeRequest != nil {
+		return MockUpdateMergeRequest(c, ctx, project, mr, opts)
+	}
+
+	req, err := newJSONRequest("PUT", fmt.Sprintf("projects/%d/merge_requests/%d", project.ID, mr.IID), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var updated MergeRequest
+	if _, err := c.do(ctx, req, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// CreateMergeRequestNote comments on the merge request with the given body.
+func (c *Client) CreateMergeRequestNote(ctx context.Context, project *Project, mr *MergeRequest, body string) (*Note, error) {
+	if MockCreateMergeRequestNote != nil {
+		return MockCreateMergeRequestNote(c, ctx, project, mr, body)
+	}
+
+	req, err := newJSONRequest("POST", fmt.Sprintf("projects/%d/merge_requests/%d/notes", project.ID, mr.IID), struct {
+		Body string `json:"body"`
+	}{Body: body})
+	if err != nil {
+		return nil, err
+	}
+
+	var note Note
+	if _, err := c.do(ctx, req, &note); err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+// LoadMergeRequestNotes loads all notes of the merge request into mr.Notes.
+func (c *Client) LoadMergeRequestNotes(ctx context.Context, project *Project, mr *MergeRequest) error {
+	if MockLoadMergeRequestNotes != nil {
+		return MockLoadMergeRequestNotes(c, ctx, project, mr)
+	}
+
+	var notes []*Note
+	err := c.listAll(ctx, fmt.Sprintf("projects/%d/merge_requests/%d/notes?sort=asc&order_by=created_at&per_page=100", project.ID, mr.IID), func(page json.RawMessage) error {
+		var ns []*Note
+		if err := json.Unmarshal(page, &ns); err != nil {
+			return err
+		}
+		notes = append(notes, ns...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	mr.Notes = notes
+	return nil
+}
+
+// LoadMergeRequestPipelines loads all pipelines of the merge request into
+// mr.Pipelines.
+func (c *Client) LoadMergeRequestPipelines(ctx context.Context, project *Project, mr *MergeRequest) error {
+	if MockLoadMergeRequestPipelines != nil {
+		return MockLoadMergeRequestPipelines(c, ctx, project, mr)
+	}
+
+	var pipelines []*Pipeline
+	err := c.listAll(ctx, fmt.Sprintf("projects/%d/merge_requests/%d/pipelines?per_page=100", project.ID, mr.IID), func(page json.RawMessage) error {
+		var ps []*Pipeline
+		if err := json.Unmarshal(page, &ps); err != nil {
+			return err
+		}
+		pipelines = append(pipelines, ps...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	mr.Pipelines = pipelines
+	return nil
+}
+
+// RetryPipeline retries the failed and canceled jobs of the pipeline with the
+// given ID and returns the restarted pipeline.
+func (c *Client) RetryPipeline(ctx context.Context, project *Project, id int64) (*Pipeline, error) {
+	if MockRetryPipeline != nil {
+		return MockRetryPipeline(c, ctx, project, id)
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("projects/%d/pipelines/%d/retry", project.ID, id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Pipeline
+	if _, err := c.do(ctx, req, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// listAll calls f with each page of the list at urlStr. See
+// https://docs.gitlab.com/ee/api/README.html#pagination-link-header.
+func (c *Client) listAll(ctx context.Context, urlStr string, f func(page json.RawMessage) error) error {
+	for urlStr != "" {
+		req, err := http.NewRequest("GET", urlStr, nil)
+		if err != nil {
+			return err
+		}
+		var page json.RawMessage
+		respHeader, err := c.do(ctx, req, &page)
+		if err != nil {
+			return err
+		}
+		if err := f(page); err != nil {
+			return err
+		}
+
+		urlStr = ""
+		if l := link.Parse(respHeader.Get("Link"))["next"]; l != nil {
+			urlStr = l.URI
+		}
+	}
+	return nil
+}
+
+func newJSONRequest(method, urlStr string, body interface{}) (*http.Request, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return http.NewRequest(method, urlStr, bytes.NewReader(data))
+}
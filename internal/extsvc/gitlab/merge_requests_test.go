@@ -0,0 +1,92 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNote_ToEvent(t *testing.T) {
+	tests := []struct {
+		note *Note
+		want interface{}
+	}{
+		{note: &Note{Body: "LGTM"}, want: &Note{}},
+		{note: &Note{Body: "approved this merge request", System: true}, want: &ReviewApprovedEvent{}},
+		{note: &Note{Body: "unapproved this merge request", System: true}, want: &ReviewUnapprovedEvent{}},
+		{note: &Note{Body: "closed", System: true}, want: &MergeRequestClosedEvent{}},
+		{note: &Note{Body: "Status changed to reopened", System: true}, want: &MergeRequestReopenedEvent{}},
+		{note: &Note{Body: "merged", System: true}, want: &MergeRequestMergedEvent{}},
+		{note: &Note{Body: "added 1 commit", System: true}, want: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.note.Body, func(t *testing.T) {
+			have := test.note.ToEvent()
+			if test.want == nil {
+				if have != nil {
+					t.Fatalf("got %T, want nil", have)
+				}
+				return
+			}
+			if have == nil {
+				t.Fatalf("got nil, want %T", test.want)
+			}
+			if haveType, wantType := fmt.Sprintf("%T", have), fmt.Sprintf("%T", test.want); haveType != wantType {
+				t.Fatalf("got %s, want %s", haveType, wantType)
+			}
+		})
+	}
+}
+
+func TestClient_CreateMergeRequest_alreadyExists(t *testing.T) {
+	c := newTestClient(t)
+	c.httpClient = mockHTTPEmptyResponse{http.StatusConflict}
+
+	_, err := c.CreateMergeRequest(context.Background(), &Project{}, CreateMergeRequestOpts{
+		SourceBranch: "campaign",
+		TargetBranch: "master",
+		Title:        "Campaign",
+	})
+	if err != ErrMergeRequestAlreadyExists {
+		t.Fatalf("got err %v, want ErrMergeRequestAlreadyExists", err)
+	}
+}
+
+func TestParseWebhookEvent(t *testing.T) {
+	want := time.Date(2020, 4, 1, 10, 30, 0, 0, time.UTC)
+	for _, ts := range []string{
+		"2020-04-01T10:30:00Z",
+		"2020-04-01 10:30:00 UTC",
+		"2020-04-01 12:30:00 +0200",
+	} {
+		t.Run(ts, func(t *testing.T) {
+			payload := `{
+				"user": {"username": "jane-doe"},
+				"project": {"id": 3},
+				"merge_request": {"iid": 12},
+				"object_attributes": {"id": 7, "note": "LGTM", "noteable_type": "MergeRequest", "created_at": "` + ts + `", "updated_at": "` + ts + `"}
+			}`
+			e, err := ParseWebhookEvent("Note Hook", []byte(payload))
+			if err != nil {
+				t.Fatal(err)
+			}
+			note, ok := e.(*NoteHookEvent).ToEvent().(*Note)
+			if !ok {
+				t.Fatalf("got %T, want *Note", e.(*NoteHookEvent).ToEvent())
+			}
+			if !note.CreatedAt.Equal(want) {
+				t.Fatalf("got created at %s, want %s", note.CreatedAt, want)
+			}
+			if note.ID != 7 || note.Author.Username != "jane-doe" {
+				t.Fatalf("unexpected note %+v", note)
+			}
+		})
+	}
+
+	e, err := ParseWebhookEvent("Push Hook", []byte(`{}`))
+	if err != nil || e != nil {
+		t.Fatalf("got (%v, %v), want (nil, nil) for unsupported event type", e, err)
+	}
+}
@@ -27,6 +27,9 @@ type Project struct {
 	Visibility        Visibility     `json:"visibility"`                    // "private", "internal", or "public"
 	ForkedFromProject *ProjectCommon `json:"forked_from_project,omitempty"` // If non-nil, the project from which this project was forked
 	Archived          bool           `json:"archived"`
+	TagList           []string       `json:"tag_list,omitempty"`       // topics the project is labelled with
+	StarCount         int            `json:"star_count"`               // number of users who starred the project
+	DefaultBranch     string         `json:"default_branch,omitempty"` // default branch, empty if the project has no commits
 }
 
 type ProjectCommon struct {
@@ -42,10 +42,13 @@ func (s mockHTTPEmptyResponse) Do(req *http.Request) (*http.Response, error) {
 
 func newTestClient(t *testing.T) *Client {
 	rcache.SetupForTest(t)
+	baseURL := &url.URL{Scheme: "https", Host: "example.com", Path: "/"}
+	budget := ratelimit.NewBudgetManager().Get(baseURL, "", "", "")
 	return &Client{
-		baseURL:    &url.URL{Scheme: "https", Host: "example.com", Path: "/"},
+		baseURL:    baseURL,
 		httpClient: &http.Client{},
-		RateLimit:  &ratelimit.Monitor{},
+		RateLimit:  budget.Monitor,
+		budget:     budget,
 		projCache:  rcache.NewWithTTL("__test__gl_proj", 1000),
 	}
 }
@@ -0,0 +1,196 @@
+package gitlab
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	eventTypeHeader = "X-Gitlab-Event"
+
+	// WebhookTokenHeader is the header of webhook requests containing the
+	// secret token of the webhook.
+	WebhookTokenHeader = "X-Gitlab-Token"
+)
+
+// WebhookEventType returns the type of the webhook event of the request, such
+// as "Merge Request Hook".
+func WebhookEventType(r *http.Request) string {
+	return r.Header.Get(eventTypeHeader)
+}
+
+// ParseWebhookEvent parses the payload of a webhook event of the given type.
+// It returns a nil event for event types that aren't related to merge requests.
+// See https://docs.gitlab.com/ee/user/project/integrations/webhooks.html.
+func ParseWebhookEvent(eventType string, payload []byte) (e interface{}, err error) {
+	switch eventType {
+	case "Merge Request Hook":
+		e = &MergeRequestHookEvent{}
+	case "Note Hook":
+		e = &NoteHookEvent{}
+	case "Pipeline Hook":
+		e = &PipelineHookEvent{}
+	default:
+		return nil, nil
+	}
+	return e, json.Unmarshal(payload, e)
+}
+
+// WebhookUser is the user that caused a webhook event.
+type WebhookUser struct {
+	Name     string `json:"name"`
+	Username string `json:"username"`
+}
+
+// WebhookProject is the project of a webhook event.
+type WebhookProject struct {
+	ID int64 `json:"id"`
+}
+
+// WebhookMergeRequest is the merge request of a note or pipeline webhook event.
+type WebhookMergeRequest struct {
+	IID int64 `json:"iid"`
+}
+
+// MergeRequestHookEvent is a webhook event for a change of a merge request.
+type MergeRequestHookEvent struct {
+	User             WebhookUser    `json:"user"`
+	Project          WebhookProject `json:"project"`
+	ObjectAttributes struct {
+		IID       int64     `json:"iid"`
+		Action    string    `json:"action"`
+		UpdatedAt eventTime `json:"updated_at"`
+	} `json:"object_attributes"`
+}
+
+// MergeRequestIID returns the IID of the merge request of the event.
+func (e *MergeRequestHookEvent) MergeRequestIID() int64 { return e.ObjectAttributes.IID }
+
+// ToEvent returns the event equivalent to the system note GitLab records for
+// the change, or nil if the change isn't an approval, unapproval, close, reopen
+// or merge.
+func (e *MergeRequestHookEvent) ToEvent() interface{ Key() string } {
+	var body string
+	switch e.ObjectAttributes.Action {
+	case "approved", "approval":
+		body = "approved this merge request"
+	case "unapproved", "unapproval":
+		body = "unapproved this merge request"
+	case "close":
+		body = "closed"
+	case "reopen":
+		body = "reopened"
+	case "merge":
+		body = "merged"
+	default:
+		return nil
+	}
+
+	n := &Note{
+		Body:      body,
+		Author:    User{Name: e.User.Name, Username: e.User.Username},
+		CreatedAt: e.ObjectAttributes.UpdatedAt.Time,
+		UpdatedAt: e.ObjectAttributes.UpdatedAt.Time,
+		System:    true,
+	}
+	return n.ToEvent()
+}
+
+// NoteHookEvent is a webhook event for a new or edited comment.
+type NoteHookEvent struct {
+	User             WebhookUser          `json:"user"`
+	Project          WebhookProject       `json:"project"`
+	MergeRequest     *WebhookMergeRequest `json:"merge_request"`
+	ObjectAttributes struct {
+		ID           int64     `json:"id"`
+		Note         string    `json:"note"`
+		NoteableType string    `json:"noteable_type"`
+		System       bool      `json:"system"`
+		CreatedAt    eventTime `json:"created_at"`
+		UpdatedAt    eventTime `json:"updated_at"`
+	} `json:"object_attributes"`
+}
+
+// ToEvent returns the event of the note (see Note.ToEvent), or nil if the note
+// isn't a note on a merge request.
+func (e *NoteHookEvent) ToEvent() interface{ Key() string } {
+	if e.ObjectAttributes.NoteableType != "MergeRequest" || e.MergeRequest == nil {
+		return nil
+	}
+
+	n := &Note{
+		ID:        e.ObjectAttributes.ID,
+		Body:      e.ObjectAttributes.Note,
+		Author:    User{Name: e.User.Name, Username: e.User.Username},
+		CreatedAt: e.ObjectAttributes.CreatedAt.Time,
+		UpdatedAt: e.ObjectAttributes.UpdatedAt.Time,
+		System:    e.ObjectAttributes.System,
+	}
+	return n.ToEvent()
+}
+
+// PipelineHookEvent is a webhook event for a change of the status of a
+// pipeline.
+type PipelineHookEvent struct {
+	Project          WebhookProject       `json:"project"`
+	MergeRequest     *WebhookMergeRequest `json:"merge_request"`
+	ObjectAttributes struct {
+		ID         int64          `json:"id"`
+		Ref        string         `json:"ref"`
+		SHA        string         `json:"sha"`
+		Status     PipelineStatus `json:"status"`
+		CreatedAt  eventTime      `json:"created_at"`
+		FinishedAt eventTime      `json:"finished_at"`
+	} `json:"object_attributes"`
+}
+
+// ToPipeline returns the pipeline of the event. Webhook events don't include
+// when the pipeline was last updated, so the time it finished (or was created,
+// if it is still running) is used instead.
+func (e *PipelineHookEvent) ToPipeline() *Pipeline {
+	updatedAt := e.ObjectAttributes.FinishedAt.Time
+	if updatedAt.IsZero() {
+		updatedAt = e.ObjectAttributes.CreatedAt.Time
+	}
+	return &Pipeline{
+		ID:        e.ObjectAttributes.ID,
+		SHA:       e.ObjectAttributes.SHA,
+		Ref:       e.ObjectAttributes.Ref,
+		Status:    e.ObjectAttributes.Status,
+		CreatedAt: e.ObjectAttributes.CreatedAt.Time,
+		UpdatedAt: updatedAt,
+	}
+}
+
+// eventTime is a timestamp in a webhook payload. Depending on the GitLab
+// version and event type, timestamps are formatted as RFC 3339 or as
+// "2006-01-02 15:04:05 UTC".
+type eventTime struct{ time.Time }
+
+var eventTimeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02 15:04:05 -0700",
+}
+
+func (t *eventTime) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s = strings.TrimSpace(s); s == "" {
+		t.Time = time.Time{}
+		return nil
+	}
+	for _, layout := range eventTimeLayouts {
+		if tt, err := time.Parse(layout, s); err == nil {
+			t.Time = tt.UTC()
+			return nil
+		}
+	}
+	return errors.Errorf("invalid timestamp %q", s)
+}
@@ -0,0 +1,281 @@
+// Package ldap authenticates users against LDAP servers (such as OpenLDAP and Active
+// Directory) and looks up their group memberships, for ldap auth providers. It uses
+// github.com/go-ldap/ldap for the LDAP protocol.
+package ldap
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	goldap "github.com/go-ldap/ldap/v3"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+// DefaultTimeout is the timeout of LDAP operations whose context has no deadline.
+const DefaultTimeout = 30 * time.Second
+
+// ErrInvalidCredentials is returned by Directory.Authenticate if the username or password is
+// wrong. It deliberately doesn't tell which one, to avoid disclosing which users exist.
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// Defaults of the optional properties of schema.LDAPAuthProvider.
+const (
+	DefaultUserSearchFilter     = "(uid={username})"
+	DefaultUsernameAttribute    = "uid"
+	DefaultEmailAttribute       = "mail"
+	DefaultDisplayNameAttribute = "cn"
+	DefaultGroupSearchFilter    = "(member={dn})"
+	DefaultGroupNameAttribute   = "cn"
+)
+
+// Directory looks up users and their groups in the LDAP directory configured by an ldap auth
+// provider.
+type Directory struct {
+	config    *schema.LDAPAuthProvider
+	tlsConfig *tls.Config
+}
+
+// User is a user found in a Directory, with its attributes mapped as configured.
+type User struct {
+	DN          string
+	Username    string
+	Email       string
+	DisplayName string
+}
+
+// NewDirectory returns a Directory for the given ldap auth provider config.
+func NewDirectory(c *schema.LDAPAuthProvider) (*Directory, error) {
+	d := &Directory{config: c}
+	if c.Certificate != "" {
+		pool := x509.NewCertPool()
+		if ok := pool.AppendCertsFromPEM([]byte(c.Certificate)); !ok {
+			return nil, errors.New("invalid LDAP server certificate")
+		}
+		d.tlsConfig = &tls.Config{RootCAs: pool}
+	}
+	return d, nil
+}
+
+// Authenticate checks the password of the user with the given username by binding as the user
+// and returns the user. It returns ErrInvalidCredentials if the user doesn't exist or the
+// password is wrong.
+func (d *Directory) Authenticate(ctx context.Context, username, password string) (*User, error) {
+	// 🚨 SECURITY: Never attempt to bind with an empty password, which LDAP servers accept
+	// as an unauthenticated bind without checking any password.
+	if username == "" || password == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	c, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	u, err := d.lookUpUser(ctx, c, username)
+	if err != nil {
+		return nil, err
+	}
+	if u == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := c.Bind(u.DN, password); err != nil {
+		if goldap.IsErrorWithCode(err, goldap.LDAPResultInvalidCredentials) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+	return u, nil
+}
+
+// LookUpUser returns the user with the given username, or nil if there is no such user.
+func (d *Directory) LookUpUser(ctx context.Context, username string) (*User, error) {
+	c, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	return d.lookUpUser(ctx, c, username)
+}
+
+func (d *Directory) lookUpUser(ctx context.Context, c *conn, username string) (*User, error) {
+	filter := d.config.UserSearch.Filter
+	if filter == "" {
+		filter = DefaultUserSearchFilter
+	}
+	// 🚨 SECURITY: Escape the username so it can't alter the filter.
+	filter = strings.Replace(filter, "{username}", goldap.EscapeFilter(username), -1)
+
+	attrs := d.attributes()
+	res, err := c.Search(goldap.NewSearchRequest(
+		d.config.UserSearch.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 2, 0, false,
+		filter, []string{attrs.Username, attrs.Email, attrs.DisplayName}, nil,
+	))
+	if goldap.IsErrorWithCode(err, goldap.LDAPResultSizeLimitExceeded) {
+		// 🚨 SECURITY: Refuse to guess which of multiple entries is the user.
+		return nil, fmt.Errorf("LDAP user search for %q matches multiple entries", username)
+	} else if err != nil {
+		return nil, err
+	}
+	entries := res.Entries
+	switch len(entries) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("LDAP user search for %q matches multiple entries", username)
+	}
+
+	e := entries[0]
+	return &User{
+		DN:          e.DN,
+		Username:    value(e, attrs.Username),
+		Email:       value(e, attrs.Email),
+		DisplayName: value(e, attrs.DisplayName),
+	}, nil
+}
+
+// Groups returns the names of the groups of the user with the given DN and username.
+func (d *Directory) Groups(ctx context.Context, dn, username string) ([]string, error) {
+	gs := d.config.GroupSearch
+	if gs == nil {
+		return nil, errors.New("LDAP auth provider has no groupSearch configured")
+	}
+	filter := gs.Filter
+	if filter == "" {
+		filter = DefaultGroupSearchFilter
+	}
+	filter = strings.NewReplacer("{dn}", goldap.EscapeFilter(dn), "{username}", goldap.EscapeFilter(username)).Replace(filter)
+	nameAttr := gs.NameAttribute
+	if nameAttr == "" {
+		nameAttr = DefaultGroupNameAttribute
+	}
+
+	c, err := d.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	res, err := c.Search(goldap.NewSearchRequest(
+		gs.BaseDN, goldap.ScopeWholeSubtree, goldap.NeverDerefAliases, 0, 0, false,
+		filter, []string{nameAttr}, nil,
+	))
+	if err != nil {
+		return nil, err
+	}
+	groups := make([]string, 0, len(res.Entries))
+	for _, e := range res.Entries {
+		if name := value(e, nameAttr); name != "" {
+			groups = append(groups, name)
+		}
+	}
+	return groups, nil
+}
+
+// conn is a connection to an LDAP server that is closed when the context it was opened with is
+// done, which fails its pending operations.
+type conn struct {
+	*goldap.Conn
+	done chan struct{}
+	once sync.Once
+}
+
+// Close closes the connection.
+func (c *conn) Close() {
+	c.once.Do(func() { close(c.done) })
+	c.Conn.Close()
+}
+
+// connect connects to the LDAP server and binds as the service account, if any.
+func (d *Directory) connect(ctx context.Context) (*conn, error) {
+	u, err := url.Parse(d.config.Url)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ldap" && u.Scheme != "ldaps" {
+		return nil, fmt.Errorf("unsupported LDAP URL scheme %q", u.Scheme)
+	}
+
+	tlsConfig := d.tlsConfig.Clone()
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = u.Hostname()
+	}
+
+	timeout := DefaultTimeout
+	if deadline, ok := ctx.Deadline(); ok {
+		timeout = time.Until(deadline)
+	}
+
+	gc, err := goldap.DialURL(d.config.Url,
+		goldap.DialWithDialer(&net.Dialer{Timeout: timeout}),
+		goldap.DialWithTLSConfig(tlsConfig),
+	)
+	if err != nil {
+		return nil, err
+	}
+	gc.SetTimeout(timeout)
+
+	c := &conn{Conn: gc, done: make(chan struct{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			gc.Close()
+		case <-c.done:
+		}
+	}()
+
+	if u.Scheme == "ldap" && d.config.StartTLS {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if d.config.BindDN != "" {
+		if err := c.Bind(d.config.BindDN, d.config.BindPassword); err != nil {
+			c.Close()
+			return nil, fmt.Errorf("binding as the LDAP service account: %s", err)
+		}
+	}
+	return c, nil
+}
+
+// value returns the first value of the named attribute of e, or "" if it has no values.
+// Attribute names are case-insensitive.
+func value(e *goldap.Entry, name string) string {
+	for _, a := range e.Attributes {
+		if strings.EqualFold(a.Name, name) && len(a.Values) > 0 {
+			return a.Values[0]
+		}
+	}
+	return ""
+}
+
+func (d *Directory) attributes() schema.LDAPUserAttributes {
+	var attrs schema.LDAPUserAttributes
+	if d.config.Attributes != nil {
+		attrs = *d.config.Attributes
+	}
+	if attrs.Username == "" {
+		attrs.Username = DefaultUsernameAttribute
+	}
+	if attrs.Email == "" {
+		attrs.Email = DefaultEmailAttribute
+	}
+	if attrs.DisplayName == "" {
+		attrs.DisplayName = DefaultDisplayNameAttribute
+	}
+	return attrs
+}
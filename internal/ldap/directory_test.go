@@ -0,0 +1,76 @@
+package ldap_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/sourcegraph/sourcegraph/internal/ldap"
+	"github.com/sourcegraph/sourcegraph/internal/ldap/ldaptest"
+	"github.com/sourcegraph/sourcegraph/schema"
+)
+
+func TestDirectory(t *testing.T) {
+	ctx := context.Background()
+	s := ldaptest.NewServer(
+		ldaptest.NewEntry("cn=sourcegraph,ou=services,dc=example,dc=com", "userPassword", "servicepw"),
+		ldaptest.NewEntry("uid=alice,ou=people,dc=example,dc=com",
+			"uid", "alice", "mail", "alice@example.com", "cn", "Alice", "userPassword", "alicepw"),
+		ldaptest.NewEntry("uid=bob,ou=people,dc=example,dc=com", "uid", "bob", "userPassword", "bobpw"),
+		ldaptest.NewEntry("uid=bob,ou=contractors,dc=example,dc=com", "uid", "bob", "userPassword", "bobpw"),
+		ldaptest.NewEntry("cn=eng,ou=groups,dc=example,dc=com", "cn", "eng", "member", "uid=alice,ou=people,dc=example,dc=com"),
+		ldaptest.NewEntry("cn=ops,ou=groups,dc=example,dc=com", "cn", "ops", "member", "uid=alice,ou=people,dc=example,dc=com"),
+		ldaptest.NewEntry("cn=sales,ou=groups,dc=example,dc=com", "cn", "sales"),
+	)
+	defer s.Close()
+
+	d, err := ldap.NewDirectory(&schema.LDAPAuthProvider{
+		Url:          s.URL,
+		StartTLS:     true,
+		Certificate:  s.Certificate,
+		BindDN:       "cn=sourcegraph,ou=services,dc=example,dc=com",
+		BindPassword: "servicepw",
+		UserSearch:   schema.LDAPUserSearch{BaseDN: "dc=example,dc=com"},
+		GroupSearch:  &schema.LDAPGroupSearch{BaseDN: "ou=groups,dc=example,dc=com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Authenticate", func(t *testing.T) {
+		u, err := d.Authenticate(ctx, "alice", "alicepw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &ldap.User{DN: "uid=alice,ou=people,dc=example,dc=com", Username: "alice", Email: "alice@example.com", DisplayName: "Alice"}
+		if !reflect.DeepEqual(u, want) {
+			t.Errorf("have %+v, want %+v", u, want)
+		}
+
+		for _, tc := range []struct{ username, password string }{
+			{"alice", "wrong"},
+			{"alice", ""},
+			{"nobody", "alicepw"},
+			{"*", "alicepw"},
+			{"alice)(uid=*", "alicepw"},
+		} {
+			if _, err := d.Authenticate(ctx, tc.username, tc.password); err != ldap.ErrInvalidCredentials {
+				t.Errorf("%q/%q: have err %v, want %v", tc.username, tc.password, err, ldap.ErrInvalidCredentials)
+			}
+		}
+
+		if _, err := d.Authenticate(ctx, "bob", "bobpw"); err == nil || err == ldap.ErrInvalidCredentials {
+			t.Errorf("ambiguous user: have err %v, want an error about multiple entries", err)
+		}
+	})
+
+	t.Run("Groups", func(t *testing.T) {
+		groups, err := d.Groups(ctx, "uid=alice,ou=people,dc=example,dc=com", "alice")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []string{"eng", "ops"}; !reflect.DeepEqual(groups, want) {
+			t.Errorf("have %v, want %v", groups, want)
+		}
+	})
+}
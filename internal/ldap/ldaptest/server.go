@@ -0,0 +1,384 @@
+// Package ldaptest provides an in-process LDAP server for testing.
+package ldaptest
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+)
+
+// PasswordAttribute is the attribute of an entry holding the (cleartext) password that the
+// server checks on binds.
+const PasswordAttribute = "userPassword"
+
+// NewEntry returns an entry with the given DN and attributes, which are given as name-value
+// pairs. Repeating a name adds another value to the attribute.
+func NewEntry(dn string, attrs ...string) *ldap.Entry {
+	e := &ldap.Entry{DN: dn}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		name, value := attrs[i], attrs[i+1]
+		var attr *ldap.EntryAttribute
+		for _, a := range e.Attributes {
+			if a.Name == name {
+				attr = a
+			}
+		}
+		if attr == nil {
+			attr = &ldap.EntryAttribute{Name: name}
+			e.Attributes = append(e.Attributes, attr)
+		}
+		attr.Values = append(attr.Values, value)
+		attr.ByteValues = append(attr.ByteValues, []byte(value))
+	}
+	return e
+}
+
+// values returns the values of the named attribute of e. Attribute names are
+// case-insensitive.
+func values(e *ldap.Entry, name string) []string {
+	for _, a := range e.Attributes {
+		if strings.EqualFold(a.Name, name) {
+			return a.Values
+		}
+	}
+	return nil
+}
+
+// Server is an in-process LDAP server serving a fixed directory. It supports simple binds,
+// searches (with all filters except extensible matches) and StartTLS.
+type Server struct {
+	// URL is the ldap:// URL of the server.
+	URL string
+	// Certificate is the PEM-encoded self-signed certificate the server uses for StartTLS.
+	Certificate string
+
+	mu      sync.Mutex
+	entries []*ldap.Entry
+	binds   []string
+
+	listener  net.Listener
+	tlsConfig *tls.Config
+}
+
+// NewServer starts a server serving the given entries. The caller should call Close when
+// finished, to shut it down.
+func NewServer(entries ...*ldap.Entry) *Server {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic("ldaptest: failed to listen: " + err.Error())
+	}
+
+	cert, certPEM := selfSignedCertificate()
+	s := &Server{
+		URL:         "ldap://" + l.Addr().String(),
+		Certificate: certPEM,
+		entries:     entries,
+		listener:    l,
+		tlsConfig:   &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.listener.Close()
+}
+
+// SetEntries replaces the entries served by the server.
+func (s *Server) SetEntries(entries ...*ldap.Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = entries
+}
+
+// Binds returns the DNs of all successful binds so far.
+func (s *Server) Binds() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.binds...)
+}
+
+func (s *Server) serve(c net.Conn) {
+	defer c.Close()
+
+	r := bufio.NewReader(c)
+	for {
+		msg, err := ber.ReadPacket(r)
+		if err != nil || len(msg.Children) < 2 {
+			return
+		}
+		id, _ := msg.Children[0].Value.(int64)
+		op := msg.Children[1]
+		if op.ClassType != ber.ClassApplication {
+			return
+		}
+
+		var resps []*ber.Packet
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			resps = []*ber.Packet{s.bind(op)}
+		case ldap.ApplicationUnbindRequest:
+			return
+		case ldap.ApplicationSearchRequest:
+			resps = s.search(op)
+		case ldap.ApplicationExtendedRequest:
+			if len(op.Children) == 0 || str(op.Children[0]) != "1.3.6.1.4.1.1466.20037" {
+				resps = []*ber.Packet{response(ldap.ApplicationExtendedResponse, ldap.LDAPResultProtocolError, "unsupported extended operation")}
+				break
+			}
+			if _, err := c.Write(message(id, response(ldap.ApplicationExtendedResponse, ldap.LDAPResultSuccess, "")).Bytes()); err != nil {
+				return
+			}
+			tc := tls.Server(c, s.tlsConfig)
+			if err := tc.Handshake(); err != nil {
+				return
+			}
+			c, r = tc, bufio.NewReader(tc)
+			continue
+		default:
+			return
+		}
+
+		for _, resp := range resps {
+			if _, err := c.Write(message(id, resp).Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) bind(op *ber.Packet) *ber.Packet {
+	if len(op.Children) != 3 {
+		return response(ldap.ApplicationBindResponse, ldap.LDAPResultProtocolError, "malformed bind request")
+	}
+	dn, password := str(op.Children[1]), str(op.Children[2])
+	if dn == "" && password == "" {
+		return response(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.entries {
+		if strings.EqualFold(e.DN, dn) {
+			if pw := values(e, PasswordAttribute); password != "" && len(pw) > 0 && pw[0] == password {
+				s.binds = append(s.binds, e.DN)
+				return response(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, "")
+			}
+			break
+		}
+	}
+	return response(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "invalid credentials")
+}
+
+func (s *Server) search(op *ber.Packet) []*ber.Packet {
+	if len(op.Children) != 8 {
+		return []*ber.Packet{response(ldap.ApplicationSearchResultDone, ldap.LDAPResultProtocolError, "malformed search request")}
+	}
+	base := strings.ToLower(str(op.Children[0]))
+	scope, _ := op.Children[1].Value.(int64)
+	sizeLimit, _ := op.Children[3].Value.(int64)
+	filter := op.Children[6]
+	var attrs []string
+	for _, a := range op.Children[7].Children {
+		attrs = append(attrs, str(a))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resps []*ber.Packet
+	for _, e := range s.entries {
+		if !inScope(strings.ToLower(e.DN), base, int(scope)) || !matches(e, filter) {
+			continue
+		}
+		if sizeLimit > 0 && int64(len(resps)) == sizeLimit {
+			return append(resps, response(ldap.ApplicationSearchResultDone, ldap.LDAPResultSizeLimitExceeded, ""))
+		}
+		resps = append(resps, entryPacket(e, attrs))
+	}
+	return append(resps, response(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, ""))
+}
+
+func inScope(dn, base string, scope int) bool {
+	switch scope {
+	case ldap.ScopeBaseObject:
+		return dn == base
+	case ldap.ScopeSingleLevel:
+		i := strings.IndexByte(dn, ',')
+		return i >= 0 && dn[i+1:] == base
+	default:
+		return base == "" || dn == base || strings.HasSuffix(dn, ","+base)
+	}
+}
+
+// matches evaluates the encoded search filter f against e. Attribute values are compared
+// case-insensitively.
+func matches(e *ldap.Entry, f *ber.Packet) bool {
+	switch f.Tag {
+	case 0: // and
+		for _, c := range f.Children {
+			if !matches(e, c) {
+				return false
+			}
+		}
+		return true
+	case 1: // or
+		for _, c := range f.Children {
+			if matches(e, c) {
+				return true
+			}
+		}
+		return false
+	case 2: // not
+		return len(f.Children) == 1 && !matches(e, f.Children[0])
+	case 3, 5, 6, 8: // equalityMatch, greaterOrEqual, lessOrEqual, approxMatch
+		want := strings.ToLower(str(f.Children[1]))
+		for _, v := range values(e, str(f.Children[0])) {
+			v = strings.ToLower(v)
+			if (f.Tag == 5 && v >= want) || (f.Tag == 6 && v <= want) || v == want {
+				return true
+			}
+		}
+		return false
+	case 4: // substrings
+		for _, v := range values(e, str(f.Children[0])) {
+			if matchesSubstrings(strings.ToLower(v), f.Children[1].Children) {
+				return true
+			}
+		}
+		return false
+	case 7: // present
+		return len(values(e, str(f))) > 0
+	default:
+		return false
+	}
+}
+
+func matchesSubstrings(v string, subs []*ber.Packet) bool {
+	for _, sub := range subs {
+		s := strings.ToLower(str(sub))
+		switch sub.Tag {
+		case 0: // initial
+			if !strings.HasPrefix(v, s) {
+				return false
+			}
+			v = v[len(s):]
+		case 1: // any
+			i := strings.Index(v, s)
+			if i < 0 {
+				return false
+			}
+			v = v[i+len(s):]
+		case 2: // final
+			if !strings.HasSuffix(v, s) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func entryPacket(e *ldap.Entry, attrs []string) *ber.Packet {
+	list := ber.NewSequence("attributes")
+	for _, a := range e.Attributes {
+		if strings.EqualFold(a.Name, PasswordAttribute) || !selected(a.Name, attrs) {
+			continue
+		}
+		vals := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "values")
+		for _, v := range a.Values {
+			vals.AppendChild(octetString(v))
+		}
+		attr := ber.NewSequence("attribute")
+		attr.AppendChild(octetString(a.Name))
+		attr.AppendChild(vals)
+		list.AppendChild(attr)
+	}
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "search result entry")
+	p.AppendChild(octetString(e.DN))
+	p.AppendChild(list)
+	return p
+}
+
+func selected(name string, attrs []string) bool {
+	if len(attrs) == 0 {
+		return true
+	}
+	for _, a := range attrs {
+		if a == "*" || strings.EqualFold(a, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func response(tag ber.Tag, code uint16, msg string) *ber.Packet {
+	p := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "response")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "result code"))
+	p.AppendChild(octetString(""))
+	p.AppendChild(octetString(msg))
+	return p
+}
+
+func message(id int64, op *ber.Packet) *ber.Packet {
+	p := ber.NewSequence("LDAP message")
+	p.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "message ID"))
+	p.AppendChild(op)
+	return p
+}
+
+func octetString(s string) *ber.Packet {
+	return ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, s, "")
+}
+
+// str returns the contents of the primitive packet p as a string.
+func str(p *ber.Packet) string {
+	return p.Data.String()
+}
+
+// selfSignedCertificate returns a certificate for 127.0.0.1 and its PEM encoding.
+func selfSignedCertificate() (tls.Certificate, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic("ldaptest: failed to generate key: " + err.Error())
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"ldaptest"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		panic("ldaptest: failed to create certificate: " + err.Error())
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key},
+		string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+}
@@ -0,0 +1,250 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Priority is the priority of the API requests made with a context. When the
+// rate limit budget of a code host token runs low, requests with a lower
+// priority wait for the rate limit to reset, leaving the remaining budget to
+// requests with a higher priority.
+type Priority int
+
+const (
+	// PriorityInteractive is the priority of requests made on behalf of a user
+	// waiting for the result, such as repository lookups. It is the default
+	// priority and never waits on the budget.
+	PriorityInteractive Priority = iota
+	// PriorityChangesetSync is the priority of campaign changeset syncs.
+	PriorityChangesetSync
+	// PriorityPermsSync is the priority of background permissions syncs.
+	PriorityPermsSync
+	// PriorityRepoListing is the priority of external service repository syncs.
+	PriorityRepoListing
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityChangesetSync:
+		return "changeset_sync"
+	case PriorityPermsSync:
+		return "perms_sync"
+	case PriorityRepoListing:
+		return "repo_listing"
+	default:
+		return "unknown"
+	}
+}
+
+// reservedFraction returns the fraction of the rate limit that requests with
+// this priority must leave to requests with a higher priority.
+func (p Priority) reservedFraction() float64 {
+	switch p {
+	case PriorityInteractive:
+		return 0
+	case PriorityChangesetSync:
+		return 0.1
+	case PriorityPermsSync:
+		return 0.2
+	default:
+		return 0.3
+	}
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose code host API requests are made with
+// the given priority.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority of the code host API requests made
+// with ctx, which is PriorityInteractive unless set with WithPriority.
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p
+	}
+	return PriorityInteractive
+}
+
+// Budget is the rate limit budget of a single code host token. It is shared
+// by all API clients using the token, so that they account for each other's
+// requests.
+type Budget struct {
+	// Monitor tracks the rate limit reported by the code host.
+	Monitor *Monitor
+
+	codeHost string // metrics label
+	resource string // metrics label
+
+	// assumedReset is when the rate limit is assumed to reset next while the
+	// rate limit info of the Monitor is out of date. It's guarded by the
+	// Monitor's mutex, and kept apart so that the Monitor's reset time is
+	// always the one reported by the code host.
+	assumedReset time.Time
+}
+
+// Reserve reserves cost units of the budget for a request made with ctx,
+// waiting until the rate limit resets if the priority of ctx (see
+// WithPriority) doesn't allow spending the remaining budget. It returns early
+// with an error if ctx is done.
+func (b *Budget) Reserve(ctx context.Context, cost int) error {
+	p := PriorityFromContext(ctx)
+	labels := prometheus.Labels{"code_host": b.codeHost, "priority": p.String()}
+
+	start := time.Now()
+	for {
+		wait := b.reserve(p, cost)
+		if wait <= 0 {
+			break
+		}
+
+		budgetWaiting.With(labels).Inc()
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			budgetWaiting.With(labels).Dec()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		budgetWaiting.With(labels).Dec()
+	}
+
+	budgetReservations.With(labels).Add(float64(cost))
+	budgetWaitDuration.With(labels).Observe(time.Since(start).Seconds())
+	return nil
+}
+
+// reserve deducts cost from the remaining rate limit and returns 0 if a
+// request with priority p can be made now. Otherwise, it returns how long to
+// wait before trying again.
+func (b *Budget) reserve(p Priority, cost int) time.Duration {
+	m := b.Monitor
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if p != PriorityInteractive && m.retry.After(now) {
+		return m.retry.Sub(now)
+	}
+
+	if !m.known {
+		return 0
+	}
+
+	// If our rate limit info is out of date, assume it was reset and resets
+	// again in an hour, until the code host reports otherwise.
+	reset := m.reset
+	if now.After(reset) {
+		if now.After(b.assumedReset) {
+			m.remaining = m.limit
+			b.assumedReset = now.Add(time.Hour)
+		}
+		reset = b.assumedReset
+	}
+
+	reserved := int(float64(m.limit) * p.reservedFraction())
+	if p != PriorityInteractive && m.remaining-cost < reserved {
+		return reset.Sub(now)
+	}
+
+	// Account for the request until the code host reports the actual
+	// remaining rate limit in its response.
+	m.remaining -= cost
+	return 0
+}
+
+// Update updates the budget's rate limit information based on the HTTP
+// response headers of a request made with its token.
+func (b *Budget) Update(h http.Header) {
+	b.Monitor.Update(h)
+	if remaining, _, _, known := b.Monitor.Get(); known {
+		budgetRemaining.WithLabelValues(b.codeHost, b.resource).Set(float64(remaining))
+	}
+}
+
+// BudgetManager hands out the rate limit budgets of code host tokens.
+type BudgetManager struct {
+	mu      sync.Mutex
+	budgets map[string]*Budget
+}
+
+// NewBudgetManager returns a new BudgetManager without any budgets.
+func NewBudgetManager() *BudgetManager {
+	return &BudgetManager{budgets: make(map[string]*Budget)}
+}
+
+// DefaultBudgetManager is the BudgetManager shared by all code host API
+// clients of a process.
+var DefaultBudgetManager = NewBudgetManager()
+
+// Get returns the budget of the given token on the code host with the given
+// base URL, creating it if needed. The resource distinguishes independent rate
+// limits of the same token (such as GitHub's search API rate limit) and is
+// empty for the default rate limit. headerPrefix is the prefix of the rate
+// limit HTTP response headers of the code host (see Monitor.HeaderPrefix).
+func (m *BudgetManager) Get(baseURL *url.URL, token, resource, headerPrefix string) *Budget {
+	// Budgets are keyed by a hash so that tokens aren't kept in memory for
+	// the lifetime of the process.
+	sum := sha256.Sum256([]byte(token + ":" + resource + ":" + baseURL.String()))
+	key := base64.URLEncoding.EncodeToString(sum[:])
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if b, ok := m.budgets[key]; ok {
+		return b
+	}
+	b := &Budget{
+		Monitor:  &Monitor{HeaderPrefix: headerPrefix},
+		codeHost: strings.ToLower(baseURL.Hostname()),
+		resource: resource,
+	}
+	m.budgets[key] = b
+	return b
+}
+
+var (
+	budgetRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "src",
+		Subsystem: "codehost_ratelimit",
+		Name:      "remaining",
+		Help:      "The rate limit remaining, as most recently reported by the code host for any token.",
+	}, []string{"code_host", "resource"})
+	budgetReservations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "src",
+		Subsystem: "codehost_ratelimit",
+		Name:      "reserved_total",
+		Help:      "Total rate limit units reserved by code host API requests.",
+	}, []string{"code_host", "priority"})
+	budgetWaitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "src",
+		Subsystem: "codehost_ratelimit",
+		Name:      "wait_duration_seconds",
+		Help:      "Time spent waiting for the rate limit budget before making code host API requests.",
+		Buckets:   []float64{0.01, 0.1, 1, 10, 60, 300, 900, 1800, 3600},
+	}, []string{"code_host", "priority"})
+	budgetWaiting = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "src",
+		Subsystem: "codehost_ratelimit",
+		Name:      "waiting",
+		Help:      "Number of code host API requests currently waiting for the rate limit budget.",
+	}, []string{"code_host", "priority"})
+)
+
+func init() {
+	prometheus.MustRegister(budgetRemaining, budgetReservations, budgetWaitDuration, budgetWaiting)
+}
@@ -0,0 +1,156 @@
+package ratelimit
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestBudget_reserve(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	for _, tc := range []struct {
+		name      string
+		monitor   *Monitor
+		priority  Priority
+		cost      int
+		wait      time.Duration
+		remaining int
+	}{
+		{
+			name:     "unknown rate limit",
+			monitor:  &Monitor{clock: clock},
+			priority: PriorityRepoListing,
+			cost:     1,
+		},
+		{
+			name:      "interactive requests spend the whole budget",
+			monitor:   &Monitor{clock: clock, known: true, limit: 100, remaining: 1, reset: now.Add(time.Minute)},
+			priority:  PriorityInteractive,
+			cost:      1,
+			remaining: 0,
+		},
+		{
+			name:      "changeset syncs leave 10% to interactive requests",
+			monitor:   &Monitor{clock: clock, known: true, limit: 100, remaining: 11, reset: now.Add(time.Minute)},
+			priority:  PriorityChangesetSync,
+			cost:      1,
+			remaining: 10,
+		},
+		{
+			name:      "perms syncs leave 20% to higher priorities",
+			monitor:   &Monitor{clock: clock, known: true, limit: 100, remaining: 20, reset: now.Add(time.Minute)},
+			priority:  PriorityPermsSync,
+			cost:      1,
+			wait:      time.Minute,
+			remaining: 20,
+		},
+		{
+			name:      "repo listing leaves 30% to higher priorities",
+			monitor:   &Monitor{clock: clock, known: true, limit: 100, remaining: 31, reset: now.Add(time.Minute)},
+			priority:  PriorityRepoListing,
+			cost:      2,
+			wait:      time.Minute,
+			remaining: 31,
+		},
+		{
+			name:      "out of date rate limit is assumed to be reset",
+			monitor:   &Monitor{clock: clock, known: true, limit: 100, remaining: 0, reset: now.Add(-time.Second)},
+			priority:  PriorityRepoListing,
+			cost:      1,
+			remaining: 99,
+		},
+		{
+			name:      "background requests wait for Retry-After",
+			monitor:   &Monitor{clock: clock, retry: now.Add(30 * time.Second)},
+			priority:  PriorityChangesetSync,
+			cost:      1,
+			wait:      30 * time.Second,
+			remaining: 0,
+		},
+		{
+			name:      "interactive requests ignore Retry-After",
+			monitor:   &Monitor{clock: clock, retry: now.Add(30 * time.Second)},
+			priority:  PriorityInteractive,
+			cost:      1,
+			remaining: 0,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			b := &Budget{Monitor: tc.monitor}
+			if have, want := b.reserve(tc.priority, tc.cost), tc.wait; have != want {
+				t.Errorf("wait: have %s, want %s", have, want)
+			}
+			if have, want := b.Monitor.remaining, tc.remaining; have != want {
+				t.Errorf("remaining: have %d, want %d", have, want)
+			}
+		})
+	}
+}
+
+func TestBudget_reserveKeepsReportedReset(t *testing.T) {
+	now := time.Now()
+	reset := now.Add(-time.Second)
+	b := &Budget{Monitor: &Monitor{
+		clock:     func() time.Time { return now },
+		known:     true,
+		limit:     100,
+		remaining: 0,
+		reset:     reset,
+	}}
+
+	for i := 0; i < 70; i++ {
+		if wait := b.reserve(PriorityRepoListing, 1); wait != 0 {
+			t.Fatalf("request %d: unexpected wait %s", i, wait)
+		}
+	}
+	if wait := b.reserve(PriorityRepoListing, 1); wait != time.Hour {
+		t.Errorf("wait: have %s, want the assumed reset in %s", wait, time.Hour)
+	}
+	if !b.Monitor.reset.Equal(reset) {
+		t.Errorf("monitor reset: have %s, want the reported %s", b.Monitor.reset, reset)
+	}
+}
+
+func TestBudget_Reserve(t *testing.T) {
+	now := time.Now()
+	b := &Budget{Monitor: &Monitor{
+		clock:     func() time.Time { return now },
+		known:     true,
+		limit:     100,
+		remaining: 5,
+		reset:     now.Add(time.Hour),
+	}}
+
+	if err := b.Reserve(context.Background(), 1); err != nil {
+		t.Fatalf("interactive request: unexpected error: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(WithPriority(context.Background(), PriorityRepoListing), 10*time.Millisecond)
+	defer cancel()
+	if err := b.Reserve(ctx, 1); err != context.DeadlineExceeded {
+		t.Fatalf("repo listing request: have err %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestBudgetManager_Get(t *testing.T) {
+	m := NewBudgetManager()
+	u := &url.URL{Scheme: "https", Host: "GHE.example.com"}
+
+	b := m.Get(u, "token", "", "X-")
+	if b.codeHost != "ghe.example.com" || b.Monitor.HeaderPrefix != "X-" {
+		t.Fatalf("unexpected budget: %+v", b)
+	}
+	if m.Get(u, "token", "", "X-") != b {
+		t.Error("expected the same budget for the same token")
+	}
+	if m.Get(u, "other", "", "X-") == b {
+		t.Error("expected a different budget for a different token")
+	}
+	if m.Get(u, "token", "search", "X-") == b {
+		t.Error("expected a different budget for a different resource")
+	}
+}
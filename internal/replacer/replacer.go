@@ -0,0 +1,8 @@
+// Package replacer contains the configuration shared by the clients of the
+// replacer service.
+package replacer
+
+import "github.com/sourcegraph/sourcegraph/internal/env"
+
+// URL is the URL of the replacer service.
+var URL = env.Get("REPLACER_URL", "http://replacer:3185", "replacer server URL")
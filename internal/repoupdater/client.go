@@ -210,6 +210,33 @@ func (c *Client) EnqueueChangesetSync(ctx context.Context, ids []int64) error {
 	return errors.New(res.Error)
 }
 
+// MockPermsSyncInfo mocks (*Client).PermsSyncInfo for tests.
+var MockPermsSyncInfo func(ctx context.Context, args protocol.PermsSyncInfoRequest) (*protocol.PermsSyncInfoResponse, error)
+
+// PermsSyncInfo returns the results of the last permissions syncs of a user and a repository.
+func (c *Client) PermsSyncInfo(ctx context.Context, args protocol.PermsSyncInfoRequest) (*protocol.PermsSyncInfoResponse, error) {
+	if MockPermsSyncInfo != nil {
+		return MockPermsSyncInfo(ctx, args)
+	}
+
+	resp, err := c.httpPost(ctx, "perms-sync-info", args)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		stack := fmt.Sprintf("PermsSyncInfo: %+v", args)
+		return nil, errors.Wrap(fmt.Errorf("http status %d", resp.StatusCode), stack)
+	}
+
+	var res protocol.PermsSyncInfoResponse
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
 // SyncExternalService requests the given external service to be synced.
 func (c *Client) SyncExternalService(ctx context.Context, svc api.ExternalService) (*protocol.ExternalServiceSyncResult, error) {
 	req := &protocol.ExternalServiceSyncRequest{ExternalService: svc}
@@ -243,6 +270,37 @@ func (c *Client) SyncExternalService(ctx context.Context, svc api.ExternalServic
 	return &result, nil
 }
 
+// SyncExternalServiceDryRun requests a preview of the repos that a sync of the
+// given external service, with its possibly unsaved config, would add, delete
+// or modify. Nothing is persisted.
+func (c *Client) SyncExternalServiceDryRun(ctx context.Context, svc api.ExternalService) (*protocol.ExternalServiceDryRunResult, error) {
+	req := &protocol.ExternalServiceDryRunRequest{ExternalService: svc}
+	resp, err := c.httpPost(ctx, "sync-external-service-dry-run", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	bs, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read response body")
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return nil, errors.New(string(bs))
+	}
+
+	var result protocol.ExternalServiceDryRunResult
+	if err = json.Unmarshal(bs, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	return &result, nil
+}
+
 // RepoExternalServices requests the external services associated with a
 // repository with the given id.
 func (c *Client) RepoExternalServices(ctx context.Context, id api.RepoID) ([]api.ExternalService, error) {
@@ -170,6 +170,28 @@ type ChangesetSyncResponse struct {
 	Error string
 }
 
+// PermsSyncInfoRequest is a request for the results of the last permissions syncs of a
+// user and a repository.
+type PermsSyncInfoRequest struct {
+	UserID int32
+	RepoID api.RepoID
+}
+
+// PermsSyncInfoResponse is a response to a PermsSyncInfoRequest. A result is nil if the
+// permissions haven't been synced since repo-updater started.
+type PermsSyncInfoResponse struct {
+	User *PermsSyncResult `json:",omitempty"`
+	Repo *PermsSyncResult `json:",omitempty"`
+}
+
+// PermsSyncResult is the result of a permissions sync of a user or a repository.
+type PermsSyncResult struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	// Error is the error of the sync, empty if it succeeded.
+	Error string `json:",omitempty"`
+}
+
 // ExternalServiceSyncRequest is a request to sync a specific external service eagerly.
 //
 // The FrontendAPI is one of the issuers of this request. It does so when creating or
@@ -185,6 +207,25 @@ type ExternalServiceSyncResult struct {
 	Error           string
 }
 
+// ExternalServiceDryRunRequest is a request to preview the effect a sync of
+// an external service with the given, not yet stored, configuration would
+// have on the synced repos.
+type ExternalServiceDryRunRequest struct {
+	ExternalService api.ExternalService
+}
+
+// ExternalServiceDryRunResult is the result type of an external service's dry
+// run request. It lists the names of the repos a sync would add, delete,
+// modify or leave unmodified.
+type ExternalServiceDryRunResult struct {
+	ExternalService api.ExternalService
+	Added           []api.RepoName
+	Deleted         []api.RepoName
+	Modified        []api.RepoName
+	Unmodified      []api.RepoName
+	Error           string
+}
+
 type CloningProgress struct {
 	Message string
 }
@@ -0,0 +1,123 @@
+// Package totp implements time-based one-time passwords (TOTP, RFC 6238) as used by
+// authenticator apps, with the common parameters: HMAC-SHA1, 6 digits and a 30 second period.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sourcegraph/sourcegraph/internal/randstring"
+)
+
+const (
+	// period is the time step of codes.
+	period = 30 * time.Second
+	// digits is the number of digits of codes.
+	digits = 6
+	// skew is the number of time steps before and after the current one whose codes are also
+	// accepted, to allow for clock drift and the time it takes to enter a code.
+	skew = 1
+	// secretSize is the number of random bytes of secrets, as recommended by RFC 4226.
+	secretSize = 20
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a new random secret, encoded in unpadded base32 as expected by
+// authenticator apps.
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(b), nil
+}
+
+// KeyURI returns the otpauth:// URI of the secret, which authenticator apps can import (usually by
+// scanning it as a QR code). The issuer identifies the site and the account identifies the user.
+func KeyURI(issuer, account, secret string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: q.Encode(),
+	}
+	return u.String()
+}
+
+// Validate reports whether the code is valid for the secret at time t. If it is, it also returns
+// the time step the code belongs to, which callers should record to reject reuse of the code.
+func Validate(secret, code string, t time.Time) (step int64, ok bool, err error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.TrimSpace(secret)))
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid TOTP secret: %s", err)
+	}
+
+	code = strings.TrimSpace(code)
+	if len(code) != digits {
+		return 0, false, nil
+	}
+
+	current := t.Unix() / int64(period/time.Second)
+	for s := current - skew; s <= current+skew; s++ {
+		if subtle.ConstantTimeCompare([]byte(generate(key, s, digits)), []byte(code)) == 1 {
+			return s, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// generate returns the code of the key at the time step (RFC 4226, section 5.3).
+func generate(key []byte, step int64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0xf
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
+
+// recoveryCodeChars are the characters of recovery codes, without the ones that are easily
+// confused with each other.
+var recoveryCodeChars = []byte("abcdefghjkmnpqrstuvwxyz23456789")
+
+// GenerateRecoveryCodes returns n new random recovery codes, which can each be used once instead
+// of a TOTP code (e.g. when the authenticator app is lost).
+func GenerateRecoveryCodes(n int) []string {
+	codes := make([]string, n)
+	for i := range codes {
+		s := randstring.NewLenChars(10, recoveryCodeChars)
+		codes[i] = s[:5] + "-" + s[5:]
+	}
+	return codes
+}
+
+// HashRecoveryCode returns the hash of the recovery code to store. Recovery codes are random and
+// long enough that a fast hash is sufficient, and it lets codes be looked up by their hashes.
+func HashRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
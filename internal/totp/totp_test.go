@@ -0,0 +1,116 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerate(t *testing.T) {
+	// The SHA1 test vectors of RFC 6238, appendix B.
+	key := []byte("12345678901234567890")
+	for _, tc := range []struct {
+		unix int64
+		want string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	} {
+		if have := generate(key, tc.unix/30, 8); have != tc.want {
+			t.Errorf("time %d: have %q, want %q", tc.unix, have, tc.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+	now := time.Unix(1111111109, 0)
+
+	for _, tc := range []struct {
+		name     string
+		code     string
+		t        time.Time
+		wantOK   bool
+		wantStep int64
+	}{
+		{name: "current step", code: "081804", t: now, wantOK: true, wantStep: 37037036},
+		{name: "surrounding whitespace", code: " 081804\n", t: now, wantOK: true, wantStep: 37037036},
+		{name: "previous step", code: "081804", t: now.Add(30 * time.Second), wantOK: true, wantStep: 37037036},
+		{name: "next step", code: "081804", t: now.Add(-30 * time.Second), wantOK: true, wantStep: 37037036},
+		{name: "too old", code: "081804", t: now.Add(90 * time.Second)},
+		{name: "wrong code", code: "081805", t: now},
+		{name: "wrong length", code: "07081804", t: now},
+		{name: "empty", code: "", t: now},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			step, ok, err := Validate(secret, tc.code, tc.t)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.wantOK || step != tc.wantStep {
+				t.Errorf("have (%d, %v), want (%d, %v)", step, ok, tc.wantStep, tc.wantOK)
+			}
+		})
+	}
+
+	if _, _, err := Validate("not base32!", "123456", now); err == nil {
+		t.Error("expected an error for an invalid secret")
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("have secret of length %d, want 32", len(secret))
+	}
+	code := generate(mustDecode(t, secret), time.Now().Unix()/30, digits)
+	if _, ok, err := Validate(secret, code, time.Now()); err != nil || !ok {
+		t.Errorf("expected the generated code to be valid, have (%v, %v)", ok, err)
+	}
+}
+
+func TestKeyURI(t *testing.T) {
+	have := KeyURI("sourcegraph.example.com", "alice", "JBSWY3DPEHPK3PXP")
+	want := "otpauth://totp/sourcegraph.example.com:alice?issuer=sourcegraph.example.com&secret=JBSWY3DPEHPK3PXP"
+	if have != want {
+		t.Errorf("have %q, want %q", have, want)
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes := GenerateRecoveryCodes(10)
+	seen := map[string]bool{}
+	for _, c := range codes {
+		if len(c) != 11 || c[5] != '-' {
+			t.Errorf("unexpected recovery code format %q", c)
+		}
+		if seen[c] {
+			t.Errorf("duplicate recovery code %q", c)
+		}
+		seen[c] = true
+	}
+
+	if HashRecoveryCode(codes[0]) != HashRecoveryCode(" "+strings.ToUpper(codes[0])+" ") {
+		t.Error("expected hashes to ignore case and surrounding whitespace")
+	}
+	if HashRecoveryCode(codes[0]) == HashRecoveryCode(codes[1]) {
+		t.Error("expected different codes to have different hashes")
+	}
+}
+
+func mustDecode(t *testing.T, secret string) []byte {
+	t.Helper()
+	key, err := encoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
@@ -0,0 +1,10 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS external_service_sync_cursors (
+  external_service_id bigint PRIMARY KEY REFERENCES external_services(id) ON DELETE CASCADE DEFERRABLE,
+  cursor text NOT NULL DEFAULT '',
+  last_full_sync_at timestamptz,
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+COMMIT;
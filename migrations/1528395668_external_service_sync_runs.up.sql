@@ -0,0 +1,22 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS external_service_sync_runs (
+  id bigserial PRIMARY KEY,
+  external_service_id bigint NOT NULL REFERENCES external_services(id) ON DELETE CASCADE DEFERRABLE,
+  started_at timestamptz NOT NULL,
+  finished_at timestamptz NOT NULL,
+  added integer NOT NULL DEFAULT 0,
+  deleted integer NOT NULL DEFAULT 0,
+  modified integer NOT NULL DEFAULT 0,
+  unmodified integer NOT NULL DEFAULT 0,
+  rate_limit_wait_ms bigint NOT NULL DEFAULT 0,
+  error text NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS external_service_sync_runs_external_service_id_started_at
+ON external_service_sync_runs (external_service_id, started_at DESC);
+
+CREATE INDEX IF NOT EXISTS external_service_sync_runs_started_at
+ON external_service_sync_runs (started_at);
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP INDEX IF EXISTS repo_topics_gin_idx;
+
+ALTER TABLE repo
+  DROP COLUMN IF EXISTS topics,
+  DROP COLUMN IF EXISTS stars,
+  DROP COLUMN IF EXISTS default_branch,
+  DROP COLUMN IF EXISTS visibility;
+
+COMMIT;
@@ -0,0 +1,11 @@
+BEGIN;
+
+ALTER TABLE repo
+  ADD COLUMN IF NOT EXISTS topics jsonb NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(topics) = 'array'),
+  ADD COLUMN IF NOT EXISTS stars integer NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS default_branch text NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS visibility text NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS repo_topics_gin_idx ON repo USING gin (topics);
+
+COMMIT;
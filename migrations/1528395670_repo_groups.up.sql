@@ -0,0 +1,17 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS repo_groups (
+  id serial PRIMARY KEY,
+  name citext NOT NULL,
+  description text NOT NULL DEFAULT '',
+  rules jsonb NOT NULL DEFAULT '{}' CHECK (jsonb_typeof(rules) = 'object'),
+  org_id integer REFERENCES orgs(id) ON DELETE CASCADE,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT repo_groups_name_valid_chars CHECK (name ~ '^[a-zA-Z0-9_.-]+$')
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS repo_groups_name_unique ON repo_groups (name);
+CREATE INDEX IF NOT EXISTS repo_groups_org_id ON repo_groups (org_id);
+
+COMMIT;
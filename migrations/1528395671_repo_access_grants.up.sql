@@ -0,0 +1,21 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS repo_access_grants (
+  id bigserial PRIMARY KEY,
+  repo_id integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE,
+  user_id integer REFERENCES users(id) ON DELETE CASCADE,
+  org_id integer REFERENCES orgs(id) ON DELETE CASCADE,
+  creator_user_id integer REFERENCES users(id) ON DELETE SET NULL,
+  reason text NOT NULL DEFAULT '',
+  expires_at timestamptz NOT NULL,
+  revoked_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT repo_access_grants_grantee CHECK ((user_id IS NULL) <> (org_id IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS repo_access_grants_repo_id ON repo_access_grants (repo_id);
+CREATE INDEX IF NOT EXISTS repo_access_grants_active_user_id ON repo_access_grants (user_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS repo_access_grants_active_org_id ON repo_access_grants (org_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS repo_access_grants_active_expires_at ON repo_access_grants (expires_at) WHERE revoked_at IS NULL;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN totp_secret text;
+ALTER TABLE users ADD COLUMN totp_enabled_at timestamp with time zone;
+ALTER TABLE users ADD COLUMN totp_last_step bigint;
+ALTER TABLE users ADD COLUMN totp_recovery_codes text[] NOT NULL DEFAULT '{}';
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE access_tokens ADD COLUMN expires_at timestamp with time zone;
+
+COMMIT;
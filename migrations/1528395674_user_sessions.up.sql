@@ -0,0 +1,16 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+  id bigserial PRIMARY KEY,
+  user_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  key text NOT NULL,
+  user_agent text NOT NULL DEFAULT '',
+  ip text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  last_seen_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_sessions_key_unique ON user_sessions (key);
+CREATE INDEX IF NOT EXISTS user_sessions_user_id ON user_sessions (user_id);
+
+COMMIT;
@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS patch_jobs (
+  id bigserial PRIMARY KEY,
+  patch_set_id bigint NOT NULL REFERENCES patch_sets(id) ON DELETE CASCADE DEFERRABLE,
+  repo_id integer NOT NULL REFERENCES repo(id) ON DELETE CASCADE DEFERRABLE,
+  rev text NOT NULL,
+  base_ref text NOT NULL CHECK (base_ref != ''),
+  match_template text NOT NULL,
+  rewrite_template text NOT NULL,
+  file_extension text NOT NULL DEFAULT '',
+  directory_exclude text NOT NULL DEFAULT '',
+  error text NOT NULL DEFAULT '',
+  started_at timestamptz,
+  finished_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS patch_jobs_patch_set_id ON patch_jobs (patch_set_id);
+CREATE INDEX IF NOT EXISTS patch_jobs_started_at ON patch_jobs (started_at);
+
+COMMIT;
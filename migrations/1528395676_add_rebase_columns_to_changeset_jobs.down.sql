@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE changeset_jobs DROP COLUMN IF EXISTS rebase_rev;
+ALTER TABLE changeset_jobs DROP COLUMN IF EXISTS rebase_error;
+ALTER TABLE changeset_jobs DROP COLUMN IF EXISTS rebased_at;
+
+COMMIT;
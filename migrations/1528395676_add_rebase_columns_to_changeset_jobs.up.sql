@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE changeset_jobs ADD COLUMN rebase_rev text NOT NULL DEFAULT '';
+ALTER TABLE changeset_jobs ADD COLUMN rebase_error text NOT NULL DEFAULT '';
+ALTER TABLE changeset_jobs ADD COLUMN rebased_at timestamptz;
+
+COMMIT;
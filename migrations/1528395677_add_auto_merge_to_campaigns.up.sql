@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE campaigns ADD COLUMN auto_merge jsonb;
+
+COMMIT;
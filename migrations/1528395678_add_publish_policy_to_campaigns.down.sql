@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE changeset_jobs DROP COLUMN IF EXISTS released_at;
+ALTER TABLE campaigns DROP COLUMN IF EXISTS publish_policy;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE campaigns ADD COLUMN publish_policy jsonb;
+ALTER TABLE changeset_jobs ADD COLUMN released_at timestamptz;
+
+COMMIT;
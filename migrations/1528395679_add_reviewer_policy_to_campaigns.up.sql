@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE campaigns ADD COLUMN reviewer_policy jsonb;
+
+COMMIT;
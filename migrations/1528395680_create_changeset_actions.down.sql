@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS changeset_action_jobs;
+DROP TABLE IF EXISTS changeset_actions;
+
+COMMIT;
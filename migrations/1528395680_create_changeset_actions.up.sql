@@ -0,0 +1,33 @@
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS changeset_actions (
+  id bigserial PRIMARY KEY,
+  campaign_id bigint NOT NULL REFERENCES campaigns(id) ON DELETE CASCADE DEFERRABLE,
+  author_id integer NOT NULL REFERENCES users(id) ON DELETE CASCADE DEFERRABLE,
+  kind text NOT NULL CHECK (kind != ''),
+  comment text NOT NULL DEFAULT '',
+  labels jsonb NOT NULL DEFAULT '[]' CHECK (jsonb_typeof(labels) = 'array'),
+  external_state text,
+  external_review_state text,
+  external_check_state text,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS changeset_actions_campaign_id ON changeset_actions (campaign_id);
+
+CREATE TABLE IF NOT EXISTS changeset_action_jobs (
+  id bigserial PRIMARY KEY,
+  action_id bigint NOT NULL REFERENCES changeset_actions(id) ON DELETE CASCADE DEFERRABLE,
+  changeset_id bigint NOT NULL REFERENCES changesets(id) ON DELETE CASCADE DEFERRABLE,
+  error text NOT NULL DEFAULT '',
+  started_at timestamptz,
+  finished_at timestamptz,
+  created_at timestamptz NOT NULL DEFAULT now(),
+  updated_at timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT changeset_action_jobs_unique UNIQUE (action_id, changeset_id)
+);
+
+CREATE INDEX IF NOT EXISTS changeset_action_jobs_started_at ON changeset_action_jobs (started_at);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS totp_attempts;
+ALTER TABLE users DROP COLUMN IF EXISTS totp_attempts_since;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE users ADD COLUMN totp_attempts integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN totp_attempts_since timestamptz;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS user_sessions_expires_at;
+ALTER TABLE user_sessions DROP COLUMN IF EXISTS expires_at;
+
+COMMIT;
@@ -0,0 +1,10 @@
+BEGIN;
+
+ALTER TABLE user_sessions ADD COLUMN expires_at timestamptz;
+-- Sessions registered before this migration expire after the default session expiry period.
+UPDATE user_sessions SET expires_at = last_seen_at + interval '90 days';
+ALTER TABLE user_sessions ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS user_sessions_expires_at ON user_sessions (expires_at);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+-- No down migration since the up migration normalizes data.
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Webhook events of Bitbucket Cloud are matched to repos by the normalized URL
+-- of the code host ("https://bitbucket.org/"), so normalize the service IDs of
+-- repos stored with the URL as configured ("https://bitbucket.org"), unless the
+-- repo is also stored with the normalized service ID.
+UPDATE repo r SET external_service_id = lower(r.external_service_id) || '/'
+WHERE r.external_service_type = 'bitbucketCloud'
+AND r.external_service_id NOT LIKE '%/'
+AND NOT EXISTS (
+    SELECT 1 FROM repo o
+    WHERE o.external_service_type = r.external_service_type
+    AND o.external_service_id = lower(r.external_service_id) || '/'
+    AND o.external_id = r.external_id
+);
+
+COMMIT;
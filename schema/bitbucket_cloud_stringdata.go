@@ -43,6 +43,23 @@ const BitbucketCloudSchemaJSON = `{
       "description": "The app password to use when authenticating to the Bitbucket Cloud. Also set the corresponding \"username\" field.",
       "type": "string"
     },
+    "webhooks": {
+      "description": "An array of configurations defining existing Bitbucket Cloud webhooks that send updates back to Sourcegraph. Bitbucket Cloud doesn't sign webhook requests, so the secret is included in the URL of the webhook, ` + "`" + `https://sourcegraph.example.com/.api/bitbucket-cloud-webhooks?secret=<secret>` + "`" + `, which is configured on repositories with the pull request and build status events.",
+      "type": "array",
+      "items": {
+        "type": "object",
+        "title": "BitbucketCloudWebhook",
+        "required": ["secret"],
+        "properties": {
+          "secret": {
+            "description": "The secret included in the URL of the webhook",
+            "type": "string",
+            "minLength": 1
+          }
+        }
+      },
+      "examples": [[{ "secret": "webhook-secret" }]]
+    },
     "gitURLType": {
       "description": "The type of Git URLs to use for cloning and fetching Git repositories on this Bitbucket Cloud.\n\nIf \"http\", Sourcegraph will access Bitbucket Cloud repositories using Git URLs of the form https://bitbucket.org/myteam/myproject.git.\n\nIf \"ssh\", Sourcegraph will access Bitbucket Cloud repositories using Git URLs of the form git@bitbucket.org:myteam/myproject.git. See the documentation for how to provide SSH private keys and known_hosts: https://docs.sourcegraph.com/admin/repo/auth#repositories-that-need-http-s-or-ssh-authentication.",
       "type": "string",
@@ -169,6 +169,11 @@ const CriticalSchemaJSON = `{
           "description": "Allows new visitors to sign up for accounts. The sign-up page will be enabled and accessible to all visitors.\n\nSECURITY: If the site has no users (i.e., during initial setup), it will always allow the first user to sign up and become site admin **without any approval** (first user to sign up becomes the admin).",
           "type": "boolean",
           "default": false
+        },
+        "requireTwoFactor": {
+          "description": "Requires users who sign in with a username and password to use two-factor authentication with an authenticator app (TOTP). Users who haven't enabled two-factor authentication are asked to set it up when they sign in. Access tokens are not affected.",
+          "type": "boolean",
+          "default": false
         }
       }
     },
@@ -304,6 +309,48 @@ const CriticalSchemaJSON = `{
           "description": "The prefix that precedes the username portion of the HTTP header specified in ` + "`" + `usernameHeader` + "`" + `. If specified, the prefix will be stripped from the header value and the remainder will be used as the username. For example, if using Google Identity-Aware Proxy (IAP) with Google Sign-In, set this value to ` + "`" + `accounts.google.com:` + "`" + `.",
           "type": "string",
           "examples": ["accounts.google.com:"]
+        },
+        "configID": {
+          "description": "An identifier that can be used to reference this authentication provider in other parts of the config. Required if there is more than one http-header auth provider. The user accounts of each provider are stored separately.",
+          "type": "string"
+        },
+        "discriminatorHeader": {
+          "description": "The name (case-insensitive) of an HTTP header that identifies the authentication proxy that sent the request. If set, this provider is only used for requests whose header has the value ` + "`" + `discriminatorValue` + "`" + `. Required if there is more than one http-header auth provider.",
+          "type": "string",
+          "examples": ["X-Forwarded-Proxy"]
+        },
+        "discriminatorValue": {
+          "description": "The value of the ` + "`" + `discriminatorHeader` + "`" + ` header of the requests that this provider is used for.",
+          "type": "string",
+          "examples": ["contractors"]
+        },
+        "groupsHeader": {
+          "description": "The name (case-insensitive) of an HTTP header whose value is a comma-separated list of the groups of the client. If set, the user is added to and removed from the organizations of ` + "`" + `groupMappings` + "`" + ` according to its groups.",
+          "type": "string",
+          "examples": ["X-Forwarded-Groups"]
+        },
+        "groupMappings": {
+          "description": "Maps the groups of the ` + "`" + `groupsHeader` + "`" + ` header to organizations. A user is a member of an organization of this list if and only if one of its groups maps to the organization. Memberships of other organizations are not changed.",
+          "type": "array",
+          "items": { "$ref": "#/definitions/HTTPHeaderGroupMapping" }
+        }
+      }
+    },
+    "HTTPHeaderGroupMapping": {
+      "description": "Maps a group of the ` + "`" + `groupsHeader` + "`" + ` HTTP header to an organization.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["group", "organization"],
+      "properties": {
+        "group": {
+          "description": "The name of the group, as sent in the ` + "`" + `groupsHeader` + "`" + ` HTTP header.",
+          "type": "string",
+          "minLength": 1
+        },
+        "organization": {
+          "description": "The name of the organization that the members of the group are added to. The organization must exist.",
+          "type": "string",
+          "minLength": 1
         }
       }
     },
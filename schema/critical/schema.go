@@ -72,8 +72,10 @@ type BuiltinAuthProvider struct {
 	// AllowSignup description: Allows new visitors to sign up for accounts. The sign-up page will be enabled and accessible to all visitors.
 	//
 	// SECURITY: If the site has no users (i.e., during initial setup), it will always allow the first user to sign up and become site admin **without any approval** (first user to sign up becomes the admin).
-	AllowSignup bool   `json:"allowSignup,omitempty"`
-	Type        string `json:"type"`
+	AllowSignup bool `json:"allowSignup,omitempty"`
+	// RequireTwoFactor description: Requires users who sign in with a username and password to use two-factor authentication with an authenticator app (TOTP). Users who haven't enabled two-factor authentication are asked to set it up when they sign in. Access tokens are not affected.
+	RequireTwoFactor bool   `json:"requireTwoFactor,omitempty"`
+	Type             string `json:"type"`
 }
 
 // CriticalConfiguration description: Critical configuration for a Sourcegraph site.
@@ -168,6 +170,16 @@ type GitLabAuthProvider struct {
 
 // HTTPHeaderAuthProvider description: Configures the HTTP header authentication provider (which authenticates users by consulting an HTTP request header set by an authentication proxy such as https://github.com/bitly/oauth2_proxy).
 type HTTPHeaderAuthProvider struct {
+	// ConfigID description: An identifier that can be used to reference this authentication provider in other parts of the config. Required if there is more than one http-header auth provider. The user accounts of each provider are stored separately.
+	ConfigID string `json:"configID,omitempty"`
+	// DiscriminatorHeader description: The name (case-insensitive) of an HTTP header that identifies the authentication proxy that sent the request. If set, this provider is only used for requests whose header has the value `discriminatorValue`. Required if there is more than one http-header auth provider.
+	DiscriminatorHeader string `json:"discriminatorHeader,omitempty"`
+	// DiscriminatorValue description: The value of the `discriminatorHeader` header of the requests that this provider is used for.
+	DiscriminatorValue string `json:"discriminatorValue,omitempty"`
+	// GroupMappings description: Maps the groups of the `groupsHeader` header to organizations. A user is a member of an organization of this list if and only if one of its groups maps to the organization. Memberships of other organizations are not changed.
+	GroupMappings []*HTTPHeaderGroupMapping `json:"groupMappings,omitempty"`
+	// GroupsHeader description: The name (case-insensitive) of an HTTP header whose value is a comma-separated list of the groups of the client. If set, the user is added to and removed from the organizations of `groupMappings` according to its groups.
+	GroupsHeader string `json:"groupsHeader,omitempty"`
 	// StripUsernameHeaderPrefix description: The prefix that precedes the username portion of the HTTP header specified in `usernameHeader`. If specified, the prefix will be stripped from the header value and the remainder will be used as the username. For example, if using Google Identity-Aware Proxy (IAP) with Google Sign-In, set this value to `accounts.google.com:`.
 	StripUsernameHeaderPrefix string `json:"stripUsernameHeaderPrefix,omitempty"`
 	Type                      string `json:"type"`
@@ -175,6 +187,14 @@ type HTTPHeaderAuthProvider struct {
 	UsernameHeader string `json:"usernameHeader"`
 }
 
+// HTTPHeaderGroupMapping description: Maps a group of the `groupsHeader` HTTP header to an organization.
+type HTTPHeaderGroupMapping struct {
+	// Group description: The name of the group, as sent in the `groupsHeader` HTTP header.
+	Group string `json:"group"`
+	// Organization description: The name of the organization that the members of the group are added to. The organization must exist.
+	Organization string `json:"organization"`
+}
+
 // Log description: Configuration for logging and alerting, including to external services.
 type Log struct {
 	// Sentry description: Configuration for Sentry
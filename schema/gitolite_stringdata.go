@@ -75,6 +75,13 @@ const GitoliteSchemaJSON = `{
           "type": "string"
         }
       }
+    },
+    "authorization": {
+      "title": "GitoliteAuthorization",
+      "description": "If non-null, enforces explicit repository permissions for the repositories of this Gitolite host. Users can only access the repositories they were granted access to with the ` + "`" + `setExplicitRepositoryPermissions` + "`" + ` GraphQL mutation or the bulk upload API.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {}
     }
   }
 }
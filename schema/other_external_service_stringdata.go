@@ -39,6 +39,13 @@ const OtherExternalServiceSchemaJSON = `{
       "type": "string",
       "default": "{base}/{repo}",
       "examples": ["pretty-host-name/{repo}"]
+    },
+    "authorization": {
+      "title": "OtherExternalServiceAuthorization",
+      "description": "If non-null, enforces explicit repository permissions for the repositories of this connection. Users can only access the repositories they were granted access to with the ` + "`" + `setExplicitRepositoryPermissions` + "`" + ` GraphQL mutation or the bulk upload API.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {}
     }
   }
 }
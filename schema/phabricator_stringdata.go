@@ -42,6 +42,13 @@ const PhabricatorSchemaJSON = `{
           }
         }
       }
+    },
+    "authorization": {
+      "title": "PhabricatorAuthorization",
+      "description": "If non-null, enforces explicit repository permissions for the repositories of this Phabricator instance. Users can only access the repositories they were granted access to with the ` + "`" + `setExplicitRepositoryPermissions` + "`" + ` GraphQL mutation or the bulk upload API.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {}
     }
   }
 }
@@ -64,6 +64,7 @@ type AuthProviders struct {
 	HttpHeader    *HTTPHeaderAuthProvider
 	Github        *GitHubAuthProvider
 	Gitlab        *GitLabAuthProvider
+	Ldap          *LDAPAuthProvider
 }
 
 func (v AuthProviders) MarshalJSON() ([]byte, error) {
@@ -85,6 +86,9 @@ func (v AuthProviders) MarshalJSON() ([]byte, error) {
 	if v.Gitlab != nil {
 		return json.Marshal(v.Gitlab)
 	}
+	if v.Ldap != nil {
+		return json.Marshal(v.Ldap)
+	}
 	return nil, errors.New("tagged union type must have exactly 1 non-nil field value")
 }
 func (v *AuthProviders) UnmarshalJSON(data []byte) error {
@@ -103,12 +107,14 @@ func (v *AuthProviders) UnmarshalJSON(data []byte) error {
 		return json.Unmarshal(data, &v.Gitlab)
 	case "http-header":
 		return json.Unmarshal(data, &v.HttpHeader)
+	case "ldap":
+		return json.Unmarshal(data, &v.Ldap)
 	case "openidconnect":
 		return json.Unmarshal(data, &v.Openidconnect)
 	case "saml":
 		return json.Unmarshal(data, &v.Saml)
 	}
-	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab"})
+	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"})
 }
 
 // BitbucketCloudConnection description: Configuration for a connection to Bitbucket Cloud.
@@ -141,6 +147,12 @@ type BitbucketCloudConnection struct {
 	Url string `json:"url"`
 	// Username description: The username to use when authenticating to the Bitbucket Cloud. Also set the corresponding "appPassword" field.
 	Username string `json:"username"`
+	// Webhooks description: An array of configurations defining existing Bitbucket Cloud webhooks that send updates back to Sourcegraph. Bitbucket Cloud doesn't sign webhook requests, so the secret is included in the URL of the webhook, `https://sourcegraph.example.com/.api/bitbucket-cloud-webhooks?secret=<secret>`, which is configured on repositories with the pull request and build status events.
+	Webhooks []*BitbucketCloudWebhook `json:"webhooks,omitempty"`
+}
+type BitbucketCloudWebhook struct {
+	// Secret description: The secret included in the URL of the webhook
+	Secret string `json:"secret"`
 }
 
 // BitbucketServerAuthorization description: If non-null, enforces Bitbucket Server repository permissions.
@@ -285,8 +297,10 @@ type BuiltinAuthProvider struct {
 	// AllowSignup description: Allows new visitors to sign up for accounts. The sign-up page will be enabled and accessible to all visitors.
 	//
 	// SECURITY: If the site has no users (i.e., during initial setup), it will always allow the first user to sign up and become site admin **without any approval** (first user to sign up becomes the admin).
-	AllowSignup bool   `json:"allowSignup,omitempty"`
-	Type        string `json:"type"`
+	AllowSignup bool `json:"allowSignup,omitempty"`
+	// RequireTwoFactor description: Requires users who sign in with a username and password to use two-factor authentication with an authenticator app (TOTP). Users who haven't enabled two-factor authentication are asked to set it up when they sign in. Access tokens are not affected.
+	RequireTwoFactor bool   `json:"requireTwoFactor,omitempty"`
+	Type             string `json:"type"`
 }
 
 // CloneURLToRepositoryName description: Describes a mapping from clone URL to repository name. The `from` field contains a regular expression with named capturing groups. The `to` field contains a template string that references capturing group names. For instance, if `from` is "^../(?P<name>\w+)$" and `to` is "github.com/user/{name}", the clone URL "../myRepository" would be mapped to the repository name "github.com/user/myRepository".
@@ -557,6 +571,8 @@ type GitLabConnection struct {
 	Token string `json:"token"`
 	// Url description: URL of a GitLab instance, such as https://gitlab.example.com or (for GitLab.com) https://gitlab.com.
 	Url string `json:"url"`
+	// Webhooks description: An array of configurations defining existing GitLab webhooks that send updates back to Sourcegraph. The webhooks are configured on GitLab projects or groups with the URL `https://sourcegraph.example.com/.api/gitlab-webhooks`, one of these secret tokens and the merge request, comment and pipeline events.
+	Webhooks []*GitLabWebhook `json:"webhooks,omitempty"`
 }
 type GitLabNameTransformation struct {
 	// Regex description: The regex to match for the occurrences of its replacement.
@@ -570,9 +586,19 @@ type GitLabProject struct {
 	// Name description: The name of a GitLab project ("group/name") to mirror.
 	Name string `json:"name,omitempty"`
 }
+type GitLabWebhook struct {
+	// Secret description: The secret token used when creating the webhook
+	Secret string `json:"secret"`
+}
+
+// GitoliteAuthorization description: If non-null, enforces explicit repository permissions for the repositories of this Gitolite host. Users can only access the repositories they were granted access to with the `setExplicitRepositoryPermissions` GraphQL mutation or the bulk upload API.
+type GitoliteAuthorization struct {
+}
 
 // GitoliteConnection description: Configuration for a connection to Gitolite.
 type GitoliteConnection struct {
+	// Authorization description: If non-null, enforces explicit repository permissions for the repositories of this Gitolite host. Users can only access the repositories they were granted access to with the `setExplicitRepositoryPermissions` GraphQL mutation or the bulk upload API.
+	Authorization *GitoliteAuthorization `json:"authorization,omitempty"`
 	// Blacklist description: Regular expression to filter repositories from auto-discovery, so they will not get cloned automatically.
 	Blacklist string `json:"blacklist,omitempty"`
 	// Exclude description: A list of repositories to never mirror from this Gitolite instance. Supports excluding by exact name ({"name": "foo"}).
@@ -591,6 +617,16 @@ type GitoliteConnection struct {
 
 // HTTPHeaderAuthProvider description: Configures the HTTP header authentication provider (which authenticates users by consulting an HTTP request header set by an authentication proxy such as https://github.com/bitly/oauth2_proxy).
 type HTTPHeaderAuthProvider struct {
+	// ConfigID description: An identifier that can be used to reference this authentication provider in other parts of the config. Required if there is more than one http-header auth provider. The user accounts of each provider are stored separately.
+	ConfigID string `json:"configID,omitempty"`
+	// DiscriminatorHeader description: The name (case-insensitive) of an HTTP header that identifies the authentication proxy that sent the request. If set, this provider is only used for requests whose header has the value `discriminatorValue`. Required if there is more than one http-header auth provider.
+	DiscriminatorHeader string `json:"discriminatorHeader,omitempty"`
+	// DiscriminatorValue description: The value of the `discriminatorHeader` header of the requests that this provider is used for.
+	DiscriminatorValue string `json:"discriminatorValue,omitempty"`
+	// GroupMappings description: Maps the groups of the `groupsHeader` header to organizations. A user is a member of an organization of this list if and only if one of its groups maps to the organization. Memberships of other organizations are not changed.
+	GroupMappings []*HTTPHeaderGroupMapping `json:"groupMappings,omitempty"`
+	// GroupsHeader description: The name (case-insensitive) of an HTTP header whose value is a comma-separated list of the groups of the client. If set, the user is added to and removed from the organizations of `groupMappings` according to its groups.
+	GroupsHeader string `json:"groupsHeader,omitempty"`
 	// StripUsernameHeaderPrefix description: The prefix that precedes the username portion of the HTTP header specified in `usernameHeader`. If specified, the prefix will be stripped from the header value and the remainder will be used as the username. For example, if using Google Identity-Aware Proxy (IAP) with Google Sign-In, set this value to `accounts.google.com:`.
 	StripUsernameHeaderPrefix string `json:"stripUsernameHeaderPrefix,omitempty"`
 	Type                      string `json:"type"`
@@ -598,6 +634,14 @@ type HTTPHeaderAuthProvider struct {
 	UsernameHeader string `json:"usernameHeader"`
 }
 
+// HTTPHeaderGroupMapping description: Maps a group of the `groupsHeader` HTTP header to an organization.
+type HTTPHeaderGroupMapping struct {
+	// Group description: The name of the group, as sent in the `groupsHeader` HTTP header.
+	Group string `json:"group"`
+	// Organization description: The name of the organization that the members of the group are added to. The organization must exist.
+	Organization string `json:"organization"`
+}
+
 // IMAPServerConfig description: Optional. The IMAP server used to retrieve emails (such as code discussion reply emails).
 type IMAPServerConfig struct {
 	// Host description: The IMAP server host.
@@ -647,6 +691,76 @@ func (v *IdentityProvider) UnmarshalJSON(data []byte) error {
 	return fmt.Errorf("tagged union type must have a %q property whose value is one of %s", "type", []string{"oauth", "username", "external"})
 }
 
+// LDAPAuthProvider description: Configures the LDAP authentication provider, which authenticates users with their LDAP (or Active Directory) username and password. Sourcegraph binds to the LDAP server with the service account, searches for the user's entry and then binds as the user to check the password.
+type LDAPAuthProvider struct {
+	// AllowSignup description: Allows users that don't have a Sourcegraph account yet to sign up by signing in with LDAP. If false, users signing in with LDAP must have an existing Sourcegraph account with a verified email address matching their LDAP email address, which will be linked to their LDAP identity after sign-in.
+	AllowSignup   *bool               `json:"allowSignup,omitempty"`
+	Attributes    *LDAPUserAttributes `json:"attributes,omitempty"`
+	Authorization *LDAPAuthorization  `json:"authorization,omitempty"`
+	// BindDN description: The DN of the service account that Sourcegraph binds as to search for users and groups. Leave empty to search anonymously.
+	BindDN string `json:"bindDN,omitempty"`
+	// BindPassword description: The password of the service account.
+	BindPassword string `json:"bindPassword,omitempty"`
+	// Certificate description: TLS certificate of the LDAP server, if it is signed by a certificate authority that is not trusted by default (such as an internal CA).
+	Certificate string `json:"certificate,omitempty"`
+	// ConfigID description: An identifier that can be used to reference this authentication provider in other parts of the config. For example, in configuration for a code host, you may want to designate this authentication provider as the identity provider for the code host.
+	ConfigID    string           `json:"configID,omitempty"`
+	DisplayName string           `json:"displayName,omitempty"`
+	GroupSearch *LDAPGroupSearch `json:"groupSearch,omitempty"`
+	// StartTLS description: Upgrade ldap:// connections to TLS with the StartTLS extended operation before sending any credentials.
+	StartTLS bool   `json:"startTLS,omitempty"`
+	Type     string `json:"type"`
+	// Url description: URL of the LDAP server. Use the ldaps scheme for LDAP over TLS.
+	Url        string         `json:"url"`
+	UserSearch LDAPUserSearch `json:"userSearch"`
+}
+
+// LDAPAuthorization description: Grants users access to the repositories of a code host based on their LDAP group membership. This is for code hosts that can't enforce repository permissions themselves, such as Gitolite, Phabricator and other Git hosts.
+type LDAPAuthorization struct {
+	// CodeHostURL description: The code host whose repositories are governed by these rules. It must be the service ID of the code host's repositories: the `host` of a Gitolite external service, or the `url` of a Phabricator or other Git host external service (without its path).
+	CodeHostURL string `json:"codeHostURL"`
+	// Rules description: Rules granting the members of LDAP groups read access to repositories. A user can access a repository of the code host if any rule matching one of the user's groups matches the repository name.
+	Rules []*LDAPAuthorizationRule `json:"rules"`
+	// Ttl description: The TTL of how long to cache the group memberships of a user. This is 3 hours by default. Changes to group memberships take up to this long to take effect.
+	Ttl string `json:"ttl,omitempty"`
+}
+
+// LDAPAuthorizationRule description: A rule granting the members of an LDAP group read access to repositories.
+type LDAPAuthorizationRule struct {
+	// Group description: The name of the LDAP group (see `groupSearch.nameAttribute`).
+	Group string `json:"group"`
+	// Repos description: Regular expressions matched against the names of the repositories (such as gitolite.example.com/eng/backend) that the group's members can access.
+	Repos []string `json:"repos"`
+}
+
+// LDAPGroupSearch description: How to find the groups a user is a member of. Required for `authorization`.
+type LDAPGroupSearch struct {
+	// BaseDN description: The DN under which to search for groups.
+	BaseDN string `json:"baseDN"`
+	// Filter description: The LDAP search filter that matches the groups of a user. The {dn} and {username} placeholders are replaced with the (escaped) DN and username of the user.
+	Filter string `json:"filter,omitempty"`
+	// NameAttribute description: The attribute holding the name of a group.
+	NameAttribute string `json:"nameAttribute,omitempty"`
+}
+
+// LDAPUserAttributes description: The attributes of the user entry from which to populate the Sourcegraph user.
+type LDAPUserAttributes struct {
+	// DisplayName description: The attribute holding the display name.
+	DisplayName string `json:"displayName,omitempty"`
+	// Email description: The attribute holding the email address.
+	Email string `json:"email,omitempty"`
+	// Username description: The attribute holding the username.
+	Username string `json:"username,omitempty"`
+}
+
+// LDAPUserSearch description: How to find the entry of a user signing in.
+type LDAPUserSearch struct {
+	// BaseDN description: The DN under which to search for users.
+	BaseDN string `json:"baseDN"`
+	// Filter description: The LDAP search filter that matches exactly one user. The {username} placeholder is replaced with the (escaped) username entered by the user.
+	Filter string `json:"filter,omitempty"`
+}
+
 // Log description: Configuration for logging and alerting, including to external services.
 type Log struct {
 	// Sentry description: Configuration for Sentry
@@ -690,9 +804,15 @@ type OpenIDConnectAuthProvider struct {
 	Type               string `json:"type"`
 }
 
+// OtherExternalServiceAuthorization description: If non-null, enforces explicit repository permissions for the repositories of this connection. Users can only access the repositories they were granted access to with the `setExplicitRepositoryPermissions` GraphQL mutation or the bulk upload API.
+type OtherExternalServiceAuthorization struct {
+}
+
 // OtherExternalServiceConnection description: Configuration for a Connection to Git repositories for which an external service integration isn't yet available.
 type OtherExternalServiceConnection struct {
-	Repos []string `json:"repos"`
+	// Authorization description: If non-null, enforces explicit repository permissions for the repositories of this connection. Users can only access the repositories they were granted access to with the `setExplicitRepositoryPermissions` GraphQL mutation or the bulk upload API.
+	Authorization *OtherExternalServiceAuthorization `json:"authorization,omitempty"`
+	Repos         []string                           `json:"repos"`
 	// RepositoryPathPattern description: The pattern used to generate the corresponding Sourcegraph repository name for the repositories. In the pattern, the variable "{base}" is replaced with the Git clone base URL host and path, and "{repo}" is replaced with the repository path taken from the `repos` field.
 	//
 	// For example, if your Git clone base URL is https://git.example.com/repos and `repos` contains the value "my/repo", then a repositoryPathPattern of "{base}/{repo}" would mean that a repository at https://git.example.com/repos/my/repo is available on Sourcegraph at https://sourcegraph.example.com/git.example.com/repos/my/repo.
@@ -729,8 +849,14 @@ type Phabricator struct {
 	Url string `json:"url"`
 }
 
+// PhabricatorAuthorization description: If non-null, enforces explicit repository permissions for the repositories of this Phabricator instance. Users can only access the repositories they were granted access to with the `setExplicitRepositoryPermissions` GraphQL mutation or the bulk upload API.
+type PhabricatorAuthorization struct {
+}
+
 // PhabricatorConnection description: Configuration for a connection to Phabricator.
 type PhabricatorConnection struct {
+	// Authorization description: If non-null, enforces explicit repository permissions for the repositories of this Phabricator instance. Users can only access the repositories they were granted access to with the `setExplicitRepositoryPermissions` GraphQL mutation or the bulk upload API.
+	Authorization *PhabricatorAuthorization `json:"authorization,omitempty"`
 	// Repos description: The list of repositories available on Phabricator.
 	Repos []*Repos `json:"repos,omitempty"`
 	// Token description: API token for the Phabricator instance.
@@ -622,7 +622,7 @@ const SiteSchemaJSON = `{
         "properties": {
           "type": {
             "type": "string",
-            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab"]
+            "enum": ["builtin", "saml", "openidconnect", "http-header", "github", "gitlab", "ldap"]
           }
         },
         "oneOf": [
@@ -631,7 +631,8 @@ const SiteSchemaJSON = `{
           { "$ref": "#/definitions/OpenIDConnectAuthProvider" },
           { "$ref": "#/definitions/HTTPHeaderAuthProvider" },
           { "$ref": "#/definitions/GitHubAuthProvider" },
-          { "$ref": "#/definitions/GitLabAuthProvider" }
+          { "$ref": "#/definitions/GitLabAuthProvider" },
+          { "$ref": "#/definitions/LDAPAuthProvider" }
         ],
         "!go": {
           "taggedUnionType": true
@@ -704,6 +705,11 @@ const SiteSchemaJSON = `{
           "description": "Allows new visitors to sign up for accounts. The sign-up page will be enabled and accessible to all visitors.\n\nSECURITY: If the site has no users (i.e., during initial setup), it will always allow the first user to sign up and become site admin **without any approval** (first user to sign up becomes the admin).",
           "type": "boolean",
           "default": false
+        },
+        "requireTwoFactor": {
+          "description": "Requires users who sign in with a username and password to use two-factor authentication with an authenticator app (TOTP). Users who haven't enabled two-factor authentication are asked to set it up when they sign in. Access tokens are not affected.",
+          "type": "boolean",
+          "default": false
         }
       }
     },
@@ -839,6 +845,212 @@ const SiteSchemaJSON = `{
           "description": "The prefix that precedes the username portion of the HTTP header specified in ` + "`" + `usernameHeader` + "`" + `. If specified, the prefix will be stripped from the header value and the remainder will be used as the username. For example, if using Google Identity-Aware Proxy (IAP) with Google Sign-In, set this value to ` + "`" + `accounts.google.com:` + "`" + `.",
           "type": "string",
           "examples": ["accounts.google.com:"]
+        },
+        "configID": {
+          "description": "An identifier that can be used to reference this authentication provider in other parts of the config. Required if there is more than one http-header auth provider. The user accounts of each provider are stored separately.",
+          "type": "string"
+        },
+        "discriminatorHeader": {
+          "description": "The name (case-insensitive) of an HTTP header that identifies the authentication proxy that sent the request. If set, this provider is only used for requests whose header has the value ` + "`" + `discriminatorValue` + "`" + `. Required if there is more than one http-header auth provider.",
+          "type": "string",
+          "examples": ["X-Forwarded-Proxy"]
+        },
+        "discriminatorValue": {
+          "description": "The value of the ` + "`" + `discriminatorHeader` + "`" + ` header of the requests that this provider is used for.",
+          "type": "string",
+          "examples": ["contractors"]
+        },
+        "groupsHeader": {
+          "description": "The name (case-insensitive) of an HTTP header whose value is a comma-separated list of the groups of the client. If set, the user is added to and removed from the organizations of ` + "`" + `groupMappings` + "`" + ` according to its groups.",
+          "type": "string",
+          "examples": ["X-Forwarded-Groups"]
+        },
+        "groupMappings": {
+          "description": "Maps the groups of the ` + "`" + `groupsHeader` + "`" + ` header to organizations. A user is a member of an organization of this list if and only if one of its groups maps to the organization. Memberships of other organizations are not changed.",
+          "type": "array",
+          "items": { "$ref": "#/definitions/HTTPHeaderGroupMapping" }
+        }
+      }
+    },
+    "HTTPHeaderGroupMapping": {
+      "description": "Maps a group of the ` + "`" + `groupsHeader` + "`" + ` HTTP header to an organization.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["group", "organization"],
+      "properties": {
+        "group": {
+          "description": "The name of the group, as sent in the ` + "`" + `groupsHeader` + "`" + ` HTTP header.",
+          "type": "string",
+          "minLength": 1
+        },
+        "organization": {
+          "description": "The name of the organization that the members of the group are added to. The organization must exist.",
+          "type": "string",
+          "minLength": 1
+        }
+      }
+    },
+    "LDAPAuthProvider": {
+      "description": "Configures the LDAP authentication provider, which authenticates users with their LDAP (or Active Directory) username and password. Sourcegraph binds to the LDAP server with the service account, searches for the user's entry and then binds as the user to check the password.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["type", "url", "userSearch"],
+      "properties": {
+        "type": {
+          "type": "string",
+          "const": "ldap"
+        },
+        "configID": {
+          "description": "An identifier that can be used to reference this authentication provider in other parts of the config. For example, in configuration for a code host, you may want to designate this authentication provider as the identity provider for the code host.",
+          "type": "string"
+        },
+        "displayName": { "$ref": "#/definitions/AuthProviderCommon/properties/displayName" },
+        "url": {
+          "description": "URL of the LDAP server. Use the ldaps scheme for LDAP over TLS.",
+          "type": "string",
+          "pattern": "^ldaps?://",
+          "examples": ["ldaps://ldap.example.com", "ldap://ad.example.com:389"]
+        },
+        "startTLS": {
+          "description": "Upgrade ldap:// connections to TLS with the StartTLS extended operation before sending any credentials.",
+          "type": "boolean",
+          "default": false
+        },
+        "certificate": {
+          "description": "TLS certificate of the LDAP server, if it is signed by a certificate authority that is not trusted by default (such as an internal CA).",
+          "type": "string",
+          "pattern": "^-----BEGIN CERTIFICATE-----\n",
+          "examples": ["-----BEGIN CERTIFICATE-----\n..."]
+        },
+        "bindDN": {
+          "description": "The DN of the service account that Sourcegraph binds as to search for users and groups. Leave empty to search anonymously.",
+          "type": "string",
+          "examples": ["cn=sourcegraph,ou=services,dc=example,dc=com"]
+        },
+        "bindPassword": {
+          "description": "The password of the service account.",
+          "type": "string"
+        },
+        "userSearch": { "$ref": "#/definitions/LDAPUserSearch" },
+        "attributes": { "$ref": "#/definitions/LDAPUserAttributes" },
+        "allowSignup": {
+          "description": "Allows users that don't have a Sourcegraph account yet to sign up by signing in with LDAP. If false, users signing in with LDAP must have an existing Sourcegraph account with a verified email address matching their LDAP email address, which will be linked to their LDAP identity after sign-in.",
+          "type": "boolean",
+          "default": true,
+          "!go": { "pointer": true }
+        },
+        "groupSearch": { "$ref": "#/definitions/LDAPGroupSearch" },
+        "authorization": { "$ref": "#/definitions/LDAPAuthorization" }
+      }
+    },
+    "LDAPAuthorization": {
+      "description": "Grants users access to the repositories of a code host based on their LDAP group membership. This is for code hosts that can't enforce repository permissions themselves, such as Gitolite, Phabricator and other Git hosts.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["codeHostURL", "rules"],
+      "properties": {
+        "codeHostURL": {
+          "description": "The code host whose repositories are governed by these rules. It must be the service ID of the code host's repositories: the ` + "`" + `host` + "`" + ` of a Gitolite external service, or the ` + "`" + `url` + "`" + ` of a Phabricator or other Git host external service (without its path).",
+          "type": "string",
+          "examples": ["https://gitolite.example.com/", "git@gitolite.example.com"]
+        },
+        "ttl": {
+          "description": "The TTL of how long to cache the group memberships of a user. This is 3 hours by default. Changes to group memberships take up to this long to take effect.",
+          "type": "string",
+          "default": "3h",
+          "examples": ["10m"]
+        },
+        "rules": {
+          "description": "Rules granting the members of LDAP groups read access to repositories. A user can access a repository of the code host if any rule matching one of the user's groups matches the repository name.",
+          "type": "array",
+          "items": { "$ref": "#/definitions/LDAPAuthorizationRule" }
+        }
+      }
+    },
+    "LDAPUserSearch": {
+      "description": "How to find the entry of a user signing in.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["baseDN"],
+      "properties": {
+        "baseDN": {
+          "description": "The DN under which to search for users.",
+          "type": "string",
+          "examples": ["ou=people,dc=example,dc=com"]
+        },
+        "filter": {
+          "description": "The LDAP search filter that matches exactly one user. The {username} placeholder is replaced with the (escaped) username entered by the user.",
+          "type": "string",
+          "default": "(uid={username})",
+          "examples": ["(&(objectClass=user)(sAMAccountName={username}))"]
+        }
+      }
+    },
+    "LDAPUserAttributes": {
+      "description": "The attributes of the user entry from which to populate the Sourcegraph user.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "username": {
+          "description": "The attribute holding the username.",
+          "type": "string",
+          "default": "uid",
+          "examples": ["sAMAccountName"]
+        },
+        "email": {
+          "description": "The attribute holding the email address.",
+          "type": "string",
+          "default": "mail"
+        },
+        "displayName": {
+          "description": "The attribute holding the display name.",
+          "type": "string",
+          "default": "cn",
+          "examples": ["displayName"]
+        }
+      }
+    },
+    "LDAPGroupSearch": {
+      "description": "How to find the groups a user is a member of. Required for ` + "`" + `authorization` + "`" + `.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["baseDN"],
+      "properties": {
+        "baseDN": {
+          "description": "The DN under which to search for groups.",
+          "type": "string",
+          "examples": ["ou=groups,dc=example,dc=com"]
+        },
+        "filter": {
+          "description": "The LDAP search filter that matches the groups of a user. The {dn} and {username} placeholders are replaced with the (escaped) DN and username of the user.",
+          "type": "string",
+          "default": "(member={dn})",
+          "examples": ["(memberUid={username})"]
+        },
+        "nameAttribute": {
+          "description": "The attribute holding the name of a group.",
+          "type": "string",
+          "default": "cn"
+        }
+      }
+    },
+    "LDAPAuthorizationRule": {
+      "description": "A rule granting the members of an LDAP group read access to repositories.",
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["group", "repos"],
+      "properties": {
+        "group": {
+          "description": "The name of the LDAP group (see ` + "`" + `groupSearch.nameAttribute` + "`" + `).",
+          "type": "string",
+          "examples": ["engineering"]
+        },
+        "repos": {
+          "description": "Regular expressions matched against the names of the repositories (such as gitolite.example.com/eng/backend) that the group's members can access.",
+          "type": "array",
+          "items": { "type": "string", "format": "regex" },
+          "minItems": 1,
+          "examples": [["^gitolite\\.example\\.com/eng/"]]
         }
       }
     },
@@ -0,0 +1,127 @@
+import { LoadingSpinner } from '@sourcegraph/react-loading-spinner'
+import * as H from 'history'
+import * as React from 'react'
+import { Form } from '../components/Form'
+import { eventLogger } from '../tracking/eventLogger'
+import { getReturnTo, PasswordInput } from './SignInSignUpCommon'
+import { ErrorAlert } from '../components/alerts'
+import { asError } from '../../../shared/src/util/errors'
+
+interface Props {
+    location: H.Location
+    history: H.History
+
+    /** The display name of the LDAP auth provider. */
+    displayName: string
+
+    /** The URL of the LDAP auth provider's sign-in endpoint. */
+    authenticationURL: string
+}
+
+interface State {
+    username: string
+    password: string
+    error?: Error
+    loading: boolean
+}
+
+/**
+ * The form for signing in with an LDAP username and password.
+ */
+export class LDAPSignInForm extends React.Component<Props, State> {
+    constructor(props: Props) {
+        super(props)
+        this.state = {
+            username: '',
+            password: '',
+            loading: false,
+        }
+    }
+
+    public render(): JSX.Element | null {
+        return (
+            <Form className="signin-signup-form signin-form e2e-ldap-signin-form" onSubmit={this.handleSubmit}>
+                <p className="text-muted">Sign in with your {this.props.displayName} account.</p>
+                {this.state.error && <ErrorAlert className="my-2" error={this.state.error} icon={false} />}
+                <div className="form-group">
+                    <input
+                        className="form-control signin-signup-form__input"
+                        type="text"
+                        placeholder="Username"
+                        onChange={this.onUsernameFieldChange}
+                        required={true}
+                        value={this.state.username}
+                        disabled={this.state.loading}
+                        autoCapitalize="off"
+                        autoComplete="username"
+                    />
+                </div>
+                <div className="form-group">
+                    <PasswordInput
+                        className="signin-signup-form__input"
+                        onChange={this.onPasswordFieldChange}
+                        value={this.state.password}
+                        required={true}
+                        disabled={this.state.loading}
+                        autoComplete="current-password"
+                    />
+                </div>
+                <div className="form-group">
+                    <button className="btn btn-primary btn-block" type="submit" disabled={this.state.loading}>
+                        Sign in with {this.props.displayName}
+                    </button>
+                </div>
+                {this.state.loading && (
+                    <div className="w-100 text-center mb-2">
+                        <LoadingSpinner className="icon-inline" />
+                    </div>
+                )}
+            </Form>
+        )
+    }
+
+    private onUsernameFieldChange = (e: React.ChangeEvent<HTMLInputElement>): void => {
+        this.setState({ username: e.target.value })
+    }
+
+    private onPasswordFieldChange = (e: React.ChangeEvent<HTMLInputElement>): void => {
+        this.setState({ password: e.target.value })
+    }
+
+    private handleSubmit = (event: React.FormEvent<HTMLFormElement>): void => {
+        event.preventDefault()
+        if (this.state.loading) {
+            return
+        }
+
+        this.setState({ loading: true })
+        eventLogger.log('InitiateSignIn')
+        fetch(this.props.authenticationURL, {
+            credentials: 'same-origin',
+            method: 'POST',
+            headers: {
+                ...window.context.xhrHeaders,
+                Accept: 'application/json',
+                'Content-Type': 'application/json',
+            },
+            body: JSON.stringify({
+                username: this.state.username,
+                password: this.state.password,
+            }),
+        })
+            .then(resp => {
+                if (resp.status === 200) {
+                    const returnTo = getReturnTo(this.props.location)
+                    window.location.replace(returnTo)
+                } else if (resp.status === 401) {
+                    throw new Error('User or password was incorrect')
+                } else {
+                    throw new Error('Unknown Error')
+                }
+            })
+            .catch(error => {
+                console.error('Auth error:', error)
+                this.setState({ loading: false, error: asError(error) })
+            })
+    }
+}
@@ -6,6 +6,7 @@ import * as GQL from '../../../shared/src/graphql/schema'
 import { HeroPage } from '../components/HeroPage'
 import { PageTitle } from '../components/PageTitle'
 import { eventLogger } from '../tracking/eventLogger'
+import { LDAPSignInForm } from './LDAPSignInForm'
 import { getReturnTo } from './SignInSignUpCommon'
 import { UsernamePasswordSignInForm } from './UsernamePasswordSignInForm'
 
@@ -35,6 +36,13 @@ export const SignInPage: React.FunctionComponent<SignInPageProps> = props => {
                             {window.context.authProviders.map((provider, i) =>
                                 provider.isBuiltin ? (
                                     <UsernamePasswordSignInForm key={i} {...props} />
+                                ) : provider.serviceType === 'ldap' && provider.authenticationURL ? (
+                                    <LDAPSignInForm
+                                        key={i}
+                                        {...props}
+                                        displayName={provider.displayName}
+                                        authenticationURL={provider.authenticationURL}
+                                    />
                                 ) : (
                                     <div className="mb-2">
                                         <a key={i} href={provider.authenticationURL} className="btn btn-secondary">
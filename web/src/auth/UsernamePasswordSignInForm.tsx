@@ -18,8 +18,28 @@ interface State {
     password: string
     error?: Error
     loading: boolean
+
+    /** The two-factor authentication code or recovery code, asked for after the password is accepted. */
+    twoFactorCode: string
+    twoFactorRequired: boolean
+    /** The secret to add to an authenticator app, when site config requires two-factor authentication. */
+    twoFactorEnrollment?: { secret: string; keyURI: string }
+    /** The recovery codes of a completed two-factor authentication enrollment, shown once. */
+    recoveryCodes?: string[]
+}
+
+/** The JSON body of the sign-in responses of users with two-factor authentication. */
+interface TwoFactorResponse {
+    twoFactorRequired?: boolean
+    twoFactorEnrollmentRequired?: boolean
+    twoFactorSecret?: string
+    twoFactorKeyURI?: string
+    recoveryCodes?: string[]
 }
 
+const isJSONResponse = (resp: Response): boolean =>
+    (resp.headers.get('Content-Type') || '').startsWith('application/json')
+
 /**
  * The form for signing in with a username and password.
  */
@@ -30,10 +50,29 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
             email: '',
             password: '',
             loading: false,
+            twoFactorCode: '',
+            twoFactorRequired: false,
         }
     }
 
     public render(): JSX.Element | null {
+        if (this.state.recoveryCodes) {
+            return (
+                <div className="signin-signup-form signin-form">
+                    <p>
+                        Two-factor authentication is now enabled. Store these recovery codes in a safe place. Each of
+                        them can be used once to sign in if you lose access to your authenticator app.
+                    </p>
+                    <pre className="e2e-recovery-codes">{this.state.recoveryCodes.join('\n')}</pre>
+                    <button className="btn btn-primary btn-block" type="button" onClick={this.redirect}>
+                        Continue
+                    </button>
+                </div>
+            )
+        }
+        if (this.state.twoFactorRequired || this.state.twoFactorEnrollment) {
+            return this.renderTwoFactorForm()
+        }
         return (
             <Form className="signin-signup-form signin-form e2e-signin-form" onSubmit={this.handleSubmit}>
                 {window.context.allowSignup ? (
@@ -87,6 +126,61 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
         )
     }
 
+    private renderTwoFactorForm(): JSX.Element {
+        const enrollment = this.state.twoFactorEnrollment
+        return (
+            <Form className="signin-signup-form signin-form e2e-two-factor-form" onSubmit={this.handleSubmit}>
+                {enrollment ? (
+                    <>
+                        <p>
+                            Two-factor authentication is required. Add this key to your authenticator app, then enter
+                            the code it shows.
+                        </p>
+                        <p>
+                            <code className="e2e-two-factor-secret">{enrollment.secret}</code>
+                        </p>
+                        <p>
+                            <small className="text-muted">
+                                Or open <a href={enrollment.keyURI}>this link</a> on a device with an authenticator app.
+                            </small>
+                        </p>
+                    </>
+                ) : (
+                    <p>Enter the code from your authenticator app, or one of your recovery codes.</p>
+                )}
+                {this.state.error && <ErrorAlert className="my-2" error={this.state.error} icon={false} />}
+                <div className="form-group">
+                    <input
+                        className="form-control signin-signup-form__input"
+                        type="text"
+                        placeholder="Authentication code"
+                        onChange={this.onTwoFactorCodeFieldChange}
+                        required={true}
+                        value={this.state.twoFactorCode}
+                        disabled={this.state.loading}
+                        autoCapitalize="off"
+                        autoFocus={true}
+                        autoComplete="one-time-code"
+                    />
+                </div>
+                <div className="form-group">
+                    <button className="btn btn-primary btn-block" type="submit" disabled={this.state.loading}>
+                        Verify
+                    </button>
+                </div>
+                {this.state.loading && (
+                    <div className="w-100 text-center mb-2">
+                        <LoadingSpinner className="icon-inline" />
+                    </div>
+                )}
+            </Form>
+        )
+    }
+
+    private onTwoFactorCodeFieldChange = (e: React.ChangeEvent<HTMLInputElement>): void => {
+        this.setState({ twoFactorCode: e.target.value })
+    }
+
     private onEmailFieldChange = (e: React.ChangeEvent<HTMLInputElement>): void => {
         this.setState({ email: e.target.value })
     }
@@ -114,18 +208,35 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
             body: JSON.stringify({
                 email: this.state.email,
                 password: this.state.password,
+                twoFactorCode: this.state.twoFactorCode,
             }),
         })
-            .then(resp => {
+            .then(async resp => {
                 if (resp.status === 200) {
-                    if (new URLSearchParams(this.props.location.search).get('close') === 'true') {
-                        window.close()
+                    const body: TwoFactorResponse = isJSONResponse(resp) ? await resp.json() : {}
+                    if (body.recoveryCodes) {
+                        this.setState({ loading: false, recoveryCodes: body.recoveryCodes })
                     } else {
-                        const returnTo = getReturnTo(this.props.location)
-                        window.location.replace(returnTo)
+                        this.redirect()
                     }
+                } else if (resp.status === 401 && isJSONResponse(resp)) {
+                    const body: TwoFactorResponse = await resp.json()
+                    this.setState({
+                        loading: false,
+                        error: undefined,
+                        twoFactorCode: '',
+                        twoFactorRequired: !!body.twoFactorRequired,
+                        twoFactorEnrollment:
+                            body.twoFactorEnrollmentRequired && body.twoFactorSecret && body.twoFactorKeyURI
+                                ? { secret: body.twoFactorSecret, keyURI: body.twoFactorKeyURI }
+                                : undefined,
+                    })
                 } else if (resp.status === 401) {
-                    throw new Error('User or password was incorrect')
+                    throw new Error(
+                        this.state.twoFactorRequired || this.state.twoFactorEnrollment
+                            ? 'Authentication code was incorrect'
+                            : 'User or password was incorrect'
+                    )
                 } else {
                     throw new Error('Unknown Error')
                 }
@@ -135,4 +246,13 @@ export class UsernamePasswordSignInForm extends React.Component<Props, State> {
                 this.setState({ loading: false, error: asError(error) })
             })
     }
+
+    private redirect = (): void => {
+        if (new URLSearchParams(this.props.location.search).get('close') === 'true') {
+            window.close()
+        } else {
+            const returnTo = getReturnTo(this.props.location)
+            window.location.replace(returnTo)
+        }
+    }
 }
@@ -109,6 +109,7 @@ interface SourcegraphContext
     authProviders?: {
         displayName: string
         isBuiltin: boolean
+        serviceType: string
         authenticationURL?: string
     }[]
 